@@ -3,7 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"os"
 	"strings"
 
 	logger "github.com/sirupsen/logrus"
@@ -18,6 +20,12 @@ import (
 	"github.com/keptn/keptn/api/utils"
 )
 
+// UserLabel is the label of the event containing the user who sent the event to the API
+const UserLabel = "triggeredBy"
+
+// envVarUserHeader is the env var containing the name of the header in which the API gateway passes the authenticated user
+const envVarUserHeader = "AUTH_USER_HEADER"
+
 // PostEventHandlerFunc forwards an event to the event broker
 func PostEventHandlerFunc(params event.PostEventParams, principal *models.Principal) middleware.Responder {
 
@@ -39,7 +47,9 @@ func PostEventHandlerFunc(params event.PostEventParams, principal *models.Princi
 		source, _ = url.Parse("https://github.com/keptn/keptn/api")
 	}
 
-	err = utils.SendEvent(keptnContext, params.Body.Triggeredid, params.Body.Gitcommitid, *params.Body.Type, source.String(), params.Body.Data)
+	data := applyUserLabel(params.Body.Data, getAuthenticatedUser(params.HTTPRequest))
+
+	err = utils.SendEvent(keptnContext, params.Body.Triggeredid, params.Body.Gitcommitid, *params.Body.Type, source.String(), data)
 
 	if err != nil {
 		return sendInternalErrorForPost(err)
@@ -50,6 +60,37 @@ func PostEventHandlerFunc(params event.PostEventParams, principal *models.Princi
 	return event.NewPostEventOK().WithPayload(&eventContext)
 }
 
+// getAuthenticatedUser returns the user the API gateway has authenticated the request for, or an empty string if the user is unknown
+func getAuthenticatedUser(req *http.Request) string {
+	header := os.Getenv(envVarUserHeader)
+	if header == "" || req == nil {
+		return ""
+	}
+	return strings.TrimSpace(req.Header.Get(header))
+}
+
+// applyUserLabel sets the user label of the event data to the authenticated user. Since Keptn services rely on this label,
+// e.g. for approval policies, a value provided by the client is never forwarded. Without an authenticated user, the label is removed
+func applyUserLabel(data interface{}, user string) interface{} {
+	eventData, ok := data.(map[string]interface{})
+	if !ok {
+		return data
+	}
+	labels, _ := eventData["labels"].(map[string]interface{})
+	if labels == nil {
+		if user == "" {
+			return data
+		}
+		labels = map[string]interface{}{}
+		eventData["labels"] = labels
+	}
+	delete(labels, UserLabel)
+	if user != "" {
+		labels[UserLabel] = user
+	}
+	return eventData
+}
+
 func createOrApplyKeptnContext(eventKeptnContext string) string {
 	uuid.SetRand(nil)
 	keptnContext := uuid.New().String()
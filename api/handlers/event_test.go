@@ -151,6 +151,57 @@ func TestPostEventHandlerFunc(t *testing.T) {
 	}
 }
 
+func Test_applyUserLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		user string
+		want interface{}
+	}{
+		{
+			name: "label provided by the client is replaced",
+			data: map[string]interface{}{"labels": map[string]interface{}{"triggeredBy": "alice", "type": "hotfix"}},
+			user: "bob",
+			want: map[string]interface{}{"labels": map[string]interface{}{"triggeredBy": "bob", "type": "hotfix"}},
+		},
+		{
+			name: "label provided by the client is removed without authenticated user",
+			data: map[string]interface{}{"labels": map[string]interface{}{"triggeredBy": "alice"}},
+			user: "",
+			want: map[string]interface{}{"labels": map[string]interface{}{}},
+		},
+		{
+			name: "labels are added for the authenticated user",
+			data: map[string]interface{}{"project": "sockshop"},
+			user: "bob",
+			want: map[string]interface{}{"project": "sockshop", "labels": map[string]interface{}{"triggeredBy": "bob"}},
+		},
+		{
+			name: "data without labels is kept without authenticated user",
+			data: map[string]interface{}{"project": "sockshop"},
+			user: "",
+			want: map[string]interface{}{"project": "sockshop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, applyUserLabel(tt.data, tt.user))
+		})
+	}
+}
+
+func Test_getAuthenticatedUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/event", nil)
+	req.Header.Set("X-Auth-Request-User", "bob")
+
+	t.Setenv(envVarUserHeader, "")
+	require.Equal(t, "", getAuthenticatedUser(req))
+
+	t.Setenv(envVarUserHeader, "X-Auth-Request-User")
+	require.Equal(t, "bob", getAuthenticatedUser(req))
+	require.Equal(t, "", getAuthenticatedUser(nil))
+}
+
 type mockProducer struct {
 }
 
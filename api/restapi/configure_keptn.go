@@ -30,6 +30,7 @@ import (
 //go:generate swagger generate server --target ../../api --name Keptn --spec ../swagger.yaml --principal models.Principal
 
 const envVarLogLevel = "LOG_LEVEL"
+const envVarUserHeader = "AUTH_USER_HEADER"
 
 type EnvConfig struct {
 	MaxAuthEnabled           bool    `envconfig:"MAX_AUTH_ENABLED" default:"true"`
@@ -120,6 +121,10 @@ func configureServer(s *http.Server, scheme, addr string) {
 			log.SetLevel(logLevel)
 		}
 	}
+
+	if os.Getenv(envVarUserHeader) == "" {
+		log.Warnf("No user header provided by '%s' env var: the %s label is removed from all events", envVarUserHeader, handlers.UserLabel)
+	}
 }
 
 // The middleware configuration is for the handler executors. These do not apply to the swagger.json document.
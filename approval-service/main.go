@@ -3,15 +3,20 @@ package main
 import (
 	"context"
 	"keptn/approval-service/pkg/handler"
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/quorum"
 	"log"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/kelseyhightower/envconfig"
 	logger "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	keptncommon "github.com/keptn/go-utils/pkg/lib/keptn"
@@ -24,6 +29,14 @@ type envConfig struct {
 	// Port on which to listen for cloudevents
 	Port int    `envconfig:"RCV_PORT" default:"8080"`
 	Path string `envconfig:"RCV_PATH" default:"/"`
+	// EventBroker is the endpoint events are sent to
+	EventBroker string `envconfig:"EVENTBROKER" default:""`
+	// ReminderWebhookURL is the URL reminders for missing votes are sent to. If it is empty, reminders are logged
+	ReminderWebhookURL string `envconfig:"REMINDER_WEBHOOK_URL" default:""`
+	// QuorumCheckInterval is the interval in which deadlines and reminders of approvals requiring a quorum are checked
+	QuorumCheckInterval time.Duration `envconfig:"QUORUM_CHECK_INTERVAL" default:"30s"`
+	// Namespace is the namespace the ConfigMaps holding the pending approvals requiring a quorum are stored in
+	Namespace string `envconfig:"POD_NAMESPACE" default:"keptn"`
 }
 
 // Opaque key type used for graceful shutdown context value
@@ -62,12 +75,33 @@ func _main(args []string, env envConfig) int {
 	if err != nil {
 		log.Fatalf("failed to create client, %v", err)
 	}
-	log.Fatal(c.StartReceiver(ctx, gotEvent))
+
+	eventSender, err := keptnv2.NewHTTPEventSender(env.EventBroker)
+	if err != nil {
+		log.Fatalf("failed to create event sender, %v", err)
+	}
+	var notifier quorum.Notifier = quorum.LogNotifier{}
+	if env.ReminderWebhookURL != "" {
+		notifier = quorum.NewWebhookNotifier(env.ReminderWebhookURL)
+	}
+	kubeAPI, err := createKubeAPI()
+	if err != nil {
+		log.Fatalf("failed to create kubernetes client, %v", err)
+	}
+	tracker := quorum.NewTracker(notifier, quorum.WithStore(quorum.NewK8sStore(kubeAPI, env.Namespace)))
+	if err := tracker.Load(); err != nil {
+		logger.WithError(err).Error("could not load pending approvals")
+	}
+	go tracker.Run(ctx, env.QuorumCheckInterval, handler.NewOutcomeSender(eventSender))
+
+	log.Fatal(c.StartReceiver(ctx, func(ctx context.Context, event cloudevents.Event) error {
+		return gotEvent(ctx, event, tracker)
+	}))
 
 	return 0
 }
 
-func gotEvent(ctx context.Context, event cloudevents.Event) error {
+func gotEvent(ctx context.Context, event cloudevents.Event, tracker *quorum.Tracker) error {
 	ctx.Value(gracefulShutdownKey).(*sync.WaitGroup).Add(1)
 	val := ctx.Value(gracefulShutdownKey)
 	if val != nil {
@@ -75,11 +109,11 @@ func gotEvent(ctx context.Context, event cloudevents.Event) error {
 			wg.Add(1)
 		}
 	}
-	go switchEvent(ctx, event)
+	go switchEvent(ctx, event, tracker)
 	return nil
 }
 
-func switchEvent(ctx context.Context, event cloudevents.Event) {
+func switchEvent(ctx context.Context, event cloudevents.Event, tracker *quorum.Tracker) {
 	defer func() {
 		logger.Info("Terminating Evaluate-SLI handler")
 		val := ctx.Value(gracefulShutdownKey)
@@ -98,7 +132,10 @@ func switchEvent(ctx context.Context, event cloudevents.Event) {
 	}
 
 	handlers := []handler.Handler{
-		handler.NewApprovalTriggeredEventHandler(keptnHandlerV2),
+		handler.NewApprovalTriggeredEventHandler(keptnHandlerV2, policy.NewResourceProvider(keptnHandlerV2.ResourceHandler), tracker),
+		handler.NewApprovalVoteEventHandler(tracker),
+		handler.NewApprovalFinishedEventHandler(tracker),
+		handler.NewSequenceFinishedEventHandler(tracker),
 	}
 
 	unhandled := true
@@ -114,6 +151,14 @@ func switchEvent(ctx context.Context, event cloudevents.Event) {
 	}
 }
 
+func createKubeAPI() (*kubernetes.Clientset, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
 func getGracefulContext() context.Context {
 
 	ch := make(chan os.Signal)
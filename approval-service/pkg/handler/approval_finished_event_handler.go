@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"keptn/approval-service/pkg/quorum"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	logger "github.com/sirupsen/logrus"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// ApprovalFinishedEventHandler stops collecting the votes of an approval requiring a quorum once its task has been finished.
+// Approvals requiring a quorum can only be decided by their votes: the shipyard-controller rejects approval.finished events
+// for such approvals sent by someone else, e.g. via the CLI or the Bridge, so these events do not stop the collection of votes
+type ApprovalFinishedEventHandler struct {
+	tracker *quorum.Tracker
+}
+
+// NewApprovalFinishedEventHandler returns a new approval.finished event handler
+func NewApprovalFinishedEventHandler(tracker *quorum.Tracker) *ApprovalFinishedEventHandler {
+	return &ApprovalFinishedEventHandler{tracker: tracker}
+}
+
+// IsTypeHandled godoc
+func (a *ApprovalFinishedEventHandler) IsTypeHandled(event cloudevents.Event) bool {
+	return event.Type() == keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName)
+}
+
+// Handle godoc
+func (a *ApprovalFinishedEventHandler) Handle(event cloudevents.Event, keptnHandler *keptnv2.Keptn) {
+	triggeredID, _ := event.Extensions()["triggeredid"].(string)
+	if triggeredID == "" || !a.tracker.IsPending(triggeredID) {
+		return
+	}
+	if event.Source() != serviceName {
+		logger.Warnf("Ignoring approval.finished event for approval %s sent by %s: the approval requires a quorum and is only decided by its votes",
+			triggeredID, event.Source())
+		return
+	}
+	if a.tracker.Remove(triggeredID) {
+		logger.Infof("Stopped collecting votes for approval %s: the approval has been finished", triggeredID)
+	}
+}
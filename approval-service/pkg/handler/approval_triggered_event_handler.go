@@ -1,20 +1,43 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/quorum"
+	"strings"
+	"time"
+
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	logger "github.com/sirupsen/logrus"
 
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
+// ApprovalQuorumStartedEventData is the data of the approval.started event of an approval requiring a quorum
+type ApprovalQuorumStartedEventData struct {
+	keptnv2.ApprovalStartedEventData
+	Quorum ApprovalQuorumStatus `json:"quorum"`
+}
+
+// ApprovalQuorumStatus marks an approval which is decided by the votes of a quorum, according to the given approval policy rule
+type ApprovalQuorumStatus struct {
+	Required bool   `json:"required"`
+	Rule     string `json:"rule"`
+}
+
 type ApprovalTriggeredEventHandler struct {
-	keptn *keptnv2.Keptn
+	keptn          *keptnv2.Keptn
+	policyProvider policy.Provider
+	tracker        *quorum.Tracker
+	now            func() time.Time
 }
 
-// NewApprovalTriggeredEventHandler returns a new approval.triggered event handler
-func NewApprovalTriggeredEventHandler(keptn *keptnv2.Keptn) *ApprovalTriggeredEventHandler {
-	return &ApprovalTriggeredEventHandler{keptn: keptn}
+// NewApprovalTriggeredEventHandler returns a new approval.triggered event handler.
+// If a policy provider is given, approvals are decided by the approval policy before falling back to the approval strategy.
+// Approvals requiring a quorum are passed to the tracker, which collects their votes
+func NewApprovalTriggeredEventHandler(keptn *keptnv2.Keptn, policyProvider policy.Provider, tracker *quorum.Tracker) *ApprovalTriggeredEventHandler {
+	return &ApprovalTriggeredEventHandler{keptn: keptn, policyProvider: policyProvider, tracker: tracker, now: time.Now}
 }
 
 // IsTypeHandled godoc
@@ -39,6 +62,51 @@ func getResult(data keptnv2.ApprovalTriggeredEventData, event cloudevents.Event)
 	return ""
 }
 
+// getScore returns the score of the evaluation preceding the approval, if there was one
+func getScore(event cloudevents.Event) *float64 {
+	evaluationData := &struct {
+		Evaluation *struct {
+			Score *float64 `json:"score"`
+		} `json:"evaluation"`
+	}{}
+	if err := event.DataAs(evaluationData); err != nil || evaluationData.Evaluation == nil {
+		return nil
+	}
+	return evaluationData.Evaluation.Score
+}
+
+// policyDecision is the decision of the approval policy, or the error which prevented the policy from being evaluated
+type policyDecision struct {
+	*policy.Decision
+	err error
+}
+
+// evaluatePolicy returns the decision of the approval policy. It returns nil if there is no policy, or no rule of the policy matches
+func (a *ApprovalTriggeredEventHandler) evaluatePolicy(data keptnv2.ApprovalTriggeredEventData, event cloudevents.Event) *policyDecision {
+	if a.policyProvider == nil || data.Result == keptnv2.ResultFailed {
+		return nil
+	}
+	approvalPolicy, err := a.policyProvider.GetPolicy(data.Project, data.Stage, data.Service)
+	if errors.Is(err, policy.ErrPolicyNotFound) {
+		return nil
+	} else if err != nil {
+		logger.WithError(err).Error("could not evaluate approval policy")
+		return &policyDecision{err: err}
+	}
+	decision := approvalPolicy.Evaluate(policy.Input{
+		Stage:   data.Stage,
+		Service: data.Service,
+		Labels:  data.Labels,
+		Result:  data.Result,
+		Score:   getScore(event),
+		Time:    a.now(),
+	})
+	if decision == nil {
+		return nil
+	}
+	return &policyDecision{Decision: decision}
+}
+
 // Handle godoc
 func (a *ApprovalTriggeredEventHandler) Handle(event cloudevents.Event, keptnHandler *keptnv2.Keptn) {
 	data := &keptnv2.ApprovalTriggeredEventData{}
@@ -50,14 +118,18 @@ func (a *ApprovalTriggeredEventHandler) Handle(event cloudevents.Event, keptnHan
 	// handle the case of no result being present (see https://github.com/keptn/keptn/issues/4391)
 	data.Result = getResult(*data, event)
 
-	outgoingEvents := a.handleApprovalTriggeredEvent(*data, event.Context.GetID(), keptnHandler.KeptnContext)
+	outgoingEvents := a.handleApprovalTriggeredEvent(*data, a.evaluatePolicy(*data, event), event.Context.GetID(), keptnHandler.KeptnContext)
 	sendEvents(keptnHandler, outgoingEvents)
 }
 
 func (a *ApprovalTriggeredEventHandler) handleApprovalTriggeredEvent(inputEvent keptnv2.ApprovalTriggeredEventData,
-	triggeredID, shkeptncontext string) []cloudevents.Event {
+	decision *policyDecision, triggeredID, shkeptncontext string) []cloudevents.Event {
 	outgoingEvents := make([]cloudevents.Event, 0)
 
+	if decision != nil {
+		return append(outgoingEvents, a.handlePolicyDecision(inputEvent, decision, triggeredID, shkeptncontext)...)
+	}
+
 	startedEvent := a.getApprovalStartedEvent(inputEvent, triggeredID, shkeptncontext)
 	outgoingEvents = append(outgoingEvents, *startedEvent)
 
@@ -67,20 +139,106 @@ func (a *ApprovalTriggeredEventHandler) handleApprovalTriggeredEvent(inputEvent
 		logger.Info(fmt.Sprintf("Automatically approve release of service %s of project %s and current stage %s",
 			inputEvent.Service, inputEvent.Project, inputEvent.Stage))
 
-		finishedEvent := a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultPass, triggeredID, shkeptncontext)
+		finishedEvent := a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultPass, "", triggeredID, shkeptncontext)
 		outgoingEvents = append(outgoingEvents, *finishedEvent)
 	} else if inputEvent.Result == keptnv2.ResultFailed {
 		// Handle case if an ApprovalTriggered event was sent even the evaluation result is failed
 		logger.Info(fmt.Sprintf("Disapprove release of service %s of project %s and current stage %s because"+
 			"the previous step failed", inputEvent.Service, inputEvent.Project, inputEvent.Stage))
-		finishedEvent := a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultFailed, triggeredID, shkeptncontext)
+		finishedEvent := a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultFailed, "", triggeredID, shkeptncontext)
 		outgoingEvents = append(outgoingEvents, *finishedEvent)
 	}
 
 	return outgoingEvents
 }
 
+// handlePolicyDecision returns the events for an approval decided by the approval policy.
+// If the policy could not be evaluated, the approval needs to be handled manually
+func (a *ApprovalTriggeredEventHandler) handlePolicyDecision(inputEvent keptnv2.ApprovalTriggeredEventData,
+	decision *policyDecision, triggeredID, shkeptncontext string) []cloudevents.Event {
+	if decision.err != nil {
+		message := fmt.Sprintf("Could not evaluate approval policy: %s. Approval needs to be handled manually", decision.err.Error())
+		return []cloudevents.Event{*a.getApprovalStartedEventWithMessage(inputEvent, message, triggeredID, shkeptncontext)}
+	}
+
+	if decision.Type == policy.RequireQuorum {
+		return []cloudevents.Event{*a.trackQuorum(inputEvent, decision, triggeredID, shkeptncontext)}
+	}
+
+	message := fmt.Sprintf("Approval policy rule '%s' for result '%s': %s", decision.Rule, string(inputEvent.Result), decision.Type)
+	outgoingEvents := []cloudevents.Event{*a.getApprovalStartedEventWithMessage(inputEvent, message, triggeredID, shkeptncontext)}
+
+	switch decision.Type {
+	case policy.Approve:
+		logger.Infof("Approve release of service %s of project %s and current stage %s by approval policy rule %s",
+			inputEvent.Service, inputEvent.Project, inputEvent.Stage, decision.Rule)
+		message := fmt.Sprintf("Approved by approval policy rule '%s'", decision.Rule)
+		outgoingEvents = append(outgoingEvents, *a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultPass, message, triggeredID, shkeptncontext))
+	case policy.Reject:
+		logger.Infof("Reject release of service %s of project %s and current stage %s by approval policy rule %s",
+			inputEvent.Service, inputEvent.Project, inputEvent.Stage, decision.Rule)
+		message := fmt.Sprintf("Rejected by approval policy rule '%s'", decision.Rule)
+		outgoingEvents = append(outgoingEvents, *a.getApprovalFinishedEvent(inputEvent, keptnv2.ResultFailed, message, triggeredID, shkeptncontext))
+	}
+	return outgoingEvents
+}
+
+// trackQuorum passes the approval to the tracker, and returns the .started event describing the required votes
+func (a *ApprovalTriggeredEventHandler) trackQuorum(inputEvent keptnv2.ApprovalTriggeredEventData,
+	decision *policyDecision, triggeredID, shkeptncontext string) *cloudevents.Event {
+	if a.tracker == nil {
+		message := fmt.Sprintf("Approval policy rule '%s' requires a quorum, but votes are not collected. Approval needs to be handled manually", decision.Rule)
+		return a.getApprovalStartedEventWithMessage(inputEvent, message, triggeredID, shkeptncontext)
+	}
+
+	now := a.now()
+	a.tracker.Add(quorum.PendingApproval{
+		TriggeredID:  triggeredID,
+		KeptnContext: shkeptncontext,
+		EventData:    inputEvent.EventData,
+		Rule:         decision.Rule,
+		Quorum:       *decision.Quorum,
+	}, now)
+
+	var groups []string
+	for _, group := range decision.Quorum.Groups {
+		groups = append(groups, fmt.Sprintf("%d of %d from %s", group.Required, len(group.Members), group.Name))
+	}
+	message := fmt.Sprintf("Approval policy rule '%s' for result '%s' requires approvals: %s", decision.Rule, string(inputEvent.Result), strings.Join(groups, ", "))
+	if deadline := decision.Quorum.GetDeadline(); deadline > 0 {
+		message += fmt.Sprintf(". Approval is rejected after %s", now.Add(deadline).UTC().Format(time.RFC3339))
+	}
+	logger.Infof("Collecting votes for approval %s of service %s of project %s and current stage %s according to approval policy rule %s",
+		triggeredID, inputEvent.Service, inputEvent.Project, inputEvent.Stage, decision.Rule)
+	return a.getApprovalQuorumStartedEvent(inputEvent, message, decision.Rule, triggeredID, shkeptncontext)
+}
+
+// getApprovalQuorumStartedEvent returns the .started event of an approval requiring a quorum. The shipyard-controller
+// only accepts the approval.finished event of such an approval from the approval-service, i.e. once the votes decided it
+func (a *ApprovalTriggeredEventHandler) getApprovalQuorumStartedEvent(inputEvent keptnv2.ApprovalTriggeredEventData, message, rule, triggeredID, shkeptncontext string) *cloudevents.Event {
+	approvalStartedEvent := ApprovalQuorumStartedEventData{
+		ApprovalStartedEventData: keptnv2.ApprovalStartedEventData{
+			EventData: keptnv2.EventData{
+				Project: inputEvent.Project,
+				Stage:   inputEvent.Stage,
+				Service: inputEvent.Service,
+				Labels:  inputEvent.Labels,
+				Status:  keptnv2.StatusSucceeded,
+				Message: message,
+			},
+		},
+		Quorum: ApprovalQuorumStatus{Required: true, Rule: rule},
+	}
+
+	return getCloudEvent(approvalStartedEvent, keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName), shkeptncontext, triggeredID)
+}
+
 func (a *ApprovalTriggeredEventHandler) getApprovalStartedEvent(inputEvent keptnv2.ApprovalTriggeredEventData, triggeredID, shkeptncontext string) *cloudevents.Event {
+	message := fmt.Sprintf("Approval strategy for result '%s': %s", string(inputEvent.Result), getApprovalStrategyForEvent(inputEvent))
+	return a.getApprovalStartedEventWithMessage(inputEvent, message, triggeredID, shkeptncontext)
+}
+
+func (a *ApprovalTriggeredEventHandler) getApprovalStartedEventWithMessage(inputEvent keptnv2.ApprovalTriggeredEventData, message, triggeredID, shkeptncontext string) *cloudevents.Event {
 	approvalStartedEvent := keptnv2.ApprovalStartedEventData{
 		EventData: keptnv2.EventData{
 			Project: inputEvent.Project,
@@ -88,7 +246,7 @@ func (a *ApprovalTriggeredEventHandler) getApprovalStartedEvent(inputEvent keptn
 			Service: inputEvent.Service,
 			Labels:  inputEvent.Labels,
 			Status:  keptnv2.StatusSucceeded,
-			Message: fmt.Sprintf("Approval strategy for result '%s': %s", string(inputEvent.Result), getApprovalStrategyForEvent(inputEvent)),
+			Message: message,
 		},
 	}
 
@@ -96,16 +254,20 @@ func (a *ApprovalTriggeredEventHandler) getApprovalStartedEvent(inputEvent keptn
 }
 
 func (a *ApprovalTriggeredEventHandler) getApprovalFinishedEvent(inputEvent keptnv2.ApprovalTriggeredEventData,
-	result keptnv2.ResultType, triggeredID, shkeptncontext string) *cloudevents.Event {
+	result keptnv2.ResultType, message, triggeredID, shkeptncontext string) *cloudevents.Event {
+	return getApprovalFinishedEvent(inputEvent.EventData, result, message, triggeredID, shkeptncontext)
+}
+
+func getApprovalFinishedEvent(eventData keptnv2.EventData, result keptnv2.ResultType, message, triggeredID, shkeptncontext string) *cloudevents.Event {
 	approvalFinishedEvent := keptnv2.ApprovalFinishedEventData{
 		EventData: keptnv2.EventData{
-			Project: inputEvent.Project,
-			Stage:   inputEvent.Stage,
-			Service: inputEvent.Service,
-			Labels:  inputEvent.Labels,
+			Project: eventData.Project,
+			Stage:   eventData.Stage,
+			Service: eventData.Service,
+			Labels:  eventData.Labels,
 			Status:  keptnv2.StatusSucceeded,
 			Result:  result,
-			Message: "",
+			Message: message,
 		},
 	}
 
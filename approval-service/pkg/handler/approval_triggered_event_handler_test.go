@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/policy/fake"
 	"testing"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/require"
 
 	keptnevents "github.com/keptn/go-utils/pkg/lib"
 	"github.com/keptn/go-utils/pkg/lib/keptn"
@@ -160,8 +165,8 @@ func TestHandleApprovalTriggeredEvent(t *testing.T) {
 			ce := cloudevents.NewEvent()
 			ce.SetData(cloudevents.ApplicationJSON, tt.inputEvent)
 			keptnHandler, _ := keptnv2.NewKeptn(&ce, keptn.KeptnOpts{})
-			e := NewApprovalTriggeredEventHandler(keptnHandler)
-			res := e.handleApprovalTriggeredEvent(tt.inputEvent, eventID, shkeptncontext)
+			e := NewApprovalTriggeredEventHandler(keptnHandler, nil, nil)
+			res := e.handleApprovalTriggeredEvent(tt.inputEvent, nil, eventID, shkeptncontext)
 			if len(res) != len(tt.outputEvent) {
 				t.Errorf("got %d output event, want %v output events for %s",
 					len(res), len(tt.outputEvent), tt.name)
@@ -179,3 +184,105 @@ func TestHandleApprovalTriggeredEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleApprovalTriggeredEvent_Policy(t *testing.T) {
+	approvalPolicy := &policy.Policy{Spec: policy.PolicySpec{Rules: []policy.Rule{
+		{Name: "no-friday-deployments", Decision: policy.Reject, TimeWindows: []policy.TimeWindow{{From: "00:00", To: "00:00", Days: []string{"Fri"}}}},
+		{Name: "release-managers", Decision: policy.Approve, TriggeredBy: []string{"alice"}},
+		{Name: "high-score", Decision: policy.Approve, Score: &policy.ScoreRange{Min: getFloatPtr(90)}},
+		{Name: "critical-services", Decision: policy.Manual, Services: []string{"carts"}},
+	}}}
+	monday := time.Date(2022, 4, 25, 10, 0, 0, 0, time.UTC)
+	friday := time.Date(2022, 4, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		inputEvent   keptnv2.ApprovalTriggeredEventData
+		score        *float64
+		now          time.Time
+		providerErr  error
+		wantStarted  string
+		wantFinished *keptnv2.ApprovalFinishedEventData
+	}{
+		{
+			name:        "rejected by time window",
+			inputEvent:  getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic),
+			now:         friday,
+			wantStarted: "Approval policy rule 'no-friday-deployments' for result 'pass': reject",
+			wantFinished: getApprovalFinishedTestDataWithMessage(keptnv2.ResultFailed, keptnv2.StatusSucceeded,
+				"Rejected by approval policy rule 'no-friday-deployments'"),
+		},
+		{
+			name:        "approved by score",
+			inputEvent:  getApprovalTriggeredTestData(keptnv2.ResultWarning, keptnv2.ApprovalManual, keptnv2.ApprovalManual),
+			score:       getFloatPtr(92.5),
+			now:         monday,
+			wantStarted: "Approval policy rule 'high-score' for result 'warning': approve",
+			wantFinished: getApprovalFinishedTestDataWithMessage(keptnv2.ResultPass, keptnv2.StatusSucceeded,
+				"Approved by approval policy rule 'high-score'"),
+		},
+		{
+			name:        "manual approval required by policy",
+			inputEvent:  getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic),
+			score:       getFloatPtr(50),
+			now:         monday,
+			wantStarted: "Approval policy rule 'critical-services' for result 'pass': manual",
+		},
+		{
+			name:        "policy could not be read",
+			inputEvent:  getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic),
+			now:         monday,
+			providerErr: errors.New("connection refused"),
+			wantStarted: "Could not evaluate approval policy: connection refused. Approval needs to be handled manually",
+		},
+		{
+			name:         "no policy",
+			inputEvent:   getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic),
+			now:          monday,
+			providerErr:  policy.ErrPolicyNotFound,
+			wantStarted:  "Approval strategy for result 'pass': automatic",
+			wantFinished: getApprovalFinishedTestDataWithMessage(keptnv2.ResultPass, keptnv2.StatusSucceeded, ""),
+		},
+		{
+			name:         "failed results are always rejected",
+			inputEvent:   getApprovalTriggeredTestData(keptnv2.ResultFailed, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic),
+			score:        getFloatPtr(95),
+			now:          monday,
+			wantStarted:  "Approval strategy for result 'fail': automatic",
+			wantFinished: getApprovalFinishedTestDataWithMessage(keptnv2.ResultFailed, keptnv2.StatusSucceeded, ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyProvider := &fake.ProviderMock{
+				GetPolicyFunc: func(project string, stage string, service string) (*policy.Policy, error) {
+					if tt.providerErr != nil {
+						return nil, tt.providerErr
+					}
+					return approvalPolicy, nil
+				},
+			}
+			data := map[string]interface{}{}
+			require.Nil(t, keptnv2.Decode(tt.inputEvent, &data))
+			if tt.score != nil {
+				data["evaluation"] = map[string]interface{}{"score": *tt.score}
+			}
+			ce := cloudevents.NewEvent()
+			require.Nil(t, ce.SetData(cloudevents.ApplicationJSON, data))
+
+			e := NewApprovalTriggeredEventHandler(nil, policyProvider, nil)
+			e.now = func() time.Time { return tt.now }
+			res := e.handleApprovalTriggeredEvent(tt.inputEvent, e.evaluatePolicy(tt.inputEvent, ce), eventID, shkeptncontext)
+
+			expected := []cloudevents.Event{*getCloudEvent(getApprovalStartedTestData(keptnv2.StatusSucceeded, tt.wantStarted),
+				keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID)}
+			if tt.wantFinished != nil {
+				expected = append(expected, *getCloudEvent(*tt.wantFinished, keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID))
+			}
+			require.Len(t, res, len(expected))
+			for i := range res {
+				require.True(t, compareEventContext(res[i], expected[i]), "got %s, want %s", string(res[i].Data()), string(expected[i].Data()))
+			}
+		})
+	}
+}
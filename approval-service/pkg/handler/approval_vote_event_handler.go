@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/quorum"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	logger "github.com/sirupsen/logrus"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// ApprovalVoteEventType is the type of the events used to vote on approvals requiring a quorum
+const ApprovalVoteEventType = "sh.keptn.event.approval.vote"
+
+const (
+	// VoteApprove is the decision of a vote approving the approval
+	VoteApprove = "approve"
+	// VoteReject is the decision of a vote rejecting the approval
+	VoteReject = "reject"
+)
+
+// ApprovalVoteEventData is the data of an approval.vote event. The triggeredid of the event refers to the approval.triggered event
+type ApprovalVoteEventData struct {
+	keptnv2.EventData
+	Vote Vote `json:"vote"`
+}
+
+// Vote contains the decision of the voter, i.e. approve or reject.
+// The voter is the user the Keptn API has authenticated, which it passes in the label triggeredBy of the event.
+// If the voter is provided in the vote as well, it has to match the authenticated user
+type Vote struct {
+	Voter    string `json:"voter,omitempty"`
+	Decision string `json:"decision"`
+}
+
+type ApprovalVoteEventHandler struct {
+	tracker *quorum.Tracker
+}
+
+// NewApprovalVoteEventHandler returns a new approval.vote event handler
+func NewApprovalVoteEventHandler(tracker *quorum.Tracker) *ApprovalVoteEventHandler {
+	return &ApprovalVoteEventHandler{tracker: tracker}
+}
+
+// IsTypeHandled godoc
+func (a *ApprovalVoteEventHandler) IsTypeHandled(event cloudevents.Event) bool {
+	return event.Type() == ApprovalVoteEventType
+}
+
+// Handle godoc
+func (a *ApprovalVoteEventHandler) Handle(event cloudevents.Event, keptnHandler *keptnv2.Keptn) {
+	sendEvents(keptnHandler, a.handleApprovalVoteEvent(event))
+}
+
+func (a *ApprovalVoteEventHandler) handleApprovalVoteEvent(event cloudevents.Event) []cloudevents.Event {
+	data := &ApprovalVoteEventData{}
+	if err := event.DataAs(data); err != nil {
+		logger.WithError(err).Error("failed to parse ApprovalVoteEventData")
+		return nil
+	}
+	triggeredID, _ := event.Extensions()["triggeredid"].(string)
+	if triggeredID == "" {
+		logger.Error("approval.vote events need a triggeredid")
+		return nil
+	}
+	voter := data.Labels[policy.UserLabel]
+	if voter == "" {
+		logger.Errorf("Rejecting vote for approval %s: approval.vote events need to be sent by a user authenticated by the Keptn API", triggeredID)
+		return nil
+	}
+	if data.Vote.Voter != "" && data.Vote.Voter != voter {
+		logger.Errorf("Rejecting vote for approval %s: voter %s does not match the authenticated user %s", triggeredID, data.Vote.Voter, voter)
+		return nil
+	}
+	decision := strings.ToLower(data.Vote.Decision)
+	if decision != VoteApprove && decision != VoteReject {
+		logger.Errorf("unsupported decision %s of voter %s", data.Vote.Decision, voter)
+		return nil
+	}
+
+	outcome, err := a.tracker.Vote(triggeredID, voter, decision == VoteApprove)
+	if err != nil {
+		logger.WithError(err).Errorf("could not record vote of %s for approval %s", voter, triggeredID)
+		return nil
+	}
+	logger.Infof("Recorded vote %s of %s for approval %s", decision, voter, triggeredID)
+	if outcome == nil {
+		return nil
+	}
+	return []cloudevents.Event{*getOutcomeEvent(*outcome)}
+}
+
+// getOutcomeEvent returns the approval.finished event for an approval decided by its quorum
+func getOutcomeEvent(outcome quorum.Outcome) *cloudevents.Event {
+	approval := outcome.Approval
+	return getApprovalFinishedEvent(approval.EventData, outcome.Result, outcome.Message, approval.TriggeredID, approval.KeptnContext)
+}
+
+// NewOutcomeSender returns a function sending the approval.finished events of approvals decided by the tracker, e.g. because of expired deadlines
+func NewOutcomeSender(eventSender EventSender) func(outcome quorum.Outcome) {
+	return func(outcome quorum.Outcome) {
+		logger.Info(outcome.Message)
+		if err := eventSender.SendEvent(*getOutcomeEvent(outcome)); err != nil {
+			logger.WithError(err).Errorf("could not send approval.finished event for approval %s", outcome.Approval.TriggeredID)
+		}
+	}
+}
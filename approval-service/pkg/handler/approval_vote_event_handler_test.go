@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/policy/fake"
+	"keptn/approval-service/pkg/quorum"
+	quorumfake "keptn/approval-service/pkg/quorum/fake"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+// getApprovalVoteEvent returns a vote of the given user, who has been authenticated by the Keptn API
+func getApprovalVoteEvent(user, decision string) cloudevents.Event {
+	return getApprovalVoteEventWithVoter(user, "", decision)
+}
+
+func getApprovalVoteEventWithVoter(user, voter, decision string) cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	ce.SetType(ApprovalVoteEventType)
+	ce.SetExtension("shkeptncontext", shkeptncontext)
+	ce.SetExtension("triggeredid", eventID)
+	var labels map[string]string
+	if user != "" {
+		labels = map[string]string{policy.UserLabel: user}
+	}
+	_ = ce.SetData(cloudevents.ApplicationJSON, ApprovalVoteEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts", Labels: labels},
+		Vote:      Vote{Voter: voter, Decision: decision},
+	})
+	return ce
+}
+
+func TestHandleApprovalVoteEvent(t *testing.T) {
+	approvalPolicy := &policy.Policy{Spec: policy.PolicySpec{Rules: []policy.Rule{
+		{
+			Name:     "change-board",
+			Decision: policy.RequireQuorum,
+			Quorum: &policy.Quorum{
+				Groups:   []policy.ApprovalGroup{{Name: "cab", Members: []string{"alice", "bob", "carol"}, Required: 2}},
+				Deadline: "2h",
+			},
+		},
+	}}}
+	policyProvider := &fake.ProviderMock{
+		GetPolicyFunc: func(project string, stage string, service string) (*policy.Policy, error) {
+			return approvalPolicy, nil
+		},
+	}
+	tracker := quorum.NewTracker(&quorumfake.NotifierMock{})
+	now := time.Date(2022, 4, 25, 10, 0, 0, 0, time.UTC)
+
+	triggeredHandler := NewApprovalTriggeredEventHandler(nil, policyProvider, tracker)
+	triggeredHandler.now = func() time.Time { return now }
+	inputEvent := getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic)
+	ce := cloudevents.NewEvent()
+	require.Nil(t, ce.SetData(cloudevents.ApplicationJSON, inputEvent))
+
+	res := triggeredHandler.handleApprovalTriggeredEvent(inputEvent, triggeredHandler.evaluatePolicy(inputEvent, ce), eventID, shkeptncontext)
+	require.Len(t, res, 1)
+	expectedStarted := getCloudEvent(ApprovalQuorumStartedEventData{
+		ApprovalStartedEventData: getApprovalStartedTestData(keptnv2.StatusSucceeded,
+			"Approval policy rule 'change-board' for result 'pass' requires approvals: 2 of 3 from cab. Approval is rejected after 2022-04-25T12:00:00Z"),
+		Quorum: ApprovalQuorumStatus{Required: true, Rule: "change-board"},
+	}, keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID)
+	require.True(t, compareEventContext(res[0], *expectedStarted), string(res[0].Data()))
+
+	voteHandler := NewApprovalVoteEventHandler(tracker)
+	require.True(t, voteHandler.IsTypeHandled(getApprovalVoteEvent("alice", VoteApprove)))
+
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEvent("alice", VoteApprove)))
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEvent("mallory", VoteApprove)))
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEvent("bob", "maybe")))
+	// votes need to be sent by an authenticated user, who cannot vote on behalf of someone else
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEventWithVoter("", "bob", VoteApprove)))
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEventWithVoter("mallory", "bob", VoteApprove)))
+	require.True(t, tracker.IsPending(eventID))
+
+	res = voteHandler.handleApprovalVoteEvent(getApprovalVoteEvent("bob", "Approve"))
+	require.Len(t, res, 1)
+	expectedFinished := getCloudEvent(*getApprovalFinishedTestDataWithMessage(keptnv2.ResultPass, keptnv2.StatusSucceeded,
+		"Approved by approval policy rule 'change-board': cab (alice, bob)"),
+		keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID)
+	require.True(t, compareEventContext(res[0], *expectedFinished), string(res[0].Data()))
+
+	// the approval has already been decided
+	require.Empty(t, voteHandler.handleApprovalVoteEvent(getApprovalVoteEvent("carol", VoteReject)))
+}
+
+func TestHandleApprovalTriggeredEvent_QuorumWithoutTracker(t *testing.T) {
+	decision := &policyDecision{Decision: &policy.Decision{Type: policy.RequireQuorum, Rule: "change-board", Quorum: &policy.Quorum{}}}
+	inputEvent := getApprovalTriggeredTestData(keptnv2.ResultPass, keptnv2.ApprovalAutomatic, keptnv2.ApprovalAutomatic)
+
+	res := NewApprovalTriggeredEventHandler(nil, nil, nil).handleApprovalTriggeredEvent(inputEvent, decision, eventID, shkeptncontext)
+	require.Len(t, res, 1)
+	expectedStarted := getCloudEvent(getApprovalStartedTestData(keptnv2.StatusSucceeded,
+		"Approval policy rule 'change-board' requires a quorum, but votes are not collected. Approval needs to be handled manually"),
+		keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID)
+	require.True(t, compareEventContext(res[0], *expectedStarted), string(res[0].Data()))
+}
+
+func TestApprovalFinishedEventHandler(t *testing.T) {
+	tracker := quorum.NewTracker(&quorumfake.NotifierMock{})
+	tracker.Add(quorum.PendingApproval{TriggeredID: eventID, Quorum: policy.Quorum{
+		Groups: []policy.ApprovalGroup{{Name: "cab", Members: []string{"alice"}, Required: 1}},
+	}}, time.Now())
+
+	ce := cloudevents.NewEvent()
+	ce.SetType(keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName))
+	ce.SetSource("https://github.com/keptn/keptn/cli")
+	ce.SetExtension("triggeredid", eventID)
+
+	finishedHandler := NewApprovalFinishedEventHandler(tracker)
+	require.True(t, finishedHandler.IsTypeHandled(ce))
+	finishedHandler.Handle(ce, nil)
+
+	// the approval is only decided by its quorum
+	require.True(t, tracker.IsPending(eventID))
+	outcome, err := tracker.Vote(eventID, "alice", true)
+	require.Nil(t, err)
+	require.Equal(t, keptnv2.ResultPass, outcome.Result)
+}
+
+func TestApprovalFinishedEventHandler_RemovesFinishedApproval(t *testing.T) {
+	tracker := quorum.NewTracker(&quorumfake.NotifierMock{})
+	tracker.Add(quorum.PendingApproval{TriggeredID: eventID}, time.Now())
+
+	ce := cloudevents.NewEvent()
+	ce.SetType(keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName))
+	ce.SetSource("approval-service")
+	ce.SetExtension("triggeredid", eventID)
+
+	NewApprovalFinishedEventHandler(tracker).Handle(ce, nil)
+	require.False(t, tracker.IsPending(eventID))
+}
+
+func TestSequenceFinishedEventHandler(t *testing.T) {
+	tracker := quorum.NewTracker(&quorumfake.NotifierMock{})
+	tracker.Add(quorum.PendingApproval{TriggeredID: eventID, KeptnContext: shkeptncontext, EventData: keptnv2.EventData{Stage: "production"}}, time.Now())
+	tracker.Add(quorum.PendingApproval{TriggeredID: "other-id", KeptnContext: shkeptncontext, EventData: keptnv2.EventData{Stage: "staging"}}, time.Now())
+
+	ce := cloudevents.NewEvent()
+	ce.SetType("sh.keptn.event.production.delivery.finished")
+	ce.SetExtension("shkeptncontext", shkeptncontext)
+
+	sequenceHandler := NewSequenceFinishedEventHandler(tracker)
+	require.True(t, sequenceHandler.IsTypeHandled(ce))
+	require.False(t, sequenceHandler.IsTypeHandled(getApprovalVoteEvent("alice", VoteApprove)))
+	sequenceHandler.Handle(ce, nil)
+
+	require.False(t, tracker.IsPending(eventID))
+	require.True(t, tracker.IsPending("other-id"))
+}
+
+func TestNewOutcomeSender(t *testing.T) {
+	var sent []cloudevents.Event
+	sender := NewOutcomeSender(eventSenderFunc(func(event cloudevents.Event) error {
+		sent = append(sent, event)
+		return nil
+	}))
+	sender(quorum.Outcome{
+		Approval: quorum.PendingApproval{TriggeredID: eventID, KeptnContext: shkeptncontext, EventData: getApprovalTriggeredTestData("", "", "").EventData},
+		Result:   keptnv2.ResultFailed,
+		Message:  "Rejected by approval policy rule 'change-board': the deadline expired with 0 of 1 approvals from cab",
+	})
+
+	require.Len(t, sent, 1)
+	expectedFinished := getCloudEvent(*getApprovalFinishedTestDataWithMessage(keptnv2.ResultFailed, keptnv2.StatusSucceeded,
+		"Rejected by approval policy rule 'change-board': the deadline expired with 0 of 1 approvals from cab"),
+		keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName), shkeptncontext, eventID)
+	require.True(t, compareEventContext(sent[0], *expectedFinished), string(sent[0].Data()))
+}
+
+type eventSenderFunc func(event cloudevents.Event) error
+
+func (f eventSenderFunc) SendEvent(event cloudevents.Event) error {
+	return f(event)
+}
@@ -11,11 +11,19 @@ import (
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
+// serviceName is the source of the events sent by the approval-service
+const serviceName = "approval-service"
+
 type Handler interface {
 	IsTypeHandled(event cloudevents.Event) bool
 	Handle(event cloudevents.Event, keptnHandler *keptnv2.Keptn)
 }
 
+// EventSender sends events which are not a response to a received event
+type EventSender interface {
+	SendEvent(event cloudevents.Event) error
+}
+
 func sendEvents(keptnHandler *keptnv2.Keptn, events []cloudevents.Event) {
 	for _, outgoingEvent := range events {
 		err := keptnHandler.SendCloudEvent(outgoingEvent)
@@ -26,7 +34,7 @@ func sendEvents(keptnHandler *keptnv2.Keptn, events []cloudevents.Event) {
 }
 
 func getCloudEvent(data interface{}, ceType string, shkeptncontext string, triggeredID string) *cloudevents.Event {
-	source, _ := url.Parse("approval-service")
+	source, _ := url.Parse(serviceName)
 
 	extensions := map[string]interface{}{"shkeptncontext": shkeptncontext}
 	if triggeredID != "" {
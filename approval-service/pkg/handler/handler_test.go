@@ -24,6 +24,10 @@ func getPtr(s string) *string {
 	return &s
 }
 
+func getFloatPtr(f float64) *float64 {
+	return &f
+}
+
 func getShipyardWithoutApproval() keptnevents.Shipyard {
 	return keptnevents.Shipyard{
 		Stages: []struct {
@@ -94,6 +98,12 @@ func getApprovalFinishedTestData(result keptnv2.ResultType, status keptnv2.Statu
 	}
 }
 
+func getApprovalFinishedTestDataWithMessage(result keptnv2.ResultType, status keptnv2.StatusType, message string) *keptnv2.ApprovalFinishedEventData {
+	data := getApprovalFinishedTestData(result, status)
+	data.Message = message
+	return &data
+}
+
 func getApprovalStartedTestData(status keptnv2.StatusType, message string) keptnv2.ApprovalStartedEventData {
 	return keptnv2.ApprovalStartedEventData{
 		EventData: keptnv2.EventData{
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"keptn/approval-service/pkg/quorum"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	logger "github.com/sirupsen/logrus"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// SequenceFinishedEventHandler stops collecting the votes of the approvals of a sequence once the sequence has been finished,
+// e.g. because it has been aborted or timed out while waiting for the votes
+type SequenceFinishedEventHandler struct {
+	tracker *quorum.Tracker
+}
+
+// NewSequenceFinishedEventHandler returns a new handler for the .finished events of sequences
+func NewSequenceFinishedEventHandler(tracker *quorum.Tracker) *SequenceFinishedEventHandler {
+	return &SequenceFinishedEventHandler{tracker: tracker}
+}
+
+// IsTypeHandled godoc
+func (s *SequenceFinishedEventHandler) IsTypeHandled(event cloudevents.Event) bool {
+	return keptnv2.IsSequenceEventType(event.Type()) && keptnv2.IsFinishedEventType(event.Type())
+}
+
+// Handle godoc
+func (s *SequenceFinishedEventHandler) Handle(event cloudevents.Event, keptnHandler *keptnv2.Keptn) {
+	stage, sequence, _, err := keptnv2.ParseSequenceEventType(event.Type())
+	if err != nil {
+		logger.WithError(err).Error("could not parse sequence event type")
+		return
+	}
+	keptnContext, _ := event.Extensions()["shkeptncontext"].(string)
+	for _, triggeredID := range s.tracker.RemoveSequence(keptnContext, stage) {
+		logger.Infof("Stopped collecting votes for approval %s: sequence %s in stage %s has been finished", triggeredID, sequence, stage)
+	}
+}
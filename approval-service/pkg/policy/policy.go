@@ -0,0 +1,361 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceName is the name of the Keptn resource containing the approval policy
+const ResourceName = "approval-policy.yaml"
+
+// UserLabel is the label of the event containing the user who triggered the sequence.
+// The Keptn API sets it to the user authenticated by the API gateway, replacing any value provided by the client
+const UserLabel = "triggeredBy"
+
+const specVersion = "spec.keptn.sh/0.1.0"
+const kind = "ApprovalPolicy"
+
+const timeOfDayLayout = "15:04"
+
+// DecisionType defines how an approval is handled if a rule matches
+type DecisionType string
+
+const (
+	// Approve automatically approves the approval
+	Approve DecisionType = "approve"
+	// Reject automatically rejects the approval
+	Reject DecisionType = "reject"
+	// Manual requires the approval to be handled by a user
+	Manual DecisionType = "manual"
+	// RequireQuorum collects votes until the quorum of the rule is met
+	RequireQuorum DecisionType = "quorum"
+)
+
+// Policy is the in-memory representation of an approval-policy.yaml file
+type Policy struct {
+	ApiVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Spec       PolicySpec `yaml:"spec"`
+}
+
+// PolicySpec contains the rules of the policy. The rules are evaluated in their order, and the first matching rule makes the decision
+type PolicySpec struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule makes a decision for all approvals which fulfill all conditions of the rule. Conditions which are not set match all approvals
+type Rule struct {
+	Name     string       `yaml:"name"`
+	Decision DecisionType `yaml:"decision"`
+	// Stages contains the names of the stages the rule applies to
+	Stages []string `yaml:"stages,omitempty"`
+	// Services contains the names of the services the rule applies to
+	Services []string `yaml:"services,omitempty"`
+	// Labels contains labels which the event needs to have
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// TriggeredBy contains the users the rule applies to. The user is taken from the label triggeredBy of the event
+	TriggeredBy []string `yaml:"triggeredBy,omitempty"`
+	// Results contains the results of the previous task the rule applies to, e.g. pass or warning
+	Results []string `yaml:"results,omitempty"`
+	// Score restricts the score of the evaluation preceding the approval
+	Score *ScoreRange `yaml:"score,omitempty"`
+	// TimeWindows restricts the rule to the given times. The rule applies if any of the windows contains the time of the approval
+	TimeWindows []TimeWindow `yaml:"timeWindows,omitempty"`
+	// Quorum defines the votes required for approving, if the decision is quorum
+	Quorum *Quorum `yaml:"quorum,omitempty"`
+}
+
+// Quorum requires approvals from each of the groups. An approval is rejected as soon as one of the groups cannot reach its required approvals anymore
+type Quorum struct {
+	Groups []ApprovalGroup `yaml:"groups"`
+	// Deadline is the duration after which the approval is rejected, e.g. 24h. Default: no deadline
+	Deadline string `yaml:"deadline,omitempty"`
+	// ReminderInterval is the interval in which reminders are sent while votes are missing, e.g. 4h. Default: no reminders
+	ReminderInterval string `yaml:"reminderInterval,omitempty"`
+}
+
+// ApprovalGroup is a group of users of which the given number needs to approve
+type ApprovalGroup struct {
+	Name     string   `yaml:"name"`
+	Members  []string `yaml:"members"`
+	Required int      `yaml:"required"`
+}
+
+// GetDeadline returns the duration after which the approval is rejected, or 0 if there is no deadline
+func (q Quorum) GetDeadline() time.Duration {
+	d, _ := parseDuration(q.Deadline)
+	return d
+}
+
+// GetReminderInterval returns the interval of reminders, or 0 if no reminders are sent
+func (q Quorum) GetReminderInterval() time.Duration {
+	d, _ := parseDuration(q.ReminderInterval)
+	return d
+}
+
+func parseDuration(d string) (time.Duration, error) {
+	if d == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(d)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %s: %w", d, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %s: must be positive", d)
+	}
+	return duration, nil
+}
+
+func (q *Quorum) validate() error {
+	if q == nil || len(q.Groups) == 0 {
+		return errors.New("decision quorum needs at least one group")
+	}
+	names := map[string]bool{}
+	for _, group := range q.Groups {
+		if group.Name == "" {
+			return errors.New("group needs a name")
+		}
+		if names[group.Name] {
+			return fmt.Errorf("group %s is defined more than once", group.Name)
+		}
+		names[group.Name] = true
+		if group.Required < 1 || group.Required > len(group.Members) {
+			return fmt.Errorf("group %s: required approvals must be between 1 and the number of members", group.Name)
+		}
+	}
+	if _, err := parseDuration(q.Deadline); err != nil {
+		return fmt.Errorf("deadline: %w", err)
+	}
+	if _, err := parseDuration(q.ReminderInterval); err != nil {
+		return fmt.Errorf("reminderInterval: %w", err)
+	}
+	return nil
+}
+
+// ScoreRange is an inclusive range of evaluation scores
+type ScoreRange struct {
+	Min *float64 `yaml:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty"`
+}
+
+// TimeWindow is a time of the day, optionally restricted to certain days of the week
+type TimeWindow struct {
+	// From is the start of the window, e.g. 16:00
+	From string `yaml:"from"`
+	// To is the (exclusive) end of the window, e.g. 23:00. Windows ending before they start span midnight,
+	// windows with the same start and end span the whole day
+	To string `yaml:"to"`
+	// Days restricts the window to the given weekdays, e.g. Fri
+	Days []string `yaml:"days,omitempty"`
+	// Timezone of the window, e.g. Europe/Vienna. Default: UTC
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// Input contains the properties of an approval which are evaluated by the rules
+type Input struct {
+	Stage   string
+	Service string
+	Labels  map[string]string
+	Result  keptnv2.ResultType
+	// Score is the score of the preceding evaluation, if there was one
+	Score *float64
+	Time  time.Time
+}
+
+// Decision is the outcome of evaluating a policy
+type Decision struct {
+	Type DecisionType
+	Rule string
+	// Quorum is set for decisions of the type quorum
+	Quorum *Quorum
+}
+
+// Parse returns the policy contained in the given content, and validates it
+func Parse(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("could not parse approval policy: %w", err)
+	}
+	if policy.ApiVersion != specVersion || policy.Kind != kind {
+		return nil, fmt.Errorf("approval policy must have the apiVersion %s and the kind %s", specVersion, kind)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("approval policy is invalid: %w", err)
+	}
+	return policy, nil
+}
+
+// Validate checks the decisions and conditions of the rules
+func (p *Policy) Validate() error {
+	names := map[string]bool{}
+	for i, rule := range p.Spec.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d needs a name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %s is defined more than once", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Decision != Approve && rule.Decision != Reject && rule.Decision != Manual && rule.Decision != RequireQuorum {
+			return fmt.Errorf("rule %s: unsupported decision %s", rule.Name, rule.Decision)
+		}
+		if rule.Decision == RequireQuorum {
+			if err := rule.Quorum.validate(); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+		} else if rule.Quorum != nil {
+			return fmt.Errorf("rule %s: quorum can only be used with the decision quorum", rule.Name)
+		}
+		if rule.Score != nil && rule.Score.Min != nil && rule.Score.Max != nil && *rule.Score.Min > *rule.Score.Max {
+			return fmt.Errorf("rule %s: minimum score must not be greater than maximum score", rule.Name)
+		}
+		for _, window := range rule.TimeWindows {
+			if _, err := window.contains(time.Now()); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+			for _, d := range window.Days {
+				if _, err := parseWeekday(d); err != nil {
+					return fmt.Errorf("rule %s: %w", rule.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the decision of the first rule matching the input, or nil if no rule matches
+func (p *Policy) Evaluate(input Input) *Decision {
+	for _, rule := range p.Spec.Rules {
+		if rule.matches(input) {
+			return &Decision{Type: rule.Decision, Rule: rule.Name, Quorum: rule.Quorum}
+		}
+	}
+	return nil
+}
+
+func (r Rule) matches(input Input) bool {
+	if len(r.Stages) > 0 && !contains(r.Stages, input.Stage) {
+		return false
+	}
+	if len(r.Services) > 0 && !contains(r.Services, input.Service) {
+		return false
+	}
+	for key, value := range r.Labels {
+		if input.Labels[key] != value {
+			return false
+		}
+	}
+	if len(r.TriggeredBy) > 0 && !contains(r.TriggeredBy, input.Labels[UserLabel]) {
+		return false
+	}
+	if len(r.Results) > 0 && !contains(r.Results, string(input.Result)) {
+		return false
+	}
+	if r.Score != nil {
+		if input.Score == nil ||
+			r.Score.Min != nil && *input.Score < *r.Score.Min ||
+			r.Score.Max != nil && *input.Score > *r.Score.Max {
+			return false
+		}
+	}
+	if len(r.TimeWindows) > 0 {
+		inWindow := false
+		for _, window := range r.TimeWindows {
+			if ok, _ := window.contains(input.Time); ok {
+				inWindow = true
+				break
+			}
+		}
+		if !inWindow {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseWeekday parses abbreviated (e.g. Fri) as well as full names (e.g. Friday) of weekdays
+func parseWeekday(d string) (time.Weekday, error) {
+	if len(d) >= 3 {
+		if weekday, ok := weekdays[strings.ToLower(d[:3])]; ok && (len(d) == 3 || strings.EqualFold(d, weekday.String())) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %s", d)
+}
+
+// contains returns whether the given time is within the time window
+func (w TimeWindow) contains(t time.Time) (bool, error) {
+	location := time.UTC
+	if w.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(w.Timezone); err != nil {
+			return false, fmt.Errorf("invalid timezone %s: %w", w.Timezone, err)
+		}
+	}
+	from, err := time.Parse(timeOfDayLayout, w.From)
+	if err != nil {
+		return false, fmt.Errorf("invalid start of time window %s: must have the format HH:MM", w.From)
+	}
+	to, err := time.Parse(timeOfDayLayout, w.To)
+	if err != nil {
+		return false, fmt.Errorf("invalid end of time window %s: must have the format HH:MM", w.To)
+	}
+
+	t = t.In(location)
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	fromMinute := from.Hour()*60 + from.Minute()
+	toMinute := to.Hour()*60 + to.Minute()
+
+	day := t.Weekday()
+	var inWindow bool
+	if fromMinute < toMinute {
+		inWindow = minuteOfDay >= fromMinute && minuteOfDay < toMinute
+	} else {
+		// the window spans midnight, i.e. the part after midnight belongs to the window starting on the previous day
+		inWindow = minuteOfDay >= fromMinute || minuteOfDay < toMinute
+		if minuteOfDay < toMinute {
+			day = (day + 6) % 7
+		}
+	}
+	if !inWindow || len(w.Days) == 0 {
+		return inWindow, nil
+	}
+	for _, d := range w.Days {
+		weekday, err := parseWeekday(d)
+		if err != nil {
+			return false, err
+		}
+		if weekday == day {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ErrPolicyNotFound is returned by providers if there is no approval policy
+var ErrPolicyNotFound = errors.New("approval policy not found")
@@ -0,0 +1,219 @@
+package policy_test
+
+import (
+	"keptn/approval-service/pkg/policy"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `apiVersion: spec.keptn.sh/0.1.0
+kind: ApprovalPolicy
+spec:
+  rules:
+    - name: no-friday-evening-deployments
+      decision: reject
+      stages: [production]
+      timeWindows:
+        - from: "16:00"
+          to: "06:00"
+          days: [Fri]
+          timezone: Europe/Vienna
+    - name: release-managers
+      decision: approve
+      triggeredBy: [alice]
+    - name: critical-services
+      decision: manual
+      services: [payment]
+    - name: high-score
+      decision: approve
+      results: [pass, warning]
+      score:
+        min: 95
+    - name: hotfixes
+      decision: approve
+      labels:
+        type: hotfix
+`
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	approvalPolicy, err := policy.Parse([]byte(testPolicy))
+	require.Nil(t, err)
+
+	// Friday, 18:00 in Vienna
+	fridayEvening := time.Date(2022, 4, 22, 16, 0, 0, 0, time.UTC)
+	// Monday, 12:00 in Vienna
+	monday := time.Date(2022, 4, 25, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input policy.Input
+		want  *policy.Decision
+	}{
+		{
+			name:  "friday evening in production",
+			input: policy.Input{Stage: "production", Service: "carts", Result: keptnv2.ResultPass, Score: float(100), Time: fridayEvening, Labels: map[string]string{"triggeredBy": "alice"}},
+			want:  &policy.Decision{Type: policy.Reject, Rule: "no-friday-evening-deployments"},
+		},
+		{
+			name:  "window spanning midnight",
+			input: policy.Input{Stage: "production", Service: "carts", Result: keptnv2.ResultPass, Time: fridayEvening.Add(10 * time.Hour)},
+			want:  &policy.Decision{Type: policy.Reject, Rule: "no-friday-evening-deployments"},
+		},
+		{
+			name:  "friday evening in staging",
+			input: policy.Input{Stage: "staging", Service: "carts", Result: keptnv2.ResultPass, Time: fridayEvening, Labels: map[string]string{"triggeredBy": "alice"}},
+			want:  &policy.Decision{Type: policy.Approve, Rule: "release-managers"},
+		},
+		{
+			name:  "critical service",
+			input: policy.Input{Stage: "production", Service: "payment", Result: keptnv2.ResultPass, Score: float(100), Time: monday},
+			want:  &policy.Decision{Type: policy.Manual, Rule: "critical-services"},
+		},
+		{
+			name:  "high score",
+			input: policy.Input{Stage: "production", Service: "carts", Result: keptnv2.ResultWarning, Score: float(95), Time: monday},
+			want:  &policy.Decision{Type: policy.Approve, Rule: "high-score"},
+		},
+		{
+			name:  "low score",
+			input: policy.Input{Stage: "production", Service: "carts", Result: keptnv2.ResultPass, Score: float(90), Time: monday},
+			want:  nil,
+		},
+		{
+			name:  "no score",
+			input: policy.Input{Stage: "production", Service: "carts", Result: keptnv2.ResultPass, Time: monday},
+			want:  nil,
+		},
+		{
+			name:  "label",
+			input: policy.Input{Stage: "production", Service: "carts", Time: monday, Labels: map[string]string{"type": "hotfix"}},
+			want:  &policy.Decision{Type: policy.Approve, Rule: "hotfixes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, approvalPolicy.Evaluate(tt.input))
+		})
+	}
+}
+
+func TestPolicy_EvaluateTimeWindowSpanningWholeDay(t *testing.T) {
+	approvalPolicy, err := policy.Parse([]byte(`apiVersion: spec.keptn.sh/0.1.0
+kind: ApprovalPolicy
+spec:
+  rules:
+    - name: no-monday-deployments
+      decision: reject
+      timeWindows:
+        - from: "08:00"
+          to: "08:00"
+          days: [Mon]
+`))
+	require.Nil(t, err)
+
+	// Monday, 00:00
+	monday := time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC)
+	decision := &policy.Decision{Type: policy.Reject, Rule: "no-monday-deployments"}
+
+	// the window starts on Monday at 08:00 and ends on Tuesday at 08:00 (exclusive), the same as for remediation actions
+	require.Nil(t, approvalPolicy.Evaluate(policy.Input{Time: monday.Add(7 * time.Hour)}))
+	require.Equal(t, decision, approvalPolicy.Evaluate(policy.Input{Time: monday.Add(8 * time.Hour)}))
+	require.Equal(t, decision, approvalPolicy.Evaluate(policy.Input{Time: monday.Add(31 * time.Hour)}))
+	require.Nil(t, approvalPolicy.Evaluate(policy.Input{Time: monday.Add(32 * time.Hour)}))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "invalid yaml",
+			content: "apiVersion: [",
+		},
+		{
+			name:    "wrong kind",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: Remediation",
+		},
+		{
+			name:    "rule without name",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - decision: approve",
+		},
+		{
+			name:    "duplicate rule",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: approve\n    - name: a\n      decision: reject",
+		},
+		{
+			name:    "unsupported decision",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: maybe",
+		},
+		{
+			name:    "invalid score range",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: approve\n      score:\n        min: 90\n        max: 80",
+		},
+		{
+			name:    "quorum without groups",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: quorum",
+		},
+		{
+			name:    "quorum requiring more approvals than members",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: quorum\n      quorum:\n        groups:\n          - name: cab\n            members: [alice]\n            required: 2",
+		},
+		{
+			name:    "quorum with invalid deadline",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: quorum\n      quorum:\n        deadline: tomorrow\n        groups:\n          - name: cab\n            members: [alice]\n            required: 1",
+		},
+		{
+			name:    "quorum with other decision",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: approve\n      quorum:\n        groups:\n          - name: cab\n            members: [alice]\n            required: 1",
+		},
+		{
+			name:    "invalid time window",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: reject\n      timeWindows:\n        - from: 4pm\n          to: \"18:00\"",
+		},
+		{
+			name:    "invalid day",
+			content: "apiVersion: spec.keptn.sh/0.1.0\nkind: ApprovalPolicy\nspec:\n  rules:\n    - name: a\n      decision: reject\n      timeWindows:\n        - from: \"16:00\"\n          to: \"18:00\"\n          days: [Friyay]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := policy.Parse([]byte(tt.content))
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func TestParse_Quorum(t *testing.T) {
+	content := `apiVersion: spec.keptn.sh/0.1.0
+kind: ApprovalPolicy
+spec:
+  rules:
+    - name: change-board
+      decision: quorum
+      stages: [production]
+      quorum:
+        deadline: 24h
+        reminderInterval: 4h
+        groups:
+          - name: cab
+            members: [alice, bob, carol]
+            required: 2
+`
+	approvalPolicy, err := policy.Parse([]byte(content))
+	require.Nil(t, err)
+
+	decision := approvalPolicy.Evaluate(policy.Input{Stage: "production"})
+	require.Equal(t, policy.RequireQuorum, decision.Type)
+	require.Equal(t, "change-board", decision.Rule)
+	require.Equal(t, []policy.ApprovalGroup{{Name: "cab", Members: []string{"alice", "bob", "carol"}, Required: 2}}, decision.Quorum.Groups)
+	require.Equal(t, 24*time.Hour, decision.Quorum.GetDeadline())
+	require.Equal(t, 4*time.Hour, decision.Quorum.GetReminderInterval())
+}
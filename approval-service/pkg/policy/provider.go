@@ -0,0 +1,48 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+//go:generate moq  -pkg fake -out ./fake/provider_mock.go . Provider
+type Provider interface {
+	// GetPolicy returns the approval policy for the service, or ErrPolicyNotFound if there is none
+	GetPolicy(project, stage, service string) (*Policy, error)
+}
+
+//go:generate moq  -pkg fake -out ./fake/resource_handler_mock.go . ResourceHandler
+type ResourceHandler interface {
+	GetResource(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error)
+}
+
+// ResourceProvider reads the approval policy from the Keptn resource approval-policy.yaml.
+// The resource of the service takes precedence over the one of the stage, which takes precedence over the one of the project
+type ResourceProvider struct {
+	resourceHandler ResourceHandler
+}
+
+func NewResourceProvider(resourceHandler ResourceHandler) *ResourceProvider {
+	return &ResourceProvider{resourceHandler: resourceHandler}
+}
+
+func (r *ResourceProvider) GetPolicy(project, stage, service string) (*Policy, error) {
+	scopes := []*api.ResourceScope{
+		api.NewResourceScope().Project(project).Stage(stage).Service(service).Resource(ResourceName),
+		api.NewResourceScope().Project(project).Stage(stage).Resource(ResourceName),
+		api.NewResourceScope().Project(project).Resource(ResourceName),
+	}
+	for _, scope := range scopes {
+		resource, err := r.resourceHandler.GetResource(*scope)
+		if errors.Is(err, api.ResourceNotFoundError) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read approval policy: %w", err)
+		}
+		return Parse([]byte(resource.ResourceContent))
+	}
+	return nil, ErrPolicyNotFound
+}
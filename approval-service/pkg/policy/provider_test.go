@@ -0,0 +1,63 @@
+package policy_test
+
+import (
+	"errors"
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/policy/fake"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceProvider_GetPolicy(t *testing.T) {
+	resourceHandler := &fake.ResourceHandlerMock{
+		GetResourceFunc: func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+			if scope.GetStagePath() != "" && scope.GetServicePath() == "" {
+				return &models.Resource{ResourceContent: testPolicy}, nil
+			}
+			return nil, api.ResourceNotFoundError
+		},
+	}
+
+	approvalPolicy, err := policy.NewResourceProvider(resourceHandler).GetPolicy("sockshop", "production", "carts")
+	require.Nil(t, err)
+	require.Len(t, approvalPolicy.Spec.Rules, 5)
+
+	// the resource of the service is read first, then the one of the stage
+	require.Len(t, resourceHandler.GetResourceCalls(), 2)
+	calls := resourceHandler.GetResourceCalls()
+	require.Equal(t, "/service/carts", calls[0].Scope.GetServicePath())
+	require.Equal(t, "", calls[1].Scope.GetServicePath())
+	require.Equal(t, "/resource/approval-policy.yaml", calls[1].Scope.GetResourcePath())
+}
+
+func TestResourceProvider_GetPolicy_NotFound(t *testing.T) {
+	resourceHandler := &fake.ResourceHandlerMock{
+		GetResourceFunc: func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+			return nil, api.ResourceNotFoundError
+		},
+	}
+
+	_, err := policy.NewResourceProvider(resourceHandler).GetPolicy("sockshop", "production", "carts")
+	require.ErrorIs(t, err, policy.ErrPolicyNotFound)
+	require.Len(t, resourceHandler.GetResourceCalls(), 3)
+}
+
+func TestResourceProvider_GetPolicy_Errors(t *testing.T) {
+	resourceHandler := &fake.ResourceHandlerMock{
+		GetResourceFunc: func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+			return nil, errors.New("oops")
+		},
+	}
+	_, err := policy.NewResourceProvider(resourceHandler).GetPolicy("sockshop", "production", "carts")
+	require.NotNil(t, err)
+	require.False(t, errors.Is(err, policy.ErrPolicyNotFound))
+
+	resourceHandler.GetResourceFunc = func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+		return &models.Resource{ResourceContent: "kind: Something"}, nil
+	}
+	_, err = policy.NewResourceProvider(resourceHandler).GetPolicy("sockshop", "production", "carts")
+	require.NotNil(t, err)
+}
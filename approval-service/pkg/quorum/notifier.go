@@ -0,0 +1,67 @@
+package quorum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// Reminder is sent for approvals which are still missing votes
+type Reminder struct {
+	TriggeredID  string `json:"triggeredId"`
+	KeptnContext string `json:"keptnContext"`
+	Project      string `json:"project"`
+	Stage        string `json:"stage"`
+	Service      string `json:"service"`
+	Rule         string `json:"rule"`
+	// Deadline is the time after which the approval is rejected. It is zero if there is no deadline
+	Deadline time.Time `json:"deadline,omitempty"`
+	// MissingVotes contains the number of missing approvals per group
+	MissingVotes map[string]int `json:"missingVotes"`
+	// PendingVoters contains the members of the groups with missing approvals which have not voted yet
+	PendingVoters []string `json:"pendingVoters"`
+}
+
+//go:generate moq  -pkg fake -out ./fake/notifier_mock.go . Notifier
+type Notifier interface {
+	Notify(reminder Reminder) error
+}
+
+// LogNotifier writes reminders to the log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(reminder Reminder) error {
+	logger.Infof("Approval %s of service %s in stage %s of project %s is waiting for votes of %v",
+		reminder.TriggeredID, reminder.Service, reminder.Stage, reminder.Project, reminder.PendingVoters)
+	return nil
+}
+
+// WebhookNotifier sends reminders as JSON to a URL, e.g. a chat integration
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookNotifier) Notify(reminder Reminder) error {
+	body, err := json.Marshal(reminder)
+	if err != nil {
+		return fmt.Errorf("could not serialize reminder: %w", err)
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not send reminder: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("could not send reminder: received status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package quorum_test
+
+import (
+	"encoding/json"
+	"keptn/approval-service/pkg/quorum"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var received quorum.Reminder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	reminder := quorum.Reminder{TriggeredID: "approval-1", Project: "sockshop", MissingVotes: map[string]int{"cab": 1}, PendingVoters: []string{"bob"}}
+	require.Nil(t, quorum.NewWebhookNotifier(server.URL).Notify(reminder))
+	require.Equal(t, reminder, received)
+}
+
+func TestWebhookNotifier_NotifyFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	require.NotNil(t, quorum.NewWebhookNotifier(server.URL).Notify(quorum.Reminder{}))
+}
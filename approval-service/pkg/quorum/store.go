@@ -0,0 +1,132 @@
+package quorum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapName is the name of the ConfigMap holding the pending approvals. The approval-service is only allowed to read and update this ConfigMap
+const ConfigMapName = "keptn-approval-quorums"
+
+// Store persists the pending approvals of a Tracker, so that they survive a restart of the approval-service
+type Store interface {
+	// Save creates or updates the given approval
+	Save(approval PendingApproval) error
+	// Delete removes the approval with the given triggered ID. Deleting an unknown approval is not an error
+	Delete(triggeredID string) error
+	// List returns all persisted approvals
+	List() ([]PendingApproval, error)
+}
+
+type noopStore struct{}
+
+func (noopStore) Save(PendingApproval) error       { return nil }
+func (noopStore) Delete(string) error              { return nil }
+func (noopStore) List() ([]PendingApproval, error) { return nil, nil }
+
+// K8sStore persists all pending approvals in a single ConfigMap. Each approval is stored in a key derived from its triggered ID
+type K8sStore struct {
+	kubeAPI   kubernetes.Interface
+	namespace string
+}
+
+func NewK8sStore(kubeAPI kubernetes.Interface, namespace string) *K8sStore {
+	return &K8sStore{kubeAPI: kubeAPI, namespace: namespace}
+}
+
+func (s *K8sStore) Save(approval PendingApproval) error {
+	content, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+	return s.modify(func(data map[string]string) {
+		data[configMapKey(approval.TriggeredID)] = string(content)
+	})
+}
+
+func (s *K8sStore) Delete(triggeredID string) error {
+	return s.modify(func(data map[string]string) {
+		delete(data, configMapKey(triggeredID))
+	})
+}
+
+func (s *K8sStore) List() ([]PendingApproval, error) {
+	configMap, err := s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if k8serr.IsNotFound(err) {
+			return []PendingApproval{}, nil
+		}
+		return nil, err
+	}
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	approvals := make([]PendingApproval, 0, len(keys))
+	for _, key := range keys {
+		approval := PendingApproval{}
+		if err := json.Unmarshal([]byte(configMap.Data[key]), &approval); err != nil {
+			return nil, fmt.Errorf("could not decode approval %s stored in ConfigMap %s: %w", key, ConfigMapName, err)
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
+
+// modify applies the given function to the stored approvals and updates the ConfigMap using its resourceVersion,
+// so that approvals stored concurrently are not overwritten. On a conflict, the function is applied to the latest version of the ConfigMap.
+// The ConfigMap is usually created by the Helm chart, as the approval-service is not allowed to create ConfigMaps
+func (s *K8sStore) modify(fn func(data map[string]string)) error {
+	isConflict := func(err error) bool {
+		return k8serr.IsConflict(err) || k8serr.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		configMap, err := s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Get(context.TODO(), ConfigMapName, metav1.GetOptions{})
+		exists := err == nil
+		if err != nil {
+			if !k8serr.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ConfigMapName,
+					Namespace: s.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "approval-service",
+						"app.kubernetes.io/component":  "quorum",
+					},
+				},
+			}
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		fn(configMap.Data)
+
+		if !exists {
+			// fails with AlreadyExists if another instance created the ConfigMap in the meantime
+			_, err = s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+			return err
+		}
+		_, err = s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// configMapKey derives a valid ConfigMap key from the triggered ID of an approval
+func configMapKey(triggeredID string) string {
+	hash := sha256.Sum256([]byte(triggeredID))
+	return hex.EncodeToString(hash[:])
+}
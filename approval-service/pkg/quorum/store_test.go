@@ -0,0 +1,124 @@
+package quorum_test
+
+import (
+	"context"
+	"keptn/approval-service/pkg/quorum"
+	"keptn/approval-service/pkg/quorum/fake"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func countStoredApprovals(t *testing.T, kubeAPI *k8sfake.Clientset) int {
+	configMap, err := kubeAPI.CoreV1().ConfigMaps("keptn").Get(context.TODO(), quorum.ConfigMapName, metav1.GetOptions{})
+	require.Nil(t, err)
+	return len(configMap.Data)
+}
+
+func TestTracker_LoadAfterRestart(t *testing.T) {
+	kubeAPI := k8sfake.NewSimpleClientset()
+	store := quorum.NewK8sStore(kubeAPI, "keptn")
+
+	tracker := quorum.NewTracker(&fake.NotifierMock{}, quorum.WithStore(store))
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+	_, err := tracker.Vote("approval-1", "alice", true)
+	require.Nil(t, err)
+	require.Equal(t, 1, countStoredApprovals(t, kubeAPI))
+
+	// simulate a restart of the approval-service
+	restarted := quorum.NewTracker(&fake.NotifierMock{}, quorum.WithStore(store))
+	require.Nil(t, restarted.Load())
+	require.True(t, restarted.IsPending("approval-1"))
+
+	outcome, err := restarted.Vote("approval-1", "bob", true)
+	require.Nil(t, err)
+	require.Nil(t, outcome)
+	outcome, err = restarted.Vote("approval-1", "dave", true)
+	require.Nil(t, err)
+	require.Equal(t, keptnv2.ResultPass, outcome.Result)
+	require.Equal(t, "Approved by approval policy rule 'change-board': cab (alice, bob), sre (dave)", outcome.Message)
+	require.Equal(t, 0, countStoredApprovals(t, kubeAPI))
+}
+
+func TestTracker_LoadExpired(t *testing.T) {
+	kubeAPI := k8sfake.NewSimpleClientset()
+	store := quorum.NewK8sStore(kubeAPI, "keptn")
+
+	tracker := quorum.NewTracker(&fake.NotifierMock{}, quorum.WithStore(store))
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+
+	// the deadline expires while the approval-service is not running
+	restarted := quorum.NewTracker(&fake.NotifierMock{}, quorum.WithStore(store))
+	require.Nil(t, restarted.Load())
+	outcomes := restarted.Check(triggeredAt.Add(25 * time.Hour))
+	require.Len(t, outcomes, 1)
+	require.Equal(t, keptnv2.ResultFailed, outcomes[0].Result)
+	require.Equal(t, "approval-1", outcomes[0].Approval.TriggeredID)
+	require.Equal(t, 0, countStoredApprovals(t, kubeAPI))
+}
+
+func TestTracker_RunChecksOnStartup(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), time.Now().Add(-48*time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outcomes := make(chan quorum.Outcome, 1)
+	go tracker.Run(ctx, time.Hour, func(outcome quorum.Outcome) {
+		outcomes <- outcome
+	})
+
+	select {
+	case outcome := <-outcomes:
+		require.Equal(t, keptnv2.ResultFailed, outcome.Result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired approval has not been rejected on startup")
+	}
+}
+
+func TestK8sStore(t *testing.T) {
+	kubeAPI := k8sfake.NewSimpleClientset()
+	store := quorum.NewK8sStore(kubeAPI, "keptn")
+
+	approval := newPendingApproval("approval-1")
+	approval.Votes = map[string]bool{"alice": true}
+	require.Nil(t, store.Save(approval))
+	approval.Votes["bob"] = false
+	require.Nil(t, store.Save(approval))
+
+	approvals, err := store.List()
+	require.Nil(t, err)
+	require.Equal(t, []quorum.PendingApproval{approval}, approvals)
+
+	require.Nil(t, store.Delete("approval-1"))
+	require.Nil(t, store.Delete("approval-1"))
+	approvals, err = store.List()
+	require.Nil(t, err)
+	require.Empty(t, approvals)
+}
+
+func TestK8sStore_UsesExistingConfigMap(t *testing.T) {
+	kubeAPI := k8sfake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: quorum.ConfigMapName, Namespace: "keptn"},
+	})
+	store := quorum.NewK8sStore(kubeAPI, "keptn")
+
+	require.Nil(t, store.Save(newPendingApproval("approval-1")))
+	require.Nil(t, store.Save(newPendingApproval("approval-2")))
+	require.Equal(t, 2, countStoredApprovals(t, kubeAPI))
+
+	configMaps, err := kubeAPI.CoreV1().ConfigMaps("keptn").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, configMaps.Items, 1)
+
+	require.Nil(t, store.Delete("approval-1"))
+	approvals, err := store.List()
+	require.Nil(t, err)
+	require.Len(t, approvals, 1)
+	require.Equal(t, "approval-2", approvals[0].TriggeredID)
+}
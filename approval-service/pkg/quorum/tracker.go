@@ -0,0 +1,334 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"keptn/approval-service/pkg/policy"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	logger "github.com/sirupsen/logrus"
+)
+
+// ErrApprovalNotFound is returned for votes on approvals which are not tracked, e.g. because they have already been decided
+var ErrApprovalNotFound = errors.New("no pending approval found")
+
+// ErrNotEligible is returned for votes of users which are not a member of any group of the quorum
+var ErrNotEligible = errors.New("voter is not a member of any approval group")
+
+// PendingApproval is an approval which waits for the votes of its quorum
+type PendingApproval struct {
+	TriggeredID  string
+	KeptnContext string
+	EventData    keptnv2.EventData
+	// Rule is the name of the approval policy rule which requires the quorum
+	Rule   string
+	Quorum policy.Quorum
+	// Deadline is the time after which the approval is rejected. It is zero if there is no deadline
+	Deadline time.Time
+	// NextReminder is the time the next reminder is due. It is zero if no reminders are sent
+	NextReminder time.Time
+	// Votes contains the decision of each voter, true meaning approved
+	Votes map[string]bool
+}
+
+// Outcome is the result of an approval which has been decided
+type Outcome struct {
+	Approval PendingApproval
+	Result   keptnv2.ResultType
+	Message  string
+}
+
+// Tracker collects the votes of pending approvals until their quorum is met, or their deadline expired.
+// The pending approvals are persisted in a Store, so that they can be loaded again after a restart
+type Tracker struct {
+	mtx       sync.Mutex
+	approvals map[string]*PendingApproval
+	notifier  Notifier
+	store     Store
+}
+
+type TrackerOption func(t *Tracker)
+
+// WithStore sets the store the pending approvals are persisted in. Without a store, pending approvals are only kept in memory
+func WithStore(store Store) TrackerOption {
+	return func(t *Tracker) {
+		t.store = store
+	}
+}
+
+// NewTracker creates a tracker which sends reminders for missing votes using the notifier
+func NewTracker(notifier Notifier, opts ...TrackerOption) *Tracker {
+	t := &Tracker{
+		approvals: map[string]*PendingApproval{},
+		notifier:  notifier,
+		store:     noopStore{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Load restores the approvals persisted before a restart. Their deadlines and reminders are checked by the next call of Check
+func (t *Tracker) Load() error {
+	approvals, err := t.store.List()
+	if err != nil {
+		return fmt.Errorf("could not load pending approvals: %w", err)
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for i := range approvals {
+		approval := approvals[i]
+		if approval.Votes == nil {
+			approval.Votes = map[string]bool{}
+		}
+		t.approvals[approval.TriggeredID] = &approval
+	}
+	logger.Infof("Loaded %d pending approvals", len(approvals))
+	return nil
+}
+
+// Add starts tracking the votes of the approval, which has been triggered at the given time
+func (t *Tracker) Add(approval PendingApproval, triggeredAt time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	approval.Votes = map[string]bool{}
+	if deadline := approval.Quorum.GetDeadline(); deadline > 0 {
+		approval.Deadline = triggeredAt.Add(deadline)
+	}
+	if interval := approval.Quorum.GetReminderInterval(); interval > 0 {
+		approval.NextReminder = triggeredAt.Add(interval)
+	}
+	t.approvals[approval.TriggeredID] = &approval
+	t.save(&approval)
+}
+
+// IsPending returns whether the approval is waiting for votes
+func (t *Tracker) IsPending(triggeredID string) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	_, ok := t.approvals[triggeredID]
+	return ok
+}
+
+// Remove stops tracking the approval, e.g. because its task has been finished. It returns whether the approval was pending
+func (t *Tracker) Remove(triggeredID string) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if _, ok := t.approvals[triggeredID]; !ok {
+		return false
+	}
+	t.remove(triggeredID)
+	return true
+}
+
+// RemoveSequence stops tracking the approvals of the sequence with the given context in the given stage, e.g. because the sequence
+// has been finished, aborted or timed out. It returns the triggered IDs of the removed approvals
+func (t *Tracker) RemoveSequence(keptnContext string, stage string) []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	var removed []string
+	for triggeredID, approval := range t.approvals {
+		if approval.KeptnContext == keptnContext && approval.EventData.Stage == stage {
+			removed = append(removed, triggeredID)
+		}
+	}
+	sort.Strings(removed)
+	for _, triggeredID := range removed {
+		t.remove(triggeredID)
+	}
+	return removed
+}
+
+// Vote records the vote of the voter. A voter can change its vote until the approval is decided.
+// If the vote decides the approval, the outcome is returned and the approval is not tracked anymore
+func (t *Tracker) Vote(triggeredID string, voter string, approve bool) (*Outcome, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	approval, ok := t.approvals[triggeredID]
+	if !ok {
+		return nil, ErrApprovalNotFound
+	}
+	if len(approval.groupsOf(voter)) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotEligible, voter)
+	}
+	approval.Votes[voter] = approve
+
+	outcome := approval.evaluate()
+	if outcome != nil {
+		t.remove(triggeredID)
+	} else {
+		t.save(approval)
+	}
+	return outcome, nil
+}
+
+// Check rejects the approvals whose deadline expired before the given time, and sends the reminders which are due
+func (t *Tracker) Check(now time.Time) []Outcome {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	outcomes := []Outcome{}
+	var reminders []Reminder
+	for triggeredID, approval := range t.approvals {
+		if !approval.Deadline.IsZero() && !now.Before(approval.Deadline) {
+			outcomes = append(outcomes, Outcome{
+				Approval: *approval,
+				Result:   keptnv2.ResultFailed,
+				Message:  fmt.Sprintf("Rejected by approval policy rule '%s': the deadline expired with %s", approval.Rule, approval.describeVotes()),
+			})
+			t.remove(triggeredID)
+			continue
+		}
+		if !approval.NextReminder.IsZero() && !now.Before(approval.NextReminder) {
+			reminders = append(reminders, approval.reminder())
+			for !now.Before(approval.NextReminder) {
+				approval.NextReminder = approval.NextReminder.Add(approval.Quorum.GetReminderInterval())
+			}
+			t.save(approval)
+		}
+	}
+	for _, reminder := range reminders {
+		if err := t.notifier.Notify(reminder); err != nil {
+			logger.WithError(err).Errorf("could not send reminder for approval %s", reminder.TriggeredID)
+		}
+	}
+	return outcomes
+}
+
+// Run checks the pending approvals immediately and then in the given interval, and passes the outcomes of expired approvals
+// to the given function. The immediate check rejects approvals whose deadline expired while the approval-service was not running
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, onOutcome func(outcome Outcome)) {
+	for _, outcome := range t.Check(time.Now()) {
+		onOutcome(outcome)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, outcome := range t.Check(now) {
+				onOutcome(outcome)
+			}
+		}
+	}
+}
+
+// save persists the approval. It needs to be called while holding the lock
+func (t *Tracker) save(approval *PendingApproval) {
+	if err := t.store.Save(*approval); err != nil {
+		logger.WithError(err).Errorf("could not persist approval %s, it will be lost if the approval-service is restarted", approval.TriggeredID)
+	}
+}
+
+// remove stops tracking the approval and deletes it from the store. It needs to be called while holding the lock
+func (t *Tracker) remove(triggeredID string) {
+	delete(t.approvals, triggeredID)
+	if err := t.store.Delete(triggeredID); err != nil {
+		logger.WithError(err).Errorf("could not delete persisted approval %s", triggeredID)
+	}
+}
+
+func (a *PendingApproval) groupsOf(voter string) []policy.ApprovalGroup {
+	var groups []policy.ApprovalGroup
+	for _, group := range a.Quorum.Groups {
+		for _, member := range group.Members {
+			if member == voter {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// countVotes returns the members of the group which approved and rejected
+func (a *PendingApproval) countVotes(group policy.ApprovalGroup) (approvers []string, rejecters []string) {
+	for _, member := range group.Members {
+		approve, voted := a.Votes[member]
+		if !voted {
+			continue
+		}
+		if approve {
+			approvers = append(approvers, member)
+		} else {
+			rejecters = append(rejecters, member)
+		}
+	}
+	return approvers, rejecters
+}
+
+// evaluate returns the outcome of the approval, or nil if the approval has not been decided yet
+func (a *PendingApproval) evaluate() *Outcome {
+	approved := true
+	var approvals []string
+	for _, group := range a.Quorum.Groups {
+		approvers, rejecters := a.countVotes(group)
+		if len(group.Members)-len(rejecters) < group.Required {
+			return &Outcome{
+				Approval: *a,
+				Result:   keptnv2.ResultFailed,
+				Message: fmt.Sprintf("Rejected by approval policy rule '%s': group %s cannot reach %d approvals anymore (rejected by %s)",
+					a.Rule, group.Name, group.Required, strings.Join(rejecters, ", ")),
+			}
+		}
+		if len(approvers) < group.Required {
+			approved = false
+		}
+		approvals = append(approvals, fmt.Sprintf("%s (%s)", group.Name, strings.Join(approvers, ", ")))
+	}
+	if !approved {
+		return nil
+	}
+	return &Outcome{
+		Approval: *a,
+		Result:   keptnv2.ResultPass,
+		Message:  fmt.Sprintf("Approved by approval policy rule '%s': %s", a.Rule, strings.Join(approvals, ", ")),
+	}
+}
+
+// describeVotes summarizes the approvals of each group
+func (a *PendingApproval) describeVotes() string {
+	var descriptions []string
+	for _, group := range a.Quorum.Groups {
+		approvers, _ := a.countVotes(group)
+		descriptions = append(descriptions, fmt.Sprintf("%d of %d approvals from %s", len(approvers), group.Required, group.Name))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func (a *PendingApproval) reminder() Reminder {
+	reminder := Reminder{
+		TriggeredID:  a.TriggeredID,
+		KeptnContext: a.KeptnContext,
+		Project:      a.EventData.Project,
+		Stage:        a.EventData.Stage,
+		Service:      a.EventData.Service,
+		Rule:         a.Rule,
+		Deadline:     a.Deadline,
+		MissingVotes: map[string]int{},
+	}
+	pendingVoters := map[string]bool{}
+	for _, group := range a.Quorum.Groups {
+		approvers, _ := a.countVotes(group)
+		if missing := group.Required - len(approvers); missing > 0 {
+			reminder.MissingVotes[group.Name] = missing
+			for _, member := range group.Members {
+				if _, voted := a.Votes[member]; !voted {
+					pendingVoters[member] = true
+				}
+			}
+		}
+	}
+	for voter := range pendingVoters {
+		reminder.PendingVoters = append(reminder.PendingVoters, voter)
+	}
+	sort.Strings(reminder.PendingVoters)
+	return reminder
+}
@@ -0,0 +1,147 @@
+package quorum_test
+
+import (
+	"errors"
+	"keptn/approval-service/pkg/policy"
+	"keptn/approval-service/pkg/quorum"
+	"keptn/approval-service/pkg/quorum/fake"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+)
+
+var triggeredAt = time.Date(2022, 4, 25, 10, 0, 0, 0, time.UTC)
+
+func newPendingApproval(triggeredID string) quorum.PendingApproval {
+	return quorum.PendingApproval{
+		TriggeredID:  triggeredID,
+		KeptnContext: "1234-4567",
+		EventData:    keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+		Rule:         "change-board",
+		Quorum: policy.Quorum{
+			Groups: []policy.ApprovalGroup{
+				{Name: "cab", Members: []string{"alice", "bob", "carol"}, Required: 2},
+				{Name: "sre", Members: []string{"dave", "erin"}, Required: 1},
+			},
+			Deadline:         "24h",
+			ReminderInterval: "4h",
+		},
+	}
+}
+
+func TestTracker_VoteApproved(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+
+	outcome, err := tracker.Vote("approval-1", "alice", true)
+	require.Nil(t, err)
+	require.Nil(t, outcome)
+
+	_, err = tracker.Vote("approval-1", "mallory", true)
+	require.True(t, errors.Is(err, quorum.ErrNotEligible))
+
+	outcome, err = tracker.Vote("approval-1", "dave", true)
+	require.Nil(t, err)
+	require.Nil(t, outcome)
+
+	outcome, err = tracker.Vote("approval-1", "carol", true)
+	require.Nil(t, err)
+	require.NotNil(t, outcome)
+	require.Equal(t, keptnv2.ResultPass, outcome.Result)
+	require.Equal(t, "Approved by approval policy rule 'change-board': cab (alice, carol), sre (dave)", outcome.Message)
+	require.Equal(t, "approval-1", outcome.Approval.TriggeredID)
+
+	// the approval has been decided
+	_, err = tracker.Vote("approval-1", "bob", true)
+	require.True(t, errors.Is(err, quorum.ErrApprovalNotFound))
+}
+
+func TestTracker_VoteRejected(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+
+	outcome, err := tracker.Vote("approval-1", "alice", false)
+	require.Nil(t, err)
+	require.Nil(t, outcome)
+
+	// votes can be changed until the approval is decided
+	outcome, err = tracker.Vote("approval-1", "bob", false)
+	require.Nil(t, err)
+	require.NotNil(t, outcome)
+	require.Equal(t, keptnv2.ResultFailed, outcome.Result)
+	require.Equal(t, "Rejected by approval policy rule 'change-board': group cab cannot reach 2 approvals anymore (rejected by alice, bob)", outcome.Message)
+}
+
+func TestTracker_Check(t *testing.T) {
+	notifier := &fake.NotifierMock{NotifyFunc: func(reminder quorum.Reminder) error { return nil }}
+	tracker := quorum.NewTracker(notifier)
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+	_, err := tracker.Vote("approval-1", "alice", true)
+	require.Nil(t, err)
+
+	require.Empty(t, tracker.Check(triggeredAt.Add(time.Hour)))
+	require.Empty(t, notifier.NotifyCalls())
+
+	require.Empty(t, tracker.Check(triggeredAt.Add(5*time.Hour)))
+	require.Len(t, notifier.NotifyCalls(), 1)
+	reminder := notifier.NotifyCalls()[0].Reminder
+	require.Equal(t, "approval-1", reminder.TriggeredID)
+	require.Equal(t, "carts", reminder.Service)
+	require.Equal(t, triggeredAt.Add(24*time.Hour), reminder.Deadline)
+	require.Equal(t, map[string]int{"cab": 1, "sre": 1}, reminder.MissingVotes)
+	require.Equal(t, []string{"bob", "carol", "dave", "erin"}, reminder.PendingVoters)
+
+	// the next reminder is due after another interval
+	require.Empty(t, tracker.Check(triggeredAt.Add(6*time.Hour)))
+	require.Len(t, notifier.NotifyCalls(), 1)
+	require.Empty(t, tracker.Check(triggeredAt.Add(8*time.Hour)))
+	require.Len(t, notifier.NotifyCalls(), 2)
+
+	outcomes := tracker.Check(triggeredAt.Add(24 * time.Hour))
+	require.Len(t, outcomes, 1)
+	require.Equal(t, keptnv2.ResultFailed, outcomes[0].Result)
+	require.Equal(t, "Rejected by approval policy rule 'change-board': the deadline expired with 1 of 2 approvals from cab, 0 of 1 approvals from sre", outcomes[0].Message)
+
+	require.Empty(t, tracker.Check(triggeredAt.Add(48*time.Hour)))
+	require.Len(t, notifier.NotifyCalls(), 2)
+}
+
+func TestTracker_IsPending(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+
+	require.True(t, tracker.IsPending("approval-1"))
+	require.False(t, tracker.IsPending("approval-2"))
+	require.Len(t, tracker.Check(triggeredAt.Add(48*time.Hour)), 1)
+	require.False(t, tracker.IsPending("approval-1"))
+}
+
+func TestTracker_Remove(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+
+	require.True(t, tracker.Remove("approval-1"))
+	require.False(t, tracker.IsPending("approval-1"))
+	require.False(t, tracker.Remove("approval-1"))
+}
+
+func TestTracker_RemoveSequence(t *testing.T) {
+	tracker := quorum.NewTracker(&fake.NotifierMock{})
+	tracker.Add(newPendingApproval("approval-1"), triggeredAt)
+	tracker.Add(newPendingApproval("approval-2"), triggeredAt)
+	otherStage := newPendingApproval("approval-3")
+	otherStage.EventData.Stage = "staging"
+	tracker.Add(otherStage, triggeredAt)
+	otherContext := newPendingApproval("approval-4")
+	otherContext.KeptnContext = "other-context"
+	tracker.Add(otherContext, triggeredAt)
+
+	require.Equal(t, []string{"approval-1", "approval-2"}, tracker.RemoveSequence("1234-4567", "production"))
+	require.False(t, tracker.IsPending("approval-1"))
+	require.False(t, tracker.IsPending("approval-2"))
+	require.True(t, tracker.IsPending("approval-3"))
+	require.True(t, tracker.IsPending("approval-4"))
+	require.Empty(t, tracker.RemoveSequence("1234-4567", "production"))
+}
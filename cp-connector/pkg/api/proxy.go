@@ -0,0 +1,127 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxiedServices maps the path prefixes handled by the APIProxy
+// to the internal Keptn services
+var proxiedServices = map[string]InternalService{
+	"/controlPlane":          ShipyardController,
+	"/configuration-service": ConfigurationService,
+	"/mongodb-datastore":     MongoDBDatastore,
+}
+
+// APIProxy is an http.Handler which forwards the requests of a Keptn service to the Keptn API.
+// Requests to /controlPlane, /configuration-service and /mongodb-datastore are forwarded
+// to the respective Keptn service, e.g. /controlPlane/v1/project is forwarded to /v1/project
+// of the shipyard-controller. It can be used in place of the API proxy of the distributor
+type APIProxy struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	token      string
+	apimap     InClusterAPIMappings
+}
+
+// NewAPIProxy creates a new APIProxy forwarding requests to the Keptn API at the given endpoint.
+// If a token is given, it is added to all forwarded requests
+func NewAPIProxy(client *http.Client, endpoint string, token string) (*APIProxy, error) {
+	parsedEndpoint, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse Keptn API endpoint: %w", err)
+	}
+	if parsedEndpoint.Scheme == "" || parsedEndpoint.Host == "" {
+		return nil, fmt.Errorf("invalid Keptn API endpoint %s", endpoint)
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &APIProxy{httpClient: client, endpoint: parsedEndpoint, token: token}, nil
+}
+
+// NewInternalAPIProxy creates a new APIProxy usable for forwarding requests to the Keptn services
+// from within the control plane
+func NewInternalAPIProxy(client *http.Client, apiMappings ...InClusterAPIMappings) *APIProxy {
+	apimap := DefaultInClusterAPIMappings
+	if len(apiMappings) > 0 {
+		apimap = apiMappings[0]
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &APIProxy{httpClient: client, apimap: apimap}
+}
+
+func (p *APIProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	if req.URL.RawPath != "" {
+		path = req.URL.RawPath
+	}
+	targetURL, err := p.targetURL(path)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+	targetURL.RawQuery = req.URL.RawQuery
+
+	forwardReq, err := http.NewRequestWithContext(req.Context(), req.Method, targetURL.String(), req.Body)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("could not create request to be forwarded: %v", err), http.StatusInternalServerError)
+		return
+	}
+	forwardReq.Header = req.Header.Clone()
+	if p.token != "" {
+		forwardReq.Header.Set("x-token", p.token)
+	}
+
+	resp, err := p.httpClient.Do(forwardReq)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("could not send request to Keptn API: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			rw.Header().Add(name, value)
+		}
+	}
+	rw.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(rw, resp.Body)
+}
+
+// targetURL returns the URL the request with the given path is forwarded to
+func (p *APIProxy) targetURL(path string) (*url.URL, error) {
+	for prefix, service := range proxiedServices {
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		servicePath := strings.TrimPrefix(path, prefix)
+		if p.endpoint == nil {
+			return url.Parse("http://" + p.apimap[service] + "/" + strings.TrimPrefix(servicePath, "/"))
+		}
+		if service == ConfigurationService {
+			servicePath = escapeResourceURI(servicePath)
+		}
+		return url.Parse(p.endpoint.Scheme + "://" + p.endpoint.Host + strings.TrimSuffix(p.endpoint.Path, "/") + prefix + servicePath)
+	}
+	return nil, fmt.Errorf("no Keptn service found for path %s", path)
+}
+
+// escapeResourceURI escapes the URI of a nested resource requested from the configuration-service,
+// since the Keptn API expects it to be a single path segment
+func escapeResourceURI(path string) string {
+	index := strings.LastIndex(path, "/resource/")
+	if index < 0 {
+		return path
+	}
+	resourceURI := path[index+len("/resource/"):]
+	if unescaped, err := url.PathUnescape(resourceURI); err == nil {
+		resourceURI = unescaped
+	}
+	return path[:index] + "/resource/" + url.PathEscape(resourceURI)
+}
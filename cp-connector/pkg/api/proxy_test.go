@@ -0,0 +1,116 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newKeptnAPIFake(t *testing.T, requests chan *http.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, err := w.Write([]byte(`{"ok":true}`))
+		assert.Nil(t, err)
+	}))
+}
+
+func TestAPIProxy(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	keptnAPI := newKeptnAPIFake(t, requests)
+	defer keptnAPI.Close()
+
+	proxy, err := NewAPIProxy(nil, keptnAPI.URL+"/api", "my-token")
+	require.Nil(t, err)
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/controlPlane/v1/project?pageSize=10", strings.NewReader(`{"name":"sockshop"}`))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	assert.Equal(t, `{"ok":true}`, string(body))
+
+	forwardedReq := <-requests
+	assert.Equal(t, http.MethodPost, forwardedReq.Method)
+	assert.Equal(t, "/api/controlPlane/v1/project", forwardedReq.URL.Path)
+	assert.Equal(t, "pageSize=10", forwardedReq.URL.RawQuery)
+	assert.Equal(t, "my-token", forwardedReq.Header.Get("x-token"))
+	assert.Equal(t, "application/json", forwardedReq.Header.Get("Content-Type"))
+}
+
+func TestAPIProxyUnknownPath(t *testing.T) {
+	proxy, err := NewAPIProxy(nil, "http://keptn", "")
+	require.Nil(t, err)
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/controlPlaneV2/v1/project", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIProxyUnreachableAPI(t *testing.T) {
+	keptnAPI := httptest.NewServer(http.NotFoundHandler())
+	keptnAPI.Close()
+	proxy, err := NewAPIProxy(nil, keptnAPI.URL, "")
+	require.Nil(t, err)
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/controlPlane/v1/project", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestNewAPIProxyInvalidEndpoint(t *testing.T) {
+	_, err := NewAPIProxy(nil, "keptn", "")
+	require.Error(t, err)
+}
+
+func TestAPIProxy_targetURL(t *testing.T) {
+	external, err := NewAPIProxy(nil, "https://keptn.example.com/api/", "")
+	require.Nil(t, err)
+	internal := NewInternalAPIProxy(nil)
+
+	tests := []struct {
+		name     string
+		proxy    *APIProxy
+		path     string
+		expected string
+	}{
+		{"external control plane", external, "/controlPlane/v1/project", "https://keptn.example.com/api/controlPlane/v1/project"},
+		{"external datastore", external, "/mongodb-datastore/event", "https://keptn.example.com/api/mongodb-datastore/event"},
+		{"external nested resource", external, "/configuration-service/v1/project/sockshop/resource/helm/values.yaml", "https://keptn.example.com/api/configuration-service/v1/project/sockshop/resource/helm%2Fvalues.yaml"},
+		{"external escaped resource", external, "/configuration-service/v1/project/sockshop/resource/helm%2Fvalues.yaml", "https://keptn.example.com/api/configuration-service/v1/project/sockshop/resource/helm%2Fvalues.yaml"},
+		{"internal control plane", internal, "/controlPlane/v1/project", "http://shipyard-controller:8080/v1/project"},
+		{"internal configuration service", internal, "/configuration-service/v1/project", "http://configuration-service:8080/v1/project"},
+		{"internal datastore", internal, "/mongodb-datastore", "http://mongodb-datastore:8080/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetURL, err := tt.proxy.targetURL(tt.path)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, targetURL.String())
+		})
+	}
+
+	_, err = internal.targetURL("/api-service/v1/auth")
+	require.Error(t, err)
+}
+
+func TestNewInternalAPIProxyOverrideMappings(t *testing.T) {
+	proxy := NewInternalAPIProxy(nil, InClusterAPIMappings{ShipyardController: "special-shipyard-controller:8080"})
+	targetURL, err := proxy.targetURL("/controlPlane/v1/project")
+	require.Nil(t, err)
+	assert.Equal(t, &url.URL{Scheme: "http", Host: "special-shipyard-controller:8080", Path: "/v1/project"}, targetURL)
+}
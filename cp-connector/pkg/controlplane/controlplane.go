@@ -2,13 +2,20 @@ package controlplane
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/cp-connector/pkg/logger"
 )
 
 var ErrEventHandleFatal = errors.New("fatal event handling error")
 
+// UserLabel is the label holding the user who triggered an event.
+// It is set by the Keptn API and removed from events sent by integrations
+const UserLabel = "triggeredBy"
+
 type RegistrationData models.Integration
 
 // Integration represents a Keptn Service that wants to receive events from the Keptn Control plane
@@ -24,30 +31,54 @@ type Integration interface {
 type ControlPlane struct {
 	subscriptionSource   SubscriptionSource
 	eventSource          EventSource
+	logForwarder         LogForwarder
+	integrationID        string
 	currentSubscriptions []models.EventSubscription
 	logger               logger.Logger
 }
 
+// WithLogForwarder specifies the LogForwarder the control plane should use
+// to forward the error logs contained in the events sent by the integration
+func WithLogForwarder(logForwarder LogForwarder) func(cp *ControlPlane) {
+	return func(cp *ControlPlane) {
+		cp.logForwarder = logForwarder
+	}
+}
+
 // New creates a new ControlPlane
 // It is using a SubscriptionSource source to get information about current uniform subscriptions
 // as well as an EventSource to actually receive events from Keptn
-func New(subscriptionSource SubscriptionSource, eventSource EventSource) *ControlPlane {
-	return &ControlPlane{
+func New(subscriptionSource SubscriptionSource, eventSource EventSource, options ...func(cp *ControlPlane)) *ControlPlane {
+	controlPlane := &ControlPlane{
 		subscriptionSource:   subscriptionSource,
 		eventSource:          eventSource,
 		currentSubscriptions: []models.EventSubscription{},
 		logger:               logger.NewDefaultLogger(),
 	}
+	for _, o := range options {
+		o(controlPlane)
+	}
+	return controlPlane
 }
 
 // Register is initially used to register the Keptn integration to the Control Plane
 func (cp *ControlPlane) Register(ctx context.Context, integration Integration) error {
+	registrationData := withVersionInfo(integration.RegistrationData())
+	if registrar, ok := cp.subscriptionSource.(IntegrationRegistrar); ok {
+		integrationID, err := registrar.Register(registrationData)
+		if err != nil {
+			return err
+		}
+		cp.integrationID = integrationID
+		registrationData.ID = integrationID
+	}
+
 	eventUpdates := make(chan EventUpdate)
 	subscriptionUpdates := make(chan []models.EventSubscription)
-	if err := cp.eventSource.Start(ctx, integration.RegistrationData(), eventUpdates); err != nil {
+	if err := cp.eventSource.Start(ctx, registrationData, eventUpdates); err != nil {
 		return err
 	}
-	if err := cp.subscriptionSource.Start(ctx, integration.RegistrationData(), subscriptionUpdates); err != nil {
+	if err := cp.subscriptionSource.Start(ctx, registrationData, subscriptionUpdates); err != nil {
 		return err
 	}
 	for {
@@ -59,7 +90,11 @@ func (cp *ControlPlane) Register(ctx context.Context, integration Integration) e
 			}
 		case subscriptions := <-subscriptionUpdates:
 			cp.currentSubscriptions = subscriptions
-			cp.eventSource.OnSubscriptionUpdate(subjects(subscriptions))
+			if listener, ok := cp.eventSource.(SubscriptionFilterListener); ok {
+				listener.OnSubscriptionFilterUpdate(subscriptions)
+			} else {
+				cp.eventSource.OnSubscriptionUpdate(subjects(subscriptions))
+			}
 		case <-ctx.Done():
 			return nil
 		}
@@ -70,8 +105,11 @@ func (cp *ControlPlane) handle(ctx context.Context, eventUpdate EventUpdate, int
 	for _, subscription := range cp.currentSubscriptions {
 		if subscription.Event == eventUpdate.MetaData.Subject {
 			matcher := NewEventMatcherFromSubscription(subscription)
+			if provider, ok := integration.(LocalFilterProvider); ok {
+				matcher = matcher.WithLocalFilter(provider.LocalFilter())
+			}
 			if matcher.Matches(eventUpdate.KeptnEvent) {
-				if err := integration.OnEvent(context.WithValue(ctx, EventSenderKey, cp.eventSource.Sender()), eventUpdate.KeptnEvent); err != nil {
+				if err := integration.OnEvent(context.WithValue(ctx, EventSenderKey, cp.sender()), eventUpdate.KeptnEvent); err != nil {
 					if errors.Is(err, ErrEventHandleFatal) {
 						cp.logger.Errorf("Fatal error during handling of event: %v", err)
 						return err
@@ -84,6 +122,68 @@ func (cp *ControlPlane) handle(ctx context.Context, eventUpdate EventUpdate, int
 	return nil
 }
 
+// sender returns the EventSender of the event source. If a LogForwarder is configured,
+// the error logs of the sent events are forwarded as well
+func (cp *ControlPlane) sender() EventSender {
+	sender := cp.eventSource.Sender()
+	return func(ce models.KeptnContextExtendedCE) error {
+		data, err := withoutUserLabel(ce.Data)
+		if err != nil {
+			return fmt.Errorf("could not remove %s label from event: %w", UserLabel, err)
+		}
+		ce.Data = data
+		if cp.logForwarder == nil {
+			return sender(ce)
+		}
+		if err := cp.logForwarder.Forward(ce, cp.integrationID); err != nil {
+			cp.logger.Warnf("Could not forward log of event: %v", err)
+		}
+		if ce.Type != nil && *ce.Type == v0_2_0.ErrorLogEventName {
+			// error log events are only stored as uniform log entries
+			return nil
+		}
+		return sender(ce)
+	}
+}
+
+// withoutUserLabel removes the UserLabel from the labels of the given event data.
+// Only the API sets this label, based on the authenticated user, so integrations must not be able to fake it
+func withoutUserLabel(data interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &dataMap); err != nil {
+			// data that is not a JSON object cannot contain labels
+			return data, nil
+		}
+	}
+	labels, ok := dataMap["labels"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+	if _, ok := labels[UserLabel]; !ok {
+		return data, nil
+	}
+	newLabels := make(map[string]interface{}, len(labels))
+	for k, v := range labels {
+		if k != UserLabel {
+			newLabels[k] = v
+		}
+	}
+	newData := make(map[string]interface{}, len(dataMap))
+	for k, v := range dataMap {
+		newData[k] = v
+	}
+	newData["labels"] = newLabels
+	return newData, nil
+}
+
 func subjects(subscriptions []models.EventSubscription) []string {
 	var ret []string
 	for _, s := range subscriptions {
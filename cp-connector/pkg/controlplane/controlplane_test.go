@@ -1,10 +1,14 @@
-package controlplane
+package controlplane_test
 
 import (
 	"context"
 	"fmt"
 	"github.com/keptn/go-utils/pkg/api/models"
 	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/controlplane"
+	"github.com/keptn/keptn/cp-connector/pkg/controlplane/fake"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/stretchr/testify/require"
 	"reflect"
 	"testing"
@@ -13,7 +17,7 @@ import (
 
 type ExampleIntegration struct {
 	OnEventFn          func(ctx context.Context, ce models.KeptnContextExtendedCE) error
-	RegistrationDataFn func() RegistrationData
+	RegistrationDataFn func() controlplane.RegistrationData
 }
 
 func (e ExampleIntegration) OnEvent(ctx context.Context, ce models.KeptnContextExtendedCE) error {
@@ -23,64 +27,131 @@ func (e ExampleIntegration) OnEvent(ctx context.Context, ce models.KeptnContextE
 	panic("implement me")
 }
 
-func (e ExampleIntegration) RegistrationData() RegistrationData {
+func (e ExampleIntegration) RegistrationData() controlplane.RegistrationData {
 	if e.RegistrationDataFn != nil {
 		return e.RegistrationDataFn()
 	}
 	panic("implement me")
 }
 
+type FilteringIntegration struct {
+	ExampleIntegration
+	LocalFilterFn func() controlplane.LocalFilter
+}
+
+func (e FilteringIntegration) LocalFilter() controlplane.LocalFilter {
+	if e.LocalFilterFn != nil {
+		return e.LocalFilterFn()
+	}
+	panic("implement me")
+}
+
 func TestControlPlaneEventSourceFailsToStart(t *testing.T) {
-	ssm := &SubscriptionSourceMock{}
-	esm := &EventSourceMock{StartFn: func(ctx context.Context, data RegistrationData, ces chan EventUpdate) error {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+	}
+	esm := &fake.EventSourceMock{StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
 		return fmt.Errorf("error occured")
 	}}
-	integration := ExampleIntegration{RegistrationDataFn: func() RegistrationData { return RegistrationData{} }}
-	err := New(ssm, esm).Register(context.TODO(), integration)
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} }}
+	err := controlplane.New(ssm, esm).Register(context.TODO(), integration)
 	require.Error(t, err)
 }
 
+func TestControlPlaneStartsUnregisteredSubscriptionSource(t *testing.T) {
+	var startedWith controlplane.RegistrationData
+	esm := &fake.EventSourceMock{StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+		startedWith = data
+		return nil
+	}}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{Name: "integrationName"} }}
+	err := controlplane.New(controlplane.NewFixedSubscriptionSource(), esm).Register(ctx, integration)
+	require.NoError(t, err)
+	require.Equal(t, "integrationName", startedWith.Name)
+	require.Empty(t, startedWith.ID)
+}
+
+type SubscriptionFilterListenerMock struct {
+	fake.EventSourceMock
+	OnSubscriptionFilterUpdateFn func([]models.EventSubscription)
+}
+
+func (e *SubscriptionFilterListenerMock) OnSubscriptionFilterUpdate(subscriptions []models.EventSubscription) {
+	e.OnSubscriptionFilterUpdateFn(subscriptions)
+}
+
+func TestControlPlanePassesSubscriptionsToSubscriptionFilterListener(t *testing.T) {
+	subscriptions := []models.EventSubscription{{ID: "sub-1", Event: "sh.keptn.event.task.triggered", Filter: models.EventSubscriptionFilter{Projects: []string{"sockshop"}}}}
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+			go func() { c <- subscriptions }()
+			return nil
+		},
+	}
+	updates := make(chan []models.EventSubscription, 1)
+	esm := &SubscriptionFilterListenerMock{
+		EventSourceMock: fake.EventSourceMock{StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+			return nil
+		}},
+		OnSubscriptionFilterUpdateFn: func(s []models.EventSubscription) { updates <- s },
+	}
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} }}
+	go controlplane.New(ssm, esm).Register(context.TODO(), integration)
+
+	select {
+	case update := <-updates:
+		require.Equal(t, subscriptions, update)
+	case <-time.After(time.Second):
+		t.Fatal("subscriptions were not passed to the event source")
+	}
+}
+
 func TestControlPlaneSubscriptionSourceFailsToStart(t *testing.T) {
-	ssm := &SubscriptionSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
 			return fmt.Errorf("error occured")
 		},
 	}
-	esm := &EventSourceMock{StartFn: func(ctx context.Context, data RegistrationData, ces chan EventUpdate) error {
+	esm := &fake.EventSourceMock{StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
 		return nil
 	}}
-	integration := ExampleIntegration{RegistrationDataFn: func() RegistrationData { return RegistrationData{} }}
-	err := New(ssm, esm).Register(context.TODO(), integration)
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} }}
+	err := controlplane.New(ssm, esm).Register(context.TODO(), integration)
 	require.Error(t, err)
 }
 
 func TestControlPlaneInboundEventIsForwardedToIntegration(t *testing.T) {
-	var eventChan chan EventUpdate
+	var eventChan chan controlplane.EventUpdate
 	var subsChan chan []models.EventSubscription
 	var integrationReceivedEvent models.KeptnContextExtendedCE
-	eventUpdate := EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
+	eventUpdate := controlplane.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
 
 	callBackSender := func(ce models.KeptnContextExtendedCE) error { return nil }
 
-	ssm := &SubscriptionSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
 			subsChan = c
 			return nil
 		},
 	}
-	esm := &EventSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, ces chan EventUpdate) error {
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
 			eventChan = ces
 			return nil
 		},
 		OnSubscriptionUpdateFn: func(strings []string) {},
-		SenderFn:               func() EventSender { return callBackSender },
+		SenderFn:               func() controlplane.EventSender { return callBackSender },
 	}
 
-	controlPlane := New(ssm, esm)
+	controlPlane := controlplane.New(ssm, esm)
 
 	integration := ExampleIntegration{
-		RegistrationDataFn: func() RegistrationData { return RegistrationData{} },
+		RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
 		OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
 			integrationReceivedEvent = ce
 			return nil
@@ -100,31 +171,32 @@ func TestControlPlaneInboundEventIsForwardedToIntegration(t *testing.T) {
 }
 
 func TestControlPlaneIntegrationOnEventThrowsIgnoreableError(t *testing.T) {
-	var eventChan chan EventUpdate
+	var eventChan chan controlplane.EventUpdate
 	var subsChan chan []models.EventSubscription
 	var integrationReceivedEvent bool
 
 	callBackSender := func(ce models.KeptnContextExtendedCE) error { return nil }
 
-	ssm := &SubscriptionSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
 			subsChan = c
 			return nil
 		},
 	}
-	esm := &EventSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, ces chan EventUpdate) error {
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
 			eventChan = ces
 			return nil
 		},
 		OnSubscriptionUpdateFn: func(strings []string) {},
-		SenderFn:               func() EventSender { return callBackSender },
+		SenderFn:               func() controlplane.EventSender { return callBackSender },
 	}
 
-	controlPlane := New(ssm, esm)
+	controlPlane := controlplane.New(ssm, esm)
 
 	integration := ExampleIntegration{
-		RegistrationDataFn: func() RegistrationData { return RegistrationData{} },
+		RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
 		OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
 			integrationReceivedEvent = true
 			return fmt.Errorf("could not handle event: %w", fmt.Errorf("error occured"))
@@ -136,41 +208,42 @@ func TestControlPlaneIntegrationOnEventThrowsIgnoreableError(t *testing.T) {
 	require.Eventually(t, func() bool { return eventChan != nil }, time.Second, time.Millisecond*100)
 
 	subsChan <- []models.EventSubscription{{ID: "some-id", Event: "sh.keptn.event.echo.triggered", Filter: models.EventSubscriptionFilter{}}}
-	eventChan <- EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
+	eventChan <- controlplane.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
 
 	require.Eventually(t, func() bool { return integrationReceivedEvent }, time.Second, time.Millisecond*100)
 	require.Never(t, func() bool { return controlPlaneErr != nil }, time.Second, time.Millisecond*100)
 }
 
 func TestControlPlaneIntegrationOnEventThrowsFatalError(t *testing.T) {
-	var eventChan chan EventUpdate
+	var eventChan chan controlplane.EventUpdate
 	var subsChan chan []models.EventSubscription
 	var integrationReceivedEvent bool
 
 	callBackSender := func(ce models.KeptnContextExtendedCE) error { return nil }
 
-	ssm := &SubscriptionSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
 			subsChan = c
 			return nil
 		},
 	}
-	esm := &EventSourceMock{
-		StartFn: func(ctx context.Context, data RegistrationData, ces chan EventUpdate) error {
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
 			eventChan = ces
 			return nil
 		},
 		OnSubscriptionUpdateFn: func(strings []string) {},
-		SenderFn:               func() EventSender { return callBackSender },
+		SenderFn:               func() controlplane.EventSender { return callBackSender },
 	}
 
-	controlPlane := New(ssm, esm)
+	controlPlane := controlplane.New(ssm, esm)
 
 	integration := ExampleIntegration{
-		RegistrationDataFn: func() RegistrationData { return RegistrationData{} },
+		RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
 		OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
 			integrationReceivedEvent = true
-			return fmt.Errorf("could not handle event: %w", ErrEventHandleFatal)
+			return fmt.Errorf("could not handle event: %w", controlplane.ErrEventHandleFatal)
 		},
 	}
 	var controlPlaneErr error
@@ -179,8 +252,238 @@ func TestControlPlaneIntegrationOnEventThrowsFatalError(t *testing.T) {
 	require.Eventually(t, func() bool { return eventChan != nil }, time.Second, time.Millisecond*100)
 
 	subsChan <- []models.EventSubscription{{ID: "some-id", Event: "sh.keptn.event.echo.triggered", Filter: models.EventSubscriptionFilter{}}}
-	eventChan <- EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
+	eventChan <- controlplane.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
 
 	require.Eventually(t, func() bool { return integrationReceivedEvent }, time.Second, time.Millisecond*100)
 	require.Eventually(t, func() bool { return controlPlaneErr != nil }, time.Second, time.Millisecond*100)
 }
+
+func TestControlPlaneInboundEventIsFilteredByLocalFilter(t *testing.T) {
+	var eventChan chan controlplane.EventUpdate
+	var subsChan chan []models.EventSubscription
+	receivedEvents := make(chan string, 2)
+	newEventUpdate := func(id string, team string) controlplane.EventUpdate {
+		return controlplane.EventUpdate{
+			KeptnEvent: models.KeptnContextExtendedCE{ID: id, Type: strutils.Stringp("sh.keptn.event.echo.triggered"), Data: v0_2_0.EventData{Project: "pr1", Labels: map[string]string{"team": team}}},
+			MetaData:   controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"},
+		}
+	}
+
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+			subsChan = c
+			return nil
+		},
+	}
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+			eventChan = ces
+			return nil
+		},
+		OnSubscriptionUpdateFn: func(strings []string) {},
+		SenderFn:               func() controlplane.EventSender { return func(ce models.KeptnContextExtendedCE) error { return nil } },
+	}
+
+	controlPlane := controlplane.New(ssm, esm)
+
+	integration := FilteringIntegration{
+		ExampleIntegration: ExampleIntegration{
+			RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
+			OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
+				receivedEvents <- ce.ID
+				return nil
+			},
+		},
+		LocalFilterFn: func() controlplane.LocalFilter {
+			return controlplane.LocalFilter{Labels: []filter.LabelSelector{{Key: "team", Operator: "=", Value: "payments"}}}
+		},
+	}
+	go controlPlane.Register(context.TODO(), integration)
+	require.Eventually(t, func() bool { return subsChan != nil }, time.Second, time.Millisecond*100)
+	require.Eventually(t, func() bool { return eventChan != nil }, time.Second, time.Millisecond*100)
+
+	subsChan <- []models.EventSubscription{{ID: "some-id", Event: "sh.keptn.event.echo.triggered", Filter: models.EventSubscriptionFilter{Projects: []string{"!pr2"}}}}
+	eventChan <- newEventUpdate("checkout-event", "checkout")
+	eventChan <- newEventUpdate("payments-event", "payments")
+
+	select {
+	case id := <-receivedEvents:
+		require.Equal(t, "payments-event", id)
+	case <-time.After(time.Second):
+		t.Fatal("integration did not receive the event")
+	}
+}
+
+func TestControlPlaneRegistrationFails(t *testing.T) {
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "", fmt.Errorf("error occured") },
+	}
+	esm := &fake.EventSourceMock{}
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} }}
+	err := controlplane.New(ssm, esm).Register(context.TODO(), integration)
+	require.Error(t, err)
+}
+
+func TestControlPlaneSourcesAreStartedWithRegisteredIntegration(t *testing.T) {
+	var registeredData controlplane.RegistrationData
+	var eventSourceData controlplane.RegistrationData
+	var subscriptionSourceData controlplane.RegistrationData
+
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) {
+			registeredData = data
+			return "some-id", nil
+		},
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+			subscriptionSourceData = data
+			return fmt.Errorf("error occured")
+		},
+	}
+	esm := &fake.EventSourceMock{StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+		eventSourceData = data
+		return nil
+	}}
+	integration := ExampleIntegration{RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{Name: "my-service"} }}
+	err := controlplane.New(ssm, esm).Register(context.TODO(), integration)
+	require.Error(t, err)
+
+	require.Equal(t, "my-service", registeredData.Name)
+	require.Equal(t, controlplane.Version(), registeredData.MetaData.DistributorVersion)
+	require.Equal(t, "some-id", eventSourceData.ID)
+	require.Equal(t, "some-id", subscriptionSourceData.ID)
+}
+
+func TestControlPlaneOutboundEventLogIsForwarded(t *testing.T) {
+	var eventChan chan controlplane.EventUpdate
+	var subsChan chan []models.EventSubscription
+	var sentEvents []models.KeptnContextExtendedCE
+	var forwardedEvents []models.KeptnContextExtendedCE
+	var forwardedIntegrationID string
+	sent := make(chan struct{})
+
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+			subsChan = c
+			return nil
+		},
+	}
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+			eventChan = ces
+			return nil
+		},
+		OnSubscriptionUpdateFn: func(strings []string) {},
+		SenderFn: func() controlplane.EventSender {
+			return func(ce models.KeptnContextExtendedCE) error {
+				sentEvents = append(sentEvents, ce)
+				return nil
+			}
+		},
+	}
+	lfm := &fake.LogForwarderMock{
+		ForwardFn: func(ce models.KeptnContextExtendedCE, integrationID string) error {
+			forwardedEvents = append(forwardedEvents, ce)
+			forwardedIntegrationID = integrationID
+			return nil
+		},
+	}
+
+	controlPlane := controlplane.New(ssm, esm, controlplane.WithLogForwarder(lfm))
+
+	integration := ExampleIntegration{
+		RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
+		OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
+			sender := ctx.Value(controlplane.EventSenderKey).(controlplane.EventSender)
+			require.NoError(t, sender(models.KeptnContextExtendedCE{ID: "finished-id", Type: strutils.Stringp("sh.keptn.event.echo.finished")}))
+			require.NoError(t, sender(models.KeptnContextExtendedCE{ID: "log-id", Type: strutils.Stringp(v0_2_0.ErrorLogEventName)}))
+			close(sent)
+			return nil
+		},
+	}
+	go controlPlane.Register(context.TODO(), integration)
+	require.Eventually(t, func() bool { return subsChan != nil }, time.Second, time.Millisecond*100)
+	require.Eventually(t, func() bool { return eventChan != nil }, time.Second, time.Millisecond*100)
+
+	subsChan <- []models.EventSubscription{{ID: "some-id", Event: "sh.keptn.event.echo.triggered", Filter: models.EventSubscriptionFilter{}}}
+	eventChan <- controlplane.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("integration did not send events")
+	}
+	require.Equal(t, 2, len(forwardedEvents))
+	require.Equal(t, "some-id", forwardedIntegrationID)
+	// error log events are not sent to the event source
+	require.Equal(t, 1, len(sentEvents))
+	require.Equal(t, "finished-id", sentEvents[0].ID)
+}
+
+func TestControlPlaneOutboundEventUserLabelIsRemoved(t *testing.T) {
+	var eventChan chan controlplane.EventUpdate
+	var subsChan chan []models.EventSubscription
+	var sentEvents []models.KeptnContextExtendedCE
+	sent := make(chan struct{})
+
+	ssm := &fake.SubscriptionSourceMock{
+		RegisterFn: func(data controlplane.RegistrationData) (string, error) { return "some-id", nil },
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+			subsChan = c
+			return nil
+		},
+	}
+	esm := &fake.EventSourceMock{
+		StartFn: func(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+			eventChan = ces
+			return nil
+		},
+		OnSubscriptionUpdateFn: func(strings []string) {},
+		SenderFn: func() controlplane.EventSender {
+			return func(ce models.KeptnContextExtendedCE) error {
+				sentEvents = append(sentEvents, ce)
+				return nil
+			}
+		},
+	}
+
+	controlPlane := controlplane.New(ssm, esm)
+
+	integration := ExampleIntegration{
+		RegistrationDataFn: func() controlplane.RegistrationData { return controlplane.RegistrationData{} },
+		OnEventFn: func(ctx context.Context, ce models.KeptnContextExtendedCE) error {
+			sender := ctx.Value(controlplane.EventSenderKey).(controlplane.EventSender)
+			require.NoError(t, sender(models.KeptnContextExtendedCE{
+				ID:   "map-id",
+				Type: strutils.Stringp("sh.keptn.event.echo.finished"),
+				Data: map[string]interface{}{"project": "my-project", "labels": map[string]interface{}{controlplane.UserLabel: "admin", "foo": "bar"}},
+			}))
+			require.NoError(t, sender(models.KeptnContextExtendedCE{
+				ID:   "struct-id",
+				Type: strutils.Stringp("sh.keptn.event.echo.finished"),
+				Data: v0_2_0.EventData{Project: "my-project", Labels: map[string]string{controlplane.UserLabel: "admin"}},
+			}))
+			close(sent)
+			return nil
+		},
+	}
+	go controlPlane.Register(context.TODO(), integration)
+	require.Eventually(t, func() bool { return subsChan != nil }, time.Second, time.Millisecond*100)
+	require.Eventually(t, func() bool { return eventChan != nil }, time.Second, time.Millisecond*100)
+
+	subsChan <- []models.EventSubscription{{ID: "some-id", Event: "sh.keptn.event.echo.triggered", Filter: models.EventSubscriptionFilter{}}}
+	eventChan <- controlplane.EventUpdate{KeptnEvent: models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.triggered")}, MetaData: controlplane.EventUpdateMetaData{Subject: "sh.keptn.event.echo.triggered"}}
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("integration did not send events")
+	}
+	require.Equal(t, 2, len(sentEvents))
+	require.Equal(t, map[string]interface{}{"project": "my-project", "labels": map[string]interface{}{"foo": "bar"}}, sentEvents[0].Data)
+	data, ok := sentEvents[1].Data.(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "my-project", data["project"])
+	require.Equal(t, map[string]interface{}{}, data["labels"])
+}
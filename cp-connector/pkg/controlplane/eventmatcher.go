@@ -2,17 +2,34 @@ package controlplane
 
 import (
 	"github.com/keptn/go-utils/pkg/api/models"
-	"github.com/keptn/go-utils/pkg/common/sliceutils"
 	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"strings"
 )
 
 // EventMatcher is used to check whether an event contains is containing information
-// about a specif event, stage or service
+// about a specif event, stage or service. Projects, stages and services prefixed with
+// the filter.ExclusionPrefix are excluded. Additionally, events can be selected by their labels
+// and by expressions over their data
 type EventMatcher struct {
-	Project string
-	Stage   string
-	Service string
+	Project     string
+	Stage       string
+	Service     string
+	Labels      []filter.LabelSelector
+	Expressions []filter.Expression
+}
+
+// LocalFilter contains the filters of an Integration which are evaluated locally
+// in addition to the filters of its subscriptions
+type LocalFilter struct {
+	Labels      []filter.LabelSelector
+	Expressions []filter.Expression
+}
+
+// LocalFilterProvider can be implemented by an Integration to only receive events
+// matching its LocalFilter, e.g. to split the work between several integrations of the same type
+type LocalFilterProvider interface {
+	LocalFilter() LocalFilter
 }
 
 // NewEventMatcherFromSubscription creates a new EventMatcher that is configured
@@ -25,6 +42,13 @@ func NewEventMatcherFromSubscription(subscription models.EventSubscription) *Eve
 	}
 }
 
+// WithLocalFilter returns a copy of the EventMatcher which additionally evaluates the given LocalFilter
+func (ef EventMatcher) WithLocalFilter(localFilter LocalFilter) *EventMatcher {
+	ef.Labels = localFilter.Labels
+	ef.Expressions = localFilter.Expressions
+	return &ef
+}
+
 // Matches checks whether a Keptn event matches the information of the currently configured
 // EventMatcher
 func (ef EventMatcher) Matches(e models.KeptnContextExtendedCE) bool {
@@ -33,10 +57,27 @@ func (ef EventMatcher) Matches(e models.KeptnContextExtendedCE) bool {
 		return false
 	}
 
-	if ef.Project != "" && !sliceutils.ContainsStr(strings.Split(ef.Project, ","), generalEventData.Project) ||
-		ef.Stage != "" && !sliceutils.ContainsStr(strings.Split(ef.Stage, ","), generalEventData.Stage) ||
-		ef.Service != "" && !sliceutils.ContainsStr(strings.Split(ef.Service, ","), generalEventData.Service) {
+	if !filter.MatchesList(ef.Project, generalEventData.Project) ||
+		!filter.MatchesList(ef.Stage, generalEventData.Stage) ||
+		!filter.MatchesList(ef.Service, generalEventData.Service) {
 		return false
 	}
+
+	for _, selector := range ef.Labels {
+		if !selector.Matches(generalEventData.Labels) {
+			return false
+		}
+	}
+	if len(ef.Expressions) > 0 {
+		var data interface{}
+		if err := e.DataAs(&data); err != nil {
+			return false
+		}
+		for _, expression := range ef.Expressions {
+			if !expression.Matches(data) {
+				return false
+			}
+		}
+	}
 	return true
 }
@@ -3,6 +3,7 @@ package controlplane
 import (
 	"github.com/keptn/go-utils/pkg/api/models"
 	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -264,3 +265,42 @@ func TestEventMatcher_Matches(t *testing.T) {
 		})
 	}
 }
+
+func TestEventMatcher_MatchesExclusions(t *testing.T) {
+	e := models.KeptnContextExtendedCE{Data: v0_2_0.EventData{
+		Project: "pr1",
+		Stage:   "st1",
+		Service: "sv1",
+	}}
+	require.False(t, EventMatcher{Project: "!pr1"}.Matches(e))
+	require.False(t, EventMatcher{Stage: "st1,st2,!st1"}.Matches(e))
+	require.True(t, EventMatcher{Service: "!sv2,!sv3"}.Matches(e))
+	require.True(t, EventMatcher{Project: "pr1,!pr2"}.Matches(e))
+}
+
+func TestEventMatcher_MatchesLocalFilter(t *testing.T) {
+	e := models.KeptnContextExtendedCE{Data: v0_2_0.EventData{
+		Project: "pr1",
+		Stage:   "st1",
+		Service: "sv1",
+		Labels:  map[string]string{"team": "payments"},
+	}}
+	matcher := NewEventMatcherFromSubscription(models.EventSubscription{Filter: models.EventSubscriptionFilter{Projects: []string{"pr1"}}})
+	require.True(t, matcher.Matches(e))
+
+	labels, err := filter.ParseLabelSelectors("labels.team=payments")
+	require.NoError(t, err)
+	expressions, err := filter.ParseExpressions(`$.stage == "st1"`)
+	require.NoError(t, err)
+	require.True(t, matcher.WithLocalFilter(LocalFilter{Labels: labels, Expressions: expressions}).Matches(e))
+
+	labels, err = filter.ParseLabelSelectors("team=checkout")
+	require.NoError(t, err)
+	require.False(t, matcher.WithLocalFilter(LocalFilter{Labels: labels}).Matches(e))
+
+	expressions, err = filter.ParseExpressions(`$.stage != "st1"`)
+	require.NoError(t, err)
+	require.False(t, matcher.WithLocalFilter(LocalFilter{Expressions: expressions}).Matches(e))
+	// the local filter does not change the original matcher
+	require.Empty(t, matcher.Labels)
+}
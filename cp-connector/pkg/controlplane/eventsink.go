@@ -0,0 +1,32 @@
+package controlplane
+
+import (
+	"fmt"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// EventSink is anything that can be used
+// to send events to the Keptn Control Plane
+type EventSink interface {
+	// Send sends the event to the Keptn Control Plane
+	Send(models.KeptnContextExtendedCE) error
+}
+
+// APIEventSink is an implementation of EventSink
+// that is sending the events to the Keptn API
+type APIEventSink struct {
+	eventAPI api.APIV1Interface
+}
+
+// NewAPIEventSink creates a new APIEventSink
+func NewAPIEventSink(eventAPI api.APIV1Interface) *APIEventSink {
+	return &APIEventSink{eventAPI: eventAPI}
+}
+
+func (s *APIEventSink) Send(ce models.KeptnContextExtendedCE) error {
+	if _, err := s.eventAPI.SendEvent(ce); err != nil {
+		return fmt.Errorf("could not send event to Keptn API: %s", err.GetMessage())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package controlplane
+
+import (
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type APIInterfaceMock struct {
+	SendEventFn func(models.KeptnContextExtendedCE) (*models.EventContext, *models.Error)
+}
+
+func (m *APIInterfaceMock) SendEvent(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+	if m.SendEventFn != nil {
+		return m.SendEventFn(event)
+	}
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) TriggerEvaluation(project string, stage string, service string, evaluation models.Evaluation) (*models.EventContext, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) CreateProject(project models.CreateProject) (string, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) UpdateProject(project models.CreateProject) (string, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) DeleteProject(project models.Project) (*models.DeleteProjectResponse, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) CreateService(project string, service models.CreateService) (string, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) DeleteService(project string, service string) (*models.DeleteServiceResponse, *models.Error) {
+	panic("implement me")
+}
+
+func (m *APIInterfaceMock) GetMetadata() (*models.Metadata, *models.Error) {
+	panic("implement me")
+}
+
+func TestAPIEventSink(t *testing.T) {
+	var sentEvent models.KeptnContextExtendedCE
+	apiMock := &APIInterfaceMock{
+		SendEventFn: func(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+			sentEvent = event
+			return &models.EventContext{KeptnContext: strutils.Stringp("my-context")}, nil
+		},
+	}
+	event := models.KeptnContextExtendedCE{ID: "some-id", Type: strutils.Stringp("sh.keptn.event.echo.started")}
+	err := NewAPIEventSink(apiMock).Send(event)
+	require.NoError(t, err)
+	require.Equal(t, event, sentEvent)
+}
+
+func TestAPIEventSinkSendFails(t *testing.T) {
+	apiMock := &APIInterfaceMock{
+		SendEventFn: func(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+			return nil, &models.Error{Code: 500, Message: strutils.Stringp("error occured")}
+		},
+	}
+	err := NewAPIEventSink(apiMock).Send(models.KeptnContextExtendedCE{ID: "some-id"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error occured")
+}
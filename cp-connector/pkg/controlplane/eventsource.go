@@ -45,6 +45,15 @@ type EventSource interface {
 	Stop() error
 }
 
+// SubscriptionFilterListener can be implemented by an EventSource which needs the filters of the
+// subscriptions, rather than only their subjects. If it is implemented, the ControlPlane calls
+// OnSubscriptionFilterUpdate instead of OnSubscriptionUpdate
+type SubscriptionFilterListener interface {
+	// OnSubscriptionFilterUpdate can be called to tell the EventSource that
+	// the current subscriptions have been changed
+	OnSubscriptionFilterUpdate([]models.EventSubscription)
+}
+
 // NATSEventSource is an implementation of EventSource
 // that is using the NATS event broker internally
 type NATSEventSource struct {
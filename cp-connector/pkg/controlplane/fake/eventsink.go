@@ -0,0 +1,19 @@
+package fake
+
+import (
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// EventSinkMock is a fake of controlplane.EventSink which records the sent events
+type EventSinkMock struct {
+	SendFn    func(models.KeptnContextExtendedCE) error
+	SendCalls []models.KeptnContextExtendedCE
+}
+
+func (e *EventSinkMock) Send(ce models.KeptnContextExtendedCE) error {
+	e.SendCalls = append(e.SendCalls, ce)
+	if e.SendFn != nil {
+		return e.SendFn(ce)
+	}
+	panic("implement me")
+}
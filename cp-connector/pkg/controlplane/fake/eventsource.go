@@ -0,0 +1,43 @@
+package fake
+
+import (
+	"context"
+	"github.com/keptn/keptn/cp-connector/pkg/controlplane"
+)
+
+// EventSourceMock is a fake of controlplane.EventSource
+type EventSourceMock struct {
+	StartFn                func(context.Context, controlplane.RegistrationData, chan controlplane.EventUpdate) error
+	OnSubscriptionUpdateFn func([]string)
+	SenderFn               func() controlplane.EventSender
+	StopFn                 func() error
+}
+
+func (e *EventSourceMock) Start(ctx context.Context, data controlplane.RegistrationData, ces chan controlplane.EventUpdate) error {
+	if e.StartFn != nil {
+		return e.StartFn(ctx, data, ces)
+	}
+	panic("implement me")
+}
+
+func (e *EventSourceMock) OnSubscriptionUpdate(strings []string) {
+	if e.OnSubscriptionUpdateFn != nil {
+		e.OnSubscriptionUpdateFn(strings)
+		return
+	}
+	panic("implement me")
+}
+
+func (e *EventSourceMock) Sender() controlplane.EventSender {
+	if e.SenderFn != nil {
+		return e.SenderFn()
+	}
+	panic("implement me")
+}
+
+func (e *EventSourceMock) Stop() error {
+	if e.StopFn != nil {
+		return e.StopFn()
+	}
+	panic("implement me")
+}
@@ -0,0 +1,17 @@
+package fake
+
+import (
+	"github.com/keptn/go-utils/pkg/api/models"
+)
+
+// LogForwarderMock is a fake of controlplane.LogForwarder
+type LogForwarderMock struct {
+	ForwardFn func(models.KeptnContextExtendedCE, string) error
+}
+
+func (l *LogForwarderMock) Forward(keptnEvent models.KeptnContextExtendedCE, integrationID string) error {
+	if l.ForwardFn != nil {
+		return l.ForwardFn(keptnEvent, integrationID)
+	}
+	panic("implement me")
+}
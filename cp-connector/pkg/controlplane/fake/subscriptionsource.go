@@ -0,0 +1,29 @@
+// Package fake provides fakes of the interfaces of the controlplane package,
+// which can be used for testing Keptn services
+package fake
+
+import (
+	"context"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/keptn/cp-connector/pkg/controlplane"
+)
+
+// SubscriptionSourceMock is a fake of controlplane.SubscriptionSource which also implements controlplane.IntegrationRegistrar
+type SubscriptionSourceMock struct {
+	RegisterFn func(data controlplane.RegistrationData) (string, error)
+	StartFn    func(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error
+}
+
+func (u *SubscriptionSourceMock) Register(data controlplane.RegistrationData) (string, error) {
+	if u.RegisterFn != nil {
+		return u.RegisterFn(data)
+	}
+	panic("implement me")
+}
+
+func (u *SubscriptionSourceMock) Start(ctx context.Context, data controlplane.RegistrationData, c chan []models.EventSubscription) error {
+	if u.StartFn != nil {
+		return u.StartFn(ctx, data, c)
+	}
+	panic("implement me")
+}
@@ -0,0 +1,157 @@
+package controlplane
+
+import (
+	"context"
+	"github.com/benbjohnson/clock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
+	"github.com/keptn/keptn/cp-connector/pkg/logger"
+	"sync"
+	"time"
+)
+
+// HTTPEventSource is an implementation of EventSource
+// that is polling the open .triggered events from the Keptn API.
+// It can be used by Keptn services which are not able to connect to the NATS event broker,
+// e.g. because they are running in a remote execution plane
+type HTTPEventSource struct {
+	mutex                sync.Mutex
+	currentSubscriptions []models.EventSubscription
+	shipyardControlAPI   api.ShipyardControlV1Interface
+	eventSink            EventSink
+	clock                clock.Clock
+	pollInterval         time.Duration
+	sentEvents           map[string]map[string]struct{}
+	quitC                chan struct{}
+	stopOnce             sync.Once
+	logger               logger.Logger
+}
+
+// WithPollInterval specifies the interval the event source should
+// use when polling for open .triggered events
+func WithPollInterval(interval time.Duration) func(s *HTTPEventSource) {
+	return func(s *HTTPEventSource) {
+		s.pollInterval = interval
+	}
+}
+
+// NewHTTPEventSource creates a new HTTPEventSource.
+// Events sent by the integration are passed to the given EventSink
+func NewHTTPEventSource(shipyardControlAPI api.ShipyardControlV1Interface, eventSink EventSink, options ...func(s *HTTPEventSource)) *HTTPEventSource {
+	eventSource := &HTTPEventSource{
+		currentSubscriptions: []models.EventSubscription{},
+		shipyardControlAPI:   shipyardControlAPI,
+		eventSink:            eventSink,
+		clock:                clock.New(),
+		pollInterval:         time.Second * 10,
+		sentEvents:           map[string]map[string]struct{}{},
+		quitC:                make(chan struct{}),
+		logger:               logger.NewDefaultLogger(),
+	}
+	for _, o := range options {
+		o(eventSource)
+	}
+	return eventSource
+}
+
+func (h *HTTPEventSource) Start(ctx context.Context, registrationData RegistrationData, eventChannel chan EventUpdate) error {
+	ticker := h.clock.Ticker(h.pollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.quitC:
+				return
+			case <-ticker.C:
+				h.pollEvents(ctx, eventChannel)
+			}
+		}
+	}()
+	return nil
+}
+
+// OnSubscriptionUpdate polls the open .triggered events of the subjects without filtering them
+func (h *HTTPEventSource) OnSubscriptionUpdate(subjects []string) {
+	subscriptions := []models.EventSubscription{}
+	for _, subject := range dedup(subjects) {
+		subscriptions = append(subscriptions, models.EventSubscription{Event: subject})
+	}
+	h.OnSubscriptionFilterUpdate(subscriptions)
+}
+
+// OnSubscriptionFilterUpdate polls the open .triggered events of each subscription using its filter
+func (h *HTTPEventSource) OnSubscriptionFilterUpdate(subscriptions []models.EventSubscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.currentSubscriptions = append([]models.EventSubscription{}, subscriptions...)
+}
+
+func (h *HTTPEventSource) Sender() EventSender {
+	return h.eventSink.Send
+}
+
+func (h *HTTPEventSource) Stop() error {
+	h.stopOnce.Do(func() { close(h.quitC) })
+	return nil
+}
+
+// pollEvents passes the open .triggered events of all subscriptions to the channel,
+// unless they have already been passed for the subject of the subscription in a previous run
+func (h *HTTPEventSource) pollEvents(ctx context.Context, eventChannel chan EventUpdate) {
+	h.mutex.Lock()
+	subscriptions := append([]models.EventSubscription{}, h.currentSubscriptions...)
+	h.mutex.Unlock()
+
+	sentEvents := map[string]map[string]struct{}{}
+	for _, subscription := range subscriptions {
+		subject := subscription.Event
+		if sentEvents[subject] == nil {
+			sentEvents[subject] = map[string]struct{}{}
+		}
+		events, err := h.shipyardControlAPI.GetOpenTriggeredEvents(getEventFilterForSubscription(subscription))
+		if err != nil {
+			h.logger.Warnf("Could not retrieve events of type %s: %v", subject, err)
+			// keep the events of the last run, otherwise they would be sent again
+			for id := range h.sentEvents[subject] {
+				sentEvents[subject][id] = struct{}{}
+			}
+			continue
+		}
+		// events which are not open anymore are removed from the list of sent events
+		for _, event := range events {
+			_, sentInThisRun := sentEvents[subject][event.ID]
+			sentEvents[subject][event.ID] = struct{}{}
+			if _, ok := h.sentEvents[subject][event.ID]; ok || sentInThisRun {
+				continue
+			}
+			select {
+			case eventChannel <- EventUpdate{KeptnEvent: *event, MetaData: EventUpdateMetaData{Subject: subject}}:
+			case <-ctx.Done():
+				return
+			case <-h.quitC:
+				return
+			}
+		}
+	}
+	h.sentEvents = sentEvents
+}
+
+// getEventFilterForSubscription returns the filter for retrieving the open .triggered events of the subscription.
+// If exactly one project, stage or service is included in the filter of the subscription, the events are
+// retrieved for it. All other filters are evaluated by the ControlPlane
+func getEventFilterForSubscription(subscription models.EventSubscription) api.EventFilter {
+	eventFilter := api.EventFilter{EventType: subscription.Event}
+	if included := filter.IncludedValues(subscription.Filter.Projects); len(included) == 1 {
+		eventFilter.Project = included[0]
+	}
+	if included := filter.IncludedValues(subscription.Filter.Stages); len(included) == 1 {
+		eventFilter.Stage = included[0]
+	}
+	if included := filter.IncludedValues(subscription.Filter.Services); len(included) == 1 {
+		eventFilter.Service = included[0]
+	}
+	return eventFilter
+}
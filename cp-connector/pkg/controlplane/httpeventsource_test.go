@@ -0,0 +1,214 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"github.com/benbjohnson/clock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ShipyardControlAPIMock struct {
+	mutex                    sync.Mutex
+	GetOpenTriggeredEventsFn func(api.EventFilter) ([]*models.KeptnContextExtendedCE, error)
+}
+
+func (m *ShipyardControlAPIMock) GetOpenTriggeredEvents(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.GetOpenTriggeredEventsFn != nil {
+		return m.GetOpenTriggeredEventsFn(filter)
+	}
+	panic("implement me")
+}
+
+func (m *ShipyardControlAPIMock) setGetOpenTriggeredEventsFn(fn func(api.EventFilter) ([]*models.KeptnContextExtendedCE, error)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.GetOpenTriggeredEventsFn = fn
+}
+
+func openEvents(ids ...string) func(api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	return func(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+		events := []*models.KeptnContextExtendedCE{}
+		for _, id := range ids {
+			events = append(events, &models.KeptnContextExtendedCE{ID: filter.EventType + "-" + id})
+		}
+		return events, nil
+	}
+}
+
+func requireEventUpdate(t *testing.T, eventChannel chan EventUpdate, expectedID string, expectedSubject string) {
+	select {
+	case update := <-eventChannel:
+		require.Equal(t, expectedID, update.KeptnEvent.ID)
+		require.Equal(t, expectedSubject, update.MetaData.Subject)
+	case <-time.After(time.Second):
+		t.Fatalf("did not receive event %s", expectedID)
+	}
+}
+
+func requireNoEventUpdate(t *testing.T, eventChannel chan EventUpdate) {
+	select {
+	case update := <-eventChannel:
+		t.Fatalf("received unexpected event %s", update.KeptnEvent.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHTTPEventSource(t *testing.T) {
+	shipyardControlAPI := &ShipyardControlAPIMock{GetOpenTriggeredEventsFn: openEvents("1")}
+	eventSource := NewHTTPEventSource(shipyardControlAPI, nil, WithPollInterval(time.Second))
+	clock := clock.NewMock()
+	eventSource.clock = clock
+
+	eventChannel := make(chan EventUpdate)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	require.NoError(t, eventSource.Start(ctx, RegistrationData{}, eventChannel))
+	eventSource.OnSubscriptionUpdate([]string{"sh.keptn.event.task.triggered", "sh.keptn.event.task.triggered"})
+
+	clock.Add(time.Second)
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.task.triggered-1", "sh.keptn.event.task.triggered")
+	requireNoEventUpdate(t, eventChannel)
+
+	// events which have already been passed to the control plane are not passed again
+	shipyardControlAPI.setGetOpenTriggeredEventsFn(openEvents("1", "2"))
+	clock.Add(time.Second)
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.task.triggered-2", "sh.keptn.event.task.triggered")
+	requireNoEventUpdate(t, eventChannel)
+
+	eventSource.OnSubscriptionUpdate([]string{"sh.keptn.event.task.triggered", "sh.keptn.event.other.triggered"})
+	clock.Add(time.Second)
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.other.triggered-1", "sh.keptn.event.other.triggered")
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.other.triggered-2", "sh.keptn.event.other.triggered")
+	requireNoEventUpdate(t, eventChannel)
+}
+
+func TestHTTPEventSourcePollsSubscriptionsWithFilter(t *testing.T) {
+	filters := make(chan api.EventFilter, 10)
+	shipyardControlAPI := &ShipyardControlAPIMock{GetOpenTriggeredEventsFn: func(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+		filters <- filter
+		return openEvents("1")(filter)
+	}}
+	eventSource := NewHTTPEventSource(shipyardControlAPI, nil, WithPollInterval(time.Second))
+	clock := clock.NewMock()
+	eventSource.clock = clock
+
+	eventChannel := make(chan EventUpdate)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	require.NoError(t, eventSource.Start(ctx, RegistrationData{}, eventChannel))
+	eventSource.OnSubscriptionFilterUpdate([]models.EventSubscription{
+		{ID: "sub-1", Event: "sh.keptn.event.task.triggered", Filter: models.EventSubscriptionFilter{Projects: []string{"sockshop"}, Stages: []string{"dev"}}},
+		{ID: "sub-2", Event: "sh.keptn.event.task.triggered", Filter: models.EventSubscriptionFilter{Projects: []string{"podtato-head"}}},
+	})
+
+	clock.Add(time.Second)
+	// the event is retrieved for both subscriptions, but only passed once for its subject
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.task.triggered-1", "sh.keptn.event.task.triggered")
+	requireNoEventUpdate(t, eventChannel)
+	require.Equal(t, api.EventFilter{EventType: "sh.keptn.event.task.triggered", Project: "sockshop", Stage: "dev"}, <-filters)
+	require.Equal(t, api.EventFilter{EventType: "sh.keptn.event.task.triggered", Project: "podtato-head"}, <-filters)
+}
+
+func TestGetEventFilterForSubscription(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.EventSubscriptionFilter
+		want   api.EventFilter
+	}{
+		{name: "no filter", want: api.EventFilter{EventType: "sh.keptn.event.task.triggered"}},
+		{
+			name:   "single project, stage and service",
+			filter: models.EventSubscriptionFilter{Projects: []string{"sockshop"}, Stages: []string{"dev"}, Services: []string{"carts"}},
+			want:   api.EventFilter{EventType: "sh.keptn.event.task.triggered", Project: "sockshop", Stage: "dev", Service: "carts"},
+		},
+		{
+			name:   "several projects and excluded stages",
+			filter: models.EventSubscriptionFilter{Projects: []string{"sockshop", "podtato-head"}, Stages: []string{"!dev", "hardening"}},
+			want:   api.EventFilter{EventType: "sh.keptn.event.task.triggered", Stage: "hardening"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := models.EventSubscription{Event: "sh.keptn.event.task.triggered", Filter: tt.filter}
+			require.Equal(t, tt.want, getEventFilterForSubscription(subscription))
+		})
+	}
+}
+
+func TestHTTPEventSourceAPIFails(t *testing.T) {
+	shipyardControlAPI := &ShipyardControlAPIMock{GetOpenTriggeredEventsFn: openEvents("1")}
+	eventSource := NewHTTPEventSource(shipyardControlAPI, nil)
+	clock := clock.NewMock()
+	eventSource.clock = clock
+
+	eventChannel := make(chan EventUpdate)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	require.NoError(t, eventSource.Start(ctx, RegistrationData{}, eventChannel))
+	eventSource.OnSubscriptionUpdate([]string{"sh.keptn.event.task.triggered"})
+
+	clock.Add(10 * time.Second)
+	requireEventUpdate(t, eventChannel, "sh.keptn.event.task.triggered-1", "sh.keptn.event.task.triggered")
+
+	shipyardControlAPI.setGetOpenTriggeredEventsFn(func(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+		return nil, fmt.Errorf("error occured")
+	})
+	clock.Add(10 * time.Second)
+	requireNoEventUpdate(t, eventChannel)
+
+	// the events sent before the failure are still known
+	shipyardControlAPI.setGetOpenTriggeredEventsFn(openEvents("1"))
+	clock.Add(10 * time.Second)
+	requireNoEventUpdate(t, eventChannel)
+}
+
+func TestHTTPEventSourceStop(t *testing.T) {
+	pollCount := 0
+	shipyardControlAPI := &ShipyardControlAPIMock{
+		GetOpenTriggeredEventsFn: func(filter api.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+			pollCount++
+			return nil, nil
+		},
+	}
+	eventSource := NewHTTPEventSource(shipyardControlAPI, nil)
+	clock := clock.NewMock()
+	eventSource.clock = clock
+
+	require.NoError(t, eventSource.Start(context.TODO(), RegistrationData{}, make(chan EventUpdate)))
+	eventSource.OnSubscriptionUpdate([]string{"sh.keptn.event.task.triggered"})
+	clock.Add(10 * time.Second)
+	require.Eventually(t, func() bool {
+		shipyardControlAPI.mutex.Lock()
+		defer shipyardControlAPI.mutex.Unlock()
+		return pollCount == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, eventSource.Stop())
+	require.NoError(t, eventSource.Stop())
+	clock.Add(10 * time.Second)
+	require.Never(t, func() bool {
+		shipyardControlAPI.mutex.Lock()
+		defer shipyardControlAPI.mutex.Unlock()
+		return pollCount > 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestHTTPEventSourceSender(t *testing.T) {
+	sentEvents := []models.KeptnContextExtendedCE{}
+	eventAPI := &APIInterfaceMock{SendEventFn: func(event models.KeptnContextExtendedCE) (*models.EventContext, *models.Error) {
+		sentEvents = append(sentEvents, event)
+		return &models.EventContext{}, nil
+	}}
+	eventSource := NewHTTPEventSource(&ShipyardControlAPIMock{}, NewAPIEventSink(eventAPI))
+
+	err := eventSource.Sender()(models.KeptnContextExtendedCE{ID: "some-id"})
+	require.NoError(t, err)
+	require.Equal(t, []models.KeptnContextExtendedCE{{ID: "some-id"}}, sentEvents)
+}
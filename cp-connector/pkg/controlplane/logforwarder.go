@@ -0,0 +1,79 @@
+package controlplane
+
+import (
+	"fmt"
+	"github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"strings"
+)
+
+// LogForwarder is anything that can be used to forward the error logs
+// contained in the events sent by an integration to the Keptn Control Plane
+type LogForwarder interface {
+	// Forward forwards the error log of the event, if there is one,
+	// on behalf of the integration with the given ID
+	Forward(keptnEvent models.KeptnContextExtendedCE, integrationID string) error
+}
+
+// UniformLogForwarder is an implementation of LogForwarder
+// that is storing the error logs as uniform log entries via the Keptn API.
+// It forwards the messages of .finished events with status 'errored' as well as
+// the messages of sh.keptn.log.error events
+type UniformLogForwarder struct {
+	logAPI api.LogsV1Interface
+}
+
+// NewUniformLogForwarder creates a new UniformLogForwarder
+func NewUniformLogForwarder(logAPI api.LogsV1Interface) *UniformLogForwarder {
+	return &UniformLogForwarder{logAPI: logAPI}
+}
+
+func (l *UniformLogForwarder) Forward(keptnEvent models.KeptnContextExtendedCE, integrationID string) error {
+	if keptnEvent.Type == nil {
+		return nil
+	}
+	if strings.HasSuffix(*keptnEvent.Type, ".finished") {
+		eventData := &v0_2_0.EventData{}
+		if err := v0_2_0.EventDataAs(keptnEvent, eventData); err != nil {
+			return fmt.Errorf("could not decode Keptn event data: %w", err)
+		}
+		if eventData.Status != v0_2_0.StatusErrored {
+			return nil
+		}
+		taskName, _, _ := v0_2_0.ParseTaskEventType(*keptnEvent.Type)
+		return l.log(models.LogEntry{
+			IntegrationID: integrationID,
+			Message:       eventData.Message,
+			KeptnContext:  keptnEvent.Shkeptncontext,
+			Task:          taskName,
+			TriggeredID:   keptnEvent.Triggeredid,
+		})
+	}
+	if *keptnEvent.Type == v0_2_0.ErrorLogEventName {
+		eventData := &v0_2_0.ErrorLogEvent{}
+		if err := v0_2_0.EventDataAs(keptnEvent, eventData); err != nil {
+			return fmt.Errorf("could not decode Keptn event data: %w", err)
+		}
+		if eventData.IntegrationID != "" {
+			// overwrite the ID of the integration if it has been set in the event
+			integrationID = eventData.IntegrationID
+		}
+		return l.log(models.LogEntry{
+			IntegrationID: integrationID,
+			Message:       eventData.Message,
+			KeptnContext:  keptnEvent.Shkeptncontext,
+			Task:          eventData.Task,
+			TriggeredID:   keptnEvent.Triggeredid,
+		})
+	}
+	return nil
+}
+
+func (l *UniformLogForwarder) log(entry models.LogEntry) error {
+	l.logAPI.Log([]models.LogEntry{entry})
+	if err := l.logAPI.Flush(); err != nil {
+		return fmt.Errorf("could not forward log: %w", err)
+	}
+	return nil
+}
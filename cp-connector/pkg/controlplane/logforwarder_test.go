@@ -0,0 +1,112 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type LogsInterfaceMock struct {
+	LogCalls [][]models.LogEntry
+	FlushFn  func() error
+}
+
+func (m *LogsInterfaceMock) Log(logs []models.LogEntry) {
+	m.LogCalls = append(m.LogCalls, logs)
+}
+
+func (m *LogsInterfaceMock) Flush() error {
+	if m.FlushFn != nil {
+		return m.FlushFn()
+	}
+	panic("implement me")
+}
+
+func (m *LogsInterfaceMock) GetLogs(params models.GetLogsParams) (*models.GetLogsResponse, error) {
+	panic("implement me")
+}
+
+func (m *LogsInterfaceMock) DeleteLogs(filter models.LogFilter) error {
+	panic("implement me")
+}
+
+func (m *LogsInterfaceMock) Start(ctx context.Context) {
+	panic("implement me")
+}
+
+func TestUniformLogForwarderErrorLogEvent(t *testing.T) {
+	logsInterface := &LogsInterfaceMock{FlushFn: func() error { return nil }}
+	logForwarder := NewUniformLogForwarder(logsInterface)
+
+	event := models.KeptnContextExtendedCE{
+		Type:           strutils.Stringp(v0_2_0.ErrorLogEventName),
+		Shkeptncontext: "my-context",
+		Triggeredid:    "my-triggered-id",
+		Data:           v0_2_0.ErrorLogEvent{Message: "my-message", Task: "my-task"},
+	}
+	require.NoError(t, logForwarder.Forward(event, "my-id"))
+	require.Equal(t, [][]models.LogEntry{{{
+		IntegrationID: "my-id",
+		Message:       "my-message",
+		KeptnContext:  "my-context",
+		Task:          "my-task",
+		TriggeredID:   "my-triggered-id",
+	}}}, logsInterface.LogCalls)
+
+	// the integration ID set in the event takes precedence
+	event.Data = v0_2_0.ErrorLogEvent{Message: "my-message", Task: "my-task", IntegrationID: "other-id"}
+	require.NoError(t, logForwarder.Forward(event, "my-id"))
+	require.Equal(t, "other-id", logsInterface.LogCalls[1][0].IntegrationID)
+}
+
+func TestUniformLogForwarderErroredFinishedEvent(t *testing.T) {
+	logsInterface := &LogsInterfaceMock{FlushFn: func() error { return nil }}
+	logForwarder := NewUniformLogForwarder(logsInterface)
+
+	event := models.KeptnContextExtendedCE{
+		Type:           strutils.Stringp("sh.keptn.event.deployment.finished"),
+		Shkeptncontext: "my-context",
+		Triggeredid:    "my-triggered-id",
+		Data:           v0_2_0.EventData{Status: v0_2_0.StatusSucceeded, Message: "my-message"},
+	}
+	require.NoError(t, logForwarder.Forward(event, "my-id"))
+	require.Empty(t, logsInterface.LogCalls)
+
+	event.Data = v0_2_0.EventData{Status: v0_2_0.StatusErrored, Message: "my-message"}
+	require.NoError(t, logForwarder.Forward(event, "my-id"))
+	require.Equal(t, [][]models.LogEntry{{{
+		IntegrationID: "my-id",
+		Message:       "my-message",
+		KeptnContext:  "my-context",
+		Task:          "deployment",
+		TriggeredID:   "my-triggered-id",
+	}}}, logsInterface.LogCalls)
+}
+
+func TestUniformLogForwarderIgnoresOtherEvents(t *testing.T) {
+	logsInterface := &LogsInterfaceMock{}
+	logForwarder := NewUniformLogForwarder(logsInterface)
+
+	require.NoError(t, logForwarder.Forward(models.KeptnContextExtendedCE{Type: strutils.Stringp("sh.keptn.event.deployment.started")}, "my-id"))
+	require.NoError(t, logForwarder.Forward(models.KeptnContextExtendedCE{}, "my-id"))
+	require.Empty(t, logsInterface.LogCalls)
+}
+
+func TestUniformLogForwarderInvalidPayload(t *testing.T) {
+	logForwarder := NewUniformLogForwarder(&LogsInterfaceMock{})
+
+	event := models.KeptnContextExtendedCE{Type: strutils.Stringp(v0_2_0.ErrorLogEventName), Data: "invalid"}
+	require.Error(t, logForwarder.Forward(event, "my-id"))
+}
+
+func TestUniformLogForwarderFlushFails(t *testing.T) {
+	logsInterface := &LogsInterfaceMock{FlushFn: func() error { return fmt.Errorf("error occured") }}
+	logForwarder := NewUniformLogForwarder(logsInterface)
+
+	event := models.KeptnContextExtendedCE{Type: strutils.Stringp(v0_2_0.ErrorLogEventName), Data: v0_2_0.ErrorLogEvent{Message: "my-message"}}
+	require.Error(t, logForwarder.Forward(event, "my-id"))
+}
@@ -10,10 +10,20 @@ import (
 	"time"
 )
 
+// SubscriptionSource is anything that can be used
+// to get the uniform subscriptions of an integration
 type SubscriptionSource interface {
+	// Start triggers the execution of the SubscriptionSource
 	Start(context.Context, RegistrationData, chan []models.EventSubscription) error
 }
 
+// IntegrationRegistrar can be implemented by a SubscriptionSource which registers
+// the integration to the Keptn Control Plane before it is started
+type IntegrationRegistrar interface {
+	// Register registers the integration to the Keptn Control Plane and returns its ID
+	Register(RegistrationData) (string, error)
+}
+
 // UniformSubscriptionSource represents a source for uniform subscriptions
 type UniformSubscriptionSource struct {
 	uniformAPI    api.UniformV1Interface
@@ -39,12 +49,23 @@ func NewUniformSubscriptionSource(uniformAPI api.UniformV1Interface, options ...
 	return subscriptionSource
 }
 
-// Start triggers the execution of the UniformSubscriptionSource
-func (s *UniformSubscriptionSource) Start(ctx context.Context, registrationData RegistrationData, subscriptionChannel chan []models.EventSubscription) error {
+// Register registers the integration to the Keptn Control Plane
+func (s *UniformSubscriptionSource) Register(registrationData RegistrationData) (string, error) {
 	integrationID, err := s.uniformAPI.RegisterIntegration(models.Integration(registrationData))
 	if err != nil {
-		return fmt.Errorf("could not start subscription source: %w", err)
+		return "", fmt.Errorf("could not register integration: %w", err)
+	}
+	return integrationID, nil
+}
+
+// Start triggers the execution of the UniformSubscriptionSource.
+// It periodically pings the control plane on behalf of the registered integration,
+// which serves as heartbeat, and passes the current subscriptions to the channel
+func (s *UniformSubscriptionSource) Start(ctx context.Context, registrationData RegistrationData, subscriptionChannel chan []models.EventSubscription) error {
+	if registrationData.ID == "" {
+		return fmt.Errorf("could not start subscription source: integration is not registered")
 	}
+	integrationID := registrationData.ID
 	ticker := s.clock.Ticker(s.fetchInterval)
 	go func() {
 		for {
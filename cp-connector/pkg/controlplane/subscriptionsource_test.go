@@ -10,17 +10,6 @@ import (
 	"time"
 )
 
-type SubscriptionSourceMock struct {
-	StartFn func(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error
-}
-
-func (u *SubscriptionSourceMock) Start(ctx context.Context, data RegistrationData, c chan []models.EventSubscription) error {
-	if u.StartFn != nil {
-		return u.StartFn(ctx, data, c)
-	}
-	panic("implement me")
-}
-
 type UniformInterfaceMock struct {
 	RegisterIntegrationFn func(models.Integration) (string, error)
 	PingFn                func(string) (*models.Integration, error)
@@ -58,12 +47,33 @@ func TestSubscriptionSourceInitialRegistrationFails(t *testing.T) {
 		RegisterIntegrationFn: func(integration models.Integration) (string, error) { return "", fmt.Errorf("error occured") },
 	}
 	subscriptionSource := NewUniformSubscriptionSource(uniformInterface)
-	err := subscriptionSource.Start(context.Background(), initialRegistrationData, nil)
+	_, err := subscriptionSource.Register(initialRegistrationData)
+	require.Error(t, err)
+}
+
+func TestSubscriptionSourceRegister(t *testing.T) {
+	initialRegistrationData := RegistrationData{Name: "integrationName"}
+
+	uniformInterface := &UniformInterfaceMock{
+		RegisterIntegrationFn: func(integration models.Integration) (string, error) {
+			require.Equal(t, models.Integration(initialRegistrationData), integration)
+			return "iID", nil
+		},
+	}
+	subscriptionSource := NewUniformSubscriptionSource(uniformInterface)
+	integrationID, err := subscriptionSource.Register(initialRegistrationData)
+	require.NoError(t, err)
+	require.Equal(t, "iID", integrationID)
+}
+
+func TestSubscriptionSourceStartWithoutRegistration(t *testing.T) {
+	subscriptionSource := NewUniformSubscriptionSource(&UniformInterfaceMock{})
+	err := subscriptionSource.Start(context.TODO(), RegistrationData{}, nil)
 	require.Error(t, err)
 }
 
 func TestSubscriptionSourceCPPingFails(t *testing.T) {
-	initialRegistrationData := RegistrationData{}
+	initialRegistrationData := RegistrationData{ID: "id"}
 
 	uniformInterface := &UniformInterfaceMock{
 		RegisterIntegrationFn: func(integration models.Integration) (string, error) { return "id", nil },
@@ -90,6 +100,7 @@ func TestSubscriptionSourceWithFetchInterval(t *testing.T) {
 	pingCount := 0
 
 	initialRegistrationData := RegistrationData{
+		ID:            integrationID,
 		Name:          integrationName,
 		MetaData:      models.MetaData{},
 		Subscriptions: []models.EventSubscription{{Event: "keptn.event", Filter: models.EventSubscriptionFilter{}}},
@@ -130,6 +141,7 @@ func TestSubscriptionSourceCancel(t *testing.T) {
 	pingCount := 0
 
 	initialRegistrationData := RegistrationData{
+		ID:            integrationID,
 		Name:          integrationName,
 		MetaData:      models.MetaData{},
 		Subscriptions: []models.EventSubscription{{Event: "keptn.event", Filter: models.EventSubscriptionFilter{}}},
@@ -172,6 +184,7 @@ func TestSubscriptionSource(t *testing.T) {
 	subscriptionID := "sID"
 
 	initialRegistrationData := RegistrationData{
+		ID:            integrationID,
 		Name:          integrationName,
 		MetaData:      models.MetaData{},
 		Subscriptions: []models.EventSubscription{{Event: "keptn.event", Filter: models.EventSubscriptionFilter{}}},
@@ -227,3 +240,9 @@ func TestFixedSubscriptionSourcer_WithNoSubscriptions(t *testing.T) {
 	updates := <-subchan
 	require.Equal(t, 0, len(updates))
 }
+
+func TestFixedSubscriptionSource_DoesNotRegister(t *testing.T) {
+	var subscriptionSource SubscriptionSource = NewFixedSubscriptionSource()
+	_, ok := subscriptionSource.(IntegrationRegistrar)
+	require.False(t, ok)
+}
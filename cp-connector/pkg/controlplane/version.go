@@ -0,0 +1,46 @@
+package controlplane
+
+import (
+	"runtime/debug"
+)
+
+const (
+	modulePath     = "github.com/keptn/keptn/cp-connector"
+	defaultVersion = "dev"
+)
+
+// Version returns the version of cp-connector the Keptn service has been built with
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return defaultVersion
+	}
+	if info.Main.Path == modulePath {
+		return moduleVersion(&info.Main)
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return moduleVersion(dep)
+		}
+	}
+	return defaultVersion
+}
+
+func moduleVersion(module *debug.Module) string {
+	if module.Replace != nil {
+		module = module.Replace
+	}
+	if module.Version == "" || module.Version == "(devel)" {
+		return defaultVersion
+	}
+	return module.Version
+}
+
+// withVersionInfo sets the version of cp-connector as distributor version of the registration data,
+// since cp-connector takes over the role of the distributor. A version set by the integration is kept
+func withVersionInfo(registrationData RegistrationData) RegistrationData {
+	if registrationData.MetaData.DistributorVersion == "" {
+		registrationData.MetaData.DistributorVersion = Version()
+	}
+	return registrationData
+}
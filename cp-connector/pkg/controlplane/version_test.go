@@ -0,0 +1,19 @@
+package controlplane
+
+import (
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	require.NotEmpty(t, Version())
+}
+
+func TestWithVersionInfo(t *testing.T) {
+	registrationData := withVersionInfo(RegistrationData{Name: "my-service"})
+	require.Equal(t, Version(), registrationData.MetaData.DistributorVersion)
+
+	registrationData = withVersionInfo(RegistrationData{Name: "my-service", MetaData: models.MetaData{DistributorVersion: "0.15.0"}})
+	require.Equal(t, "0.15.0", registrationData.MetaData.DistributorVersion)
+}
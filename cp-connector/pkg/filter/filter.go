@@ -0,0 +1,206 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ExclusionPrefix marks a project, stage or service of a filter that is excluded, e.g. !sockshop
+const ExclusionPrefix = "!"
+
+var (
+	labelSelectorRegex = regexp.MustCompile(`^(!)?(?:labels\.)?([A-Za-z0-9_./-]+)(?:(==|=|!=)(.*))?$`)
+	expressionRegex    = regexp.MustCompile(`^\s*(\$(?:\.[A-Za-z0-9_-]+)*)\s*(?:(==|!=)\s*(.+?))?\s*$`)
+)
+
+// LabelSelector selects events by one of their labels. The supported selectors are
+//
+//	team=payments   the label team has the value payments
+//	team!=payments  the label team does not have the value payments
+//	team            the label team is set
+//	!team           the label team is not set
+//
+// Selectors may be prefixed with 'labels.', e.g. labels.team=payments
+type LabelSelector struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+// ParseLabelSelector parses a label selector
+func ParseLabelSelector(selector string) (*LabelSelector, error) {
+	match := labelSelectorRegex.FindStringSubmatch(strings.TrimSpace(selector))
+	if match == nil {
+		return nil, fmt.Errorf("invalid label selector %s", selector)
+	}
+	ls := &LabelSelector{Key: match[2], Operator: match[3], Value: strings.TrimSpace(match[4])}
+	if ls.Operator == "==" {
+		ls.Operator = "="
+	}
+	if match[1] != "" {
+		if ls.Operator != "" {
+			return nil, fmt.Errorf("invalid label selector %s: a negated selector must not have a value", selector)
+		}
+		ls.Operator = ExclusionPrefix
+	}
+	return ls, nil
+}
+
+// Matches checks whether the labels match the selector
+func (ls LabelSelector) Matches(labels map[string]string) bool {
+	value, ok := labels[ls.Key]
+	switch ls.Operator {
+	case "=":
+		return ok && value == ls.Value
+	case "!=":
+		return !ok || value != ls.Value
+	case ExclusionPrefix:
+		return !ok
+	}
+	return ok
+}
+
+// Expression selects events by a field of their data. The supported expressions are
+//
+//	$.result == "pass"   the field has the given JSON value
+//	$.result != "pass"   the field does not have the given JSON value
+//	$.deployment.url     the field is set
+//
+// where $ refers to the data of the event
+type Expression struct {
+	Path     []string
+	Operator string
+	Value    interface{}
+}
+
+// ParseExpression parses an expression
+func ParseExpression(expression string) (*Expression, error) {
+	match := expressionRegex.FindStringSubmatch(expression)
+	if match == nil {
+		return nil, fmt.Errorf("invalid expression %s", expression)
+	}
+	e := &Expression{Operator: match[2]}
+	if path := strings.TrimPrefix(match[1], "$"); path != "" {
+		e.Path = strings.Split(strings.TrimPrefix(path, "."), ".")
+	}
+	if len(e.Path) == 0 {
+		return nil, fmt.Errorf("invalid expression %s: no field selected", expression)
+	}
+	if e.Operator != "" {
+		if err := json.Unmarshal([]byte(match[3]), &e.Value); err != nil {
+			return nil, fmt.Errorf("invalid expression %s: %s is not a JSON value", expression, match[3])
+		}
+	}
+	return e, nil
+}
+
+// Matches checks whether the data matches the expression
+func (e Expression) Matches(data interface{}) bool {
+	value, ok := lookup(data, e.Path)
+	switch e.Operator {
+	case "==":
+		return ok && reflect.DeepEqual(value, e.Value)
+	case "!=":
+		return !ok || !reflect.DeepEqual(value, e.Value)
+	}
+	return ok && value != nil
+}
+
+// lookup returns the value of the field in the decoded JSON data. Numeric segments select elements of arrays
+func lookup(data interface{}, path []string) (interface{}, bool) {
+	value := data
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			field, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// MatchesList checks whether the value matches the comma separated list of a filter. An empty list matches all values
+func MatchesList(list string, value string) bool {
+	if list == "" {
+		return true
+	}
+	return MatchesValues(strings.Split(list, ","), value)
+}
+
+// MatchesValues checks whether the value matches the values of a filter. Values with the ExclusionPrefix
+// are excluded, and a filter containing only exclusions matches all other values
+func MatchesValues(values []string, value string) bool {
+	included := IncludedValues(values)
+	for _, v := range values {
+		if strings.HasPrefix(v, ExclusionPrefix) && strings.TrimPrefix(v, ExclusionPrefix) == value {
+			return false
+		}
+	}
+	if len(included) == 0 {
+		return true
+	}
+	for _, v := range included {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludedValues returns the values of a filter which are not excluded
+func IncludedValues(values []string) []string {
+	included := []string{}
+	for _, value := range values {
+		if !strings.HasPrefix(value, ExclusionPrefix) {
+			included = append(included, value)
+		}
+	}
+	return included
+}
+
+// ParseLabelSelectors parses a comma separated list of label selectors
+func ParseLabelSelectors(selectors string) ([]LabelSelector, error) {
+	result := []LabelSelector{}
+	if strings.TrimSpace(selectors) == "" {
+		return result, nil
+	}
+	for _, selector := range strings.Split(selectors, ",") {
+		ls, err := ParseLabelSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *ls)
+	}
+	return result, nil
+}
+
+// ParseExpressions parses a list of expressions separated by semicolons
+func ParseExpressions(expressions string) ([]Expression, error) {
+	result := []Expression{}
+	for _, expression := range strings.Split(expressions, ";") {
+		if strings.TrimSpace(expression) == "" {
+			continue
+		}
+		e, err := ParseExpression(expression)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *e)
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     *LabelSelector
+		wantErr  bool
+	}{
+		{selector: "team=payments", want: &LabelSelector{Key: "team", Operator: "=", Value: "payments"}},
+		{selector: "labels.team==payments", want: &LabelSelector{Key: "team", Operator: "=", Value: "payments"}},
+		{selector: " team!=payments ", want: &LabelSelector{Key: "team", Operator: "!=", Value: "payments"}},
+		{selector: "team", want: &LabelSelector{Key: "team"}},
+		{selector: "!team", want: &LabelSelector{Key: "team", Operator: ExclusionPrefix}},
+		{selector: "!team=payments", wantErr: true},
+		{selector: "", wantErr: true},
+		{selector: "te am=payments", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := ParseLabelSelector(tt.selector)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := map[string]string{"team": "payments"}
+	selectors, err := ParseLabelSelectors("team=payments,!owner,team!=checkout")
+	require.Nil(t, err)
+	for _, selector := range selectors {
+		assert.True(t, selector.Matches(labels), selector)
+	}
+
+	selectors, err = ParseLabelSelectors("team=checkout,owner,!team,team!=payments")
+	require.Nil(t, err)
+	for _, selector := range selectors {
+		assert.False(t, selector.Matches(labels), selector)
+	}
+	assert.False(t, LabelSelector{Key: "team"}.Matches(nil))
+}
+
+func TestParseExpression(t *testing.T) {
+	e, err := ParseExpression(`$.evaluation.result == "pass"`)
+	require.Nil(t, err)
+	assert.Equal(t, &Expression{Path: []string{"evaluation", "result"}, Operator: "==", Value: "pass"}, e)
+
+	e, err = ParseExpression(`$.evaluation.score!=100`)
+	require.Nil(t, err)
+	assert.Equal(t, &Expression{Path: []string{"evaluation", "score"}, Operator: "!=", Value: float64(100)}, e)
+
+	e, err = ParseExpression(`$.deployment.deploymentURIsPublic`)
+	require.Nil(t, err)
+	assert.Equal(t, &Expression{Path: []string{"deployment", "deploymentURIsPublic"}}, e)
+
+	_, err = ParseExpression(`$`)
+	assert.Error(t, err)
+	_, err = ParseExpression(`$.result == pass`)
+	assert.Error(t, err)
+	_, err = ParseExpression(`result == "pass"`)
+	assert.Error(t, err)
+}
+
+func TestExpression_Matches(t *testing.T) {
+	var data interface{}
+	require.Nil(t, json.Unmarshal([]byte(`{
+		"evaluation": {"result": "pass", "score": 100},
+		"deployment": {"deploymentURIsPublic": ["http://carts.sockshop"]}
+	}`), &data))
+
+	expressions, err := ParseExpressions(`$.evaluation.result == "pass"; $.evaluation.score == 100; $.deployment.deploymentURIsPublic.0; $.evaluation.result != "fail"`)
+	require.Nil(t, err)
+	require.Len(t, expressions, 4)
+	for _, expression := range expressions {
+		assert.True(t, expression.Matches(data), expression)
+	}
+
+	expressions, err = ParseExpressions(`$.evaluation.result == "fail"; $.deployment.deploymentURIsPublic.1; $.evaluation.result.value; $.evaluation.score != 100`)
+	require.Nil(t, err)
+	for _, expression := range expressions {
+		assert.False(t, expression.Matches(data), expression)
+	}
+}
+
+func TestMatchesList(t *testing.T) {
+	assert.True(t, MatchesList("", "sockshop"))
+	assert.True(t, MatchesList("sockshop,podtato-head", "sockshop"))
+	assert.False(t, MatchesList("podtato-head", "sockshop"))
+	assert.False(t, MatchesList("!sockshop", "sockshop"))
+	assert.True(t, MatchesList("!podtato-head", "sockshop"))
+	assert.False(t, MatchesList("sockshop,!sockshop", "sockshop"))
+	assert.False(t, MatchesList("podtato-head,!carts", "sockshop"))
+}
+
+func TestMatchesValues(t *testing.T) {
+	assert.True(t, MatchesValues(nil, "sockshop"))
+	assert.True(t, MatchesValues([]string{"sockshop", "podtato-head"}, "sockshop"))
+	assert.True(t, MatchesValues([]string{"!podtato-head"}, "sockshop"))
+	assert.False(t, MatchesValues([]string{"podtato-head"}, "sockshop"))
+	assert.False(t, MatchesValues([]string{"!sockshop"}, "sockshop"))
+	assert.False(t, MatchesValues([]string{"sockshop", "!sockshop"}, "sockshop"))
+}
+
+func TestIncludedValues(t *testing.T) {
+	assert.Equal(t, []string{}, IncludedValues(nil))
+	assert.Equal(t, []string{"sockshop"}, IncludedValues([]string{"sockshop", "!podtato-head"}))
+}
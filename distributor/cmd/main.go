@@ -16,20 +16,27 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/keptn/keptn/distributor/pkg/api"
 	"github.com/keptn/keptn/distributor/pkg/clientget"
 	"github.com/keptn/keptn/distributor/pkg/config"
+	"github.com/keptn/keptn/distributor/pkg/delivery"
 	"github.com/keptn/keptn/distributor/pkg/forwarder"
+	"github.com/keptn/keptn/distributor/pkg/limiter"
 	"github.com/keptn/keptn/distributor/pkg/poller"
 	"github.com/keptn/keptn/distributor/pkg/receiver"
+	"github.com/keptn/keptn/distributor/pkg/stream"
 	"github.com/keptn/keptn/distributor/pkg/uniform/controlplane"
 	"github.com/keptn/keptn/distributor/pkg/uniform/log"
 	"github.com/keptn/keptn/distributor/pkg/uniform/watch"
 	"github.com/keptn/keptn/distributor/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	logger "github.com/sirupsen/logrus"
 	"net/http"
 	"net/url"
@@ -53,6 +60,13 @@ func main() {
 
 	bark(env)
 
+	if _, err := filter.ParseLabelSelectors(env.LabelFilter); err != nil {
+		logger.WithError(err).Fatal("Invalid label filter.")
+	}
+	if _, err := filter.ParseExpressions(env.ExpressionFilter); err != nil {
+		logger.WithError(err).Fatal("Invalid expression filter.")
+	}
+
 	executionContext := createExecutionContext()
 	eventSender, err := createEventSender(env)
 	if err != nil {
@@ -73,16 +87,39 @@ func main() {
 	uniformWatch := watch.New(controlPlane, env)
 	forwarder := forwarder.New(apiset.APIV1(), httpClient, env)
 
+	// Retry failed deliveries of events to the Keptn service
+	deliveryQueue, err := delivery.New(eventSender, createDeliveryQueueConfig(env))
+	if err != nil {
+		logger.WithError(err).Warn("Could not initialize delivery queue. Failed deliveries will not be retried.")
+	} else {
+		forwarder.Handle(delivery.APIPath, delivery.NewHandler(deliveryQueue))
+		executionContext.Wg.Add(1)
+		deliveryQueue.Start(executionContext)
+		eventSender = deliveryQueue
+	}
+
+	// Limit the number of events the Keptn service processes at the same time
+	eventLimiter := limiter.New(env.MaxInFlightEvents, env.MaxInFlightProjectEvents, env.InFlightEventTimeout, prometheus.DefaultRegisterer)
+	forwarder.RegisterListener(eventLimiter)
+
+	// Serve the metrics on all interfaces, as the API proxy only accepts connections from within the pod
+	if env.MetricsPort > 0 {
+		executionContext.Wg.Add(1)
+		startMetricsServer(executionContext, env.MetricsPort)
+	}
+
 	// Start event forwarder
 	logger.Info("Starting Event Forwarder")
 	forwarder.Start(executionContext)
 
 	// Eventually start registration process
+	integrationID := ""
 	if env.ValidateRegistrationConstraints() {
 		id, err := uniformWatch.Start(executionContext)
 		if err != nil {
 			logger.Fatal(err)
 		}
+		integrationID = id
 		uniformLogger := log.New(id, apiset.LogsV1())
 		uniformLogger.Start(executionContext, forwarder.EventChannel)
 	}
@@ -93,11 +130,22 @@ func main() {
 		if err != nil {
 			logger.Fatalf("No valid URL configured for keptn api endpoint: %s", err)
 		}
-		logger.Info("Starting HTTP event poller")
-		httpEventPoller := poller.New(env, apiset.ShipyardControlV1(), eventSender)
+		httpEventPoller := poller.New(env, apiset.ShipyardControlV1(), eventSender, eventLimiter)
 		uniformWatch.RegisterListener(httpEventPoller)
-		if err := httpEventPoller.Start(executionContext); err != nil {
-			logger.Fatalf("Could not start HTTP event poller: %v", err)
+		if env.EventStreamEnabled && integrationID == "" {
+			logger.Warn("The event stream requires the registration of the Keptn integration, falling back to the HTTP event poller")
+		}
+		if env.EventStreamEnabled && integrationID != "" {
+			logger.Info("Starting event stream receiver")
+			eventStreamReceiver := stream.New(env, integrationID, httpEventPoller, httpClient)
+			if err := eventStreamReceiver.Start(executionContext); err != nil {
+				logger.Fatalf("Could not start event stream receiver: %v", err)
+			}
+		} else {
+			logger.Info("Starting HTTP event poller")
+			if err := httpEventPoller.Start(executionContext); err != nil {
+				logger.Fatalf("Could not start HTTP event poller: %v", err)
+			}
 		}
 	} else {
 		logger.Info("Starting NATS event receiver")
@@ -139,8 +187,9 @@ func bark(env config.EnvConfig) {
 	fmt.Printf(printFmtStr, padR("Keptn API endpoint"), strOrUnknown(env.KeptnAPIEndpoint))
 	fmt.Printf(printFmtStr, padR("Api proxy path"), strOrUnknown(env.APIProxyPath))
 	fmt.Printf(printFmtStr, padR("Api proxy port"), strOrUnknown(strconv.Itoa(env.APIProxyPort)))
+	fmt.Printf(printFmtStr, padR("Metrics port"), strOrUnknown(strconv.Itoa(env.MetricsPort)))
 	fmt.Printf(printFmtStr, padR("PubSub URL"), strOrUnknown(env.PubSubURL))
-	fmt.Printf(printFmtStr, padR("PubSub group"), strOrUnknown(env.PubSubGroup))
+	fmt.Printf(printFmtStr, padR("PubSub group"), strOrUnknown(env.PubSubQueueGroup()))
 	fmt.Printf(printFmtStr, padR("K8S node name"), strOrUnknown(env.K8sNodeName))
 	fmt.Printf(printFmtStr, padR("K8S namespace"), strOrUnknown(env.K8sNamespace))
 	fmt.Printf(printFmtStr, padR("K8S deployment name"), strOrUnknown(env.K8sDeploymentName))
@@ -167,6 +216,32 @@ func createExecutionContext() *utils.ExecutionContext {
 	return &executionContext
 }
 
+// startMetricsServer serves the Prometheus metrics on the given port until the execution context is done
+func startMetricsServer(executionContext *utils.ExecutionContext, port int) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	svr := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	go func() {
+		defer executionContext.Wg.Done()
+		if err := svr.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("Unexpected HTTP server error in metrics server: %v", err)
+		}
+	}()
+	go func() {
+		<-executionContext.Done()
+		logger.Info("Terminating metrics server")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := svr.Shutdown(ctx); err != nil {
+			logger.Errorf("Could not gracefully shutdown metrics server: %v", err)
+		}
+	}()
+}
+
 func createKeptnAPI(httpClient *http.Client, env config.EnvConfig) (keptnapi.KeptnInterface, error) {
 	if httpClient == nil {
 		httpClient = &http.Client{}
@@ -183,6 +258,17 @@ func createKeptnAPI(httpClient *http.Client, env config.EnvConfig) (keptnapi.Kep
 	return api.NewInternal(httpClient)
 }
 
+func createDeliveryQueueConfig(env config.EnvConfig) delivery.Config {
+	return delivery.Config{
+		Directory:          env.DeliveryQueueDir,
+		MaxSize:            env.DeliveryQueueSize,
+		MaxRetries:         env.DeliveryMaxRetries,
+		InitialBackoff:     env.DeliveryInitialBackoff,
+		MaxBackoff:         env.DeliveryMaxBackoff,
+		DeadLetterEndpoint: env.DeadLetterEndpoint,
+	}
+}
+
 func createEventSender(env config.EnvConfig) (poller.EventSender, error) {
 	eventSender, err := keptnv2.NewHTTPEventSender(env.PubSubRecipientURL())
 	if err != nil {
@@ -2,7 +2,9 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	logger "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
@@ -14,40 +16,55 @@ import (
 )
 
 type EnvConfig struct {
-	KeptnAPIEndpoint       string        `envconfig:"KEPTN_API_ENDPOINT" default:""`
-	KeptnAPIToken          string        `envconfig:"KEPTN_API_TOKEN" default:""`
-	APIProxyPort           int           `envconfig:"API_PROXY_PORT" default:"8081"`
-	APIProxyPath           string        `envconfig:"API_PROXY_PATH" default:"/"`
-	APIProxyHTTPTimeout    string        `envconfig:"API_PROXY_HTTP_TIMEOUT" default:"30"`
-	HTTPPollingInterval    string        `envconfig:"HTTP_POLLING_INTERVAL" default:"10"`
-	EventForwardingPath    string        `envconfig:"EVENT_FORWARDING_PATH" default:"/event"`
-	VerifySSL              bool          `envconfig:"HTTP_SSL_VERIFY" default:"true"`
-	PubSubURL              string        `envconfig:"PUBSUB_URL" default:"nats://keptn-nats"`
-	PubSubTopic            string        `envconfig:"PUBSUB_TOPIC" default:""`
-	PubSubRecipient        string        `envconfig:"PUBSUB_RECIPIENT" default:"http://127.0.0.1"`
-	PubSubRecipientPort    string        `envconfig:"PUBSUB_RECIPIENT_PORT" default:"8080"`
-	PubSubRecipientPath    string        `envconfig:"PUBSUB_RECIPIENT_PATH" default:""`
-	PubSubGroup            string        `envconfig:"PUBSUB_GROUP" default:""`
-	ProjectFilter          string        `envconfig:"PROJECT_FILTER" default:""`
-	StageFilter            string        `envconfig:"STAGE_FILTER" default:""`
-	ServiceFilter          string        `envconfig:"SERVICE_FILTER" default:""`
-	DisableRegistration    bool          `envconfig:"DISABLE_REGISTRATION" default:"false"`
-	RegistrationInterval   string        `envconfig:"REGISTRATION_INTERVAL" default:"10s"`
-	Location               string        `envconfig:"LOCATION" default:""`
-	DistributorVersion     string        `envconfig:"DISTRIBUTOR_VERSION" default:"0.9.0"` // TODO: set this automatically
-	Version                string        `envconfig:"VERSION" default:""`
-	K8sDeploymentName      string        `envconfig:"K8S_DEPLOYMENT_NAME" default:""`
-	K8sNamespace           string        `envconfig:"K8S_NAMESPACE" default:""`
-	K8sPodName             string        `envconfig:"K8S_POD_NAME" default:""`
-	K8sNodeName            string        `envconfig:"K8S_NODE_NAME" default:""`
-	MaxHeartBeatRetries    int           `envconfig:"MAX_HEARTBEAT_RETRIES" default:"10"`
-	HeartbeatInterval      time.Duration `envconfig:"HEARTBEAT_INTERVAL" default:"10s"`
-	MaxRegistrationRetries int           `envconfig:"MAX_REGISTRATION_RETRIES" default:"10"`
-	OAuthClientID          string        `envconfig:"OAUTH_CLIENT_ID" default:""`
-	OAuthClientSecret      string        `envconfig:"OAUTH_CLIENT_SECRET" default:""`
-	OAuthScopes            []string      `envconfig:"OAUTH_SCOPES" default:""`
-	OAuthDiscovery         string        `envconfig:"OAUTH_DISCOVERY" default:""`
-	OauthTokenURL          string        `envconfig:"OAUTH_TOKEN_URL" default:""`
+	KeptnAPIEndpoint         string        `envconfig:"KEPTN_API_ENDPOINT" default:""`
+	KeptnAPIToken            string        `envconfig:"KEPTN_API_TOKEN" default:""`
+	APIProxyPort             int           `envconfig:"API_PROXY_PORT" default:"8081"`
+	APIProxyPath             string        `envconfig:"API_PROXY_PATH" default:"/"`
+	APIProxyHTTPTimeout      string        `envconfig:"API_PROXY_HTTP_TIMEOUT" default:"30"`
+	HTTPPollingInterval      string        `envconfig:"HTTP_POLLING_INTERVAL" default:"10"`
+	EventStreamEnabled       bool          `envconfig:"EVENT_STREAM_ENABLED" default:"false"`
+	EventStreamResync        time.Duration `envconfig:"EVENT_STREAM_RESYNC_INTERVAL" default:"1m"`
+	EventForwardingPath      string        `envconfig:"EVENT_FORWARDING_PATH" default:"/event"`
+	VerifySSL                bool          `envconfig:"HTTP_SSL_VERIFY" default:"true"`
+	PubSubURL                string        `envconfig:"PUBSUB_URL" default:"nats://keptn-nats"`
+	PubSubTopic              string        `envconfig:"PUBSUB_TOPIC" default:""`
+	PubSubRecipient          string        `envconfig:"PUBSUB_RECIPIENT" default:"http://127.0.0.1"`
+	PubSubRecipientPort      string        `envconfig:"PUBSUB_RECIPIENT_PORT" default:"8080"`
+	PubSubRecipientPath      string        `envconfig:"PUBSUB_RECIPIENT_PATH" default:""`
+	PubSubGroup              string        `envconfig:"PUBSUB_GROUP" default:""`
+	ProjectFilter            string        `envconfig:"PROJECT_FILTER" default:""`
+	StageFilter              string        `envconfig:"STAGE_FILTER" default:""`
+	ServiceFilter            string        `envconfig:"SERVICE_FILTER" default:""`
+	LabelFilter              string        `envconfig:"LABEL_FILTER" default:""`
+	ExpressionFilter         string        `envconfig:"EXPRESSION_FILTER" default:""`
+	DisableRegistration      bool          `envconfig:"DISABLE_REGISTRATION" default:"false"`
+	RegistrationInterval     string        `envconfig:"REGISTRATION_INTERVAL" default:"10s"`
+	Location                 string        `envconfig:"LOCATION" default:""`
+	DistributorVersion       string        `envconfig:"DISTRIBUTOR_VERSION" default:"0.9.0"` // TODO: set this automatically
+	Version                  string        `envconfig:"VERSION" default:""`
+	K8sDeploymentName        string        `envconfig:"K8S_DEPLOYMENT_NAME" default:""`
+	K8sNamespace             string        `envconfig:"K8S_NAMESPACE" default:""`
+	K8sPodName               string        `envconfig:"K8S_POD_NAME" default:""`
+	K8sNodeName              string        `envconfig:"K8S_NODE_NAME" default:""`
+	MaxHeartBeatRetries      int           `envconfig:"MAX_HEARTBEAT_RETRIES" default:"10"`
+	HeartbeatInterval        time.Duration `envconfig:"HEARTBEAT_INTERVAL" default:"10s"`
+	MaxRegistrationRetries   int           `envconfig:"MAX_REGISTRATION_RETRIES" default:"10"`
+	OAuthClientID            string        `envconfig:"OAUTH_CLIENT_ID" default:""`
+	OAuthClientSecret        string        `envconfig:"OAUTH_CLIENT_SECRET" default:""`
+	OAuthScopes              []string      `envconfig:"OAUTH_SCOPES" default:""`
+	OAuthDiscovery           string        `envconfig:"OAUTH_DISCOVERY" default:""`
+	OauthTokenURL            string        `envconfig:"OAUTH_TOKEN_URL" default:""`
+	DeliveryQueueDir         string        `envconfig:"DELIVERY_QUEUE_DIR" default:"/tmp/keptn-distributor"`
+	DeliveryQueueSize        int           `envconfig:"DELIVERY_QUEUE_SIZE" default:"1000"`
+	DeliveryMaxRetries       int           `envconfig:"DELIVERY_MAX_RETRIES" default:"5"`
+	DeliveryInitialBackoff   time.Duration `envconfig:"DELIVERY_INITIAL_BACKOFF" default:"5s"`
+	DeliveryMaxBackoff       time.Duration `envconfig:"DELIVERY_MAX_BACKOFF" default:"5m"`
+	DeadLetterEndpoint       string        `envconfig:"DEAD_LETTER_ENDPOINT" default:""`
+	MaxInFlightEvents        int           `envconfig:"MAX_IN_FLIGHT_EVENTS" default:"0"`
+	MaxInFlightProjectEvents int           `envconfig:"MAX_IN_FLIGHT_PROJECT_EVENTS" default:"0"`
+	InFlightEventTimeout     time.Duration `envconfig:"IN_FLIGHT_EVENT_TIMEOUT" default:"15m"`
+	MetricsPort              int           `envconfig:"METRICS_PORT" default:"9090"`
+	TrustUserLabel           bool          `envconfig:"TRUST_USER_LABEL" default:"false"`
 }
 
 func (env *EnvConfig) PubSubConnectionType() ConnectionType {
@@ -151,6 +168,20 @@ func (env *EnvConfig) HTTPPollingEndpoint() string {
 	return parsedURL.String()
 }
 
+// EventStreamEndpoint returns the WebSocket URL of the event stream of the integration with the given ID
+func (env *EnvConfig) EventStreamEndpoint(integrationID string) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimSuffix(env.KeptnAPIEndpoint, "/") + "/controlPlane/v1/uniform/registration/" + integrationID + "/stream")
+	if err != nil {
+		return "", err
+	}
+	if parsedURL.Scheme == "https" {
+		parsedURL.Scheme = "wss"
+	} else {
+		parsedURL.Scheme = "ws"
+	}
+	return parsedURL.String(), nil
+}
+
 func (env *EnvConfig) PubSubRecipientURL() string {
 	recipientService := env.PubSubRecipient
 
@@ -172,6 +203,17 @@ func (env *EnvConfig) PubSubTopics() []string {
 	return strings.Split(env.PubSubTopic, ",")
 }
 
+// PubSubQueueGroup returns the queue group to join on the message broker. An event is delivered to only one member
+// of a group, so a member filtering it out by its labels or data drops it for the whole group. Therefore, the label
+// and expression filters are part of the group, and only distributors with the same filters share their events
+func (env *EnvConfig) PubSubQueueGroup() string {
+	if env.PubSubGroup == "" || env.LabelFilter == "" && env.ExpressionFilter == "" {
+		return env.PubSubGroup
+	}
+	hash := sha256.Sum256([]byte(env.LabelFilter + "\n" + env.ExpressionFilter))
+	return env.PubSubGroup + "-" + hex.EncodeToString(hash[:])[:8]
+}
+
 func (env *EnvConfig) HTTPClient() *http.Client {
 	c := &http.Client{
 		Transport: &http.Transport{
@@ -236,6 +236,18 @@ func Test_ValidateKeptnAPIEndpointURL(t *testing.T) {
 	assert.Nil(t, config.ValidateKeptnAPIEndpointURL())
 }
 
+func Test_EventStreamEndpoint(t *testing.T) {
+	config := EnvConfig{KeptnAPIEndpoint: "https://keptn.example.com/api/"}
+	endpoint, err := config.EventStreamEndpoint("my-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "wss://keptn.example.com/api/controlPlane/v1/uniform/registration/my-id/stream", endpoint)
+
+	config = EnvConfig{KeptnAPIEndpoint: "http://1.2.3.4.nip.io/api"}
+	endpoint, err = config.EventStreamEndpoint("my-id")
+	assert.Nil(t, err)
+	assert.Equal(t, "ws://1.2.3.4.nip.io/api/controlPlane/v1/uniform/registration/my-id/stream", endpoint)
+}
+
 func Test_GetPubSubTopics(t *testing.T) {
 	// multiple topics
 	config := EnvConfig{PubSubTopic: "a,b,c"}
@@ -246,6 +258,19 @@ func Test_GetPubSubTopics(t *testing.T) {
 	assert.Equal(t, 0, len(config.PubSubTopics()))
 }
 
+func Test_PubSubQueueGroup(t *testing.T) {
+	assert.Equal(t, "", (&EnvConfig{LabelFilter: "team=payments"}).PubSubQueueGroup())
+	assert.Equal(t, "my-group", (&EnvConfig{PubSubGroup: "my-group"}).PubSubQueueGroup())
+
+	payments := &EnvConfig{PubSubGroup: "my-group", LabelFilter: "team=payments"}
+	checkout := &EnvConfig{PubSubGroup: "my-group", LabelFilter: "team=checkout"}
+	expression := &EnvConfig{PubSubGroup: "my-group", ExpressionFilter: `$.result == "pass"`}
+	assert.Regexp(t, "^my-group-[0-9a-f]{8}$", payments.PubSubQueueGroup())
+	assert.Equal(t, payments.PubSubQueueGroup(), (&EnvConfig{PubSubGroup: "my-group", LabelFilter: "team=payments"}).PubSubQueueGroup())
+	assert.NotEqual(t, payments.PubSubQueueGroup(), checkout.PubSubQueueGroup())
+	assert.NotEqual(t, payments.PubSubQueueGroup(), expression.PubSubQueueGroup())
+}
+
 func Test_OAuthEnabled(t *testing.T) {
 	tests := []struct {
 		input EnvConfig
@@ -0,0 +1,70 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// APIPath is the path of the internal API of the delivery queue:
+//
+//	GET  /delivery/queue                    lists the events waiting for a retry
+//	GET  /delivery/deadletter               lists the dead-lettered events
+//	POST /delivery/deadletter/{id}/replay   moves a dead-lettered event back to the queue
+const APIPath = "/delivery/"
+
+type handler struct {
+	queue *Queue
+}
+
+// NewHandler creates the handler of the internal API of the delivery queue
+func NewHandler(queue *Queue) http.Handler {
+	return &handler{queue: queue}
+}
+
+func (h *handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	path := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, APIPath), "/"), "/")
+	switch {
+	case req.Method == http.MethodGet && len(path) == 1 && path[0] == queueDirectory:
+		h.writeEntries(rw, h.queue.Pending)
+	case req.Method == http.MethodGet && len(path) == 1 && path[0] == deadLetterDirectory:
+		h.writeEntries(rw, h.queue.DeadLetters)
+	case req.Method == http.MethodPost && len(path) == 3 && path[0] == deadLetterDirectory && path[2] == "replay":
+		h.replay(rw, path[1])
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (h *handler) writeEntries(rw http.ResponseWriter, getEntries func() ([]*Entry, error)) {
+	entries, err := getEntries()
+	if err != nil {
+		logger.Errorf("Could not read delivery queue: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(entries); err != nil {
+		logger.Errorf("Could not send entries of delivery queue: %v", err)
+	}
+}
+
+func (h *handler) replay(rw http.ResponseWriter, id string) {
+	if err := h.queue.Replay(id); err != nil {
+		if errors.Is(err, ErrEntryNotFound) {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrQueueFull) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		logger.Errorf("Could not replay dead-lettered event %s: %v", id, err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
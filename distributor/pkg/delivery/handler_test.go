@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	sender := &fakeSender{err: errors.New("connection refused")}
+	config := testConfig
+	config.MaxRetries = 0
+	config.MaxSize = 2
+	q, mockClock := newTestQueue(t, sender, config)
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-2")))
+	mockClock.Add(time.Second)
+	q.retryDueEntries()
+
+	server := httptest.NewServer(NewHandler(q))
+	defer server.Close()
+
+	entries := getEntries(t, server.URL+"/delivery/deadletter")
+	require.Len(t, entries, 2)
+	assert.Empty(t, getEntries(t, server.URL+"/delivery/queue"))
+
+	resp, err := http.Post(server.URL+"/delivery/deadletter/"+entries[0].ID+"/replay", "application/json", nil)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Len(t, getEntries(t, server.URL+"/delivery/queue"), 1)
+	assert.Len(t, getEntries(t, server.URL+"/delivery/deadletter"), 1)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-3")))
+	resp, err = http.Post(server.URL+"/delivery/deadletter/"+entries[1].ID+"/replay", "application/json", nil)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, getEntries(t, server.URL+"/delivery/deadletter"), 1)
+
+	resp, err = http.Post(server.URL+"/delivery/deadletter/unknown/replay", "application/json", nil)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/delivery/unknown")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func getEntries(t *testing.T, url string) []*Entry {
+	resp, err := http.Get(url)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entries := []*Entry{}
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&entries))
+	return entries
+}
@@ -0,0 +1,361 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/keptn/distributor/pkg/model"
+	"github.com/keptn/keptn/distributor/pkg/utils"
+	logger "github.com/sirupsen/logrus"
+)
+
+// ErrQueueFull is returned if an event can not be delivered and the delivery queue has reached its maximum size
+var ErrQueueFull = errors.New("delivery queue is full")
+
+// ErrEntryNotFound is returned if no dead-lettered event with the given ID exists
+var ErrEntryNotFound = errors.New("entry not found")
+
+const (
+	queueDirectory      = "queue"
+	deadLetterDirectory = "deadletter"
+	retryInterval       = time.Second
+	sendTimeout         = 5 * time.Second
+)
+
+var invalidFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+type EventSender interface {
+	Send(ctx context.Context, event cloudevents.Event) error
+}
+
+// Config contains the configuration of the delivery queue
+type Config struct {
+	// Directory is the directory the queued and dead-lettered events are stored in
+	Directory string
+	// MaxSize is the maximum number of events waiting for a retry
+	MaxSize int
+	// MaxRetries is the number of retries before an event is dead-lettered
+	MaxRetries int
+	// InitialBackoff is the time between the first failed delivery and the first retry. It doubles with every retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time between two retries
+	MaxBackoff time.Duration
+	// DeadLetterEndpoint is an optional URL each dead-lettered event is posted to
+	DeadLetterEndpoint string
+}
+
+// Entry is an event the distributor could not deliver to the Keptn service
+type Entry struct {
+	ID             string            `json:"id"`
+	SubscriptionID string            `json:"subscriptionID,omitempty"`
+	Event          cloudevents.Event `json:"event"`
+	Attempts       int               `json:"attempts"`
+	LastError      string            `json:"lastError,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	NextAttempt    time.Time         `json:"nextAttempt,omitempty"`
+}
+
+// Queue sends events to the Keptn service and retries failed deliveries with an exponential backoff. The events
+// are stored on disk, such that they survive a restart of the distributor. Events which exhaust their retries are
+// moved to the dead-letter store, from where they can be replayed
+type Queue struct {
+	sender     EventSender
+	config     Config
+	httpClient *http.Client
+	clock      clock.Clock
+	mutex      sync.Mutex
+}
+
+// New creates a new Queue storing its events in the configured directory
+func New(sender EventSender, config Config) (*Queue, error) {
+	for _, dir := range []string{queueDirectory, deadLetterDirectory} {
+		if err := os.MkdirAll(filepath.Join(config.Directory, dir), 0700); err != nil {
+			return nil, fmt.Errorf("could not create directory of delivery queue: %w", err)
+		}
+	}
+	return &Queue{
+		sender:     sender,
+		config:     config,
+		httpClient: &http.Client{Timeout: sendTimeout},
+		clock:      clock.New(),
+	}, nil
+}
+
+// Send sends the event to the Keptn service. If this fails, the event is queued for a retry, and an error is only
+// returned if the event could not be queued
+func (q *Queue) Send(ctx context.Context, event cloudevents.Event) error {
+	err := q.sender.Send(ctx, event)
+	if err == nil {
+		return nil
+	}
+
+	now := q.clock.Now().UTC()
+	subscriptionID := getSubscriptionID(event)
+	entry := &Entry{
+		ID:             getEntryID(subscriptionID, event.ID()),
+		SubscriptionID: subscriptionID,
+		Event:          event,
+		Attempts:       1,
+		LastError:      err.Error(),
+		CreatedAt:      now,
+		NextAttempt:    now.Add(q.backoff(1)),
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	entries, listErr := q.list(queueDirectory)
+	if listErr != nil {
+		return fmt.Errorf("could not queue event %s: %w", event.ID(), listErr)
+	}
+	if len(entries) >= q.config.MaxSize {
+		return fmt.Errorf("could not queue event %s after failed delivery (%s): %w", event.ID(), err.Error(), ErrQueueFull)
+	}
+	if err := q.save(queueDirectory, entry); err != nil {
+		return fmt.Errorf("could not queue event %s: %w", event.ID(), err)
+	}
+	logger.Warnf("Delivery of CloudEvent with ID %s failed, retrying in %s: %v", event.ID(), q.backoff(1), err)
+	return nil
+}
+
+// Start retries the delivery of the queued events until the execution context is done
+func (q *Queue) Start(ctx *utils.ExecutionContext) {
+	go func() {
+		defer ctx.Wg.Done()
+		ticker := q.clock.Ticker(retryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				q.retryDueEntries()
+			case <-ctx.Done():
+				logger.Info("Terminating delivery queue")
+				return
+			}
+		}
+	}()
+}
+
+// Pending returns the events waiting for a retry
+func (q *Queue) Pending() ([]*Entry, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.list(queueDirectory)
+}
+
+// DeadLetters returns the events which exhausted their retries
+func (q *Queue) DeadLetters() ([]*Entry, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.list(deadLetterDirectory)
+}
+
+// Replay moves a dead-lettered event back to the queue, such that its delivery is retried immediately. The event
+// remains in the dead-letter store if the queue has reached its maximum size
+func (q *Queue) Replay(id string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entry, err := q.load(deadLetterDirectory, id)
+	if err != nil {
+		return err
+	}
+	entries, err := q.list(queueDirectory)
+	if err != nil {
+		return err
+	}
+	if len(entries) >= q.config.MaxSize {
+		return fmt.Errorf("could not replay event %s: %w", entry.Event.ID(), ErrQueueFull)
+	}
+	entry.Attempts = 0
+	entry.NextAttempt = q.clock.Now().UTC()
+	if err := q.save(queueDirectory, entry); err != nil {
+		return err
+	}
+	return q.remove(deadLetterDirectory, id)
+}
+
+func (q *Queue) retryDueEntries() {
+	q.mutex.Lock()
+	entries, err := q.list(queueDirectory)
+	q.mutex.Unlock()
+	if err != nil {
+		logger.Errorf("Could not read delivery queue: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.NextAttempt.After(q.clock.Now()) {
+			continue
+		}
+		q.retry(entry)
+	}
+}
+
+func (q *Queue) retry(entry *Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	err := q.sender.Send(ctx, entry.Event)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if err == nil {
+		logger.Infof("Delivered CloudEvent with ID %s after %d failed attempts", entry.Event.ID(), entry.Attempts)
+		if err := q.remove(queueDirectory, entry.ID); err != nil {
+			logger.Errorf("Could not remove CloudEvent with ID %s from delivery queue: %v", entry.Event.ID(), err)
+		}
+		return
+	}
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	if entry.Attempts > q.config.MaxRetries {
+		q.deadLetter(entry)
+		return
+	}
+	entry.NextAttempt = q.clock.Now().UTC().Add(q.backoff(entry.Attempts))
+	if err := q.save(queueDirectory, entry); err != nil {
+		logger.Errorf("Could not update CloudEvent with ID %s in delivery queue: %v", entry.Event.ID(), err)
+	}
+}
+
+func (q *Queue) deadLetter(entry *Entry) {
+	logger.Errorf("Delivery of CloudEvent with ID %s failed %d times, moving it to the dead-letter store: %s", entry.Event.ID(), entry.Attempts, entry.LastError)
+	entry.NextAttempt = time.Time{}
+	if err := q.save(deadLetterDirectory, entry); err != nil {
+		logger.Errorf("Could not store dead-lettered CloudEvent with ID %s: %v", entry.Event.ID(), err)
+		return
+	}
+	if err := q.remove(queueDirectory, entry.ID); err != nil {
+		logger.Errorf("Could not remove CloudEvent with ID %s from delivery queue: %v", entry.Event.ID(), err)
+	}
+	if q.config.DeadLetterEndpoint != "" {
+		if err := q.postDeadLetter(entry); err != nil {
+			logger.Errorf("Could not send dead-lettered CloudEvent with ID %s to %s: %v", entry.Event.ID(), q.config.DeadLetterEndpoint, err)
+		}
+	}
+}
+
+func (q *Queue) postDeadLetter(entry *Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	resp, err := q.httpClient.Post(q.config.DeadLetterEndpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("received unexpected response: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the time to wait after the given number of failed attempts
+func (q *Queue) backoff(attempts int) time.Duration {
+	backoff := q.config.InitialBackoff
+	for i := 1; i < attempts && backoff < q.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > q.config.MaxBackoff {
+		return q.config.MaxBackoff
+	}
+	return backoff
+}
+
+func (q *Queue) list(dir string) ([]*Entry, error) {
+	files, err := ioutil.ReadDir(filepath.Join(q.config.Directory, dir))
+	if err != nil {
+		return nil, err
+	}
+	entries := []*Entry{}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		entry, err := q.load(dir, file.Name()[:len(file.Name())-len(".json")])
+		if err != nil {
+			logger.Warnf("Skipping invalid entry %s of delivery queue: %v", file.Name(), err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (q *Queue) load(dir string, id string) (*Entry, error) {
+	content, err := ioutil.ReadFile(q.fileName(dir, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, err
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(content, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (q *Queue) save(dir string, entry *Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, such that a crash does not leave a partially written entry behind
+	tmpFileName := q.fileName(dir, entry.ID) + ".tmp"
+	if err := ioutil.WriteFile(tmpFileName, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFileName, q.fileName(dir, entry.ID))
+}
+
+func (q *Queue) remove(dir string, id string) error {
+	if err := os.Remove(q.fileName(dir, id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (q *Queue) fileName(dir string, id string) string {
+	return filepath.Join(q.config.Directory, dir, id+".json")
+}
+
+// getEntryID returns the ID of the entry of an event, which is unique per subscription
+func getEntryID(subscriptionID string, eventID string) string {
+	id := eventID
+	if subscriptionID != "" {
+		id = subscriptionID + "_" + eventID
+	}
+	return invalidFileNameChars.ReplaceAllString(id, "-")
+}
+
+// getSubscriptionID returns the ID of the subscription the distributor added to the temporary data of the event
+func getSubscriptionID(event cloudevents.Event) string {
+	data := struct {
+		TemporaryData struct {
+			Distributor model.AdditionalSubscriptionData `json:"distributor"`
+		} `json:"temporaryData"`
+	}{}
+	if err := event.DataAs(&data); err != nil {
+		return ""
+	}
+	return data.TemporaryData.Distributor.SubscriptionID
+}
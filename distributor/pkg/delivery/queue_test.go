@@ -0,0 +1,203 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	mutex  sync.Mutex
+	err    error
+	events []cloudevents.Event
+}
+
+func (s *fakeSender) Send(ctx context.Context, event cloudevents.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func (s *fakeSender) setError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+func (s *fakeSender) sent() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.events)
+}
+
+func newTestEvent(id string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType("sh.keptn.event.task.triggered")
+	event.SetSource("shipyard-controller")
+	_ = event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"project":       "sockshop",
+		"temporaryData": map[string]interface{}{"distributor": map[string]interface{}{"subscriptionID": "my-sub"}},
+	})
+	return event
+}
+
+func newTestQueue(t *testing.T, sender EventSender, config Config) (*Queue, *clock.Mock) {
+	config.Directory = t.TempDir()
+	q, err := New(sender, config)
+	require.Nil(t, err)
+	mockClock := clock.NewMock()
+	q.clock = mockClock
+	return q, mockClock
+}
+
+var testConfig = Config{MaxSize: 10, MaxRetries: 2, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+
+func TestQueue_SendDelivered(t *testing.T) {
+	sender := &fakeSender{}
+	q, _ := newTestQueue(t, sender, testConfig)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	pending, err := q.Pending()
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestQueue_RetryWithBackoff(t *testing.T) {
+	sender := &fakeSender{err: errors.New("connection refused")}
+	q, mockClock := newTestQueue(t, sender, testConfig)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	pending, err := q.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "my-sub_id-1", pending[0].ID)
+	assert.Equal(t, "my-sub", pending[0].SubscriptionID)
+	assert.Equal(t, 1, pending[0].Attempts)
+
+	// the retry is not due yet
+	q.retryDueEntries()
+	assert.Equal(t, 1, sender.sent())
+
+	mockClock.Add(time.Second)
+	q.retryDueEntries()
+	assert.Equal(t, 2, sender.sent())
+	pending, _ = q.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, 2, pending[0].Attempts)
+	// the backoff doubles after every failed attempt
+	assert.Equal(t, mockClock.Now().UTC().Add(2*time.Second), pending[0].NextAttempt.UTC())
+
+	sender.setError(nil)
+	mockClock.Add(2 * time.Second)
+	q.retryDueEntries()
+	assert.Equal(t, 3, sender.sent())
+	pending, _ = q.Pending()
+	assert.Empty(t, pending)
+}
+
+func TestQueue_DeadLetterAndReplay(t *testing.T) {
+	var postedEntry *Entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		postedEntry = &Entry{}
+		_ = json.NewDecoder(r.Body).Decode(postedEntry)
+	}))
+	defer server.Close()
+
+	sender := &fakeSender{err: errors.New("connection refused")}
+	config := testConfig
+	config.DeadLetterEndpoint = server.URL
+	q, mockClock := newTestQueue(t, sender, config)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	for i := 0; i < config.MaxRetries; i++ {
+		mockClock.Add(time.Minute)
+		q.retryDueEntries()
+	}
+
+	pending, _ := q.Pending()
+	assert.Empty(t, pending)
+	deadLetters, err := q.DeadLetters()
+	require.Nil(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, "connection refused", deadLetters[0].LastError)
+	require.NotNil(t, postedEntry)
+	assert.Equal(t, "id-1", postedEntry.Event.ID())
+
+	assert.ErrorIs(t, q.Replay("unknown"), ErrEntryNotFound)
+	require.Nil(t, q.Replay(deadLetters[0].ID))
+	deadLetters, _ = q.DeadLetters()
+	assert.Empty(t, deadLetters)
+
+	sender.setError(nil)
+	q.retryDueEntries()
+	pending, _ = q.Pending()
+	assert.Empty(t, pending)
+	assert.Equal(t, 4, sender.sent())
+}
+
+func TestQueue_Full(t *testing.T) {
+	sender := &fakeSender{err: errors.New("connection refused")}
+	config := testConfig
+	config.MaxSize = 1
+	q, _ := newTestQueue(t, sender, config)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	assert.ErrorIs(t, q.Send(context.TODO(), newTestEvent("id-2")), ErrQueueFull)
+}
+
+func TestQueue_ReplayFull(t *testing.T) {
+	sender := &fakeSender{err: errors.New("connection refused")}
+	config := testConfig
+	config.MaxSize = 1
+	config.MaxRetries = 0
+	q, mockClock := newTestQueue(t, sender, config)
+
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+	mockClock.Add(time.Second)
+	q.retryDueEntries()
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-2")))
+
+	deadLetters, err := q.DeadLetters()
+	require.Nil(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.ErrorIs(t, q.Replay(deadLetters[0].ID), ErrQueueFull)
+	deadLetters, _ = q.DeadLetters()
+	assert.Len(t, deadLetters, 1)
+	pending, _ := q.Pending()
+	assert.Len(t, pending, 1)
+}
+
+func TestQueue_SurvivesRestart(t *testing.T) {
+	sender := &fakeSender{err: errors.New("connection refused")}
+	q, _ := newTestQueue(t, sender, testConfig)
+	require.Nil(t, q.Send(context.TODO(), newTestEvent("id-1")))
+
+	restarted, err := New(sender, q.config)
+	require.Nil(t, err)
+	pending, err := restarted.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "id-1", pending[0].Event.ID())
+}
+
+func TestQueue_backoff(t *testing.T) {
+	q := &Queue{config: Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 4*time.Second, q.backoff(3))
+	assert.Equal(t, 5*time.Second, q.backoff(4))
+	assert.Equal(t, 5*time.Second, q.backoff(100))
+}
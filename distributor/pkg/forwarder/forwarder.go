@@ -19,6 +19,10 @@ import (
 	"time"
 )
 
+// UserLabel is the label holding the user who triggered an event.
+// It is set by the Keptn API and removed from events sent by any other service
+const UserLabel = "triggeredBy"
+
 // Forwarder receives events directly from the Keptn Service and forwards them to the Keptn API
 type Forwarder struct {
 	EventChannel      chan cloudevents.Event
@@ -26,6 +30,14 @@ type Forwarder struct {
 	httpClient        *http.Client
 	pubSubConnections map[string]*cenats.Sender
 	env               config.EnvConfig
+	handlers          map[string]http.Handler
+	listeners         []EventListener
+}
+
+// EventListener is the interface used to describe a component that wants to be notified about the events sent by
+// the Keptn service
+type EventListener interface {
+	OnEvent(event cloudevents.Event)
 }
 
 func New(keptnEventAPI api.APIV1Interface, client *http.Client, env config.EnvConfig) *Forwarder {
@@ -35,14 +47,28 @@ func New(keptnEventAPI api.APIV1Interface, client *http.Client, env config.EnvCo
 		httpClient:        client,
 		pubSubConnections: map[string]*cenats.Sender{},
 		env:               env,
+		handlers:          map[string]http.Handler{},
 	}
 }
 
+// RegisterListener adds a listener which is notified about every event forwarded to Keptn
+func (f *Forwarder) RegisterListener(listener EventListener) {
+	f.listeners = append(f.listeners, listener)
+}
+
+// Handle registers an additional handler for the given pattern. It has to be called before Start
+func (f *Forwarder) Handle(pattern string, handler http.Handler) {
+	f.handlers[pattern] = handler
+}
+
 func (f *Forwarder) Start(executionContext *utils.ExecutionContext) {
 	mux := http.NewServeMux()
 	mux.Handle("/health", http.HandlerFunc(api.HealthEndpointHandler))
 	mux.Handle(f.env.EventForwardingPath, http.HandlerFunc(f.handleEvent))
 	mux.Handle(f.env.APIProxyPath, http.HandlerFunc(f.apiProxyHandler))
+	for pattern, handler := range f.handlers {
+		mux.Handle(pattern, handler)
+	}
 	serverURL := fmt.Sprintf("localhost:%d", f.env.APIProxyPort)
 
 	svr := &http.Server{
@@ -87,6 +113,14 @@ func (f *Forwarder) handleEvent(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if !f.env.TrustUserLabel {
+		if err := removeUserLabel(event); err != nil {
+			logger.Errorf("Failed to remove %s label from CloudEvent: %v", UserLabel, err)
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
 	err = f.forwardEvent(*event)
 	if err != nil {
 		logger.Errorf("Failed to forward CloudEvent: %v", err)
@@ -95,8 +129,34 @@ func (f *Forwarder) handleEvent(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// removeUserLabel removes the UserLabel from the labels of the given event.
+// Only the Keptn API sets this label, based on the authenticated user, so the services must not be able to fake it
+func removeUserLabel(event *cloudevents.Event) error {
+	if len(event.Data()) == 0 {
+		return nil
+	}
+	data := map[string]interface{}{}
+	if err := event.DataAs(&data); err != nil {
+		// data that is not a JSON object cannot contain labels
+		return nil
+	}
+	labels, ok := data["labels"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if _, ok := labels[UserLabel]; !ok {
+		return nil
+	}
+	logger.Warnf("Removing %s label from CloudEvent with ID %s", UserLabel, event.ID())
+	delete(labels, UserLabel)
+	return event.SetData(cloudevents.ApplicationJSON, data)
+}
+
 func (f *Forwarder) forwardEvent(event cloudevents.Event) error {
 	logger.Infof("Received CloudEvent with ID %s - Forwarding to Keptn", event.ID())
+	for _, listener := range f.listeners {
+		listener.OnEvent(event)
+	}
 	select {
 	case f.EventChannel <- event:
 		// no-op
@@ -239,6 +239,31 @@ func Test_APIProxy(t *testing.T) {
 	executionContext.Wg.Wait()
 }
 
+func Test_AdditionalHandler(t *testing.T) {
+	handlerCalled := 0
+	cfg := config.EnvConfig{}
+	envconfig.Process("", &cfg)
+
+	f := New(nil, &http.Client{}, cfg)
+	f.Handle("/additional/", http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		handlerCalled++
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	executionContext := utils.NewExecutionContext(ctx, 1)
+	go f.Start(executionContext)
+
+	//TODO: remove wait
+	time.Sleep(2 * time.Second)
+	http.Get(fmt.Sprintf("http://127.0.0.1:%d/additional/path", 8081))
+
+	assert.Eventually(t, func() bool {
+		return handlerCalled == 1
+	}, time.Second*time.Duration(10), time.Second)
+
+	cancel()
+	executionContext.Wg.Wait()
+}
+
 func apiCallFromService() {
 	http.Get(fmt.Sprintf("http://127.0.0.1:%d/testpath", 8081))
 
@@ -264,3 +289,60 @@ func runNATSServer() (*server.Server, func()) {
 	svr := natsserver.RunRandClientPortServer()
 	return svr, func() { svr.Shutdown() }
 }
+
+func Test_HandleEventRemovesUserLabel(t *testing.T) {
+	const event = `{
+				"data": {"project": "my-project", "labels": {"triggeredBy": "admin", "foo": "bar"}},
+				"id": "7de83495-4f83-481c-8dbe-fcceb2e0243b",
+				"source": "my-service",
+				"specversion": "1.0",
+				"type": "sh.keptn.events.task.finished",
+				"shkeptncontext": "c9ffbbb-6e1d-4789-9fee-6e63b4bcc1fb"
+			}`
+	tests := []struct {
+		name           string
+		trustUserLabel bool
+		expectedLabels map[string]interface{}
+	}{
+		{
+			name:           "remove user label",
+			trustUserLabel: false,
+			expectedLabels: map[string]interface{}{"foo": "bar"},
+		},
+		{
+			name:           "keep trusted user label",
+			trustUserLabel: true,
+			expectedLabels: map[string]interface{}{"triggeredBy": "admin", "foo": "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {}))
+			defer ts.Close()
+
+			cfg := config.EnvConfig{}
+			envconfig.Process("", &cfg)
+			cfg.KeptnAPIEndpoint = ts.URL
+			cfg.TrustUserLabel = tt.trustUserLabel
+			apiset, _ := keptnapi.New(ts.URL)
+
+			f := &Forwarder{
+				EventChannel:      make(chan cloudevents.Event, 1),
+				keptnEventAPI:     apiset.APIV1(),
+				httpClient:        &http.Client{},
+				pubSubConnections: map[string]*cenats.Sender{},
+				env:               cfg,
+			}
+
+			rec := httptest.NewRecorder()
+			f.handleEvent(rec, httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(event)))
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			forwarded := <-f.EventChannel
+			data := map[string]interface{}{}
+			assert.Nil(t, forwarded.DataAs(&data))
+			assert.Equal(t, "my-project", data["project"])
+			assert.Equal(t, tt.expectedLabels, data["labels"])
+		})
+	}
+}
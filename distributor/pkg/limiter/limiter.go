@@ -0,0 +1,191 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	logger "github.com/sirupsen/logrus"
+)
+
+type inFlightEvent struct {
+	project string
+	sentAt  time.Time
+}
+
+// inFlightKey identifies an event sent for a subscription. The same event can be sent for several subscriptions,
+// each of which occupies the capacity of its subscription
+type inFlightKey struct {
+	subscriptionID string
+	eventID        string
+}
+
+// Limiter limits the number of events a Keptn service processes at the same time, per subscription and optionally
+// per project. An event is in flight from the time it is sent to the Keptn service until the service sends the
+// corresponding .finished event, the event is not open in the control plane anymore, or the timeout elapsed
+type Limiter struct {
+	maxPerSubscription    int
+	maxPerProject         int
+	timeout               time.Duration
+	clock                 clock.Clock
+	mutex                 sync.Mutex
+	inFlight              map[inFlightKey]*inFlightEvent
+	inFlightEvents        *prometheus.GaugeVec
+	waitingEvents         *prometheus.GaugeVec
+	inFlightProjectEvents *prometheus.GaugeVec
+}
+
+// New creates a new Limiter, whose metrics are registered with the given registerer. A maximum of 0 means that the
+// number of events is not limited
+func New(maxPerSubscription int, maxPerProject int, timeout time.Duration, registerer prometheus.Registerer) *Limiter {
+	factory := promauto.With(registerer)
+	return &Limiter{
+		maxPerSubscription: maxPerSubscription,
+		maxPerProject:      maxPerProject,
+		timeout:            timeout,
+		clock:              clock.New(),
+		inFlight:           map[inFlightKey]*inFlightEvent{},
+		inFlightEvents: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "keptn_distributor_events_in_flight",
+			Help: "Number of events sent to the Keptn service which have not been finished yet",
+		}, []string{"subscription"}),
+		waitingEvents: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "keptn_distributor_events_waiting",
+			Help: "Number of events which remain in the control plane until the Keptn service has capacity for them",
+		}, []string{"subscription"}),
+		inFlightProjectEvents: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "keptn_distributor_project_events_in_flight",
+			Help: "Number of events of a project sent to the Keptn service which have not been finished yet",
+		}, []string{"project"}),
+	}
+}
+
+// Acquire reserves capacity for the event. It returns false if the maximum number of events in flight of the
+// subscription or of the project is reached
+func (l *Limiter) Acquire(subscriptionID string, project string, eventID string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.releaseExpired()
+
+	key := inFlightKey{subscriptionID: subscriptionID, eventID: eventID}
+	if _, ok := l.inFlight[key]; ok {
+		return true
+	}
+	subscriptionCount, projectCount := 0, 0
+	for other, event := range l.inFlight {
+		if other.subscriptionID == subscriptionID {
+			subscriptionCount++
+		}
+		if event.project == project {
+			projectCount++
+		}
+	}
+	if l.maxPerSubscription > 0 && subscriptionCount >= l.maxPerSubscription ||
+		l.maxPerProject > 0 && project != "" && projectCount >= l.maxPerProject {
+		return false
+	}
+
+	l.inFlight[key] = &inFlightEvent{project: project, sentAt: l.clock.Now()}
+	l.inFlightEvents.WithLabelValues(subscriptionID).Inc()
+	if project != "" {
+		l.inFlightProjectEvents.WithLabelValues(project).Inc()
+	}
+	return true
+}
+
+// Release frees the capacity reserved for the event sent for the subscription
+func (l *Limiter) Release(subscriptionID string, eventID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.release(inFlightKey{subscriptionID: subscriptionID, eventID: eventID})
+}
+
+// ReleaseEvent frees the capacity reserved for the event for all subscriptions it has been sent for
+func (l *Limiter) ReleaseEvent(eventID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for key := range l.inFlight {
+		if key.eventID == eventID {
+			l.release(key)
+		}
+	}
+}
+
+// Keep frees the capacity reserved for all events of the subscription except the given ones, which are still open
+// in the control plane
+func (l *Limiter) Keep(subscriptionID string, eventIDs []string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	open := map[string]bool{}
+	for _, id := range eventIDs {
+		open[id] = true
+	}
+	for key := range l.inFlight {
+		if key.subscriptionID == subscriptionID && !open[key.eventID] {
+			l.release(key)
+		}
+	}
+}
+
+// SetWaiting sets the number of events of the subscription waiting for capacity
+func (l *Limiter) SetWaiting(subscriptionID string, count int) {
+	l.waitingEvents.WithLabelValues(subscriptionID).Set(float64(count))
+}
+
+// InFlight returns the number of events in flight of the subscription
+func (l *Limiter) InFlight(subscriptionID string) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	count := 0
+	for key := range l.inFlight {
+		if key.subscriptionID == subscriptionID {
+			count++
+		}
+	}
+	return count
+}
+
+// OnEvent frees the capacity reserved for a .triggered event when the Keptn service sends the corresponding
+// .finished event. As the .finished event does not tell which subscription it answers, the capacity is freed for
+// all subscriptions the .triggered event has been sent for
+func (l *Limiter) OnEvent(event cloudevents.Event) {
+	if !keptnv2.IsFinishedEventType(event.Type()) {
+		return
+	}
+	triggeredID, err := event.Context.GetExtension("triggeredid")
+	if err != nil {
+		return
+	}
+	if id, ok := triggeredID.(string); ok {
+		l.ReleaseEvent(id)
+	}
+}
+
+func (l *Limiter) release(key inFlightKey) {
+	event, ok := l.inFlight[key]
+	if !ok {
+		return
+	}
+	delete(l.inFlight, key)
+	l.inFlightEvents.WithLabelValues(key.subscriptionID).Dec()
+	if event.project != "" {
+		l.inFlightProjectEvents.WithLabelValues(event.project).Dec()
+	}
+}
+
+func (l *Limiter) releaseExpired() {
+	if l.timeout <= 0 {
+		return
+	}
+	for key, event := range l.inFlight {
+		if l.clock.Since(event.sentAt) > l.timeout {
+			logger.Warnf("Event with ID %s of subscription %s has not been finished within %s, releasing its capacity", key.eventID, key.subscriptionID, l.timeout)
+			l.release(key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_PerSubscription(t *testing.T) {
+	l := New(2, 0, 0, prometheus.NewRegistry())
+	assert.True(t, l.Acquire("sub-1", "sockshop", "id-1"))
+	assert.True(t, l.Acquire("sub-1", "sockshop", "id-2"))
+	// an event which is already in flight does not need additional capacity
+	assert.True(t, l.Acquire("sub-1", "sockshop", "id-2"))
+	assert.False(t, l.Acquire("sub-1", "sockshop", "id-3"))
+	assert.True(t, l.Acquire("sub-2", "sockshop", "id-3"))
+	assert.Equal(t, 2, l.InFlight("sub-1"))
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.inFlightEvents.WithLabelValues("sub-1")))
+
+	l.Release("sub-1", "id-1")
+	assert.Equal(t, 1, l.InFlight("sub-1"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(l.inFlightEvents.WithLabelValues("sub-1")))
+	assert.True(t, l.Acquire("sub-1", "sockshop", "id-4"))
+}
+
+func TestLimiter_EventOfSeveralSubscriptions(t *testing.T) {
+	l := New(1, 0, 0, prometheus.NewRegistry())
+	// an event sent for several subscriptions occupies the capacity of each of them
+	assert.True(t, l.Acquire("sub-1", "sockshop", "id-1"))
+	assert.True(t, l.Acquire("sub-2", "sockshop", "id-1"))
+	assert.False(t, l.Acquire("sub-2", "sockshop", "id-2"))
+
+	l.Release("sub-1", "id-1")
+	assert.Equal(t, 0, l.InFlight("sub-1"))
+	assert.Equal(t, 1, l.InFlight("sub-2"))
+
+	l.Acquire("sub-1", "sockshop", "id-1")
+	l.ReleaseEvent("id-1")
+	assert.Equal(t, 0, l.InFlight("sub-1"))
+	assert.Equal(t, 0, l.InFlight("sub-2"))
+	assert.Equal(t, float64(0), testutil.ToFloat64(l.inFlightEvents.WithLabelValues("sub-2")))
+}
+
+func TestLimiter_PerProject(t *testing.T) {
+	l := New(0, 1, 0, prometheus.NewRegistry())
+	assert.True(t, l.Acquire("sub-3", "sockshop", "id-1"))
+	assert.False(t, l.Acquire("sub-4", "sockshop", "id-2"))
+	assert.True(t, l.Acquire("sub-4", "podtato-head", "id-3"))
+	// events without a project are only limited per subscription
+	assert.True(t, l.Acquire("sub-4", "", "id-4"))
+}
+
+func TestLimiter_Keep(t *testing.T) {
+	l := New(0, 0, 0, prometheus.NewRegistry())
+	l.Acquire("sub-5", "sockshop", "id-1")
+	l.Acquire("sub-5", "sockshop", "id-2")
+	l.Acquire("sub-6", "sockshop", "id-3")
+
+	l.Keep("sub-5", []string{"id-2"})
+	assert.Equal(t, 1, l.InFlight("sub-5"))
+	assert.Equal(t, 1, l.InFlight("sub-6"))
+}
+
+func TestLimiter_Timeout(t *testing.T) {
+	l := New(1, 0, time.Minute, prometheus.NewRegistry())
+	mockClock := clock.NewMock()
+	l.clock = mockClock
+
+	assert.True(t, l.Acquire("sub-7", "sockshop", "id-1"))
+	assert.False(t, l.Acquire("sub-7", "sockshop", "id-2"))
+	mockClock.Add(2 * time.Minute)
+	assert.True(t, l.Acquire("sub-7", "sockshop", "id-2"))
+	assert.Equal(t, 1, l.InFlight("sub-7"))
+}
+
+func TestLimiter_OnEvent(t *testing.T) {
+	l := New(0, 0, 0, prometheus.NewRegistry())
+	l.Acquire("sub-8", "sockshop", "id-1")
+
+	started := cloudevents.NewEvent()
+	started.SetType("sh.keptn.event.deployment.started")
+	started.SetExtension("triggeredid", "id-1")
+	l.OnEvent(started)
+	assert.Equal(t, 1, l.InFlight("sub-8"))
+
+	finished := cloudevents.NewEvent()
+	finished.SetType("sh.keptn.event.deployment.finished")
+	finished.SetExtension("triggeredid", "id-1")
+	l.OnEvent(finished)
+	assert.Equal(t, 0, l.InFlight("sub-8"))
+}
+
+func TestLimiter_SetWaiting(t *testing.T) {
+	l := New(0, 0, 0, prometheus.NewRegistry())
+	l.SetWaiting("sub-9", 3)
+	assert.Equal(t, float64(3), testutil.ToFloat64(l.waitingEvents.WithLabelValues("sub-9")))
+}
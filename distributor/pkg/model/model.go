@@ -1,8 +1,17 @@
 package model
 
+import apimodels "github.com/keptn/go-utils/pkg/api/models"
+
 // AdditionalSubscriptionData is the data the distributor
 // will add as temporary data to the keptn events forwarded
 // to the keptn integration
 type AdditionalSubscriptionData struct {
 	SubscriptionID string `json:"subscriptionID"`
 }
+
+// StreamedEvent is a .triggered event the control plane
+// pushes to the distributor via the event stream
+type StreamedEvent struct {
+	SubscriptionID string                           `json:"subscriptionID"`
+	Event          apimodels.KeptnContextExtendedCE `json:"event"`
+}
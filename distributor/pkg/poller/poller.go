@@ -8,7 +8,9 @@ import (
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
 	api "github.com/keptn/go-utils/pkg/api/utils"
 	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/keptn/keptn/distributor/pkg/config"
+	"github.com/keptn/keptn/distributor/pkg/limiter"
 	"github.com/keptn/keptn/distributor/pkg/model"
 	"github.com/keptn/keptn/distributor/pkg/utils"
 	logger "github.com/sirupsen/logrus"
@@ -28,16 +30,18 @@ type Poller struct {
 	ceCache              *utils.Cache
 	env                  config.EnvConfig
 	eventMatcher         *utils.EventMatcher
+	limiter              *limiter.Limiter
 	currentSubscriptions []apimodels.EventSubscription
 }
 
-func New(envConfig config.EnvConfig, shipyardControlAPI api.ShipyardControlV1Interface, eventSender EventSender) *Poller {
+func New(envConfig config.EnvConfig, shipyardControlAPI api.ShipyardControlV1Interface, eventSender EventSender, limiter *limiter.Limiter) *Poller {
 	return &Poller{
 		shipyardControlAPI: shipyardControlAPI,
 		eventSender:        eventSender,
 		ceCache:            utils.NewCache(),
 		env:                envConfig,
 		eventMatcher:       utils.NewEventMatcherFromEnv(envConfig),
+		limiter:            limiter,
 	}
 }
 
@@ -55,7 +59,7 @@ func (p *Poller) Start(ctx *utils.ExecutionContext) error {
 	for {
 		select {
 		case <-time.After(time.Duration(pollingInterval) * time.Second):
-			p.doPollEvents()
+			p.PollEvents()
 		case <-ctx.Done():
 			logger.Info("Terminating HTTP event poller")
 			ctx.Wg.Done()
@@ -68,12 +72,26 @@ func (p *Poller) UpdateSubscriptions(subscriptions []apimodels.EventSubscription
 	p.currentSubscriptions = subscriptions
 }
 
-func (p *Poller) doPollEvents() {
+// PollEvents fetches the open .triggered events of all subscriptions and sends them to the Keptn service
+func (p *Poller) PollEvents() {
 	for _, sub := range p.currentSubscriptions {
 		p.pollEventsForSubscription(sub)
 	}
 }
 
+// HandleEvent sends an event which has been received for the subscription with the given ID to the Keptn service
+func (p *Poller) HandleEvent(subscriptionID string, event apimodels.KeptnContextExtendedCE) {
+	for _, subscription := range p.currentSubscriptions {
+		if subscription.ID == subscriptionID {
+			if !p.dispatchEvent(subscription, event) {
+				logger.Debugf("Maximum number of events in flight reached, CloudEvent with ID %s remains in the control plane", event.ID)
+			}
+			return
+		}
+	}
+	logger.Debugf("Ignoring CloudEvent with ID %s for unknown subscription %s", event.ID, subscriptionID)
+}
+
 func (p *Poller) pollEventsForSubscription(subscription apimodels.EventSubscription) {
 
 	eventFilter := getEventFilterForSubscription(subscription)
@@ -84,40 +102,66 @@ func (p *Poller) pollEventsForSubscription(subscription apimodels.EventSubscript
 	}
 
 	logger.Debugf("Received %d new .triggered events", len(events))
+	// events which have been finished are not open anymore, hence they do not occupy the capacity of the service
+	p.limiter.Keep(subscription.ID, utils.ToIds(events))
+	waiting := 0
 	// iterate over all events, discard the event if it has already been sent
 	for index := range events {
-		event := *events[index]
-		if p.ceCache.Contains(subscription.ID, event.ID) {
-			// Skip this event as it has already been sent
-			logger.Infof("CloudEvent with ID %s has already been sent for subscription %s", event.ID, subscription.ID)
-			continue
-		}
-
-		logger.Infof("Adding temporary data to event: <subscriptionID=%s>", subscription.ID)
-		// add subscription ID as additional information to the keptn event
-		if err := event.AddTemporaryData("distributor", model.AdditionalSubscriptionData{SubscriptionID: subscription.ID}, apimodels.AddTemporaryDataOptions{OverwriteIfExisting: true}); err != nil {
-			logger.Errorf("Could not add temporary information about subscriptions to event: %v", err)
+		if !p.dispatchEvent(subscription, *events[index]) {
+			logger.Debugf("Maximum number of events in flight reached, CloudEvent with ID %s remains in the control plane", events[index].ID)
+			waiting++
 		}
-
-		// add to CloudEvents cache
-		p.ceCache.Add(subscription.ID, event.ID)
-		go func() {
-			logger.Infof("Sending CloudEvent with ID %s to %s", event.ID, p.env.PubSubRecipient)
-			if err := p.sendEvent(event, subscription); err != nil {
-				logger.Errorf("Sending CloudEvent with ID %s to %s failed: %s", event.ID, p.env.PubSubRecipient, err.Error())
-				// Sending failed, remove from CloudEvents cache
-				p.ceCache.Remove(subscription.ID, event.ID)
-			}
-		}()
 	}
+	p.limiter.SetWaiting(subscription.ID, waiting)
 
 	logger.Debugf("Cleaning up list of sent events for topic %s", subscription.Event)
 	p.ceCache.Keep(subscription.ID, utils.ToIds(events))
 }
 
+// dispatchEvent sends the event to the Keptn service unless it has already been sent for the subscription.
+// It returns false if the event remains in the control plane because the Keptn service has no capacity for it
+func (p *Poller) dispatchEvent(subscription apimodels.EventSubscription, event apimodels.KeptnContextExtendedCE) bool {
+	if p.ceCache.Contains(subscription.ID, event.ID) {
+		// Skip this event as it has already been sent
+		logger.Infof("CloudEvent with ID %s has already been sent for subscription %s", event.ID, subscription.ID)
+		return true
+	}
+
+	// events not matching the filter of the subscription are not sent, hence they do not need capacity
+	matcher := utils.NewEventMatcherFromSubscription(subscription, p.env)
+	if !matcher.Matches(v0_2_0.ToCloudEvent(event)) {
+		return true
+	}
+
+	// leave the event in the control plane until the service has capacity for it
+	if !p.limiter.Acquire(subscription.ID, getProject(event), event.ID) {
+		return false
+	}
+
+	logger.Infof("Adding temporary data to event: <subscriptionID=%s>", subscription.ID)
+	// add subscription ID as additional information to the keptn event
+	if err := event.AddTemporaryData("distributor", model.AdditionalSubscriptionData{SubscriptionID: subscription.ID}, apimodels.AddTemporaryDataOptions{OverwriteIfExisting: true}); err != nil {
+		logger.Errorf("Could not add temporary information about subscriptions to event: %v", err)
+	}
+
+	// add to CloudEvents cache
+	p.ceCache.Add(subscription.ID, event.ID)
+	go func() {
+		logger.Infof("Sending CloudEvent with ID %s to %s", event.ID, p.env.PubSubRecipient)
+		if err := p.sendEvent(event); err != nil {
+			logger.Errorf("Sending CloudEvent with ID %s to %s failed: %s", event.ID, p.env.PubSubRecipient, err.Error())
+			// Sending failed, remove from CloudEvents cache
+			p.ceCache.Remove(subscription.ID, event.ID)
+			p.limiter.Release(subscription.ID, event.ID)
+		}
+	}()
+	return true
+}
+
 // getEventFilterForSubscription returns the event filter for the subscription
 // Per default, it only sets the event type of the subscription.
 // If exactly one project, stage or service is specified respectively, they are included in the filter.
+// Excluded projects, stages and services are only evaluated by the distributor.
 // However, this is only a (very) short term solution for the RBAC use case.
 // In the long term, we should just pass the subscription ID in the request, since the backend knows the required filters associated with the subscription.
 func getEventFilterForSubscription(subscription apimodels.EventSubscription) api.EventFilter {
@@ -125,26 +169,21 @@ func getEventFilterForSubscription(subscription apimodels.EventSubscription) api
 		EventType: subscription.Event,
 	}
 
-	if len(subscription.Filter.Projects) == 1 {
-		eventFilter.Project = subscription.Filter.Projects[0]
+	if included := filter.IncludedValues(subscription.Filter.Projects); len(included) == 1 {
+		eventFilter.Project = included[0]
 	}
-	if len(subscription.Filter.Stages) == 1 {
-		eventFilter.Stage = subscription.Filter.Stages[0]
+	if included := filter.IncludedValues(subscription.Filter.Stages); len(included) == 1 {
+		eventFilter.Stage = included[0]
 	}
-	if len(subscription.Filter.Services) == 1 {
-		eventFilter.Service = subscription.Filter.Services[0]
+	if included := filter.IncludedValues(subscription.Filter.Services); len(included) == 1 {
+		eventFilter.Service = included[0]
 	}
 
 	return eventFilter
 }
 
-func (p *Poller) sendEvent(e apimodels.KeptnContextExtendedCE, subscription apimodels.EventSubscription) error {
+func (p *Poller) sendEvent(e apimodels.KeptnContextExtendedCE) error {
 	event := v0_2_0.ToCloudEvent(e)
-	matcher := utils.NewEventMatcherFromSubscription(subscription)
-	if !matcher.Matches(event) {
-		return nil
-	}
-
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
 
@@ -154,3 +193,12 @@ func (p *Poller) sendEvent(e apimodels.KeptnContextExtendedCE, subscription apim
 
 	return nil
 }
+
+// getProject returns the project of the event
+func getProject(event apimodels.KeptnContextExtendedCE) string {
+	eventData := &v0_2_0.EventData{}
+	if err := event.DataAs(eventData); err != nil {
+		return ""
+	}
+	return eventData.Project
+}
@@ -4,18 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	"github.com/keptn/go-utils/pkg/common/strutils"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	keptnfake "github.com/keptn/go-utils/pkg/lib/v0_2_0/fake"
 	"github.com/keptn/keptn/distributor/pkg/config"
+	"github.com/keptn/keptn/distributor/pkg/limiter"
 	"github.com/keptn/keptn/distributor/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -87,7 +91,7 @@ func Test_PollAndForwardEvents1(t *testing.T) {
 	}
 	eventSender := keptnfake.EventSender{}
 	apiset, _ := keptnapi.New(server.URL)
-	poller := New(envConfig, apiset.ShipyardControlV1(), &eventSender)
+	poller := New(envConfig, apiset.ShipyardControlV1(), &eventSender, limiter.New(0, 0, 0, prometheus.NewRegistry()))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	executionContext := utils.NewExecutionContext(ctx, 1)
@@ -177,7 +181,7 @@ func Test_PollAndForwardEvents2(t *testing.T) {
 	eventSender := keptnfake.EventSender{}
 
 	apiset, _ := keptnapi.New(server.URL)
-	poller := New(envConfig, apiset.ShipyardControlV1(), &eventSender)
+	poller := New(envConfig, apiset.ShipyardControlV1(), &eventSender, limiter.New(0, 0, 0, prometheus.NewRegistry()))
 
 	ctx, cancel := context.WithCancel(context.Background())
 	executionContext := utils.NewExecutionContext(ctx, 1)
@@ -289,6 +293,22 @@ func Test_getEventFilterForSubscription(t *testing.T) {
 				Service:   "service-a",
 			},
 		},
+		{
+			name: "excluded projects and services are not part of the filter",
+			args: args{
+				subscription: apimodels.EventSubscription{
+					Event: "my-event",
+					Filter: apimodels.EventSubscriptionFilter{
+						Projects: []string{"a", "!b"},
+						Services: []string{"!service-a"},
+					},
+				},
+			},
+			want: keptnapi.EventFilter{
+				EventType: "my-event",
+				Project:   "a",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -298,3 +318,114 @@ func Test_getEventFilterForSubscription(t *testing.T) {
 		})
 	}
 }
+
+func Test_PollEventsWithConcurrencyLimit(t *testing.T) {
+	var mutex sync.Mutex
+	openEvents := []string{"id-1", "id-2", "id-3"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		mutex.Lock()
+		defer mutex.Unlock()
+		events := apimodels.Events{}
+		for _, id := range openEvents {
+			events.Events = append(events.Events, &apimodels.KeptnContextExtendedCE{
+				ID:          id,
+				Type:        strutils.Stringp("sh.keptn.event.task.triggered"),
+				Source:      strutils.Stringp("source"),
+				Specversion: "1.0",
+				Data:        map[string]interface{}{"project": "sockshop"},
+			})
+		}
+		marshal, _ := json.Marshal(events)
+		w.Write(marshal)
+	}))
+	defer server.Close()
+
+	envConfig := config.EnvConfig{
+		KeptnAPIEndpoint: server.URL,
+		PubSubRecipient:  "http://127.0.0.1",
+	}
+	eventSender := &syncEventSender{}
+	apiset, _ := keptnapi.New(server.URL)
+	eventLimiter := limiter.New(2, 0, 0, prometheus.NewRegistry())
+	poller := New(envConfig, apiset.ShipyardControlV1(), eventSender, eventLimiter)
+	subscription := apimodels.EventSubscription{ID: "my-sub", Event: "sh.keptn.event.task.triggered"}
+
+	poller.pollEventsForSubscription(subscription)
+	assert.Eventually(t, func() bool {
+		return len(eventSender.sentEvents()) == 2
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, 2, eventLimiter.InFlight("my-sub"))
+
+	// the third event remains in the control plane until the service has capacity for it
+	poller.pollEventsForSubscription(subscription)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, eventSender.sentEvents(), 2)
+
+	// the first event has been finished, hence it is not open anymore
+	mutex.Lock()
+	openEvents = []string{"id-2", "id-3"}
+	mutex.Unlock()
+	poller.pollEventsForSubscription(subscription)
+	assert.Eventually(t, func() bool {
+		return len(eventSender.sentEvents()) == 3
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, "id-3", eventSender.sentEvents()[2].ID())
+}
+
+func Test_HandleEvent(t *testing.T) {
+	envConfig := config.EnvConfig{PubSubRecipient: "http://127.0.0.1"}
+	eventSender := &syncEventSender{}
+	eventLimiter := limiter.New(1, 0, 0, prometheus.NewRegistry())
+	poller := New(envConfig, nil, eventSender, eventLimiter)
+	poller.UpdateSubscriptions([]apimodels.EventSubscription{
+		{ID: "my-sub", Event: "sh.keptn.event.task.triggered"},
+		{ID: "other-sub", Event: "sh.keptn.event.task.triggered", Filter: apimodels.EventSubscriptionFilter{Projects: []string{"podtato-head"}}},
+	})
+	newEvent := func(id string) apimodels.KeptnContextExtendedCE {
+		return apimodels.KeptnContextExtendedCE{
+			ID:          id,
+			Type:        strutils.Stringp("sh.keptn.event.task.triggered"),
+			Source:      strutils.Stringp("source"),
+			Specversion: "1.0",
+			Data:        map[string]interface{}{"project": "sockshop"},
+		}
+	}
+
+	poller.HandleEvent("my-sub", newEvent("id-1"))
+	assert.Eventually(t, func() bool {
+		return len(eventSender.sentEvents()) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// events which have already been sent, events for unknown subscriptions and events exceeding the capacity are not sent
+	poller.HandleEvent("my-sub", newEvent("id-1"))
+	poller.HandleEvent("unknown-sub", newEvent("id-2"))
+	poller.HandleEvent("my-sub", newEvent("id-3"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, eventSender.sentEvents(), 1)
+	assert.Equal(t, 1, eventLimiter.InFlight("my-sub"))
+
+	// events not matching the filter of the subscription do not occupy its capacity
+	poller.HandleEvent("other-sub", newEvent("id-1"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, eventSender.sentEvents(), 1)
+	assert.Equal(t, 0, eventLimiter.InFlight("other-sub"))
+}
+
+// syncEventSender records the sent events like keptnfake.EventSender, but can be read while the poller sends events
+type syncEventSender struct {
+	mutex  sync.Mutex
+	sender keptnfake.EventSender
+}
+
+func (s *syncEventSender) Send(ctx context.Context, event cloudevents.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sender.Send(ctx, event)
+}
+
+func (s *syncEventSender) sentEvents() []cloudevents.Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]cloudevents.Event{}, s.sender.SentEvents...)
+}
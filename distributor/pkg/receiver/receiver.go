@@ -62,7 +62,7 @@ func (n *NATSEventReceiver) Start(ctx *utils.ExecutionContext) error {
 		return fmt.Errorf("could not Start NatsEventReceiver: %w", err)
 	}
 	n.natsConnectionHandler.MessageHandler = n.handleMessage
-	err := n.natsConnectionHandler.QueueSubscribeToTopics(n.env.PubSubTopics(), n.env.PubSubGroup)
+	err := n.natsConnectionHandler.QueueSubscribeToTopics(n.env.PubSubTopics(), n.env.PubSubQueueGroup())
 	if err != nil {
 		return fmt.Errorf("could not subscribe to events: %w", err)
 	}
@@ -86,7 +86,7 @@ func (n *NATSEventReceiver) UpdateSubscriptions(subscriptions []models.EventSubs
 	for _, s := range subscriptions {
 		topics = append(topics, s.Event)
 	}
-	err := n.natsConnectionHandler.QueueSubscribeToTopics(topics, n.env.PubSubGroup)
+	err := n.natsConnectionHandler.QueueSubscribeToTopics(topics, n.env.PubSubQueueGroup())
 	if err != nil {
 		logger.Errorf("Could not subscribe to topics %v: %v", topics, err)
 	}
@@ -160,7 +160,7 @@ func (n *NATSEventReceiver) getSubscriptionsFromReceivedMessage(m *nats.Msg, eve
 	subscriptionsForTopic := []models.EventSubscription{}
 	for _, subscription := range n.currentSubscriptions {
 		if subscription.Event == m.Sub.Subject { // need to check against the name of the subscription because this can be a wildcard as well
-			matcher := utils.NewEventMatcherFromSubscription(subscription)
+			matcher := utils.NewEventMatcherFromSubscription(subscription, n.env)
 			if matcher.Matches(event) {
 				subscriptionsForTopic = append(subscriptionsForTopic, subscription)
 			}
@@ -172,7 +172,7 @@ func (n *NATSEventReceiver) getSubscriptionsFromReceivedMessage(m *nats.Msg, eve
 func (n *NATSEventReceiver) sendEvent(e models.KeptnContextExtendedCE, subscription *models.EventSubscription) error {
 	event := v0_2_0.ToCloudEvent(e)
 	if subscription != nil {
-		matcher := utils.NewEventMatcherFromSubscription(*subscription)
+		matcher := utils.NewEventMatcherFromSubscription(*subscription, n.env)
 		if !matcher.Matches(event) {
 			return nil
 		}
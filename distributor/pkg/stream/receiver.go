@@ -0,0 +1,179 @@
+package stream
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/keptn/distributor/pkg/config"
+	"github.com/keptn/keptn/distributor/pkg/model"
+	"github.com/keptn/keptn/distributor/pkg/utils"
+	logger "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// pingWait is the time after which the connection is considered broken if the control plane did not send a ping
+	pingWait            = 90 * time.Second
+	writeWait           = 10 * time.Second
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// EventHandler sends the events received via the event stream to the Keptn service
+type EventHandler interface {
+	// PollEvents fetches and sends all open .triggered events
+	PollEvents()
+	// HandleEvent sends an event received for the subscription with the given ID
+	HandleEvent(subscriptionID string, event apimodels.KeptnContextExtendedCE)
+}
+
+// Receiver keeps a WebSocket connection to the control plane, which pushes the .triggered events matching the
+// subscriptions of the integration in real time. Events which have been pushed while the connection was interrupted,
+// or which could not be sent because the Keptn service had no capacity, are fetched via the EventHandler whenever the
+// connection is (re-)established and in the resync interval
+type Receiver struct {
+	env           config.EnvConfig
+	integrationID string
+	eventHandler  EventHandler
+	httpClient    *http.Client
+	dialer        *websocket.Dialer
+}
+
+// New creates a new Receiver for the integration with the given ID. The HTTP client is used to obtain the
+// OAuth token for the connection if OAuth is enabled
+func New(env config.EnvConfig, integrationID string, eventHandler EventHandler, httpClient *http.Client) *Receiver {
+	return &Receiver{
+		env:           env,
+		integrationID: integrationID,
+		eventHandler:  eventHandler,
+		httpClient:    httpClient,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: env.GetAPIProxyHTTPTimeout(),
+			TLSClientConfig:  &tls.Config{InsecureSkipVerify: !env.VerifySSL}, //nolint:gosec
+		},
+	}
+}
+
+// Start receives events until the context is done
+func (r *Receiver) Start(ctx *utils.ExecutionContext) error {
+	endpoint, err := r.env.EventStreamEndpoint(r.integrationID)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Receiving events from: %s", endpoint)
+	backoff := minReconnectBackoff
+	for {
+		conn, err := r.connect(endpoint)
+		if err != nil {
+			logger.Warnf("Could not connect to event stream, retrying in %s: %v", backoff, err)
+			select {
+			case <-time.After(backoff):
+				backoff = nextBackoff(backoff)
+				continue
+			case <-ctx.Done():
+				logger.Info("Terminating event stream receiver")
+				ctx.Wg.Done()
+				return nil
+			}
+		}
+		backoff = minReconnectBackoff
+
+		if done := r.receive(ctx, conn); done {
+			logger.Info("Terminating event stream receiver")
+			ctx.Wg.Done()
+			return nil
+		}
+	}
+}
+
+func (r *Receiver) connect(endpoint string) (*websocket.Conn, error) {
+	header := http.Header{}
+	if r.env.KeptnAPIToken != "" {
+		header.Set("x-token", r.env.KeptnAPIToken)
+	}
+	if r.httpClient == nil {
+		return r.dial(endpoint, header)
+	}
+	if transport, ok := r.httpClient.Transport.(*oauth2.Transport); ok {
+		token, err := transport.Source.Token()
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	}
+	return r.dial(endpoint, header)
+}
+
+func (r *Receiver) dial(endpoint string, header http.Header) (*websocket.Conn, error) {
+	conn, resp, err := r.dialer.Dial(endpoint, header)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	return conn, err
+}
+
+// receive processes the events of the connection until it is closed. It returns true if the context is done
+func (r *Receiver) receive(ctx *utils.ExecutionContext, conn *websocket.Conn) bool {
+	defer conn.Close()
+	logger.Info("Connected to event stream")
+
+	events := make(chan model.StreamedEvent)
+	closed := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go readEvents(conn, events, closed, stop)
+
+	// fetch the events which have been missed while the connection was interrupted
+	r.eventHandler.PollEvents()
+	resync := time.NewTicker(r.env.EventStreamResync)
+	defer resync.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			r.eventHandler.HandleEvent(event.SubscriptionID, event.Event)
+		case <-resync.C:
+			r.eventHandler.PollEvents()
+		case err := <-closed:
+			logger.Warnf("Event stream has been closed: %v", err)
+			return false
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+			return true
+		}
+	}
+}
+
+// readEvents reads the events of the connection until it is closed or the receiver stops
+func readEvents(conn *websocket.Conn, events chan<- model.StreamedEvent, closed chan<- error, stop <-chan struct{}) {
+	_ = conn.SetReadDeadline(time.Now().Add(pingWait))
+	conn.SetPingHandler(func(data string) error {
+		_ = conn.SetReadDeadline(time.Now().Add(pingWait))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+	})
+	for {
+		event := model.StreamedEvent{}
+		if err := conn.ReadJSON(&event); err != nil {
+			closed <- err
+			return
+		}
+		select {
+		case events <- event:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxReconnectBackoff {
+		return maxReconnectBackoff
+	}
+	return backoff
+}
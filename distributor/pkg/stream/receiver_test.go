@@ -0,0 +1,102 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/keptn/distributor/pkg/config"
+	"github.com/keptn/keptn/distributor/pkg/model"
+	"github.com/keptn/keptn/distributor/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEventHandler struct {
+	mutex  sync.Mutex
+	polls  int
+	events []model.StreamedEvent
+}
+
+func (h *fakeEventHandler) PollEvents() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.polls++
+}
+
+func (h *fakeEventHandler) HandleEvent(subscriptionID string, event apimodels.KeptnContextExtendedCE) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.events = append(h.events, model.StreamedEvent{SubscriptionID: subscriptionID, Event: event})
+}
+
+func (h *fakeEventHandler) state() (int, []model.StreamedEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.polls, append([]model.StreamedEvent{}, h.events...)
+}
+
+func TestReceiver(t *testing.T) {
+	connections := make(chan *websocket.Conn, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/controlPlane/v1/uniform/registration/my-id/stream", r.URL.Path)
+		assert.Equal(t, "my-token", r.Header.Get("x-token"))
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.Nil(t, err)
+		connections <- conn
+	}))
+	defer server.Close()
+
+	env := config.EnvConfig{KeptnAPIEndpoint: server.URL, KeptnAPIToken: "my-token", EventStreamResync: time.Hour}
+	eventHandler := &fakeEventHandler{}
+	receiver := New(env, "my-id", eventHandler, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	executionContext := utils.NewExecutionContext(ctx, 1)
+	stopped := make(chan struct{})
+	go func() {
+		require.Nil(t, receiver.Start(executionContext))
+		close(stopped)
+	}()
+
+	conn := <-connections
+	require.Eventually(t, func() bool {
+		polls, _ := eventHandler.state()
+		return polls == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Nil(t, conn.WriteJSON(model.StreamedEvent{SubscriptionID: "sub-1", Event: apimodels.KeptnContextExtendedCE{ID: "id-1"}}))
+	require.Eventually(t, func() bool {
+		_, events := eventHandler.state()
+		return len(events) == 1 && events[0].SubscriptionID == "sub-1" && events[0].Event.ID == "id-1"
+	}, time.Second, 10*time.Millisecond)
+
+	// the receiver reconnects and fetches the events it missed in the meantime
+	require.Nil(t, conn.Close())
+	conn = <-connections
+	require.Eventually(t, func() bool {
+		polls, _ := eventHandler.state()
+		return polls == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("receiver did not stop")
+	}
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	executionContext.Wg.Wait()
+}
+
+func Test_nextBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextBackoff(time.Second))
+	assert.Equal(t, maxReconnectBackoff, nextBackoff(45*time.Second))
+}
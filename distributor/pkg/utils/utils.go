@@ -10,15 +10,20 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
-	"github.com/keptn/go-utils/pkg/common/sliceutils"
 	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/keptn/keptn/distributor/pkg/config"
+	logger "github.com/sirupsen/logrus"
 )
 
+// EventMatcher checks whether events match the project, stage and service filters, which are comma separated lists
+// that may contain excluded values, as well as label selectors and expressions over the data of the event
 type EventMatcher struct {
-	Project string
-	Stage   string
-	Service string
+	Project     string
+	Stage       string
+	Service     string
+	Labels      []filter.LabelSelector
+	Expressions []filter.Expression
 }
 
 // ExecutionContext is used for synchronizing components e.g.
@@ -47,20 +52,42 @@ func NewExecutionContext(ctx context.Context, waitGroupCount int) *ExecutionCont
 
 func NewEventMatcherFromEnv(config config.EnvConfig) *EventMatcher {
 	return &EventMatcher{
-		Project: config.ProjectFilter,
-		Stage:   config.StageFilter,
-		Service: config.ServiceFilter,
+		Project:     config.ProjectFilter,
+		Stage:       config.StageFilter,
+		Service:     config.ServiceFilter,
+		Labels:      labelSelectorsFromEnv(config),
+		Expressions: expressionsFromEnv(config),
 	}
 }
 
-func NewEventMatcherFromSubscription(subscription apimodels.EventSubscription) *EventMatcher {
+// NewEventMatcherFromSubscription creates an EventMatcher for the filter of the subscription. As the label selectors
+// and expressions are evaluated locally, they are taken from the environment
+func NewEventMatcherFromSubscription(subscription apimodels.EventSubscription, config config.EnvConfig) *EventMatcher {
 	return &EventMatcher{
-		Project: strings.Join(subscription.Filter.Projects, ","),
-		Stage:   strings.Join(subscription.Filter.Stages, ","),
-		Service: strings.Join(subscription.Filter.Services, ","),
+		Project:     strings.Join(subscription.Filter.Projects, ","),
+		Stage:       strings.Join(subscription.Filter.Stages, ","),
+		Service:     strings.Join(subscription.Filter.Services, ","),
+		Labels:      labelSelectorsFromEnv(config),
+		Expressions: expressionsFromEnv(config),
 	}
 }
 
+func labelSelectorsFromEnv(config config.EnvConfig) []filter.LabelSelector {
+	selectors, err := filter.ParseLabelSelectors(config.LabelFilter)
+	if err != nil {
+		logger.Errorf("Ignoring invalid label filter: %v", err)
+	}
+	return selectors
+}
+
+func expressionsFromEnv(config config.EnvConfig) []filter.Expression {
+	expressions, err := filter.ParseExpressions(config.ExpressionFilter)
+	if err != nil {
+		logger.Errorf("Ignoring invalid expression filter: %v", err)
+	}
+	return expressions
+}
+
 func (ef EventMatcher) Matches(e cloudevents.Event) bool {
 	// decode event data
 	generalEventData := &v0_2_0.EventData{}
@@ -68,11 +95,28 @@ func (ef EventMatcher) Matches(e cloudevents.Event) bool {
 		return false
 	}
 
-	if ef.Project != "" && !sliceutils.ContainsStr(strings.Split(ef.Project, ","), generalEventData.Project) ||
-		ef.Stage != "" && !sliceutils.ContainsStr(strings.Split(ef.Stage, ","), generalEventData.Stage) ||
-		ef.Service != "" && !sliceutils.ContainsStr(strings.Split(ef.Service, ","), generalEventData.Service) {
+	if !filter.MatchesList(ef.Project, generalEventData.Project) ||
+		!filter.MatchesList(ef.Stage, generalEventData.Stage) ||
+		!filter.MatchesList(ef.Service, generalEventData.Service) {
 		return false
 	}
+
+	for _, selector := range ef.Labels {
+		if !selector.Matches(generalEventData.Labels) {
+			return false
+		}
+	}
+	if len(ef.Expressions) > 0 {
+		var data interface{}
+		if err := e.DataAs(&data); err != nil {
+			return false
+		}
+		for _, expression := range ef.Expressions {
+			if !expression.Matches(data) {
+				return false
+			}
+		}
+	}
 	return true
 }
 
@@ -5,6 +5,7 @@ import (
 	"github.com/keptn/go-utils/pkg/api/models"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"reflect"
@@ -370,6 +371,92 @@ func Test_matchesFilter(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "project exclusion - should not match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Project: "!my-project",
+			},
+			want: false,
+		},
+		{
+			name: "service exclusion - should match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Service: "!my-other-service",
+			},
+			want: true,
+		},
+		{
+			name: "label filter - should match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+					Labels:  map[string]string{"team": "payments"},
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Labels: []filter.LabelSelector{{Key: "team", Operator: "=", Value: "payments"}},
+			},
+			want: true,
+		},
+		{
+			name: "label filter - should not match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+					Labels:  map[string]string{"team": "checkout"},
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Labels: []filter.LabelSelector{{Key: "team", Operator: "=", Value: "payments"}},
+			},
+			want: false,
+		},
+		{
+			name: "expression filter - should match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Expressions: []filter.Expression{{Path: []string{"stage"}, Operator: "==", Value: "my-stage"}},
+			},
+			want: true,
+		},
+		{
+			name: "expression filter - should not match",
+			args: args{
+				getCloudEventWithEventData(keptnv2.EventData{
+					Project: "my-project",
+					Stage:   "my-stage",
+					Service: "my-service",
+				}),
+			},
+			eventMatcher: EventMatcher{
+				Expressions: []filter.Expression{{Path: []string{"result"}}},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -4,33 +4,78 @@ import (
 	"fmt"
 	keptn "github.com/keptn/go-utils/pkg/lib"
 	logger "github.com/sirupsen/logrus"
+	"reflect"
 	"strconv"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/helm-service/pkg/configurationchanger"
+	"github.com/keptn/keptn/helm-service/pkg/helm"
+	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	keptntypes "github.com/keptn/keptn/helm-service/pkg/types"
+	keptnutils "github.com/keptn/kubernetes-utils/pkg"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
 )
 
-// ActionTriggeredHandler handles sh.keptn.events.action.triggered events for scaling
+// ActionTriggeredHandler handles sh.keptn.events.action.triggered events for scaling, rolling back and restarting a service
 type ActionTriggeredHandler struct {
 	Handler
-	configChanger configurationchanger.IConfigurationChanger
+	mesh           mesh.Mesh
+	configChanger  configurationchanger.IConfigurationChanger
+	chartGenerator helm.ChartGenerator
+	chartStorer    keptntypes.IChartStorer
+	chartPackager  keptntypes.IChartPackager
 }
 
 // ActionScaling is the identifier for the scaling action
 const ActionScaling = "scaling"
 
+// ActionRollback is the identifier for the action rolling back the Helm release of a service
+const ActionRollback = "rollback"
+
+// ActionRestart is the identifier for the action triggering a rolling restart of a service
+const ActionRestart = "restart"
+
+// ReleaseActionFinishedEventData is the data of a sh.keptn.event.action.finished event sent by the helm-service.
+// Actions changing a Helm release report the resulting revision of the release
+type ReleaseActionFinishedEventData struct {
+	keptnv2.EventData
+	Release *ReleaseRevision `json:"release,omitempty"`
+}
+
+// ReleaseRevision describes the revision of a Helm release
+type ReleaseRevision struct {
+	Name     string `json:"name"`
+	Revision int    `json:"revision"`
+}
+
 // NewActionTriggeredHandler creates a new ActionTriggeredHandler
-func NewActionTriggeredHandler(keptnHandler Handler, configChanger configurationchanger.IConfigurationChanger) *ActionTriggeredHandler {
+func NewActionTriggeredHandler(keptnHandler Handler,
+	mesh mesh.Mesh,
+	configChanger configurationchanger.IConfigurationChanger,
+	chartGenerator helm.ChartGenerator,
+	chartStorer keptntypes.IChartStorer,
+	chartPackager keptntypes.IChartPackager) *ActionTriggeredHandler {
 
 	return &ActionTriggeredHandler{
-		Handler:       keptnHandler,
-		configChanger: configChanger,
+		Handler:        keptnHandler,
+		mesh:           mesh,
+		configChanger:  configChanger,
+		chartGenerator: chartGenerator,
+		chartStorer:    chartStorer,
+		chartPackager:  chartPackager,
 	}
 }
 
-// HandleEvent takes the sh.keptn.events.action.triggered event and performs the scaling action on the generated chart
-// Therefore, this scaling action only works if the service is deployed b/g
+// isHandledAction returns whether the action is performed by the helm-service
+func isHandledAction(action string) bool {
+	return action == ActionScaling || action == ActionRollback || action == ActionRestart
+}
+
+// HandleEvent takes the sh.keptn.events.action.triggered event and performs the requested action.
+// The scaling action is performed on the generated chart and therefore only works if the service is deployed b/g.
+// The rollback and restart actions are performed on the release serving the traffic of the service
 func (h *ActionTriggeredHandler) HandleEvent(ce cloudevents.Event) {
 
 	actionTriggeredEvent := keptnv2.ActionTriggeredEventData{}
@@ -44,35 +89,44 @@ func (h *ActionTriggeredHandler) HandleEvent(ce cloudevents.Event) {
 
 	commitID := retrieveCommit(ce)
 
-	if actionTriggeredEvent.Action.Action == ActionScaling {
-		// Send action.started event
-		logger.Info(fmt.Sprintf("Start action scaling for service %s in stage %s of project %s",
-			actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project))
-		if sendErr := h.sendEvent(ce.ID(), keptnv2.GetStartedEventType(keptnv2.ActionTaskName),
-			h.getStartedEventData(actionTriggeredEvent.EventData)); sendErr != nil {
-			h.handleError(ce.ID(), sendErr, keptnv2.ActionTaskName, h.getFinishedEventDataForError(actionTriggeredEvent.EventData, sendErr))
-			return
+	var handleAction func(e keptnv2.ActionTriggeredEventData, commitID string) ReleaseActionFinishedEventData
+	switch actionTriggeredEvent.Action.Action {
+	case ActionScaling:
+		handleAction = func(e keptnv2.ActionTriggeredEventData, commitID string) ReleaseActionFinishedEventData {
+			return ReleaseActionFinishedEventData{EventData: h.handleScaling(e, commitID).EventData}
 		}
+	case ActionRollback:
+		handleAction = h.handleRollback
+	case ActionRestart:
+		handleAction = h.handleRestart
+	default:
+		logger.Info(fmt.Sprintf("Received unhandled action %s for service %s in stage %s of project %s",
+			actionTriggeredEvent.Action.Action, actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project))
+		return
+	}
 
-		resp := h.handleScaling(actionTriggeredEvent, commitID)
-		if resp.Status == keptnv2.StatusErrored {
-			logger.Errorf("action %s errored with result %s", actionTriggeredEvent.Action.Action, resp.Message)
-		} else {
-			logger.Infof("Finished action %s for service %s in stage %s of project %s",
-				actionTriggeredEvent.Action.Action, actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project)
-		}
+	// Send action.started event
+	logger.Info(fmt.Sprintf("Start action %s for service %s in stage %s of project %s", actionTriggeredEvent.Action.Action,
+		actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project))
+	if sendErr := h.sendEvent(ce.ID(), keptnv2.GetStartedEventType(keptnv2.ActionTaskName),
+		h.getStartedEventData(actionTriggeredEvent.EventData)); sendErr != nil {
+		h.handleError(ce.ID(), sendErr, keptnv2.ActionTaskName, h.getFinishedEventDataForError(actionTriggeredEvent.EventData, sendErr))
+		return
+	}
 
-		// Send action.finished event
-		if err := h.sendEvent(ce.ID(), keptnv2.GetFinishedEventType(keptnv2.ActionTaskName), resp); err != nil {
-			h.handleError(ce.ID(), err, keptnv2.ActionTaskName, h.getFinishedEventDataForError(actionTriggeredEvent.EventData, err))
-			return
-		}
+	resp := handleAction(actionTriggeredEvent, commitID)
+	if resp.Status == keptnv2.StatusErrored {
+		logger.Errorf("action %s errored with result %s", actionTriggeredEvent.Action.Action, resp.Message)
 	} else {
-		logger.Info(fmt.Sprintf("Received unhandled action %s for service %s in stage %s of project %s",
-			actionTriggeredEvent.Action.Action, actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project))
+		logger.Infof("Finished action %s for service %s in stage %s of project %s",
+			actionTriggeredEvent.Action.Action, actionTriggeredEvent.Service, actionTriggeredEvent.Stage, actionTriggeredEvent.Project)
 	}
 
-	return
+	// Send action.finished event
+	if err := h.sendEvent(ce.ID(), keptnv2.GetFinishedEventType(keptnv2.ActionTaskName), resp); err != nil {
+		h.handleError(ce.ID(), err, keptnv2.ActionTaskName, h.getFinishedEventDataForError(actionTriggeredEvent.EventData, err))
+		return
+	}
 }
 
 func (h *ActionTriggeredHandler) getStartedEventData(inEventData keptnv2.EventData) keptnv2.ActionStartedEventData {
@@ -84,10 +138,10 @@ func (h *ActionTriggeredHandler) getStartedEventData(inEventData keptnv2.EventDa
 	}
 }
 
-func (h *ActionTriggeredHandler) getFinishedEventDataForSuccess(inEventData keptnv2.EventData) keptnv2.ActionFinishedEventData {
+func (h *ActionTriggeredHandler) getFinishedEventDataForSuccess(inEventData keptnv2.EventData, action string) keptnv2.ActionFinishedEventData {
 	inEventData.Status = keptnv2.StatusSucceeded
 	inEventData.Result = keptnv2.ResultPass
-	inEventData.Message = "Successfully executed scaling action"
+	inEventData.Message = fmt.Sprintf("Successfully executed %s action", action)
 	return keptnv2.ActionFinishedEventData{
 		EventData: inEventData,
 	}
@@ -144,5 +198,183 @@ func (h *ActionTriggeredHandler) handleScaling(e keptnv2.ActionTriggeredEventDat
 		return h.getFinishedEventDataForError(e.EventData, err)
 	}
 
-	return h.getFinishedEventDataForSuccess(e.EventData)
+	return h.getFinishedEventDataForSuccess(e.EventData, ActionScaling)
+}
+
+func (h *ActionTriggeredHandler) handleRollback(e keptnv2.ActionTriggeredEventData, commitID string) ReleaseActionFinishedEventData {
+
+	revision, err := getRevision(e.Action.Value)
+	if err != nil {
+		return ReleaseActionFinishedEventData{EventData: h.getFinishedEventData(e.EventData, keptnv2.StatusSucceeded,
+			keptnv2.ResultFailed, err.Error()).EventData}
+	}
+
+	releaseName := h.getReleaseName(e.EventData)
+	var newRevision int
+	if releaseName == helm.GetReleaseName(e.Project, e.Stage, e.Service, true) {
+		// the revision refers to the user release, which is the release a later rollback is based on
+		releaseName = helm.GetReleaseName(e.Project, e.Stage, e.Service, false)
+		newRevision, err = h.rollbackBlueGreen(e.EventData, commitID, revision)
+	} else {
+		newRevision, err = h.getHelmExecutor().RollbackRelease(releaseName, e.Project+"-"+e.Stage, revision)
+	}
+	if err != nil {
+		return ReleaseActionFinishedEventData{EventData: h.getFinishedEventDataForError(e.EventData, err).EventData}
+	}
+	return ReleaseActionFinishedEventData{
+		EventData: h.getFinishedEventDataForSuccess(e.EventData, ActionRollback).EventData,
+		Release:   &ReleaseRevision{Name: releaseName, Revision: newRevision},
+	}
+}
+
+// rollbackBlueGreen rolls back a service deployed b/g to the chart of the given revision of the user release, or to
+// the last completed revision deploying a different chart than the current one. Rolling back the generated release
+// would restore the weights and the primary of an intermediate step of a promotion, therefore the chart is deployed
+// as canary and promoted like a release: the primary is replaced by the rolled back version, receives all traffic
+// and the canary is scaled down. The user chart and the generated chart in the configuration repository are
+// updated accordingly. It returns the new revision of the user release
+func (h *ActionTriggeredHandler) rollbackBlueGreen(e keptnv2.EventData, commitID string, revision int) (int, error) {
+	namespace := e.Project + "-" + e.Stage
+	userReleaseName := helm.GetReleaseName(e.Project, e.Stage, e.Service, false)
+	history, err := h.getHelmExecutor().GetReleaseHistory(userReleaseName, namespace)
+	if err != nil {
+		return 0, err
+	}
+	target, err := getRollbackTarget(history, revision)
+	if err != nil {
+		return 0, fmt.Errorf("could not roll back release %s: %w", userReleaseName, err)
+	}
+	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Rolling back service %s in stage %s of project %s to revision %d of release %s",
+		e.Service, e.Stage, e.Project, target.Version, userReleaseName))
+
+	// revert the user chart in the configuration repository and deploy it as canary
+	chartData, err := h.chartPackager.Package(target.Chart)
+	if err != nil {
+		return 0, err
+	}
+	commitID, err = h.chartStorer.Store(keptnutils.StoreChartOptions{
+		Project:   e.Project,
+		Service:   e.Service,
+		Stage:     e.Stage,
+		ChartName: helm.GetChartName(e.Service, false),
+		HelmChart: chartData,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := h.upgradeChart(target.Chart, e, keptn.Duplicate); err != nil {
+		return 0, err
+	}
+
+	releaseHandler := &ReleaseHandler{
+		Handler:               h.Handler,
+		mesh:                  h.mesh,
+		generatedChartHandler: h.chartGenerator,
+		configurationChanger:  h.configChanger,
+		chartStorer:           h.chartStorer,
+		chartPackager:         h.chartPackager,
+	}
+	if _, err := releaseHandler.promoteDeployment(e, commitID); err != nil {
+		return 0, err
+	}
+
+	userHistory, err := h.getHelmExecutor().GetReleaseHistory(userReleaseName, namespace)
+	if err != nil {
+		return 0, err
+	}
+	if len(userHistory) == 0 {
+		return 0, nil
+	}
+	return userHistory[len(userHistory)-1].Version, nil
+}
+
+// getRollbackTarget returns the revision of the history with the given version. Without a version, the last
+// completed revision deploying a different chart than the current revision is returned
+func getRollbackTarget(history []*release.Release, version int) (*release.Release, error) {
+	if len(history) == 0 {
+		return nil, fmt.Errorf("the release has no revisions")
+	}
+	if version > 0 {
+		for _, revision := range history {
+			if revision.Version == version {
+				return revision, nil
+			}
+		}
+		return nil, fmt.Errorf("revision %d does not exist", version)
+	}
+	current := history[len(history)-1]
+	for i := len(history) - 2; i >= 0; i-- {
+		revision := history[i]
+		if revision.Info == nil || (revision.Info.Status != release.StatusSuperseded && revision.Info.Status != release.StatusDeployed) {
+			continue
+		}
+		if !sameChart(revision.Chart, current.Chart) {
+			return revision, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no previous revision with a different chart")
+}
+
+// sameChart returns whether the charts have the same metadata, values and templates. Revisions created by scaling
+// a release only differ in the values they have been deployed with, but not in their chart
+func sameChart(a, b *chart.Chart) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(a.Metadata, b.Metadata) && reflect.DeepEqual(a.Values, b.Values) &&
+		reflect.DeepEqual(a.Templates, b.Templates)
+}
+
+func (h *ActionTriggeredHandler) handleRestart(e keptnv2.ActionTriggeredEventData, commitID string) ReleaseActionFinishedEventData {
+
+	releaseName := h.getReleaseName(e.EventData)
+	revision, err := h.getHelmExecutor().RestartRelease(releaseName, e.Project+"-"+e.Stage)
+	if err != nil {
+		return ReleaseActionFinishedEventData{EventData: h.getFinishedEventDataForError(e.EventData, err).EventData}
+	}
+	return ReleaseActionFinishedEventData{
+		EventData: h.getFinishedEventDataForSuccess(e.EventData, ActionRestart).EventData,
+		Release:   &ReleaseRevision{Name: releaseName, Revision: revision},
+	}
+}
+
+// getReleaseName returns the release serving the traffic of the service. For b/g deployments, this is the generated
+// release containing the primary deployment. Otherwise, the generated release only contains the mesh configuration
+// and the release of the user chart is returned
+func (h *ActionTriggeredHandler) getReleaseName(e keptnv2.EventData) string {
+	generatedReleaseName := helm.GetReleaseName(e.Project, e.Stage, e.Service, true)
+	manifest, err := h.getHelmExecutor().GetManifest(generatedReleaseName, e.Project+"-"+e.Stage)
+	if err == nil && len(helm.GetDeployments(manifest)) > 0 {
+		return generatedReleaseName
+	}
+	return helm.GetReleaseName(e.Project, e.Stage, e.Service, false)
+}
+
+// getRevision returns the revision the release is rolled back to. Without a value, the release is rolled back
+// to the previous revision, which is indicated by 0
+func getRevision(value interface{}) (int, error) {
+	var revision int
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		revision = int(v)
+		if float64(revision) != v {
+			return 0, fmt.Errorf("could not parse action.value to int")
+		}
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		var err error
+		if revision, err = strconv.Atoi(v); err != nil {
+			return 0, fmt.Errorf("could not parse action.value to int")
+		}
+	default:
+		return 0, fmt.Errorf("could not parse action.value to int")
+	}
+	if revision < 1 {
+		return 0, fmt.Errorf("action.value has to be a revision greater than 0")
+	}
+	return revision, nil
 }
@@ -0,0 +1,364 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnevents "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/pkg/configurationchanger"
+	"github.com/keptn/keptn/helm-service/pkg/helm"
+	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	keptnutils "github.com/keptn/kubernetes-utils/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+)
+
+// fakeBlueGreenEnvironment keeps the history of the Helm releases in the cluster and the charts in the
+// configuration repository, such that a b/g deployment can be promoted and rolled back
+type fakeBlueGreenEnvironment struct {
+	releases map[string][]*release.Release
+	charts   map[string][]byte
+	commits  int
+}
+
+func newFakeBlueGreenEnvironment() *fakeBlueGreenEnvironment {
+	return &fakeBlueGreenEnvironment{releases: map[string][]*release.Release{}, charts: map[string][]byte{}}
+}
+
+func (f *fakeBlueGreenEnvironment) GetManifest(releaseName string, namespace string) (string, error) {
+	history := f.releases[releaseName]
+	if len(history) == 0 {
+		return "", errors.New("release: not found")
+	}
+	return history[len(history)-1].Manifest, nil
+}
+
+func (f *fakeBlueGreenEnvironment) GetReleaseHistory(releaseName string, namespace string) ([]*release.Release, error) {
+	return f.releases[releaseName], nil
+}
+
+func (f *fakeBlueGreenEnvironment) UpgradeChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) error {
+	manifest, err := f.RenderChart(ch, releaseName, namespace, vals)
+	if err != nil {
+		return err
+	}
+	deployed, err := copyChart(ch)
+	if err != nil {
+		return err
+	}
+	history := f.releases[releaseName]
+	if len(history) > 0 {
+		history[len(history)-1].Info.Status = release.StatusSuperseded
+	}
+	f.releases[releaseName] = append(history, &release.Release{
+		Name:     releaseName,
+		Version:  len(history) + 1,
+		Chart:    deployed,
+		Config:   vals,
+		Manifest: manifest,
+		Info:     &release.Info{Status: release.StatusDeployed},
+	})
+	return nil
+}
+
+func (f *fakeBlueGreenEnvironment) RenderChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) (string, error) {
+	return (&helm.HelmV3Executor{}).RenderChart(ch, releaseName, namespace, vals)
+}
+
+func (f *fakeBlueGreenEnvironment) UninstallRelease(releaseName, namespace string) error {
+	return errors.New("not supported")
+}
+
+func (f *fakeBlueGreenEnvironment) RollbackRelease(releaseName, namespace string, revision int) (int, error) {
+	return 0, errors.New("not supported")
+}
+
+func (f *fakeBlueGreenEnvironment) RestartRelease(releaseName, namespace string) (int, error) {
+	return 0, errors.New("not supported")
+}
+
+// Store stores the chart in the configuration repository
+func (f *fakeBlueGreenEnvironment) Store(opts keptnutils.StoreChartOptions) (string, error) {
+	f.charts[opts.ChartName] = opts.HelmChart
+	f.commits++
+	return fmt.Sprintf("commit-%d", f.commits), nil
+}
+
+// UpdateLoadedChart manipulates the chart and stores it in the configuration repository
+func (f *fakeBlueGreenEnvironment) UpdateLoadedChart(ch *chart.Chart, event keptnv2.EventData, generated bool,
+	chartUpdater configurationchanger.ChartManipulator) (*chart.Chart, string, error) {
+	if err := chartUpdater.Manipulate(ch); err != nil {
+		return nil, "", err
+	}
+	data, err := keptnutils.NewChartPackager().Package(ch)
+	if err != nil {
+		return nil, "", err
+	}
+	commitID, err := f.Store(keptnutils.StoreChartOptions{ChartName: helm.GetChartName(event.Service, generated), HelmChart: data})
+	return ch, commitID, err
+}
+
+func (f *fakeBlueGreenEnvironment) loadChart(name string) (*chart.Chart, error) {
+	data, ok := f.charts[name]
+	if !ok {
+		return nil, fmt.Errorf("chart %s not found", name)
+	}
+	return loader.LoadArchive(bytes.NewReader(data))
+}
+
+func copyChart(ch *chart.Chart) (*chart.Chart, error) {
+	data, err := keptnutils.NewChartPackager().Package(ch)
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadArchive(bytes.NewReader(data))
+}
+
+// blueGreenTestHandler reads the charts from and upgrades the releases of a fakeBlueGreenEnvironment
+type blueGreenTestHandler struct {
+	*MockedHandler
+	env *fakeBlueGreenEnvironment
+}
+
+func (h *blueGreenTestHandler) getHelmExecutor() helm.HelmExecutor {
+	return h.env
+}
+
+func (h *blueGreenTestHandler) getGeneratedChart(e keptnv2.EventData, commitID string) (*chart.Chart, string, error) {
+	ch, err := h.env.loadChart(helm.GetChartName(e.Service, true))
+	return ch, commitID, err
+}
+
+func (h *blueGreenTestHandler) getUserChart(e keptnv2.EventData, commitID string) (*chart.Chart, string, error) {
+	ch, err := h.env.loadChart(helm.GetChartName(e.Service, false))
+	return ch, commitID, err
+}
+
+func (h *blueGreenTestHandler) upgradeChart(ch *chart.Chart, event keptnv2.EventData, strategy keptnevents.DeploymentStrategy) error {
+	return NewHandlerBase(h.getKeptnHandler(), h.env, "").upgradeChart(ch, event, strategy)
+}
+
+func (h *blueGreenTestHandler) upgradeChartWithReplicas(ch *chart.Chart, event keptnv2.EventData,
+	strategy keptnevents.DeploymentStrategy, replicas int) error {
+	return NewHandlerBase(h.getKeptnHandler(), h.env, "").upgradeChartWithReplicas(ch, event, strategy, replicas)
+}
+
+func newBlueGreenUserChart(t *testing.T, image string) *chart.Chart {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "carts", Version: "0.1.0"},
+		Values:   map[string]interface{}{"image": image, "replicaCount": 1},
+		Raw:      []*chart.File{{Name: "values.yaml"}},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app: carts
+  template:
+    metadata:
+      labels:
+        app: carts
+    spec:
+      containers:
+      - name: carts
+        image: {{ .Values.image }}
+`)},
+			{Name: "templates/service.yaml", Data: []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: carts
+spec:
+  ports:
+  - name: http
+    port: 80
+    targetPort: 8080
+  selector:
+    app: carts
+`)},
+		},
+	}
+	ch, err := copyChart(ch)
+	require.Nil(t, err)
+	return ch
+}
+
+var blueGreenEventData = keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"}
+
+// deployBlueGreen deploys the image as canary and routes all traffic to it like the deployment handler,
+// and promotes it like the release handler
+func deployBlueGreen(t *testing.T, handler *blueGreenTestHandler, istio mesh.Mesh, image string) {
+	env := handler.env
+	userChart := newBlueGreenUserChart(t, image)
+	data, err := keptnutils.NewChartPackager().Package(userChart)
+	require.Nil(t, err)
+	_, err = env.Store(keptnutils.StoreChartOptions{ChartName: "carts", HelmChart: data})
+	require.Nil(t, err)
+	require.Nil(t, handler.upgradeChart(userChart, blueGreenEventData, keptnevents.Duplicate))
+
+	if _, ok := env.charts["carts-generated"]; !ok {
+		manifest, err := env.GetManifest("sockshop-production-carts", "sockshop-production")
+		require.Nil(t, err)
+		genChart, err := helm.NewGeneratedChartGenerator(istio).GenerateDuplicateChart(manifest, "sockshop", "production", "carts")
+		require.Nil(t, err)
+		data, err := keptnutils.NewChartPackager().Package(genChart)
+		require.Nil(t, err)
+		_, err = env.Store(keptnutils.StoreChartOptions{ChartName: "carts-generated", HelmChart: data})
+		require.Nil(t, err)
+	}
+	genChart, _, err := handler.getGeneratedChart(blueGreenEventData, "")
+	require.Nil(t, err)
+	genChart, _, err = env.UpdateLoadedChart(genChart, blueGreenEventData, true, configurationchanger.NewCanaryWeightManipulator(istio, 100))
+	require.Nil(t, err)
+	require.Nil(t, handler.upgradeChart(genChart, blueGreenEventData, keptnevents.Duplicate))
+
+	releaseHandler := NewReleaseHandler(handler, istio, env, helm.NewGeneratedChartGenerator(istio), env, keptnutils.NewChartPackager(),
+		nil, nil, nil, nil)
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ReleaseTriggeredEventData{
+		EventData:  keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts", Result: keptnv2.ResultPass},
+		Deployment: keptnv2.DeploymentFinishedData{DeploymentStrategy: keptnevents.Duplicate.String()},
+	})
+	releaseHandler.HandleEvent(ce)
+	require.Empty(t, handler.handledErrorEvents)
+}
+
+// getDeployment returns the image and the replicas of the deployment in the manifest
+func getDeployment(t *testing.T, manifest string, name string) (string, int32) {
+	for _, depl := range helm.GetDeployments(manifest) {
+		if depl.Name == name {
+			if depl.Spec.Replicas == nil {
+				return depl.Spec.Template.Spec.Containers[0].Image, 1
+			}
+			return depl.Spec.Template.Spec.Containers[0].Image, *depl.Spec.Replicas
+		}
+	}
+	t.Fatalf("deployment %s not found", name)
+	return "", 0
+}
+
+// getCanaryWeight returns the weight of the canary in the VirtualService of the manifest
+func getCanaryWeight(t *testing.T, manifest string) int {
+	for _, document := range strings.Split(manifest, "\n---") {
+		vs := struct {
+			Kind string `json:"kind"`
+			Spec struct {
+				HTTP []struct {
+					Route []struct {
+						Destination struct {
+							Host string `json:"host"`
+						} `json:"destination"`
+						Weight int `json:"weight"`
+					} `json:"route"`
+				} `json:"http"`
+			} `json:"spec"`
+		}{}
+		if err := yaml.Unmarshal([]byte(document), &vs); err != nil || vs.Kind != "VirtualService" {
+			continue
+		}
+		for _, route := range vs.Spec.HTTP[0].Route {
+			if strings.HasPrefix(route.Destination.Host, "carts-canary") {
+				return route.Weight
+			}
+		}
+	}
+	t.Fatal("VirtualService not found")
+	return 0
+}
+
+func TestHandleActionTriggeredEvent_RollbackBlueGreenAfterPromotion(t *testing.T) {
+	env := newFakeBlueGreenEnvironment()
+	handler := &blueGreenTestHandler{MockedHandler: NewMockedHandler(createKeptn(), ""), env: env}
+	istio := mesh.NewIstioMesh()
+
+	deployBlueGreen(t, handler, istio, "carts:0.1.0")
+	deployBlueGreen(t, handler, istio, "carts:0.2.0")
+
+	generatedManifest, err := env.GetManifest("sockshop-production-carts-generated", "sockshop-production")
+	require.Nil(t, err)
+	image, _ := getDeployment(t, generatedManifest, "carts-primary")
+	require.Equal(t, "carts:0.2.0", image)
+	require.Equal(t, 0, getCanaryWeight(t, generatedManifest))
+
+	instance := NewActionTriggeredHandler(handler, istio, env, helm.NewGeneratedChartGenerator(istio), env, keptnutils.NewChartPackager())
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ActionTriggeredEventData{
+		EventData: blueGreenEventData,
+		Action:    keptnv2.ActionInfo{Action: ActionRollback},
+	})
+	handler.sentCloudEvents = nil
+	instance.HandleEvent(ce)
+
+	require.Empty(t, handler.handledErrorEvents)
+	finishedEventData := ReleaseActionFinishedEventData{}
+	require.Nil(t, handler.sentCloudEvents[len(handler.sentCloudEvents)-1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.ResultPass, finishedEventData.Result)
+	// the revision of the user release is reported, which can be passed to a later rollback
+	userHistory := env.releases["sockshop-production-carts"]
+	assert.Equal(t, &ReleaseRevision{Name: "sockshop-production-carts", Revision: len(userHistory)}, finishedEventData.Release)
+
+	// the primary runs the previous version and receives all traffic
+	generatedManifest, err = env.GetManifest("sockshop-production-carts-generated", "sockshop-production")
+	require.Nil(t, err)
+	image, _ = getDeployment(t, generatedManifest, "carts-primary")
+	assert.Equal(t, "carts:0.1.0", image)
+	assert.Equal(t, 0, getCanaryWeight(t, generatedManifest))
+
+	// the canary runs the previous version as well and is scaled down
+	userManifest, err := env.GetManifest("sockshop-production-carts", "sockshop-production")
+	require.Nil(t, err)
+	image, replicas := getDeployment(t, userManifest, "carts")
+	assert.Equal(t, "carts:0.1.0", image)
+	assert.Equal(t, int32(0), replicas)
+
+	// the charts in the configuration repository are reverted, such that the next promotion is consistent
+	userChart, err := env.loadChart("carts")
+	require.Nil(t, err)
+	assert.Equal(t, "carts:0.1.0", userChart.Values["image"])
+	generatedChart, err := env.loadChart("carts-generated")
+	require.Nil(t, err)
+	renderedGeneratedChart, err := env.RenderChart(generatedChart, "sockshop-production-carts-generated", "sockshop-production", nil)
+	require.Nil(t, err)
+	image, _ = getDeployment(t, renderedGeneratedChart, "carts-primary")
+	assert.Equal(t, "carts:0.1.0", image)
+	assert.Equal(t, 0, getCanaryWeight(t, renderedGeneratedChart))
+}
+
+func Test_getRollbackTarget(t *testing.T) {
+	v1 := &chart.Chart{Metadata: &chart.Metadata{Name: "carts", Version: "0.1.0"}}
+	v2 := &chart.Chart{Metadata: &chart.Metadata{Name: "carts", Version: "0.2.0"}}
+	history := []*release.Release{
+		{Version: 1, Chart: v1, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 2, Chart: v1, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 3, Chart: v2, Info: &release.Info{Status: release.StatusFailed}},
+		{Version: 4, Chart: v2, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 5, Chart: v2, Info: &release.Info{Status: release.StatusDeployed}},
+	}
+
+	target, err := getRollbackTarget(history, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 2, target.Version)
+
+	target, err = getRollbackTarget(history, 1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, target.Version)
+
+	_, err = getRollbackTarget(history, 6)
+	assert.NotNil(t, err)
+	_, err = getRollbackTarget(history[:2], 0)
+	assert.NotNil(t, err)
+	_, err = getRollbackTarget(nil, 0)
+	assert.NotNil(t, err)
+}
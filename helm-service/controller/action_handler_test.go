@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"errors"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/golang/mock/gomock"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/helm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -13,7 +16,8 @@ func TestCreateActionHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	instance := NewActionTriggeredHandler(&MockedHandler{}, mocks.NewMockIConfigurationChanger(ctrl))
+	instance := NewActionTriggeredHandler(&MockedHandler{}, mocks.NewMockMesh(ctrl), mocks.NewMockIConfigurationChanger(ctrl),
+		mocks.NewMockChartGenerator(ctrl), mocks.NewMockIChartStorer(ctrl), mocks.NewMockIChartPackager(ctrl))
 	assert.NotNil(t, instance)
 }
 
@@ -314,3 +318,146 @@ func TestHandleEvent_WithMissingAction(t *testing.T) {
 	instance.HandleEvent(ce)
 
 }
+
+func TestHandleActionTriggeredEvent_Rollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+
+	instance := &ActionTriggeredHandler{Handler: mockedBaseHandler, configChanger: mocks.NewMockIConfigurationChanger(ctrl)}
+
+	tests := []struct {
+		name             string
+		value            interface{}
+		expectedRevision int
+	}{
+		{name: "previous revision", value: nil, expectedRevision: 0},
+		{name: "revision as number", value: 3, expectedRevision: 3},
+		{name: "revision as string", value: "3", expectedRevision: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedBaseHandler.sentCloudEvents = nil
+			ce := cloudevents.NewEvent()
+			_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ActionTriggeredEventData{
+				EventData: keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+				Action:    keptnv2.ActionInfo{Action: ActionRollback, Value: tt.value},
+			})
+
+			// the generated release contains no deployment, i.e. the service is deployed directly
+			mockedHelmExecutor.EXPECT().GetManifest("sockshop-production-carts-generated", "sockshop-production").Return("", nil)
+			mockedHelmExecutor.EXPECT().RollbackRelease("sockshop-production-carts", "sockshop-production", tt.expectedRevision).Return(5, nil)
+
+			instance.HandleEvent(ce)
+
+			require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+			finishedEventData := ReleaseActionFinishedEventData{}
+			require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+			assert.Equal(t, keptnv2.ResultPass, finishedEventData.Result)
+			assert.Equal(t, "Successfully executed rollback action", finishedEventData.Message)
+			assert.Equal(t, &ReleaseRevision{Name: "sockshop-production-carts", Revision: 5}, finishedEventData.Release)
+		})
+	}
+}
+
+func TestHandleActionTriggeredEvent_RollbackWithInvalidRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedBaseHandler.helmExecutor = mocks.NewMockHelmExecutor(ctrl)
+
+	instance := &ActionTriggeredHandler{Handler: mockedBaseHandler, configChanger: mocks.NewMockIConfigurationChanger(ctrl)}
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ActionTriggeredEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+		Action:    keptnv2.ActionInfo{Action: ActionRollback, Value: "previous"},
+	})
+	instance.HandleEvent(ce)
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	finishedEventData := ReleaseActionFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.StatusSucceeded, finishedEventData.Status)
+	assert.Equal(t, keptnv2.ResultFailed, finishedEventData.Result)
+	assert.Nil(t, finishedEventData.Release)
+}
+
+func TestHandleActionTriggeredEvent_Restart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+
+	instance := &ActionTriggeredHandler{Handler: mockedBaseHandler, configChanger: mocks.NewMockIConfigurationChanger(ctrl)}
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ActionTriggeredEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+		Action:    keptnv2.ActionInfo{Action: ActionRestart},
+	})
+
+	// the generated release contains the primary deployment of a b/g deployment
+	mockedHelmExecutor.EXPECT().GetManifest("sockshop-production-carts-generated", "sockshop-production").Return(helm.GeneratedPrimaryDeployment, nil)
+	mockedHelmExecutor.EXPECT().RestartRelease("sockshop-production-carts-generated", "sockshop-production").Return(2, nil)
+
+	instance.HandleEvent(ce)
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	finishedEventData := ReleaseActionFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.ResultPass, finishedEventData.Result)
+	assert.Equal(t, &ReleaseRevision{Name: "sockshop-production-carts-generated", Revision: 2}, finishedEventData.Release)
+}
+
+func TestHandleActionTriggeredEvent_RestartFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+
+	instance := &ActionTriggeredHandler{Handler: mockedBaseHandler, configChanger: mocks.NewMockIConfigurationChanger(ctrl)}
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.ActionTriggeredEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"},
+		Action:    keptnv2.ActionInfo{Action: ActionRestart},
+	})
+
+	mockedHelmExecutor.EXPECT().GetManifest(gomock.Any(), gomock.Any()).Return("", errors.New("release: not found"))
+	mockedHelmExecutor.EXPECT().RestartRelease("sockshop-production-carts", "sockshop-production").Return(0, errors.New("oops"))
+
+	instance.HandleEvent(ce)
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	finishedEventData := ReleaseActionFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.StatusErrored, finishedEventData.Status)
+	assert.Equal(t, "oops", finishedEventData.Message)
+}
+
+func Test_getRevision(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{value: nil, want: 0},
+		{value: "", want: 0},
+		{value: float64(2), want: 2},
+		{value: "2", want: 2},
+		{value: float64(1.5), wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "two", wantErr: true},
+		{value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getRevision(tt.value)
+		assert.Equal(t, tt.wantErr, err != nil, "value %v", tt.value)
+		assert.Equal(t, tt.want, got, "value %v", tt.value)
+	}
+}
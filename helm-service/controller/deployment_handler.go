@@ -11,6 +11,7 @@ import (
 	"github.com/keptn/keptn/helm-service/pkg/configurationchanger"
 	"github.com/keptn/keptn/helm-service/pkg/helm"
 	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
 	"helm.sh/helm/v3/pkg/chart"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -22,18 +23,21 @@ type DeploymentHandler struct {
 	mesh                  mesh.Mesh
 	generatedChartHandler helm.ChartGenerator
 	onboarder             Onboarder
+	policyProvider        validation.PolicyProvider
 }
 
 // NewDeploymentHandler creates a new DeploymentHandler
 func NewDeploymentHandler(keptnHandler Handler,
 	mesh mesh.Mesh,
 	onboarder Onboarder,
-	chartGenerator helm.ChartGenerator) *DeploymentHandler {
+	chartGenerator helm.ChartGenerator,
+	policyProvider validation.PolicyProvider) *DeploymentHandler {
 	return &DeploymentHandler{
 		Handler:               keptnHandler,
 		mesh:                  mesh,
 		onboarder:             onboarder,
 		generatedChartHandler: chartGenerator,
+		policyProvider:        policyProvider,
 	}
 }
 
@@ -93,6 +97,20 @@ func (h *DeploymentHandler) HandleEvent(ce cloudevents.Event) {
 		return
 	}
 
+	// Validate the rendered user chart before touching the cluster
+	violations, err := h.validateChart(userChart, e.EventData, deploymentStrategy, commitID)
+	if err != nil {
+		h.handleError(ce.ID(), err, keptnv2.DeploymentTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		return
+	}
+	if len(violations) > 0 {
+		h.getKeptnHandler().Logger.Info(fmt.Sprintf("Chart of service %s in stage %s of project %s violates the validation policy", e.Service, e.Stage, e.Project))
+		if err := h.sendEvent(ce.ID(), keptnv2.GetFinishedEventType(keptnv2.DeploymentTaskName), h.getFinishedEventDataForViolations(e.EventData, violations)); err != nil {
+			h.handleError(ce.ID(), err, keptnv2.DeploymentTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		}
+		return
+	}
+
 	// Upgrade user chart
 	if err := h.upgradeChart(userChart, e.EventData, deploymentStrategy); err != nil {
 		h.handleError(ce.ID(), err, keptnv2.DeploymentTaskName, h.getFinishedEventDataForError(e.EventData, err))
@@ -118,6 +136,20 @@ func (h *DeploymentHandler) HandleEvent(ce cloudevents.Event) {
 	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Deployment finished for service %s in stage %s of project %s", e.Service, e.Stage, e.Project))
 }
 
+// validateChart renders the user chart and returns the violations of the validation policy
+func (h *DeploymentHandler) validateChart(userChart *chart.Chart, e keptnv2.EventData, deploymentStrategy keptnevents.DeploymentStrategy,
+	commitID string) ([]validation.Violation, error) {
+	policy, err := getValidationPolicy(h.policyProvider, e, commitID)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	manifest, err := h.renderChart(userChart, e, deploymentStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Validate(manifest), nil
+}
+
 func (h *DeploymentHandler) upgradeGeneratedChart(deploymentStrategy keptnevents.DeploymentStrategy, e keptnv2.DeploymentTriggeredEventData, commitID string) error {
 
 	genChart, err := h.catchupGeneratedChartOnboarding(deploymentStrategy, e.EventData, commitID)
@@ -271,6 +303,16 @@ func (h *DeploymentHandler) getFinishedEventDataForError(eventData keptnv2.Event
 	}
 }
 
+func (h *DeploymentHandler) getFinishedEventDataForViolations(eventData keptnv2.EventData, violations []validation.Violation) keptnv2.DeploymentFinishedEventData {
+
+	eventData.Status = keptnv2.StatusSucceeded
+	eventData.Result = keptnv2.ResultFailed
+	eventData.Message = getViolationsMessage(violations)
+	return keptnv2.DeploymentFinishedEventData{
+		EventData: eventData,
+	}
+}
+
 func (h *DeploymentHandler) getFinishedEventDataForNoDeployment(eventData keptnv2.EventData) keptnv2.DeploymentFinishedEventData {
 
 	eventData.Status = keptnv2.StatusSucceeded
@@ -8,6 +8,7 @@ import (
 	keptn "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -399,6 +400,64 @@ func TestHandleEventWithoutResources(t *testing.T) {
 	assert.Equal(t, expectedDeploymentFinishedEvent, mockedBaseHandler.handledErrorEvents[0])
 }
 
+func TestHandleEventWithViolatedValidationPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+	mockedPolicyProvider := mocks.NewMockPolicyProvider(ctrl)
+
+	deploymentHandler := DeploymentHandler{
+		Handler:               mockedBaseHandler,
+		mesh:                  mocks.NewMockMesh(ctrl),
+		generatedChartHandler: mocks.NewMockChartGenerator(ctrl),
+		onboarder:             mocks.NewMockOnboarder(ctrl),
+		policyProvider:        mockedPolicyProvider,
+	}
+
+	deploymentTriggeredEventData := keptnv2.DeploymentTriggeredEventData{
+		EventData: keptnv2.EventData{
+			Project: "my-project",
+			Stage:   "my-stage",
+			Service: "my-service",
+		},
+		ConfigurationChange: keptnv2.ConfigurationChange{},
+		Deployment: keptnv2.DeploymentTriggeredData{
+			DeploymentStrategy: keptn.Direct.String(),
+		},
+	}
+	mockedPolicyProvider.EXPECT().GetPolicy(deploymentTriggeredEventData.EventData, "USER_CHART_GIT_ID").Return(&validation.Policy{RequireResourceLimits: true}, nil)
+	mockedHelmExecutor.EXPECT().RenderChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dryRunCurrentManifest, nil)
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, deploymentTriggeredEventData)
+	ce.SetExtension("gitcommitid", "USER_CHART_GIT_ID")
+	deploymentHandler.HandleEvent(ce)
+
+	expectedDeploymentFinishedEvent := cloudevents.NewEvent()
+	expectedDeploymentFinishedEvent.SetType("sh.keptn.event.deployment.finished")
+	expectedDeploymentFinishedEvent.SetSource("helm-service")
+	expectedDeploymentFinishedEvent.SetDataContentType(cloudevents.ApplicationJSON)
+	expectedDeploymentFinishedEvent.SetExtension("triggeredid", "")
+	expectedDeploymentFinishedEvent.SetExtension("shkeptncontext", "")
+	expectedDeploymentFinishedEvent.SetData(cloudevents.ApplicationJSON, keptnv2.DeploymentFinishedEventData{
+		EventData: keptnv2.EventData{
+			Project: "my-project",
+			Stage:   "my-stage",
+			Service: "my-service",
+			Status:  keptnv2.StatusSucceeded,
+			Result:  keptnv2.ResultFailed,
+			Message: "The chart violates the validation policy: Deployment carts, container carts: missing cpu limit; " +
+				"Deployment carts, container carts: missing memory limit",
+		},
+	})
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	assert.Equal(t, expectedDeploymentFinishedEvent, mockedBaseHandler.sentCloudEvents[1])
+	assert.Empty(t, mockedBaseHandler.upgradeChartInvocations)
+}
+
 func TestHandleUnparsableDeploymentEvent(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnevents "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/pkg/configurationchanger"
+	"github.com/keptn/keptn/helm-service/pkg/helm"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// DryRunTaskName is the task which previews the changes of a deployment without applying them
+const DryRunTaskName = "dryrun"
+
+// DryRunFinishedEventData is the data of a sh.keptn.event.dryrun.finished event
+type DryRunFinishedEventData struct {
+	keptnv2.EventData
+	DryRun DryRunData `json:"dryrun"`
+}
+
+// DryRunData contains the changes of the release of the user chart and the violations of the validation policy
+type DryRunData struct {
+	Diff       helm.ManifestDiff      `json:"diff"`
+	Violations []validation.Violation `json:"violations"`
+}
+
+// DryRunHandler renders the user chart with the values of a deployment, compares it with the current release and
+// validates it against the validation policy. The cluster is not changed
+type DryRunHandler struct {
+	Handler
+	policyProvider validation.PolicyProvider
+}
+
+// NewDryRunHandler creates a new DryRunHandler
+func NewDryRunHandler(keptnHandler Handler, policyProvider validation.PolicyProvider) *DryRunHandler {
+	return &DryRunHandler{
+		Handler:        keptnHandler,
+		policyProvider: policyProvider,
+	}
+}
+
+// HandleEvent handles dryrun.triggered events, which have the same data as deployment.triggered events
+func (h *DryRunHandler) HandleEvent(ce cloudevents.Event) {
+	e := keptnv2.DeploymentTriggeredEventData{}
+	if err := ce.DataAs(&e); err != nil {
+		err = fmt.Errorf("Failed to unmarshal data: unable to convert json data from cloudEvent to dryrun event")
+		h.handleError(ce.ID(), err, DryRunTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		return
+	}
+
+	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Starting dry run for service %s in stage %s of project %s", e.Service, e.Stage, e.Project))
+	if err := h.sendEvent(ce.ID(), keptnv2.GetStartedEventType(DryRunTaskName), h.getStartedEventData(e.EventData)); err != nil {
+		h.handleError(ce.ID(), err, DryRunTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		return
+	}
+
+	data, err := h.dryRun(e, retrieveCommit(ce))
+	if err != nil {
+		h.handleError(ce.ID(), err, DryRunTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		return
+	}
+	if err := h.sendEvent(ce.ID(), keptnv2.GetFinishedEventType(DryRunTaskName), data); err != nil {
+		h.handleError(ce.ID(), err, DryRunTaskName, h.getFinishedEventDataForError(e.EventData, err))
+		return
+	}
+	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Finished dry run for service %s in stage %s of project %s", e.Service, e.Stage, e.Project))
+}
+
+func (h *DryRunHandler) dryRun(e keptnv2.DeploymentTriggeredEventData, commitID string) (*DryRunFinishedEventData, error) {
+	userChart, commitID, err := h.getUserChart(e.EventData, commitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart: %w", err)
+	}
+	if len(e.ConfigurationChange.Values) > 0 {
+		// the values are only changed in memory, such that the configuration is not changed
+		if userChart.Values == nil {
+			userChart.Values = map[string]interface{}{}
+		}
+		if err := configurationchanger.NewValuesManipulator(e.ConfigurationChange.Values).Manipulate(userChart); err != nil {
+			return nil, fmt.Errorf("failed to update values: %w", err)
+		}
+	}
+
+	deploymentStrategy := keptnevents.Direct
+	if e.Deployment.DeploymentStrategy != "" {
+		deploymentStrategy, err = keptnevents.GetDeploymentStrategy(e.Deployment.DeploymentStrategy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targetManifest, err := h.renderChart(userChart, e.EventData, deploymentStrategy)
+	if err != nil {
+		return nil, err
+	}
+	currentManifest, err := h.getHelmExecutor().GetManifest(helm.GetReleaseName(e.Project, e.Stage, e.Service, false), e.Project+"-"+e.Stage)
+	if err != nil {
+		if !strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return nil, err
+		}
+		// the service has not been deployed yet
+		currentManifest = ""
+	}
+	policy, err := getValidationPolicy(h.policyProvider, e.EventData, commitID)
+	if err != nil {
+		return nil, err
+	}
+	violations := []validation.Violation{}
+	if policy != nil {
+		violations = policy.Validate(targetManifest)
+	}
+
+	data := &DryRunFinishedEventData{
+		EventData: e.EventData,
+		DryRun: DryRunData{
+			Diff:       helm.DiffManifests(currentManifest, targetManifest),
+			Violations: violations,
+		},
+	}
+	data.Status = keptnv2.StatusSucceeded
+	if len(violations) > 0 {
+		data.Result = keptnv2.ResultFailed
+		data.Message = getViolationsMessage(violations)
+	} else {
+		data.Result = keptnv2.ResultPass
+		data.Message = fmt.Sprintf("The deployment changes %d resources", len(data.DryRun.Diff.Changes))
+	}
+	return data, nil
+}
+
+func (h *DryRunHandler) getStartedEventData(inEventData keptnv2.EventData) keptnv2.EventData {
+	inEventData.Status = keptnv2.StatusSucceeded
+	inEventData.Result = ""
+	inEventData.Message = ""
+	return inEventData
+}
+
+func (h *DryRunHandler) getFinishedEventDataForError(eventData keptnv2.EventData, err error) DryRunFinishedEventData {
+	eventData.Status = keptnv2.StatusErrored
+	eventData.Result = keptnv2.ResultFailed
+	eventData.Message = err.Error()
+	return DryRunFinishedEventData{EventData: eventData}
+}
+
+// getValidationPolicy returns the validation policy of the service, or nil if the service has no policy
+func getValidationPolicy(policyProvider validation.PolicyProvider, e keptnv2.EventData, commitID string) (*validation.Policy, error) {
+	if policyProvider == nil {
+		return nil, nil
+	}
+	policy, err := policyProvider.GetPolicy(e, commitID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load validation policy: %w", err)
+	}
+	return policy, nil
+}
+
+func getViolationsMessage(violations []validation.Violation) string {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+	return "The chart violates the validation policy: " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/golang/mock/gomock"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/helm"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dryRunCurrentManifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  template:
+    spec:
+      containers:
+      - name: carts
+        image: docker.io/keptnexamples/carts:0.8.1
+`
+
+const dryRunTargetManifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  template:
+    spec:
+      containers:
+      - name: carts
+        image: docker.io/keptnexamples/carts:latest
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: carts
+`
+
+func getDryRunTriggeredEvent() cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, keptnv2.DeploymentTriggeredEventData{
+		EventData: keptnv2.EventData{
+			Project: "sockshop",
+			Stage:   "production",
+			Service: "carts",
+		},
+		ConfigurationChange: keptnv2.ConfigurationChange{
+			Values: map[string]interface{}{"image": "docker.io/keptnexamples/carts:latest"},
+		},
+		Deployment: keptnv2.DeploymentTriggeredData{
+			DeploymentStrategy: keptn.Direct.String(),
+		},
+	})
+	ce.SetExtension("gitcommitid", "USER_CHART_GIT_ID")
+	return ce
+}
+
+func TestHandleDryRunTriggeredEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+	mockedPolicyProvider := mocks.NewMockPolicyProvider(ctrl)
+
+	mockedHelmExecutor.EXPECT().RenderChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dryRunTargetManifest, nil)
+	mockedHelmExecutor.EXPECT().GetManifest("sockshop-production-carts", "sockshop-production").Return(dryRunCurrentManifest, nil)
+	mockedPolicyProvider.EXPECT().GetPolicy(gomock.Any(), "USER_CHART_GIT_ID").Return(nil, nil)
+
+	NewDryRunHandler(mockedBaseHandler, mockedPolicyProvider).HandleEvent(getDryRunTriggeredEvent())
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	assert.Equal(t, "sh.keptn.event.dryrun.started", mockedBaseHandler.sentCloudEvents[0].Type())
+	assert.Equal(t, "sh.keptn.event.dryrun.finished", mockedBaseHandler.sentCloudEvents[1].Type())
+	assert.Empty(t, mockedBaseHandler.upgradeChartInvocations)
+
+	finishedEventData := DryRunFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.StatusSucceeded, finishedEventData.Status)
+	assert.Equal(t, keptnv2.ResultPass, finishedEventData.Result)
+	assert.Equal(t, "The deployment changes 2 resources", finishedEventData.Message)
+	require.Equal(t, 2, len(finishedEventData.DryRun.Diff.Changes))
+	assert.Equal(t, helm.ResourceChanged, finishedEventData.DryRun.Diff.Changes[0].Action)
+	assert.Equal(t, helm.ResourceAdded, finishedEventData.DryRun.Diff.Changes[1].Action)
+	assert.Empty(t, finishedEventData.DryRun.Violations)
+}
+
+func TestHandleDryRunTriggeredEvent_WithViolations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+	mockedPolicyProvider := mocks.NewMockPolicyProvider(ctrl)
+
+	mockedHelmExecutor.EXPECT().RenderChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(dryRunTargetManifest, nil)
+	// the service has not been deployed yet
+	mockedHelmExecutor.EXPECT().GetManifest(gomock.Any(), gomock.Any()).Return("", errors.New("release: not found"))
+	mockedPolicyProvider.EXPECT().GetPolicy(gomock.Any(), gomock.Any()).Return(&validation.Policy{ForbiddenImageTags: []string{"latest"}}, nil)
+
+	NewDryRunHandler(mockedBaseHandler, mockedPolicyProvider).HandleEvent(getDryRunTriggeredEvent())
+
+	require.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	finishedEventData := DryRunFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finishedEventData))
+	assert.Equal(t, keptnv2.StatusSucceeded, finishedEventData.Status)
+	assert.Equal(t, keptnv2.ResultFailed, finishedEventData.Result)
+	assert.Equal(t, "The chart violates the validation policy: Deployment carts, container carts: image docker.io/keptnexamples/carts:latest uses the forbidden tag latest", finishedEventData.Message)
+	require.Equal(t, 2, len(finishedEventData.DryRun.Diff.Changes))
+	assert.Equal(t, helm.ResourceAdded, finishedEventData.DryRun.Diff.Changes[0].Action)
+	require.Equal(t, 1, len(finishedEventData.DryRun.Violations))
+}
+
+func TestHandleDryRunTriggeredEvent_RenderingFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedHelmExecutor := mocks.NewMockHelmExecutor(ctrl)
+	mockedBaseHandler.helmExecutor = mockedHelmExecutor
+
+	mockedHelmExecutor.EXPECT().RenderChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", errors.New("invalid template"))
+
+	NewDryRunHandler(mockedBaseHandler, nil).HandleEvent(getDryRunTriggeredEvent())
+
+	require.Equal(t, 1, len(mockedBaseHandler.handledErrorEvents))
+	finishedEventData := mockedBaseHandler.handledErrorEvents[0].(DryRunFinishedEventData)
+	assert.Equal(t, keptnv2.StatusErrored, finishedEventData.Status)
+	assert.Equal(t, "invalid template", finishedEventData.Message)
+}
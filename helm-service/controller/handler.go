@@ -23,5 +23,6 @@ type Handler interface {
 		strategy keptnevents.DeploymentStrategy) error
 	upgradeChartWithReplicas(ch *chart.Chart, event keptnv2.EventData,
 		strategy keptnevents.DeploymentStrategy, replicas int) error
+	renderChart(ch *chart.Chart, event keptnv2.EventData, strategy keptnevents.DeploymentStrategy) (string, error)
 	getUserManagedEndpoints(event keptnv2.EventData, commitID string) (*keptnv2.Endpoints, error)
 }
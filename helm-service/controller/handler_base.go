@@ -169,6 +169,16 @@ func (h *HandlerBase) upgradeChartWithReplicas(ch *chart.Chart, event keptnv2.Ev
 			getDeploymentName(strategy, generated)), replicas))
 }
 
+// renderChart renders the chart with the values it would be upgraded with
+func (h *HandlerBase) renderChart(ch *chart.Chart, event keptnv2.EventData, strategy keptnevents.DeploymentStrategy) (string, error) {
+	generated := strings.HasSuffix(ch.Name(), "-generated")
+	releasename := helm.GetReleaseName(event.Project, event.Stage, event.Service, generated)
+	namespace := event.Project + "-" + event.Stage
+
+	return h.helmExecutor.RenderChart(ch, releasename, namespace,
+		getKeptnValues(event.Project, event.Stage, event.Service, getDeploymentName(strategy, generated)))
+}
+
 func getKeptnValues(project, stage, service, deploymentName string) map[string]interface{} {
 	return map[string]interface{}{
 		"keptn": map[string]interface{}{
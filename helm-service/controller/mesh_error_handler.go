@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	logger "github.com/sirupsen/logrus"
+)
+
+// MeshErrorHandler handles the .triggered events of the tasks changing the traffic routing of a service if the mesh of
+// the project could not be created, e.g. because its mesh.yaml is invalid. The task is finished with an error instead of
+// being performed with a different mesh, so that the sequence does not wait for the task until it times out
+type MeshErrorHandler struct {
+	Handler
+	taskName string
+	err      error
+}
+
+// NewMeshErrorHandler returns a handler finishing the given task with the error which occurred while creating the mesh
+func NewMeshErrorHandler(keptnHandler Handler, taskName string, err error) *MeshErrorHandler {
+	return &MeshErrorHandler{
+		Handler:  keptnHandler,
+		taskName: taskName,
+		err:      err,
+	}
+}
+
+// HandleEvent sends a .started and an errored .finished event for the task of the given .triggered event
+func (h *MeshErrorHandler) HandleEvent(ce cloudevents.Event) {
+	e := keptnv2.EventData{}
+	if err := ce.DataAs(&e); err != nil {
+		logger.WithError(err).Error("could not decode event data")
+		return
+	}
+	if h.taskName == keptnv2.ActionTaskName {
+		actionTriggeredEvent := keptnv2.ActionTriggeredEventData{}
+		if err := ce.DataAs(&actionTriggeredEvent); err == nil && !isHandledAction(actionTriggeredEvent.Action.Action) {
+			// the action is handled by another service, which does not depend on the mesh
+			return
+		}
+	}
+
+	started := e
+	started.Status = keptnv2.StatusSucceeded
+	started.Result = ""
+	started.Message = ""
+	if err := h.sendEvent(ce.ID(), keptnv2.GetStartedEventType(h.taskName), started); err != nil {
+		logger.WithError(err).Errorf("could not send %s.started event", h.taskName)
+	}
+
+	err := fmt.Errorf("could not create the mesh of project %s: %w", e.Project, h.err)
+	finished := e
+	finished.Status = keptnv2.StatusErrored
+	finished.Result = keptnv2.ResultFailed
+	finished.Message = err.Error()
+	h.handleError(ce.ID(), err, h.taskName, finished)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeshErrorHandler_HandleEvent(t *testing.T) {
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	instance := NewMeshErrorHandler(mockedBaseHandler, v0_2_0.ReleaseTaskName, errors.New("unsupported mesh: consul"))
+
+	eventData := v0_2_0.EventData{
+		Project: "my-project",
+		Stage:   "my-stage",
+		Service: "my-service",
+	}
+	ce := cloudevents.NewEvent()
+	ce.SetID("my-triggered-id")
+	_ = ce.SetData(cloudevents.ApplicationJSON, v0_2_0.ReleaseTriggeredEventData{EventData: eventData})
+
+	instance.HandleEvent(ce)
+
+	assert.Equal(t, 2, len(mockedBaseHandler.sentCloudEvents))
+	assert.Equal(t, "sh.keptn.event.release.started", mockedBaseHandler.sentCloudEvents[0].Type())
+	assert.Equal(t, "sh.keptn.event.release.finished", mockedBaseHandler.sentCloudEvents[1].Type())
+	assert.Equal(t, "my-triggered-id", mockedBaseHandler.sentCloudEvents[1].Extensions()["triggeredid"])
+
+	finished := v0_2_0.EventData{}
+	assert.Nil(t, mockedBaseHandler.sentCloudEvents[1].DataAs(&finished))
+	assert.Equal(t, v0_2_0.StatusErrored, finished.Status)
+	assert.Equal(t, v0_2_0.ResultFailed, finished.Result)
+	assert.Equal(t, "could not create the mesh of project my-project: unsupported mesh: consul", finished.Message)
+}
+
+func TestMeshErrorHandler_IgnoresUnhandledAction(t *testing.T) {
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	instance := NewMeshErrorHandler(mockedBaseHandler, v0_2_0.ActionTaskName, errors.New("unsupported mesh: consul"))
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, v0_2_0.ActionTriggeredEventData{
+		EventData: v0_2_0.EventData{Project: "my-project", Stage: "my-stage", Service: "my-service"},
+		Action:    v0_2_0.ActionInfo{Action: "toggle-feature"},
+	})
+
+	instance.HandleEvent(ce)
+
+	assert.Equal(t, 0, len(mockedBaseHandler.sentCloudEvents))
+}
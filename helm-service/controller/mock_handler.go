@@ -172,3 +172,7 @@ func (h *MockedHandler) upgradeChartWithReplicas(ch *chart.Chart, event keptnv2.
 
 	return nil
 }
+
+func (h *MockedHandler) renderChart(ch *chart.Chart, event keptnv2.EventData, strategy keptnevents.DeploymentStrategy) (string, error) {
+	return h.helmExecutor.RenderChart(ch, "", "", nil)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	keptnevents "github.com/keptn/go-utils/pkg/lib"
@@ -8,8 +9,10 @@ import (
 	"github.com/keptn/keptn/helm-service/pkg/configurationchanger"
 	"github.com/keptn/keptn/helm-service/pkg/helm"
 	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	"github.com/keptn/keptn/helm-service/pkg/progressive"
 	keptntypes "github.com/keptn/keptn/helm-service/pkg/types"
 	keptnutils "github.com/keptn/kubernetes-utils/pkg"
+	"time"
 )
 
 // ReleaseHandler is a handler for releasing a service
@@ -20,6 +23,20 @@ type ReleaseHandler struct {
 	configurationChanger  configurationchanger.IConfigurationChanger
 	chartStorer           keptntypes.IChartStorer
 	chartPackager         keptntypes.IChartPackager
+	progressiveConfig     progressive.ConfigProvider
+	healthChecker         progressive.HealthChecker
+	signals               *progressive.Signals
+	states                progressive.StateStore
+}
+
+// canaryRolledBackError is returned if a progressive release has rolled back the canary
+type canaryRolledBackError struct {
+	progress progressive.Progress
+	reason   error
+}
+
+func (e *canaryRolledBackError) Error() string {
+	return fmt.Sprintf("Rolled back canary in step %d of %d (%d%% traffic): %s", e.progress.Step, e.progress.Steps, e.progress.Weight, e.reason.Error())
 }
 
 // NewReleaseHandler creates a ReleaseHandler
@@ -28,7 +45,11 @@ func NewReleaseHandler(keptnHandler Handler,
 	configurationChanger configurationchanger.IConfigurationChanger,
 	chartGenerator helm.ChartGenerator,
 	chartStorer keptntypes.IChartStorer,
-	chartPackager keptntypes.IChartPackager) *ReleaseHandler {
+	chartPackager keptntypes.IChartPackager,
+	progressiveConfig progressive.ConfigProvider,
+	healthChecker progressive.HealthChecker,
+	signals *progressive.Signals,
+	states progressive.StateStore) *ReleaseHandler {
 	//generatedChartHandler := helm.NewGeneratedChartGenerator(mesh, keptnHandler.Logger)
 	return &ReleaseHandler{
 		Handler:               keptnHandler,
@@ -37,6 +58,10 @@ func NewReleaseHandler(keptnHandler Handler,
 		configurationChanger:  configurationChanger,
 		chartStorer:           chartStorer,
 		chartPackager:         chartPackager,
+		progressiveConfig:     progressiveConfig,
+		healthChecker:         healthChecker,
+		signals:               signals,
+		states:                states,
 	}
 }
 
@@ -68,13 +93,22 @@ func (h *ReleaseHandler) HandleEvent(ce cloudevents.Event) {
 		// Only in case of a duplicate deployment strategy, the user-chart has to be promoted/aborted and
 		// a traffic switch is necessary
 		if e.Result == keptnv2.ResultPass || e.Result == keptnv2.ResultWarning {
-			h.getKeptnHandler().Logger.Info(fmt.Sprintf("Promote service %s in stage %s of project %s",
-				e.Service, e.Stage, e.Project))
-			commitID, err = h.promoteDeployment(e.EventData, commitID)
+			progressiveConfig, err := h.getProgressiveConfig(e.EventData, commitID)
 			if err != nil {
 				h.handleError(ce.ID(), err, keptnv2.ReleaseTaskName, h.getFinishedEventDataForError(e.EventData, err))
 				return
 			}
+			h.getKeptnHandler().Logger.Info(fmt.Sprintf("Promote service %s in stage %s of project %s",
+				e.Service, e.Stage, e.Project))
+			if progressiveConfig != nil {
+				state := progressive.State{Event: ce, CommitID: commitID, Config: *progressiveConfig}
+				commitID, err = h.promoteProgressively(state, e.EventData)
+			} else {
+				commitID, err = h.promoteDeployment(e.EventData, commitID)
+			}
+			if h.handlePromotionError(ce.ID(), e.EventData, err) {
+				return
+			}
 		}
 	} else {
 		h.getKeptnHandler().Logger.Info(fmt.Sprintf(
@@ -82,60 +116,219 @@ func (h *ReleaseHandler) HandleEvent(ce cloudevents.Event) {
 			e.Service, e.Stage, e.Project))
 	}
 
-	// Send finished event
-	data := h.getFinishedEventData(e.EventData, keptnv2.StatusSucceeded, e.Result, "Finished release")
-	if err := h.sendEvent(ce.ID(), keptnv2.GetFinishedEventType(keptnv2.ReleaseTaskName), data); err != nil {
-		h.handleError(ce.ID(), err, keptnv2.ReleaseTaskName, h.getFinishedEventDataForError(e.EventData, err))
+	h.sendReleaseFinishedEvent(ce.ID(), e.EventData)
+}
+
+// ResumeProgressiveRelease continues a progressive release which has been interrupted by a restart of the helm-service
+func (h *ReleaseHandler) ResumeProgressiveRelease(state progressive.State) {
+	e := keptnv2.ReleaseTriggeredEventData{}
+	if err := state.Event.DataAs(&e); err != nil {
+		h.getKeptnHandler().Logger.Error(fmt.Sprintf("could not decode interrupted progressive release %s: %s", state.Event.ID(), err.Error()))
+		h.deleteState(state)
+		return
+	}
+	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Resuming progressive release of service %s in stage %s of project %s after step %d of %d",
+		e.Service, e.Stage, e.Project, state.Step, len(state.Config.Steps)))
+	if _, err := h.promoteProgressively(state, e.EventData); h.handlePromotionError(state.Event.ID(), e.EventData, err) {
+		return
+	}
+	h.sendReleaseFinishedEvent(state.Event.ID(), e.EventData)
+}
+
+// FailProgressiveRelease finishes a progressive release which cannot be resumed after a restart of the helm-service
+// with an error, so that its sequence does not wait forever
+func (h *ReleaseHandler) FailProgressiveRelease(state progressive.State, reason error) {
+	e := keptnv2.ReleaseTriggeredEventData{}
+	_ = state.Event.DataAs(&e)
+	err := fmt.Errorf("could not resume progressive release after a restart of the helm-service: %w", reason)
+	h.handleError(state.Event.ID(), err, keptnv2.ReleaseTaskName, h.getFinishedEventDataForError(e.EventData, err))
+	h.deleteState(state)
+}
+
+// handlePromotionError sends the finished event of a failed or rolled back promotion, and returns whether the promotion failed
+func (h *ReleaseHandler) handlePromotionError(triggeredID string, e keptnv2.EventData, err error) bool {
+	var rolledBackErr *canaryRolledBackError
+	if errors.As(err, &rolledBackErr) {
+		h.getKeptnHandler().Logger.Info(rolledBackErr.Error())
+		data := h.getFinishedEventData(e, keptnv2.StatusSucceeded, keptnv2.ResultFailed, rolledBackErr.Error())
+		if err := h.sendEvent(triggeredID, keptnv2.GetFinishedEventType(keptnv2.ReleaseTaskName), data); err != nil {
+			h.getKeptnHandler().Logger.Error(err.Error())
+		}
+		return true
+	} else if err != nil {
+		h.handleError(triggeredID, err, keptnv2.ReleaseTaskName, h.getFinishedEventDataForError(e, err))
+		return true
+	}
+	return false
+}
+
+func (h *ReleaseHandler) sendReleaseFinishedEvent(triggeredID string, e keptnv2.EventData) {
+	data := h.getFinishedEventData(e, keptnv2.StatusSucceeded, e.Result, "Finished release")
+	if err := h.sendEvent(triggeredID, keptnv2.GetFinishedEventType(keptnv2.ReleaseTaskName), data); err != nil {
+		h.handleError(triggeredID, err, keptnv2.ReleaseTaskName, h.getFinishedEventDataForError(e, err))
 		return
 	}
 	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Finished release for service %s in stage %s and project %s", e.Service, e.Stage, e.Project))
 }
 
 func (h *ReleaseHandler) promoteDeployment(e keptnv2.EventData, commitID string) (string, error) {
+	// Switch weight to 100% canary, 0% primary
+	newCommit, err := h.shiftTraffic(e, commitID, 100)
+	if err != nil {
+		return "", err
+	}
+	return h.completePromotion(e, newCommit)
+}
 
-	//configChanger := configurationchanger.NewConfigurationChanger(h.getConfigServiceURL())
+// promoteProgressively shifts the traffic to the canary in the steps of the config, starting after the steps already
+// applied according to the state. Between the steps, it waits for the interval or a signal and checks the health of
+// the canary. If a step fails, the canary is rolled back. The state is persisted after each step, so that the release
+// can be resumed after a restart of the helm-service
+func (h *ReleaseHandler) promoteProgressively(state progressive.State, e keptnv2.EventData) (string, error) {
+	defer h.deleteState(state)
+	var signals <-chan progressive.SignalAction
+	if h.signals != nil {
+		ch, unsubscribe := h.signals.Subscribe(h.getKeptnHandler().KeptnContext)
+		defer unsubscribe()
+		signals = ch
+	}
 
-	// Switch weight to 100% canary, 0% primary
-	canaryWeightTo100Updater := configurationchanger.NewCanaryWeightManipulator(h.mesh, 100)
+	config := state.Config
+	triggeredID := state.Event.ID()
+	h.saveState(state)
+	for state.Step < len(config.Steps) {
+		weight := config.Steps[state.Step]
+		progress := progressive.Progress{Step: state.Step + 1, Steps: len(config.Steps), Weight: weight}
+		if state.WaitingSince.IsZero() {
+			newCommit, err := h.shiftTraffic(e, state.CommitID, weight)
+			if err != nil {
+				return "", err
+			}
+			h.sendStatusChangedEvent(triggeredID, e, progress,
+				fmt.Sprintf("Step %d of %d: routed %d%% of the traffic to the canary", progress.Step, progress.Steps, weight))
+			if weight == 100 {
+				return h.completePromotion(e, newCommit)
+			}
+			state.CommitID = newCommit
+			state.WaitingSince = time.Now()
+			h.saveState(state)
+		}
+
+		err := config.Await(signals, state.WaitingSince)
+		if err == nil && config.HealthCheck != nil && h.healthChecker != nil {
+			err = h.healthChecker.Check(*config.HealthCheck)
+		}
+		if err != nil {
+			return "", h.rollbackCanary(triggeredID, e, state.CommitID, progress, err)
+		}
+		state.Step++
+		state.WaitingSince = time.Time{}
+		h.saveState(state)
+	}
+	return h.completePromotion(e, state.CommitID)
+}
+
+func (h *ReleaseHandler) getStates() progressive.StateStore {
+	if h.states == nil {
+		return progressive.NewNoopStateStore()
+	}
+	return h.states
+}
+
+func (h *ReleaseHandler) saveState(state progressive.State) {
+	if err := h.getStates().Save(state); err != nil {
+		h.getKeptnHandler().Logger.Error(fmt.Sprintf("could not persist the state of progressive release %s: %s", state.Event.ID(), err.Error()))
+	}
+}
+
+func (h *ReleaseHandler) deleteState(state progressive.State) {
+	if err := h.getStates().Delete(state.Event.ID()); err != nil {
+		h.getKeptnHandler().Logger.Error(fmt.Sprintf("could not delete the state of progressive release %s: %s", state.Event.ID(), err.Error()))
+	}
+}
+
+// shiftTraffic routes the given percentage of the traffic to the canary and returns the commit of the updated generated chart
+func (h *ReleaseHandler) shiftTraffic(e keptnv2.EventData, commitID string, canaryWeight int32) (string, error) {
+	canaryWeightUpdater := configurationchanger.NewCanaryWeightManipulator(h.mesh, canaryWeight)
 	genChart, _, err := h.getGeneratedChart(e, commitID)
 	if err != nil {
 		return "", err
 	}
-	genChart, newCommit, err := h.configurationChanger.UpdateLoadedChart(genChart, e, true, canaryWeightTo100Updater)
+	genChart, newCommit, err := h.configurationChanger.UpdateLoadedChart(genChart, e, true, canaryWeightUpdater)
 	if err != nil {
 		return "", err
 	}
 	if err := h.upgradeChart(genChart, e, keptnevents.Duplicate); err != nil {
 		return "", err
 	}
+	return newCommit, nil
+}
 
+// completePromotion replaces the primary with the canary, which currently receives all traffic
+func (h *ReleaseHandler) completePromotion(e keptnv2.EventData, commitID string) (string, error) {
 	// Update and apply new generated chart
 	if err := h.updateGeneratedChart(e); err != nil {
 		return "", err
 	}
 	// Switch weight to 0% canary, 100% primary
-	canaryWeightTo0Updater := configurationchanger.NewCanaryWeightManipulator(h.mesh, 0)
-	genChart, _, err = h.getGeneratedChart(e, newCommit)
+	commitID, err := h.shiftTraffic(e, commitID, 0)
 	if err != nil {
 		return "", err
 	}
-	genChart, commitID, err = h.configurationChanger.UpdateLoadedChart(genChart, e, true, canaryWeightTo0Updater)
+
+	// Scale down replicas of user chart
+	userChart, _, err := h.getUserChart(e, commitID)
 	if err != nil {
 		return "", err
 	}
-	if err := h.upgradeChart(genChart, e, keptnevents.Duplicate); err != nil {
+	if err := h.upgradeChartWithReplicas(userChart, e, keptnevents.Duplicate, 0); err != nil {
 		return "", err
 	}
+	return commitID, nil
+}
 
-	// Scale down replicas of user chart
+// rollbackCanary routes all traffic back to the primary and scales down the canary
+func (h *ReleaseHandler) rollbackCanary(triggeredID string, e keptnv2.EventData, commitID string, progress progressive.Progress, reason error) error {
+	h.getKeptnHandler().Logger.Info(fmt.Sprintf("Rolling back canary of service %s in stage %s of project %s: %s",
+		e.Service, e.Stage, e.Project, reason.Error()))
+	commitID, err := h.shiftTraffic(e, commitID, 0)
+	if err != nil {
+		return fmt.Errorf("could not roll back canary after %s: %w", reason.Error(), err)
+	}
 	userChart, _, err := h.getUserChart(e, commitID)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("could not roll back canary after %s: %w", reason.Error(), err)
 	}
 	if err := h.upgradeChartWithReplicas(userChart, e, keptnevents.Duplicate, 0); err != nil {
-		return "", err
+		return fmt.Errorf("could not roll back canary after %s: %w", reason.Error(), err)
+	}
+
+	rolledBackErr := &canaryRolledBackError{progress: progress, reason: reason}
+	progress.Weight = 0
+	progress.RolledBack = true
+	h.sendStatusChangedEvent(triggeredID, e, progress, rolledBackErr.Error())
+	return rolledBackErr
+}
+
+func (h *ReleaseHandler) getProgressiveConfig(e keptnv2.EventData, commitID string) (*progressive.Config, error) {
+	if h.progressiveConfig == nil {
+		return nil, nil
+	}
+	config, err := h.progressiveConfig.GetConfig(e, commitID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load progressive release config: %w", err)
+	}
+	return config, nil
+}
+
+func (h *ReleaseHandler) sendStatusChangedEvent(triggeredID string, e keptnv2.EventData, progress progressive.Progress, message string) {
+	e.Status = keptnv2.StatusSucceeded
+	e.Result = ""
+	e.Message = message
+	data := progressive.StatusChangedEventData{EventData: e, Canary: progress}
+	if err := h.sendEvent(triggeredID, keptnv2.GetStatusChangedEventType(keptnv2.DeploymentTaskName), data); err != nil {
+		h.getKeptnHandler().Logger.Error(fmt.Sprintf("could not send status changed event: %s", err.Error()))
 	}
-	return commitID, nil
 }
 
 func (h *ReleaseHandler) updateGeneratedChart(e keptnv2.EventData) error {
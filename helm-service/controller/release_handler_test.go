@@ -1,14 +1,19 @@
 package controller
 
 import (
+	"errors"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/golang/mock/gomock"
 	keptnevents "github.com/keptn/go-utils/pkg/lib"
 	. "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/progressive"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"k8s.io/client-go/kubernetes/fake"
 	"testing"
+	"time"
 )
 
 func TestHandleReleaseTriggeredEvent_WhenDeploymentStrategyDirect_ThenNoActionRequired(t *testing.T) {
@@ -162,3 +167,268 @@ func TestHandleUnparsableReleaseEvent(t *testing.T) {
 	assert.Equal(t, 1, len(mockedBaseHandler.handledErrorEvents))
 	assert.Equal(t, expectedTriggerEventData, mockedBaseHandler.handledErrorEvents[0])
 }
+
+func TestHandleReleaseTriggeredEvent_Progressive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedMesh := mocks.NewMockMesh(ctrl)
+	mockedChartGenerator := mocks.NewMockChartGenerator(ctrl)
+	mockedConfigurationChanger := mocks.NewMockIConfigurationChanger(ctrl)
+	mockedChartStorer := mocks.NewMockIChartStorer(ctrl)
+	mockedChartPackager := mocks.NewMockIChartPackager(ctrl)
+	mockedConfigProvider := mocks.NewMockConfigProvider(ctrl)
+	mockedHealthChecker := mocks.NewMockHealthChecker(ctrl)
+
+	healthCheck := progressive.HealthCheck{URL: "http://carts/health"}
+	mockedConfigProvider.EXPECT().GetConfig(gomock.Any(), gomock.Any()).Return(&progressive.Config{Steps: []int32{25, 50, 100}, HealthCheck: &healthCheck}, nil)
+	mockedHealthChecker.EXPECT().Check(healthCheck).Return(nil).Times(2)
+	// 25%, 50%, 100% and 0% after replacing the primary
+	mockedConfigurationChanger.EXPECT().UpdateLoadedChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "123-456", nil).Times(4)
+	mockedChartGenerator.EXPECT().GenerateDuplicateChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&chart.Chart{}, nil)
+	mockedChartPackager.EXPECT().Package(gomock.Any()).Return([]byte("chart"), nil)
+	mockedChartStorer.EXPECT().Store(gomock.Any()).Return("", nil)
+
+	instance := &ReleaseHandler{
+		Handler:               mockedBaseHandler,
+		mesh:                  mockedMesh,
+		generatedChartHandler: mockedChartGenerator,
+		configurationChanger:  mockedConfigurationChanger,
+		chartStorer:           mockedChartStorer,
+		chartPackager:         mockedChartPackager,
+		progressiveConfig:     mockedConfigProvider,
+		healthChecker:         mockedHealthChecker,
+		signals:               progressive.NewSignals(),
+	}
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, ReleaseTriggeredEventData{
+		EventData: EventData{Project: "sockshop", Stage: "production", Service: "carts", Result: ResultPass},
+		Deployment: DeploymentFinishedData{
+			DeploymentStrategy: keptnevents.Duplicate.String(),
+		},
+	})
+
+	instance.HandleEvent(ce)
+
+	require.Equal(t, 5, len(mockedBaseHandler.sentCloudEvents))
+	require.Equal(t, 0, len(mockedBaseHandler.handledErrorEvents))
+	for i, weight := range []int32{25, 50, 100} {
+		statusChangedEvent := mockedBaseHandler.sentCloudEvents[i+1]
+		assert.Equal(t, "sh.keptn.event.deployment.status.changed", statusChangedEvent.Type())
+		data := progressive.StatusChangedEventData{}
+		require.Nil(t, statusChangedEvent.DataAs(&data))
+		assert.Equal(t, progressive.Progress{Step: i + 1, Steps: 3, Weight: weight}, data.Canary)
+	}
+	finishedData := ReleaseFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[4].DataAs(&finishedData))
+	assert.Equal(t, "sh.keptn.event.release.finished", mockedBaseHandler.sentCloudEvents[4].Type())
+	assert.Equal(t, ResultPass, finishedData.Result)
+	require.Equal(t, 1, len(mockedBaseHandler.upgradeChartWithReplicasInvocations))
+	assert.Equal(t, 0, mockedBaseHandler.upgradeChartWithReplicasInvocations[0].replicas)
+}
+
+func TestHandleReleaseTriggeredEvent_ProgressiveRollbackOnFailedHealthCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedMesh := mocks.NewMockMesh(ctrl)
+	mockedChartGenerator := mocks.NewMockChartGenerator(ctrl)
+	mockedConfigurationChanger := mocks.NewMockIConfigurationChanger(ctrl)
+	mockedConfigProvider := mocks.NewMockConfigProvider(ctrl)
+	mockedHealthChecker := mocks.NewMockHealthChecker(ctrl)
+
+	healthCheck := progressive.HealthCheck{URL: "http://carts/health"}
+	mockedConfigProvider.EXPECT().GetConfig(gomock.Any(), gomock.Any()).Return(&progressive.Config{Steps: []int32{10, 50, 100}, HealthCheck: &healthCheck}, nil)
+	mockedHealthChecker.EXPECT().Check(healthCheck).Return(nil)
+	mockedHealthChecker.EXPECT().Check(healthCheck).Return(errors.New("health check failed"))
+	// 10%, 50% and 0% for the rollback
+	mockedConfigurationChanger.EXPECT().UpdateLoadedChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "123-456", nil).Times(3)
+	mockedChartGenerator.EXPECT().GenerateDuplicateChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	instance := &ReleaseHandler{
+		Handler:               mockedBaseHandler,
+		mesh:                  mockedMesh,
+		generatedChartHandler: mockedChartGenerator,
+		configurationChanger:  mockedConfigurationChanger,
+		progressiveConfig:     mockedConfigProvider,
+		healthChecker:         mockedHealthChecker,
+	}
+
+	ce := cloudevents.NewEvent()
+	_ = ce.SetData(cloudevents.ApplicationJSON, ReleaseTriggeredEventData{
+		EventData: EventData{Project: "sockshop", Stage: "production", Service: "carts", Result: ResultPass},
+		Deployment: DeploymentFinishedData{
+			DeploymentStrategy: keptnevents.Duplicate.String(),
+		},
+	})
+
+	instance.HandleEvent(ce)
+
+	require.Equal(t, 5, len(mockedBaseHandler.sentCloudEvents))
+	require.Equal(t, 0, len(mockedBaseHandler.handledErrorEvents))
+	rollbackData := progressive.StatusChangedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[3].DataAs(&rollbackData))
+	assert.Equal(t, progressive.Progress{Step: 2, Steps: 3, Weight: 0, RolledBack: true}, rollbackData.Canary)
+
+	finishedData := ReleaseFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[4].DataAs(&finishedData))
+	assert.Equal(t, StatusSucceeded, finishedData.Status)
+	assert.Equal(t, ResultFailed, finishedData.Result)
+	assert.Equal(t, "Rolled back canary in step 2 of 3 (50% traffic): health check failed", finishedData.Message)
+	require.Equal(t, 1, len(mockedBaseHandler.upgradeChartWithReplicasInvocations))
+	assert.Equal(t, 0, mockedBaseHandler.upgradeChartWithReplicasInvocations[0].replicas)
+}
+
+func newProgressiveReleaseEvent() cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	ce.SetID("release-triggered-id")
+	ce.SetSource("shipyard-controller")
+	ce.SetType("sh.keptn.event.release.triggered")
+	_ = ce.SetData(cloudevents.ApplicationJSON, ReleaseTriggeredEventData{
+		EventData: EventData{Project: "sockshop", Stage: "production", Service: "carts", Result: ResultPass},
+		Deployment: DeploymentFinishedData{
+			DeploymentStrategy: keptnevents.Duplicate.String(),
+		},
+	})
+	return ce
+}
+
+func TestHandleReleaseTriggeredEvent_ProgressivePersistsState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedChartGenerator := mocks.NewMockChartGenerator(ctrl)
+	mockedConfigurationChanger := mocks.NewMockIConfigurationChanger(ctrl)
+	mockedChartStorer := mocks.NewMockIChartStorer(ctrl)
+	mockedChartPackager := mocks.NewMockIChartPackager(ctrl)
+	mockedConfigProvider := mocks.NewMockConfigProvider(ctrl)
+	mockedHealthChecker := mocks.NewMockHealthChecker(ctrl)
+	states := progressive.NewK8sStateStore(fake.NewSimpleClientset(), "keptn")
+
+	healthCheck := progressive.HealthCheck{URL: "http://carts/health"}
+	mockedConfigProvider.EXPECT().GetConfig(gomock.Any(), gomock.Any()).Return(&progressive.Config{Steps: []int32{50, 100}, HealthCheck: &healthCheck}, nil)
+	mockedHealthChecker.EXPECT().Check(healthCheck).DoAndReturn(func(progressive.HealthCheck) error {
+		// the release waits after the weight of the first step has been applied
+		persisted, err := states.List()
+		require.Nil(t, err)
+		require.Len(t, persisted, 1)
+		assert.Equal(t, "release-triggered-id", persisted[0].Event.ID())
+		assert.Equal(t, 0, persisted[0].Step)
+		assert.Equal(t, "123-456", persisted[0].CommitID)
+		assert.False(t, persisted[0].WaitingSince.IsZero())
+		return nil
+	})
+	// 50%, 100% and 0% after replacing the primary
+	mockedConfigurationChanger.EXPECT().UpdateLoadedChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "123-456", nil).Times(3)
+	mockedChartGenerator.EXPECT().GenerateDuplicateChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&chart.Chart{}, nil)
+	mockedChartPackager.EXPECT().Package(gomock.Any()).Return([]byte("chart"), nil)
+	mockedChartStorer.EXPECT().Store(gomock.Any()).Return("", nil)
+
+	instance := &ReleaseHandler{
+		Handler:               mockedBaseHandler,
+		mesh:                  mocks.NewMockMesh(ctrl),
+		generatedChartHandler: mockedChartGenerator,
+		configurationChanger:  mockedConfigurationChanger,
+		chartStorer:           mockedChartStorer,
+		chartPackager:         mockedChartPackager,
+		progressiveConfig:     mockedConfigProvider,
+		healthChecker:         mockedHealthChecker,
+		states:                states,
+	}
+
+	instance.HandleEvent(newProgressiveReleaseEvent())
+
+	require.Equal(t, 0, len(mockedBaseHandler.handledErrorEvents))
+	persisted, err := states.List()
+	require.Nil(t, err)
+	assert.Empty(t, persisted)
+}
+
+func TestReleaseHandler_ResumeProgressiveRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	mockedChartGenerator := mocks.NewMockChartGenerator(ctrl)
+	mockedConfigurationChanger := mocks.NewMockIConfigurationChanger(ctrl)
+	mockedChartStorer := mocks.NewMockIChartStorer(ctrl)
+	mockedChartPackager := mocks.NewMockIChartPackager(ctrl)
+	mockedHealthChecker := mocks.NewMockHealthChecker(ctrl)
+	states := progressive.NewK8sStateStore(fake.NewSimpleClientset(), "keptn")
+
+	// the helm-service has been restarted while waiting after the first of three steps, and the interval is already over
+	healthCheck := progressive.HealthCheck{URL: "http://carts/health"}
+	state := progressive.State{
+		Event:        newProgressiveReleaseEvent(),
+		CommitID:     "123-456",
+		Config:       progressive.Config{Steps: []int32{25, 50, 100}, Interval: "1h", HealthCheck: &healthCheck},
+		WaitingSince: time.Now().Add(-2 * time.Hour),
+	}
+	require.Nil(t, states.Save(state))
+
+	mockedHealthChecker.EXPECT().Check(healthCheck).Return(nil)
+	// 50% is awaited for an hour, therefore the health check of the first step is the only one executed
+	mockedConfigurationChanger.EXPECT().UpdateLoadedChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "123-456", nil).Times(1)
+
+	instance := &ReleaseHandler{
+		Handler:               mockedBaseHandler,
+		mesh:                  mocks.NewMockMesh(ctrl),
+		generatedChartHandler: mockedChartGenerator,
+		configurationChanger:  mockedConfigurationChanger,
+		chartStorer:           mockedChartStorer,
+		chartPackager:         mockedChartPackager,
+		healthChecker:         mockedHealthChecker,
+		signals:               progressive.NewSignals(),
+		states:                states,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		instance.ResumeProgressiveRelease(state)
+		close(done)
+	}()
+
+	// abort the release while it waits after the second step
+	require.Eventually(t, func() bool {
+		persisted, err := states.List()
+		return err == nil && len(persisted) == 1 && persisted[0].Step == 1 && !persisted[0].WaitingSince.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+	mockedConfigurationChanger.EXPECT().UpdateLoadedChart(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, "123-456", nil).Times(1)
+	require.Eventually(t, func() bool {
+		return instance.signals.Send(mockedBaseHandler.getKeptnHandler().KeptnContext, progressive.SignalAbort)
+	}, 5*time.Second, 10*time.Millisecond)
+	<-done
+
+	require.Equal(t, 0, len(mockedBaseHandler.handledErrorEvents))
+	require.Equal(t, 3, len(mockedBaseHandler.sentCloudEvents))
+	statusChangedData := progressive.StatusChangedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[0].DataAs(&statusChangedData))
+	assert.Equal(t, progressive.Progress{Step: 2, Steps: 3, Weight: 50}, statusChangedData.Canary)
+	finishedData := ReleaseFinishedEventData{}
+	require.Nil(t, mockedBaseHandler.sentCloudEvents[2].DataAs(&finishedData))
+	assert.Equal(t, "sh.keptn.event.release.finished", mockedBaseHandler.sentCloudEvents[2].Type())
+	assert.Equal(t, ResultFailed, finishedData.Result)
+	assert.Equal(t, "Rolled back canary in step 2 of 3 (50% traffic): the release has been aborted by a signal", finishedData.Message)
+
+	persisted, err := states.List()
+	require.Nil(t, err)
+	assert.Empty(t, persisted)
+}
+
+func TestReleaseHandler_FailProgressiveRelease(t *testing.T) {
+	mockedBaseHandler := NewMockedHandler(createKeptn(), "")
+	states := progressive.NewK8sStateStore(fake.NewSimpleClientset(), "keptn")
+	state := progressive.State{Event: newProgressiveReleaseEvent(), Config: progressive.Config{Steps: []int32{100}}}
+	require.Nil(t, states.Save(state))
+
+	instance := &ReleaseHandler{Handler: mockedBaseHandler, states: states}
+	instance.FailProgressiveRelease(state, errors.New("could not fetch mesh configuration"))
+
+	require.Equal(t, 1, len(mockedBaseHandler.handledErrorEvents))
+	finishedData := mockedBaseHandler.handledErrorEvents[0].(ReleaseFinishedEventData)
+	assert.Equal(t, StatusErrored, finishedData.Status)
+	assert.Equal(t, "could not resume progressive release after a restart of the helm-service: could not fetch mesh configuration", finishedData.Message)
+	persisted, err := states.List()
+	require.Nil(t, err)
+	assert.Empty(t, persisted)
+}
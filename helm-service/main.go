@@ -29,7 +29,9 @@ import (
 
 	utils "github.com/keptn/go-utils/pkg/api/utils"
 	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	"github.com/keptn/keptn/helm-service/pkg/progressive"
 	"github.com/keptn/keptn/helm-service/pkg/serviceutils"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
 	authorizationv1 "k8s.io/api/authorization/v1"
 )
 
@@ -38,10 +40,18 @@ type envConfig struct {
 	Port     int    `envconfig:"RCV_PORT" default:"8080"`
 	Path     string `envconfig:"RCV_PATH" default:"/"`
 	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+	// Namespace is the namespace the states of running progressive releases are stored in
+	Namespace string `envconfig:"POD_NAMESPACE" default:"keptn"`
 }
 
 const serviceName = "helm-service"
 
+// signals passes received sh.keptn.event.canary.signal events to the progressive releases waiting for them
+var signals = progressive.NewSignals()
+
+// progressiveStates persists the states of the running progressive releases, so that they are resumed after a restart
+var progressiveStates = progressive.NewNoopStateStore()
+
 func main() {
 	var env envConfig
 	if err := envconfig.Process("", &env); err != nil {
@@ -89,26 +99,49 @@ func gotEvent(ctx context.Context, event cloudevents.Event) error {
 		return err
 	}
 
-	//create dependencies
-
-	istioMesh := mesh.NewIstioMesh()
 	logger.Debug("Got event of type " + event.Type())
 
 	// ToDo: Multithreaded is important here, such that the endpoint responds immediately
 	// else we will have deployment handler take 30 seconds, and after that the response will be sent
 
 	if event.Type() == keptnv2.GetTriggeredEventType(keptnv2.DeploymentTaskName) {
-		deploymentHandler := createDeploymentHandler(configServiceURL, keptnHandler, istioMesh)
+		trafficMesh, err := getProjectMesh(configServiceURL, event)
+		if err != nil {
+			go gracefulMiddleware(ctx, event, controller.NewMeshErrorHandler(createKeptnBaseHandler(configServiceURL, keptnHandler), keptnv2.DeploymentTaskName, err))
+			return nil
+		}
+		deploymentHandler := createDeploymentHandler(configServiceURL, keptnHandler, trafficMesh)
 		go gracefulMiddleware(ctx, event, deploymentHandler)
 	} else if event.Type() == keptnv2.GetTriggeredEventType(keptnv2.ReleaseTaskName) {
-		releaseHandler := createReleaseHandler(configServiceURL, istioMesh, keptnHandler)
+		trafficMesh, err := getProjectMesh(configServiceURL, event)
+		if err != nil {
+			go gracefulMiddleware(ctx, event, controller.NewMeshErrorHandler(createKeptnBaseHandler(configServiceURL, keptnHandler), keptnv2.ReleaseTaskName, err))
+			return nil
+		}
+		releaseHandler := createReleaseHandler(configServiceURL, trafficMesh, keptnHandler)
 		go gracefulMiddleware(ctx, event, releaseHandler)
 	} else if event.Type() == keptnv2.GetTriggeredEventType(keptnv2.RollbackTaskName) {
-		rollbackHandler := createRollbackHandler(configServiceURL, istioMesh, keptnHandler)
+		trafficMesh, err := getProjectMesh(configServiceURL, event)
+		if err != nil {
+			go gracefulMiddleware(ctx, event, controller.NewMeshErrorHandler(createKeptnBaseHandler(configServiceURL, keptnHandler), keptnv2.RollbackTaskName, err))
+			return nil
+		}
+		rollbackHandler := createRollbackHandler(configServiceURL, trafficMesh, keptnHandler)
 		go gracefulMiddleware(ctx, event, rollbackHandler)
 	} else if event.Type() == keptnv2.GetTriggeredEventType(keptnv2.ActionTaskName) {
-		actionHandler := createActionTriggeredHandler(configServiceURL, keptnHandler)
+		// the rollback action promotes the rolled back version of services deployed b/g
+		trafficMesh, err := getProjectMesh(configServiceURL, event)
+		if err != nil {
+			go gracefulMiddleware(ctx, event, controller.NewMeshErrorHandler(createKeptnBaseHandler(configServiceURL, keptnHandler), keptnv2.ActionTaskName, err))
+			return nil
+		}
+		actionHandler := createActionTriggeredHandler(configServiceURL, keptnHandler, trafficMesh)
 		go gracefulMiddleware(ctx, event, actionHandler)
+	} else if event.Type() == keptnv2.GetTriggeredEventType(controller.DryRunTaskName) {
+		dryRunHandler := createDryRunHandler(configServiceURL, keptnHandler)
+		go gracefulMiddleware(ctx, event, dryRunHandler)
+	} else if event.Type() == progressive.SignalEventType {
+		handleCanarySignal(event, keptnHandler)
 	} else if event.Type() == keptnv2.GetFinishedEventType(keptnv2.ServiceDeleteTaskName) {
 		deleteHandler := createDeleteHandler(configServiceURL, shipyardControllerURL, keptnHandler)
 		go gracefulMiddleware(ctx, event, deleteHandler)
@@ -120,6 +153,37 @@ func gotEvent(ctx context.Context, event cloudevents.Event) error {
 	return nil
 }
 
+// getProjectMesh loads the mesh configured in helm/mesh.yaml of the project of the event. It is only needed by the
+// handlers changing the traffic routing of a service
+func getProjectMesh(configServiceURL *url.URL, event cloudevents.Event) (mesh.Mesh, error) {
+	eventData := keptnv2.EventData{}
+	if err := event.DataAs(&eventData); err != nil {
+		logger.WithError(err).Error("could not decode event data")
+		return nil, err
+	}
+	trafficMesh, err := mesh.GetProjectMesh(utils.NewResourceHandler(configServiceURL.String()), eventData.Project)
+	if err != nil {
+		logger.WithError(err).Error("could not create mesh")
+		return nil, err
+	}
+	return trafficMesh, nil
+}
+
+func handleCanarySignal(event cloudevents.Event, keptn *keptnv2.Keptn) {
+	data := progressive.SignalEventData{}
+	if err := event.DataAs(&data); err != nil {
+		logger.WithError(err).Error("could not decode canary signal")
+		return
+	}
+	if data.Canary.Action != progressive.SignalProceed && data.Canary.Action != progressive.SignalAbort {
+		logger.Errorf("received canary signal with unsupported action '%s'", data.Canary.Action)
+		return
+	}
+	if !signals.Send(keptn.KeptnContext, data.Canary.Action) {
+		logger.Infof("no progressive release of context %s is waiting for a signal", keptn.KeptnContext)
+	}
+}
+
 func createKeptnBaseHandler(url *url.URL, keptn *keptnv2.Keptn) controller.Handler {
 	namespaceManager := namespacemanager.NewNamespaceManager(keptn.Logger)
 	helmExecutor := helm.NewHelmV3Executor(keptn.Logger, namespaceManager)
@@ -134,31 +198,42 @@ func createDeleteHandler(configServiceURL *url.URL, shipyardControllerURL *url.U
 	return deleteHandler
 }
 
-func createActionTriggeredHandler(configServiceURL *url.URL, keptn *keptnv2.Keptn) *controller.ActionTriggeredHandler {
+func createActionTriggeredHandler(configServiceURL *url.URL, keptn *keptnv2.Keptn, mesh mesh.Mesh) *controller.ActionTriggeredHandler {
 	configChanger := configurationchanger.NewConfigurationChanger(configServiceURL.String())
+	chartGenerator := helm.NewGeneratedChartGenerator(mesh)
+	chartStorer := keptnutils.NewChartStorer(utils.NewResourceHandler(configServiceURL.String()))
+	chartPackager := keptnutils.NewChartPackager()
 	keptnBaseHandler := createKeptnBaseHandler(configServiceURL, keptn)
-	actionHandler := controller.NewActionTriggeredHandler(keptnBaseHandler, configChanger)
+	actionHandler := controller.NewActionTriggeredHandler(keptnBaseHandler, mesh, configChanger, chartGenerator, chartStorer, chartPackager)
 	return actionHandler
 }
 
-func createReleaseHandler(url *url.URL, mesh *mesh.IstioMesh, keptn *keptnv2.Keptn) *controller.ReleaseHandler {
+func createReleaseHandler(url *url.URL, mesh mesh.Mesh, keptn *keptnv2.Keptn) *controller.ReleaseHandler {
 	configChanger := configurationchanger.NewConfigurationChanger(url.String())
 	chartGenerator := helm.NewGeneratedChartGenerator(mesh)
 	chartStorer := keptnutils.NewChartStorer(utils.NewResourceHandler(url.String()))
 	chartPackager := keptnutils.NewChartPackager()
+	progressiveConfig := progressive.NewResourceConfigProvider(utils.NewResourceHandler(url.String()))
 	keptnBaseHandler := createKeptnBaseHandler(url, keptn)
-	releaseHandler := controller.NewReleaseHandler(keptnBaseHandler, mesh, configChanger, chartGenerator, chartStorer, chartPackager)
+	releaseHandler := controller.NewReleaseHandler(keptnBaseHandler, mesh, configChanger, chartGenerator, chartStorer, chartPackager,
+		progressiveConfig, progressive.NewHTTPHealthChecker(), signals, progressiveStates)
 	return releaseHandler
 }
 
-func createRollbackHandler(url *url.URL, mesh *mesh.IstioMesh, keptn *keptnv2.Keptn) *controller.RollbackHandler {
+func createRollbackHandler(url *url.URL, mesh mesh.Mesh, keptn *keptnv2.Keptn) *controller.RollbackHandler {
 	configChanger := configurationchanger.NewConfigurationChanger(url.String())
 	keptnBaseHandler := createKeptnBaseHandler(url, keptn)
 	rollbackHandler := controller.NewRollbackHandler(keptnBaseHandler, mesh, configChanger)
 	return rollbackHandler
 }
 
-func createOnboarder(configServiceURL *url.URL, keptn *keptnv2.Keptn, mesh *mesh.IstioMesh) controller.Onboarder {
+func createDryRunHandler(url *url.URL, keptn *keptnv2.Keptn) *controller.DryRunHandler {
+	policyProvider := validation.NewResourcePolicyProvider(utils.NewResourceHandler(url.String()))
+	keptnBaseHandler := createKeptnBaseHandler(url, keptn)
+	return controller.NewDryRunHandler(keptnBaseHandler, policyProvider)
+}
+
+func createOnboarder(configServiceURL *url.URL, keptn *keptnv2.Keptn, mesh mesh.Mesh) controller.Onboarder {
 	namespaceManager := namespacemanager.NewNamespaceManager(keptn.Logger)
 	chartStorer := keptnutils.NewChartStorer(utils.NewResourceHandler(configServiceURL.String()))
 	chartGenerator := helm.NewGeneratedChartGenerator(mesh)
@@ -168,11 +243,12 @@ func createOnboarder(configServiceURL *url.URL, keptn *keptnv2.Keptn, mesh *mesh
 	return onBoarder
 }
 
-func createDeploymentHandler(url *url.URL, keptn *keptnv2.Keptn, mesh *mesh.IstioMesh) *controller.DeploymentHandler {
+func createDeploymentHandler(url *url.URL, keptn *keptnv2.Keptn, mesh mesh.Mesh) *controller.DeploymentHandler {
 	chartGenerator := helm.NewGeneratedChartGenerator(mesh)
 	onBoarder := createOnboarder(url, keptn, mesh)
+	policyProvider := validation.NewResourcePolicyProvider(utils.NewResourceHandler(url.String()))
 	keptnBaseHandler := createKeptnBaseHandler(url, keptn)
-	deploymentHandler := controller.NewDeploymentHandler(keptnBaseHandler, mesh, onBoarder, chartGenerator)
+	deploymentHandler := controller.NewDeploymentHandler(keptnBaseHandler, mesh, onBoarder, chartGenerator, policyProvider)
 	return deploymentHandler
 }
 
@@ -189,6 +265,13 @@ func _main(args []string, env envConfig) int {
 
 	ctx := getGracefulContext()
 
+	clientset, err := keptnutils.GetClientset(true)
+	if err != nil {
+		log.Fatalf("failed to create kubernetes client, %v", err)
+	}
+	progressiveStates = progressive.NewK8sStateStore(clientset, env.Namespace)
+	resumeProgressiveReleases(ctx)
+
 	p, err := cloudevents.NewHTTP(cloudevents.WithPath(env.Path), cloudevents.WithPort(env.Port), cloudevents.WithGetHandlerFunc(keptnapi.HealthEndpointHandler))
 	if err != nil {
 		log.Fatalf("failed to create client, %v", err)
@@ -202,6 +285,48 @@ func _main(args []string, env envConfig) int {
 	return 0
 }
 
+// resumeProgressiveReleases continues the progressive releases which have been interrupted by a restart of the
+// helm-service. Releases which cannot be resumed are finished with an error
+func resumeProgressiveReleases(ctx context.Context) {
+	states, err := progressiveStates.List()
+	if err != nil {
+		logger.WithError(err).Error("could not load interrupted progressive releases")
+		return
+	}
+	for _, state := range states {
+		event := state.Event
+		keptnHandler, err := keptnv2.NewKeptn(&event, keptncommon.KeptnOpts{
+			ConfigurationServiceURL: os.Getenv("CONFIGURATION_SERVICE"),
+		})
+		if err != nil {
+			logger.WithError(err).Errorf("could not resume progressive release %s", event.ID())
+			if err := progressiveStates.Delete(event.ID()); err != nil {
+				logger.WithError(err).Error("could not delete the state of the progressive release")
+			}
+			continue
+		}
+		configServiceURL, err := serviceutils.GetConfigServiceURL()
+		if err != nil {
+			logger.WithError(err).Error("Error when getting configServiceURL")
+			return
+		}
+		trafficMesh, err := getProjectMesh(configServiceURL, event)
+		if err != nil {
+			// failing the release only reports the error, so it does not need the mesh
+			createReleaseHandler(configServiceURL, nil, keptnHandler).FailProgressiveRelease(state, err)
+			continue
+		}
+		releaseHandler := createReleaseHandler(configServiceURL, trafficMesh, keptnHandler)
+		go func(state progressive.State) {
+			if wg, ok := ctx.Value(controller.GracefulShutdownKey).(*sync.WaitGroup); ok {
+				wg.Add(1)
+				defer wg.Done()
+			}
+			releaseHandler.ResumeProgressiveRelease(state)
+		}(state)
+	}
+}
+
 // hasAdminRights checks if the current pod is assigned the Admin Role
 func hasAdminRights() (bool, error) {
 	clientset, err := keptnutils.GetClientset(true)
@@ -17,4 +17,7 @@ mockgen -package mocks -destination=./mock_stages_handler.go  github.com/keptn/k
 mockgen -package mocks -destination=./mock_mesh.go github.com/keptn/keptn/helm-service/pkg/mesh Mesh
 mockgen -package mocks -destination=./mock_service_handler.go github.com/keptn/keptn/helm-service/pkg/types IServiceHandler
 mockgen -package mocks -destination=./mock_resource_handler.go github.com/keptn/keptn/helm-service/pkg/types IResourceHandler
-mockgen -package mocks -destination=./mock_onboarder.go github.com/keptn/keptn/helm-service/controller Onboarder
\ No newline at end of file
+mockgen -package mocks -destination=./mock_onboarder.go github.com/keptn/keptn/helm-service/controller Onboarder
+mockgen -package mocks -destination=./mock_progressive_config_provider.go github.com/keptn/keptn/helm-service/pkg/progressive ConfigProvider
+mockgen -package mocks -destination=./mock_health_checker.go github.com/keptn/keptn/helm-service/pkg/progressive HealthChecker
+mockgen -package mocks -destination=./mock_policy_provider.go github.com/keptn/keptn/helm-service/pkg/validation PolicyProvider
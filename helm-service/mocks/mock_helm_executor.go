@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	chart "helm.sh/helm/v3/pkg/chart"
+	release "helm.sh/helm/v3/pkg/release"
 )
 
 // MockHelmExecutor is a mock of HelmExecutor interface.
@@ -49,6 +50,66 @@ func (mr *MockHelmExecutorMockRecorder) GetManifest(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManifest", reflect.TypeOf((*MockHelmExecutor)(nil).GetManifest), arg0, arg1)
 }
 
+// GetReleaseHistory mocks base method.
+func (m *MockHelmExecutor) GetReleaseHistory(arg0, arg1 string) ([]*release.Release, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReleaseHistory", arg0, arg1)
+	ret0, _ := ret[0].([]*release.Release)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReleaseHistory indicates an expected call of GetReleaseHistory.
+func (mr *MockHelmExecutorMockRecorder) GetReleaseHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseHistory", reflect.TypeOf((*MockHelmExecutor)(nil).GetReleaseHistory), arg0, arg1)
+}
+
+// RenderChart mocks base method.
+func (m *MockHelmExecutor) RenderChart(arg0 *chart.Chart, arg1, arg2 string, arg3 map[string]interface{}) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderChart", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderChart indicates an expected call of RenderChart.
+func (mr *MockHelmExecutorMockRecorder) RenderChart(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderChart", reflect.TypeOf((*MockHelmExecutor)(nil).RenderChart), arg0, arg1, arg2, arg3)
+}
+
+// RestartRelease mocks base method.
+func (m *MockHelmExecutor) RestartRelease(arg0, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestartRelease", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestartRelease indicates an expected call of RestartRelease.
+func (mr *MockHelmExecutorMockRecorder) RestartRelease(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartRelease", reflect.TypeOf((*MockHelmExecutor)(nil).RestartRelease), arg0, arg1)
+}
+
+// RollbackRelease mocks base method.
+func (m *MockHelmExecutor) RollbackRelease(arg0, arg1 string, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackRelease", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackRelease indicates an expected call of RollbackRelease.
+func (mr *MockHelmExecutorMockRecorder) RollbackRelease(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackRelease", reflect.TypeOf((*MockHelmExecutor)(nil).RollbackRelease), arg0, arg1, arg2)
+}
+
 // UninstallRelease mocks base method.
 func (m *MockHelmExecutor) UninstallRelease(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
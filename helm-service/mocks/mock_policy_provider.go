@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/keptn/keptn/helm-service/pkg/validation (interfaces: PolicyProvider)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v0_2_0 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	validation "github.com/keptn/keptn/helm-service/pkg/validation"
+)
+
+// MockPolicyProvider is a mock of PolicyProvider interface.
+type MockPolicyProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyProviderMockRecorder
+}
+
+// MockPolicyProviderMockRecorder is the mock recorder for MockPolicyProvider.
+type MockPolicyProviderMockRecorder struct {
+	mock *MockPolicyProvider
+}
+
+// NewMockPolicyProvider creates a new mock instance.
+func NewMockPolicyProvider(ctrl *gomock.Controller) *MockPolicyProvider {
+	mock := &MockPolicyProvider{ctrl: ctrl}
+	mock.recorder = &MockPolicyProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicyProvider) EXPECT() *MockPolicyProviderMockRecorder {
+	return m.recorder
+}
+
+// GetPolicy mocks base method.
+func (m *MockPolicyProvider) GetPolicy(arg0 v0_2_0.EventData, arg1 string) (*validation.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPolicy", arg0, arg1)
+	ret0, _ := ret[0].(*validation.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPolicy indicates an expected call of GetPolicy.
+func (mr *MockPolicyProviderMockRecorder) GetPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockPolicyProvider)(nil).GetPolicy), arg0, arg1)
+}
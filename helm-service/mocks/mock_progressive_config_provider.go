@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/keptn/keptn/helm-service/pkg/progressive (interfaces: ConfigProvider)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v0_2_0 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	progressive "github.com/keptn/keptn/helm-service/pkg/progressive"
+)
+
+// MockConfigProvider is a mock of ConfigProvider interface.
+type MockConfigProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigProviderMockRecorder
+}
+
+// MockConfigProviderMockRecorder is the mock recorder for MockConfigProvider.
+type MockConfigProviderMockRecorder struct {
+	mock *MockConfigProvider
+}
+
+// NewMockConfigProvider creates a new mock instance.
+func NewMockConfigProvider(ctrl *gomock.Controller) *MockConfigProvider {
+	mock := &MockConfigProvider{ctrl: ctrl}
+	mock.recorder = &MockConfigProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigProvider) EXPECT() *MockConfigProviderMockRecorder {
+	return m.recorder
+}
+
+// GetConfig mocks base method.
+func (m *MockConfigProvider) GetConfig(arg0 v0_2_0.EventData, arg1 string) (*progressive.Config, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfig", arg0, arg1)
+	ret0, _ := ret[0].(*progressive.Config)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfig indicates an expected call of GetConfig.
+func (mr *MockConfigProviderMockRecorder) GetConfig(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockConfigProvider)(nil).GetConfig), arg0, arg1)
+}
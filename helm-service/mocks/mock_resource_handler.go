@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/keptn/go-utils/pkg/api/models"
+	api "github.com/keptn/go-utils/pkg/api/utils"
 )
 
 // MockIResourceHandler is a mock of IResourceHandler interface.
@@ -34,17 +35,22 @@ func (m *MockIResourceHandler) EXPECT() *MockIResourceHandlerMockRecorder {
 	return m.recorder
 }
 
-// GetServiceResource mocks base method.
-func (m *MockIResourceHandler) GetServiceResource(arg0, arg1, arg2, arg3 string) (*models.Resource, error) {
+// GetResource mocks base method.
+func (m *MockIResourceHandler) GetResource(arg0 api.ResourceScope, arg1 ...api.URIOption) (*models.Resource, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetServiceResource", arg0, arg1, arg2, arg3)
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetResource", varargs...)
 	ret0, _ := ret[0].(*models.Resource)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetServiceResource indicates an expected call of GetServiceResource.
-func (mr *MockIResourceHandlerMockRecorder) GetServiceResource(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// GetResource indicates an expected call of GetResource.
+func (mr *MockIResourceHandlerMockRecorder) GetResource(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceResource", reflect.TypeOf((*MockIResourceHandler)(nil).GetServiceResource), arg0, arg1, arg2, arg3)
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResource", reflect.TypeOf((*MockIResourceHandler)(nil).GetResource), varargs...)
 }
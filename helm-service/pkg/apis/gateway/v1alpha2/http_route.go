@@ -0,0 +1,38 @@
+// Package v1alpha2 contains the subset of the Gateway API HTTPRoute (gateway.networking.k8s.io/v1alpha2) used by the helm-service
+package v1alpha2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// HTTPRoute provides a way to route HTTP requests of a Gateway to Kubernetes services
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              HTTPRouteSpec `json:"spec"`
+}
+
+// HTTPRouteSpec defines the desired state of HTTPRoute
+type HTTPRouteSpec struct {
+	// ParentRefs references the Gateways the route is attached to
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+	// Hostnames defines the hostnames which are matched against the Host header of HTTP requests
+	Hostnames []string        `json:"hostnames,omitempty"`
+	Rules     []HTTPRouteRule `json:"rules,omitempty"`
+}
+
+// ParentReference identifies a Gateway
+type ParentReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HTTPRouteRule defines the backends requests are forwarded to
+type HTTPRouteRule struct {
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+// HTTPBackendRef references a Kubernetes service and the proportion of requests forwarded to it
+type HTTPBackendRef struct {
+	Name   string `json:"name"`
+	Port   *int32 `json:"port,omitempty"`
+	Weight *int32 `json:"weight,omitempty"`
+}
@@ -0,0 +1,25 @@
+// Package v1alpha2 contains the subset of the SMI TrafficSplit API (split.smi-spec.io/v1alpha2) used by the helm-service
+package v1alpha2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// TrafficSplit allows users to incrementally direct percentages of traffic between various services
+type TrafficSplit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              TrafficSplitSpec `json:"spec"`
+}
+
+// TrafficSplitSpec is the specification for a TrafficSplit
+type TrafficSplitSpec struct {
+	// Service is the root service to which clients connect
+	Service string `json:"service"`
+	// Backends defines a list of Kubernetes services used as the traffic split destination
+	Backends []TrafficSplitBackend `json:"backends"`
+}
+
+// TrafficSplitBackend defines a backend of a TrafficSplit
+type TrafficSplitBackend struct {
+	Service string `json:"service"`
+	Weight  int    `json:"weight"`
+}
@@ -125,7 +125,7 @@ func (c *GeneratedChartGenerator) generateServices(svc *corev1.Service, project
 		logger.Error("Error while generating destination rule for canary service " + serviceCanary.Name + ": " + err.Error())
 		return nil, err
 	}
-	templates = append(templates, &chart.File{Name: "templates/" + serviceCanary.Name + c.mesh.GetDestinationRuleSuffix(), Data: destinationRuleCanary})
+	templates = appendTemplate(templates, "templates/"+serviceCanary.Name+c.mesh.GetDestinationRuleSuffix(), destinationRuleCanary)
 
 	servicePrimary := svc.DeepCopy()
 	servicePrimary.Name = servicePrimary.Name + "-primary"
@@ -156,7 +156,7 @@ func (c *GeneratedChartGenerator) generateServices(svc *corev1.Service, project
 		logger.Error("Error while generating destination rule for primary service " + svc.Name + ": " + err.Error())
 		return nil, err
 	}
-	templates = append(templates, &chart.File{Name: "templates/" + servicePrimary.Name + c.mesh.GetDestinationRuleSuffix(), Data: destinationRulePrimary})
+	templates = appendTemplate(templates, "templates/"+servicePrimary.Name+c.mesh.GetDestinationRuleSuffix(), destinationRulePrimary)
 
 	// get the public hostname based on what has been configured in HOSTNAME_TEMPLATE and INGRESS_HOSTNAME_SUFFIX
 	publicHostName, err := getVirtualServicePublicHost(svc.Name, project, stageName)
@@ -170,8 +170,9 @@ func (c *GeneratedChartGenerator) generateServices(svc *corev1.Service, project
 		publicHostName, // service_name.dev.123.45.67.89.xip.io
 		svc.Name,       // service-name
 	}
-	destCanary := mesh.HTTPRouteDestination{Host: hostCanary, Weight: 0}
-	destPrimary := mesh.HTTPRouteDestination{Host: hostPrimary, Weight: 100}
+	port := getServicePort(svc)
+	destCanary := mesh.HTTPRouteDestination{Host: hostCanary, Weight: 0, Port: port}
+	destPrimary := mesh.HTTPRouteDestination{Host: hostPrimary, Weight: 100, Port: port}
 	httpRouteDestinations := []mesh.HTTPRouteDestination{destCanary, destPrimary}
 
 	logger.Info("Generating VirtualService for service " + svc.Name + ". URL = " + mesh.GetIngressProtocol() +
@@ -182,11 +183,27 @@ func (c *GeneratedChartGenerator) generateServices(svc *corev1.Service, project
 		return nil, err
 	}
 
-	templates = append(templates, &chart.File{Name: "templates/" + svc.Name + c.mesh.GetVirtualServiceSuffix(), Data: vs})
+	templates = appendTemplate(templates, "templates/"+svc.Name+c.mesh.GetVirtualServiceSuffix(), vs)
 
 	return templates, nil
 }
 
+// appendTemplate appends a template with the given data, unless the data is nil because the mesh does not require the resource
+func appendTemplate(templates []*chart.File, name string, data []byte) []*chart.File {
+	if data == nil {
+		return templates
+	}
+	return append(templates, &chart.File{Name: name, Data: data})
+}
+
+// getServicePort returns the first port of the service, or 0 if the service has no ports
+func getServicePort(svc *corev1.Service) int32 {
+	if len(svc.Spec.Ports) == 0 {
+		return 0
+	}
+	return svc.Spec.Ports[0].Port
+}
+
 func getVirtualServicePublicHost(serviceName, projectName, stageName string) (string, error) {
 	publicURI := mesh.GetPublicDeploymentURI(keptnv2.EventData{
 		Project: projectName,
@@ -261,7 +278,7 @@ func (c *GeneratedChartGenerator) GenerateMeshChart(helmManifest string, project
 			svc.Name,
 		}
 		host := svc.Name + "." + c.getNamespace(project, stageName) + ".svc.cluster.local"
-		dest := mesh.HTTPRouteDestination{Host: host}
+		dest := mesh.HTTPRouteDestination{Host: host, Port: getServicePort(svc)}
 		httpRouteDestinations := []mesh.HTTPRouteDestination{dest}
 
 		vs, err := c.mesh.GenerateVirtualService(svc.Name, gws, hosts, httpRouteDestinations)
@@ -269,15 +286,13 @@ func (c *GeneratedChartGenerator) GenerateMeshChart(helmManifest string, project
 			return nil, err
 		}
 
-		vsTemplate := chart.File{Name: "templates/" + svc.Name + c.mesh.GetVirtualServiceSuffix(), Data: vs}
-		ch.Templates = append(ch.Templates, &vsTemplate)
+		ch.Templates = appendTemplate(ch.Templates, "templates/"+svc.Name+c.mesh.GetVirtualServiceSuffix(), vs)
 
 		dr, err := c.mesh.GenerateDestinationRule(svc.Name, host)
 		if err != nil {
 			return nil, err
 		}
-		drTemplate := chart.File{Name: "templates/" + svc.Name + c.mesh.GetDestinationRuleSuffix(), Data: dr}
-		ch.Templates = append(ch.Templates, &drTemplate)
+		ch.Templates = appendTemplate(ch.Templates, "templates/"+svc.Name+c.mesh.GetDestinationRuleSuffix(), dr)
 	}
 
 	return &ch, nil
@@ -44,6 +44,34 @@ func TestGenerateDuplicateChart(t *testing.T) {
 	assert.Equal(t, yamlUnmarshal([]byte(strings.Replace(GeneratedPrimaryDeployment, nsPlaceholder, ns, -1))), yamlUnmarshal(ch.Templates[5].Data))
 }
 
+func TestGenerateDuplicateChart_SMIMesh(t *testing.T) {
+	generator := NewGeneratedChartGenerator(mesh.NewSMIMesh())
+
+	ch, err := generator.GenerateDuplicateChart(userService+renderedUserDeployment, "sockshop", "dev", "carts")
+	assert.Nil(t, err)
+
+	// no destination rules are generated for SMI
+	var names []string
+	for _, template := range ch.Templates {
+		names = append(names, template.Name)
+	}
+	assert.Equal(t, []string{
+		"templates/carts-canary-service.yaml",
+		"templates/carts-primary-service.yaml",
+		"templates/carts-smi-trafficsplit.yaml",
+		"templates/carts-primary-deployment.yaml",
+	}, names)
+	assert.Contains(t, string(ch.Templates[2].Data), "service: carts-primary")
+}
+
+func TestGenerateMeshChart_SMIMesh(t *testing.T) {
+	generator := NewGeneratedChartGenerator(mesh.NewSMIMesh())
+
+	ch, err := generator.GenerateMeshChart(userService, "sockshop", "dev", "carts")
+	assert.Nil(t, err)
+	assert.Empty(t, ch.Templates)
+}
+
 func TestGenerateDuplicateChartWithTwoServices(t *testing.T) {
 	generator := NewGeneratedChartGenerator(mesh.NewIstioMesh())
 
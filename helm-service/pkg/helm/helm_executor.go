@@ -2,11 +2,16 @@ package helm
 
 import (
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 // HelmExecutor is an interface for Helm operations
 type HelmExecutor interface {
 	GetManifest(releaseName string, namespace string) (string, error)
+	GetReleaseHistory(releaseName string, namespace string) ([]*release.Release, error)
 	UpgradeChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) error
+	RenderChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) (string, error)
 	UninstallRelease(releaseName, namespace string) error
+	RollbackRelease(releaseName, namespace string, revision int) (int, error)
+	RestartRelease(releaseName, namespace string) (int, error)
 }
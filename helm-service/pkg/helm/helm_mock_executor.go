@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 // HelmMockExecutor mocks Helm operations
@@ -232,12 +233,36 @@ func (h *HelmMockExecutor) GetManifest(releaseName, namespace string) (string, e
 	return userDeployment + userService, nil
 }
 
+// GetReleaseHistory returns no revisions
+func (h *HelmMockExecutor) GetReleaseHistory(releaseName, namespace string) ([]*release.Release, error) {
+	return nil, nil
+}
+
 // UpgradeChart does not execute any action
 func (h *HelmMockExecutor) UpgradeChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) error {
 	return nil
 }
 
+// RenderChart returns the unrendered templates of the chart
+func (h *HelmMockExecutor) RenderChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) (string, error) {
+	manifest := ""
+	for _, template := range ch.Templates {
+		manifest += "---\n" + string(template.Data) + "\n"
+	}
+	return manifest, nil
+}
+
 // UninstallRelease does not execute any action
 func (h *HelmMockExecutor) UninstallRelease(releaseName, namespace string) error {
 	return nil
 }
+
+// RollbackRelease does not execute any action
+func (h *HelmMockExecutor) RollbackRelease(releaseName, namespace string, revision int) (int, error) {
+	return revision, nil
+}
+
+// RestartRelease does not execute any action
+func (h *HelmMockExecutor) RestartRelease(releaseName, namespace string) (int, error) {
+	return 1, nil
+}
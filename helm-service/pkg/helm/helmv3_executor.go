@@ -1,10 +1,12 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"github.com/keptn/keptn/helm-service/pkg/namespacemanager"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	keptncommon "github.com/keptn/go-utils/pkg/lib/keptn"
@@ -17,7 +19,10 @@ import (
 	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -49,7 +54,7 @@ func NewHelmV3Executor(logger keptncommon.LoggerInterface, nsManager namespacema
 func (h *HelmV3Executor) newActionConfig(config *rest.Config, namespace string) (*action.Configuration, error) {
 
 	logFunc := func(format string, v ...interface{}) {
-		fmt.Sprintf(format, v)
+		h.logger.Debug(fmt.Sprintf(format, v...))
 	}
 
 	restClientGetter := h.newConfigFlags(config, namespace)
@@ -116,6 +121,29 @@ func (h *HelmV3Executor) GetManifest(releaseName, namespace string) (string, err
 	return release.Manifest, nil
 }
 
+// GetReleaseHistory returns all revisions of the provided release, sorted by their version
+func (h *HelmV3Executor) GetReleaseHistory(releaseName, namespace string) ([]*release.Release, error) {
+
+	config, err := h.getKubeRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := h.newActionConfig(config, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := action.NewHistory(cfg).Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("Error when querying the history of release %s in namespace %s: %s",
+			releaseName, namespace, err.Error())
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Version < history[j].Version
+	})
+	return history, nil
+}
+
 // UpgradeChart upgrades the provided chart and waits for all deployments
 func (h *HelmV3Executor) UpgradeChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) error {
 
@@ -174,6 +202,21 @@ func (h *HelmV3Executor) UpgradeChart(ch *chart.Chart, releaseName, namespace st
 	return nil
 }
 
+// RenderChart renders the templates of the chart with the provided values without accessing the cluster
+func (h *HelmV3Executor) RenderChart(ch *chart.Chart, releaseName, namespace string, vals map[string]interface{}) (string, error) {
+	iCli := action.NewInstall(&action.Configuration{})
+	iCli.DryRun = true
+	iCli.ClientOnly = true
+	iCli.Replace = true
+	iCli.ReleaseName = releaseName
+	iCli.Namespace = namespace
+	release, err := iCli.Run(ch, vals)
+	if err != nil {
+		return "", fmt.Errorf("Error when rendering chart %s in namespace %s: %s", releaseName, namespace, err.Error())
+	}
+	return release.Manifest, nil
+}
+
 func (h *HelmV3Executor) waitForDeploymentsOfHelmRelease(helmManifest string) error {
 	depls := GetDeployments(helmManifest)
 	for _, depl := range depls {
@@ -211,3 +254,81 @@ func (h *HelmV3Executor) UninstallRelease(releaseName, namespace string) error {
 	h.logger.Debug(fmt.Sprintf("Successfully uninstall Helm release %s in namespace %s", releaseName, namespace))
 	return nil
 }
+
+// RollbackRelease rolls back the release to the provided revision, or to the previous revision if the revision is 0,
+// and waits for all deployments. It returns the revision created by the rollback
+func (h *HelmV3Executor) RollbackRelease(releaseName, namespace string, revision int) (int, error) {
+
+	h.logger.Info(fmt.Sprintf("Start rolling back release %s in namespace %s", releaseName, namespace))
+	config, err := h.getKubeRestConfig()
+	if err != nil {
+		return 0, err
+	}
+	cfg, err := h.newActionConfig(config, namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	rCli := action.NewRollback(cfg)
+	rCli.Version = revision
+	rCli.Wait = true
+	rCli.Timeout = time.Minute * 3
+	if err := rCli.Run(releaseName); err != nil {
+		return 0, fmt.Errorf("Error when rolling back release %s in namespace %s: %s",
+			releaseName, namespace, err.Error())
+	}
+
+	release, err := action.NewGet(cfg).Run(releaseName)
+	if err != nil {
+		return 0, fmt.Errorf("Error when querying release %s in namespace %s: %s",
+			releaseName, namespace, err.Error())
+	}
+	if err := h.waitForDeploymentsOfHelmRelease(release.Manifest); err != nil {
+		return 0, err
+	}
+	h.logger.Info(fmt.Sprintf("Finished rolling back release %s in namespace %s to revision %d", releaseName, namespace, release.Version))
+	return release.Version, nil
+}
+
+// RestartRelease triggers a rolling restart of the deployments of the release and waits until they are rolled out.
+// The restart does not create a new revision, hence the current revision of the release is returned
+func (h *HelmV3Executor) RestartRelease(releaseName, namespace string) (int, error) {
+
+	h.logger.Info(fmt.Sprintf("Start restarting release %s in namespace %s", releaseName, namespace))
+	config, err := h.getKubeRestConfig()
+	if err != nil {
+		return 0, err
+	}
+	cfg, err := h.newActionConfig(config, namespace)
+	if err != nil {
+		return 0, err
+	}
+	release, err := action.NewGet(cfg).Run(releaseName)
+	if err != nil {
+		return 0, fmt.Errorf("Error when querying release %s in namespace %s: %s",
+			releaseName, namespace, err.Error())
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return 0, err
+	}
+
+	// same as kubectl rollout restart: changing the pod template rolls out new pods
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339))
+	for _, depl := range GetDeployments(release.Manifest) {
+		deplNamespace := depl.Namespace
+		if deplNamespace == "" {
+			deplNamespace = namespace
+		}
+		if _, err := clientset.AppsV1().Deployments(deplNamespace).Patch(context.TODO(), depl.Name, types.StrategicMergePatchType,
+			[]byte(patch), metav1.PatchOptions{}); err != nil {
+			return 0, fmt.Errorf("Error when restarting deployment %s in namespace %s: %s", depl.Name, deplNamespace, err.Error())
+		}
+	}
+	if err := h.waitForDeploymentsOfHelmRelease(release.Manifest); err != nil {
+		return 0, err
+	}
+	h.logger.Info(fmt.Sprintf("Finished restarting release %s in namespace %s", releaseName, namespace))
+	return release.Version, nil
+}
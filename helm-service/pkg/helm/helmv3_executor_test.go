@@ -0,0 +1,16 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmV3Executor_RenderChart(t *testing.T) {
+	ch := GetTestUserChart()
+	manifest, err := NewHelmV3Executor(nil, nil).RenderChart(&ch, "carts", "sockshop-dev", map[string]interface{}{"replicas": 3})
+	require.Nil(t, err)
+	assert.Contains(t, manifest, "kind: Service")
+	assert.Contains(t, manifest, "replicas: 3")
+}
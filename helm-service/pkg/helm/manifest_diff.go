@@ -0,0 +1,178 @@
+package helm
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ResourceAdded is the action of resources which are only contained in the target manifest
+	ResourceAdded = "added"
+	// ResourceRemoved is the action of resources which are only contained in the current manifest
+	ResourceRemoved = "removed"
+	// ResourceChanged is the action of resources whose content differs between the manifests
+	ResourceChanged = "changed"
+)
+
+// redactedValue replaces the values of Secrets in diffs
+const redactedValue = "<redacted>"
+
+// redactedChangedValue replaces the values of Secrets which differ from the current version of the Secret
+const redactedChangedValue = "<redacted, changed>"
+
+// ManifestDiff describes the changes of the resources of a Helm release
+type ManifestDiff struct {
+	Changes []ResourceChange `json:"changes"`
+}
+
+// ResourceChange describes the change of a single resource
+type ResourceChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Diff is the unified diff of the resource
+	Diff string `json:"diff,omitempty"`
+}
+
+// HasChanges returns whether any resource is changed
+func (d ManifestDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+type manifestResource struct {
+	kind    string
+	name    string
+	content string
+	obj     map[string]interface{}
+}
+
+func (r manifestResource) key() string {
+	return r.kind + "/" + r.name
+}
+
+// displayContent returns the content of the resource shown in a diff. The values in data and stringData of Secrets
+// are redacted. Values which differ from the given previous version of the Secret are marked as changed
+func (r manifestResource) displayContent(previous *manifestResource) string {
+	if r.kind != "Secret" {
+		return r.content
+	}
+	redacted := map[string]interface{}{}
+	for k, v := range r.obj {
+		redacted[k] = v
+	}
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := r.obj[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var previousValues map[string]interface{}
+		if previous != nil {
+			previousValues, _ = previous.obj[field].(map[string]interface{})
+		}
+		masked := map[string]interface{}{}
+		for key, value := range values {
+			masked[key] = redactedValue
+			if previousValue, ok := previousValues[key]; previous != nil && (!ok || !reflect.DeepEqual(previousValue, value)) {
+				masked[key] = redactedChangedValue
+			}
+		}
+		redacted[field] = masked
+	}
+	content, err := yaml.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// DiffManifests compares the resources of the current manifest of a release with the target manifest.
+// The changes are sorted by kind and name. The values of Secrets are not contained in the diffs
+func DiffManifests(current string, target string) ManifestDiff {
+	currentResources := splitManifest(current)
+	targetResources := splitManifest(target)
+
+	diff := ManifestDiff{Changes: []ResourceChange{}}
+	for key, targetResource := range targetResources {
+		currentResource, ok := currentResources[key]
+		if !ok {
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: targetResource.kind, Name: targetResource.name, Action: ResourceAdded,
+				Diff: unifiedDiff(key, "", targetResource.displayContent(nil))})
+			continue
+		}
+		if currentResource.content != targetResource.content {
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: targetResource.kind, Name: targetResource.name, Action: ResourceChanged,
+				Diff: unifiedDiff(key, currentResource.displayContent(nil), targetResource.displayContent(&currentResource))})
+		}
+	}
+	for key, currentResource := range currentResources {
+		if _, ok := targetResources[key]; !ok {
+			diff.Changes = append(diff.Changes, ResourceChange{Kind: currentResource.kind, Name: currentResource.name, Action: ResourceRemoved,
+				Diff: unifiedDiff(key, currentResource.displayContent(nil), "")})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Kind != diff.Changes[j].Kind {
+			return diff.Changes[i].Kind < diff.Changes[j].Kind
+		}
+		return diff.Changes[i].Name < diff.Changes[j].Name
+	})
+	return diff
+}
+
+// splitManifest returns the resources of the manifest by their kind and name. The content of the resources is
+// normalized, such that formatting and comments do not result in changes
+func splitManifest(manifest string) map[string]manifestResource {
+	resources := map[string]manifestResource{}
+	for _, document := range splitYAMLDocuments(manifest) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &obj); err != nil || obj == nil {
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		if kind == "" || name == "" {
+			continue
+		}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			continue
+		}
+		resource := manifestResource{kind: kind, name: name, content: string(content), obj: obj}
+		resources[resource.key()] = resource
+	}
+	return resources
+}
+
+// splitYAMLDocuments splits the manifest at the lines separating YAML documents
+func splitYAMLDocuments(manifest string) []string {
+	var documents []string
+	var current []string
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.HasPrefix(line, "---") {
+			documents = append(documents, strings.Join(current, "\n"))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	return append(documents, strings.Join(current, "\n"))
+}
+
+func unifiedDiff(key string, current string, target string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(target),
+		FromFile: "current/" + key,
+		ToFile:   "target/" + key,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
@@ -0,0 +1,125 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const currentManifest = `---
+# Source: carts/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: carts
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: carts-config
+`
+
+const targetManifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: carts
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: carts-secret
+`
+
+func TestDiffManifests(t *testing.T) {
+	diff := DiffManifests(currentManifest, targetManifest)
+
+	require.True(t, diff.HasChanges())
+	require.Equal(t, 3, len(diff.Changes))
+	assert.Equal(t, ResourceChange{Kind: "ConfigMap", Name: "carts-config", Action: ResourceRemoved}, withoutDiff(diff.Changes[0]))
+	assert.Equal(t, ResourceChange{Kind: "Deployment", Name: "carts", Action: ResourceChanged}, withoutDiff(diff.Changes[1]))
+	assert.Contains(t, diff.Changes[1].Diff, "-  replicas: 1")
+	assert.Contains(t, diff.Changes[1].Diff, "+  replicas: 2")
+	assert.Equal(t, ResourceChange{Kind: "Secret", Name: "carts-secret", Action: ResourceAdded}, withoutDiff(diff.Changes[2]))
+}
+
+func TestDiffManifests_NoChanges(t *testing.T) {
+	diff := DiffManifests(currentManifest, currentManifest)
+	assert.False(t, diff.HasChanges())
+}
+
+func TestDiffManifests_NewRelease(t *testing.T) {
+	diff := DiffManifests("", targetManifest)
+	require.Equal(t, 3, len(diff.Changes))
+	for _, change := range diff.Changes {
+		assert.Equal(t, ResourceAdded, change.Action)
+	}
+}
+
+func withoutDiff(change ResourceChange) ResourceChange {
+	change.Diff = ""
+	return change
+}
+
+func TestDiffManifests_RedactsSecrets(t *testing.T) {
+	current := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: carts-db
+data:
+  user: Y2FydHM=
+  password: c2VjcmV0
+`
+	target := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: carts-db
+  labels:
+    app: carts
+data:
+  user: Y2FydHM=
+  password: bmV3LXNlY3JldA==
+stringData:
+  token: my-token
+`
+	diff := DiffManifests(current, target)
+
+	require.Equal(t, 1, len(diff.Changes))
+	assert.Equal(t, ResourceChanged, diff.Changes[0].Action)
+	for _, value := range []string{"Y2FydHM=", "c2VjcmV0", "bmV3LXNlY3JldA==", "my-token"} {
+		assert.NotContains(t, diff.Changes[0].Diff, value)
+	}
+	assert.Contains(t, diff.Changes[0].Diff, "+    app: carts")
+	assert.Contains(t, diff.Changes[0].Diff, "-  password: <redacted>")
+	assert.Contains(t, diff.Changes[0].Diff, "+  password: <redacted, changed>")
+	assert.Contains(t, diff.Changes[0].Diff, "   user: <redacted>")
+	assert.Contains(t, diff.Changes[0].Diff, "+  token: <redacted, changed>")
+
+	diff = DiffManifests("", target)
+	require.Equal(t, 1, len(diff.Changes))
+	assert.NotContains(t, diff.Changes[0].Diff, "my-token")
+	assert.Contains(t, diff.Changes[0].Diff, "+  token: <redacted>")
+}
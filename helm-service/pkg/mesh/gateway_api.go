@@ -0,0 +1,97 @@
+package mesh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keptn/keptn/helm-service/pkg/apis/gateway/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// GatewayAPIMesh is an implementation of interface Mesh using Gateway API HTTPRoutes
+type GatewayAPIMesh struct {
+	gateway v1alpha2.ParentReference
+}
+
+// NewGatewayAPIMesh generates a new Gateway API mesh attaching its routes to the given gateway, e.g. gateway-system/public-gateway
+func NewGatewayAPIMesh(gateway string) *GatewayAPIMesh {
+	parentRef := v1alpha2.ParentReference{Name: gateway}
+	if idx := strings.Index(gateway, "/"); idx >= 0 {
+		parentRef = v1alpha2.ParentReference{Namespace: gateway[:idx], Name: gateway[idx+1:]}
+	}
+	return &GatewayAPIMesh{gateway: parentRef}
+}
+
+// GenerateDestinationRule returns nil, because the Gateway API does not require destination rules
+func (*GatewayAPIMesh) GenerateDestinationRule(name string, host string) ([]byte, error) {
+	return nil, nil
+}
+
+// GenerateVirtualService generates an HTTPRoute which routes the traffic of the public hosts to the destinations
+func (m *GatewayAPIMesh) GenerateVirtualService(name string, gateways []string, hosts []string, httpRouteDestinations []HTTPRouteDestination) ([]byte, error) {
+	backendRefs := []v1alpha2.HTTPBackendRef{}
+	for _, httpRouteDst := range httpRouteDestinations {
+		backendRef := v1alpha2.HTTPBackendRef{Name: getServiceName(httpRouteDst.Host)}
+		if httpRouteDst.Port > 0 {
+			port := httpRouteDst.Port
+			backendRef.Port = &port
+		}
+		// a single backend receives all traffic
+		if len(httpRouteDestinations) > 1 {
+			weight := httpRouteDst.Weight
+			backendRef.Weight = &weight
+		}
+		backendRefs = append(backendRefs, backendRef)
+	}
+
+	spec := v1alpha2.HTTPRouteSpec{
+		ParentRefs: []v1alpha2.ParentReference{m.gateway},
+		Hostnames:  getExternalHosts(hosts),
+		Rules:      []v1alpha2.HTTPRouteRule{{BackendRefs: backendRefs}},
+	}
+	route := v1alpha2.HTTPRoute{TypeMeta: metav1.TypeMeta{Kind: "HTTPRoute", APIVersion: "gateway.networking.k8s.io/v1alpha2"},
+		ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	return yaml.Marshal(route)
+}
+
+// UpdateWeights returns an HTTPRoute with updated weights
+func (*GatewayAPIMesh) UpdateWeights(httpRoute []byte, canaryWeight int32) ([]byte, error) {
+	route := v1alpha2.HTTPRoute{}
+	if err := yaml.Unmarshal(httpRoute, &route); err != nil {
+		return nil, err
+	}
+
+	primaryWeight, err := getPrimaryWeight(canaryWeight)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range route.Spec.Rules {
+		for i, backendRef := range rule.BackendRefs {
+			if !strings.HasPrefix(backendRef.Name, route.ObjectMeta.Name) {
+				return nil, fmt.Errorf("Cannot update HTTPRoute because backend has unexpected name %s", backendRef.Name)
+			}
+			if strings.HasPrefix(backendRef.Name, route.ObjectMeta.Name+"-canary") {
+				weight := canaryWeight
+				rule.BackendRefs[i].Weight = &weight
+			}
+			if strings.HasPrefix(backendRef.Name, route.ObjectMeta.Name+"-primary") {
+				weight := primaryWeight
+				rule.BackendRefs[i].Weight = &weight
+			}
+		}
+	}
+
+	return yaml.Marshal(route)
+}
+
+// GetDestinationRuleSuffix returns the file name suffix of destination rules, which are not generated for the Gateway API
+func (*GatewayAPIMesh) GetDestinationRuleSuffix() string {
+	return "-gateway-destinationrule.yaml"
+}
+
+// GetVirtualServiceSuffix returns the file name suffix of HTTPRoutes
+func (*GatewayAPIMesh) GetVirtualServiceSuffix() string {
+	return "-gateway-httproute.yaml"
+}
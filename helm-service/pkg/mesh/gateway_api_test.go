@@ -0,0 +1,71 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/keptn/keptn/helm-service/pkg/objectutils"
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewayAPIMesh_HTTPRoute(t *testing.T) {
+	routeDestinations := []HTTPRouteDestination{{Host: "carts-canary.sockshop-dev.svc.cluster.local", Weight: 0, Port: 8080},
+		{Host: "carts-primary.sockshop-dev.svc.cluster.local", Weight: 100, Port: 8080}}
+
+	gatewayMesh := NewGatewayAPIMesh("gateway-system/public-gateway")
+	data, err := gatewayMesh.GenerateVirtualService("carts", []string{"public-gateway.istio-system", "mesh"}, []string{"carts.sockshop-dev.35.226.86.78.xip.io", "carts"}, routeDestinations)
+	assert.Nil(t, err)
+
+	data, err = gatewayMesh.UpdateWeights(data, 10)
+	assert.Nil(t, err)
+	jsonData, err := objectutils.ToJSON(data)
+	assert.Nil(t, err)
+
+	ja := jsonassert.New(t)
+	ja.Assertf(string(jsonData), `
+    {
+		"apiVersion": "gateway.networking.k8s.io/v1alpha2",
+		"kind": "HTTPRoute",
+		"metadata": {
+		  "name": "carts",
+		  "creationTimestamp": null
+		},
+		"spec": {
+		  "parentRefs": [
+			{
+			  "name": "public-gateway",
+			  "namespace": "gateway-system"
+			}
+		  ],
+		  "hostnames": [
+			"carts.sockshop-dev.35.226.86.78.xip.io"
+		  ],
+		  "rules": [
+			{
+			  "backendRefs": [
+				{
+				  "name": "carts-canary",
+				  "port": 8080,
+				  "weight": 10
+				},
+				{
+				  "name": "carts-primary",
+				  "port": 8080,
+				  "weight": 90
+				}
+			  ]
+			}
+		  ]
+		}
+	  }`)
+}
+
+func TestGatewayAPIMesh_InvalidWeight(t *testing.T) {
+	routeDestinations := []HTTPRouteDestination{{Host: "carts-canary.sockshop-dev.svc.cluster.local"}, {Host: "carts-primary.sockshop-dev.svc.cluster.local", Weight: 100}}
+	gatewayMesh := NewGatewayAPIMesh("public-gateway")
+	data, err := gatewayMesh.GenerateVirtualService("carts", nil, []string{"carts.sockshop-dev.35.226.86.78.xip.io"}, routeDestinations)
+	assert.Nil(t, err)
+
+	_, err = gatewayMesh.UpdateWeights(data, 110)
+	assert.NotNil(t, err)
+}
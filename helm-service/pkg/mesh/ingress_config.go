@@ -65,3 +65,24 @@ func GetPublicDeploymentHostNameTemplate() string {
 	}
 	return strings.ToUpper(hostNameTemplate)
 }
+
+// GetMeshProvider returns the mesh provider used for projects without a mesh configuration
+func GetMeshProvider() string {
+	if os.Getenv("MESH_PROVIDER") != "" {
+		return strings.ToLower(os.Getenv("MESH_PROVIDER"))
+	}
+	return ProviderIstio
+}
+
+// GetGatewayAPIGateway returns the gateway HTTPRoutes are attached to if the Gateway API is used
+func GetGatewayAPIGateway() string {
+	return os.Getenv("GATEWAY_API_GATEWAY")
+}
+
+// GetIngressClass returns the ingress class of the ingresses generated for the NGINX ingress controller
+func GetIngressClass() string {
+	if os.Getenv("INGRESS_CLASS") != "" {
+		return os.Getenv("INGRESS_CLASS")
+	}
+	return "nginx"
+}
@@ -1,8 +1,17 @@
 package mesh
 
+import (
+	"errors"
+	"strings"
+)
+
+var errInvalidCanaryWeight = errors.New("Invalid canary weight")
+
 // Mesh abstracts the underlying mesh router
 type Mesh interface {
+	// GenerateDestinationRule generates the routing resource of a single service. It returns nil if the mesh does not require one
 	GenerateDestinationRule(name string, host string) ([]byte, error)
+	// GenerateVirtualService generates the resource routing the traffic to the destinations. It returns nil if the mesh does not require one
 	GenerateVirtualService(name string, gateways []string, hosts []string, httpRouteDestinations []HTTPRouteDestination) ([]byte, error)
 	UpdateWeights(virtualService []byte, canaryWeight int32) ([]byte, error)
 	GetDestinationRuleSuffix() string
@@ -13,4 +22,31 @@ type Mesh interface {
 type HTTPRouteDestination struct {
 	Host   string
 	Weight int32
+	// Port is the port of the destination service. It is only used by meshes referencing services by name and port
+	Port int32
+}
+
+// getServiceName returns the name of the service of a host like carts-primary.sockshop-dev.svc.cluster.local
+func getServiceName(host string) string {
+	return strings.Split(host, ".")[0]
+}
+
+// getExternalHosts returns the fully qualified hosts, i.e. omits the names of services
+func getExternalHosts(hosts []string) []string {
+	externalHosts := []string{}
+	for _, host := range hosts {
+		if strings.Contains(host, ".") {
+			externalHosts = append(externalHosts, host)
+		}
+	}
+	return externalHosts
+}
+
+// getPrimaryWeight returns the weight of the primary for the given canary weight
+func getPrimaryWeight(canaryWeight int32) (int32, error) {
+	primaryWeight := 100 - canaryWeight
+	if primaryWeight < 0 || canaryWeight < 0 {
+		return 0, errInvalidCanaryWeight
+	}
+	return primaryWeight, nil
 }
@@ -0,0 +1,116 @@
+package mesh
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	nginxCanaryAnnotation       = "nginx.ingress.kubernetes.io/canary"
+	nginxCanaryWeightAnnotation = "nginx.ingress.kubernetes.io/canary-weight"
+	defaultIngressPort          = 80
+	yamlDocumentSeparator       = "---\n"
+)
+
+// NginxMesh is an implementation of interface Mesh using the canary annotations of the NGINX ingress controller
+type NginxMesh struct {
+	ingressClass string
+}
+
+// NewNginxMesh generates a new NGINX mesh creating ingresses of the given class
+func NewNginxMesh(ingressClass string) *NginxMesh {
+	return &NginxMesh{ingressClass: ingressClass}
+}
+
+// GenerateDestinationRule returns nil, because the NGINX ingress controller does not require destination rules
+func (*NginxMesh) GenerateDestinationRule(name string, host string) ([]byte, error) {
+	return nil, nil
+}
+
+// GenerateVirtualService generates an ingress for the public hosts of each destination. The ingress of the canary
+// destination is annotated as canary ingress, which receives the weight of the canary
+func (m *NginxMesh) GenerateVirtualService(name string, gateways []string, hosts []string, httpRouteDestinations []HTTPRouteDestination) ([]byte, error) {
+	var documents [][]byte
+	for _, httpRouteDst := range httpRouteDestinations {
+		serviceName := getServiceName(httpRouteDst.Host)
+		ingress := m.generateIngress(name, serviceName, getExternalHosts(hosts), httpRouteDst.Port)
+		if strings.HasPrefix(serviceName, name+"-canary") {
+			ingress.Name = serviceName
+			ingress.Annotations = map[string]string{
+				nginxCanaryAnnotation:       "true",
+				nginxCanaryWeightAnnotation: strconv.Itoa(int(httpRouteDst.Weight)),
+			}
+		}
+		data, err := yaml.Marshal(ingress)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, data)
+	}
+	return bytes.Join(documents, []byte(yamlDocumentSeparator)), nil
+}
+
+func (m *NginxMesh) generateIngress(name string, serviceName string, hosts []string, port int32) networkingv1.Ingress {
+	if port == 0 {
+		port = defaultIngressPort
+	}
+	pathType := networkingv1.PathTypePrefix
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: serviceName, Port: networkingv1.ServiceBackendPort{Number: port}},
+	}
+	rules := []networkingv1.IngressRule{}
+	for _, host := range hosts {
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{Path: "/", PathType: &pathType, Backend: backend}},
+			}},
+		})
+	}
+	ingressClass := m.ingressClass
+	return networkingv1.Ingress{TypeMeta: metav1.TypeMeta{Kind: "Ingress", APIVersion: "networking.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       networkingv1.IngressSpec{IngressClassName: &ingressClass, Rules: rules}}
+}
+
+// UpdateWeights returns the ingresses with an updated weight of the canary ingress. The primary ingress receives the remaining traffic
+func (*NginxMesh) UpdateWeights(ingresses []byte, canaryWeight int32) ([]byte, error) {
+	if _, err := getPrimaryWeight(canaryWeight); err != nil {
+		return nil, err
+	}
+
+	var documents [][]byte
+	for _, document := range bytes.Split(ingresses, []byte("\n"+yamlDocumentSeparator)) {
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		ingress := networkingv1.Ingress{}
+		if err := yaml.Unmarshal(document, &ingress); err != nil {
+			return nil, err
+		}
+		if ingress.Annotations[nginxCanaryAnnotation] == "true" {
+			ingress.Annotations[nginxCanaryWeightAnnotation] = strconv.Itoa(int(canaryWeight))
+		}
+		data, err := yaml.Marshal(ingress)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, data)
+	}
+	return bytes.Join(documents, []byte(yamlDocumentSeparator)), nil
+}
+
+// GetDestinationRuleSuffix returns the file name suffix of destination rules, which are not generated for NGINX
+func (*NginxMesh) GetDestinationRuleSuffix() string {
+	return "-nginx-destinationrule.yaml"
+}
+
+// GetVirtualServiceSuffix returns the file name suffix of ingresses
+func (*NginxMesh) GetVirtualServiceSuffix() string {
+	return "-nginx-ingress.yaml"
+}
@@ -0,0 +1,108 @@
+package mesh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/keptn/keptn/helm-service/pkg/objectutils"
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNginxMesh_Ingresses(t *testing.T) {
+	routeDestinations := []HTTPRouteDestination{{Host: "carts-canary.sockshop-dev.svc.cluster.local", Weight: 0, Port: 8080},
+		{Host: "carts-primary.sockshop-dev.svc.cluster.local", Weight: 100, Port: 8080}}
+
+	nginxMesh := NewNginxMesh("nginx")
+	data, err := nginxMesh.GenerateVirtualService("carts", nil, []string{"carts.sockshop-dev.35.226.86.78.xip.io", "carts"}, routeDestinations)
+	assert.Nil(t, err)
+
+	data, err = nginxMesh.UpdateWeights(data, 50)
+	assert.Nil(t, err)
+	documents := bytes.Split(data, []byte("\n---\n"))
+	require.Equal(t, 2, len(documents))
+
+	canaryIngress, err := objectutils.ToJSON(documents[0])
+	assert.Nil(t, err)
+	ja := jsonassert.New(t)
+	ja.Assertf(string(canaryIngress), `
+    {
+		"apiVersion": "networking.k8s.io/v1",
+		"kind": "Ingress",
+		"metadata": {
+		  "name": "carts-canary",
+		  "creationTimestamp": null,
+		  "annotations": {
+			"nginx.ingress.kubernetes.io/canary": "true",
+			"nginx.ingress.kubernetes.io/canary-weight": "50"
+		  }
+		},
+		"spec": {
+		  "ingressClassName": "nginx",
+		  "rules": [
+			{
+			  "host": "carts.sockshop-dev.35.226.86.78.xip.io",
+			  "http": {
+				"paths": [
+				  {
+					"path": "/",
+					"pathType": "Prefix",
+					"backend": {
+					  "service": {
+						"name": "carts-canary",
+						"port": {
+						  "number": 8080
+						}
+					  }
+					}
+				  }
+				]
+			  }
+			}
+		  ]
+		},
+		"status": {
+		  "loadBalancer": {}
+		}
+	  }`)
+
+	primaryIngress, err := objectutils.ToJSON(documents[1])
+	assert.Nil(t, err)
+	ja.Assertf(string(primaryIngress), `
+    {
+		"apiVersion": "networking.k8s.io/v1",
+		"kind": "Ingress",
+		"metadata": {
+		  "name": "carts",
+		  "creationTimestamp": null
+		},
+		"spec": {
+		  "ingressClassName": "nginx",
+		  "rules": [
+			{
+			  "host": "carts.sockshop-dev.35.226.86.78.xip.io",
+			  "http": {
+				"paths": [
+				  {
+					"path": "/",
+					"pathType": "Prefix",
+					"backend": {
+					  "service": {
+						"name": "carts-primary",
+						"port": {
+						  "number": 8080
+						}
+					  }
+					}
+				  }
+				]
+			  }
+			}
+		  ]
+		},
+		"status": {
+		  "loadBalancer": {}
+		}
+	  }`)
+}
@@ -0,0 +1,80 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/keptn/helm-service/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigResourceURI is the project resource selecting the mesh of a project
+const ConfigResourceURI = "helm/mesh.yaml"
+
+const (
+	// ProviderIstio routes the traffic using Istio VirtualServices and DestinationRules
+	ProviderIstio = "istio"
+	// ProviderSMI routes the traffic using SMI TrafficSplits, e.g. for Linkerd
+	ProviderSMI = "smi"
+	// ProviderGatewayAPI routes the traffic using Gateway API HTTPRoutes
+	ProviderGatewayAPI = "gatewayapi"
+	// ProviderNginx routes the traffic using the canary annotations of the NGINX ingress controller
+	ProviderNginx = "nginx"
+)
+
+// Config selects the mesh of a project
+type Config struct {
+	Provider string `json:"provider"`
+	// Gateway is the gateway HTTPRoutes are attached to, e.g. gateway-system/public-gateway. It is required for the Gateway API
+	Gateway string `json:"gateway,omitempty"`
+	// IngressClass is the class of the ingresses generated for the NGINX ingress controller
+	IngressClass string `json:"ingressClass,omitempty"`
+}
+
+// NewMesh creates the mesh of the config. Empty properties are set to the values of the environment
+func NewMesh(config Config) (Mesh, error) {
+	if config.Provider == "" {
+		config.Provider = GetMeshProvider()
+	}
+	switch config.Provider {
+	case ProviderIstio:
+		return NewIstioMesh(), nil
+	case ProviderSMI, "linkerd":
+		return NewSMIMesh(), nil
+	case ProviderGatewayAPI:
+		if config.Gateway == "" {
+			config.Gateway = GetGatewayAPIGateway()
+		}
+		if config.Gateway == "" {
+			return nil, errors.New("the Gateway API provider requires a gateway")
+		}
+		return NewGatewayAPIMesh(config.Gateway), nil
+	case ProviderNginx:
+		if config.IngressClass == "" {
+			config.IngressClass = GetIngressClass()
+		}
+		return NewNginxMesh(config.IngressClass), nil
+	default:
+		return nil, fmt.Errorf("unsupported mesh provider %s", config.Provider)
+	}
+}
+
+// GetProjectMesh creates the mesh configured in the mesh.yaml resource of the project.
+// Projects without this resource use the mesh provider of the environment
+func GetProjectMesh(resourceHandler types.IResourceHandler, project string) (Mesh, error) {
+	config := Config{}
+	if project != "" {
+		resourceScope := *keptnapi.NewResourceScope().Project(project).Resource(ConfigResourceURI)
+		resource, err := resourceHandler.GetResource(resourceScope)
+		if err != nil && err != keptnapi.ResourceNotFoundError {
+			return nil, fmt.Errorf("could not fetch mesh configuration of project %s: %w", project, err)
+		}
+		if err == nil && resource != nil {
+			if err := yaml.Unmarshal([]byte(resource.ResourceContent), &config); err != nil {
+				return nil, fmt.Errorf("could not parse %s of project %s: %w", ConfigResourceURI, project, err)
+			}
+		}
+	}
+	return NewMesh(config)
+}
@@ -0,0 +1,61 @@
+package mesh_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/mesh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMesh(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  mesh.Config
+		want    mesh.Mesh
+		wantErr bool
+	}{
+		{name: "default", config: mesh.Config{}, want: mesh.NewIstioMesh()},
+		{name: "smi", config: mesh.Config{Provider: mesh.ProviderSMI}, want: mesh.NewSMIMesh()},
+		{name: "gateway api", config: mesh.Config{Provider: mesh.ProviderGatewayAPI, Gateway: "gateway-system/public-gateway"}, want: mesh.NewGatewayAPIMesh("gateway-system/public-gateway")},
+		{name: "gateway api without gateway", config: mesh.Config{Provider: mesh.ProviderGatewayAPI}, wantErr: true},
+		{name: "nginx", config: mesh.Config{Provider: mesh.ProviderNginx}, want: mesh.NewNginxMesh("nginx")},
+		{name: "unsupported", config: mesh.Config{Provider: "consul"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mesh.NewMesh(tt.config)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetProjectMesh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	resourceHandler := mocks.NewMockIResourceHandler(ctrl)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any()).Return(&models.Resource{ResourceContent: "provider: nginx\ningressClass: public"}, nil)
+	got, err := mesh.GetProjectMesh(resourceHandler, "sockshop")
+	require.Nil(t, err)
+	assert.Equal(t, mesh.NewNginxMesh("public"), got)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any()).Return(nil, keptnapi.ResourceNotFoundError)
+	got, err = mesh.GetProjectMesh(resourceHandler, "sockshop")
+	require.Nil(t, err)
+	assert.Equal(t, mesh.NewIstioMesh(), got)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any()).Return(nil, errors.New("oops"))
+	_, err = mesh.GetProjectMesh(resourceHandler, "sockshop")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,78 @@
+package mesh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keptn/keptn/helm-service/pkg/apis/split/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// SMIMesh is an implementation of interface Mesh using SMI TrafficSplits, e.g. for Linkerd
+type SMIMesh struct {
+}
+
+// NewSMIMesh generates a new SMI mesh
+func NewSMIMesh() *SMIMesh {
+	return &SMIMesh{}
+}
+
+// GenerateDestinationRule returns nil, because SMI does not require destination rules
+func (*SMIMesh) GenerateDestinationRule(name string, host string) ([]byte, error) {
+	return nil, nil
+}
+
+// GenerateVirtualService generates a TrafficSplit which splits the traffic of the service between the destinations.
+// If there is only one destination, no TrafficSplit is required and nil is returned
+func (*SMIMesh) GenerateVirtualService(name string, gateways []string, hosts []string, httpRouteDestinations []HTTPRouteDestination) ([]byte, error) {
+	if len(httpRouteDestinations) < 2 {
+		return nil, nil
+	}
+
+	backends := []v1alpha2.TrafficSplitBackend{}
+	for _, httpRouteDst := range httpRouteDestinations {
+		backends = append(backends, v1alpha2.TrafficSplitBackend{Service: getServiceName(httpRouteDst.Host), Weight: int(httpRouteDst.Weight)})
+	}
+
+	ts := v1alpha2.TrafficSplit{TypeMeta: metav1.TypeMeta{Kind: "TrafficSplit", APIVersion: "split.smi-spec.io/v1alpha2"},
+		ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1alpha2.TrafficSplitSpec{Service: name, Backends: backends}}
+	return yaml.Marshal(ts)
+}
+
+// UpdateWeights returns a TrafficSplit with updated weights
+func (*SMIMesh) UpdateWeights(trafficSplit []byte, canaryWeight int32) ([]byte, error) {
+	ts := v1alpha2.TrafficSplit{}
+	if err := yaml.Unmarshal(trafficSplit, &ts); err != nil {
+		return nil, err
+	}
+
+	primaryWeight, err := getPrimaryWeight(canaryWeight)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, backend := range ts.Spec.Backends {
+		if !strings.HasPrefix(backend.Service, ts.ObjectMeta.Name) {
+			return nil, fmt.Errorf("Cannot update TrafficSplit because backend has unexpected name %s", backend.Service)
+		}
+		if strings.HasPrefix(backend.Service, ts.ObjectMeta.Name+"-canary") {
+			ts.Spec.Backends[i].Weight = int(canaryWeight)
+		}
+		if strings.HasPrefix(backend.Service, ts.ObjectMeta.Name+"-primary") {
+			ts.Spec.Backends[i].Weight = int(primaryWeight)
+		}
+	}
+
+	return yaml.Marshal(ts)
+}
+
+// GetDestinationRuleSuffix returns the file name suffix of destination rules, which are not generated for SMI
+func (*SMIMesh) GetDestinationRuleSuffix() string {
+	return "-smi-destinationrule.yaml"
+}
+
+// GetVirtualServiceSuffix returns the file name suffix of traffic splits
+func (*SMIMesh) GetVirtualServiceSuffix() string {
+	return "-smi-trafficsplit.yaml"
+}
@@ -0,0 +1,58 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/keptn/keptn/helm-service/pkg/objectutils"
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMIMesh_TrafficSplit(t *testing.T) {
+	routeDestinations := []HTTPRouteDestination{{Host: "carts-canary.sockshop-dev.svc.cluster.local", Weight: 0},
+		{Host: "carts-primary.sockshop-dev.svc.cluster.local", Weight: 100}}
+
+	smiMesh := NewSMIMesh()
+	data, err := smiMesh.GenerateVirtualService("carts", []string{"public-gateway.istio-system"}, []string{"carts.sockshop-dev.35.226.86.78.xip.io"}, routeDestinations)
+	assert.Nil(t, err)
+
+	data, err = smiMesh.UpdateWeights(data, 25)
+	assert.Nil(t, err)
+	jsonData, err := objectutils.ToJSON(data)
+	assert.Nil(t, err)
+
+	ja := jsonassert.New(t)
+	ja.Assertf(string(jsonData), `
+    {
+		"apiVersion": "split.smi-spec.io/v1alpha2",
+		"kind": "TrafficSplit",
+		"metadata": {
+		  "name": "carts",
+		  "creationTimestamp": null
+		},
+		"spec": {
+		  "service": "carts",
+		  "backends": [
+			{
+			  "service": "carts-canary",
+			  "weight": 25
+			},
+			{
+			  "service": "carts-primary",
+			  "weight": 75
+			}
+		  ]
+		}
+	  }`)
+}
+
+func TestSMIMesh_NoTrafficSplitForSingleDestination(t *testing.T) {
+	smiMesh := NewSMIMesh()
+	data, err := smiMesh.GenerateVirtualService("carts", nil, []string{"carts"}, []HTTPRouteDestination{{Host: "carts.sockshop-dev.svc.cluster.local"}})
+	assert.Nil(t, err)
+	assert.Nil(t, data)
+
+	data, err = smiMesh.GenerateDestinationRule("carts", "carts.sockshop-dev.svc.cluster.local")
+	assert.Nil(t, err)
+	assert.Nil(t, data)
+}
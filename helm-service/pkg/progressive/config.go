@@ -0,0 +1,148 @@
+package progressive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// ConfigResourceURI is the resource which enables the progressive release of a service
+const ConfigResourceURI = "helm/progressive.yaml"
+
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// defaultSignalTimeout is used if a config waits for signals without a signalTimeout, so that a release never waits forever
+const defaultSignalTimeout = time.Hour
+
+// Config describes the steps in which the traffic is shifted to the canary during a release
+type Config struct {
+	// Steps contains the canary weights in percent. The weights have to increase and the last one has to be 100
+	Steps []int32 `json:"steps"`
+	// Interval is the duration to wait after each step, e.g. 5m
+	Interval string `json:"interval,omitempty"`
+	// WaitForSignal makes each step wait for a sh.keptn.event.canary.signal event instead of the interval
+	WaitForSignal bool `json:"waitForSignal,omitempty"`
+	// SignalTimeout is the duration after which the canary is rolled back if no signal has been received, 1h by default
+	SignalTimeout string `json:"signalTimeout,omitempty"`
+	// HealthCheck is executed after each step. If it fails, the canary is rolled back
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+}
+
+// HealthCheck is an HTTP endpoint which has to respond with a 2xx status code while the canary is healthy
+type HealthCheck struct {
+	URL     string `json:"url"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Parse parses and validates the content of the progressive.yaml resource
+func Parse(content []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", ConfigResourceURI, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ConfigResourceURI, err)
+	}
+	return config, nil
+}
+
+// Validate checks whether the steps and durations of the config are valid
+func (c *Config) Validate() error {
+	if len(c.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	var previous int32
+	for _, weight := range c.Steps {
+		if weight <= previous || weight > 100 {
+			return fmt.Errorf("step %d%% is invalid: the weights have to increase and must not exceed 100", weight)
+		}
+		previous = weight
+	}
+	if previous != 100 {
+		return errors.New("the last step has to shift 100% of the traffic to the canary")
+	}
+	if _, err := parseDuration(c.Interval); err != nil {
+		return fmt.Errorf("invalid interval: %w", err)
+	}
+	if _, err := parseDuration(c.SignalTimeout); err != nil {
+		return fmt.Errorf("invalid signalTimeout: %w", err)
+	}
+	if c.HealthCheck != nil {
+		if c.HealthCheck.URL == "" {
+			return errors.New("the health check requires a url")
+		}
+		if _, err := parseDuration(c.HealthCheck.Timeout); err != nil {
+			return fmt.Errorf("invalid health check timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetInterval returns the duration to wait after each step, or 0 if the steps are not delayed
+func (c *Config) GetInterval() time.Duration {
+	d, _ := parseDuration(c.Interval)
+	return d
+}
+
+// GetSignalTimeout returns the duration to wait for a signal, or 0 if the steps do not wait for signals
+func (c *Config) GetSignalTimeout() time.Duration {
+	if !c.WaitForSignal {
+		return 0
+	}
+	if d, _ := parseDuration(c.SignalTimeout); d > 0 {
+		return d
+	}
+	return defaultSignalTimeout
+}
+
+// GetTimeout returns the timeout of the health check request
+func (h *HealthCheck) GetTimeout() time.Duration {
+	if d, _ := parseDuration(h.Timeout); d > 0 {
+		return d
+	}
+	return defaultHealthCheckTimeout
+}
+
+// Await blocks until the next step may be executed. A step either waits for the interval, which can be ended early
+// by a proceed signal, or for a signal if WaitForSignal is set. An abort signal or a missing signal returns an error.
+// The interval and the signal timeout are counted from the given start of the wait, so that a release resumed after a
+// restart only waits for the remaining time
+func (c *Config) Await(signals <-chan SignalAction, since time.Time) error {
+	var timeout <-chan time.Time
+	if c.WaitForSignal {
+		timeout = time.After(c.GetSignalTimeout() - time.Since(since))
+	} else if d := c.GetInterval(); d > 0 {
+		timeout = time.After(d - time.Since(since))
+	} else {
+		return nil
+	}
+
+	select {
+	case action := <-signals:
+		if action == SignalAbort {
+			return errors.New("the release has been aborted by a signal")
+		}
+		return nil
+	case <-timeout:
+		if c.WaitForSignal {
+			return fmt.Errorf("no signal has been received within %s", c.GetSignalTimeout())
+		}
+		return nil
+	}
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %s must not be negative", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,85 @@
+package progressive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(`
+steps: [10, 25, 50, 100]
+interval: 5m
+healthCheck:
+  url: http://carts.sockshop-production/health
+`))
+	require.Nil(t, err)
+	assert.Equal(t, []int32{10, 25, 50, 100}, config.Steps)
+	assert.Equal(t, 5*time.Minute, config.GetInterval())
+	assert.Equal(t, time.Duration(0), config.GetSignalTimeout())
+	assert.Equal(t, defaultHealthCheckTimeout, config.HealthCheck.GetTimeout())
+}
+
+func TestConfig_GetSignalTimeout(t *testing.T) {
+	assert.Equal(t, time.Duration(0), (&Config{SignalTimeout: "5m"}).GetSignalTimeout())
+	assert.Equal(t, defaultSignalTimeout, (&Config{WaitForSignal: true}).GetSignalTimeout())
+	assert.Equal(t, 5*time.Minute, (&Config{WaitForSignal: true, SignalTimeout: "5m"}).GetSignalTimeout())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "no steps", content: `interval: 5m`},
+		{name: "decreasing steps", content: `steps: [50, 25, 100]`},
+		{name: "last step below 100", content: `steps: [10, 50]`},
+		{name: "step above 100", content: `steps: [50, 150]`},
+		{name: "invalid interval", content: "steps: [100]\ninterval: five minutes"},
+		{name: "negative signal timeout", content: "steps: [100]\nsignalTimeout: -1m"},
+		{name: "health check without url", content: "steps: [100]\nhealthCheck:\n  timeout: 1s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestConfig_Await(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		signal  SignalAction
+		wantErr bool
+	}{
+		{name: "no delay", config: Config{}},
+		{name: "interval", config: Config{Interval: "10ms"}},
+		{name: "interval ended by proceed signal", config: Config{Interval: "1h"}, signal: SignalProceed},
+		{name: "interval aborted by signal", config: Config{Interval: "1h"}, signal: SignalAbort, wantErr: true},
+		{name: "proceed signal", config: Config{WaitForSignal: true}, signal: SignalProceed},
+		{name: "abort signal", config: Config{WaitForSignal: true}, signal: SignalAbort, wantErr: true},
+		{name: "signal timeout", config: Config{WaitForSignal: true, SignalTimeout: "10ms"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := make(chan SignalAction, 1)
+			if tt.signal != "" {
+				signals <- tt.signal
+			}
+			err := tt.config.Await(signals, time.Now())
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConfig_AwaitRemainingTime(t *testing.T) {
+	config := Config{Interval: "1h"}
+	require.Nil(t, config.Await(nil, time.Now().Add(-time.Hour)))
+
+	config = Config{WaitForSignal: true, SignalTimeout: "1h"}
+	require.NotNil(t, config.Await(nil, time.Now().Add(-time.Hour)))
+}
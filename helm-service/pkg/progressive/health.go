@@ -0,0 +1,33 @@
+package progressive
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HealthChecker checks whether the canary is healthy
+type HealthChecker interface {
+	Check(check HealthCheck) error
+}
+
+// HTTPHealthChecker executes health checks by sending a GET request to their URL
+type HTTPHealthChecker struct{}
+
+// NewHTTPHealthChecker creates a HTTPHealthChecker
+func NewHTTPHealthChecker() *HTTPHealthChecker {
+	return &HTTPHealthChecker{}
+}
+
+// Check returns an error if the request fails or does not respond with a 2xx status code
+func (HTTPHealthChecker) Check(check HealthCheck) error {
+	client := &http.Client{Timeout: check.GetTimeout()}
+	resp, err := client.Get(check.URL)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health check failed: %s responded with status code %d", check.URL, resp.StatusCode)
+	}
+	return nil
+}
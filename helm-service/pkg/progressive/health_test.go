@@ -0,0 +1,25 @@
+package progressive
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHealthChecker_Check(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	checker := NewHTTPHealthChecker()
+	assert.Nil(t, checker.Check(HealthCheck{URL: ts.URL}))
+
+	healthy = false
+	assert.NotNil(t, checker.Check(HealthCheck{URL: ts.URL}))
+}
@@ -0,0 +1,44 @@
+package progressive
+
+import (
+	"net/url"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/pkg/types"
+)
+
+// ConfigProvider retrieves the progressive release config of a service
+type ConfigProvider interface {
+	GetConfig(event keptnv2.EventData, commitID string) (*Config, error)
+}
+
+// ResourceConfigProvider reads the config from the progressive.yaml resource of the service
+type ResourceConfigProvider struct {
+	resourceHandler types.IResourceHandler
+}
+
+// NewResourceConfigProvider creates a ResourceConfigProvider
+func NewResourceConfigProvider(resourceHandler types.IResourceHandler) *ResourceConfigProvider {
+	return &ResourceConfigProvider{resourceHandler: resourceHandler}
+}
+
+// GetConfig returns the config of the service, or nil if the service is not released progressively
+func (p *ResourceConfigProvider) GetConfig(event keptnv2.EventData, commitID string) (*Config, error) {
+	commitOption := url.Values{}
+	if commitID != "" {
+		commitOption.Add("commitID", commitID)
+	}
+	resourceScope := *keptnapi.NewResourceScope().Project(event.Project).Stage(event.Stage).Service(event.Service).Resource(ConfigResourceURI)
+	resource, err := p.resourceHandler.GetResource(resourceScope, keptnapi.AppendQuery(commitOption))
+	if err != nil {
+		if err == keptnapi.ResourceNotFoundError {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if resource == nil {
+		return nil, nil
+	}
+	return Parse([]byte(resource.ResourceContent))
+}
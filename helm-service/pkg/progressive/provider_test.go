@@ -0,0 +1,36 @@
+package progressive_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/progressive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceConfigProvider_GetConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	resourceHandler := mocks.NewMockIResourceHandler(ctrl)
+	event := keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"}
+
+	resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(&models.Resource{ResourceContent: "steps: [50, 100]"}, nil)
+	config, err := progressive.NewResourceConfigProvider(resourceHandler).GetConfig(event, "")
+	require.Nil(t, err)
+	assert.Equal(t, []int32{50, 100}, config.Steps)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(nil, keptnapi.ResourceNotFoundError)
+	config, err = progressive.NewResourceConfigProvider(resourceHandler).GetConfig(event, "")
+	require.Nil(t, err)
+	assert.Nil(t, config)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(nil, errors.New("oops"))
+	_, err = progressive.NewResourceConfigProvider(resourceHandler).GetConfig(event, "")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,76 @@
+package progressive
+
+import (
+	"sync"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// SignalEventType is the type of the events which continue or abort a progressive release waiting between two steps
+const SignalEventType = "sh.keptn.event.canary.signal"
+
+// SignalAction is the action requested by a signal
+type SignalAction string
+
+const (
+	// SignalProceed continues with the next step
+	SignalProceed SignalAction = "proceed"
+	// SignalAbort rolls back the canary
+	SignalAbort SignalAction = "abort"
+)
+
+// SignalEventData is the data of a sh.keptn.event.canary.signal event
+type SignalEventData struct {
+	keptnv2.EventData
+	Canary SignalData `json:"canary"`
+}
+
+// SignalData contains the requested action of a signal
+type SignalData struct {
+	Action SignalAction `json:"action"`
+}
+
+// Signals passes received signals to the progressive releases waiting for them.
+// The releases are identified by their keptn context
+type Signals struct {
+	mtx     sync.Mutex
+	waiters map[string]chan SignalAction
+}
+
+// NewSignals creates an empty Signals
+func NewSignals() *Signals {
+	return &Signals{waiters: map[string]chan SignalAction{}}
+}
+
+// Subscribe registers a release for the signals of its keptn context. The returned function has to be called
+// when the release does not wait for signals anymore
+func (s *Signals) Subscribe(keptnContext string) (<-chan SignalAction, func()) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ch := make(chan SignalAction, 1)
+	s.waiters[keptnContext] = ch
+	return ch, func() {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if s.waiters[keptnContext] == ch {
+			delete(s.waiters, keptnContext)
+		}
+	}
+}
+
+// Send passes the action to the release of the keptn context, and returns whether such a release is waiting.
+// If the release has not yet consumed a previous signal, the previous signal is replaced
+func (s *Signals) Send(keptnContext string, action SignalAction) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ch, ok := s.waiters[keptnContext]
+	if !ok {
+		return false
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- action
+	return true
+}
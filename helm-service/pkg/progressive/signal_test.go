@@ -0,0 +1,22 @@
+package progressive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignals(t *testing.T) {
+	signals := NewSignals()
+	assert.False(t, signals.Send("my-context", SignalProceed))
+
+	ch, unsubscribe := signals.Subscribe("my-context")
+	assert.True(t, signals.Send("my-context", SignalProceed))
+	// a signal which has not been consumed yet is replaced
+	assert.True(t, signals.Send("my-context", SignalAbort))
+	assert.Equal(t, SignalAbort, <-ch)
+	assert.False(t, signals.Send("other-context", SignalProceed))
+
+	unsubscribe()
+	assert.False(t, signals.Send("my-context", SignalProceed))
+}
@@ -0,0 +1,118 @@
+package progressive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const stateConfigMapPrefix = "progressive-release-"
+const stateConfigMapDataKey = "release"
+const stateConfigMapLabelSelector = "app.kubernetes.io/managed-by=helm-service,app.kubernetes.io/component=progressive-release"
+
+// State is the progress of a running progressive release, which allows resuming it after a restart of the helm-service
+type State struct {
+	// Event is the release.triggered event of the release
+	Event cloudevents.Event `json:"event"`
+	// CommitID is the commit of the generated chart after the last applied step
+	CommitID string `json:"commitId,omitempty"`
+	Config   Config `json:"config"`
+	// Step is the number of steps which have been completed, i.e. whose weight has been applied and whose wait has ended
+	Step int `json:"step"`
+	// WaitingSince is the time the release started to wait after applying the weight of the next step, or zero if
+	// the weight of the next step has not been applied yet
+	WaitingSince time.Time `json:"waitingSince,omitempty"`
+}
+
+// StateStore persists the states of the running progressive releases
+type StateStore interface {
+	// Save creates or updates the state of a release
+	Save(state State) error
+	// Delete removes the state of the release with the given triggered ID. Deleting an unknown release is not an error
+	Delete(triggeredID string) error
+	// List returns the states of all running releases
+	List() ([]State, error)
+}
+
+type noopStateStore struct{}
+
+func (noopStateStore) Save(State) error       { return nil }
+func (noopStateStore) Delete(string) error    { return nil }
+func (noopStateStore) List() ([]State, error) { return nil, nil }
+
+// NewNoopStateStore returns a StateStore which does not persist anything
+func NewNoopStateStore() StateStore {
+	return noopStateStore{}
+}
+
+// K8sStateStore persists the state of each running progressive release in a dedicated ConfigMap
+type K8sStateStore struct {
+	kubeAPI   kubernetes.Interface
+	namespace string
+}
+
+func NewK8sStateStore(kubeAPI kubernetes.Interface, namespace string) *K8sStateStore {
+	return &K8sStateStore{kubeAPI: kubeAPI, namespace: namespace}
+}
+
+func (s *K8sStateStore) Save(state State) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stateConfigMapName(state.Event.ID()),
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "helm-service",
+				"app.kubernetes.io/component":  "progressive-release",
+			},
+		},
+		Data: map[string]string{stateConfigMapDataKey: string(content)},
+	}
+	_, err = s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	if k8serr.IsNotFound(err) {
+		_, err = s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Create(context.TODO(), configMap, metav1.CreateOptions{})
+	}
+	return err
+}
+
+func (s *K8sStateStore) Delete(triggeredID string) error {
+	err := s.kubeAPI.CoreV1().ConfigMaps(s.namespace).Delete(context.TODO(), stateConfigMapName(triggeredID), metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *K8sStateStore) List() ([]State, error) {
+	configMaps, err := s.kubeAPI.CoreV1().ConfigMaps(s.namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: stateConfigMapLabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, 0, len(configMaps.Items))
+	for _, configMap := range configMaps.Items {
+		state := State{}
+		if err := json.Unmarshal([]byte(configMap.Data[stateConfigMapDataKey]), &state); err != nil {
+			return nil, fmt.Errorf("could not decode progressive release stored in ConfigMap %s: %w", configMap.Name, err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// stateConfigMapName derives a valid ConfigMap name from the triggered ID of a release
+func stateConfigMapName(triggeredID string) string {
+	hash := sha256.Sum256([]byte(triggeredID))
+	return stateConfigMapPrefix + hex.EncodeToString(hash[:])
+}
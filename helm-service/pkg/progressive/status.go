@@ -0,0 +1,20 @@
+package progressive
+
+import keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+
+// StatusChangedEventData is the data of the sh.keptn.event.deployment.status.changed events which report the progress of a release
+type StatusChangedEventData struct {
+	keptnv2.EventData
+	Canary Progress `json:"canary"`
+}
+
+// Progress describes the current step of a progressive release
+type Progress struct {
+	// Step is the number of the current step, starting with 1
+	Step  int `json:"step"`
+	Steps int `json:"steps"`
+	// Weight is the percentage of the traffic routed to the canary
+	Weight int32 `json:"weight"`
+	// RolledBack is set if the canary has been rolled back in this step
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
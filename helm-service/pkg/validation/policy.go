@@ -0,0 +1,167 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// PolicyResourceURI is the resource containing the validation policy of the rendered charts
+const PolicyResourceURI = "helm/validation.yaml"
+
+// Policy defines the requirements the rendered manifests of a chart have to fulfill before they are deployed
+type Policy struct {
+	// RequireResourceLimits requires cpu and memory limits for all containers
+	RequireResourceLimits bool `json:"requireResourceLimits,omitempty"`
+	// RequireResourceRequests requires cpu and memory requests for all containers
+	RequireResourceRequests bool `json:"requireResourceRequests,omitempty"`
+	// ForbiddenImageTags contains the image tags which must not be used, e.g. latest. Images without tag use the tag latest
+	ForbiddenImageTags []string `json:"forbiddenImageTags,omitempty"`
+}
+
+// Violation describes a requirement of the policy which is not fulfilled by a container
+type Violation struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Message   string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s %s, container %s: %s", v.Kind, v.Name, v.Container, v.Message)
+}
+
+// Parse parses the content of the validation.yaml resource
+func Parse(content []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", PolicyResourceURI, err)
+	}
+	return policy, nil
+}
+
+// Validate returns the violations of the workloads contained in the manifest
+func (p *Policy) Validate(manifest string) []Violation {
+	violations := []Violation{}
+	for _, workload := range getWorkloads(manifest) {
+		containers := append(append([]corev1.Container{}, workload.podSpec.InitContainers...), workload.podSpec.Containers...)
+		for _, container := range containers {
+			for _, message := range p.validateContainer(container) {
+				violations = append(violations, Violation{Kind: workload.kind, Name: workload.name, Container: container.Name, Message: message})
+			}
+		}
+	}
+	return violations
+}
+
+func (p *Policy) validateContainer(container corev1.Container) []string {
+	var messages []string
+	if p.RequireResourceLimits {
+		for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := container.Resources.Limits[resource]; !ok {
+				messages = append(messages, fmt.Sprintf("missing %s limit", resource))
+			}
+		}
+	}
+	if p.RequireResourceRequests {
+		for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := container.Resources.Requests[resource]; !ok {
+				messages = append(messages, fmt.Sprintf("missing %s request", resource))
+			}
+		}
+	}
+	tag := getImageTag(container.Image)
+	for _, forbiddenTag := range p.ForbiddenImageTags {
+		if tag == forbiddenTag {
+			messages = append(messages, fmt.Sprintf("image %s uses the forbidden tag %s", container.Image, tag))
+		}
+	}
+	return messages
+}
+
+// getImageTag returns the tag of the image, or latest if the image has no tag. Images referenced by digest have no tag
+func getImageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	// the last colon separates the tag, unless it belongs to the port of the registry
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx+1:], "/") {
+		return "latest"
+	}
+	return image[idx+1:]
+}
+
+type workload struct {
+	kind    string
+	name    string
+	podSpec corev1.PodSpec
+}
+
+// getWorkloads returns the pod specs of the workloads contained in the manifest
+func getWorkloads(manifest string) []workload {
+	workloads := []workload{}
+	dec := kyaml.NewYAMLToJSONDecoder(strings.NewReader(manifest))
+	for {
+		var raw map[string]interface{}
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil || raw == nil {
+			continue
+		}
+		data, err := yaml.Marshal(raw)
+		if err != nil {
+			continue
+		}
+		kind, _ := raw["kind"].(string)
+		if w, ok := decodeWorkload(kind, data); ok {
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads
+}
+
+func decodeWorkload(kind string, data []byte) (workload, bool) {
+	switch kind {
+	case "Deployment":
+		obj := appsv1.Deployment{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec.Template.Spec}, true
+		}
+	case "StatefulSet":
+		obj := appsv1.StatefulSet{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec.Template.Spec}, true
+		}
+	case "DaemonSet":
+		obj := appsv1.DaemonSet{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec.Template.Spec}, true
+		}
+	case "Job":
+		obj := batchv1.Job{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec.Template.Spec}, true
+		}
+	case "CronJob":
+		obj := batchv1beta1.CronJob{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec.JobTemplate.Spec.Template.Spec}, true
+		}
+	case "Pod":
+		obj := corev1.Pod{}
+		if yaml.Unmarshal(data, &obj) == nil {
+			return workload{kind: kind, name: obj.Name, podSpec: obj.Spec}, true
+		}
+	}
+	return workload{}, false
+}
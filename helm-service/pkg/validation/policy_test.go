@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: carts
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: carts
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.33
+        resources:
+          limits:
+            cpu: 100m
+            memory: 64Mi
+      containers:
+      - name: carts
+        image: docker.io/keptnexamples/carts:latest
+        resources:
+          limits:
+            cpu: 500m
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "0 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: localhost:5000/cleanup
+`
+
+func TestParse(t *testing.T) {
+	policy, err := Parse([]byte(`
+requireResourceLimits: true
+forbiddenImageTags:
+- latest
+`))
+	require.Nil(t, err)
+	assert.Equal(t, &Policy{RequireResourceLimits: true, ForbiddenImageTags: []string{"latest"}}, policy)
+
+	_, err = Parse([]byte("forbiddenImageTags: latest"))
+	assert.NotNil(t, err)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []Violation
+	}{
+		{
+			name:   "empty policy",
+			policy: Policy{},
+			want:   []Violation{},
+		},
+		{
+			name:   "resource limits",
+			policy: Policy{RequireResourceLimits: true},
+			want: []Violation{
+				{Kind: "Deployment", Name: "carts", Container: "carts", Message: "missing memory limit"},
+				{Kind: "CronJob", Name: "cleanup", Container: "cleanup", Message: "missing cpu limit"},
+				{Kind: "CronJob", Name: "cleanup", Container: "cleanup", Message: "missing memory limit"},
+			},
+		},
+		{
+			name:   "resource requests",
+			policy: Policy{RequireResourceRequests: true},
+			want: []Violation{
+				{Kind: "Deployment", Name: "carts", Container: "init", Message: "missing cpu request"},
+				{Kind: "Deployment", Name: "carts", Container: "init", Message: "missing memory request"},
+				{Kind: "Deployment", Name: "carts", Container: "carts", Message: "missing cpu request"},
+				{Kind: "Deployment", Name: "carts", Container: "carts", Message: "missing memory request"},
+				{Kind: "CronJob", Name: "cleanup", Container: "cleanup", Message: "missing cpu request"},
+				{Kind: "CronJob", Name: "cleanup", Container: "cleanup", Message: "missing memory request"},
+			},
+		},
+		{
+			name:   "forbidden image tags",
+			policy: Policy{ForbiddenImageTags: []string{"latest"}},
+			want: []Violation{
+				{Kind: "Deployment", Name: "carts", Container: "carts", Message: "image docker.io/keptnexamples/carts:latest uses the forbidden tag latest"},
+				{Kind: "CronJob", Name: "cleanup", Container: "cleanup", Message: "image localhost:5000/cleanup uses the forbidden tag latest"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Validate(manifest))
+		})
+	}
+}
+
+func Test_getImageTag(t *testing.T) {
+	assert.Equal(t, "0.8.1", getImageTag("docker.io/keptnexamples/carts:0.8.1"))
+	assert.Equal(t, "latest", getImageTag("docker.io/keptnexamples/carts"))
+	assert.Equal(t, "latest", getImageTag("localhost:5000/carts"))
+	assert.Equal(t, "1.0", getImageTag("localhost:5000/carts:1.0"))
+	assert.Equal(t, "", getImageTag("carts@sha256:e5a8b8e8ed4d5b0b5a8e8"))
+}
@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"net/url"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/pkg/types"
+)
+
+// PolicyProvider retrieves the validation policy of a service
+type PolicyProvider interface {
+	GetPolicy(event keptnv2.EventData, commitID string) (*Policy, error)
+}
+
+// ResourcePolicyProvider reads the policy from the validation.yaml resource of the service, stage or project.
+// The resource of the service has precedence over the one of the stage, which has precedence over the one of the project
+type ResourcePolicyProvider struct {
+	resourceHandler types.IResourceHandler
+}
+
+// NewResourcePolicyProvider creates a ResourcePolicyProvider
+func NewResourcePolicyProvider(resourceHandler types.IResourceHandler) *ResourcePolicyProvider {
+	return &ResourcePolicyProvider{resourceHandler: resourceHandler}
+}
+
+// GetPolicy returns the policy of the service, or nil if no policy is defined
+func (p *ResourcePolicyProvider) GetPolicy(event keptnv2.EventData, commitID string) (*Policy, error) {
+	commitOption := url.Values{}
+	if commitID != "" {
+		commitOption.Add("commitID", commitID)
+	}
+	scopes := []*keptnapi.ResourceScope{
+		keptnapi.NewResourceScope().Project(event.Project).Stage(event.Stage).Service(event.Service).Resource(PolicyResourceURI),
+		keptnapi.NewResourceScope().Project(event.Project).Stage(event.Stage).Resource(PolicyResourceURI),
+		keptnapi.NewResourceScope().Project(event.Project).Resource(PolicyResourceURI),
+	}
+	for _, scope := range scopes {
+		resource, err := p.resourceHandler.GetResource(*scope, keptnapi.AppendQuery(commitOption))
+		if err == keptnapi.ResourceNotFoundError {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resource != nil {
+			return Parse([]byte(resource.ResourceContent))
+		}
+	}
+	return nil, nil
+}
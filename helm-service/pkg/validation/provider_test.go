@@ -0,0 +1,40 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/helm-service/mocks"
+	"github.com/keptn/keptn/helm-service/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcePolicyProvider_GetPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	resourceHandler := mocks.NewMockIResourceHandler(ctrl)
+	event := keptnv2.EventData{Project: "sockshop", Stage: "production", Service: "carts"}
+
+	// the service has no policy, so the one of the stage is used
+	gomock.InOrder(
+		resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(nil, keptnapi.ResourceNotFoundError),
+		resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(&models.Resource{ResourceContent: "requireResourceLimits: true"}, nil),
+	)
+	policy, err := validation.NewResourcePolicyProvider(resourceHandler).GetPolicy(event, "")
+	require.Nil(t, err)
+	assert.True(t, policy.RequireResourceLimits)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(nil, keptnapi.ResourceNotFoundError).Times(3)
+	policy, err = validation.NewResourcePolicyProvider(resourceHandler).GetPolicy(event, "")
+	require.Nil(t, err)
+	assert.Nil(t, policy)
+
+	resourceHandler.EXPECT().GetResource(gomock.Any(), gomock.Any()).Return(nil, errors.New("oops"))
+	_, err = validation.NewResourcePolicyProvider(resourceHandler).GetPolicy(event, "")
+	assert.NotNil(t, err)
+}
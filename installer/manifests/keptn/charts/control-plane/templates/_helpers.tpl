@@ -140,6 +140,68 @@ lifecycle:
   value: "{{ (((.Values.distributor).config).oauth).tokenURL }}"
 - name: OAUTH_SCOPES
   value: "{{ (((.Values.distributor).config).oauth).scopes }}"
+- name: DELIVERY_QUEUE_DIR
+  value: /var/lib/keptn-distributor
+{{- end }}
+
+{{- define "control-plane.dist.volumeMounts" -}}
+volumeMounts:
+  - name: distributor-delivery-queue
+    mountPath: /var/lib/keptn-distributor
+{{- end }}
+
+{{/*
+The delivery queue of the distributor of the given service is stored in a PVC, unless persistence is disabled.
+Without persistence it is stored in an emptyDir, hence queued events are lost if the pod is restarted.
+Usage: include "control-plane.dist.volume" (dict "name" "api-service" "context" .)
+*/}}
+{{- define "control-plane.dist.volume" -}}
+{{- $deliveryQueue := ((.context.Values.distributor).config).deliveryQueue | default dict -}}
+- name: distributor-delivery-queue
+{{- if ($deliveryQueue.persistence).enabled }}
+  persistentVolumeClaim:
+    claimName: {{ include "control-plane.dist.claimName" . }}
+{{- else }}
+  emptyDir:
+    sizeLimit: {{ $deliveryQueue.sizeLimit | default "100Mi" }}
+{{- end }}
+{{- end }}
+
+{{- define "control-plane.dist.claimName" -}}
+{{- $existingClaims := (((((.context.Values.distributor).config).deliveryQueue).persistence).existingClaims) | default dict -}}
+{{- index $existingClaims .name | default (printf "%s-delivery-queue" .name) -}}
+{{- end }}
+
+{{/*
+The PVC storing the delivery queue of the distributor of the given service, unless persistence is disabled or an
+existing claim is configured. Usage: include "control-plane.dist.persistentVolumeClaim" (dict "name" "api-service" "context" .)
+*/}}
+{{- define "control-plane.dist.persistentVolumeClaim" -}}
+{{- $persistence := (((.context.Values.distributor).config).deliveryQueue).persistence | default dict -}}
+{{- $existingClaims := $persistence.existingClaims | default dict -}}
+{{- if and $persistence.enabled (not (index $existingClaims .name)) }}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{ .name }}-delivery-queue
+  labels:
+    app.kubernetes.io/name: {{ .name }}-delivery-queue
+    app.kubernetes.io/instance: {{ .context.Release.Name }}
+    app.kubernetes.io/managed-by: {{ .context.Release.Service }}
+    app.kubernetes.io/part-of: keptn-{{ .context.Release.Namespace }}
+    app.kubernetes.io/component: {{ include "control-plane.name" .context }}
+    helm.sh/chart: {{ include "control-plane.chart" .context }}
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: {{ $persistence.storage | default "100Mi" }}
+  {{- if $persistence.storageClass }}
+  storageClassName: {{ $persistence.storageClass }}
+  {{- end }}
+{{- end }}
 {{- end }}
 
 {{- define "control-plane.common.security-context-seccomp" -}}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// executeCommandInDirectoryWithContext executes the command in the directory and returns its combined output. If the
+// context is done before the command finished, the command and all processes started by it are killed
+func executeCommandInDirectoryWithContext(ctx context.Context, command string, args []string, directory string) (string, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = directory
+	output := &bytes.Buffer{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("error executing command %s %v: %w", command, args, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return output.String(), fmt.Errorf("error executing command %s %v: %s", command, args, output.String())
+		}
+		return output.String(), nil
+	case <-ctx.Done():
+		// jmeter is a shell script starting the JVM, hence the whole process group has to be killed
+		_ = killProcessGroup(cmd)
+		<-done
+		return output.String(), ctx.Err()
+	}
+}
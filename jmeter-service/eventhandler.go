@@ -11,9 +11,11 @@ import (
 )
 
 // EventHandler handles events of type 'test.triggered' and kicks off
-// the TestRunner to execute the JMeter tests
+// the TestRunner to execute the JMeter tests. Events of type 'get-sli.triggered'
+// for the jmeter SLI provider are passed to the SLIProvider
 type EventHandler struct {
-	testRunner *TestRunner
+	testRunner  *TestRunner
+	sliProvider *SLIProvider
 }
 
 func (e *EventHandler) handleEvent(ctx context.Context, event cloudevents.Event) error {
@@ -27,6 +29,10 @@ func (e *EventHandler) handleEvent(ctx context.Context, event cloudevents.Event)
 	var commitID string
 	event.ExtensionAs("gitcommitid", &commitID)
 
+	if event.Type() == keptnv2.GetTriggeredEventType(keptnv2.GetSLITaskName) {
+		return e.handleGetSLIEvent(event, shkeptncontext, commitID)
+	}
+
 	if event.Type() != keptnv2.GetTriggeredEventType(keptnv2.TestTaskName) {
 		logger.Warnf("Received unexpected keptn event: %s", event.Type())
 		return nil
@@ -53,6 +59,22 @@ func (e *EventHandler) handleEvent(ctx context.Context, event cloudevents.Event)
 	return nil
 }
 
+func (e *EventHandler) handleGetSLIEvent(event cloudevents.Event, shkeptncontext string, commitID string) error {
+	data := &keptnv2.GetSLITriggeredEventData{}
+	if err := event.DataAs(data); err != nil {
+		logger.Errorf("Unable to decode 'get-sli.triggered' event data: %v", err)
+		return nil
+	}
+	if data.GetSLI.SLIProvider != SLIProviderName {
+		logger.Debugf("Received 'get-sli.triggered' event for SLI provider %s, hence no SLIs are retrieved", data.GetSLI.SLIProvider)
+		return nil
+	}
+	if err := e.sliProvider.GetSLIs(*data, shkeptncontext, event.ID(), commitID); err != nil {
+		logger.Errorf("Unable to retrieve SLIs: %v", err)
+	}
+	return nil
+}
+
 func createTestInfo(data keptnv2.TestTriggeredEventData, shkeptncontext string, triggeredID string, commitID string) (*TestInfo, error) {
 	serviceURL, err := getServiceURL(data)
 	if err != nil {
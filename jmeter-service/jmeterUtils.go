@@ -46,7 +46,10 @@ func shouldRemoveTempFiles() bool {
 func createJMeterCLIArguments(workload *Workload, url *url.URL, resultsDir string, loadTestName string) []string {
 	return []string{"-n", "-t", workload.Script,
 		// "-e", "-o", resultsDir,
-		"-l", resultsDir + "_result.tlf",
+		"-l", getResultsFileName(resultsDir),
+		// the results are written as CSV including the column names, such that the metrics can be computed from them
+		"-Jjmeter.save.saveservice.output_format=csv",
+		"-Jjmeter.save.saveservice.print_field_names=true",
 		"-JPROTOCOL=" + url.Scheme,
 		"-JSERVER_PROTOCOL=" + url.Scheme,
 		"-JSERVER_URL=" + url.Hostname(),
@@ -193,16 +196,45 @@ func executeJMeter(testInfo TestInfo, workload *Workload, resultsDir string, url
 		return false, err
 	}
 	// Step 2: Lets execute the script - but be aware that we launch jmeter from the localTempDir as a working directory!
+	if workload.Workers > 0 {
+		return executeDistributedAndCleanup(testInfo, workload, url, resultsDir, loadTestName, localTempDir, removeTempFiles, funcValidation)
+	}
 	jMeterCommandLineArgs := addJMeterCommandLineArguments(testInfo, createJMeterCLIArguments(workload, url, resultsDir, loadTestName))
-	return execute(testInfo, workload, err, jMeterCommandLineArgs, localTempDir, removeTempFiles, funcValidation)
+	return execute(testInfo, workload, err, jMeterCommandLineArgs, localTempDir, getResultsFileName(resultsDir), removeTempFiles, funcValidation)
+}
+
+// executeDistributedAndCleanup executes the workload on the JMeter workers and removes the downloaded files afterwards
+func executeDistributedAndCleanup(testInfo TestInfo, workload *Workload, url *url.URL, resultsDir string, loadTestName string, localTempDir string, removeTempFiles bool, funcValidation bool) (bool, error) {
+	jMeterCommandLineArgs := func(vuser int) []string {
+		workerWorkload := *workload
+		workerWorkload.VUser = vuser
+		return addJMeterCommandLineArguments(testInfo, createJMeterCLIArguments(&workerWorkload, url, resultsDir, loadTestName))
+	}
+	result, err := executeDistributed(testInfo, workload, jMeterCommandLineArgs, localTempDir, getResultsFileName(resultsDir), funcValidation)
+	if removeTempFiles {
+		if err := os.RemoveAll(localTempDir); err != nil {
+			return false, err
+		}
+	}
+	if err != nil {
+		logger.Errorf("Distributed JMeter test failed (%v). Error: %v", testInfo, err)
+		return false, err
+	}
+	logger.Debugf("Jmeter tests passed: %t", result)
+	return result, nil
 }
 
-func execute(testInfo TestInfo, workload *Workload, err error, jMeterCommandLineArgs []string, localTempDir string, removeTempFiles bool, funcValidation bool) (bool, error) {
+func execute(testInfo TestInfo, workload *Workload, err error, jMeterCommandLineArgs []string, localTempDir string, resultsFile string, removeTempFiles bool, funcValidation bool) (bool, error) {
 	jmeterCommandResult, err := keptnutils.ExecuteCommandInDirectory("jmeter", jMeterCommandLineArgs, localTempDir)
 	if err != nil {
 		logger.Error(err.Error())
 		return false, err
 	}
+	// the results file is written relative to the working directory of JMeter, hence it has to be processed before
+	// the temp files are removed
+	if workload.TestStrategy != TestStrategy_HealthCheck {
+		processTestMetrics(testInfo, workload, resolveResultsFile(resultsFile, localTempDir))
+	}
 	// now lets remove all downloaded files
 	if removeTempFiles {
 		if err := os.RemoveAll(localTempDir); err != nil {
@@ -220,6 +252,34 @@ func execute(testInfo TestInfo, workload *Workload, err error, jMeterCommandLine
 	return result, nil
 }
 
+// getResultsFileName returns the name of the JTL file JMeter writes the results to
+func getResultsFileName(resultsDir string) string {
+	return resultsDir + "_result.tlf"
+}
+
+// processTestMetrics computes the metrics of the test run from the results file and stores them as a resource of the
+// service, such that they can be served as SLIs. Failures are logged, as they do not affect the result of the test
+func processTestMetrics(testInfo TestInfo, workload *Workload, resultsFile string) {
+	metrics, err := parseJTLFile(resultsFile)
+	if err != nil {
+		logger.Warnf("Could not compute metrics from JMeter results file %s (%v): %v", resultsFile, testInfo, err)
+		return
+	}
+	storeMetrics(testInfo, workload, metrics)
+}
+
+// storeMetrics stores the metrics of the test run as a resource of the service. Failures are logged, as they do not
+// affect the result of the test
+func storeMetrics(testInfo TestInfo, workload *Workload, metrics *TestMetrics) {
+	metrics.Context = testInfo.Context
+	metrics.TestStrategy = workload.TestStrategy
+	if err := storeTestMetrics(testInfo, metrics); err != nil {
+		logger.Warnf("Could not store JMeter test metrics (%v): %v", testInfo, err)
+		return
+	}
+	logger.Infof("Stored metrics of %d samples in %s (%v)", metrics.Total.Count, JMeterMetricsFilename, testInfo)
+}
+
 func createDir(dir string) error {
 	if err := os.RemoveAll(dir); err != nil {
 		return err
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// TestMetrics contains the metrics of a JMeter test run computed from its JTL results file
+type TestMetrics struct {
+	Context      string                     `json:"context"`
+	TestStrategy string                     `json:"teststrategy"`
+	Start        time.Time                  `json:"start"`
+	End          time.Time                  `json:"end"`
+	Total        *SamplerMetrics            `json:"total"`
+	Samplers     map[string]*SamplerMetrics `json:"samplers"`
+}
+
+// SamplerMetrics contains the metrics of the samples of a sampler. Response times are in milliseconds, the
+// throughput is in samples per second
+type SamplerMetrics struct {
+	Count      int     `json:"count"`
+	Errors     int     `json:"errors"`
+	ErrorRate  float64 `json:"errorRate"`
+	Avg        float64 `json:"avg"`
+	P50        float64 `json:"p50"`
+	P90        float64 `json:"p90"`
+	P95        float64 `json:"p95"`
+	P99        float64 `json:"p99"`
+	Throughput float64 `json:"throughput"`
+}
+
+// samples collects the samples of a sampler
+type samples struct {
+	elapsed []float64
+	errors  int
+	// start and end are the timestamps in ms of the first sample started and the last sample finished
+	start int64
+	end   int64
+}
+
+func (s *samples) add(timestamp int64, elapsed int64, success bool) {
+	if len(s.elapsed) == 0 || timestamp < s.start {
+		s.start = timestamp
+	}
+	if timestamp+elapsed > s.end {
+		s.end = timestamp + elapsed
+	}
+	s.elapsed = append(s.elapsed, float64(elapsed))
+	if !success {
+		s.errors++
+	}
+}
+
+func (s *samples) metrics() *SamplerMetrics {
+	count := len(s.elapsed)
+	sort.Float64s(s.elapsed)
+	sum := 0.0
+	for _, elapsed := range s.elapsed {
+		sum += elapsed
+	}
+	m := &SamplerMetrics{
+		Count:     count,
+		Errors:    s.errors,
+		ErrorRate: float64(s.errors) / float64(count),
+		Avg:       sum / float64(count),
+		P50:       percentile(s.elapsed, 50),
+		P90:       percentile(s.elapsed, 90),
+		P95:       percentile(s.elapsed, 95),
+		P99:       percentile(s.elapsed, 99),
+	}
+	if duration := s.end - s.start; duration > 0 {
+		m.Throughput = float64(count) / (float64(duration) / 1000)
+	}
+	return m
+}
+
+// percentile returns the percentile of the sorted values using the nearest-rank method
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// parseJTLFile parses the JTL results file written by JMeter
+func parseJTLFile(fileName string) (*TestMetrics, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseJTL(file)
+}
+
+// parseJTL parses JMeter results in the CSV format including the header line and computes the metrics of each sampler
+// and of all samples
+func parseJTL(reader io.Reader) (*TestMetrics, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("JTL results file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read JTL header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"timeStamp", "elapsed", "label", "success"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("JTL results file does not contain the column %s", name)
+		}
+	}
+
+	total := &samples{}
+	samplers := map[string]*samples{}
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read line %d of JTL results file: %w", line, err)
+		}
+		if len(record) < len(header) {
+			return nil, fmt.Errorf("line %d of JTL results file has %d instead of %d columns", line, len(record), len(header))
+		}
+		timestamp, err := strconv.ParseInt(record[columns["timeStamp"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeStamp in line %d of JTL results file: %w", line, err)
+		}
+		elapsed, err := strconv.ParseInt(record[columns["elapsed"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid elapsed time in line %d of JTL results file: %w", line, err)
+		}
+		success, err := strconv.ParseBool(record[columns["success"]])
+		if err != nil {
+			return nil, fmt.Errorf("invalid success flag in line %d of JTL results file: %w", line, err)
+		}
+
+		label := record[columns["label"]]
+		if samplers[label] == nil {
+			samplers[label] = &samples{}
+		}
+		samplers[label].add(timestamp, elapsed, success)
+		total.add(timestamp, elapsed, success)
+	}
+	if len(total.elapsed) == 0 {
+		return nil, errors.New("JTL results file does not contain any samples")
+	}
+
+	metrics := &TestMetrics{
+		Start:    time.Unix(0, total.start*int64(time.Millisecond)).UTC(),
+		End:      time.Unix(0, total.end*int64(time.Millisecond)).UTC(),
+		Total:    total.metrics(),
+		Samplers: map[string]*SamplerMetrics{},
+	}
+	for label, s := range samplers {
+		metrics.Samplers[label] = s.metrics()
+	}
+	return metrics, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jtlContent = `timeStamp,elapsed,label,responseCode,responseMessage,threadName,dataType,success,failureMessage,bytes,sentBytes,grpThreads,allThreads,URL,Latency,IdleTime,Connect
+1650000000000,100,Login,200,OK,Thread Group 1-1,text,true,,100,50,1,1,http://carts/login,90,0,10
+1650000000100,300,Login,500,"Internal Server Error, retry",Thread Group 1-1,text,false,,100,50,1,1,http://carts/login,290,0,10
+1650000000400,200,Cart,200,OK,Thread Group 1-1,text,true,,100,50,1,1,http://carts/cart,190,0,10
+1650000000600,400,Cart,200,OK,Thread Group 1-1,text,true,,100,50,1,1,http://carts/cart,390,0,10
+`
+
+func Test_parseJTL(t *testing.T) {
+	metrics, err := parseJTL(strings.NewReader(jtlContent))
+	if err != nil {
+		t.Fatalf("parseJTL() error = %v", err)
+	}
+
+	if want := time.Unix(1650000000, 0).UTC(); !metrics.Start.Equal(want) {
+		t.Errorf("parseJTL() start = %v, want %v", metrics.Start, want)
+	}
+	if want := time.Unix(1650000001, 0).UTC(); !metrics.End.Equal(want) {
+		t.Errorf("parseJTL() end = %v, want %v", metrics.End, want)
+	}
+
+	wantTotal := &SamplerMetrics{Count: 4, Errors: 1, ErrorRate: 0.25, Avg: 250, P50: 200, P90: 400, P95: 400, P99: 400, Throughput: 4}
+	if !reflect.DeepEqual(metrics.Total, wantTotal) {
+		t.Errorf("parseJTL() total = %+v, want %+v", metrics.Total, wantTotal)
+	}
+	wantLogin := &SamplerMetrics{Count: 2, Errors: 1, ErrorRate: 0.5, Avg: 200, P50: 100, P90: 300, P95: 300, P99: 300, Throughput: 5}
+	if !reflect.DeepEqual(metrics.Samplers["Login"], wantLogin) {
+		t.Errorf("parseJTL() Login = %+v, want %+v", metrics.Samplers["Login"], wantLogin)
+	}
+	wantCart := &SamplerMetrics{Count: 2, Errors: 0, ErrorRate: 0, Avg: 300, P50: 200, P90: 400, P95: 400, P99: 400, Throughput: 3.3333333333333335}
+	if !reflect.DeepEqual(metrics.Samplers["Cart"], wantCart) {
+		t.Errorf("parseJTL() Cart = %+v, want %+v", metrics.Samplers["Cart"], wantCart)
+	}
+}
+
+func Test_parseJTL_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"no samples", "timeStamp,elapsed,label,success\n"},
+		{"missing column", "timeStamp,elapsed,label\n1650000000000,100,Login\n"},
+		{"invalid elapsed time", "timeStamp,elapsed,label,success\n1650000000000,fast,Login,true\n"},
+		{"missing values", "timeStamp,elapsed,label,success\n1650000000000,100\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseJTL(strings.NewReader(tt.content)); err == nil {
+				t.Errorf("parseJTL() expected error")
+			}
+		})
+	}
+}
+
+func Test_parseJTLFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test_result.tlf")
+	if err := os.WriteFile(fileName, []byte(jtlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := parseJTLFile(fileName)
+	if err != nil {
+		t.Fatalf("parseJTLFile() error = %v", err)
+	}
+	if metrics.Total.Count != 4 {
+		t.Errorf("parseJTLFile() count = %d, want 4", metrics.Total.Count)
+	}
+
+	if _, err := parseJTLFile(filepath.Join(t.TempDir(), "missing.tlf")); err == nil {
+		t.Errorf("parseJTLFile() expected error for missing file")
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{50, 5},
+		{90, 9},
+		{95, 10},
+		{100, 10},
+	}
+	for _, tt := range tests {
+		if got := percentile(values, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile() of no values = %v, want 0", got)
+	}
+}
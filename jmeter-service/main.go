@@ -55,7 +55,7 @@ func main() {
 		logger.Fatalf("Failed to create event sender: %v", err)
 	}
 
-	eventHandler := &EventHandler{testRunner: NewTestRunner(eventSender)}
+	eventHandler := &EventHandler{testRunner: NewTestRunner(eventSender), sliProvider: NewSLIProvider(eventSender)}
 
 	logger.Fatal(c.StartReceiver(ctx, eventHandler.handleEvent))
 
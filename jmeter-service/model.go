@@ -8,6 +8,12 @@ workloads:
     vuser: 200
     loopcount: 50
     script: load.jmx
+  - teststrategy: performance_distributed
+    vuser: 2000
+    loopcount: 50
+    script: load.jmx
+    workers: 4
+    timeout: 30m
   - teststrategy: performance_light
     vuser: 50
     loopcount: 10
@@ -41,6 +47,11 @@ type Workload struct {
 	AcceptedErrorRate float32           `json:"acceptederrorrate" yaml:"acceptederrorrate"`
 	AvgRtValidation   int               `json:"avgrtvalidation" yaml:"avgrtvalidation"`
 	Properties        map[string]string `json:"properties" yaml:"properties"`
+	// Workers is the number of remote JMeter servers the virtual users are distributed across. If not set, the
+	// workload is executed by a local JMeter process
+	Workers int `json:"workers,omitempty" yaml:"workers,omitempty"`
+	// Timeout is the maximum duration of a distributed workload, e.g. 30m
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 var defaultWorkloads = []Workload{
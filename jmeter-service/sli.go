@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	configutils "github.com/keptn/go-utils/pkg/api/utils"
+	commontime "github.com/keptn/go-utils/pkg/common/timeutils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	logger "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SLIProviderName is the name of the SLI provider served by the jmeter-service
+	SLIProviderName = "jmeter"
+	// JMeterMetricsFilename is the service resource containing the metrics of the last test run
+	JMeterMetricsFilename = "jmeter/metrics.json"
+	// JMeterSLIFilename is the resource containing the queries of the SLIs
+	JMeterSLIFilename = "jmeter/sli.yaml"
+)
+
+// Metrics which can be queried by SLIs
+const (
+	MetricCount          = "count"
+	MetricErrors         = "errors"
+	MetricErrorRate      = "error_rate"
+	MetricResponseTime   = "response_time_avg"
+	MetricResponseTime50 = "response_time_p50"
+	MetricResponseTime90 = "response_time_p90"
+	MetricResponseTime95 = "response_time_p95"
+	MetricResponseTime99 = "response_time_p99"
+	MetricThroughput     = "throughput"
+)
+
+// samplerFilterKey is the key of the custom filter and the query parameter selecting the metrics of a sampler
+const samplerFilterKey = "sampler"
+
+// SLIConfig contains the queries of the SLIs, e.g.
+//
+//   indicators:
+//     response_time_p95: response_time_p95
+//     login_errors: error_rate;sampler=Login
+type SLIConfig struct {
+	SpecVersion string            `json:"spec_version" yaml:"spec_version"`
+	Indicators  map[string]string `json:"indicators" yaml:"indicators"`
+}
+
+// SLIProvider serves the metrics of the last JMeter test run of a service as SLIs
+type SLIProvider struct {
+	eventSender *keptnv2.HTTPEventSender
+}
+
+// NewSLIProvider creates a new SLIProvider
+func NewSLIProvider(eventSender *keptnv2.HTTPEventSender) *SLIProvider {
+	return &SLIProvider{eventSender}
+}
+
+// GetSLIs sends the values of the requested indicators in a 'get-sli.finished' event
+func (sp *SLIProvider) GetSLIs(data keptnv2.GetSLITriggeredEventData, shkeptncontext string, triggeredID string, commitID string) error {
+	if err := sp.sendEvent(keptnv2.GetStartedEventType(keptnv2.GetSLITaskName), shkeptncontext, triggeredID, commitID,
+		keptnv2.GetSLIStartedEventData{EventData: getSLIEventData(data.EventData, keptnv2.StatusSucceeded, "", "")}); err != nil {
+		return fmt.Errorf("could not send '.started' event: %w", err)
+	}
+
+	finishedData := keptnv2.GetSLIFinishedEventData{
+		GetSLI: keptnv2.GetSLIFinished{Start: data.GetSLI.Start, End: data.GetSLI.End},
+	}
+	indicatorValues, err := getIndicatorValues(data, shkeptncontext, commitID)
+	if err != nil {
+		logger.Errorf("Could not retrieve SLIs: %v", err)
+		finishedData.EventData = getSLIEventData(data.EventData, keptnv2.StatusErrored, keptnv2.ResultFailed, err.Error())
+	} else {
+		finishedData.EventData = getSLIEventData(data.EventData, keptnv2.StatusSucceeded, keptnv2.ResultPass, "")
+		finishedData.GetSLI.IndicatorValues = indicatorValues
+	}
+	if err := sp.sendEvent(keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), shkeptncontext, triggeredID, commitID, finishedData); err != nil {
+		return fmt.Errorf("could not send '.finished' event: %w", err)
+	}
+	return nil
+}
+
+func (sp *SLIProvider) sendEvent(eventType string, shkeptncontext string, triggeredID string, commitID string, data interface{}) error {
+	source, _ := url.Parse(JMeterServiceName)
+
+	event := cloudevents.NewEvent()
+	event.SetType(eventType)
+	event.SetSource(source.String())
+	event.SetDataContentType(cloudevents.ApplicationJSON)
+	event.SetExtension("shkeptncontext", shkeptncontext)
+	event.SetExtension("triggeredid", triggeredID)
+	event.SetExtension("gitcommitid", commitID)
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return err
+	}
+
+	return sp.eventSender.SendEvent(event)
+}
+
+func getSLIEventData(eventData keptnv2.EventData, status keptnv2.StatusType, result keptnv2.ResultType, msg string) keptnv2.EventData {
+	eventData.Status = status
+	eventData.Result = result
+	eventData.Message = msg
+	return eventData
+}
+
+// getIndicatorValues loads the metrics of the last test run and the SLI configuration and returns the values of the indicators
+func getIndicatorValues(data keptnv2.GetSLITriggeredEventData, shkeptncontext string, commitID string) ([]*keptnv2.SLIResult, error) {
+	metrics, err := getTestMetrics(data.Project, data.Stage, data.Service)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTestMetricsMatch(metrics, shkeptncontext, data.GetSLI.Start, data.GetSLI.End); err != nil {
+		return nil, err
+	}
+
+	sliConfig := &SLIConfig{}
+	sliContent, err := GetKeptnResource(commitID, data.Project, data.Stage, data.Service, JMeterSLIFilename)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %w", JMeterSLIFilename, err)
+	}
+	if sliContent != "" {
+		if err := yaml.Unmarshal([]byte(sliContent), sliConfig); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", JMeterSLIFilename, err)
+		}
+	}
+
+	return getSLIResults(metrics, sliConfig, data.GetSLI.Indicators, data.GetSLI.CustomFilters), nil
+}
+
+// getTestMetrics loads the metrics of the last test run of the service
+func getTestMetrics(project string, stage string, service string) (*TestMetrics, error) {
+	resourceHandler := configutils.NewResourceHandler(GetConfigurationServiceURL())
+	resourceScope := *configutils.NewResourceScope().Project(project).Stage(stage).Service(service).Resource(JMeterMetricsFilename)
+	resource, err := resourceHandler.GetResource(resourceScope)
+	if err != nil {
+		if errors.Is(err, configutils.ResourceNotFoundError) {
+			return nil, fmt.Errorf("no JMeter test results available for service %s in stage %s of project %s", service, stage, project)
+		}
+		return nil, fmt.Errorf("could not load %s: %w", JMeterMetricsFilename, err)
+	}
+	metrics := &TestMetrics{}
+	if err := json.Unmarshal([]byte(resource.ResourceContent), metrics); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", JMeterMetricsFilename, err)
+	}
+	return metrics, nil
+}
+
+// storeTestMetrics stores the metrics of the test run as a resource of the service
+func storeTestMetrics(testInfo TestInfo, metrics *TestMetrics) error {
+	content, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	resourceURI := JMeterMetricsFilename
+	resourceHandler := configutils.NewResourceHandler(GetConfigurationServiceURL())
+	_, err = resourceHandler.UpdateServiceResources(testInfo.Project, testInfo.Stage, testInfo.Service, []*apimodels.Resource{
+		{ResourceURI: &resourceURI, ResourceContent: string(content)},
+	})
+	return err
+}
+
+// checkTestMetricsMatch checks that the metrics belong to the evaluated sequence, or that the test run lies within the
+// evaluated timeframe
+func checkTestMetricsMatch(metrics *TestMetrics, shkeptncontext string, start string, end string) error {
+	if metrics.Context == shkeptncontext {
+		return nil
+	}
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return fmt.Errorf("could not parse start %s: %w", start, err)
+	}
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return fmt.Errorf("could not parse end %s: %w", end, err)
+	}
+	if metrics.Start.Before(startTime) || metrics.End.After(endTime) {
+		return fmt.Errorf("the last JMeter test run from %s to %s is not within the evaluated timeframe",
+			metrics.Start.Format(commontime.KeptnTimeFormatISO8601), metrics.End.Format(commontime.KeptnTimeFormatISO8601))
+	}
+	return nil
+}
+
+// getSLIResults returns the values of the indicators. Indicators without a query in the SLI configuration are
+// interpreted as the name of a metric
+func getSLIResults(metrics *TestMetrics, sliConfig *SLIConfig, indicators []string, filters []*keptnv2.SLIFilter) []*keptnv2.SLIResult {
+	defaultSampler := ""
+	for _, filter := range filters {
+		if filter != nil && filter.Key == samplerFilterKey {
+			defaultSampler = filter.Value
+		}
+	}
+
+	results := make([]*keptnv2.SLIResult, 0, len(indicators))
+	for _, indicator := range indicators {
+		query, ok := sliConfig.Indicators[indicator]
+		if !ok {
+			query = indicator
+		}
+		value, err := getMetricValue(metrics, query, defaultSampler)
+		if err != nil {
+			results = append(results, &keptnv2.SLIResult{Metric: indicator, Success: false, Message: err.Error()})
+			continue
+		}
+		results = append(results, &keptnv2.SLIResult{Metric: indicator, Value: value, Success: true})
+	}
+	return results
+}
+
+// getMetricValue returns the value of a query, which is the name of a metric optionally followed by the sampler,
+// e.g. error_rate;sampler=Login. Without a sampler, the metric of all samples is returned
+func getMetricValue(metrics *TestMetrics, query string, defaultSampler string) (float64, error) {
+	parts := strings.Split(query, ";")
+	metric := strings.TrimSpace(parts[0])
+	sampler := defaultSampler
+	for _, param := range parts[1:] {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) != samplerFilterKey {
+			return 0, fmt.Errorf("invalid query %s", query)
+		}
+		sampler = strings.TrimSpace(keyValue[1])
+	}
+
+	samplerMetrics := metrics.Total
+	if sampler != "" {
+		samplerMetrics = metrics.Samplers[sampler]
+		if samplerMetrics == nil {
+			return 0, fmt.Errorf("no samples of sampler %s", sampler)
+		}
+	}
+	if samplerMetrics == nil {
+		return 0, errors.New("no samples")
+	}
+
+	switch metric {
+	case MetricCount:
+		return float64(samplerMetrics.Count), nil
+	case MetricErrors:
+		return float64(samplerMetrics.Errors), nil
+	case MetricErrorRate:
+		return samplerMetrics.ErrorRate, nil
+	case MetricResponseTime:
+		return samplerMetrics.Avg, nil
+	case MetricResponseTime50:
+		return samplerMetrics.P50, nil
+	case MetricResponseTime90:
+		return samplerMetrics.P90, nil
+	case MetricResponseTime95:
+		return samplerMetrics.P95, nil
+	case MetricResponseTime99:
+		return samplerMetrics.P99, nil
+	case MetricThroughput:
+		return samplerMetrics.Throughput, nil
+	}
+	return 0, fmt.Errorf("unknown metric %s", metric)
+}
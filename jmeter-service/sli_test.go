@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+var testMetrics = &TestMetrics{
+	Context:      "my-context",
+	TestStrategy: TestStrategy_Performance,
+	Start:        time.Date(2022, 4, 15, 10, 0, 0, 0, time.UTC),
+	End:          time.Date(2022, 4, 15, 10, 5, 0, 0, time.UTC),
+	Total:        &SamplerMetrics{Count: 4, Errors: 1, ErrorRate: 0.25, Avg: 250, P50: 200, P90: 400, P95: 400, P99: 400, Throughput: 4},
+	Samplers: map[string]*SamplerMetrics{
+		"Login": {Count: 2, Errors: 1, ErrorRate: 0.5, Avg: 200, P50: 100, P90: 300, P95: 300, P99: 300, Throughput: 5},
+	},
+}
+
+func Test_getSLIResults(t *testing.T) {
+	sliConfig := &SLIConfig{Indicators: map[string]string{
+		"login_error_rate":  "error_rate;sampler=Login",
+		"response_time_p95": "response_time_p95",
+		"invalid_query":     "error_rate;label=Login",
+	}}
+
+	tests := []struct {
+		name       string
+		indicators []string
+		filters    []*keptnv2.SLIFilter
+		want       []*keptnv2.SLIResult
+	}{
+		{
+			name:       "queries and metric names",
+			indicators: []string{"login_error_rate", "response_time_p95", "throughput"},
+			want: []*keptnv2.SLIResult{
+				{Metric: "login_error_rate", Value: 0.5, Success: true},
+				{Metric: "response_time_p95", Value: 400, Success: true},
+				{Metric: "throughput", Value: 4, Success: true},
+			},
+		},
+		{
+			name:       "sampler filter",
+			indicators: []string{"response_time_avg"},
+			filters:    []*keptnv2.SLIFilter{{Key: "sampler", Value: "Login"}},
+			want: []*keptnv2.SLIResult{
+				{Metric: "response_time_avg", Value: 200, Success: true},
+			},
+		},
+		{
+			name:       "invalid indicators",
+			indicators: []string{"invalid_query", "apdex", "count"},
+			filters:    []*keptnv2.SLIFilter{{Key: "sampler", Value: "Checkout"}},
+			want: []*keptnv2.SLIResult{
+				{Metric: "invalid_query", Success: false, Message: "invalid query error_rate;label=Login"},
+				{Metric: "apdex", Success: false, Message: "no samples of sampler Checkout"},
+				{Metric: "count", Success: false, Message: "no samples of sampler Checkout"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getSLIResults(testMetrics, sliConfig, tt.indicators, tt.filters)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("getSLIResults() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func Test_getMetricValue_UnknownMetric(t *testing.T) {
+	if _, err := getMetricValue(testMetrics, "apdex", ""); err == nil || err.Error() != "unknown metric apdex" {
+		t.Errorf("getMetricValue() error = %v, want unknown metric", err)
+	}
+}
+
+func Test_checkTestMetricsMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		start   string
+		end     string
+		wantErr bool
+	}{
+		{"same context", "my-context", "", "", false},
+		{"test run within timeframe", "other-context", "2022-04-15T09:55:00.000Z", "2022-04-15T10:10:00.000Z", false},
+		{"test run outside of timeframe", "other-context", "2022-04-15T10:01:00.000Z", "2022-04-15T10:10:00.000Z", true},
+		{"invalid timeframe", "other-context", "yesterday", "today", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTestMetricsMatch(testMetrics, tt.context, tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkTestMetricsMatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_storeAndGetTestMetrics(t *testing.T) {
+	var storedResource *apimodels.Resource
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			if r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/project/sockshop/stage/dev/service/carts/resource") {
+				body, _ := ioutil.ReadAll(r.Body)
+				resources := apimodels.Resources{}
+				_ = json.Unmarshal(body, &resources)
+				storedResource = resources.Resources[0]
+				w.Write([]byte(`{"version": "1"}`))
+				return
+			}
+			if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/project/sockshop/stage/dev/service/carts/resource/jmeter/metrics.json") && storedResource != nil {
+				marshal, _ := json.Marshal(storedResource)
+				w.Write(marshal)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "Could not find resource"}`))
+		}),
+	)
+	defer ts.Close()
+	os.Setenv("CONFIGURATION_SERVICE", ts.URL)
+	os.Setenv("env", "production")
+
+	if _, err := getTestMetrics("sockshop", "dev", "carts"); err == nil {
+		t.Errorf("getTestMetrics() expected error if no metrics are stored")
+	}
+
+	testInfo := TestInfo{Project: "sockshop", Stage: "dev", Service: "carts"}
+	if err := storeTestMetrics(testInfo, testMetrics); err != nil {
+		t.Fatalf("storeTestMetrics() error = %v", err)
+	}
+	if *storedResource.ResourceURI != JMeterMetricsFilename {
+		t.Errorf("storeTestMetrics() stored resource %s, want %s", *storedResource.ResourceURI, JMeterMetricsFilename)
+	}
+	if _, err := base64.StdEncoding.DecodeString(storedResource.ResourceContent); err != nil {
+		t.Errorf("storeTestMetrics() stored content which is not base64 encoded: %v", err)
+	}
+
+	got, err := getTestMetrics("sockshop", "dev", "carts")
+	if err != nil {
+		t.Fatalf("getTestMetrics() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, testMetrics) {
+		t.Errorf("getTestMetrics() = %+v, want %+v", got, testMetrics)
+	}
+}
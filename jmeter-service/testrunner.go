@@ -192,7 +192,7 @@ func (tr *TestRunner) runWorkload(testInfo TestInfo, workload *Workload) (bool,
 		return false, err
 	}
 
-	err = os.RemoveAll(resultDirectory + "_result.tlf")
+	err = os.RemoveAll(getResultsFileName(resultDirectory))
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultWorkerService is the headless service of the JMeter servers deployed by the chart
+	defaultWorkerService = "jmeter-workers"
+	// defaultWorkerPort is the RMI registry port the JMeter servers listen on
+	defaultWorkerPort = "1099"
+	// defaultDistributedTestTimeout is the maximum duration of a distributed workload without a timeout
+	defaultDistributedTestTimeout = 15 * time.Minute
+	// workerDialTimeout is the timeout for checking whether a JMeter server is reachable
+	workerDialTimeout = 5 * time.Second
+	// workerHeartbeatRetries is the number of consecutive failed checks after which a JMeter server is considered lost
+	workerHeartbeatRetries = 2
+)
+
+// workerHeartbeatInterval is the interval of checking whether the JMeter servers of a running workload are reachable
+var workerHeartbeatInterval = 10 * time.Second
+
+// lookupHost resolves the addresses of the JMeter servers behind the worker service
+var lookupHost = net.LookupHost
+
+// checkWorkerAvailable checks whether the JMeter server listens on the given address
+var checkWorkerAvailable = func(address string) error {
+	conn, err := net.DialTimeout("tcp", address, workerDialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// runJMeter executes a JMeter client and kills it if the context is done before it finished
+var runJMeter = func(ctx context.Context, args []string, directory string) (string, error) {
+	return executeCommandInDirectoryWithContext(ctx, "jmeter", args, directory)
+}
+
+// getWorkerPool returns the addresses of the JMeter servers, which are either configured in JMETER_WORKER_HOSTS as
+// a comma separated list, or resolved from the service JMETER_WORKER_SERVICE
+func getWorkerPool() ([]string, error) {
+	port := os.Getenv("JMETER_WORKER_PORT")
+	if port == "" {
+		port = defaultWorkerPort
+	}
+
+	var hosts []string
+	if configuredHosts := os.Getenv("JMETER_WORKER_HOSTS"); configuredHosts != "" {
+		for _, host := range strings.Split(configuredHosts, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	} else {
+		service := os.Getenv("JMETER_WORKER_SERVICE")
+		if service == "" {
+			service = defaultWorkerService
+		}
+		addresses, err := lookupHost(service)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve JMeter workers of service %s: %w", service, err)
+		}
+		sort.Strings(addresses)
+		hosts = addresses
+	}
+
+	workers := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, port)
+		}
+		workers = append(workers, host)
+	}
+	return workers, nil
+}
+
+// workerLeases are the JMeter servers currently running a workload. A server executes the test plan of a single client
+// at a time, hence concurrent workloads must not share servers
+var workerLeases = &workerLeaseSet{leased: map[string]bool{}}
+
+// workerLeaseRetryInterval is the interval of checking whether a leased JMeter server has been released
+var workerLeaseRetryInterval = 5 * time.Second
+
+type workerLeaseSet struct {
+	mutex  sync.Mutex
+	leased map[string]bool
+}
+
+// tryAcquire leases the worker, unless it is already leased
+func (s *workerLeaseSet) tryAcquire(worker string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.leased[worker] {
+		return false
+	}
+	s.leased[worker] = true
+	return true
+}
+
+func (s *workerLeaseSet) isLeased(worker string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.leased[worker]
+}
+
+func (s *workerLeaseSet) release(workers []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, worker := range workers {
+		delete(s.leased, worker)
+	}
+}
+
+// getAvailableWorkers leases up to count reachable JMeter servers which are not leased by another workload.
+// Unreachable servers are skipped. It also returns the number of reachable servers leased by other workloads
+func getAvailableWorkers(count int) ([]string, int, error) {
+	pool, err := getWorkerPool()
+	if err != nil {
+		return nil, 0, err
+	}
+	workers := []string{}
+	busy := 0
+	for _, worker := range pool {
+		if len(workers) == count {
+			break
+		}
+		if workerLeases.isLeased(worker) {
+			busy++
+			continue
+		}
+		if err := checkWorkerAvailable(worker); err != nil {
+			logger.Warnf("Skipping JMeter worker %s as it is not reachable: %v", worker, err)
+			continue
+		}
+		if !workerLeases.tryAcquire(worker) {
+			busy++
+			continue
+		}
+		workers = append(workers, worker)
+	}
+	return workers, busy, nil
+}
+
+// acquireWorkers leases up to count JMeter servers. If all reachable servers are leased by other workloads, it waits
+// until one of them is released or the context is done. The leases have to be released with releaseWorkers
+func acquireWorkers(ctx context.Context, count int) ([]string, error) {
+	for {
+		workers, busy, err := getAvailableWorkers(count)
+		if err != nil {
+			return nil, err
+		}
+		if len(workers) > 0 {
+			if len(workers) < count {
+				logger.Warnf("Only %d of %d JMeter workers are available", len(workers), count)
+			}
+			return workers, nil
+		}
+		if busy == 0 {
+			return nil, errors.New("no JMeter worker is available")
+		}
+		logger.Infof("Waiting for one of %d JMeter workers running other workloads", busy)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no JMeter worker has been released: %w", ctx.Err())
+		case <-time.After(workerLeaseRetryInterval):
+		}
+	}
+}
+
+// releaseWorkers releases the leases of the JMeter servers
+func releaseWorkers(workers []string) {
+	workerLeases.release(workers)
+}
+
+// watchWorkers checks periodically whether the JMeter servers are reachable until the context is done. If a server
+// cannot be reached by workerHeartbeatRetries consecutive checks, onLost is called with its address and the watch ends
+func watchWorkers(ctx context.Context, workers []string, onLost func(worker string)) {
+	ticker := time.NewTicker(workerHeartbeatInterval)
+	defer ticker.Stop()
+	failures := map[string]int{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, worker := range workers {
+			if err := checkWorkerAvailable(worker); err != nil {
+				failures[worker]++
+				logger.Warnf("JMeter worker %s is not reachable (%d/%d): %v", worker, failures[worker], workerHeartbeatRetries, err)
+				if failures[worker] >= workerHeartbeatRetries {
+					onLost(worker)
+					return
+				}
+				continue
+			}
+			failures[worker] = 0
+		}
+	}
+}
+
+// getRemoteJMeterCLIArguments turns the arguments of a local JMeter run into the arguments of a JMeter client
+// controlling the given workers. Properties of the script are sent to the workers, while the properties configuring how
+// the results are saved remain local
+func getRemoteJMeterCLIArguments(args []string, workers []string) []string {
+	remoteArgs := make([]string, 0, len(args)+4)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-J") && !strings.HasPrefix(arg, "-Jjmeter.") {
+			arg = "-G" + strings.TrimPrefix(arg, "-J")
+		}
+		remoteArgs = append(remoteArgs, arg)
+	}
+	remoteArgs = append(remoteArgs, "-R", strings.Join(workers, ","), "-Jserver.rmi.ssl.disable=true")
+	// the workers send the samples back to the client, hence it has to be reachable via the address of the pod
+	if podIP := os.Getenv("POD_IP"); podIP != "" {
+		remoteArgs = append(remoteArgs, "-Djava.rmi.server.hostname="+podIP)
+	}
+	return remoteArgs
+}
+
+// getDistributedTestTimeout returns the maximum duration of the distributed workload
+func getDistributedTestTimeout(workload *Workload) (time.Duration, error) {
+	if workload.Timeout == "" {
+		return defaultDistributedTestTimeout, nil
+	}
+	timeout, err := time.ParseDuration(workload.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %s of workload %s", workload.Timeout, workload.TestStrategy)
+	}
+	return timeout, nil
+}
+
+// executeDistributed leases the available JMeter servers and runs a single JMeter client controlling all of them.
+// As each server runs the whole test plan, the virtual users of the workload are divided by the number of servers.
+// If the workload does not finish within its timeout, or one of the servers is lost, the client is stopped. The results of the servers are collected
+// by the client and evaluated like the results of a local test run
+func executeDistributed(testInfo TestInfo, workload *Workload, jMeterCommandLineArgs func(vuser int) []string, localTempDir string, resultsFile string, funcValidation bool) (bool, error) {
+	timeout, err := getDistributedTestTimeout(workload)
+	if err != nil {
+		return false, err
+	}
+	count := workload.Workers
+	if workload.VUser < count {
+		count = workload.VUser
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	workers, err := acquireWorkers(ctx, count)
+	if err != nil {
+		return false, err
+	}
+	defer releaseWorkers(workers)
+
+	vuser := workload.VUser / len(workers)
+	if vuser*len(workers) != workload.VUser {
+		logger.Warnf("Running %d instead of %d virtual users, as they cannot be split evenly across %d JMeter workers (%v)",
+			vuser*len(workers), workload.VUser, len(workers), testInfo)
+	}
+	logger.Infof("Running %d virtual users on each of the JMeter workers %v (%v)", vuser, workers, testInfo)
+
+	// the JMeter client does not notice when a server is gone and would wait for its samples until the timeout
+	runCtx, stopRun := context.WithCancel(ctx)
+	defer stopRun()
+	lostWorker := make(chan string, 1)
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		watchWorkers(runCtx, workers, func(worker string) {
+			lostWorker <- worker
+			stopRun()
+		})
+	}()
+
+	args := getRemoteJMeterCLIArguments(jMeterCommandLineArgs(vuser), workers)
+	_, err = runJMeter(runCtx, args, localTempDir)
+	stopRun()
+	<-watchDone
+	select {
+	case worker := <-lostWorker:
+		err = fmt.Errorf("JMeter worker %s has been lost during the distributed test", worker)
+		logger.Error(err.Error())
+		return false, err
+	default:
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return false, fmt.Errorf("distributed test did not finish within %s", timeout)
+		}
+		err = fmt.Errorf("JMeter client of workers %v failed: %w", workers, err)
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	metrics, err := parseJTLFile(resolveResultsFile(resultsFile, localTempDir))
+	if err != nil {
+		return false, fmt.Errorf("could not parse results of distributed test: %w", err)
+	}
+	if workload.TestStrategy != TestStrategy_HealthCheck {
+		storeMetrics(testInfo, workload, metrics)
+	}
+	return evaluateTestMetrics(metrics, testInfo, workload, funcValidation), nil
+}
+
+// resolveResultsFile returns the path of a results file, which JMeter writes relative to its working directory
+func resolveResultsFile(resultsFile string, localTempDir string) string {
+	if filepath.IsAbs(resultsFile) {
+		return resultsFile
+	}
+	return filepath.Join(localTempDir, resultsFile)
+}
+
+// evaluateTestMetrics validates the metrics of a test run like parseJMeterResult validates the summary of a local
+// test run
+func evaluateTestMetrics(metrics *TestMetrics, testInfo TestInfo, workload *Workload, funcValidation bool) bool {
+	if funcValidation && metrics.Total.Errors > 0 {
+		logger.Debugf("Function validation failed because we got %d errors. %v", metrics.Total.Errors, testInfo)
+		return false
+	}
+
+	maxAcceptedErrors := float64(workload.AcceptedErrorRate) * float64(metrics.Total.Count)
+	if metrics.Total.Errors > int(maxAcceptedErrors) {
+		logger.Debugf("Jmeter test failed because we got a too high error rate of %.2f. %v", metrics.Total.ErrorRate, testInfo)
+		return false
+	}
+
+	if workload.AvgRtValidation > 0 && metrics.Total.Avg > float64(workload.AvgRtValidation) {
+		logger.Debugf("Avg rt validation failed because we got an avg rt of %.0f. %v", metrics.Total.Avg, testInfo)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const workerJTLContent = `timeStamp,elapsed,label,success
+1650000000000,100,Login,true
+1650000000100,300,Login,%t
+`
+
+func Test_getRemoteJMeterCLIArguments(t *testing.T) {
+	os.Setenv("POD_IP", "10.0.0.1")
+	defer os.Unsetenv("POD_IP")
+
+	args := []string{"-n", "-t", "load.jmx", "-l", "results", "-Jjmeter.save.saveservice.output_format=csv", "-JVUCount=5"}
+	want := []string{"-n", "-t", "load.jmx", "-l", "results", "-Jjmeter.save.saveservice.output_format=csv", "-GVUCount=5",
+		"-R", "10.0.0.2:1099,10.0.0.3:1099", "-Jserver.rmi.ssl.disable=true", "-Djava.rmi.server.hostname=10.0.0.1"}
+	if got := getRemoteJMeterCLIArguments(args, []string{"10.0.0.2:1099", "10.0.0.3:1099"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("getRemoteJMeterCLIArguments() = %v, want %v", got, want)
+	}
+}
+
+func Test_getAvailableWorkers(t *testing.T) {
+	defer stubWorkerPool([]string{"10.0.0.4", "10.0.0.3", "10.0.0.1", "10.0.0.2"}, map[string]bool{"10.0.0.2:1099": true})()
+
+	workers, busy, err := getAvailableWorkers(2)
+	if err != nil {
+		t.Fatalf("getAvailableWorkers() error = %v", err)
+	}
+	if want := []string{"10.0.0.1:1099", "10.0.0.3:1099"}; !reflect.DeepEqual(workers, want) || busy != 0 {
+		t.Errorf("getAvailableWorkers() = %v, %d, want %v, 0", workers, busy, want)
+	}
+
+	// the leased workers are not handed out to another workload until they are released
+	otherWorkers, busy, err := getAvailableWorkers(2)
+	if err != nil {
+		t.Fatalf("getAvailableWorkers() error = %v", err)
+	}
+	if want := []string{"10.0.0.4:1099"}; !reflect.DeepEqual(otherWorkers, want) || busy != 2 {
+		t.Errorf("getAvailableWorkers() = %v, %d, want %v, 2", otherWorkers, busy, want)
+	}
+	releaseWorkers(workers)
+	releaseWorkers(otherWorkers)
+
+	os.Setenv("JMETER_WORKER_HOSTS", "10.0.0.2, worker:2099")
+	defer os.Unsetenv("JMETER_WORKER_HOSTS")
+	workers, _, err = getAvailableWorkers(2)
+	if err != nil {
+		t.Fatalf("getAvailableWorkers() error = %v", err)
+	}
+	if want := []string{"worker:2099"}; !reflect.DeepEqual(workers, want) {
+		t.Errorf("getAvailableWorkers() = %v, want %v", workers, want)
+	}
+	releaseWorkers(workers)
+}
+
+func Test_acquireWorkers(t *testing.T) {
+	defer stubWorkerPool([]string{"10.0.0.1", "10.0.0.2"}, map[string]bool{"10.0.0.2:1099": true})()
+	originalRetryInterval := workerLeaseRetryInterval
+	workerLeaseRetryInterval = 10 * time.Millisecond
+	defer func() { workerLeaseRetryInterval = originalRetryInterval }()
+
+	workers, err := acquireWorkers(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("acquireWorkers() error = %v", err)
+	}
+	if want := []string{"10.0.0.1:1099"}; !reflect.DeepEqual(workers, want) {
+		t.Errorf("acquireWorkers() = %v, want %v", workers, want)
+	}
+
+	// a second workload waits until the worker is released
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := acquireWorkers(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquireWorkers() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		releaseWorkers(workers)
+	}()
+	otherWorkers, err := acquireWorkers(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("acquireWorkers() error = %v", err)
+	}
+	if !reflect.DeepEqual(otherWorkers, workers) {
+		t.Errorf("acquireWorkers() = %v, want %v", otherWorkers, workers)
+	}
+	releaseWorkers(otherWorkers)
+
+	os.Setenv("JMETER_WORKER_HOSTS", "10.0.0.2")
+	defer os.Unsetenv("JMETER_WORKER_HOSTS")
+	if _, err := acquireWorkers(context.Background(), 2); err == nil {
+		t.Errorf("acquireWorkers() expected error if no worker is available")
+	}
+}
+
+func Test_evaluateTestMetrics(t *testing.T) {
+	metrics := &TestMetrics{Total: &SamplerMetrics{Count: 10, Errors: 1, ErrorRate: 0.1, Avg: 200}}
+	tests := []struct {
+		name           string
+		workload       *Workload
+		funcValidation bool
+		want           bool
+	}{
+		{"accepted error rate", &Workload{AcceptedErrorRate: 0.1}, false, true},
+		{"too high error rate", &Workload{AcceptedErrorRate: 0.05}, false, false},
+		{"function validation", &Workload{AcceptedErrorRate: 0.1}, true, false},
+		{"avg rt validation", &Workload{AcceptedErrorRate: 0.1, AvgRtValidation: 100}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateTestMetrics(metrics, TestInfo{}, tt.workload, tt.funcValidation); got != tt.want {
+				t.Errorf("evaluateTestMetrics() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_executeDistributed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.Write([]byte(`{"version": "1"}`))
+	}))
+	defer ts.Close()
+	os.Setenv("CONFIGURATION_SERVICE", ts.URL)
+	os.Setenv("env", "production")
+	defer stubWorkerPool([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, nil)()
+
+	argsFunc := func(vuser int) []string {
+		return []string{"-l", "results_result.tlf", fmt.Sprintf("-JVUCount=%d", vuser)}
+	}
+
+	tests := []struct {
+		name        string
+		workload    *Workload
+		runJMeter   func(ctx context.Context, args []string, directory string) (string, error)
+		want        bool
+		wantErr     bool
+		wantArgs    []string
+		wantWorkers string
+	}{
+		{
+			name:        "all workers succeed",
+			workload:    &Workload{TestStrategy: TestStrategy_Performance, VUser: 6, Workers: 2, AcceptedErrorRate: 0.5},
+			runJMeter:   fakeJMeterClient(false),
+			want:        true,
+			wantArgs:    []string{"-l", "results_result.tlf", "-GVUCount=3"},
+			wantWorkers: "10.0.0.1:1099,10.0.0.2:1099",
+		},
+		{
+			name:        "too high error rate",
+			workload:    &Workload{TestStrategy: TestStrategy_Performance, VUser: 2, Workers: 4, AcceptedErrorRate: 0.1},
+			runJMeter:   fakeJMeterClient(false),
+			want:        false,
+			wantArgs:    []string{"-l", "results_result.tlf", "-GVUCount=1"},
+			wantWorkers: "10.0.0.1:1099,10.0.0.2:1099",
+		},
+		{
+			name:        "virtual users rounded down",
+			workload:    &Workload{TestStrategy: TestStrategy_Performance, VUser: 7, Workers: 3, AcceptedErrorRate: 0.5},
+			runJMeter:   fakeJMeterClient(true),
+			want:        true,
+			wantArgs:    []string{"-l", "results_result.tlf", "-GVUCount=2"},
+			wantWorkers: "10.0.0.1:1099,10.0.0.2:1099,10.0.0.3:1099",
+		},
+		{
+			name:     "client fails",
+			workload: &Workload{TestStrategy: TestStrategy_Performance, VUser: 3, Workers: 3},
+			runJMeter: func(ctx context.Context, args []string, directory string) (string, error) {
+				return "", errors.New("connection refused")
+			},
+			wantErr: true,
+		},
+		{
+			name:     "timeout",
+			workload: &Workload{TestStrategy: TestStrategy_Performance, VUser: 3, Workers: 3, Timeout: "50ms"},
+			runJMeter: func(ctx context.Context, args []string, directory string) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			wantErr: true,
+		},
+		{
+			name:      "invalid timeout",
+			workload:  &Workload{TestStrategy: TestStrategy_Performance, VUser: 3, Workers: 3, Timeout: "soon"},
+			runJMeter: fakeJMeterClient(false),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]string
+			originalRunJMeter := runJMeter
+			defer func() { runJMeter = originalRunJMeter }()
+			runJMeter = func(ctx context.Context, args []string, directory string) (string, error) {
+				calls = append(calls, args)
+				return tt.runJMeter(ctx, args, directory)
+			}
+
+			testInfo := TestInfo{Project: "sockshop", Stage: "dev", Service: "carts", Context: "my-context"}
+			got, err := executeDistributed(testInfo, tt.workload, argsFunc, t.TempDir(), "results_result.tlf", false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeDistributed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("executeDistributed() = %v, want %v", got, tt.want)
+			}
+			if tt.wantArgs != nil {
+				if len(calls) != 1 {
+					t.Fatalf("executeDistributed() started %d JMeter clients, want 1", len(calls))
+				}
+				if !reflect.DeepEqual(calls[0][:3], tt.wantArgs) || calls[0][4] != tt.wantWorkers {
+					t.Errorf("executeDistributed() started JMeter client with %v, want %v -R %s", calls[0], tt.wantArgs, tt.wantWorkers)
+				}
+			}
+			// the workers are released after the workload
+			for _, worker := range []string{"10.0.0.1:1099", "10.0.0.2:1099", "10.0.0.3:1099"} {
+				if workerLeases.isLeased(worker) {
+					t.Errorf("executeDistributed() did not release worker %s", worker)
+				}
+			}
+		})
+	}
+}
+
+func Test_executeDistributedWorkerLost(t *testing.T) {
+	defer stubWorkerPool([]string{"10.0.0.1", "10.0.0.2"}, nil)()
+	originalHeartbeatInterval := workerHeartbeatInterval
+	originalRunJMeter := runJMeter
+	defer func() {
+		workerHeartbeatInterval = originalHeartbeatInterval
+		runJMeter = originalRunJMeter
+	}()
+	workerHeartbeatInterval = 10 * time.Millisecond
+
+	// the second worker goes away once the test is running
+	var lost int32
+	checkWorkerAvailable = func(address string) error {
+		if address == "10.0.0.2:1099" && atomic.LoadInt32(&lost) == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	runJMeter = func(ctx context.Context, args []string, directory string) (string, error) {
+		atomic.StoreInt32(&lost, 1)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+
+	argsFunc := func(vuser int) []string {
+		return []string{"-l", "results_result.tlf", fmt.Sprintf("-JVUCount=%d", vuser)}
+	}
+	testInfo := TestInfo{Project: "sockshop", Stage: "dev", Service: "carts", Context: "my-context"}
+	workload := &Workload{TestStrategy: TestStrategy_Performance, VUser: 2, Workers: 2, Timeout: "1m"}
+	start := time.Now()
+	got, err := executeDistributed(testInfo, workload, argsFunc, t.TempDir(), "results_result.tlf", false)
+	if err == nil || !strings.Contains(err.Error(), "10.0.0.2:1099") {
+		t.Errorf("executeDistributed() error = %v, want error about lost worker 10.0.0.2:1099", err)
+	}
+	if got {
+		t.Errorf("executeDistributed() = %v, want false", got)
+	}
+	if time.Since(start) > 30*time.Second {
+		t.Errorf("executeDistributed() did not stop the JMeter client when the worker was lost")
+	}
+	for _, worker := range []string{"10.0.0.1:1099", "10.0.0.2:1099"} {
+		if workerLeases.isLeased(worker) {
+			t.Errorf("executeDistributed() did not release worker %s", worker)
+		}
+	}
+}
+
+func Test_executeCommandInDirectoryWithContext(t *testing.T) {
+	if _, err := executeCommandInDirectoryWithContext(context.Background(), "sh", []string{"-c", "echo ok"}, t.TempDir()); err != nil {
+		t.Errorf("executeCommandInDirectoryWithContext() error = %v", err)
+	}
+	if _, err := executeCommandInDirectoryWithContext(context.Background(), "sh", []string{"-c", "exit 1"}, t.TempDir()); err == nil {
+		t.Errorf("executeCommandInDirectoryWithContext() expected error if the command fails")
+	}
+
+	// the child process keeps the output open, hence it has to be killed as well
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := executeCommandInDirectoryWithContext(ctx, "sh", []string{"-c", "sleep 10 & wait"}, t.TempDir())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("executeCommandInDirectoryWithContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("executeCommandInDirectoryWithContext() did not stop the command")
+	}
+}
+
+// fakeJMeterClient returns a fake JMeter client writing a results file with two samples
+func fakeJMeterClient(success bool) func(ctx context.Context, args []string, directory string) (string, error) {
+	return func(ctx context.Context, args []string, directory string) (string, error) {
+		return "", ioutil.WriteFile(filepath.Join(directory, args[1]), []byte(fmt.Sprintf(workerJTLContent, success)), 0644)
+	}
+}
+
+// stubWorkerPool stubs the resolution of the worker service and the availability check of the workers and returns
+// a function restoring them
+func stubWorkerPool(addresses []string, unavailable map[string]bool) func() {
+	originalLookupHost := lookupHost
+	originalCheckWorkerAvailable := checkWorkerAvailable
+	lookupHost = func(host string) ([]string, error) {
+		if host != defaultWorkerService {
+			return nil, fmt.Errorf("unknown host %s", host)
+		}
+		return addresses, nil
+	}
+	checkWorkerAvailable = func(address string) error {
+		if unavailable[address] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	return func() {
+		lookupHost = originalLookupHost
+		checkWorkerAvailable = originalCheckWorkerAvailable
+	}
+}
@@ -40,8 +40,33 @@ type criteriaObject struct {
 	CheckPercentage bool
 	IsComparison    bool
 	CheckIncrease   bool
+	// Statistic is set for criteria comparing the value against a band around the previous results, e.g. <=3sigma
+	Statistic string
+	// CheckBand is set if a statistical criteria has no sign, i.e. the value must be within the band in both directions
+	CheckBand bool
 }
 
+const (
+	// statisticSigma compares the value against a band of n standard deviations around the mean of the previous results
+	statisticSigma = "sigma"
+	// statisticMAD compares the value against a band of n (scaled) median absolute deviations around the median of the previous results
+	statisticMAD = "mad"
+)
+
+const (
+	// aggregateFunctionEWMA weights the previous results exponentially, with the most recent result having the highest weight.
+	// The smoothing factor can be passed as a parameter, e.g. ewma(0.3)
+	aggregateFunctionEWMA = "ewma"
+	// aggregateFunctionTrend fits a linear regression line through the previous results and uses its value at the oldest result as baseline,
+	// so that a gradual regression accumulates over the compared runs instead of being hidden by a moving baseline
+	aggregateFunctionTrend = "trend"
+)
+
+const defaultEWMASmoothingFactor = 0.5
+
+// madScaleFactor makes the median absolute deviation a consistent estimator of the standard deviation for normally distributed values
+const madScaleFactor = 1.4826
+
 type EvaluateSLIHandler struct {
 	Event            cloudevents.Event
 	HTTPClient       *http.Client
@@ -160,14 +185,7 @@ func (eh *EvaluateSLIHandler) processGetSliFinishedEvent(ctx context.Context, sh
 	}
 
 	// get results of previous evaluations from data store (mongodb-datastore)
-	numberOfPreviousResults := 3
-	if sloConfig.Comparison.CompareWith == "single_result" {
-		numberOfPreviousResults = 1
-	} else if sloConfig.Comparison.CompareWith == "several_results" {
-		numberOfPreviousResults = sloConfig.Comparison.NumberOfComparisonResults
-	}
-
-	previousEvaluationEvents, comparisonEventIDs, err := eh.getPreviousEvaluations(e, numberOfPreviousResults, sloConfig.Comparison.IncludeResultWithScore)
+	previousEvaluationEvents, comparisonEventIDs, err := eh.getPreviousEvaluations(e, getNumberOfPreviousResults(sloConfig), sloConfig.Comparison.IncludeResultWithScore)
 	if err != nil {
 		return sendErroredFinishedEventWithMessage(shkeptncontext, triggeredID, commitID, err.Error(), string(sloFileContent), eh.KeptnHandler, e)
 	}
@@ -179,20 +197,51 @@ func (eh *EvaluateSLIHandler) processGetSliFinishedEvent(ctx context.Context, sh
 		filteredPreviousEvaluationEvents = append(filteredPreviousEvaluationEvents, val)
 	}
 
-	evaluationResult, maximumAchievableScore, keySLIFailed := evaluateObjectives(e, sloConfig, filteredPreviousEvaluationEvents)
+	evaluationResult, err := evaluateSLIs(e, sloConfig, sloFileContent, filteredPreviousEvaluationEvents, comparisonEventIDs)
+	if err != nil {
+		return sendErroredFinishedEventWithMessage(shkeptncontext, triggeredID, commitID, err.Error(), string(sloFileContent), eh.KeptnHandler, e)
+	}
+	logger.Debug("Evaluation result: " + string(evaluationResult.Result))
+
+	return sendEvent(shkeptncontext, triggeredEvents[0].ID, keptnv2.GetFinishedEventType(keptnv2.EvaluationTaskName), commitID, eh.KeptnHandler, evaluationResult)
+}
+
+// getNumberOfPreviousResults returns the number of previous evaluations the SLI results are compared with
+func getNumberOfPreviousResults(sloConfig *keptn.ServiceLevelObjectives) int {
+	numberOfPreviousResults := 3
+	if sloConfig.Comparison.CompareWith == "single_result" {
+		numberOfPreviousResults = 1
+	} else if sloConfig.Comparison.CompareWith == "several_results" {
+		numberOfPreviousResults = sloConfig.Comparison.NumberOfComparisonResults
+	}
+	return numberOfPreviousResults
+}
+
+// evaluateSLIs evaluates the SLI results against the objectives of the SLO file and calculates the total score
+func evaluateSLIs(e *keptnv2.GetSLIFinishedEventData, sloConfig *keptn.ServiceLevelObjectives, sloFileContent []byte, previousEvaluationEvents []*keptnv2.EvaluationFinishedEventData, comparisonEventIDs []string) (*EvaluationFinishedEventData, error) {
+	evaluationResult, maximumAchievableScore, keySLIFailed := evaluateObjectives(e, sloConfig, previousEvaluationEvents)
 	evaluationResult.Labels = e.Labels
 	evaluationResult.Evaluation.ComparedEvents = comparisonEventIDs
 
+	groups, objectiveGroups, err := parseSLOGroups(sloFileContent)
+	if err != nil {
+		return nil, errors.New("Could not parse scoring groups: " + err.Error())
+	}
+
 	// calculate the total score
-	err = calculateScore(maximumAchievableScore, evaluationResult, sloConfig, keySLIFailed)
+	var groupResults []*SLOGroupResult
+	if len(groups) > 0 {
+		groupResults, err = calculateGroupedScore(maximumAchievableScore, evaluationResult, sloConfig, groups, objectiveGroups, keySLIFailed)
+	} else {
+		err = calculateScore(maximumAchievableScore, evaluationResult, sloConfig, keySLIFailed)
+	}
 	if err != nil {
-		return sendErroredFinishedEventWithMessage(shkeptncontext, triggeredID, commitID, err.Error(), string(sloFileContent), eh.KeptnHandler, e)
+		return nil, err
 	}
-	logger.Debug("Evaluation result: " + string(evaluationResult.Result))
 
 	evaluationResult.Evaluation.SLOFileContent = base64.StdEncoding.EncodeToString(sloFileContent)
 
-	return sendEvent(shkeptncontext, triggeredEvents[0].ID, keptnv2.GetFinishedEventType(keptnv2.EvaluationTaskName), commitID, eh.KeptnHandler, evaluationResult)
+	return newEvaluationFinishedEventData(evaluationResult, groupResults), nil
 }
 
 func evaluateObjectives(e *keptnv2.GetSLIFinishedEventData, sloConfig *keptn.ServiceLevelObjectives, previousEvaluationEvents []*keptnv2.EvaluationFinishedEventData) (*keptnv2.EvaluationFinishedEventData, float64, bool) {
@@ -325,6 +374,11 @@ func calculateScore(maximumAchievableScore float64, evaluationResult *keptnv2.Ev
 	}
 	achievedPercentage := 100.0 * (totalScore / maximumAchievableScore)
 	evaluationResult.Evaluation.Score = achievedPercentage
+	return evaluateTotalScore(achievedPercentage, evaluationResult, sloConfig, keySLIFailed)
+}
+
+// evaluateTotalScore sets the result of the evaluation based on the achieved score and the total_score targets of the SLO file
+func evaluateTotalScore(achievedPercentage float64, evaluationResult *keptnv2.EvaluationFinishedEventData, sloConfig *keptn.ServiceLevelObjectives, keySLIFailed bool) error {
 	if sloConfig.TotalScore == nil || sloConfig.TotalScore.Pass == "" {
 		return errors.New("no target score defined")
 	}
@@ -428,6 +482,10 @@ func evaluateSingleCriteria(sliResult *keptnv2.SLIResult, criteria string, previ
 		return false, err
 	}
 
+	if co.Statistic != "" {
+		return evaluateStatisticalComparison(sliResult, co, previousResults, violation)
+	}
+
 	if !co.IsComparison {
 		//compared value is used only if the criteria is a comparison without fixed threshold,
 		//anyway we calculate it here to allow Bridge to display it
@@ -465,30 +523,121 @@ func evaluateComparison(sliResult *keptnv2.SLIResult, co *criteriaObject, previo
 	return evaluateValue(sliResult.Value, targetValue, co.Operator)
 }
 
+// evaluateStatisticalComparison checks if the value lies within a band of co.Value standard deviations (or median absolute deviations)
+// around the mean (or median) of the previous results. Unsigned criteria (e.g. <=3sigma) check both sides of the band,
+// while criteria with a sign (e.g. <=+3sigma, >=-3sigma) only check the upper or lower bound of the band
+func evaluateStatisticalComparison(sliResult *keptnv2.SLIResult, co *criteriaObject, previousResults []*keptnv2.SLIEvaluationResult, violation *keptnv2.SLITarget) (bool, error) {
+	previousValues := getSuccessfulValues(previousResults)
+	// at least two values are needed to calculate the spread of the previous results
+	if len(previousValues) < 2 {
+		sliResult.ComparedValue = calculateAverage(previousValues)
+		return true, nil
+	}
+
+	var center, spread float64
+	var centerName, spreadName string
+	switch co.Statistic {
+	case statisticSigma:
+		center, spread = calculateAverage(previousValues), calculateStandardDeviation(previousValues)
+		centerName, spreadName = "mean", "standard deviations"
+	case statisticMAD:
+		center, spread = calculateMedian(previousValues), madScaleFactor*calculateMedianAbsoluteDeviation(previousValues)
+		centerName, spreadName = "median", "median absolute deviations"
+	default:
+		return false, fmt.Errorf("unknown statistic %s", co.Statistic)
+	}
+	sliResult.ComparedValue = center
+
+	upperBound := center + co.Value*spread
+	lowerBound := center - co.Value*spread
+
+	// the bound that is checked, together with the operator the value is compared against it with
+	checkUpperBound := co.CheckIncrease || (co.CheckBand && sliResult.Value >= center)
+	bound, operator := lowerBound, co.Operator
+	if checkUpperBound {
+		bound = upperBound
+	} else if co.CheckBand {
+		// e.g. <=2sigma requires the value to be >= the lower bound of the band
+		operator = invertOperator(co.Operator)
+	}
+	violation.TargetValue = bound
+
+	satisfied, err := evaluateValue(sliResult.Value, bound, operator)
+	if err != nil {
+		return false, err
+	}
+	if spread == 0 && sliResult.Value == center {
+		// all previous results are equal, i.e. the band collapses to a single value. A value equal to it never violates
+		// the criteria, even if a strict operator is used, while any deviation in the checked direction does
+		satisfied = true
+	}
+
+	if !satisfied {
+		// the criteria is kept as written, while the checked bound and the statistic causing the violation are described in the message
+		if spread == 0 {
+			addResultMessage(sliResult, fmt.Sprintf("criteria %s violated: value %v is not %s%.2f, it deviates from the %s %v of %d previous results, which are all equal",
+				violation.Criteria, sliResult.Value, operator, bound, centerName, center, len(previousValues)))
+		} else {
+			addResultMessage(sliResult, fmt.Sprintf("criteria %s violated: value %v is not %s%.2f, it deviates by %.2f %s from the %s %v of %d previous results (bounds: [%.2f, %.2f])",
+				violation.Criteria, sliResult.Value, operator, bound, math.Abs(sliResult.Value-center)/spread, spreadName, centerName, center, len(previousValues), lowerBound, upperBound))
+		}
+	}
+	return satisfied, nil
+}
+
+// invertOperator returns the operator checking the opposite direction, e.g. >= for <=
+func invertOperator(operator string) string {
+	switch operator {
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	}
+	return operator
+}
+
+func addResultMessage(sliResult *keptnv2.SLIResult, message string) {
+	if sliResult.Message != "" {
+		sliResult.Message += "; "
+	}
+	sliResult.Message += message
+}
+
+// getSuccessfulValues returns the values of all successful previous results
+func getSuccessfulValues(previousResults []*keptnv2.SLIEvaluationResult) []float64 {
+	var previousValues []float64
+	for _, val := range previousResults {
+		if val.Value != nil && val.Value.Success {
+			previousValues = append(previousValues, val.Value.Value)
+		}
+	}
+	return previousValues
+}
+
 //aggregateValues combines the previous values into a single one, based on the aggregation function
 //it returns the aggregated value and a boolean telling if the rest of the evaluation should be skipped
 //(no previous results or no successful previous results)
+//the previous results are expected to be ordered from the most recent to the oldest result, as returned by the datastore
 func aggregateValues(previousResults []*keptnv2.SLIEvaluationResult, comparison *keptn.SLOComparison) (float64, bool) {
 
 	if len(previousResults) == 0 {
 		// if no comparison values are available, the evaluation passes
 		return 0, true
 	}
-	var previousValues []float64
-	for _, val := range previousResults {
-		if val.Value.Success == true {
-			// always include
-			previousValues = append(previousValues, val.Value.Value)
-		}
-	}
+	previousValues := getSuccessfulValues(previousResults)
 
 	if len(previousValues) == 0 {
 		// if no comparison values are available, the evaluation passes
 		return 0, true
 	}
 	var aggregatedValue float64
-	// aggregate the previous values based on the passed aggregation function
-	switch comparison.AggregateFunction {
+	// aggregate the previous values based on the passed aggregation function, whose name is matched case-insensitively
+	name, parameter := parseAggregateFunction(comparison.AggregateFunction)
+	switch name {
 	case "avg":
 		aggregatedValue = calculateAverage(previousValues)
 	case "p50":
@@ -497,12 +646,97 @@ func aggregateValues(previousResults []*keptnv2.SLIEvaluationResult, comparison
 		aggregatedValue = calculatePercentile(sort.Float64Slice(previousValues), 0.9)
 	case "p95":
 		aggregatedValue = calculatePercentile(sort.Float64Slice(previousValues), 0.95)
-	default:
-		break
+	case aggregateFunctionTrend:
+		aggregatedValue = calculateTrendBaseline(getTrendPoints(previousResults))
+	case aggregateFunctionEWMA:
+		aggregatedValue = calculateEWMA(previousValues, getEWMASmoothingFactor(parameter))
 	}
 	return aggregatedValue, false
 }
 
+// parseAggregateFunction splits an aggregate function of the form name(parameter) into its name and parameter
+func parseAggregateFunction(aggregateFunction string) (string, string) {
+	aggregateFunction = strings.ToLower(strings.Replace(aggregateFunction, " ", "", -1))
+	openIndex := strings.Index(aggregateFunction, "(")
+	if openIndex < 0 || !strings.HasSuffix(aggregateFunction, ")") {
+		return aggregateFunction, ""
+	}
+	return aggregateFunction[:openIndex], aggregateFunction[openIndex+1 : len(aggregateFunction)-1]
+}
+
+func getEWMASmoothingFactor(parameter string) float64 {
+	if parameter == "" {
+		return defaultEWMASmoothingFactor
+	}
+	alpha, err := strconv.ParseFloat(parameter, 64)
+	if err != nil || alpha <= 0 || alpha > 1 {
+		logger.Warnf("Invalid smoothing factor %s for aggregate function %s. Using default value %v", parameter, aggregateFunctionEWMA, defaultEWMASmoothingFactor)
+		return defaultEWMASmoothingFactor
+	}
+	return alpha
+}
+
+// calculateEWMA returns the exponentially weighted moving average of values ordered from the most recent to the oldest value
+func calculateEWMA(values []float64, alpha float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	ewma := values[len(values)-1]
+	for i := len(values) - 2; i >= 0; i-- {
+		ewma = alpha*values[i] + (1-alpha)*ewma
+	}
+	return ewma
+}
+
+// trendPoint is a previous result placed on the x-axis of the trend, with x = 0 for the oldest previous result
+type trendPoint struct {
+	x float64
+	y float64
+}
+
+// getTrendPoints returns the values of all successful previous results as points of the trend. The x-index is the position of the result
+// within all previous results, so that failed results leave a gap instead of shifting the remaining results closer together
+func getTrendPoints(previousResults []*keptnv2.SLIEvaluationResult) []trendPoint {
+	points := []trendPoint{}
+	for i, val := range previousResults {
+		if val.Value != nil && val.Value.Success {
+			points = append(points, trendPoint{x: float64(len(previousResults) - 1 - i), y: val.Value.Value})
+		}
+	}
+	return points
+}
+
+// calculateTrendSlope returns the slope of the least squares regression line through the points, i.e. the average change per run
+func calculateTrendSlope(points []trendPoint) float64 {
+	if len(points) < 2 {
+		return 0.0
+	}
+	n := float64(len(points))
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for _, point := range points {
+		sumX += point.x
+		sumY += point.y
+		sumXY += point.x * point.y
+		sumXX += point.x * point.x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
+
+// calculateTrendBaseline returns the value of the least squares regression line through the points at the oldest previous result
+func calculateTrendBaseline(points []trendPoint) float64 {
+	if len(points) == 0 {
+		return 0.0
+	}
+	sumX, sumY := 0.0, 0.0
+	for _, point := range points {
+		sumX += point.x
+		sumY += point.y
+	}
+	n := float64(len(points))
+	// the regression line passes through the mean of x and y
+	return sumY/n - calculateTrendSlope(points)*sumX/n
+}
+
 func calculateAverage(values []float64) float64 {
 	sum := 0.0
 
@@ -543,6 +777,45 @@ func calculatePercentile(values sort.Float64Slice, perc float64) float64 {
 	return scores[0]
 }
 
+// calculateStandardDeviation returns the sample standard deviation of the values
+func calculateStandardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0.0
+	}
+	mean := calculateAverage(values)
+	sumOfSquares := 0.0
+	for _, value := range values {
+		sumOfSquares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sumOfSquares / float64(len(values)-1))
+}
+
+func calculateMedian(values []float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// calculateMedianAbsoluteDeviation returns the median of the absolute deviations of the values from their median
+func calculateMedianAbsoluteDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+	median := calculateMedian(values)
+	deviations := make([]float64, 0, len(values))
+	for _, value := range values {
+		deviations = append(deviations, math.Abs(value-median))
+	}
+	return calculateMedian(deviations)
+}
+
 func evaluateFixedThreshold(sliResult *keptnv2.SLIResult, co *criteriaObject, violation *keptnv2.SLITarget) (bool, error) {
 	violation.TargetValue = co.Value
 	return evaluateValue(sliResult.Value, co.Value, co.Operator)
@@ -566,7 +839,7 @@ func evaluateValue(measured float64, expected float64, operator string) (bool, e
 }
 
 func parseCriteriaString(criteria string) (*criteriaObject, error) {
-	// example values: <+15%, <500, >-8%, =0
+	// example values: <+15%, <500, >-8%, =0, <=3sigma, <=+2.5mad
 	// possible operators: <, <=, =, >, >=
 	// regex: ^([<|<=|=|>|>=]{1,2})([+|-]{0,1}\\d*\.?\d*)([%]{0,1})
 	regex := `^([<|<=|=|>|>=]{1,2})([+|-]{0,1}\d*\.?\d*)([%]{0,1})`
@@ -592,6 +865,15 @@ func parseCriteriaString(criteria string) (*criteriaObject, error) {
 		}
 	}
 
+	for _, statistic := range []string{statisticSigma, statisticMAD} {
+		if strings.HasSuffix(strings.ToLower(criteria), statistic) {
+			c.Statistic = statistic
+			c.IsComparison = true
+			criteria = criteria[:len(criteria)-len(statistic)]
+			break
+		}
+	}
+
 	if strings.HasSuffix(criteria, "%") {
 		c.CheckPercentage = true
 		c.IsComparison = true // Issue #1498: criteria containing '%' is always a comparison
@@ -607,6 +889,12 @@ func parseCriteriaString(criteria string) (*criteriaObject, error) {
 		c.IsComparison = true
 		c.CheckIncrease = true
 		criteria = strings.TrimPrefix(criteria, "+")
+	} else if c.Statistic != "" {
+		c.CheckBand = true
+	}
+
+	if c.Statistic != "" && c.CheckPercentage {
+		return nil, errors.New("statistical criteria cannot be combined with a percentage")
 	}
 
 	floatValue, err := strconv.ParseFloat(criteria, 64)
@@ -620,6 +908,11 @@ func parseCriteriaString(criteria string) (*criteriaObject, error) {
 
 // gets previous evaluation.finished events from mongodb-datastore
 func (eh *EvaluateSLIHandler) getPreviousEvaluations(e *keptnv2.GetSLIFinishedEventData, numberOfPreviousResults int, includeResult string) ([]*keptnv2.EvaluationFinishedEventData, []string, error) {
+	return getPreviousEvaluations(eh.HTTPClient, e, numberOfPreviousResults, includeResult)
+}
+
+// getPreviousEvaluations retrieves the most recent evaluation.finished events of the service from the datastore
+func getPreviousEvaluations(httpClient *http.Client, e *keptnv2.GetSLIFinishedEventData, numberOfPreviousResults int, includeResult string) ([]*keptnv2.EvaluationFinishedEventData, []string, error) {
 	var evaluationDoneEvents []*keptnv2.EvaluationFinishedEventData
 	var eventIDs []string
 
@@ -645,7 +938,7 @@ func (eh *EvaluateSLIHandler) getPreviousEvaluations(e *keptnv2.GetSLIFinishedEv
 
 	req, err := http.NewRequest("GET", getDatastoreURL()+"/event/type/"+keptnv2.GetFinishedEventType(keptnv2.EvaluationTaskName)+"?"+queryString, nil)
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := eh.HTTPClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -127,6 +127,36 @@ func TestParseCriteriaString(t *testing.T) {
 				CheckIncrease:   true,
 			},
 		},
+		{
+			Criteria: "<=3sigma",
+			ExpectedCriteriaObject: &criteriaObject{
+				Operator:     "<=",
+				Value:        3,
+				IsComparison: true,
+				Statistic:    statisticSigma,
+				CheckBand:    true,
+			},
+		},
+		{
+			Criteria: "<= +2.5 sigma",
+			ExpectedCriteriaObject: &criteriaObject{
+				Operator:      "<=",
+				Value:         2.5,
+				IsComparison:  true,
+				CheckIncrease: true,
+				Statistic:     statisticSigma,
+			},
+		},
+		{
+			Criteria: ">=-3MAD",
+			ExpectedCriteriaObject: &criteriaObject{
+				Operator:      ">=",
+				Value:         3,
+				IsComparison:  true,
+				CheckIncrease: false,
+				Statistic:     statisticMAD,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -137,10 +167,17 @@ func TestParseCriteriaString(t *testing.T) {
 			assert.EqualValues(t, test.ExpectedCriteriaObject.CheckPercentage, co.CheckPercentage)
 			assert.EqualValues(t, test.ExpectedCriteriaObject.IsComparison, co.IsComparison)
 			assert.EqualValues(t, test.ExpectedCriteriaObject.CheckIncrease, co.CheckIncrease)
+			assert.EqualValues(t, test.ExpectedCriteriaObject.Statistic, co.Statistic)
+			assert.EqualValues(t, test.ExpectedCriteriaObject.CheckBand, co.CheckBand)
 		})
 	}
 }
 
+func TestParseCriteriaString_InvalidStatisticalCriteria(t *testing.T) {
+	_, err := parseCriteriaString("<=3%sigma")
+	assert.NotNil(t, err)
+}
+
 type evaluateValueTestObject struct {
 	Name           string
 	MeasuredValue  float64
@@ -335,6 +372,349 @@ func TestCalculatePercentile(t *testing.T) {
 	}
 }
 
+func TestCalculateEWMA(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []float64
+		alpha         float64
+		expectedValue float64
+	}{
+		{
+			name:          "no values",
+			values:        []float64{},
+			alpha:         0.5,
+			expectedValue: 0,
+		},
+		{
+			name:          "single value",
+			values:        []float64{10},
+			alpha:         0.5,
+			expectedValue: 10,
+		},
+		{
+			name: "most recent value has the highest weight",
+			// oldest: 8 => 0.5*12 + 0.5*8 = 10 => 0.5*16 + 0.5*10 = 13
+			values:        []float64{16, 12, 8},
+			alpha:         0.5,
+			expectedValue: 13,
+		},
+		{
+			name:          "alpha of 1 only considers the most recent value",
+			values:        []float64{16, 12, 8},
+			alpha:         1,
+			expectedValue: 16,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expectedValue, calculateEWMA(tt.values, tt.alpha), 0.0001)
+		})
+	}
+}
+
+func TestCalculateTrend(t *testing.T) {
+	tests := []struct {
+		name             string
+		previousResults  []*keptnv2.SLIEvaluationResult
+		expectedSlope    float64
+		expectedBaseline float64
+	}{
+		{
+			name:             "no values",
+			previousResults:  newPreviousSLIResults(),
+			expectedSlope:    0,
+			expectedBaseline: 0,
+		},
+		{
+			name:             "single value",
+			previousResults:  newPreviousSLIResults(10),
+			expectedSlope:    0,
+			expectedBaseline: 10,
+		},
+		{
+			name:             "constant values",
+			previousResults:  newPreviousSLIResults(10, 10, 10),
+			expectedSlope:    0,
+			expectedBaseline: 10,
+		},
+		{
+			name:             "linear increase",
+			previousResults:  newPreviousSLIResults(16, 14, 12, 10),
+			expectedSlope:    2,
+			expectedBaseline: 10,
+		},
+		{
+			name:             "noisy increase",
+			previousResults:  newPreviousSLIResults(15, 15, 11, 11),
+			expectedSlope:    1.6,
+			expectedBaseline: 10.6,
+		},
+		{
+			name:             "failed result does not shift the remaining results",
+			previousResults:  withFailedResult(newPreviousSLIResults(16, 14, 12, 10), 1),
+			expectedSlope:    2,
+			expectedBaseline: 10,
+		},
+		{
+			name:             "failed oldest result",
+			previousResults:  withFailedResult(newPreviousSLIResults(16, 14, 12, 10), 3),
+			expectedSlope:    2,
+			expectedBaseline: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := getTrendPoints(tt.previousResults)
+			assert.InDelta(t, tt.expectedSlope, calculateTrendSlope(points), 0.0001)
+			assert.InDelta(t, tt.expectedBaseline, calculateTrendBaseline(points), 0.0001)
+		})
+	}
+}
+
+func withFailedResult(results []*keptnv2.SLIEvaluationResult, index int) []*keptnv2.SLIEvaluationResult {
+	results[index].Value.Success = false
+	results[index].Value.Value = 0
+	return results
+}
+
+func TestParseAggregateFunction(t *testing.T) {
+	name, parameter := parseAggregateFunction("ewma(0.3)")
+	assert.Equal(t, aggregateFunctionEWMA, name)
+	assert.Equal(t, "0.3", parameter)
+
+	name, parameter = parseAggregateFunction(" EWMA ")
+	assert.Equal(t, aggregateFunctionEWMA, name)
+	assert.Empty(t, parameter)
+
+	assert.Equal(t, 0.3, getEWMASmoothingFactor("0.3"))
+	assert.Equal(t, defaultEWMASmoothingFactor, getEWMASmoothingFactor(""))
+	assert.Equal(t, defaultEWMASmoothingFactor, getEWMASmoothingFactor("2"))
+	assert.Equal(t, defaultEWMASmoothingFactor, getEWMASmoothingFactor("abc"))
+}
+
+func TestAggregateValues_CaseInsensitiveFunctionNames(t *testing.T) {
+	tests := []struct {
+		aggregateFunction string
+		previousResults   []*keptnv2.SLIEvaluationResult
+		expectedValue     float64
+	}{
+		{aggregateFunction: "AVG", previousResults: newPreviousSLIResults(16, 12, 8), expectedValue: 12},
+		{aggregateFunction: "Trend", previousResults: newPreviousSLIResults(16, 14, 12, 10), expectedValue: 10},
+		{aggregateFunction: " TREND ", previousResults: newPreviousSLIResults(16, 14, 12, 10), expectedValue: 10},
+		{aggregateFunction: "EWMA(0.5)", previousResults: newPreviousSLIResults(16, 12, 8), expectedValue: 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregateFunction, func(t *testing.T) {
+			value, skip := aggregateValues(tt.previousResults, &apimodelsv2.SLOComparison{AggregateFunction: tt.aggregateFunction})
+			assert.False(t, skip)
+			assert.InDelta(t, tt.expectedValue, value, 0.0001)
+		})
+	}
+}
+
+// a service that gets 2% slower with each build must eventually fail a <=+10% criteria when comparing with the trend of the previous results,
+// while it never fails when comparing with the average of the previous results
+func TestEvaluateComparison_GradualRegression(t *testing.T) {
+	const numberOfComparisonResults = 5
+
+	evaluate := func(aggregateFunction string) int {
+		comparison := &apimodelsv2.SLOComparison{
+			CompareWith:               "several_results",
+			IncludeResultWithScore:    "all",
+			NumberOfComparisonResults: numberOfComparisonResults,
+			AggregateFunction:         aggregateFunction,
+		}
+		value := 100.0
+		var history []float64 // most recent value first
+		for build := 1; build <= 20; build++ {
+			value *= 1.02
+			co, _ := parseCriteriaString("<=+10%")
+			sliResult := &keptnv2.SLIResult{Metric: "response_time", Value: value, Success: true}
+			previous := history
+			if len(previous) > numberOfComparisonResults {
+				previous = previous[:numberOfComparisonResults]
+			}
+			passed, err := evaluateComparison(sliResult, co, newPreviousSLIResults(previous...), comparison, &keptnv2.SLITarget{})
+			require.Nil(t, err)
+			if !passed {
+				return build
+			}
+			history = append([]float64{value}, history...)
+		}
+		return -1
+	}
+
+	assert.Equal(t, -1, evaluate("avg"))
+	assert.Equal(t, 6, evaluate(aggregateFunctionTrend))
+}
+
+func TestCalculateStandardDeviation(t *testing.T) {
+	assert.InDelta(t, 2.138, calculateStandardDeviation([]float64{2, 4, 4, 4, 5, 5, 7, 9}), 0.001)
+	assert.EqualValues(t, 0.0, calculateStandardDeviation([]float64{5}))
+	assert.EqualValues(t, 0.0, calculateStandardDeviation([]float64{}))
+}
+
+func TestCalculateMedian(t *testing.T) {
+	assert.EqualValues(t, 3.0, calculateMedian([]float64{5, 1, 3}))
+	assert.EqualValues(t, 2.5, calculateMedian([]float64{4, 1, 3, 2}))
+	assert.EqualValues(t, 0.0, calculateMedian([]float64{}))
+}
+
+func TestCalculateMedianAbsoluteDeviation(t *testing.T) {
+	// median = 2, absolute deviations = [1, 1, 0, 0, 2, 4, 7] => median = 1
+	assert.EqualValues(t, 1.0, calculateMedianAbsoluteDeviation([]float64{1, 1, 2, 2, 4, 6, 9}))
+	assert.EqualValues(t, 0.0, calculateMedianAbsoluteDeviation([]float64{}))
+}
+
+func newPreviousSLIResults(values ...float64) []*keptnv2.SLIEvaluationResult {
+	var results []*keptnv2.SLIEvaluationResult
+	for _, value := range values {
+		results = append(results, &keptnv2.SLIEvaluationResult{
+			Value: &keptnv2.SLIResult{
+				Metric:  "my-test-metric",
+				Value:   value,
+				Success: true,
+			},
+			Status: "pass",
+		})
+	}
+	return results
+}
+
+func TestEvaluateStatisticalComparison(t *testing.T) {
+	// mean = 100, standard deviation = 10
+	previousSigmaResults := newPreviousSLIResults(90, 110, 90, 110, 100)
+	// median = 100, MAD = 5 => scaled MAD = 7.413
+	previousMADResults := newPreviousSLIResults(95, 100, 105, 100, 500)
+
+	tests := []struct {
+		name                  string
+		value                 float64
+		criteria              string
+		previousResults       []*keptnv2.SLIEvaluationResult
+		expectedResult        bool
+		expectedTargetValue   float64
+		expectedComparedValue float64
+		expectedMessage       string
+	}{
+		{
+			name:                  "value within 2 sigma band",
+			value:                 115,
+			criteria:              "<=2sigma",
+			previousResults:       previousSigmaResults,
+			expectedResult:        true,
+			expectedTargetValue:   120.0,
+			expectedComparedValue: 100,
+		},
+		{
+			name:                  "value below 2 sigma band",
+			value:                 75,
+			criteria:              "<=2sigma",
+			previousResults:       previousSigmaResults,
+			expectedResult:        false,
+			expectedTargetValue:   80.0,
+			expectedComparedValue: 100,
+			expectedMessage:       "criteria <=2sigma violated: value 75 is not >=80.00, it deviates by 2.50 standard deviations from the mean 100 of 5 previous results (bounds: [80.00, 120.00])",
+		},
+		{
+			name:                  "value below 2 sigma band, but only the upper bound is checked",
+			value:                 75,
+			criteria:              "<=+2sigma",
+			previousResults:       previousSigmaResults,
+			expectedResult:        true,
+			expectedTargetValue:   120.0,
+			expectedComparedValue: 100,
+		},
+		{
+			name:                  "value below lower bound",
+			value:                 75,
+			criteria:              ">=-2sigma",
+			previousResults:       previousSigmaResults,
+			expectedResult:        false,
+			expectedTargetValue:   80.0,
+			expectedComparedValue: 100,
+			expectedMessage:       "criteria >=-2sigma violated: value 75 is not >=80.00, it deviates by 2.50 standard deviations from the mean 100 of 5 previous results (bounds: [80.00, 120.00])",
+		},
+		{
+			name:                  "MAD is robust against outliers in previous results",
+			value:                 150,
+			criteria:              "<=3mad",
+			previousResults:       previousMADResults,
+			expectedResult:        false,
+			expectedTargetValue:   100 + 3*5*madScaleFactor,
+			expectedComparedValue: 100,
+			expectedMessage:       "criteria <=3mad violated: value 150 is not <=122.24, it deviates by 6.74 median absolute deviations from the median 100 of 5 previous results (bounds: [77.76, 122.24])",
+		},
+		{
+			name:                  "value above 2 sigma band",
+			value:                 125,
+			criteria:              "<2sigma",
+			previousResults:       previousSigmaResults,
+			expectedResult:        false,
+			expectedTargetValue:   120.0,
+			expectedComparedValue: 100,
+			expectedMessage:       "criteria <2sigma violated: value 125 is not <120.00, it deviates by 2.50 standard deviations from the mean 100 of 5 previous results (bounds: [80.00, 120.00])",
+		},
+		{
+			name:                  "zero spread, equal value satisfies strict criteria",
+			value:                 100,
+			criteria:              "<3sigma",
+			previousResults:       newPreviousSLIResults(100, 100, 100),
+			expectedResult:        true,
+			expectedTargetValue:   100,
+			expectedComparedValue: 100,
+		},
+		{
+			name:                  "zero spread, any deviation violates band",
+			value:                 100.5,
+			criteria:              "<=3mad",
+			previousResults:       newPreviousSLIResults(100, 100, 100),
+			expectedResult:        false,
+			expectedTargetValue:   100,
+			expectedComparedValue: 100,
+			expectedMessage:       "criteria <=3mad violated: value 100.5 is not <=100.00, it deviates from the median 100 of 3 previous results, which are all equal",
+		},
+		{
+			name:                  "zero spread, deviation in unchecked direction",
+			value:                 99,
+			criteria:              "<=+3sigma",
+			previousResults:       newPreviousSLIResults(100, 100, 100),
+			expectedResult:        true,
+			expectedTargetValue:   100,
+			expectedComparedValue: 100,
+		},
+		{
+			name:                  "not enough previous results",
+			value:                 1000,
+			criteria:              "<=3sigma",
+			previousResults:       newPreviousSLIResults(100),
+			expectedResult:        true,
+			expectedComparedValue: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sliResult := &keptnv2.SLIResult{Metric: "my-test-metric", Value: tt.value, Success: true}
+			target := &keptnv2.SLITarget{Criteria: tt.criteria}
+			co, err := parseCriteriaString(tt.criteria)
+			require.Nil(t, err)
+
+			result, err := evaluateStatisticalComparison(sliResult, co, tt.previousResults, target)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.InDelta(t, tt.expectedTargetValue, target.TargetValue, 0.0001)
+			assert.InDelta(t, tt.expectedComparedValue, sliResult.ComparedValue, 0.0001)
+			// the criteria is kept as written, even if it is violated
+			assert.Equal(t, tt.criteria, target.Criteria)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, sliResult.Message)
+			} else {
+				assert.Empty(t, sliResult.Message)
+			}
+		})
+	}
+}
+
 type evaluateComparisonTestObject struct {
 	Name              string
 	InSLIResult       *keptnv2.SLIResult
@@ -3520,6 +3900,30 @@ func Test_aggregateValues(t *testing.T) {
 			wantedValue: 10.0,
 			shouldSkip:  false,
 		},
+		{name: "Aggregate 3 values with EWMA",
+			fields: fields{
+				InPreviousResults: newPreviousSLIResults(16, 12, 8),
+				InComparison: &apimodelsv2.SLOComparison{
+					CompareWith:               "several_results",
+					NumberOfComparisonResults: 3,
+					AggregateFunction:         "ewma(0.5)",
+				},
+			},
+			wantedValue: 13.0,
+			shouldSkip:  false,
+		},
+		{name: "Aggregate 4 values with trend",
+			fields: fields{
+				InPreviousResults: newPreviousSLIResults(16, 14, 12, 10),
+				InComparison: &apimodelsv2.SLOComparison{
+					CompareWith:               "several_results",
+					NumberOfComparisonResults: 4,
+					AggregateFunction:         "trend",
+				},
+			},
+			wantedValue: 10.0,
+			shouldSkip:  false,
+		},
 		{name: "Skip because of no previous results",
 			fields: fields{
 				InComparison: &apimodelsv2.SLOComparison{
@@ -0,0 +1,150 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	logger "github.com/sirupsen/logrus"
+)
+
+// EvaluationDryRunPath is the path of the endpoint evaluating SLI results without sending or storing an evaluation.finished event
+const EvaluationDryRunPath = "/v1/evaluation/dry-run"
+
+var errMissingSLO = errors.New("no SLO file content provided")
+var errMissingSLIResults = errors.New("either SLI results or a reference to a get-sli.finished event must be provided")
+var errGetSLIFinishedEventNotFound = errors.New("could not find referenced get-sli.finished event")
+var errRetrieveEvents = errors.New("could not retrieve events from datastore")
+
+// EvaluationDryRunRequest contains the SLO file and the SLI results to be evaluated
+type EvaluationDryRunRequest struct {
+	// SLO is the content of the SLO file
+	SLO string `json:"slo"`
+	// Data contains the SLI results. If project, stage and service are set, the results are compared with the previous evaluations of the service
+	Data *keptnv2.GetSLIFinishedEventData `json:"data,omitempty"`
+	// Event references a get-sli.finished event whose SLI results should be evaluated. Only used if Data is not set
+	Event *EventReference `json:"event,omitempty"`
+}
+
+// EventReference identifies an event by its keptn context and, optionally, its ID
+type EventReference struct {
+	KeptnContext string `json:"keptnContext"`
+	ID           string `json:"id,omitempty"`
+}
+
+// EvaluationDryRunHandler evaluates an SLO file against a set of SLI results and returns the evaluation.finished event data.
+// Nothing is stored and no events are sent, i.e. the results do not show up in the previous evaluations of subsequent evaluations
+type EvaluationDryRunHandler struct {
+	HTTPClient *http.Client
+	EventStore EventStore
+}
+
+func NewEvaluationDryRunHandler() *EvaluationDryRunHandler {
+	return &EvaluationDryRunHandler{
+		HTTPClient: &http.Client{},
+		EventStore: keptnapi.NewEventHandler(getDatastoreURL()),
+	}
+}
+
+func (h *EvaluationDryRunHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+
+	request := &EvaluationDryRunRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "could not parse request: "+err.Error())
+		return
+	}
+
+	result, err := h.Evaluate(request)
+	if err != nil {
+		switch {
+		case errors.Is(err, errGetSLIFinishedEventNotFound):
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, errRetrieveEvents):
+			writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.WithError(err).Error("could not write evaluation dry-run response")
+	}
+}
+
+// Evaluate evaluates the SLI results of the request in the same way as the EvaluateSLIHandler, but without sending an event
+func (h *EvaluationDryRunHandler) Evaluate(request *EvaluationDryRunRequest) (*EvaluationFinishedEventData, error) {
+	if request.SLO == "" {
+		return nil, errMissingSLO
+	}
+	sloFileContent := []byte(request.SLO)
+	sloConfig, err := parseSLO(sloFileContent)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse SLO file: %w", err)
+	}
+
+	getSLIFinishedData, err := h.getSLIResults(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var previousEvaluationEvents []*keptnv2.EvaluationFinishedEventData
+	var comparisonEventIDs []string
+	if getSLIFinishedData.Project != "" && getSLIFinishedData.Stage != "" && getSLIFinishedData.Service != "" {
+		previousEvaluationEvents, comparisonEventIDs, err = getPreviousEvaluations(h.HTTPClient, getSLIFinishedData, getNumberOfPreviousResults(sloConfig), sloConfig.Comparison.IncludeResultWithScore)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errRetrieveEvents, err.Error())
+		}
+	}
+
+	return evaluateSLIs(getSLIFinishedData, sloConfig, sloFileContent, previousEvaluationEvents, comparisonEventIDs)
+}
+
+func (h *EvaluationDryRunHandler) getSLIResults(request *EvaluationDryRunRequest) (*keptnv2.GetSLIFinishedEventData, error) {
+	if request.Data != nil {
+		return request.Data, nil
+	}
+	if request.Event == nil || request.Event.KeptnContext == "" {
+		return nil, errMissingSLIResults
+	}
+
+	events, errObj := h.EventStore.GetEvents(&keptnapi.EventFilter{
+		KeptnContext: request.Event.KeptnContext,
+		EventID:      request.Event.ID,
+		EventType:    keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName),
+	})
+	if errObj != nil {
+		if errObj.Code == http.StatusNotFound {
+			return nil, errGetSLIFinishedEventNotFound
+		}
+		return nil, fmt.Errorf("%w: %s", errRetrieveEvents, errObj.GetMessage())
+	}
+	if len(events) == 0 {
+		return nil, errGetSLIFinishedEventNotFound
+	}
+
+	getSLIFinishedData := &keptnv2.GetSLIFinishedEventData{}
+	if err := keptnv2.Decode(events[0].Data, getSLIFinishedData); err != nil {
+		return nil, fmt.Errorf("could not decode get-sli.finished event: %w", err)
+	}
+	return getSLIFinishedData, nil
+}
+
+func writeErrorResponse(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(&apimodels.Error{
+		Code:    int64(code),
+		Message: &message,
+	})
+}
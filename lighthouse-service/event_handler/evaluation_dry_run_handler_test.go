@@ -0,0 +1,258 @@
+package event_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	event_handler_mock "github.com/keptn/keptn/lighthouse-service/event_handler/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dryRunSLO = `---
+spec_version: "1.0"
+comparison:
+  compare_with: "single_result"
+  include_result_with_score: "pass"
+  aggregate_function: avg
+objectives:
+  - sli: response_time_p95
+    pass:
+      - criteria:
+          - "<=+10%"
+          - "<600"
+  - sli: error_rate
+    pass:
+      - criteria:
+          - "<=1"
+total_score:
+  pass: "90%"
+  warning: "50%"
+`
+
+func newDryRunGetSLIFinishedData(responseTime, errorRate float64) *keptnv2.GetSLIFinishedEventData {
+	data := &keptnv2.GetSLIFinishedEventData{
+		EventData: keptnv2.EventData{
+			Project: "sockshop",
+			Stage:   "dev",
+			Service: "carts",
+		},
+	}
+	data.GetSLI.Start = "2022-01-26T10:05:53.931Z"
+	data.GetSLI.End = "2022-01-26T10:10:53.931Z"
+	data.GetSLI.IndicatorValues = []*keptnv2.SLIResult{
+		{Metric: "response_time_p95", Value: responseTime, Success: true},
+		{Metric: "error_rate", Value: errorRate, Success: true},
+	}
+	return data
+}
+
+func newDatastoreWithPreviousEvaluation(t *testing.T, responseTime float64) *httptest.Server {
+	previousEvaluation := &keptnv2.EvaluationFinishedEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Stage: "dev", Service: "carts", Result: keptnv2.ResultPass},
+		Evaluation: keptnv2.EvaluationDetails{
+			IndicatorResults: []*keptnv2.SLIEvaluationResult{
+				{Value: &keptnv2.SLIResult{Metric: "response_time_p95", Value: responseTime, Success: true}, Status: "pass"},
+			},
+		},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		result := datastoreResult{
+			Events: []struct {
+				Data interface{} `json:"data"`
+				ID   string      `json:"id"`
+			}{
+				{Data: previousEvaluation, ID: "previous-evaluation-id"},
+			},
+		}
+		w.Header().Add("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	_ = os.Setenv("MONGODB_DATASTORE", strings.TrimPrefix(ts.URL, "http://"))
+	return ts
+}
+
+func TestEvaluationDryRunHandler_Evaluate(t *testing.T) {
+	ts := newDatastoreWithPreviousEvaluation(t, 500)
+	defer ts.Close()
+
+	handler := &EvaluationDryRunHandler{HTTPClient: &http.Client{}}
+
+	result, err := handler.Evaluate(&EvaluationDryRunRequest{
+		SLO:  dryRunSLO,
+		Data: newDryRunGetSLIFinishedData(520, 0),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, keptnv2.ResultPass, result.Result)
+	assert.Equal(t, 100.0, result.Evaluation.Score)
+	assert.Equal(t, []string{"previous-evaluation-id"}, result.Evaluation.ComparedEvents)
+	assert.NotEmpty(t, result.Evaluation.SLOFileContent)
+
+	// response time increased by more than 10% compared to the previous evaluation
+	result, err = handler.Evaluate(&EvaluationDryRunRequest{
+		SLO:  dryRunSLO,
+		Data: newDryRunGetSLIFinishedData(580, 0),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, keptnv2.ResultWarning, result.Result)
+	assert.Equal(t, 50.0, result.Evaluation.Score)
+}
+
+func TestEvaluationDryRunHandler_EvaluateWithoutComparison(t *testing.T) {
+	handler := &EvaluationDryRunHandler{
+		HTTPClient: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t.Error("previous evaluations must not be retrieved if no service is given")
+			return nil, nil
+		})},
+	}
+
+	data := newDryRunGetSLIFinishedData(520, 5)
+	data.Project = ""
+	result, err := handler.Evaluate(&EvaluationDryRunRequest{
+		SLO:  dryRunSLO,
+		Data: data,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, keptnv2.ResultWarning, result.Result)
+	assert.Equal(t, 50.0, result.Evaluation.Score)
+	assert.Empty(t, result.Evaluation.ComparedEvents)
+}
+
+func TestEvaluationDryRunHandler_EvaluateReferencedEvent(t *testing.T) {
+	ts := newDatastoreWithPreviousEvaluation(t, 500)
+	defer ts.Close()
+
+	eventStore := &event_handler_mock.EventStoreMock{
+		GetEventsFunc: func(filter *keptnapi.EventFilter) ([]*models.KeptnContextExtendedCE, *models.Error) {
+			if filter.EventID != "get-sli-finished-id" {
+				return nil, nil
+			}
+			return []*models.KeptnContextExtendedCE{
+				{
+					ID:   "get-sli-finished-id",
+					Type: strPtr(keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName)),
+					Data: newDryRunGetSLIFinishedData(520, 5),
+				},
+			}, nil
+		},
+	}
+	handler := &EvaluationDryRunHandler{HTTPClient: &http.Client{}, EventStore: eventStore}
+
+	result, err := handler.Evaluate(&EvaluationDryRunRequest{
+		SLO:   dryRunSLO,
+		Event: &EventReference{KeptnContext: "my-context", ID: "get-sli-finished-id"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, keptnv2.ResultWarning, result.Result)
+	assert.Equal(t, 50.0, result.Evaluation.Score)
+
+	require.Len(t, eventStore.GetEventsCalls(), 1)
+	assert.Equal(t, "my-context", eventStore.GetEventsCalls()[0].Filter.KeptnContext)
+	assert.Equal(t, keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), eventStore.GetEventsCalls()[0].Filter.EventType)
+
+	_, err = handler.Evaluate(&EvaluationDryRunRequest{
+		SLO:   dryRunSLO,
+		Event: &EventReference{KeptnContext: "my-context", ID: "unknown-id"},
+	})
+	assert.ErrorIs(t, err, errGetSLIFinishedEventNotFound)
+}
+
+func TestEvaluationDryRunHandler_ServeHTTP(t *testing.T) {
+	ts := newDatastoreWithPreviousEvaluation(t, 500)
+	defer ts.Close()
+
+	handler := &EvaluationDryRunHandler{
+		HTTPClient: &http.Client{},
+		EventStore: &event_handler_mock.EventStoreMock{
+			GetEventsFunc: func(filter *keptnapi.EventFilter) ([]*models.KeptnContextExtendedCE, *models.Error) {
+				return nil, &models.Error{Code: http.StatusNotFound, Message: strPtr("not found")}
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "evaluate SLI results",
+			method:       http.MethodPost,
+			body:         marshalDryRunRequest(t, &EvaluationDryRunRequest{SLO: dryRunSLO, Data: newDryRunGetSLIFinishedData(520, 0)}),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "wrong method",
+			method:       http.MethodGet,
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "invalid payload",
+			method:       http.MethodPost,
+			body:         "{",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing SLO",
+			method:       http.MethodPost,
+			body:         marshalDryRunRequest(t, &EvaluationDryRunRequest{Data: newDryRunGetSLIFinishedData(520, 0)}),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing SLI results",
+			method:       http.MethodPost,
+			body:         marshalDryRunRequest(t, &EvaluationDryRunRequest{SLO: dryRunSLO}),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "referenced event not found",
+			method:       http.MethodPost,
+			body:         marshalDryRunRequest(t, &EvaluationDryRunRequest{SLO: dryRunSLO, Event: &EventReference{KeptnContext: "my-context"}}),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, EvaluationDryRunPath, bytes.NewBufferString(tt.body)))
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedCode == http.StatusOK {
+				result := &EvaluationFinishedEventData{}
+				require.Nil(t, json.Unmarshal(w.Body.Bytes(), result))
+				assert.Equal(t, keptnv2.ResultPass, result.Result)
+			} else {
+				errorResponse := &models.Error{}
+				require.Nil(t, json.Unmarshal(w.Body.Bytes(), errorResponse))
+				assert.Equal(t, int64(tt.expectedCode), errorResponse.Code)
+				assert.NotEmpty(t, errorResponse.GetMessage())
+			}
+		})
+	}
+}
+
+func marshalDryRunRequest(t *testing.T, request *EvaluationDryRunRequest) string {
+	marshalled, err := json.Marshal(request)
+	require.Nil(t, err)
+	return string(marshalled)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
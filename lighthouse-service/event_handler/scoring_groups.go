@@ -0,0 +1,285 @@
+package event_handler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSLOGroupName is the group of all objectives that are not assigned to a group explicitly
+const defaultSLOGroupName = "default"
+
+// SLOGroup groups several objectives of an SLO file, which are scored together.
+// The scores of all groups are combined to the total score using the weights of the groups
+type SLOGroup struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"displayName"`
+	// Weight of the group when calculating the total score, default: 1
+	Weight int `yaml:"weight"`
+	// KeyGroup fails the whole evaluation if the group fails
+	KeyGroup bool `yaml:"key_group"`
+	// Pass is the score (in percent) the objectives of the group need to achieve for the group to pass
+	Pass string `yaml:"pass"`
+	// Warning is the score (in percent) the objectives of the group need to achieve for the group to result in a warning
+	Warning string `yaml:"warning"`
+}
+
+// SLOGroupResult contains the score and the status of a scoring group
+type SLOGroupResult struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Score       float64  `json:"score"`
+	Weight      int      `json:"weight"`
+	KeyGroup    bool     `json:"keyGroup"`
+	Status      string   `json:"status" jsonschema:"enum=pass,enum=warning,enum=fail,enum=info"`
+	Objectives  []string `json:"objectives"`
+}
+
+// sloGroups contains the scoring groups of an SLO file and the assignment of the objectives to these groups.
+// Both are not part of keptn.ServiceLevelObjectives, hence the SLO file is parsed a second time to retrieve them
+type sloGroups struct {
+	Groups     []*SLOGroup `yaml:"groups"`
+	Objectives []*struct {
+		SLI   string `yaml:"sli"`
+		Group string `yaml:"group"`
+	} `yaml:"objectives"`
+}
+
+// EvaluationFinishedEventData extends the data of an evaluation.finished event with the results of the scoring groups
+type EvaluationFinishedEventData struct {
+	keptnv2.EventData
+	Evaluation EvaluationDetails `json:"evaluation,omitempty"`
+}
+
+type EvaluationDetails struct {
+	keptnv2.EvaluationDetails
+	Groups []*SLOGroupResult `json:"groups,omitempty"`
+}
+
+func newEvaluationFinishedEventData(evaluationResult *keptnv2.EvaluationFinishedEventData, groupResults []*SLOGroupResult) *EvaluationFinishedEventData {
+	return &EvaluationFinishedEventData{
+		EventData: evaluationResult.EventData,
+		Evaluation: EvaluationDetails{
+			EvaluationDetails: evaluationResult.Evaluation,
+			Groups:            groupResults,
+		},
+	}
+}
+
+// parseSLOGroups returns the scoring groups defined in the given SLO file as well as a map containing the group of each SLI.
+// If no groups are defined, no groups are returned
+func parseSLOGroups(input []byte) ([]*SLOGroup, map[string]string, error) {
+	config := &sloGroups{}
+	if err := yaml.Unmarshal(input, config); err != nil {
+		return nil, nil, err
+	}
+	if len(config.Groups) == 0 {
+		for _, objective := range config.Objectives {
+			if objective != nil && objective.Group != "" {
+				return nil, nil, fmt.Errorf("objective %s is assigned to group %s, but no groups are defined", objective.SLI, objective.Group)
+			}
+		}
+		return nil, nil, nil
+	}
+
+	groups := []*SLOGroup{}
+	groupNames := map[string]bool{}
+	for _, group := range config.Groups {
+		if group == nil {
+			continue
+		}
+		if group.Name == "" {
+			return nil, nil, errors.New("name of scoring group must not be empty")
+		}
+		if groupNames[group.Name] {
+			return nil, nil, fmt.Errorf("scoring group %s is defined more than once", group.Name)
+		}
+		if group.Weight == 0 {
+			group.Weight = 1
+		}
+		if _, err := parseGroupTarget(group.Pass); err != nil {
+			return nil, nil, fmt.Errorf("could not parse pass target of scoring group %s: %w", group.Name, err)
+		}
+		if _, err := parseGroupTarget(group.Warning); err != nil {
+			return nil, nil, fmt.Errorf("could not parse warning target of scoring group %s: %w", group.Name, err)
+		}
+		groupNames[group.Name] = true
+		groups = append(groups, group)
+	}
+
+	objectiveGroups := map[string]string{}
+	for _, objective := range config.Objectives {
+		if objective == nil {
+			continue
+		}
+		if objective.Group == "" {
+			objectiveGroups[objective.SLI] = defaultSLOGroupName
+			continue
+		}
+		if !groupNames[objective.Group] {
+			return nil, nil, fmt.Errorf("objective %s is assigned to unknown group %s", objective.SLI, objective.Group)
+		}
+		objectiveGroups[objective.SLI] = objective.Group
+	}
+	return groups, objectiveGroups, nil
+}
+
+// parseGroupTarget parses a score target such as "90%". An empty target results in a target of 0
+func parseGroupTarget(target string) (float64, error) {
+	if target == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(target), "%"), 64)
+}
+
+// calculateGroupScores calculates the score and the status of each scoring group.
+// Objectives that are not assigned to a group are part of an additional default group
+func calculateGroupScores(evaluationResult *keptnv2.EvaluationFinishedEventData, sloConfig *keptn.ServiceLevelObjectives, groups []*SLOGroup, objectiveGroups map[string]string) ([]*SLOGroupResult, error) {
+	type groupScore struct {
+		group        *SLOGroup
+		result       *SLOGroupResult
+		score        float64
+		maximumScore float64
+		keySLIFailed bool
+		// slis contains the SLIs already considered for the group, so that an SLI listed more than once is only scored once
+		slis map[string]bool
+	}
+
+	scores := map[string]*groupScore{}
+	orderedScores := []*groupScore{}
+	addGroup := func(group *SLOGroup) *groupScore {
+		score := &groupScore{
+			group: group,
+			result: &SLOGroupResult{
+				Name:        group.Name,
+				DisplayName: group.DisplayName,
+				Weight:      group.Weight,
+				KeyGroup:    group.KeyGroup,
+				Objectives:  []string{},
+			},
+			slis: map[string]bool{},
+		}
+		scores[group.Name] = score
+		orderedScores = append(orderedScores, score)
+		return score
+	}
+	for _, group := range groups {
+		addGroup(group)
+	}
+
+	for _, objective := range sloConfig.Objectives {
+		groupName, ok := objectiveGroups[objective.SLI]
+		if !ok {
+			groupName = defaultSLOGroupName
+		}
+		score, ok := scores[groupName]
+		if !ok {
+			score = addGroup(&SLOGroup{Name: defaultSLOGroupName, Weight: 1})
+		}
+		if score.slis[objective.SLI] {
+			continue
+		}
+		score.slis[objective.SLI] = true
+		score.result.Objectives = append(score.result.Objectives, objective.SLI)
+
+		// only consider the SLI for the group score if pass criteria have been included
+		if len(objective.Pass) > 0 {
+			score.maximumScore += float64(objective.Weight)
+		}
+		for _, indicatorResult := range evaluationResult.Evaluation.IndicatorResults {
+			if indicatorResult.Value == nil || indicatorResult.Value.Metric != objective.SLI {
+				continue
+			}
+			score.score += indicatorResult.Score
+			if indicatorResult.KeySLI && indicatorResult.Status == "fail" {
+				score.keySLIFailed = true
+			}
+			break
+		}
+	}
+
+	results := make([]*SLOGroupResult, 0, len(orderedScores))
+	for _, score := range orderedScores {
+		if score.maximumScore == 0 {
+			// groups without pass criteria are informational only and not considered for the total score
+			score.result.Score = 100.0
+			score.result.Status = "info"
+			results = append(results, score.result)
+			continue
+		}
+		score.result.Score = 100.0 * (score.score / score.maximumScore)
+		status, err := getGroupStatus(score.group, score.result.Score, score.keySLIFailed)
+		if err != nil {
+			return nil, err
+		}
+		score.result.Status = status
+		results = append(results, score.result)
+	}
+	return results, nil
+}
+
+func getGroupStatus(group *SLOGroup, score float64, keySLIFailed bool) (string, error) {
+	if keySLIFailed {
+		return "fail", nil
+	}
+	passTarget, err := parseGroupTarget(group.Pass)
+	if err != nil {
+		return "", fmt.Errorf("could not parse pass target of scoring group %s", group.Name)
+	}
+	if score >= passTarget {
+		return "pass", nil
+	}
+	if group.Warning != "" {
+		warningTarget, err := parseGroupTarget(group.Warning)
+		if err != nil {
+			return "", fmt.Errorf("could not parse warning target of scoring group %s", group.Name)
+		}
+		if score >= warningTarget {
+			return "warning", nil
+		}
+	}
+	return "fail", nil
+}
+
+// calculateGroupedScore calculates the total score as the weighted average of the scores of all groups.
+// Besides a failed key SLI, a failed key group fails the whole evaluation
+func calculateGroupedScore(maximumAchievableScore float64, evaluationResult *keptnv2.EvaluationFinishedEventData, sloConfig *keptn.ServiceLevelObjectives, groups []*SLOGroup, objectiveGroups map[string]string, keySLIFailed bool) ([]*SLOGroupResult, error) {
+	groupResults, err := calculateGroupScores(evaluationResult, sloConfig, groups, objectiveGroups)
+	if err != nil {
+		return nil, err
+	}
+	if maximumAchievableScore == 0 {
+		return groupResults, calculateScore(maximumAchievableScore, evaluationResult, sloConfig, keySLIFailed)
+	}
+
+	totalScore := 0.0
+	totalWeight := 0.0
+	failedKeyGroups := []string{}
+	for _, groupResult := range groupResults {
+		if groupResult.Status == "info" {
+			continue
+		}
+		totalScore += groupResult.Score * float64(groupResult.Weight)
+		totalWeight += float64(groupResult.Weight)
+		if groupResult.KeyGroup && groupResult.Status == "fail" {
+			failedKeyGroups = append(failedKeyGroups, groupResult.Name)
+		}
+	}
+
+	achievedPercentage := totalScore / totalWeight
+	evaluationResult.Evaluation.Score = achievedPercentage
+	if err := evaluateTotalScore(achievedPercentage, evaluationResult, sloConfig, keySLIFailed || len(failedKeyGroups) > 0); err != nil {
+		return nil, err
+	}
+	if len(failedKeyGroups) > 0 {
+		sort.Strings(failedKeyGroups)
+		evaluationResult.Message = fmt.Sprintf("Evaluation failed since the key group(s) %s failed", strings.Join(failedKeyGroups, ", "))
+	}
+	return groupResults, nil
+}
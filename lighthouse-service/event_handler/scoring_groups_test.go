@@ -0,0 +1,317 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const groupedSLO = `---
+spec_version: "1.0"
+comparison:
+  compare_with: "single_result"
+  include_result_with_score: "pass"
+  aggregate_function: avg
+groups:
+  - name: latency
+    weight: 2
+    pass: "90%"
+    warning: "50%"
+  - name: errors
+    key_group: true
+    pass: "100%"
+objectives:
+  - sli: response_time_p90
+    group: latency
+    pass:
+      - criteria:
+          - "<=200"
+    warning:
+      - criteria:
+          - "<=400"
+  - sli: response_time_p95
+    group: latency
+    pass:
+      - criteria:
+          - "<=300"
+  - sli: error_rate
+    group: errors
+    pass:
+      - criteria:
+          - "<=1"
+  - sli: throughput
+    pass:
+      - criteria:
+          - ">=100"
+  - sli: cpu_usage
+total_score:
+  pass: "90%"
+  warning: "75%"
+`
+
+func TestParseSLOGroups(t *testing.T) {
+	groups, objectiveGroups, err := parseSLOGroups([]byte(groupedSLO))
+	require.Nil(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, &SLOGroup{Name: "latency", Weight: 2, Pass: "90%", Warning: "50%"}, groups[0])
+	assert.Equal(t, &SLOGroup{Name: "errors", Weight: 1, KeyGroup: true, Pass: "100%"}, groups[1])
+	assert.Equal(t, map[string]string{
+		"response_time_p90": "latency",
+		"response_time_p95": "latency",
+		"error_rate":        "errors",
+		"throughput":        defaultSLOGroupName,
+		"cpu_usage":         defaultSLOGroupName,
+	}, objectiveGroups)
+}
+
+func TestParseSLOGroups_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		slo  string
+	}{
+		{
+			name: "unknown group",
+			slo: `groups:
+  - name: latency
+objectives:
+  - sli: error_rate
+    group: errors`,
+		},
+		{
+			name: "group without groups",
+			slo: `objectives:
+  - sli: error_rate
+    group: errors`,
+		},
+		{
+			name: "duplicate group",
+			slo: `groups:
+  - name: latency
+  - name: latency`,
+		},
+		{
+			name: "missing name",
+			slo: `groups:
+  - pass: "90%"`,
+		},
+		{
+			name: "invalid pass target",
+			slo: `groups:
+  - name: latency
+    pass: "abc"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseSLOGroups([]byte(tt.slo))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestParseSLOGroups_NoGroups(t *testing.T) {
+	groups, objectiveGroups, err := parseSLOGroups([]byte(`objectives:
+  - sli: error_rate`))
+	require.Nil(t, err)
+	assert.Empty(t, groups)
+	assert.Empty(t, objectiveGroups)
+}
+
+func TestCalculateGroupedScore(t *testing.T) {
+	tests := []struct {
+		name            string
+		values          map[string]float64
+		expectedResult  keptnv2.ResultType
+		expectedScore   float64
+		expectedGroups  map[string]string
+		expectedMessage string
+	}{
+		{
+			name: "all groups pass",
+			values: map[string]float64{
+				"response_time_p90": 150,
+				"response_time_p95": 250,
+				"error_rate":        0,
+				"throughput":        200,
+				"cpu_usage":         50,
+			},
+			expectedResult: keptnv2.ResultPass,
+			expectedScore:  100,
+			expectedGroups: map[string]string{"latency": "pass", "errors": "pass", defaultSLOGroupName: "pass"},
+		},
+		{
+			name: "latency group results in a warning",
+			values: map[string]float64{
+				"response_time_p90": 350,
+				"response_time_p95": 250,
+				"error_rate":        0,
+				"throughput":        200,
+			},
+			expectedResult: keptnv2.ResultWarning,
+			// latency: 75% with weight 2, errors: 100%, default: 100%
+			expectedScore:  87.5,
+			expectedGroups: map[string]string{"latency": "warning", "errors": "pass", defaultSLOGroupName: "pass"},
+		},
+		{
+			name: "failed key group fails the evaluation",
+			values: map[string]float64{
+				"response_time_p90": 150,
+				"response_time_p95": 250,
+				"error_rate":        5,
+				"throughput":        200,
+			},
+			expectedResult: keptnv2.ResultFailed,
+			// latency: 100% with weight 2, errors: 0%, default: 100%
+			expectedScore:   75,
+			expectedGroups:  map[string]string{"latency": "pass", "errors": "fail", defaultSLOGroupName: "pass"},
+			expectedMessage: "Evaluation failed since the key group(s) errors failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sloConfig, err := parseSLO([]byte(groupedSLO))
+			require.Nil(t, err)
+			groups, objectiveGroups, err := parseSLOGroups([]byte(groupedSLO))
+			require.Nil(t, err)
+
+			getSLIFinished := &keptnv2.GetSLIFinishedEventData{}
+			for metric, value := range tt.values {
+				getSLIFinished.GetSLI.IndicatorValues = append(getSLIFinished.GetSLI.IndicatorValues, &keptnv2.SLIResult{Metric: metric, Value: value, Success: true})
+			}
+			evaluationResult, maximumAchievableScore, keySLIFailed := evaluateObjectives(getSLIFinished, sloConfig, nil)
+
+			groupResults, err := calculateGroupedScore(maximumAchievableScore, evaluationResult, sloConfig, groups, objectiveGroups, keySLIFailed)
+			require.Nil(t, err)
+
+			assert.Equal(t, tt.expectedResult, evaluationResult.Result)
+			assert.InDelta(t, tt.expectedScore, evaluationResult.Evaluation.Score, 0.001)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, evaluationResult.Message)
+			}
+
+			groupStatus := map[string]string{}
+			for _, groupResult := range groupResults {
+				groupStatus[groupResult.Name] = groupResult.Status
+			}
+			assert.Equal(t, tt.expectedGroups, groupStatus)
+		})
+	}
+}
+
+func TestCalculateGroupScores_InformationalGroup(t *testing.T) {
+	slo := `groups:
+  - name: latency
+    pass: "90%"
+  - name: saturation
+objectives:
+  - sli: response_time_p90
+    group: latency
+    pass:
+      - criteria:
+          - "<=200"
+  - sli: cpu_usage
+    group: saturation
+total_score:
+  pass: "90%"
+`
+	sloConfig, err := parseSLO([]byte(slo))
+	require.Nil(t, err)
+	groups, objectiveGroups, err := parseSLOGroups([]byte(slo))
+	require.Nil(t, err)
+
+	getSLIFinished := &keptnv2.GetSLIFinishedEventData{}
+	getSLIFinished.GetSLI.IndicatorValues = []*keptnv2.SLIResult{
+		{Metric: "response_time_p90", Value: 300, Success: true},
+		{Metric: "cpu_usage", Value: 50, Success: true},
+	}
+	evaluationResult, maximumAchievableScore, keySLIFailed := evaluateObjectives(getSLIFinished, sloConfig, nil)
+
+	groupResults, err := calculateGroupedScore(maximumAchievableScore, evaluationResult, sloConfig, groups, objectiveGroups, keySLIFailed)
+	require.Nil(t, err)
+	require.Len(t, groupResults, 2)
+
+	assert.Equal(t, "fail", groupResults[0].Status)
+	assert.Equal(t, []string{"response_time_p90"}, groupResults[0].Objectives)
+	assert.Equal(t, "info", groupResults[1].Status)
+	assert.Equal(t, []string{"cpu_usage"}, groupResults[1].Objectives)
+
+	// the informational group must not increase the total score
+	assert.Equal(t, 0.0, evaluationResult.Evaluation.Score)
+	assert.Equal(t, keptnv2.ResultFailed, evaluationResult.Result)
+}
+
+func TestCalculateGroupScores_DuplicateSLI(t *testing.T) {
+	slo := `groups:
+  - name: latency
+    pass: "90%"
+objectives:
+  - sli: response_time_p90
+    group: latency
+    pass:
+      - criteria:
+          - "<=200"
+  - sli: response_time_p90
+    group: latency
+    pass:
+      - criteria:
+          - "<=200"
+  - sli: response_time_p95
+    group: latency
+    pass:
+      - criteria:
+          - "<=300"
+total_score:
+  pass: "90%"
+`
+	sloConfig, err := parseSLO([]byte(slo))
+	require.Nil(t, err)
+	groups, objectiveGroups, err := parseSLOGroups([]byte(slo))
+	require.Nil(t, err)
+
+	getSLIFinished := &keptnv2.GetSLIFinishedEventData{}
+	getSLIFinished.GetSLI.IndicatorValues = []*keptnv2.SLIResult{
+		{Metric: "response_time_p90", Value: 100, Success: true},
+		{Metric: "response_time_p95", Value: 400, Success: true},
+	}
+	evaluationResult, _, _ := evaluateObjectives(getSLIFinished, sloConfig, nil)
+
+	groupResults, err := calculateGroupScores(evaluationResult, sloConfig, groups, objectiveGroups)
+	require.Nil(t, err)
+	require.Len(t, groupResults, 1)
+
+	// the duplicate SLI must neither be listed nor scored twice, otherwise the group would score more than 100%
+	assert.Equal(t, []string{"response_time_p90", "response_time_p95"}, groupResults[0].Objectives)
+	assert.Equal(t, 50.0, groupResults[0].Score)
+	assert.Equal(t, "fail", groupResults[0].Status)
+}
+
+func TestNewEvaluationFinishedEventData(t *testing.T) {
+	evaluationResult := &keptnv2.EvaluationFinishedEventData{
+		EventData: keptnv2.EventData{Project: "sockshop", Result: keptnv2.ResultPass},
+		Evaluation: keptnv2.EvaluationDetails{
+			Score:  90,
+			Result: "pass",
+		},
+	}
+	groupResults := []*SLOGroupResult{{Name: "latency", Score: 90, Weight: 1, Status: "pass", Objectives: []string{"response_time_p90"}}}
+
+	marshalled, err := json.Marshal(newEvaluationFinishedEventData(evaluationResult, groupResults))
+	require.Nil(t, err)
+
+	// the event data must still be readable as regular evaluation.finished event data
+	decoded := &keptnv2.EvaluationFinishedEventData{}
+	require.Nil(t, json.Unmarshal(marshalled, decoded))
+	assert.Equal(t, evaluationResult, decoded)
+
+	decodedWithGroups := &EvaluationFinishedEventData{}
+	require.Nil(t, json.Unmarshal(marshalled, decodedWithGroups))
+	assert.Equal(t, groupResults, decodedWithGroups.Evaluation.Groups)
+
+	// without groups, the event data must not contain any groups
+	marshalled, err = json.Marshal(newEvaluationFinishedEventData(evaluationResult, nil))
+	require.Nil(t, err)
+	assert.NotContains(t, string(marshalled), "groups")
+}
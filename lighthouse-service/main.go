@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -43,7 +44,12 @@ func main() {
 func _main(args []string, env envConfig) int {
 	ctx := getGracefulContext()
 
-	p, err := cloudevents.NewHTTP(cloudevents.WithPath(env.Path), cloudevents.WithPort(env.Port), cloudevents.WithGetHandlerFunc(keptnapi.HealthEndpointHandler))
+	p, err := cloudevents.NewHTTP(
+		cloudevents.WithPath(env.Path),
+		cloudevents.WithPort(env.Port),
+		cloudevents.WithGetHandlerFunc(keptnapi.HealthEndpointHandler),
+		cloudevents.WithMiddleware(withEvaluationDryRunEndpoint),
+	)
 	if err != nil {
 		logger.Fatalf("failed to create client, %v", err)
 	}
@@ -77,6 +83,14 @@ func gotEvent(ctx context.Context, event cloudevents.Event) error {
 	return nil
 }
 
+// withEvaluationDryRunEndpoint serves the evaluation dry-run endpoint next to the cloudevents receiver
+func withEvaluationDryRunEndpoint(next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(event_handler.EvaluationDryRunPath, event_handler.NewEvaluationDryRunHandler())
+	mux.Handle("/", next)
+	return mux
+}
+
 // storing wait group into context to sync before shutdown
 func getGracefulContext() context.Context {
 
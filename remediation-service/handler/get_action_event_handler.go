@@ -1,25 +1,63 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
-	"github.com/ghodss/yaml"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
 	"github.com/keptn/go-utils/pkg/api/models"
 	utils "github.com/keptn/go-utils/pkg/api/utils"
-	"github.com/keptn/go-utils/pkg/lib/v0_1_4"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	logger "github.com/sirupsen/logrus"
 )
 
-const remediationSpecVersion = "spec.keptn.sh/0.1.4"
 const remediationResourceFileName = "remediation.yaml"
+const serviceName = "remediation-service"
 
 type GetActionEventHandler struct {
+	eventSender sdk.EventSender
+	executions  *ActionExecutions
+	history     *RemediationHistory
+	now         func() time.Time
 }
 
-func NewGetActionEventHandler() *GetActionEventHandler {
-	return &GetActionEventHandler{}
+type GetActionEventHandlerOption func(g *GetActionEventHandler)
+
+// WithEventSender sets the sender used for the events of escalations. Without a sender, escalations are not possible
+func WithEventSender(eventSender sdk.EventSender) GetActionEventHandlerOption {
+	return func(g *GetActionEventHandler) {
+		g.eventSender = eventSender
+	}
+}
+
+// WithClock sets the function returning the current time, which is used to evaluate the preconditions of actions
+func WithClock(now func() time.Time) GetActionEventHandlerOption {
+	return func(g *GetActionEventHandler) {
+		g.now = now
+	}
+}
+
+// WithHistory sets the history the selected actions are recorded in. It is used to order actions by their past success
+func WithHistory(history *RemediationHistory) GetActionEventHandlerOption {
+	return func(g *GetActionEventHandler) {
+		g.history = history
+	}
+}
+
+func NewGetActionEventHandler(opts ...GetActionEventHandlerOption) *GetActionEventHandler {
+	g := &GetActionEventHandler{
+		executions: NewActionExecutions(),
+		history:    NewRemediationHistory(),
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 func (g *GetActionEventHandler) Execute(k sdk.IKeptn, event sdk.KeptnEvent) (interface{}, *sdk.Error) {
@@ -44,16 +82,41 @@ func (g *GetActionEventHandler) Execute(k sdk.IKeptn, event sdk.KeptnEvent) (int
 	}
 
 	// determine next action
-	action, err := GetNextAction(remediation, getActionTriggeredData.Problem, getActionTriggeredData.GetAction.ActionIndex)
+	problem := newProblem(event, getActionTriggeredData)
+	now := g.now()
+	scope := getActionTriggeredData.Project + "/" + getActionTriggeredData.Stage + "/" + getActionTriggeredData.Service
+	checker := &preconditionChecker{scope: scope, now: now, executions: g.executions}
+	ranker := historyRanker{
+		history:      g.history,
+		filter:       HistoryFilter{Project: getActionTriggeredData.Project, Stage: getActionTriggeredData.Stage, Service: getActionTriggeredData.Service, ProblemType: problem.Type()},
+		keptnContext: event.Shkeptncontext,
+	}
+	nextAction, err := GetNextAction(remediation, problem, getActionTriggeredData.GetAction.ActionIndex, checker, ranker)
 	if err != nil {
+		var usedUpErr *ActionsUsedUpError
+		if errors.As(err, &usedUpErr) && usedUpErr.Escalation != nil {
+			err = g.escalate(event, getActionTriggeredData, usedUpErr.Escalation)
+		}
 		return nil, &sdk.Error{Err: err, StatusType: keptnv2.StatusSucceeded, ResultType: keptnv2.ResultFailed, Message: err.Error()}
 	}
+	g.executions.Add(scope, nextAction.Action.Action, now)
+	g.history.AddSelection(HistoryRecord{
+		KeptnContext: event.Shkeptncontext,
+		Project:      getActionTriggeredData.Project,
+		Stage:        getActionTriggeredData.Stage,
+		Service:      getActionTriggeredData.Service,
+		ProblemType:  problem.Type(),
+		Action:       nextAction.Action.Action,
+		Name:         nextAction.key,
+		SelectedAt:   now,
+		Ranking:      nextAction.ranking,
+	})
 
 	finishedEventData := keptnv2.GetActionFinishedEventData{
 		EventData: getActionTriggeredData.EventData,
-		Action:    *action,
+		Action:    nextAction.Action,
 		GetAction: keptnv2.GetActionData{
-			ActionIndex: getActionTriggeredData.GetAction.ActionIndex + 1,
+			ActionIndex: nextAction.Index + 1,
 		},
 	}
 
@@ -69,84 +132,127 @@ func (g *GetActionEventHandler) getRemediationResource(keptn sdk.IKeptn, eventDa
 	return keptn.GetResourceHandler().GetResource(resourceScope, utils.AppendQuery(commitOption))
 }
 
-// ParseRemediationResource returns the in-memory representation of a keptn resource.
-// Note, that the spec version of the remediation.yaml file needs to match "spec.keptn.sh/0.1.4"
-func ParseRemediationResource(resource *models.Resource) (*v0_1_4.Remediation, error) {
-	remediationData := &v0_1_4.Remediation{}
-	err := yaml.Unmarshal([]byte(resource.ResourceContent), remediationData)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse remediation.yaml: %w", err)
+// escalate sends the event of the escalation, and returns the error reported in the .finished event
+func (g *GetActionEventHandler) escalate(event sdk.KeptnEvent, eventData *keptnv2.GetActionTriggeredEventData, escalation *Escalation) error {
+	if g.eventSender == nil {
+		return fmt.Errorf("all remediation actions have been used up, but escalation event %s could not be sent: no event sender configured", escalation.Type)
+	}
+	data := map[string]interface{}{}
+	for key, value := range escalation.Data {
+		data[key] = value
 	}
+	data["project"] = eventData.Project
+	data["stage"] = eventData.Stage
+	data["service"] = eventData.Service
+	data["labels"] = eventData.Labels
+	data["problem"] = eventData.Problem
 
-	if remediationData.ApiVersion != remediationSpecVersion {
-		return nil, fmt.Errorf("remediation.yaml file does not conform to remediation spec %s", remediationSpecVersion)
+	escalationEvent := cloudevents.NewEvent()
+	escalationEvent.SetID(uuid.New().String())
+	escalationEvent.SetType(escalation.Type)
+	escalationEvent.SetSource(serviceName)
+	escalationEvent.SetExtension(sdk.KeptnContextCEExtension, event.Shkeptncontext)
+	if err := escalationEvent.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return fmt.Errorf("all remediation actions have been used up, but escalation event %s could not be created: %w", escalation.Type, err)
 	}
-	return remediationData, nil
+	if err := g.eventSender.SendEvent(escalationEvent); err != nil {
+		return fmt.Errorf("all remediation actions have been used up, but escalation event %s could not be sent: %w", escalation.Type, err)
+	}
+	return fmt.Errorf("all remediation actions have been used up, escalated by sending event %s", escalation.Type)
+}
+
+// newProblem extracts the properties used for selecting a remediation from the event
+func newProblem(event sdk.KeptnEvent, eventData *keptnv2.GetActionTriggeredEventData) Problem {
+	// the severity is not part of keptnv2.ProblemDetails, but might be provided by the problem source
+	severity := struct {
+		Problem struct {
+			Severity string `json:"severity"`
+		} `json:"problem"`
+	}{}
+	_ = keptnv2.Decode(event.Data, &severity)
+	return Problem{
+		ProblemTitle: eventData.Problem.ProblemTitle,
+		RootCause:    eventData.Problem.RootCause,
+		Severity:     severity.Problem.Severity,
+		Labels:       eventData.Labels,
+	}
+}
+
+// PreconditionChecker decides whether an action can be selected. It returns an error describing why an action is skipped
+type PreconditionChecker interface {
+	Check(action RemediationAction) error
+}
+
+// ActionRanker orders the actions of a remediation before the next action is selected
+type ActionRanker interface {
+	Rank(actions []RemediationAction) []RemediationAction
+}
+
+// NextAction is the action selected for a problem, together with its index within the (ranked) actions of the remediation
+type NextAction struct {
+	Action keptnv2.ActionInfo
+	Index  int
+	key    string
+	// ranking contains the keys of the ranked actions, if the actions have been ranked
+	ranking []string
+}
+
+// ActionsUsedUpError is returned if there are no more actions available for a problem
+type ActionsUsedUpError struct {
+	Problem     Problem
+	ActionIndex int
+	// Escalation is the escalation of the remediation, if any
+	Escalation *Escalation
+}
+
+func (e *ActionsUsedUpError) Error() string {
+	return fmt.Sprintf("there is no action with index %d for %s", e.ActionIndex, e.Problem.String())
 }
 
 // GetNextAction contains the logic to determine, what will be the next remediation action according to the remediation.yaml file
 // It searches for a problem type matching the root cause of the problem. If no problem type is found a problem type matching the problem title
-// will be searched as a fallback. If still no problem type is found it will return an error.
-
-// The actionIndex parameter specifies which action to take if a problem type was found.
-func GetNextAction(remediation *v0_1_4.Remediation, problemDetails keptnv2.ProblemDetails, actionIndex int) (*keptnv2.ActionInfo, error) {
-	rootCause := problemDetails.RootCause
-	problemTitle := problemDetails.ProblemTitle
-	var problem string
-
-	if rootCause != "" {
-		problem = "root cause " + rootCause
-	} else if problemTitle != "" {
-		problem = "problem title " + problemTitle
-	} else {
-		problem = "problem type default"
-	}
-
-	var actions []v0_1_4.RemediationActionsOnOpen
-	// search problem type matching root cause
-	for _, r := range remediation.Spec.Remediations {
-		if r.ProblemType == rootCause {
-			actions = r.ActionsOnOpen
-			break
-		}
+// will be searched as a fallback, followed by the matchers of the remediations and the problem type default. If still nothing is found it will return an error.
+
+// The actionIndex parameter specifies which action to take if a remediation was found. Actions whose preconditions are not fulfilled are skipped.
+// If the remediation orders its actions by success rate, the actions are ordered by the ranker first.
+// If no action is left, an ActionsUsedUpError is returned
+func GetNextAction(remediation *Remediation, problem Problem, actionIndex int, checker PreconditionChecker, ranker ActionRanker) (*NextAction, error) {
+	remediationMap := remediation.findRemediation(problem)
+
+	// we did not find an action
+	if remediationMap == nil {
+		return nil, fmt.Errorf("unable to find action for %s", problem.String())
 	}
 
-	// fallback: search problem type matching problem title
-	if actions == nil {
-		for _, r := range remediation.Spec.Remediations {
-			if r.ProblemType == problemTitle {
-				actions = r.ActionsOnOpen
-				break
-			}
+	actions := remediationMap.ActionsOnOpen
+	var ranking []string
+	if remediationMap.ActionOrder == ActionOrderSuccessRate && ranker != nil {
+		actions = ranker.Rank(actions)
+		for _, action := range actions {
+			ranking = append(ranking, action.key())
 		}
 	}
-
-	// fallback: search problem type default
-	if actions == nil {
-		for _, r := range remediation.Spec.Remediations {
-			if r.ProblemType == "default" {
-				actions = r.ActionsOnOpen
-				break
+	for i := actionIndex; i < len(actions); i++ {
+		action := actions[i]
+		if checker != nil {
+			if err := checker.Check(action); err != nil {
+				logger.Infof("skipping action %s for %s: %s", action.Name, problem.String(), err.Error())
+				continue
 			}
 		}
-	}
-
-	// we did not find an action
-	if actions == nil {
-		return nil, fmt.Errorf("unable to find action for %s", problem)
+		return &NextAction{
+			Action: keptnv2.ActionInfo{
+				Name:        action.Name,
+				Action:      action.Action,
+				Description: action.Description,
+				Value:       action.Value,
+			},
+			Index:   i,
+			key:     action.key(),
+			ranking: ranking,
+		}, nil
 	}
 
 	// the required action does not exist
-	if actionIndex >= len(actions) {
-		return nil, fmt.Errorf("there is no action with index %d for %s", actionIndex, problem)
-	}
-
-	action := actions[actionIndex]
-	return &keptnv2.ActionInfo{
-		Name:        action.Name,
-		Action:      action.Action,
-		Description: action.Description,
-		Value:       action.Value,
-	}, nil
-
+	return nil, &ActionsUsedUpError{Problem: problem, ActionIndex: actionIndex, Escalation: remediation.getEscalation(remediationMap)}
 }
@@ -3,9 +3,7 @@ package handler_test
 import (
 	"encoding/json"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
-	"github.com/ghodss/yaml"
 	"github.com/keptn/go-utils/pkg/api/models"
-	"github.com/keptn/go-utils/pkg/lib/v0_1_4"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/go-sdk/pkg/sdk"
 	"github.com/keptn/keptn/remediation-service/handler"
@@ -14,6 +12,7 @@ import (
 	"log"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func newGetActionTriggeredEvent(filename string) cloudevents.Event {
@@ -65,18 +64,16 @@ func Test_Receiving_GetActionTriggeredEvent_RemediationFromServiceLevel(t *testi
 	require.Equal(t, keptnv2.ResultFailed, getActionFinishedData.Result)
 }
 
-func newRemediation(fileName string) *v0_1_4.Remediation {
-	content, err := ioutil.ReadFile(fileName)
+func newRemediation(fileName string) *handler.Remediation {
+	remediation, err := handler.ParseRemediationResource(newResourceFromFile(fileName))
 	if err != nil {
 		log.Fatal(err)
 	}
-	remediation := &v0_1_4.Remediation{}
-	yaml.Unmarshal(content, remediation)
 	return remediation
 }
 
-func newProblemDetails(problemTitle, rootCause string) keptnv2.ProblemDetails {
-	return keptnv2.ProblemDetails{
+func newProblemDetails(problemTitle, rootCause string) handler.Problem {
+	return handler.Problem{
 		ProblemTitle: problemTitle,
 		RootCause:    rootCause,
 	}
@@ -84,8 +81,8 @@ func newProblemDetails(problemTitle, rootCause string) keptnv2.ProblemDetails {
 
 func TestGetNextAction(t *testing.T) {
 	type args struct {
-		remediation    *v0_1_4.Remediation
-		problemDetails keptnv2.ProblemDetails
+		remediation    *handler.Remediation
+		problemDetails handler.Problem
 		actionIndex    int
 	}
 	tests := []struct {
@@ -177,13 +174,17 @@ func TestGetNextAction(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := handler.GetNextAction(tt.args.remediation, tt.args.problemDetails, tt.args.actionIndex)
+			got, err := handler.GetNextAction(tt.args.remediation, tt.args.problemDetails, tt.args.actionIndex, nil, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetNextAction() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetNextAction() got = %v, want %v", got, tt.want)
+			if tt.wantErr {
+				return
+			}
+			require.Equal(t, tt.args.actionIndex, got.Index)
+			if !reflect.DeepEqual(&got.Action, tt.want) {
+				t.Errorf("GetNextAction() got = %v, want %v", got.Action, tt.want)
 			}
 		})
 	}
@@ -196,12 +197,37 @@ func TestParseRemediationResource(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    *v0_1_4.Remediation
+		want    *handler.Remediation
 		wantErr bool
 	}{
-		{"", args{
-			resource: newResourceFromFile("test/remediation.yaml"),
-		}, newRemediation("test/remediation.yaml"), false},
+		{"spec version 0.1.4", args{
+			resource: newResourceFromFile("test/remediation-without-default.yaml"),
+		}, &handler.Remediation{
+			ApiVersion: "spec.keptn.sh/0.1.4",
+			Kind:       "Remediation",
+			Metadata:   handler.RemediationMetadata{Name: "service-remediation"},
+			Spec: handler.RemediationSpec{
+				Remediations: []handler.RemediationMap{
+					{
+						ProblemType: "problemType1",
+						ActionsOnOpen: []handler.RemediationAction{
+							{Name: "actionName1", Action: "action1", Description: "actionDescription1", Value: map[string]interface{}{"foo": "bar"}},
+							{Name: "actionName2", Action: "action2", Description: "actionDescription2", Value: map[string]interface{}{"foo": "baz"}},
+						},
+					},
+					{
+						ProblemType: "problemType2",
+						ActionsOnOpen: []handler.RemediationAction{
+							{Name: "actionName11", Action: "action11", Description: "actionDescription11", Value: map[string]interface{}{"foo": "bar"}},
+							{Name: "actionName22", Action: "action22", Description: "actionDescription22", Value: map[string]interface{}{"foo": "baz"}},
+						},
+					},
+				},
+			},
+		}, false},
+		{"unsupported spec version", args{
+			resource: &models.Resource{ResourceContent: "apiVersion: spec.keptn.sh/0.1.3\nkind: Remediation"},
+		}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -229,3 +255,70 @@ func newResourceFromFile(filename string) *models.Resource {
 		ResourceURI:     nil,
 	}
 }
+
+func newGetActionTriggeredEventWithProblem(problem map[string]interface{}, actionIndex int) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("f2b878d3-03c0-4e8f-bc3f-454bc1b3d79d")
+	event.SetType(keptnv2.GetTriggeredEventType(keptnv2.GetActionTaskName))
+	event.SetSource("test")
+	event.SetExtension("shkeptncontext", "08735340-6f9e-4b32-97ff-3b6c292bc50f")
+	_ = event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"project":    "myproject",
+		"stage":      "mystage",
+		"service":    "myservice",
+		"labels":     map[string]string{"team": "payments"},
+		"problem":    problem,
+		"get-action": map[string]interface{}{"actionIndex": actionIndex},
+	})
+	return event
+}
+
+func Test_Receiving_GetActionTriggeredEvent_PreconditionsAndEscalation(t *testing.T) {
+	remediationContent, err := ioutil.ReadFile("test/remediation-v2.yaml")
+	require.Nil(t, err)
+
+	// a Saturday, i.e. outside of the time window of the restart action
+	now := time.Date(2022, 4, 16, 10, 0, 0, 0, time.UTC)
+	eventSender := &sdk.EventSenderMock{SendEventFunc: func(event cloudevents.Event) error { return nil }}
+	getActionHandler := handler.NewGetActionEventHandler(handler.WithEventSender(eventSender), handler.WithClock(func() time.Time { return now }))
+
+	fakeKeptn := sdk.NewFakeKeptn("test-remediation-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: string(remediationContent)})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.get-action.triggered", getActionHandler)
+	fakeKeptn.Start()
+
+	problem := map[string]interface{}{"problemTitle": "Failure rate increase", "severity": "ERROR"}
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 0))
+	// the feature toggle can only be used once per hour, and restarts are only allowed on weekdays
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 0))
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 3))
+
+	require.Equal(t, 6, len(fakeKeptn.GetEventSender().SentEvents))
+
+	finishedEvent, _ := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	getActionFinishedData := keptnv2.GetActionFinishedEventData{}
+	require.Nil(t, finishedEvent.DataAs(&getActionFinishedData))
+	require.Equal(t, "disableFeature", getActionFinishedData.Action.Name)
+	require.Equal(t, 1, getActionFinishedData.GetAction.ActionIndex)
+
+	finishedEvent, _ = keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[3])
+	getActionFinishedData = keptnv2.GetActionFinishedEventData{}
+	require.Nil(t, finishedEvent.DataAs(&getActionFinishedData))
+	require.Equal(t, "rollback", getActionFinishedData.Action.Name)
+	require.Equal(t, 3, getActionFinishedData.GetAction.ActionIndex)
+
+	finishedEvent, _ = keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[5])
+	getActionFinishedData = keptnv2.GetActionFinishedEventData{}
+	require.Nil(t, finishedEvent.DataAs(&getActionFinishedData))
+	require.Equal(t, keptnv2.StatusSucceeded, getActionFinishedData.Status)
+	require.Equal(t, keptnv2.ResultFailed, getActionFinishedData.Result)
+	require.Contains(t, getActionFinishedData.Message, "escalated")
+
+	require.Len(t, eventSender.SendEventCalls(), 1)
+	escalationEvent := eventSender.SendEventCalls()[0].EventMoqParam
+	require.Equal(t, "sh.keptn.event.page-oncall.triggered", escalationEvent.Type())
+	escalationData := map[string]interface{}{}
+	require.Nil(t, escalationEvent.DataAs(&escalationData))
+	require.Equal(t, "myproject", escalationData["project"])
+	require.Equal(t, "Failure rate increase", escalationData["problem"].(map[string]interface{})["problemTitle"])
+}
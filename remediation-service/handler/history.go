@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// OutcomePending is the outcome of an action which has been selected, but not yet been evaluated
+	OutcomePending = "pending"
+	// OutcomeSuccess is the outcome of an action whose evaluation passed
+	OutcomeSuccess = "success"
+	// OutcomeFailure is the outcome of an action which failed, or whose evaluation failed
+	OutcomeFailure = "failure"
+)
+
+// maxHistoryRecords limits the number of records kept by the history. The oldest records are removed first
+const maxHistoryRecords = 10000
+
+// HistoryRecord describes a remediation action which has been selected for a problem, and its outcome
+type HistoryRecord struct {
+	KeptnContext string    `json:"keptnContext"`
+	Project      string    `json:"project"`
+	Stage        string    `json:"stage"`
+	Service      string    `json:"service"`
+	ProblemType  string    `json:"problemType"`
+	Action       string    `json:"action"`
+	Name         string    `json:"name"`
+	SelectedAt   time.Time `json:"selectedAt"`
+	Outcome      string    `json:"outcome"`
+	// ActionResult is the result of the action.finished event
+	ActionResult string `json:"actionResult,omitempty"`
+	// EvaluationResult is the result of the evaluation.finished event
+	EvaluationResult string     `json:"evaluationResult,omitempty"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	// Ranking contains the names of the actions in the order they have been ranked in for the problem.
+	// It is kept for all actions selected within the keptn context, so that the action index of the get-action events stays valid
+	Ranking []string `json:"ranking,omitempty"`
+}
+
+// HistoryFilter restricts the records of the history. Empty properties match all records
+type HistoryFilter struct {
+	Project     string
+	Stage       string
+	Service     string
+	ProblemType string
+}
+
+func (f HistoryFilter) matches(record HistoryRecord) bool {
+	return (f.Project == "" || f.Project == record.Project) &&
+		(f.Stage == "" || f.Stage == record.Stage) &&
+		(f.Service == "" || f.Service == record.Service) &&
+		(f.ProblemType == "" || f.ProblemType == record.ProblemType)
+}
+
+// ActionStats summarizes the outcomes of an action for a problem type of a service
+type ActionStats struct {
+	Project     string `json:"project"`
+	Stage       string `json:"stage"`
+	Service     string `json:"service"`
+	ProblemType string `json:"problemType"`
+	Name        string `json:"name"`
+	Executions  int    `json:"executions"`
+	Successes   int    `json:"successes"`
+	Failures    int    `json:"failures"`
+	Pending     int    `json:"pending"`
+	// SuccessRate is the share of successful executions among the completed executions
+	SuccessRate float64 `json:"successRate"`
+}
+
+// RemediationHistory records the selected remediation actions and their outcomes.
+// If a file is configured, the records are persisted to it, otherwise they are only kept in memory
+type RemediationHistory struct {
+	mtx     sync.RWMutex
+	records []HistoryRecord
+	file    string
+}
+
+// NewRemediationHistory creates a history which is only kept in memory
+func NewRemediationHistory() *RemediationHistory {
+	return &RemediationHistory{}
+}
+
+// NewPersistentRemediationHistory creates a history which is persisted to the given file. Existing records are loaded from the file
+func NewPersistentRemediationHistory(file string) (*RemediationHistory, error) {
+	h := &RemediationHistory{file: file}
+	content, err := ioutil.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read remediation history: %w", err)
+	}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &h.records); err != nil {
+			return nil, fmt.Errorf("could not parse remediation history: %w", err)
+		}
+	}
+	return h, nil
+}
+
+// AddSelection records that the action has been selected for a problem
+func (h *RemediationHistory) AddSelection(record HistoryRecord) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	record.Outcome = OutcomePending
+	h.records = append(h.records, record)
+	if len(h.records) > maxHistoryRecords {
+		h.records = h.records[len(h.records)-maxHistoryRecords:]
+	}
+	h.persist()
+}
+
+// SetActionResult stores the result of the action.finished event for the latest action selected within the keptn context.
+// A failed action completes the record with the outcome failure
+func (h *RemediationHistory) SetActionResult(keptnContext string, result string, t time.Time) bool {
+	return h.update(keptnContext, func(record *HistoryRecord) {
+		record.ActionResult = result
+		if result == string(keptnv2.ResultFailed) {
+			record.Outcome = OutcomeFailure
+			record.CompletedAt = &t
+		}
+	})
+}
+
+// SetEvaluationResult completes the latest action selected within the keptn context with the result of the evaluation.finished event.
+// The results pass and warning are considered a success
+func (h *RemediationHistory) SetEvaluationResult(keptnContext string, result string, t time.Time) bool {
+	return h.update(keptnContext, func(record *HistoryRecord) {
+		record.EvaluationResult = result
+		if result == string(keptnv2.ResultFailed) {
+			record.Outcome = OutcomeFailure
+		} else {
+			record.Outcome = OutcomeSuccess
+		}
+		record.CompletedAt = &t
+	})
+}
+
+// update applies the function to the latest pending record of the keptn context, and returns whether there was such a record
+func (h *RemediationHistory) update(keptnContext string, fn func(record *HistoryRecord)) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if h.records[i].KeptnContext != keptnContext {
+			continue
+		}
+		if h.records[i].Outcome != OutcomePending {
+			return false
+		}
+		fn(&h.records[i])
+		h.persist()
+		return true
+	}
+	return false
+}
+
+// persist writes the records to the file of the history. It needs to be called while holding the lock
+func (h *RemediationHistory) persist() {
+	if h.file == "" {
+		return
+	}
+	content, err := json.Marshal(h.records)
+	if err != nil {
+		logger.Errorf("could not serialize remediation history: %s", err.Error())
+		return
+	}
+	// write to a temporary file first, such that a crash does not leave a partially written history behind
+	tmpFileName := h.file + ".tmp"
+	if err := ioutil.WriteFile(tmpFileName, content, 0600); err != nil {
+		logger.Errorf("could not write remediation history to %s: %s", tmpFileName, err.Error())
+		return
+	}
+	if err := os.Rename(tmpFileName, h.file); err != nil {
+		logger.Errorf("could not write remediation history to %s: %s", h.file, err.Error())
+	}
+}
+
+// GetRanking returns the ranking of the actions selected within the keptn context, or nil if no ranked action has been selected yet
+func (h *RemediationHistory) GetRanking(keptnContext string) []string {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if h.records[i].KeptnContext == keptnContext && len(h.records[i].Ranking) > 0 {
+			return h.records[i].Ranking
+		}
+	}
+	return nil
+}
+
+// GetRecords returns the records matching the filter, starting with the most recent one
+func (h *RemediationHistory) GetRecords(filter HistoryFilter) []HistoryRecord {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	records := []HistoryRecord{}
+	for i := len(h.records) - 1; i >= 0; i-- {
+		if filter.matches(h.records[i]) {
+			records = append(records, h.records[i])
+		}
+	}
+	return records
+}
+
+// GetStats returns the statistics of the actions matching the filter. Records of the excluded keptn context are ignored
+func (h *RemediationHistory) GetStats(filter HistoryFilter, excludedKeptnContext string) []ActionStats {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	statsByKey := map[string]*ActionStats{}
+	keys := []string{}
+	for _, record := range h.records {
+		if !filter.matches(record) || (excludedKeptnContext != "" && record.KeptnContext == excludedKeptnContext) {
+			continue
+		}
+		key := record.Project + "/" + record.Stage + "/" + record.Service + "/" + record.ProblemType + "/" + record.Name
+		stats, ok := statsByKey[key]
+		if !ok {
+			stats = &ActionStats{
+				Project:     record.Project,
+				Stage:       record.Stage,
+				Service:     record.Service,
+				ProblemType: record.ProblemType,
+				Name:        record.Name,
+			}
+			statsByKey[key] = stats
+			keys = append(keys, key)
+		}
+		stats.Executions++
+		switch record.Outcome {
+		case OutcomeSuccess:
+			stats.Successes++
+		case OutcomeFailure:
+			stats.Failures++
+		default:
+			stats.Pending++
+		}
+	}
+	sort.Strings(keys)
+	result := []ActionStats{}
+	for _, key := range keys {
+		stats := statsByKey[key]
+		if completed := stats.Successes + stats.Failures; completed > 0 {
+			stats.SuccessRate = float64(stats.Successes) / float64(completed)
+		}
+		result = append(result, *stats)
+	}
+	return result
+}
+
+// historyRanker orders the actions of a remediation by their past success for a single problem
+type historyRanker struct {
+	history      *RemediationHistory
+	filter       HistoryFilter
+	keptnContext string
+}
+
+// Rank orders the actions by their success rate, starting with the most successful one.
+// Actions without completed executions are ranked with a success rate of 50%, and the order of the file is kept for equal rates.
+// Once an action has been selected for the keptn context, its ranking is reused, so that the order does not change while the actions
+// of a problem are executed, even if the outcomes of other problems are recorded in the meantime
+func (r historyRanker) Rank(actions []RemediationAction) []RemediationAction {
+	if ranking := r.history.GetRanking(r.keptnContext); ranking != nil {
+		return applyRanking(actions, ranking)
+	}
+	rates := map[string]float64{}
+	for _, stats := range r.history.GetStats(r.filter, r.keptnContext) {
+		// additive smoothing prevents single executions from dominating the order
+		rates[stats.Name] = float64(stats.Successes+1) / float64(stats.Successes+stats.Failures+2)
+	}
+	rate := func(action RemediationAction) float64 {
+		if r, ok := rates[action.key()]; ok {
+			return r
+		}
+		return 0.5
+	}
+	ranked := make([]RemediationAction, len(actions))
+	copy(ranked, actions)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return rate(ranked[i]) > rate(ranked[j])
+	})
+	return ranked
+}
+
+// applyRanking orders the actions according to the given names. Actions missing in the ranking, e.g. because the remediation has
+// been changed in the meantime, are appended in the order of the file
+func applyRanking(actions []RemediationAction, ranking []string) []RemediationAction {
+	position := map[string]int{}
+	for i, name := range ranking {
+		position[name] = i
+	}
+	pos := func(action RemediationAction) int {
+		if p, ok := position[action.key()]; ok {
+			return p
+		}
+		return len(ranking)
+	}
+	ranked := make([]RemediationAction, len(actions))
+	copy(ranked, actions)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return pos(ranked[i]) < pos(ranked[j])
+	})
+	return ranked
+}
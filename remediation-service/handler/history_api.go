@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// HistoryPath is the path of the API returning the records of the remediation history
+const HistoryPath = "/v1/remediation/history"
+
+// HistoryStatsPath is the path of the API returning the success rates of remediation actions
+const HistoryStatsPath = "/v1/remediation/history/stats"
+
+// HistoryAPI serves the records and the statistics of the remediation history.
+// Both endpoints can be filtered with the query parameters project, stage, service and problemType.
+// Requests need to provide the Keptn API token in the x-token header
+type HistoryAPI struct {
+	history *RemediationHistory
+	token   string
+}
+
+func NewHistoryAPI(history *RemediationHistory, token string) *HistoryAPI {
+	return &HistoryAPI{history: history, token: token}
+}
+
+// Register adds the endpoints of the API to the given mux
+func (a *HistoryAPI) Register(mux *http.ServeMux) {
+	mux.HandleFunc(HistoryPath, a.authenticated(a.getRecords))
+	mux.HandleFunc(HistoryStatsPath, a.authenticated(a.getStats))
+}
+
+func (a *HistoryAPI) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		token := req.Header.Get("x-token")
+		if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		next(w, req)
+	}
+}
+
+func (a *HistoryAPI) getRecords(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, a.history.GetRecords(historyFilterFromRequest(req)))
+}
+
+func (a *HistoryAPI) getStats(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, a.history.GetStats(historyFilterFromRequest(req), ""))
+}
+
+func historyFilterFromRequest(req *http.Request) HistoryFilter {
+	query := req.URL.Query()
+	return HistoryFilter{
+		Project:     query.Get("project"),
+		Stage:       query.Get("stage"),
+		Service:     query.Get("service"),
+		ProblemType: query.Get("problemType"),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
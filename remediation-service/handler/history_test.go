@@ -0,0 +1,268 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	"github.com/keptn/keptn/remediation-service/handler"
+	"github.com/stretchr/testify/require"
+)
+
+const successRateRemediation = `apiVersion: spec.keptn.sh/0.2.0
+kind: Remediation
+spec:
+  remediations:
+    - problemType: "Response time degradation"
+      actionOrder: successRate
+      actionsOnOpen:
+        - action: scale
+          name: scaleUp
+        - action: restart
+          name: restartPods
+        - action: toggle-feature
+          name: disableFeature
+`
+
+var historyTime = time.Date(2022, 4, 18, 10, 0, 0, 0, time.UTC)
+
+func newHistoryRecord(keptnContext string, name string) handler.HistoryRecord {
+	return handler.HistoryRecord{
+		KeptnContext: keptnContext,
+		Project:      "myproject",
+		Stage:        "mystage",
+		Service:      "myservice",
+		ProblemType:  "Response time degradation",
+		Action:       name,
+		Name:         name,
+		SelectedAt:   historyTime,
+	}
+}
+
+func TestRemediationHistory_Outcomes(t *testing.T) {
+	history := handler.NewRemediationHistory()
+
+	history.AddSelection(newHistoryRecord("ctx-1", "scaleUp"))
+	history.AddSelection(newHistoryRecord("ctx-2", "scaleUp"))
+	history.AddSelection(newHistoryRecord("ctx-3", "restartPods"))
+	history.AddSelection(newHistoryRecord("ctx-3", "disableFeature"))
+
+	require.True(t, history.SetActionResult("ctx-1", "pass", historyTime))
+	require.True(t, history.SetEvaluationResult("ctx-1", "warning", historyTime))
+	// the record has already been completed
+	require.False(t, history.SetEvaluationResult("ctx-1", "fail", historyTime))
+	require.True(t, history.SetActionResult("ctx-2", "fail", historyTime))
+	// only the latest action of a context is updated
+	require.True(t, history.SetEvaluationResult("ctx-3", "pass", historyTime))
+	require.False(t, history.SetEvaluationResult("unknown", "pass", historyTime))
+
+	records := history.GetRecords(handler.HistoryFilter{Service: "myservice"})
+	require.Len(t, records, 4)
+	require.Equal(t, "disableFeature", records[0].Name)
+	require.Equal(t, handler.OutcomeSuccess, records[0].Outcome)
+	require.Equal(t, handler.OutcomePending, records[1].Outcome)
+	require.Equal(t, handler.OutcomeFailure, records[2].Outcome)
+	require.Equal(t, "fail", records[2].ActionResult)
+	require.Equal(t, handler.OutcomeSuccess, records[3].Outcome)
+	require.Equal(t, "warning", records[3].EvaluationResult)
+
+	require.Empty(t, history.GetRecords(handler.HistoryFilter{Service: "otherservice"}))
+
+	stats := history.GetStats(handler.HistoryFilter{}, "")
+	require.Equal(t, []handler.ActionStats{
+		{Project: "myproject", Stage: "mystage", Service: "myservice", ProblemType: "Response time degradation", Name: "disableFeature", Executions: 1, Successes: 1, SuccessRate: 1},
+		{Project: "myproject", Stage: "mystage", Service: "myservice", ProblemType: "Response time degradation", Name: "restartPods", Executions: 1, Pending: 1},
+		{Project: "myproject", Stage: "mystage", Service: "myservice", ProblemType: "Response time degradation", Name: "scaleUp", Executions: 2, Successes: 1, Failures: 1, SuccessRate: 0.5},
+	}, stats)
+
+	require.Len(t, history.GetStats(handler.HistoryFilter{}, "ctx-3"), 1)
+}
+
+func TestRemediationHistory_Persistent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.json")
+
+	history, err := handler.NewPersistentRemediationHistory(file)
+	require.Nil(t, err)
+	history.AddSelection(newHistoryRecord("ctx-1", "scaleUp"))
+	history.SetEvaluationResult("ctx-1", "pass", historyTime)
+
+	loaded, err := handler.NewPersistentRemediationHistory(file)
+	require.Nil(t, err)
+	require.Equal(t, history.GetRecords(handler.HistoryFilter{}), loaded.GetRecords(handler.HistoryFilter{}))
+
+	// the history is written to a temporary file, which is renamed afterwards
+	_, err = os.Stat(file + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestGetNextAction_OrderedBySuccessRate(t *testing.T) {
+	history := handler.NewRemediationHistory()
+	for i, outcome := range []string{"fail", "fail", "pass"} {
+		keptnContext := "scale-" + string(rune('a'+i))
+		history.AddSelection(newHistoryRecord(keptnContext, "scaleUp"))
+		history.SetEvaluationResult(keptnContext, outcome, historyTime)
+	}
+	for i, outcome := range []string{"pass", "pass"} {
+		keptnContext := "disable-" + string(rune('a'+i))
+		history.AddSelection(newHistoryRecord(keptnContext, "disableFeature"))
+		history.SetEvaluationResult(keptnContext, outcome, historyTime)
+	}
+
+	fakeKeptn := sdk.NewFakeKeptn("test-remediation-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: successRateRemediation})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.get-action.triggered", handler.NewGetActionEventHandler(handler.WithHistory(history)))
+	fakeKeptn.Start()
+
+	problem := map[string]interface{}{"problemTitle": "Response time degradation"}
+	for i := 0; i < 3; i++ {
+		fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, i))
+	}
+
+	// disableFeature: 3/4, restartPods: 1/2 (no executions), scaleUp: 2/5
+	require.Len(t, fakeKeptn.GetEventSender().SentEvents, 6)
+	var names []string
+	for _, i := range []int{1, 3, 5} {
+		finishedEvent, _ := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[i])
+		getActionFinishedData := keptnv2.GetActionFinishedEventData{}
+		require.Nil(t, finishedEvent.DataAs(&getActionFinishedData))
+		names = append(names, getActionFinishedData.Action.Name)
+	}
+	require.Equal(t, []string{"disableFeature", "restartPods", "scaleUp"}, names)
+
+	// the selections of the current context do not change the order
+	records := history.GetRecords(handler.HistoryFilter{ProblemType: "Response time degradation"})
+	require.Equal(t, "scaleUp", records[0].Name)
+	require.Equal(t, handler.OutcomePending, records[0].Outcome)
+	require.Equal(t, []string{"disableFeature", "restartPods", "scaleUp"}, records[0].Ranking)
+}
+
+func TestGetNextAction_RankingPinnedPerContext(t *testing.T) {
+	history := handler.NewRemediationHistory()
+	fakeKeptn := sdk.NewFakeKeptn("test-remediation-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: successRateRemediation})
+	fakeKeptn.AddTaskHandler("sh.keptn.event.get-action.triggered", handler.NewGetActionEventHandler(handler.WithHistory(history)))
+	fakeKeptn.Start()
+
+	problem := map[string]interface{}{"problemTitle": "Response time degradation"}
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 0))
+
+	// outcomes of other problems are recorded while the actions of the current problem are executed
+	for i := 0; i < 3; i++ {
+		keptnContext := "other-" + string(rune('a'+i))
+		history.AddSelection(newHistoryRecord(keptnContext, "disableFeature"))
+		history.SetEvaluationResult(keptnContext, "pass", historyTime)
+	}
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 1))
+	fakeKeptn.NewEvent(newGetActionTriggeredEventWithProblem(problem, 2))
+
+	require.Len(t, fakeKeptn.GetEventSender().SentEvents, 6)
+	var names []string
+	for _, i := range []int{1, 3, 5} {
+		finishedEvent, _ := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[i])
+		getActionFinishedData := keptnv2.GetActionFinishedEventData{}
+		require.Nil(t, finishedEvent.DataAs(&getActionFinishedData))
+		names = append(names, getActionFinishedData.Action.Name)
+	}
+	// without pinning, disableFeature would move to the front and scaleUp would be selected twice
+	require.Equal(t, []string{"scaleUp", "restartPods", "disableFeature"}, names)
+}
+
+func newFinishedEvent(eventType string, keptnContext string, result keptnv2.ResultType) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("c4d3a334-6cb8-4e21-8d07-5f0dea6d2de7")
+	event.SetType(eventType)
+	event.SetSource("test")
+	event.SetExtension("shkeptncontext", keptnContext)
+	_ = event.SetData(cloudevents.ApplicationJSON, keptnv2.EventData{
+		Project: "myproject",
+		Stage:   "mystage",
+		Service: "myservice",
+		Status:  keptnv2.StatusSucceeded,
+		Result:  result,
+	})
+	return event
+}
+
+func TestOutcomeEventHandler(t *testing.T) {
+	history := handler.NewRemediationHistory()
+	history.AddSelection(newHistoryRecord("ctx-1", "scaleUp"))
+	history.AddSelection(newHistoryRecord("ctx-2", "restartPods"))
+
+	outcomeHandler := handler.NewOutcomeEventHandler(history)
+	fakeKeptn := sdk.NewFakeKeptn("test-remediation-svc")
+	fakeKeptn.AddTaskHandler("sh.keptn.event.action.finished", outcomeHandler)
+	fakeKeptn.AddTaskHandler("sh.keptn.event.evaluation.finished", outcomeHandler)
+	fakeKeptn.Start()
+
+	fakeKeptn.NewEvent(newFinishedEvent("sh.keptn.event.action.finished", "ctx-1", keptnv2.ResultPass))
+	fakeKeptn.NewEvent(newFinishedEvent("sh.keptn.event.evaluation.finished", "ctx-1", keptnv2.ResultPass))
+	fakeKeptn.NewEvent(newFinishedEvent("sh.keptn.event.action.finished", "ctx-2", keptnv2.ResultFailed))
+	// evaluations of other sequences are ignored
+	fakeKeptn.NewEvent(newFinishedEvent("sh.keptn.event.evaluation.finished", "delivery", keptnv2.ResultFailed))
+
+	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
+	records := history.GetRecords(handler.HistoryFilter{})
+	require.Len(t, records, 2)
+	require.Equal(t, handler.OutcomeFailure, records[0].Outcome)
+	require.Equal(t, handler.OutcomeSuccess, records[1].Outcome)
+	require.Equal(t, "pass", records[1].ActionResult)
+	require.Equal(t, "pass", records[1].EvaluationResult)
+}
+
+func TestHistoryAPI(t *testing.T) {
+	history := handler.NewRemediationHistory()
+	history.AddSelection(newHistoryRecord("ctx-1", "scaleUp"))
+	history.SetEvaluationResult("ctx-1", "pass", historyTime)
+	otherRecord := newHistoryRecord("ctx-2", "scaleUp")
+	otherRecord.Service = "otherservice"
+	history.AddSelection(otherRecord)
+
+	mux := http.NewServeMux()
+	handler.NewHistoryAPI(history, "my-token").Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	get := func(path string, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.Nil(t, err)
+		req.Header.Set("x-token", token)
+		resp, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		return resp
+	}
+
+	resp := get(handler.HistoryPath, "invalid-token")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = get(handler.HistoryPath+"?project=myproject&service=myservice", "my-token")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	records := []handler.HistoryRecord{}
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&records))
+	require.Len(t, records, 1)
+	require.Equal(t, "ctx-1", records[0].KeptnContext)
+
+	resp = get(handler.HistoryStatsPath+"?problemType="+"Response%20time%20degradation", "my-token")
+	defer resp.Body.Close()
+	stats := []handler.ActionStats{}
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&stats))
+	require.Len(t, stats, 2)
+	require.Equal(t, 1.0, stats[0].SuccessRate)
+	require.Equal(t, "otherservice", stats[1].Service)
+	require.Equal(t, 1, stats[1].Pending)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+handler.HistoryStatsPath, nil)
+	require.Nil(t, err)
+	req.Header.Set("x-token", "my-token")
+	resp, err = http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
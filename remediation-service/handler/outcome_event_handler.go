@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+)
+
+// OutcomeEventHandler records the outcome of remediation actions in the history.
+// It handles sh.keptn.event.action.finished and sh.keptn.event.evaluation.finished events.
+// Events of keptn contexts without a pending remediation action, e.g. of delivery sequences, are ignored
+type OutcomeEventHandler struct {
+	history *RemediationHistory
+	now     func() time.Time
+}
+
+func NewOutcomeEventHandler(history *RemediationHistory) *OutcomeEventHandler {
+	return &OutcomeEventHandler{
+		history: history,
+		now:     time.Now,
+	}
+}
+
+func (o *OutcomeEventHandler) Execute(k sdk.IKeptn, event sdk.KeptnEvent) (interface{}, *sdk.Error) {
+	eventData := &keptnv2.EventData{}
+	if err := keptnv2.Decode(event.Data, eventData); err != nil {
+		return nil, &sdk.Error{Err: err, StatusType: keptnv2.StatusErrored, ResultType: keptnv2.ResultFailed, Message: "Could not decode input event data"}
+	}
+
+	if event.Type == nil {
+		return nil, nil
+	}
+	var updated bool
+	switch *event.Type {
+	case keptnv2.GetFinishedEventType(keptnv2.ActionTaskName):
+		updated = o.history.SetActionResult(event.Shkeptncontext, string(eventData.Result), o.now())
+	case keptnv2.GetFinishedEventType(keptnv2.EvaluationTaskName):
+		updated = o.history.SetEvaluationResult(event.Shkeptncontext, string(eventData.Result), o.now())
+	}
+	if updated {
+		k.Logger().Infof("recorded result %s of %s for remediation of context %s", eventData.Result, *event.Type, event.Shkeptncontext)
+	}
+	// no .finished event is sent for .finished events
+	return nil, nil
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const timeOfDayLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func (p *ActionPreconditions) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxExecutionsPerHour < 0 {
+		return errors.New("maxExecutionsPerHour must not be negative")
+	}
+	for _, window := range p.TimeWindows {
+		if _, err := window.contains(time.Now()); err != nil {
+			return err
+		}
+		for _, d := range window.Days {
+			if _, err := parseWeekday(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseWeekday parses abbreviated (e.g. Mon) as well as full names (e.g. Monday) of weekdays
+func parseWeekday(d string) (time.Weekday, error) {
+	if len(d) >= 3 {
+		if weekday, ok := weekdays[strings.ToLower(d[:3])]; ok && (len(d) == 3 || strings.EqualFold(d, weekday.String())) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %s", d)
+}
+
+// contains returns whether the given time is within the time window
+func (w TimeWindow) contains(t time.Time) (bool, error) {
+	location := time.UTC
+	if w.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(w.Timezone); err != nil {
+			return false, fmt.Errorf("invalid timezone %s: %w", w.Timezone, err)
+		}
+	}
+	from, err := time.Parse(timeOfDayLayout, w.From)
+	if err != nil {
+		return false, fmt.Errorf("invalid start of time window %s: must have the format HH:MM", w.From)
+	}
+	to, err := time.Parse(timeOfDayLayout, w.To)
+	if err != nil {
+		return false, fmt.Errorf("invalid end of time window %s: must have the format HH:MM", w.To)
+	}
+
+	t = t.In(location)
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	fromMinute := from.Hour()*60 + from.Minute()
+	toMinute := to.Hour()*60 + to.Minute()
+
+	day := t.Weekday()
+	var inWindow bool
+	if fromMinute < toMinute {
+		inWindow = minuteOfDay >= fromMinute && minuteOfDay < toMinute
+	} else {
+		// the window spans midnight, i.e. the part after midnight belongs to the window starting on the previous day
+		inWindow = minuteOfDay >= fromMinute || minuteOfDay < toMinute
+		if minuteOfDay < toMinute {
+			day = (day + 6) % 7
+		}
+	}
+	if !inWindow || len(w.Days) == 0 {
+		return inWindow, nil
+	}
+	for _, d := range w.Days {
+		weekday, err := parseWeekday(d)
+		if err != nil {
+			return false, err
+		}
+		if weekday == day {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ActionExecutions keeps track of the actions selected within the last hour, to evaluate the maxExecutionsPerHour precondition.
+// The executions are only kept in memory
+type ActionExecutions struct {
+	mtx        sync.Mutex
+	executions map[string][]time.Time
+}
+
+func NewActionExecutions() *ActionExecutions {
+	return &ActionExecutions{executions: map[string][]time.Time{}}
+}
+
+// Add records the execution of the action within the given scope, e.g. the service of the problem
+func (a *ActionExecutions) Add(scope string, action string, t time.Time) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	key := scope + "/" + action
+	a.executions[key] = append(a.prune(key, t), t)
+}
+
+// CountLastHour returns how often the action has been executed within the given scope during the hour before t
+func (a *ActionExecutions) CountLastHour(scope string, action string, t time.Time) int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	key := scope + "/" + action
+	executions := a.prune(key, t)
+	if len(executions) == 0 {
+		delete(a.executions, key)
+	} else {
+		a.executions[key] = executions
+	}
+	return len(executions)
+}
+
+func (a *ActionExecutions) prune(key string, t time.Time) []time.Time {
+	var recent []time.Time
+	for _, execution := range a.executions[key] {
+		if t.Sub(execution) < time.Hour {
+			recent = append(recent, execution)
+		}
+	}
+	return recent
+}
+
+// preconditionChecker evaluates the preconditions of actions for a single problem
+type preconditionChecker struct {
+	scope      string
+	now        time.Time
+	executions *ActionExecutions
+}
+
+// Check returns an error describing why the action cannot be selected, or nil if all preconditions are fulfilled
+func (c preconditionChecker) Check(action RemediationAction) error {
+	preconditions := action.Preconditions
+	if preconditions == nil {
+		return nil
+	}
+	if len(preconditions.TimeWindows) > 0 {
+		inWindow := false
+		for _, window := range preconditions.TimeWindows {
+			contains, err := window.contains(c.now)
+			if err != nil {
+				return err
+			}
+			if contains {
+				inWindow = true
+				break
+			}
+		}
+		if !inWindow {
+			return errors.New("not within any of the configured time windows")
+		}
+	}
+	if preconditions.MaxExecutionsPerHour > 0 && c.executions != nil {
+		if count := c.executions.CountLastHour(c.scope, action.Action, c.now); count >= preconditions.MaxExecutionsPerHour {
+			return fmt.Errorf("already executed %d times within the last hour", count)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeWindow_Contains(t *testing.T) {
+	// Monday
+	monday := time.Date(2022, 4, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		window TimeWindow
+		time   time.Time
+		want   bool
+	}{
+		{
+			name:   "within window",
+			window: TimeWindow{From: "08:00", To: "18:00"},
+			time:   monday.Add(9 * time.Hour),
+			want:   true,
+		},
+		{
+			name:   "end of window is exclusive",
+			window: TimeWindow{From: "08:00", To: "18:00"},
+			time:   monday.Add(18 * time.Hour),
+			want:   false,
+		},
+		{
+			name:   "window spanning midnight",
+			window: TimeWindow{From: "22:00", To: "06:00", Days: []string{"Sunday"}},
+			time:   monday.Add(2 * time.Hour),
+			want:   true,
+		},
+		{
+			name:   "window spanning midnight - wrong day",
+			window: TimeWindow{From: "22:00", To: "06:00", Days: []string{"Mon"}},
+			time:   monday.Add(2 * time.Hour),
+			want:   false,
+		},
+		{
+			name:   "window with the same start and end spans the whole day",
+			window: TimeWindow{From: "08:00", To: "08:00"},
+			time:   monday.Add(7 * time.Hour),
+			want:   true,
+		},
+		{
+			name:   "window with the same start and end spans the whole day - days refer to the start",
+			window: TimeWindow{From: "08:00", To: "08:00", Days: []string{"Mon"}},
+			time:   monday.Add(7 * time.Hour),
+			want:   false,
+		},
+		{
+			name:   "timezone",
+			window: TimeWindow{From: "08:00", To: "18:00", Timezone: "America/New_York"},
+			time:   monday.Add(9 * time.Hour),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.contains(tt.time)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestActionExecutions(t *testing.T) {
+	now := time.Date(2022, 4, 18, 10, 0, 0, 0, time.UTC)
+	executions := NewActionExecutions()
+
+	executions.Add("myproject/mystage/myservice", "restart", now.Add(-90*time.Minute))
+	executions.Add("myproject/mystage/myservice", "restart", now.Add(-30*time.Minute))
+	executions.Add("myproject/mystage/otherservice", "restart", now.Add(-10*time.Minute))
+
+	require.Equal(t, 1, executions.CountLastHour("myproject/mystage/myservice", "restart", now))
+	require.Equal(t, 0, executions.CountLastHour("myproject/mystage/myservice", "scale", now))
+
+	checker := preconditionChecker{scope: "myproject/mystage/myservice", now: now, executions: executions}
+	require.NotNil(t, checker.Check(RemediationAction{Action: "restart", Preconditions: &ActionPreconditions{MaxExecutionsPerHour: 1}}))
+	require.Nil(t, checker.Check(RemediationAction{Action: "restart", Preconditions: &ActionPreconditions{MaxExecutionsPerHour: 2}}))
+}
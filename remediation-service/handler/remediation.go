@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/lib/v0_1_4"
+)
+
+const remediationSpecVersion = "spec.keptn.sh/0.1.4"
+const remediationSpecVersionV2 = "spec.keptn.sh/0.2.0"
+
+const defaultProblemType = "default"
+
+const (
+	matchTypeGlob  = "glob"
+	matchTypeRegex = "regex"
+)
+
+const (
+	// ActionOrderFile selects the actions in the order of the remediation.yaml file
+	ActionOrderFile = "file"
+	// ActionOrderSuccessRate selects the actions in the order of their past success for the problem type
+	ActionOrderSuccessRate = "successRate"
+)
+
+// Remediation is the in-memory representation of a remediation.yaml file.
+// Files using spec version 0.1.4 are converted into this representation when being parsed
+type Remediation struct {
+	ApiVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   RemediationMetadata `json:"metadata"`
+	Spec       RemediationSpec     `json:"spec"`
+}
+
+// RemediationMetadata describes Remediation metadata
+type RemediationMetadata struct {
+	Name string `json:"name"`
+}
+
+// RemediationSpec contains a list of remediations
+type RemediationSpec struct {
+	Remediations []RemediationMap `json:"remediations"`
+	// Escalation is used for all remediations which do not define their own escalation
+	Escalation *Escalation `json:"escalation,omitempty"`
+}
+
+// RemediationMap maps a problem to a list of actions which are executed when a problem.open occurred.
+// A problem is either selected by an exact match of the problemType, or by a matcher
+type RemediationMap struct {
+	ProblemType   string              `json:"problemType,omitempty"`
+	Match         *ProblemMatcher     `json:"match,omitempty"`
+	ActionsOnOpen []RemediationAction `json:"actionsOnOpen"`
+	// Escalation is triggered once all actions of the remediation have been used up
+	Escalation *Escalation `json:"escalation,omitempty"`
+	// ActionOrder defines the order in which the actions are selected: file (default) or successRate
+	ActionOrder string `json:"actionOrder,omitempty"`
+}
+
+// ProblemMatcher selects problems by their properties. All properties which are set have to match
+type ProblemMatcher struct {
+	// ProblemTitle is a pattern for the title of the problem
+	ProblemTitle string `json:"problemTitle,omitempty"`
+	// RootCause is a pattern for the root cause of the problem
+	RootCause string `json:"rootCause,omitempty"`
+	// MatchType defines how the patterns are interpreted: glob (default) or regex
+	MatchType string `json:"matchType,omitempty"`
+	// Labels contains patterns for the labels of the event, which all need to match
+	Labels map[string]string `json:"labels,omitempty"`
+	// Severity contains the accepted severities of the problem
+	Severity []string `json:"severity,omitempty"`
+}
+
+// RemediationAction describes an action which is executed when a problem.open occurred
+type RemediationAction struct {
+	Name          string               `json:"name"`
+	Action        string               `json:"action"`
+	Description   string               `json:"description"`
+	Value         interface{}          `json:"value"`
+	Preconditions *ActionPreconditions `json:"preconditions,omitempty"`
+}
+
+// key identifies the action within the remediation history
+func (a RemediationAction) key() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Action
+}
+
+// ActionPreconditions need to be fulfilled for an action to be selected. Otherwise, the action is skipped
+type ActionPreconditions struct {
+	// TimeWindows restricts the action to the given times of the day. The action can be selected if any of the windows applies
+	TimeWindows []TimeWindow `json:"timeWindows,omitempty"`
+	// MaxExecutionsPerHour limits how often the action is selected for a service within one hour
+	MaxExecutionsPerHour int `json:"maxExecutionsPerHour,omitempty"`
+}
+
+// TimeWindow is a time of the day, optionally restricted to certain days of the week
+type TimeWindow struct {
+	// From is the start of the window, e.g. 08:00
+	From string `json:"from"`
+	// To is the (exclusive) end of the window, e.g. 18:00. Windows ending before they start span midnight,
+	// windows with the same start and end span the whole day
+	To string `json:"to"`
+	// Days restricts the window to the given weekdays, e.g. Mon, Tue
+	Days []string `json:"days,omitempty"`
+	// Timezone of the window, e.g. Europe/Vienna. Default: UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Escalation describes the event which is sent once all actions of a remediation have been used up
+type Escalation struct {
+	// Type of the event, e.g. sh.keptn.event.escalation.triggered
+	Type string `json:"type"`
+	// Data is added to the data of the event, next to the project, stage, service, labels and problem
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Problem contains the properties of a problem which remediations are matched against
+type Problem struct {
+	ProblemTitle string
+	RootCause    string
+	Severity     string
+	Labels       map[string]string
+}
+
+// String returns the representation of the problem used within messages
+func (p Problem) String() string {
+	if p.RootCause != "" {
+		return "root cause " + p.RootCause
+	} else if p.ProblemTitle != "" {
+		return "problem title " + p.ProblemTitle
+	}
+	return "problem type default"
+}
+
+// Type returns the problem type used for tracking the history of remediations, i.e. the root cause, the problem title, or default
+func (p Problem) Type() string {
+	if p.RootCause != "" {
+		return p.RootCause
+	} else if p.ProblemTitle != "" {
+		return p.ProblemTitle
+	}
+	return defaultProblemType
+}
+
+// ParseRemediationResource returns the in-memory representation of a keptn resource.
+// Note, that the spec version of the remediation.yaml file needs to match "spec.keptn.sh/0.1.4" or "spec.keptn.sh/0.2.0"
+func ParseRemediationResource(resource *models.Resource) (*Remediation, error) {
+	versionInfo := struct {
+		ApiVersion string `json:"apiVersion"`
+	}{}
+	if err := yaml.Unmarshal([]byte(resource.ResourceContent), &versionInfo); err != nil {
+		return nil, fmt.Errorf("could not parse remediation.yaml: %w", err)
+	}
+
+	switch versionInfo.ApiVersion {
+	case remediationSpecVersion:
+		remediationData := &v0_1_4.Remediation{}
+		if err := yaml.Unmarshal([]byte(resource.ResourceContent), remediationData); err != nil {
+			return nil, fmt.Errorf("could not parse remediation.yaml: %w", err)
+		}
+		return convertRemediation014(remediationData), nil
+	case remediationSpecVersionV2:
+		remediationData := &Remediation{}
+		if err := yaml.Unmarshal([]byte(resource.ResourceContent), remediationData); err != nil {
+			return nil, fmt.Errorf("could not parse remediation.yaml: %w", err)
+		}
+		if err := remediationData.Validate(); err != nil {
+			return nil, fmt.Errorf("remediation.yaml is invalid: %w", err)
+		}
+		return remediationData, nil
+	default:
+		return nil, fmt.Errorf("remediation.yaml file does not conform to remediation spec %s or %s", remediationSpecVersion, remediationSpecVersionV2)
+	}
+}
+
+func convertRemediation014(remediation *v0_1_4.Remediation) *Remediation {
+	result := &Remediation{
+		ApiVersion: remediation.ApiVersion,
+		Kind:       remediation.Kind,
+		Metadata:   RemediationMetadata{Name: remediation.Metadata.Name},
+	}
+	for _, r := range remediation.Spec.Remediations {
+		remediationMap := RemediationMap{ProblemType: r.ProblemType}
+		for _, action := range r.ActionsOnOpen {
+			remediationMap.ActionsOnOpen = append(remediationMap.ActionsOnOpen, RemediationAction{
+				Name:        action.Name,
+				Action:      action.Action,
+				Description: action.Description,
+				Value:       action.Value,
+			})
+		}
+		result.Spec.Remediations = append(result.Spec.Remediations, remediationMap)
+	}
+	return result
+}
+
+// Validate checks the matchers, preconditions and escalations of the remediation
+func (r *Remediation) Validate() error {
+	if err := r.Spec.Escalation.validate(); err != nil {
+		return err
+	}
+	for i, remediation := range r.Spec.Remediations {
+		if remediation.ProblemType == "" && remediation.Match == nil {
+			return fmt.Errorf("remediation %d needs either a problemType or a match", i)
+		}
+		if err := remediation.Match.validate(); err != nil {
+			return fmt.Errorf("remediation %d: %w", i, err)
+		}
+		if err := remediation.Escalation.validate(); err != nil {
+			return fmt.Errorf("remediation %d: %w", i, err)
+		}
+		if remediation.ActionOrder != "" && remediation.ActionOrder != ActionOrderFile && remediation.ActionOrder != ActionOrderSuccessRate {
+			return fmt.Errorf("remediation %d: unsupported actionOrder %s", i, remediation.ActionOrder)
+		}
+		for _, action := range remediation.ActionsOnOpen {
+			if err := action.Preconditions.validate(); err != nil {
+				return fmt.Errorf("remediation %d: action %s: %w", i, action.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *ProblemMatcher) validate() error {
+	if m == nil {
+		return nil
+	}
+	if m.MatchType != "" && m.MatchType != matchTypeGlob && m.MatchType != matchTypeRegex {
+		return fmt.Errorf("unsupported matchType %s", m.MatchType)
+	}
+	patterns := []string{m.ProblemTitle, m.RootCause}
+	for _, pattern := range m.Labels {
+		patterns = append(patterns, pattern)
+	}
+	for _, pattern := range patterns {
+		if _, err := m.matches(pattern, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Escalation) validate() error {
+	if e != nil && e.Type == "" {
+		return errors.New("escalation needs an event type")
+	}
+	return nil
+}
+
+// Matches returns whether all criteria of the matcher are fulfilled by the problem
+func (m *ProblemMatcher) Matches(problem Problem) bool {
+	if m == nil {
+		return false
+	}
+	if ok, _ := m.matches(m.ProblemTitle, problem.ProblemTitle); !ok {
+		return false
+	}
+	if ok, _ := m.matches(m.RootCause, problem.RootCause); !ok {
+		return false
+	}
+	for key, pattern := range m.Labels {
+		value, found := problem.Labels[key]
+		if !found {
+			return false
+		}
+		if ok, _ := m.matches(pattern, value); !ok {
+			return false
+		}
+	}
+	if len(m.Severity) > 0 {
+		severityMatches := false
+		for _, severity := range m.Severity {
+			if strings.EqualFold(severity, problem.Severity) {
+				severityMatches = true
+				break
+			}
+		}
+		if !severityMatches {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks the value against the pattern. An empty pattern matches every value
+func (m *ProblemMatcher) matches(pattern, value string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+	if m.MatchType == matchTypeRegex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex %s: %w", pattern, err)
+		}
+		return re.MatchString(value), nil
+	}
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+	}
+	return re.MatchString(value), nil
+}
+
+// globToRegexp converts a glob pattern into an anchored regular expression. Unlike path.Match, * matches any sequence
+// of characters including /, as problem titles and labels are not paths. ? matches a single character,
+// [...] a character class, and \ escapes the following character
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^(?s:")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			expr.WriteString(pattern[i : i+end+2])
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString(")$")
+	return regexp.Compile(expr.String())
+}
+
+// findRemediation returns the remediation for the given problem. Remediations are selected in the following order:
+// a problemType matching the root cause, a problemType matching the problem title, the first matching matcher, and the problemType default
+func (r *Remediation) findRemediation(problem Problem) *RemediationMap {
+	selectors := []func(remediation RemediationMap) bool{
+		func(remediation RemediationMap) bool {
+			return remediation.ProblemType != "" && remediation.ProblemType == problem.RootCause
+		},
+		func(remediation RemediationMap) bool {
+			return remediation.ProblemType != "" && remediation.ProblemType == problem.ProblemTitle
+		},
+		func(remediation RemediationMap) bool {
+			return remediation.Match.Matches(problem)
+		},
+		func(remediation RemediationMap) bool {
+			return remediation.ProblemType == defaultProblemType
+		},
+	}
+	for _, selector := range selectors {
+		for i := range r.Spec.Remediations {
+			if selector(r.Spec.Remediations[i]) {
+				return &r.Spec.Remediations[i]
+			}
+		}
+	}
+	return nil
+}
+
+// getEscalation returns the escalation of the remediation, or the escalation of the spec as a fallback
+func (r *Remediation) getEscalation(remediation *RemediationMap) *Escalation {
+	if remediation != nil && remediation.Escalation != nil {
+		return remediation.Escalation
+	}
+	return r.Spec.Escalation
+}
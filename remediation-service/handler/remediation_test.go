@@ -0,0 +1,240 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/keptn/remediation-service/handler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemediationResource_SpecVersion020(t *testing.T) {
+	remediation, err := handler.ParseRemediationResource(newResourceFromFile("test/remediation-v2.yaml"))
+	require.Nil(t, err)
+
+	require.Equal(t, "sh.keptn.event.escalation.triggered", remediation.Spec.Escalation.Type)
+	require.Len(t, remediation.Spec.Remediations, 3)
+	require.Equal(t, &handler.ProblemMatcher{
+		ProblemTitle: "Failure rate increase*",
+		Labels:       map[string]string{"team": "payments"},
+		Severity:     []string{"ERROR"},
+	}, remediation.Spec.Remediations[1].Match)
+	require.Equal(t, 1, remediation.Spec.Remediations[1].ActionsOnOpen[0].Preconditions.MaxExecutionsPerHour)
+	require.Equal(t, []handler.TimeWindow{{From: "08:00", To: "18:00", Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}}}, remediation.Spec.Remediations[1].ActionsOnOpen[1].Preconditions.TimeWindows)
+}
+
+func TestParseRemediationResource_InvalidSpecVersion020(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "neither problemType nor match",
+			content: `
+  remediations:
+    - actionsOnOpen:
+        - action: scale`,
+		},
+		{
+			name: "invalid regex",
+			content: `
+  remediations:
+    - match:
+        rootCause: "(CPU"
+        matchType: regex`,
+		},
+		{
+			name: "invalid glob",
+			content: `
+  remediations:
+    - match:
+        problemTitle: "[CPU"`,
+		},
+		{
+			name: "unsupported match type",
+			content: `
+  remediations:
+    - match:
+        problemTitle: "CPU"
+        matchType: exact`,
+		},
+		{
+			name: "invalid time window",
+			content: `
+  remediations:
+    - problemType: default
+      actionsOnOpen:
+        - action: restart
+          preconditions:
+            timeWindows:
+              - from: "8am"
+                to: "18:00"`,
+		},
+		{
+			name: "invalid day",
+			content: `
+  remediations:
+    - problemType: default
+      actionsOnOpen:
+        - action: restart
+          preconditions:
+            timeWindows:
+              - from: "00:00"
+                to: "00:00"
+                days: [Someday]`,
+		},
+		{
+			name: "invalid timezone",
+			content: `
+  remediations:
+    - problemType: default
+      actionsOnOpen:
+        - action: restart
+          preconditions:
+            timeWindows:
+              - from: "08:00"
+                to: "18:00"
+                timezone: Mars/Olympus`,
+		},
+		{
+			name: "unsupported action order",
+			content: `
+  remediations:
+    - problemType: default
+      actionOrder: random`,
+		},
+		{
+			name: "escalation without type",
+			content: `
+  escalation:
+    data:
+      team: operations
+  remediations:
+    - problemType: default`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &models.Resource{ResourceContent: "apiVersion: spec.keptn.sh/0.2.0\nkind: Remediation\nspec:" + tt.content}
+			_, err := handler.ParseRemediationResource(resource)
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func TestProblemMatcher_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher *handler.ProblemMatcher
+		problem handler.Problem
+		want    bool
+	}{
+		{
+			name:    "glob on problem title",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "Failure rate*"},
+			problem: handler.Problem{ProblemTitle: "Failure rate increase"},
+			want:    true,
+		},
+		{
+			name:    "glob on problem title - no match",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "Failure rate*"},
+			problem: handler.Problem{ProblemTitle: "Response time degradation"},
+			want:    false,
+		},
+		{
+			name:    "glob on problem title containing a slash",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "*degradation*"},
+			problem: handler.Problem{ProblemTitle: "Response time degradation on /api/carts"},
+			want:    true,
+		},
+		{
+			name:    "glob is anchored",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "degradation"},
+			problem: handler.Problem{ProblemTitle: "Response time degradation"},
+			want:    false,
+		},
+		{
+			name:    "glob with single character wildcard and character class",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "CPU saturation on host-?[0-9]"},
+			problem: handler.Problem{ProblemTitle: "CPU saturation on host-a1"},
+			want:    true,
+		},
+		{
+			name:    "glob treats regex characters literally",
+			matcher: &handler.ProblemMatcher{ProblemTitle: "Failure rate (5xx)*"},
+			problem: handler.Problem{ProblemTitle: "Failure rate (5xx) increase"},
+			want:    true,
+		},
+		{
+			name:    "regex on root cause",
+			matcher: &handler.ProblemMatcher{RootCause: "saturation$", MatchType: "regex"},
+			problem: handler.Problem{RootCause: "CPU saturation"},
+			want:    true,
+		},
+		{
+			name:    "labels and severity",
+			matcher: &handler.ProblemMatcher{Labels: map[string]string{"team": "pay*"}, Severity: []string{"error", "PERFORMANCE"}},
+			problem: handler.Problem{Labels: map[string]string{"team": "payments", "owner": "alice"}, Severity: "ERROR"},
+			want:    true,
+		},
+		{
+			name:    "missing label",
+			matcher: &handler.ProblemMatcher{Labels: map[string]string{"team": "*"}},
+			problem: handler.Problem{Labels: map[string]string{"owner": "alice"}},
+			want:    false,
+		},
+		{
+			name:    "severity not accepted",
+			matcher: &handler.ProblemMatcher{Severity: []string{"ERROR"}},
+			problem: handler.Problem{Severity: "RESOURCE_CONTENTION"},
+			want:    false,
+		},
+		{
+			name:    "no matcher",
+			matcher: nil,
+			problem: handler.Problem{ProblemTitle: "Failure rate increase"},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.matcher.Matches(tt.problem))
+		})
+	}
+}
+
+func TestGetNextAction_Matchers(t *testing.T) {
+	remediation := newRemediation("test/remediation-v2.yaml")
+
+	// exact matches of the problem type have precedence over matchers
+	got, err := handler.GetNextAction(remediation, handler.Problem{ProblemTitle: "Response time degradation", Labels: map[string]string{"team": "payments"}}, 0, nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, "scaleUp", got.Action.Name)
+
+	got, err = handler.GetNextAction(remediation, handler.Problem{ProblemTitle: "Failure rate increase on checkout", Severity: "ERROR", Labels: map[string]string{"team": "payments"}}, 1, nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, "restartPods", got.Action.Name)
+	require.Equal(t, 1, got.Index)
+
+	got, err = handler.GetNextAction(remediation, handler.Problem{RootCause: "Memory saturation"}, 0, nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, "scaleUp", got.Action.Name)
+
+	_, err = handler.GetNextAction(remediation, handler.Problem{ProblemTitle: "Failure rate increase", Severity: "INFO"}, 0, nil, nil)
+	require.NotNil(t, err)
+}
+
+func TestGetNextAction_Escalation(t *testing.T) {
+	remediation := newRemediation("test/remediation-v2.yaml")
+
+	_, err := handler.GetNextAction(remediation, handler.Problem{ProblemTitle: "Failure rate increase", Severity: "ERROR", Labels: map[string]string{"team": "payments"}}, 3, nil, nil)
+	usedUpErr, ok := err.(*handler.ActionsUsedUpError)
+	require.True(t, ok)
+	require.Equal(t, "sh.keptn.event.page-oncall.triggered", usedUpErr.Escalation.Type)
+
+	// remediations without an escalation use the escalation of the spec
+	_, err = handler.GetNextAction(remediation, handler.Problem{RootCause: "CPU saturation"}, 1, nil, nil)
+	usedUpErr, ok = err.(*handler.ActionsUsedUpError)
+	require.True(t, ok)
+	require.Equal(t, "sh.keptn.event.escalation.triggered", usedUpErr.Escalation.Type)
+}
@@ -1,21 +1,68 @@
 package main
 
 import (
+	"log"
+	"net/http"
+	"os"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/go-sdk/pkg/sdk"
 	"github.com/keptn/keptn/remediation-service/handler"
 	"github.com/sirupsen/logrus"
-	"log"
 )
 
 const getActionTriggeredEventType = "sh.keptn.event.get-action.triggered"
+const actionFinishedEventType = "sh.keptn.event.action.finished"
+const evaluationFinishedEventType = "sh.keptn.event.evaluation.finished"
 const serviceName = "remediation-service"
 
+const envVarHistoryFile = "REMEDIATION_HISTORY_FILE"
+const envVarHistoryPort = "REMEDIATION_HISTORY_PORT"
+const defaultHistoryPort = "8082"
+const envVarAPIToken = "KEPTN_API_TOKEN"
+
 func main() {
+	eventSender, err := keptnv2.NewHTTPEventSender("")
+	if err != nil {
+		log.Fatal(err)
+	}
+	history, err := createHistory()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go startHistoryAPI(history)
+
+	outcomeHandler := handler.NewOutcomeEventHandler(history)
 	log.Fatal(sdk.NewKeptn(
 		serviceName,
 		sdk.WithTaskHandler(
 			getActionTriggeredEventType,
-			handler.NewGetActionEventHandler()),
+			handler.NewGetActionEventHandler(handler.WithEventSender(eventSender), handler.WithHistory(history))),
+		sdk.WithTaskHandler(actionFinishedEventType, outcomeHandler),
+		sdk.WithTaskHandler(evaluationFinishedEventType, outcomeHandler),
 		sdk.WithLogger(logrus.New()),
 	).Start())
 }
+
+func createHistory() (*handler.RemediationHistory, error) {
+	if file := os.Getenv(envVarHistoryFile); file != "" {
+		return handler.NewPersistentRemediationHistory(file)
+	}
+	return handler.NewRemediationHistory(), nil
+}
+
+func startHistoryAPI(history *handler.RemediationHistory) {
+	token := os.Getenv(envVarAPIToken)
+	if token == "" {
+		logrus.Warnf("%s is not set, the remediation history is not served", envVarAPIToken)
+		return
+	}
+	port := os.Getenv(envVarHistoryPort)
+	if port == "" {
+		port = defaultHistoryPort
+	}
+	mux := http.NewServeMux()
+	handler.NewHistoryAPI(history, token).Register(mux)
+	logrus.Infof("serving remediation history on port %s", port)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
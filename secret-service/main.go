@@ -34,6 +34,7 @@ import (
 // @BasePath /v1
 
 const envVarLogLevel = "LOG_LEVEL"
+const envVarSecretBackend = "SECRET_SERVICE_BACKEND"
 
 func main() {
 	log.SetLevel(log.InfoLevel)
@@ -59,12 +60,18 @@ func main() {
 
 	log.Infof("Registered Backends: %v", backend.GetRegisteredBackends())
 
+	backendType := osutils.GetOSEnvOrDefault(envVarSecretBackend, backend.SecretBackendTypeK8s)
+	if !backend.IsRegistered(backendType) {
+		log.Fatalf("Unknown secret backend type '%s' provided by '%s' env var", backendType, envVarSecretBackend)
+	}
+	log.Infof("Using secret backend: %s", backendType)
+
 	engine := gin.Default()
 	apiV1 := engine.Group("/v1")
 
-	// only kubernetes supported, so we hard code it for now
-	secretsBackend := backend.CreateBackend("kubernetes")
-	secretController := controller.NewSecretController(handler.NewSecretHandler(secretsBackend))
+	secretsBackend := backend.CreateBackend(backendType)
+	secretHistory := backend.NewSecretHistory(backend.CreateHistoryStore(backendType))
+	secretController := controller.NewSecretController(handler.NewSecretHandler(secretsBackend, secretHistory))
 	secretController.Inject(apiV1)
 
 	scopeController := controller.NewScopeController(handler.NewScopeHandler(secretsBackend))
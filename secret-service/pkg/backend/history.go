@@ -0,0 +1,287 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/keptn/keptn/secret-service/pkg/common"
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const DefaultMaxSecretVersions = 10
+const DefaultMaxAuditLogEntries = 1000
+
+// envVarKeepValues enables keeping the values of previous versions of a secret, which is required for rollbacks
+const envVarKeepValues = "SECRET_HISTORY_KEEP_VALUES"
+
+var ErrSecretVersionNotFound = errors.New("secret version not found")
+var ErrSecretHistoryNotFound = errors.New("secret history not found")
+var ErrSecretVersionValuesNotStored = errors.New("the values of the secret version have not been stored, rollbacks require " + envVarKeepValues + " to be enabled")
+
+//go:generate moq -pkg fake -out ./fake/secrethistorymanager_mock.go . SecretHistoryManager
+type SecretHistoryManager interface {
+	// RecordChange stores a new version of the given secret and adds an entry performed by the given caller to the audit log
+	RecordChange(operation model.SecretOperation, secret model.Secret, caller string) error
+	// GetSecretVersions returns the metadata of all stored versions of a secret, ordered from the latest to the oldest version
+	GetSecretVersions(secret model.SecretMetadata) ([]model.SecretVersion, error)
+	// GetSecretVersion returns the secret including its values as it was stored in the given version,
+	// or ErrSecretVersionValuesNotStored if the values have not been kept
+	GetSecretVersion(secret model.SecretMetadata, version int) (*model.Secret, error)
+	// GetLatestVersion returns the metadata of the latest version of a secret
+	GetLatestVersion(secret model.SecretMetadata) (*model.SecretVersion, error)
+	// GetAuditLog returns the audit log entries, optionally filtered by the name of a secret, ordered from the latest to the oldest entry
+	GetAuditLog(name string) ([]model.AuditLogEntry, error)
+}
+
+// SecretHistoryStore persists the history and the audit log of secrets
+type SecretHistoryStore interface {
+	// GetHistory returns the history of a secret or ErrSecretHistoryNotFound if there is none
+	GetHistory(name string) (*model.SecretHistory, error)
+	// UpdateHistory passes the current history of a secret, or nil if there is none, to update and stores the returned history.
+	// Implementations must not overwrite changes made concurrently by other instances of the secret-service,
+	// i.e. update is called again with the latest history if it has been modified in the meantime
+	UpdateHistory(name string, update func(history *model.SecretHistory) (*model.SecretHistory, error)) error
+	DeleteHistory(name string) error
+	GetAuditLog() ([]model.AuditLogEntry, error)
+	// AddAuditLogEntry appends an entry to the audit log, dropping the oldest entries if there are more than maxEntries
+	AddAuditLogEntry(entry model.AuditLogEntry, maxEntries int) error
+}
+
+// SecretHistory keeps a bounded list of versions per secret as well as a bounded audit log in a SecretHistoryStore.
+// Unless KeepValues is set, only the hashes of the values of a secret are stored
+type SecretHistory struct {
+	Store              SecretHistoryStore
+	MaxVersions        int
+	MaxAuditLogEntries int
+	KeepValues         bool
+	Now                func() time.Time
+}
+
+func NewSecretHistory(store SecretHistoryStore) *SecretHistory {
+	keepValues, _ := strconv.ParseBool(common.EnvBasedStringSupplier(envVarKeepValues, "false")())
+	return &SecretHistory{
+		Store:              store,
+		MaxVersions:        DefaultMaxSecretVersions,
+		MaxAuditLogEntries: DefaultMaxAuditLogEntries,
+		KeepValues:         keepValues,
+		Now:                time.Now,
+	}
+}
+
+func (h SecretHistory) RecordChange(operation model.SecretOperation, secret model.Secret, caller string) error {
+	now := h.Now().UTC()
+	version := 0
+
+	if operation == model.SecretOperationDelete {
+		if err := h.Store.DeleteHistory(secret.Name); err != nil && !errors.Is(err, ErrSecretHistoryNotFound) {
+			return err
+		}
+	} else {
+		err := h.Store.UpdateHistory(secret.Name, func(history *model.SecretHistory) (*model.SecretHistory, error) {
+			if history == nil || operation == model.SecretOperationCreate {
+				// a newly created secret must not inherit the versions of a previously deleted secret with the same name
+				history = &model.SecretHistory{SecretMetadata: secret.SecretMetadata}
+			}
+			version = 1
+			if len(history.Versions) > 0 {
+				version = history.Versions[len(history.Versions)-1].Version + 1
+			}
+			history.Scope = secret.Scope
+			history.Versions = append(history.Versions, h.newStoredVersion(version, operation, secret, now))
+			if h.MaxVersions > 0 && len(history.Versions) > h.MaxVersions {
+				history.Versions = history.Versions[len(history.Versions)-h.MaxVersions:]
+			}
+			return history, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return h.Store.AddAuditLogEntry(model.AuditLogEntry{
+		Time:      now,
+		Operation: operation,
+		Name:      secret.Name,
+		Scope:     secret.Scope,
+		Version:   version,
+		Caller:    caller,
+	}, h.MaxAuditLogEntries)
+}
+
+func (h SecretHistory) newStoredVersion(version int, operation model.SecretOperation, secret model.Secret, now time.Time) model.StoredSecretVersion {
+	keys := []string{}
+	hashes := map[string]string{}
+	for key, value := range secret.Data {
+		keys = insert(keys, key)
+		hashes[key] = fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+	}
+	sort.Strings(keys)
+
+	stored := model.StoredSecretVersion{
+		SecretVersion: model.SecretVersion{
+			Version:   version,
+			Operation: operation,
+			CreatedAt: now,
+			Keys:      keys,
+			ExpiresAt: secret.ExpiresAt,
+		},
+	}
+	if h.KeepValues {
+		stored.Data = secret.Data
+	} else {
+		stored.Hashes = hashes
+	}
+	return stored
+}
+
+// appendAuditLogEntry appends the entry to the audit log and drops the oldest entries if there are more than maxEntries
+func appendAuditLogEntry(entries []model.AuditLogEntry, entry model.AuditLogEntry, maxEntries int) []model.AuditLogEntry {
+	entries = append(entries, entry)
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	return entries
+}
+
+func (h SecretHistory) GetSecretVersions(secret model.SecretMetadata) ([]model.SecretVersion, error) {
+	history, err := h.getHistory(secret)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.SecretVersion, 0, len(history.Versions))
+	for i := len(history.Versions) - 1; i >= 0; i-- {
+		result = append(result, history.Versions[i].SecretVersion)
+	}
+	return result, nil
+}
+
+func (h SecretHistory) GetSecretVersion(secret model.SecretMetadata, version int) (*model.Secret, error) {
+	history, err := h.getHistory(secret)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range history.Versions {
+		if v.Version == version {
+			if v.Data == nil && len(v.Keys) > 0 {
+				return nil, ErrSecretVersionValuesNotStored
+			}
+			return &model.Secret{
+				SecretMetadata: history.SecretMetadata,
+				Data:           v.Data,
+				ExpiresAt:      v.ExpiresAt,
+			}, nil
+		}
+	}
+	return nil, ErrSecretVersionNotFound
+}
+
+func (h SecretHistory) GetLatestVersion(secret model.SecretMetadata) (*model.SecretVersion, error) {
+	history, err := h.getHistory(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Versions) == 0 {
+		return nil, ErrSecretVersionNotFound
+	}
+	return &history.Versions[len(history.Versions)-1].SecretVersion, nil
+}
+
+func (h SecretHistory) GetAuditLog(name string) ([]model.AuditLogEntry, error) {
+	entries, err := h.Store.GetAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	result := []model.AuditLogEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if name == "" || entries[i].Name == name {
+			result = append(result, entries[i])
+		}
+	}
+	return result, nil
+}
+
+func (h SecretHistory) getHistory(secret model.SecretMetadata) (*model.SecretHistory, error) {
+	history, err := h.Store.GetHistory(secret.Name)
+	if err != nil {
+		if errors.Is(err, ErrSecretHistoryNotFound) {
+			return nil, ErrSecretNotFound
+		}
+		return nil, err
+	}
+	if secret.Scope != "" && history.Scope != secret.Scope {
+		log.Debugf("Scope %s of secret %s does not match requested scope %s", history.Scope, secret.Name, secret.Scope)
+		return nil, ErrSecretNotFound
+	}
+	return history, nil
+}
+
+// InMemorySecretHistoryStore keeps the history of secrets in memory, i.e. the history is lost when the service restarts
+type InMemorySecretHistoryStore struct {
+	histories map[string]model.SecretHistory
+	auditLog  []model.AuditLogEntry
+	mutex     *sync.Mutex
+}
+
+func NewInMemorySecretHistoryStore() *InMemorySecretHistoryStore {
+	return &InMemorySecretHistoryStore{
+		histories: map[string]model.SecretHistory{},
+		auditLog:  []model.AuditLogEntry{},
+		mutex:     &sync.Mutex{},
+	}
+}
+
+func (s *InMemorySecretHistoryStore) GetHistory(name string) (*model.SecretHistory, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	history, ok := s.histories[name]
+	if !ok {
+		return nil, ErrSecretHistoryNotFound
+	}
+	history.Versions = append([]model.StoredSecretVersion{}, history.Versions...)
+	return &history, nil
+}
+
+func (s *InMemorySecretHistoryStore) UpdateHistory(name string, update func(history *model.SecretHistory) (*model.SecretHistory, error)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var current *model.SecretHistory
+	if history, ok := s.histories[name]; ok {
+		history.Versions = append([]model.StoredSecretVersion{}, history.Versions...)
+		current = &history
+	}
+	updated, err := update(current)
+	if err != nil {
+		return err
+	}
+	s.histories[name] = *updated
+	return nil
+}
+
+func (s *InMemorySecretHistoryStore) DeleteHistory(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.histories[name]; !ok {
+		return ErrSecretHistoryNotFound
+	}
+	delete(s.histories, name)
+	return nil
+}
+
+func (s *InMemorySecretHistoryStore) GetAuditLog() ([]model.AuditLogEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]model.AuditLogEntry{}, s.auditLog...), nil
+}
+
+func (s *InMemorySecretHistoryStore) AddAuditLogEntry(entry model.AuditLogEntry, maxEntries int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.auditLog = appendAuditLogEntry(s.auditLog, entry, maxEntries)
+	return nil
+}
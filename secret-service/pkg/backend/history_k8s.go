@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/keptn/keptn/secret-service/pkg/common"
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// SecretHistoryManagedBy is used as 'managed-by' label of the K8S secrets holding the history,
+// so that they are not returned as regular secrets by the K8sSecretBackend
+const SecretHistoryManagedBy = "keptn-secret-service-history"
+const secretHistoryNamePrefix = "keptn-secret-history-"
+const secretAuditLogName = "keptn-secret-audit-log"
+const secretHistoryDataKey = "history"
+const secretHistoryNameAnnotation = "keptn.sh/secret-name"
+
+// K8sSecretHistoryStore stores the history of each secret, including its previous values, in a dedicated K8S secret.
+// The audit log is kept in a single K8S secret
+type K8sSecretHistoryStore struct {
+	KubeAPI                kubernetes.Interface
+	KeptnNamespaceProvider common.StringSupplier
+}
+
+func NewK8sSecretHistoryStore(kubeAPI kubernetes.Interface) *K8sSecretHistoryStore {
+	return &K8sSecretHistoryStore{
+		KubeAPI:                kubeAPI,
+		KeptnNamespaceProvider: common.EnvBasedStringSupplier("POD_NAMESPACE", DefaultNamespace),
+	}
+}
+
+func (k K8sSecretHistoryStore) GetHistory(name string) (*model.SecretHistory, error) {
+	history := &model.SecretHistory{}
+	if err := k.read(historySecretName(name), history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (k K8sSecretHistoryStore) UpdateHistory(name string, update func(history *model.SecretHistory) (*model.SecretHistory, error)) error {
+	return k.modify(historySecretName(name), name, func(current *corev1.Secret) (interface{}, error) {
+		if current == nil {
+			return update(nil)
+		}
+		history := &model.SecretHistory{}
+		if err := decodeHistorySecret(current, history); err != nil {
+			return nil, err
+		}
+		return update(history)
+	})
+}
+
+func (k K8sSecretHistoryStore) DeleteHistory(name string) error {
+	err := k.KubeAPI.CoreV1().Secrets(k.KeptnNamespaceProvider()).Delete(context.TODO(), historySecretName(name), metav1.DeleteOptions{})
+	if err != nil {
+		if statusError, isStatus := err.(*k8serr.StatusError); isStatus && statusError.Status().Reason == metav1.StatusReasonNotFound {
+			return ErrSecretHistoryNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (k K8sSecretHistoryStore) GetAuditLog() ([]model.AuditLogEntry, error) {
+	entries := []model.AuditLogEntry{}
+	if err := k.read(secretAuditLogName, &entries); err != nil {
+		if err == ErrSecretHistoryNotFound {
+			return []model.AuditLogEntry{}, nil
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (k K8sSecretHistoryStore) AddAuditLogEntry(entry model.AuditLogEntry, maxEntries int) error {
+	return k.modify(secretAuditLogName, "", func(current *corev1.Secret) (interface{}, error) {
+		entries := []model.AuditLogEntry{}
+		if current != nil {
+			if err := decodeHistorySecret(current, &entries); err != nil {
+				return nil, err
+			}
+		}
+		return appendAuditLogEntry(entries, entry, maxEntries), nil
+	})
+}
+
+func (k K8sSecretHistoryStore) read(secretName string, into interface{}) error {
+	secret, err := k.KubeAPI.CoreV1().Secrets(k.KeptnNamespaceProvider()).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		if statusError, isStatus := err.(*k8serr.StatusError); isStatus && statusError.Status().Reason == metav1.StatusReasonNotFound {
+			return ErrSecretHistoryNotFound
+		}
+		return err
+	}
+	return decodeHistorySecret(secret, into)
+}
+
+// modify passes the current K8S secret, or nil if it does not exist yet, to update and stores the returned content.
+// The secret is updated using its resourceVersion, so that changes made concurrently by other instances of the secret-service
+// are not overwritten. On a conflict, update is called again with the latest version of the secret
+func (k K8sSecretHistoryStore) modify(secretName, originalName string, update func(current *corev1.Secret) (interface{}, error)) error {
+	isConflict := func(err error) bool {
+		return k8serr.IsConflict(err) || k8serr.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		namespace := k.KeptnNamespaceProvider()
+		current, err := k.KubeAPI.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+		if err != nil {
+			if !k8serr.IsNotFound(err) {
+				return err
+			}
+			current = nil
+		}
+
+		content, err := update(current)
+		if err != nil {
+			return err
+		}
+		secret, err := k.historySecretObj(secretName, originalName, content, namespace)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			// fails with AlreadyExists if another instance created the secret in the meantime
+			_, err = k.KubeAPI.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+			return err
+		}
+		secret.ResourceVersion = current.ResourceVersion
+		_, err = k.KubeAPI.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (k K8sSecretHistoryStore) historySecretObj(secretName, originalName string, content interface{}, namespace string) (*corev1.Secret, error) {
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": SecretHistoryManagedBy,
+			},
+		},
+		Data: map[string][]byte{secretHistoryDataKey: contentBytes},
+		Type: "Opaque",
+	}
+	if originalName != "" {
+		secret.Annotations = map[string]string{secretHistoryNameAnnotation: originalName}
+	}
+	return secret, nil
+}
+
+func decodeHistorySecret(secret *corev1.Secret, into interface{}) error {
+	content, ok := secret.Data[secretHistoryDataKey]
+	if !ok {
+		content = []byte(secret.StringData[secretHistoryDataKey])
+	}
+	return json.Unmarshal(content, into)
+}
+
+// historySecretName derives the name of the K8S secret holding the history from the secret name.
+// A hash is used to stay within the length limit of K8S resource names
+func historySecretName(name string) string {
+	return fmt.Sprintf("%s%x", secretHistoryNamePrefix, sha256.Sum256([]byte(name)))
+}
@@ -0,0 +1,272 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestSecretHistory(store SecretHistoryStore) *SecretHistory {
+	history := NewSecretHistory(store)
+	history.Now = func() time.Time {
+		return time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	}
+	return history
+}
+
+func testSecretHistoryStores(t *testing.T) map[string]SecretHistoryStore {
+	k8sStore := NewK8sSecretHistoryStore(k8sfake.NewSimpleClientset())
+	k8sStore.KeptnNamespaceProvider = FakeNamespaceProvider()
+	vaultBackend, _ := newTestVaultBackend(t)
+	fileStore, err := NewFileSecretHistoryStore(t.TempDir()+"/secrets.enc.history", testEncryptionKey)
+	require.Nil(t, err)
+
+	return map[string]SecretHistoryStore{
+		"in-memory":  NewInMemorySecretHistoryStore(),
+		"kubernetes": k8sStore,
+		"vault":      NewVaultSecretHistoryStore(*vaultBackend),
+		"file":       fileStore,
+	}
+}
+
+func TestSecretHistory_RecordChange(t *testing.T) {
+	for name, store := range testSecretHistoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			history := newTestSecretHistory(store)
+			history.KeepValues = true
+			expiresAt := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+
+			secret := createTestSecret("my-secret", "my-scope")
+			require.Nil(t, history.RecordChange(model.SecretOperationCreate, secret, "jane"))
+
+			secret.Data = map[string]string{"password": "new-keptn", "user": "keptn"}
+			secret.ExpiresAt = &expiresAt
+			require.Nil(t, history.RecordChange(model.SecretOperationUpdate, secret, "jane"))
+
+			versions, err := history.GetSecretVersions(secret.SecretMetadata)
+			require.Nil(t, err)
+			require.Len(t, versions, 2)
+			assert.Equal(t, model.SecretVersion{
+				Version:   2,
+				Operation: model.SecretOperationUpdate,
+				CreatedAt: history.Now(),
+				Keys:      []string{"password", "user"},
+				ExpiresAt: &expiresAt,
+			}, versions[0])
+			assert.Equal(t, 1, versions[1].Version)
+
+			latest, err := history.GetLatestVersion(secret.SecretMetadata)
+			require.Nil(t, err)
+			assert.Equal(t, 2, latest.Version)
+
+			previous, err := history.GetSecretVersion(secret.SecretMetadata, 1)
+			require.Nil(t, err)
+			assert.Equal(t, model.Data{"password": "keptn"}, previous.Data)
+
+			_, err = history.GetSecretVersion(secret.SecretMetadata, 3)
+			assert.ErrorIs(t, err, ErrSecretVersionNotFound)
+
+			_, err = history.GetSecretVersions(model.SecretMetadata{Name: "my-secret", Scope: "other-scope"})
+			assert.ErrorIs(t, err, ErrSecretNotFound)
+
+			require.Nil(t, history.RecordChange(model.SecretOperationDelete, secret, "jane"))
+			_, err = history.GetSecretVersions(secret.SecretMetadata)
+			assert.ErrorIs(t, err, ErrSecretNotFound)
+
+			auditLog, err := history.GetAuditLog("")
+			require.Nil(t, err)
+			require.Len(t, auditLog, 3)
+			assert.Equal(t, model.SecretOperationDelete, auditLog[0].Operation)
+			assert.Equal(t, model.SecretOperationUpdate, auditLog[1].Operation)
+			assert.Equal(t, 2, auditLog[1].Version)
+			assert.Equal(t, model.SecretOperationCreate, auditLog[2].Operation)
+			for _, entry := range auditLog {
+				assert.Equal(t, "jane", entry.Caller)
+			}
+		})
+	}
+}
+
+func TestSecretHistory_RecordChangeStoresHashesByDefault(t *testing.T) {
+	store := NewInMemorySecretHistoryStore()
+	history := newTestSecretHistory(store)
+
+	secret := createTestSecret("my-secret", "my-scope")
+	require.Nil(t, history.RecordChange(model.SecretOperationCreate, secret, "jane"))
+
+	stored, err := store.GetHistory("my-secret")
+	require.Nil(t, err)
+	require.Len(t, stored.Versions, 1)
+	assert.Nil(t, stored.Versions[0].Data)
+	// sha256 of "keptn"
+	assert.Equal(t, map[string]string{"password": "7cbdc2941fa96c43c11f76ac36a505efd488dba0a5302d411b1f82c4063e1a47"}, stored.Versions[0].Hashes)
+
+	versions, err := history.GetSecretVersions(secret.SecretMetadata)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"password"}, versions[0].Keys)
+
+	_, err = history.GetSecretVersion(secret.SecretMetadata, 1)
+	assert.ErrorIs(t, err, ErrSecretVersionValuesNotStored)
+}
+
+func TestSecretHistory_BoundedVersionsAndAuditLog(t *testing.T) {
+	history := newTestSecretHistory(NewInMemorySecretHistoryStore())
+	history.MaxVersions = 3
+	history.MaxAuditLogEntries = 4
+
+	secret := createTestSecret("my-secret", "my-scope")
+	require.Nil(t, history.RecordChange(model.SecretOperationCreate, secret, "jane"))
+	for i := 0; i < 4; i++ {
+		require.Nil(t, history.RecordChange(model.SecretOperationUpdate, secret, "jane"))
+	}
+	require.Nil(t, history.RecordChange(model.SecretOperationCreate, createTestSecret("other-secret", "my-scope"), "jane"))
+
+	versions, err := history.GetSecretVersions(secret.SecretMetadata)
+	require.Nil(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, 5, versions[0].Version)
+	assert.Equal(t, 3, versions[2].Version)
+
+	auditLog, err := history.GetAuditLog("")
+	require.Nil(t, err)
+	assert.Len(t, auditLog, 4)
+
+	auditLog, err = history.GetAuditLog("other-secret")
+	require.Nil(t, err)
+	assert.Len(t, auditLog, 1)
+}
+
+func TestSecretHistory_RecreatedSecretStartsNewHistory(t *testing.T) {
+	history := newTestSecretHistory(NewInMemorySecretHistoryStore())
+
+	secret := createTestSecret("my-secret", "my-scope")
+	require.Nil(t, history.RecordChange(model.SecretOperationCreate, secret, "jane"))
+	require.Nil(t, history.RecordChange(model.SecretOperationUpdate, secret, "jane"))
+	require.Nil(t, history.RecordChange(model.SecretOperationCreate, secret, "jane"))
+
+	versions, err := history.GetSecretVersions(secret.SecretMetadata)
+	require.Nil(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+}
+
+func TestSecretHistoryStore_History(t *testing.T) {
+	for name, store := range testSecretHistoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.GetHistory("my-secret")
+			assert.ErrorIs(t, err, ErrSecretHistoryNotFound)
+
+			history := model.SecretHistory{
+				SecretMetadata: model.SecretMetadata{Name: "my-secret", Scope: "my-scope"},
+				Versions: []model.StoredSecretVersion{
+					{SecretVersion: model.SecretVersion{Version: 1, Operation: model.SecretOperationCreate, Keys: []string{"password"}}, Data: model.Data{"password": "keptn"}},
+				},
+			}
+			require.Nil(t, store.UpdateHistory("my-secret", func(current *model.SecretHistory) (*model.SecretHistory, error) {
+				assert.Nil(t, current)
+				return &history, nil
+			}))
+
+			require.Nil(t, store.UpdateHistory("my-secret", func(current *model.SecretHistory) (*model.SecretHistory, error) {
+				require.NotNil(t, current)
+				assert.Len(t, current.Versions, 1)
+				current.Versions = append(current.Versions, model.StoredSecretVersion{
+					SecretVersion: model.SecretVersion{Version: 2, Operation: model.SecretOperationUpdate, Keys: []string{"password"}},
+					Data:          model.Data{"password": "new-keptn"},
+				})
+				return current, nil
+			}))
+
+			stored, err := store.GetHistory("my-secret")
+			require.Nil(t, err)
+			assert.Equal(t, history.SecretMetadata, stored.SecretMetadata)
+			require.Len(t, stored.Versions, 2)
+			assert.Equal(t, model.Data{"password": "new-keptn"}, stored.Versions[1].Data)
+
+			require.Nil(t, store.DeleteHistory("my-secret"))
+			_, err = store.GetHistory("my-secret")
+			assert.ErrorIs(t, err, ErrSecretHistoryNotFound)
+		})
+	}
+}
+
+func TestSecretHistoryStore_AuditLog(t *testing.T) {
+	for name, store := range testSecretHistoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			entries, err := store.GetAuditLog()
+			require.Nil(t, err)
+			assert.Empty(t, entries)
+
+			for i := 1; i <= 3; i++ {
+				require.Nil(t, store.AddAuditLogEntry(model.AuditLogEntry{Operation: model.SecretOperationUpdate, Name: "my-secret", Version: i}, 2))
+			}
+
+			entries, err = store.GetAuditLog()
+			require.Nil(t, err)
+			require.Len(t, entries, 2)
+			assert.Equal(t, 2, entries[0].Version)
+			assert.Equal(t, 3, entries[1].Version)
+		})
+	}
+}
+
+func TestK8sSecretHistoryStore_AddAuditLogEntryConflict(t *testing.T) {
+	kubernetes := k8sfake.NewSimpleClientset()
+	store := NewK8sSecretHistoryStore(kubernetes)
+	store.KeptnNamespaceProvider = FakeNamespaceProvider()
+	require.Nil(t, store.AddAuditLogEntry(model.AuditLogEntry{Name: "my-secret", Version: 1}, 0))
+
+	conflicts := 0
+	kubernetes.Fake.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		// another instance adds an entry between reading and updating the audit log
+		concurrent, err := store.historySecretObj(secretAuditLogName, "", []model.AuditLogEntry{{Name: "my-secret", Version: 1}, {Name: "other-secret", Version: 1}}, "keptn_namespace")
+		require.Nil(t, err)
+		require.Nil(t, kubernetes.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), concurrent, "keptn_namespace"))
+		return true, nil, k8serr.NewConflict(corev1.Resource("secrets"), secretAuditLogName, fmt.Errorf("the object has been modified"))
+	})
+
+	require.Nil(t, store.AddAuditLogEntry(model.AuditLogEntry{Name: "my-secret", Version: 2}, 0))
+	assert.Equal(t, 1, conflicts)
+
+	entries, err := store.GetAuditLog()
+	require.Nil(t, err)
+	assert.Equal(t, []model.AuditLogEntry{{Name: "my-secret", Version: 1}, {Name: "other-secret", Version: 1}, {Name: "my-secret", Version: 2}}, entries)
+}
+
+func TestVaultSecretHistoryStore_ModifyConflict(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+	store := NewVaultSecretHistoryStore(*backend)
+	require.Nil(t, store.AddAuditLogEntry(model.AuditLogEntry{Name: "my-secret", Version: 1}, 0))
+
+	attempts := 0
+	err := store.modify(store.auditLog, "log", func(current []byte) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			// another instance adds an entry between reading and writing the audit log
+			require.Nil(t, store.AddAuditLogEntry(model.AuditLogEntry{Name: "other-secret", Version: 1}, 0))
+		}
+		entries := []model.AuditLogEntry{}
+		require.Nil(t, json.Unmarshal(current, &entries))
+		return append(entries, model.AuditLogEntry{Name: "my-secret", Version: 2}), nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+
+	entries, err := store.GetAuditLog()
+	require.Nil(t, err)
+	assert.Equal(t, []model.AuditLogEntry{{Name: "my-secret", Version: 1}, {Name: "other-secret", Version: 1}, {Name: "my-secret", Version: 2}}, entries)
+}
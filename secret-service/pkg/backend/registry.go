@@ -1,5 +1,7 @@
 package backend
 
+import log "github.com/sirupsen/logrus"
+
 var backendRegistry = map[string]func() SecretBackend{}
 
 func Register(name string, factory func() SecretBackend) {
@@ -7,13 +9,35 @@ func Register(name string, factory func() SecretBackend) {
 }
 
 func GetRegisteredBackends() []string {
-	r := make([]string, len(backendRegistry))
+	r := make([]string, 0, len(backendRegistry))
 	for i := range backendRegistry {
 		r = append(r, i)
 	}
 	return r
 }
 
+func IsRegistered(backendType string) bool {
+	_, ok := backendRegistry[backendType]
+	return ok
+}
+
 func CreateBackend(backendType string) SecretBackend {
 	return backendRegistry[backendType]()
 }
+
+var historyStoreRegistry = map[string]func() SecretHistoryStore{}
+
+// RegisterHistoryStore registers the factory of the SecretHistoryStore that should be used together with the given backend type
+func RegisterHistoryStore(backendType string, factory func() SecretHistoryStore) {
+	historyStoreRegistry[backendType] = factory
+}
+
+// CreateHistoryStore creates the SecretHistoryStore registered for the given backend type.
+// If there is none, the history is only kept in memory
+func CreateHistoryStore(backendType string) SecretHistoryStore {
+	if factory, ok := historyStoreRegistry[backendType]; ok {
+		return factory()
+	}
+	log.Warnf("No persistent secret history store available for backend %s. Secret history will be kept in memory only", backendType)
+	return NewInMemorySecretHistoryStore()
+}
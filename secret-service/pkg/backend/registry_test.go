@@ -19,5 +19,10 @@ func Test_Register(t *testing.T) {
 
 	backends := backend.GetRegisteredBackends()
 	assert.Contains(t, backends, backend.SecretBackendTypeK8s)
+	assert.Contains(t, backends, backend.SecretBackendTypeVault)
+	assert.Contains(t, backends, backend.SecretBackendTypeFile)
+	assert.NotContains(t, backends, "")
+	assert.True(t, backend.IsRegistered("a"))
+	assert.False(t, backend.IsRegistered("c"))
 
 }
@@ -0,0 +1,35 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+func checkScopeDefined(scopesRepository repository.ScopesRepository, secret model.Secret) (model.Scopes, error) {
+	scopes, err := scopesRepository.Read()
+	if err != nil {
+		return model.Scopes{}, err
+	}
+	if _, ok := scopes.Scopes[secret.Scope]; !ok {
+		log.Errorf("Unable to find scope %s for secret %s", secret.Scope, secret.Name)
+		return model.Scopes{}, fmt.Errorf("unable to check defined scope %s for secret %s: %w", secret.Scope, secret.Name, ErrScopeNotFound)
+	}
+	return scopes, nil
+}
+
+func getScopes(scopesRepository repository.ScopesRepository) ([]string, error) {
+	scopes, err := scopesRepository.Read()
+	if err != nil {
+		return nil, err
+	}
+	scopeArray := make([]string, 0, len(scopes.Scopes))
+	for scope := range scopes.Scopes {
+		scopeArray = append(scopeArray, scope)
+	}
+	sort.Strings(scopeArray)
+	return scopeArray, nil
+}
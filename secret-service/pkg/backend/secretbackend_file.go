@@ -0,0 +1,380 @@
+package backend
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/keptn/keptn/secret-service/pkg/common"
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+const SecretBackendTypeFile = "file"
+
+var ErrInvalidEncryptionKey = errors.New("encryption key must be 16, 24 or 32 bytes long")
+
+// FileSecretBackend stores all secrets in a single file on disk which is encrypted using AES-GCM
+type FileSecretBackend struct {
+	FilePath         string
+	EncryptionKey    []byte
+	ScopesRepository repository.ScopesRepository
+	mutex            *sync.Mutex
+}
+
+type fileSecretEntry struct {
+	Scope string     `json:"scope"`
+	Data  model.Data `json:"data"`
+}
+
+type fileSecretStore map[string]fileSecretEntry
+
+func NewFileSecretBackend(filePath string, encryptionKey []byte, scopesRepository repository.ScopesRepository) (*FileSecretBackend, error) {
+	switch len(encryptionKey) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrInvalidEncryptionKey
+	}
+	return &FileSecretBackend{
+		FilePath:         filePath,
+		EncryptionKey:    encryptionKey,
+		ScopesRepository: scopesRepository,
+		mutex:            &sync.Mutex{},
+	}, nil
+}
+
+func (f FileSecretBackend) CreateSecret(secret model.Secret) error {
+	log.Infof("Creating secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(f.ScopesRepository, secret); err != nil {
+		return err
+	}
+	if err := checkKeySize(secret); err != nil {
+		return err
+	}
+
+	return f.modify(func(store fileSecretStore) error {
+		if _, ok := store[secret.Name]; ok {
+			return ErrSecretAlreadyExists
+		}
+		store[secret.Name] = fileSecretEntry{Scope: secret.Scope, Data: secret.Data}
+		return nil
+	})
+}
+
+func (f FileSecretBackend) UpdateSecret(secret model.Secret) error {
+	log.Infof("Updating secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(f.ScopesRepository, secret); err != nil {
+		return err
+	}
+
+	return f.modify(func(store fileSecretStore) error {
+		if entry, ok := store[secret.Name]; !ok || entry.Scope != secret.Scope {
+			return ErrSecretNotFound
+		}
+		store[secret.Name] = fileSecretEntry{Scope: secret.Scope, Data: secret.Data}
+		return nil
+	})
+}
+
+func (f FileSecretBackend) DeleteSecret(secret model.Secret) error {
+	log.Infof("Deleting secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(f.ScopesRepository, secret); err != nil {
+		return err
+	}
+
+	return f.modify(func(store fileSecretStore) error {
+		if entry, ok := store[secret.Name]; !ok || entry.Scope != secret.Scope {
+			return ErrSecretNotFound
+		}
+		delete(store, secret.Name)
+		return nil
+	})
+}
+
+func (f FileSecretBackend) GetSecrets() ([]model.GetSecretResponseItem, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	store, err := f.read()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+	}
+
+	result := []model.GetSecretResponseItem{}
+	for name, entry := range store {
+		keys := []string{}
+		for key := range entry.Data {
+			if key != "" {
+				keys = insert(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		result = append(result, model.GetSecretResponseItem{
+			SecretMetadata: model.SecretMetadata{
+				Name:  name,
+				Scope: entry.Scope,
+			},
+			Keys: keys,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (f FileSecretBackend) GetScopes() ([]string, error) {
+	return getScopes(f.ScopesRepository)
+}
+
+// modify reads the current content of the store, applies the given function and writes the result back to disk
+func (f FileSecretBackend) modify(fn func(store fileSecretStore) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	store, err := f.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return f.write(store)
+}
+
+func (f FileSecretBackend) read() (fileSecretStore, error) {
+	store := fileSecretStore{}
+	if err := f.file().read(&store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (f FileSecretBackend) write(store fileSecretStore) error {
+	return f.file().write(store)
+}
+
+func (f FileSecretBackend) file() encryptedFile {
+	return encryptedFile{path: f.FilePath, key: f.EncryptionKey}
+}
+
+// encryptedFile stores JSON encoded content in a file which is encrypted using AES-GCM
+type encryptedFile struct {
+	path string
+	key  []byte
+}
+
+// read decodes the content of the file into the given value. If the file does not exist, the value is left untouched
+func (e encryptedFile) read(into interface{}) error {
+	content, err := ioutil.ReadFile(e.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	plaintext, err := e.decrypt(content)
+	if err != nil {
+		return fmt.Errorf("could not decrypt file %s: %w", e.path, err)
+	}
+	return json.Unmarshal(plaintext, into)
+}
+
+func (e encryptedFile) write(content interface{}) error {
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := e.encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that the file is never left in a partially written state
+	tmpFile := e.path + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, ciphertext, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, e.path)
+}
+
+func (e encryptedFile) encrypt(plaintext []byte) ([]byte, error) {
+	gcm, err := e.newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (e encryptedFile) decrypt(ciphertext []byte) ([]byte, error) {
+	gcm, err := e.newGCM()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func (e encryptedFile) newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// FileSecretHistoryStore stores the history and the audit log of all secrets in a single file which is encrypted using AES-GCM
+type FileSecretHistoryStore struct {
+	file  encryptedFile
+	mutex *sync.Mutex
+}
+
+type fileHistoryContent struct {
+	Histories map[string]model.SecretHistory `json:"histories"`
+	AuditLog  []model.AuditLogEntry          `json:"auditLog"`
+}
+
+func NewFileSecretHistoryStore(filePath string, encryptionKey []byte) (*FileSecretHistoryStore, error) {
+	if _, err := aes.NewCipher(encryptionKey); err != nil {
+		return nil, ErrInvalidEncryptionKey
+	}
+	return &FileSecretHistoryStore{
+		file:  encryptedFile{path: filePath, key: encryptionKey},
+		mutex: &sync.Mutex{},
+	}, nil
+}
+
+func (f FileSecretHistoryStore) GetHistory(name string) (*model.SecretHistory, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	content, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	history, ok := content.Histories[name]
+	if !ok {
+		return nil, ErrSecretHistoryNotFound
+	}
+	return &history, nil
+}
+
+func (f FileSecretHistoryStore) UpdateHistory(name string, update func(history *model.SecretHistory) (*model.SecretHistory, error)) error {
+	return f.modify(func(content *fileHistoryContent) error {
+		var current *model.SecretHistory
+		if history, ok := content.Histories[name]; ok {
+			current = &history
+		}
+		updated, err := update(current)
+		if err != nil {
+			return err
+		}
+		content.Histories[name] = *updated
+		return nil
+	})
+}
+
+func (f FileSecretHistoryStore) DeleteHistory(name string) error {
+	return f.modify(func(content *fileHistoryContent) error {
+		if _, ok := content.Histories[name]; !ok {
+			return ErrSecretHistoryNotFound
+		}
+		delete(content.Histories, name)
+		return nil
+	})
+}
+
+func (f FileSecretHistoryStore) GetAuditLog() ([]model.AuditLogEntry, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	content, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return content.AuditLog, nil
+}
+
+func (f FileSecretHistoryStore) AddAuditLogEntry(entry model.AuditLogEntry, maxEntries int) error {
+	return f.modify(func(content *fileHistoryContent) error {
+		content.AuditLog = appendAuditLogEntry(content.AuditLog, entry, maxEntries)
+		return nil
+	})
+}
+
+func (f FileSecretHistoryStore) modify(fn func(content *fileHistoryContent) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	content, err := f.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(content); err != nil {
+		return err
+	}
+	return f.file.write(content)
+}
+
+func (f FileSecretHistoryStore) read() (*fileHistoryContent, error) {
+	content := &fileHistoryContent{}
+	if err := f.file.read(content); err != nil {
+		return nil, err
+	}
+	if content.Histories == nil {
+		content.Histories = map[string]model.SecretHistory{}
+	}
+	if content.AuditLog == nil {
+		content.AuditLog = []model.AuditLogEntry{}
+	}
+	return content, nil
+}
+
+func getFileStoreConfig() (string, []byte) {
+	filePath := common.EnvBasedStringSupplier("SECRET_STORE_FILE", "/data/secrets/secrets.enc")()
+	encryptionKey, err := base64.StdEncoding.DecodeString(os.Getenv("SECRET_STORE_KEY"))
+	if err != nil {
+		log.Fatalf("Unable to decode encryption key provided by 'SECRET_STORE_KEY' env var: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		log.Fatalf("Unable to create directory for secret store %s: %s", filePath, err)
+	}
+	return filePath, encryptionKey
+}
+
+func init() {
+	log.Info("Registering Secret Backend type: file")
+	Register(SecretBackendTypeFile, func() SecretBackend {
+		filePath, encryptionKey := getFileStoreConfig()
+		fileBackend, err := NewFileSecretBackend(filePath, encryptionKey, repository.NewFileBasedScopesRepository())
+		if err != nil {
+			log.Fatalf("Unable to create file secret backend: %s", err)
+		}
+		return mirrorToK8sIfEnabled(fileBackend)
+	})
+	RegisterHistoryStore(SecretBackendTypeFile, func() SecretHistoryStore {
+		filePath, encryptionKey := getFileStoreConfig()
+		historyStore, err := NewFileSecretHistoryStore(filePath+".history", encryptionKey)
+		if err != nil {
+			log.Fatalf("Unable to create file secret history store: %s", err)
+		}
+		return historyStore
+	})
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestFileBackend(t *testing.T) *FileSecretBackend {
+	scopesRepository := &fake.ScopesRepositoryMock{}
+	scopesRepository.ReadFunc = func() (model.Scopes, error) { return createTestScopes(), nil }
+
+	backend, err := NewFileSecretBackend(filepath.Join(t.TempDir(), "secrets.enc"), testEncryptionKey, scopesRepository)
+	require.Nil(t, err)
+	return backend
+}
+
+func TestNewFileSecretBackend_InvalidKey(t *testing.T) {
+	backend, err := NewFileSecretBackend("secrets.enc", []byte("too-short"), &fake.ScopesRepositoryMock{})
+	assert.Nil(t, backend)
+	assert.ErrorIs(t, err, ErrInvalidEncryptionKey)
+}
+
+func TestFileSecretBackend_CreateAndGetSecrets(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-other-secret", "my-scope")))
+
+	secrets, err := backend.GetSecrets()
+	require.Nil(t, err)
+	assert.Equal(t, []model.GetSecretResponseItem{
+		{SecretMetadata: model.SecretMetadata{Name: "my-other-secret", Scope: "my-scope"}, Keys: []string{"password"}},
+		{SecretMetadata: model.SecretMetadata{Name: "my-secret", Scope: "my-scope"}, Keys: []string{"password"}},
+	}, secrets)
+
+	// the secret values must not be stored in plain text
+	content, err := ioutil.ReadFile(backend.FilePath)
+	require.Nil(t, err)
+	assert.NotContains(t, string(content), "keptn")
+	assert.NotContains(t, string(content), "my-secret")
+}
+
+func TestFileSecretBackend_CreateSecretAlreadyExists(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretAlreadyExists)
+}
+
+func TestFileSecretBackend_CreateSecretUnknownScope(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "unknown-scope"))
+	assert.ErrorIs(t, err, ErrScopeNotFound)
+}
+
+func TestFileSecretBackend_UpdateSecret(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	err := backend.UpdateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	updated := createTestSecret("my-secret", "my-scope")
+	updated.Data = map[string]string{"user": "keptn", "password": "new-keptn"}
+	require.Nil(t, backend.UpdateSecret(updated))
+
+	secrets, err := backend.GetSecrets()
+	require.Nil(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, []string{"password", "user"}, secrets[0].Keys)
+}
+
+func TestFileSecretBackend_UpdateSecretScopeMismatch(t *testing.T) {
+	backend := newTestFileBackend(t)
+	scopes := createTestScopes()
+	scopes.Scopes["my-other-scope"] = model.Scope{}
+	backend.ScopesRepository = &fake.ScopesRepositoryMock{ReadFunc: func() (model.Scopes, error) { return scopes, nil }}
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	err := backend.UpdateSecret(createTestSecret("my-secret", "my-other-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	secrets, err := backend.GetSecrets()
+	require.Nil(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "my-scope", secrets[0].Scope)
+}
+
+func TestFileSecretBackend_DeleteSecret(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	err := backend.DeleteSecret(createTestSecret("unknown-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	require.Nil(t, backend.DeleteSecret(createTestSecret("my-secret", "my-scope")))
+	secrets, err := backend.GetSecrets()
+	require.Nil(t, err)
+	assert.Empty(t, secrets)
+}
+
+func TestFileSecretBackend_WrongKey(t *testing.T) {
+	backend := newTestFileBackend(t)
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	otherBackend, err := NewFileSecretBackend(backend.FilePath, []byte("fedcba9876543210fedcba9876543210"), backend.ScopesRepository)
+	require.Nil(t, err)
+	_, err = otherBackend.GetSecrets()
+	assert.NotNil(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/keptn/keptn/secret-service/pkg/common"
@@ -42,15 +41,7 @@ func NewK8sSecretBackend(kubeAPI kubernetes.Interface, scopesRepository reposito
 }
 
 func (k K8sSecretBackend) checkScopeDefined(secret model.Secret) (model.Scopes, error) {
-	scopes, err := k.ScopesRepository.Read()
-	if err != nil {
-		return model.Scopes{}, err
-	}
-	if _, ok := scopes.Scopes[secret.Scope]; !ok {
-		log.Errorf("Unable to find scope %s for secret %s", secret.Scope, secret.Name)
-		return model.Scopes{}, fmt.Errorf("unable to check defined scope %s for secret %s: %w", secret.Scope, secret.Name, ErrScopeNotFound)
-	}
-	return scopes, nil
+	return checkScopeDefined(k.ScopesRepository, secret)
 }
 
 func (k K8sSecretBackend) CreateSecret(secret model.Secret) error {
@@ -305,16 +296,7 @@ func (k K8sSecretBackend) createK8sSecretObj(secret model.Secret, namespace stri
 }
 
 func (k K8sSecretBackend) GetScopes() ([]string, error) {
-	scopes, err := k.ScopesRepository.Read()
-	if err != nil {
-		return nil, err
-	}
-	scopeArray := make([]string, 0, len(scopes.Scopes))
-	for scope := range scopes.Scopes {
-		scopeArray = append(scopeArray, scope)
-	}
-	sort.Strings(scopeArray)
-	return scopeArray, nil
+	return getScopes(k.ScopesRepository)
 }
 
 func remove(s []string, r string) []string {
@@ -361,4 +343,11 @@ func init() {
 		scopesRepository := repository.NewFileBasedScopesRepository()
 		return NewK8sSecretBackend(kubeAPI, scopesRepository)
 	})
+	RegisterHistoryStore(SecretBackendTypeK8s, func() SecretHistoryStore {
+		kubeAPI, err := createKubeAPI()
+		if err != nil {
+			log.Fatalf("Unable to create kubernetes client: %s", err)
+		}
+		return NewK8sSecretHistoryStore(kubeAPI)
+	})
 }
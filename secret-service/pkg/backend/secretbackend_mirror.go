@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/keptn/keptn/secret-service/pkg/common"
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+// K8sMirrorEnvVar is the env var controlling whether secrets stored in vault or a file are mirrored into K8S Secrets
+const K8sMirrorEnvVar = "SECRET_SERVICE_K8S_MIRROR"
+
+// K8sMirroredSecretBackend is a SecretBackend storing secrets in another backend and mirroring them into K8S Secrets,
+// including the Roles and RoleBindings of their scope. Keptn services reading the secrets managed by the secret-service
+// from K8S, e.g. the webhook-service, therefore keep working if the secrets are stored in vault or a file.
+// Secrets are listed from the primary backend, which remains the source of truth
+type K8sMirroredSecretBackend struct {
+	SecretBackend
+	Mirror SecretManager
+}
+
+func NewK8sMirroredSecretBackend(primary SecretBackend, mirror SecretManager) *K8sMirroredSecretBackend {
+	return &K8sMirroredSecretBackend{
+		SecretBackend: primary,
+		Mirror:        mirror,
+	}
+}
+
+func (m K8sMirroredSecretBackend) CreateSecret(secret model.Secret) error {
+	if err := m.SecretBackend.CreateSecret(secret); err != nil {
+		return err
+	}
+
+	err := m.Mirror.CreateSecret(secret)
+	if errors.Is(err, ErrSecretAlreadyExists) {
+		// a leftover of a previous run, overwrite it with the values of the new secret
+		err = m.Mirror.UpdateSecret(secret)
+	}
+	if err != nil {
+		log.Errorf("Unable to mirror secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		// do not keep a secret the Keptn services are not able to read
+		if deleteErr := m.SecretBackend.DeleteSecret(secret); deleteErr != nil {
+			log.Errorf("Unable to delete secret %s after failed mirroring: %s", secret.Name, deleteErr)
+		}
+		return fmt.Errorf("could not mirror secret to kubernetes: %w", err)
+	}
+	return nil
+}
+
+func (m K8sMirroredSecretBackend) UpdateSecret(secret model.Secret) error {
+	if err := m.SecretBackend.UpdateSecret(secret); err != nil {
+		return err
+	}
+
+	err := m.Mirror.UpdateSecret(secret)
+	if errors.Is(err, ErrSecretNotFound) {
+		// the secret has been created before mirroring was enabled
+		err = m.Mirror.CreateSecret(secret)
+	}
+	if err != nil {
+		log.Errorf("Unable to mirror secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		return fmt.Errorf("could not mirror secret to kubernetes: %w", err)
+	}
+	return nil
+}
+
+func (m K8sMirroredSecretBackend) DeleteSecret(secret model.Secret) error {
+	if err := m.SecretBackend.DeleteSecret(secret); err != nil {
+		return err
+	}
+
+	if err := m.Mirror.DeleteSecret(secret); err != nil && !errors.Is(err, ErrSecretNotFound) {
+		log.Errorf("Unable to delete mirrored secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		return fmt.Errorf("could not delete mirrored secret from kubernetes: %w", err)
+	}
+	return nil
+}
+
+// mirrorToK8s wraps the given backend into a K8sMirroredSecretBackend if mirroring has been enabled
+func mirrorToK8s(secretBackend SecretBackend) (SecretBackend, error) {
+	if strings.ToLower(common.EnvBasedStringSupplier(K8sMirrorEnvVar, "false")()) != "true" {
+		return secretBackend, nil
+	}
+	kubeAPI, err := createKubeAPI()
+	if err != nil {
+		return nil, fmt.Errorf("could not create kubernetes client for mirroring secrets: %w", err)
+	}
+	return NewK8sMirroredSecretBackend(secretBackend, NewK8sSecretBackend(kubeAPI, repository.NewFileBasedScopesRepository())), nil
+}
+
+// mirrorToK8sIfEnabled wraps the given backend into a K8sMirroredSecretBackend if mirroring has been enabled.
+// If the secrets cannot be mirrored, the backend is used without mirroring
+func mirrorToK8sIfEnabled(secretBackend SecretBackend) SecretBackend {
+	mirroredBackend, err := mirrorToK8s(secretBackend)
+	if err != nil {
+		log.Errorf("Secrets are not mirrored into kubernetes secrets: %s", err)
+		return secretBackend
+	}
+	return mirroredBackend
+}
@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestMirroredBackend(t *testing.T) (*K8sMirroredSecretBackend, *FileSecretBackend, *k8sfake.Clientset) {
+	kubernetes := k8sfake.NewSimpleClientset()
+	scopesRepository := &fake.ScopesRepositoryMock{}
+	scopesRepository.ReadFunc = func() (model.Scopes, error) { return createTestScopes(), nil }
+
+	fileBackend := newTestFileBackend(t)
+	mirror := &K8sSecretBackend{
+		KubeAPI:                kubernetes,
+		KeptnNamespaceProvider: FakeNamespaceProvider(),
+		ScopesRepository:       scopesRepository,
+	}
+	return NewK8sMirroredSecretBackend(fileBackend, mirror), fileBackend, kubernetes
+}
+
+func TestK8sMirroredSecretBackend_CreateSecret(t *testing.T) {
+	backend, fileBackend, kubernetes := newTestMirroredBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	secrets, err := fileBackend.GetSecrets()
+	require.Nil(t, err)
+	assert.Len(t, secrets, 1)
+
+	k8sSecret, err := kubernetes.CoreV1().Secrets("keptn_namespace").Get(context.TODO(), "my-secret", metav1.GetOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "keptn"}, k8sSecret.StringData)
+	assert.Equal(t, SecretServiceName, k8sSecret.Labels["app.kubernetes.io/managed-by"])
+
+	roles, err := kubernetes.RbacV1().Roles("keptn_namespace").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, roles.Items, 2)
+}
+
+func TestK8sMirroredSecretBackend_CreateSecretAlreadyMirrored(t *testing.T) {
+	backend, _, kubernetes := newTestMirroredBackend(t)
+
+	_, err := kubernetes.CoreV1().Secrets("keptn_namespace").Create(context.TODO(), backend.Mirror.(*K8sSecretBackend).createK8sSecretObj(createTestSecret("my-secret", "my-scope"), "keptn_namespace"), metav1.CreateOptions{})
+	require.Nil(t, err)
+
+	secret := createTestSecret("my-secret", "my-scope")
+	secret.Data = map[string]string{"password": "new-keptn"}
+	require.Nil(t, backend.CreateSecret(secret))
+
+	k8sSecret, err := kubernetes.CoreV1().Secrets("keptn_namespace").Get(context.TODO(), "my-secret", metav1.GetOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "new-keptn"}, k8sSecret.StringData)
+}
+
+func TestK8sMirroredSecretBackend_CreateSecretMirrorFails(t *testing.T) {
+	backend, fileBackend, kubernetes := newTestMirroredBackend(t)
+	kubernetes.Fake.PrependReactor("create", "secrets", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, nil, fmt.Errorf("error occurred")
+	})
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.NotNil(t, err)
+
+	// the secret must not be kept in the primary backend
+	secrets, err := fileBackend.GetSecrets()
+	require.Nil(t, err)
+	assert.Empty(t, secrets)
+}
+
+func TestK8sMirroredSecretBackend_CreateSecretAlreadyExists(t *testing.T) {
+	backend, fileBackend, _ := newTestMirroredBackend(t)
+	require.Nil(t, fileBackend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretAlreadyExists)
+}
+
+func TestK8sMirroredSecretBackend_UpdateSecret(t *testing.T) {
+	backend, _, kubernetes := newTestMirroredBackend(t)
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	updated := createTestSecret("my-secret", "my-scope")
+	updated.Data = map[string]string{"password": "new-keptn"}
+	require.Nil(t, backend.UpdateSecret(updated))
+
+	k8sSecret, err := kubernetes.CoreV1().Secrets("keptn_namespace").Get(context.TODO(), "my-secret", metav1.GetOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "new-keptn"}, k8sSecret.StringData)
+}
+
+func TestK8sMirroredSecretBackend_UpdateSecretNotMirroredYet(t *testing.T) {
+	backend, fileBackend, kubernetes := newTestMirroredBackend(t)
+	require.Nil(t, fileBackend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	require.Nil(t, backend.UpdateSecret(createTestSecret("my-secret", "my-scope")))
+
+	_, err := kubernetes.CoreV1().Secrets("keptn_namespace").Get(context.TODO(), "my-secret", metav1.GetOptions{})
+	require.Nil(t, err)
+}
+
+func TestK8sMirroredSecretBackend_UpdateSecretNotFound(t *testing.T) {
+	backend, _, kubernetes := newTestMirroredBackend(t)
+
+	err := backend.UpdateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	secrets, err := kubernetes.CoreV1().Secrets("keptn_namespace").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, secrets.Items)
+}
+
+func TestK8sMirroredSecretBackend_DeleteSecret(t *testing.T) {
+	backend, fileBackend, kubernetes := newTestMirroredBackend(t)
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	require.Nil(t, backend.DeleteSecret(createTestSecret("my-secret", "my-scope")))
+
+	secrets, err := fileBackend.GetSecrets()
+	require.Nil(t, err)
+	assert.Empty(t, secrets)
+	k8sSecrets, err := kubernetes.CoreV1().Secrets("keptn_namespace").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Empty(t, k8sSecrets.Items)
+}
+
+func TestK8sMirroredSecretBackend_DeleteSecretNotMirrored(t *testing.T) {
+	backend, fileBackend, _ := newTestMirroredBackend(t)
+	require.Nil(t, fileBackend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	require.Nil(t, backend.DeleteSecret(createTestSecret("my-secret", "my-scope")))
+}
+
+func TestMirrorToK8s_DisabledByDefault(t *testing.T) {
+	fileBackend := newTestFileBackend(t)
+	secretBackend, err := mirrorToK8s(fileBackend)
+	require.NoError(t, err)
+	assert.Same(t, fileBackend, secretBackend)
+}
+
+func TestMirrorToK8s_NoKubernetesClient(t *testing.T) {
+	t.Setenv(K8sMirrorEnvVar, "true")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	fileBackend := newTestFileBackend(t)
+
+	_, err := mirrorToK8s(fileBackend)
+	require.Error(t, err)
+	assert.Same(t, fileBackend, mirrorToK8sIfEnabled(fileBackend))
+}
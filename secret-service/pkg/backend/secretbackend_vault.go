@@ -0,0 +1,438 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keptn/keptn/secret-service/pkg/common"
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+)
+
+const SecretBackendTypeVault = "vault"
+
+const vaultTokenHeader = "X-Vault-Token"
+const vaultScopeMetadataKey = "scope"
+const vaultManagedByMetadataKey = "managed-by"
+
+// VaultSecretBackend stores secrets in a HashiCorp Vault compatible KV version 2 secrets engine.
+// Each secret is stored at <MountPath>/data/<BasePath>/<secret name>, while its scope is kept
+// in the custom metadata of the secret
+type VaultSecretBackend struct {
+	HTTPClient       *http.Client
+	AddressProvider  common.StringSupplier
+	TokenProvider    common.StringSupplier
+	MountPath        string
+	BasePath         string
+	ScopesRepository repository.ScopesRepository
+}
+
+func NewVaultSecretBackend(httpClient *http.Client, scopesRepository repository.ScopesRepository) *VaultSecretBackend {
+	return &VaultSecretBackend{
+		HTTPClient:       httpClient,
+		AddressProvider:  common.EnvBasedStringSupplier("VAULT_ADDR", "http://127.0.0.1:8200"),
+		TokenProvider:    common.EnvBasedStringSupplier("VAULT_TOKEN", ""),
+		MountPath:        common.EnvBasedStringSupplier("VAULT_KV_MOUNT", "secret")(),
+		BasePath:         common.EnvBasedStringSupplier("VAULT_KV_PATH", "keptn")(),
+		ScopesRepository: scopesRepository,
+	}
+}
+
+type vaultKVData struct {
+	Data    map[string]string      `json:"data"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// vaultKVVersion is the content of a secret as returned by vault, including the version it is stored in
+type vaultKVVersion struct {
+	Data     map[string]string `json:"data"`
+	Metadata struct {
+		Version int `json:"version"`
+	} `json:"metadata"`
+}
+
+type vaultKVMetadata struct {
+	CustomMetadata map[string]string `json:"custom_metadata"`
+}
+
+var errVaultCASMismatch = errors.New("stored content has been modified concurrently")
+
+type vaultKeyList struct {
+	Keys []string `json:"keys"`
+}
+
+type vaultResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []string        `json:"errors"`
+}
+
+func (v VaultSecretBackend) CreateSecret(secret model.Secret) error {
+	log.Infof("Creating secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(v.ScopesRepository, secret); err != nil {
+		return err
+	}
+	if err := checkKeySize(secret); err != nil {
+		return err
+	}
+
+	// cas=0 makes vault reject the write if the secret already exists
+	statusCode, _, err := v.do(http.MethodPost, v.dataPath(secret.Name), vaultKVData{
+		Data:    secret.Data,
+		Options: map[string]interface{}{"cas": 0},
+	})
+	if err != nil {
+		log.Errorf("Unable to create secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		if statusCode == http.StatusBadRequest && strings.Contains(err.Error(), "check-and-set") {
+			return ErrSecretAlreadyExists
+		}
+		return err
+	}
+
+	return v.writeMetadata(secret)
+}
+
+func (v VaultSecretBackend) UpdateSecret(secret model.Secret) error {
+	log.Infof("Updating secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(v.ScopesRepository, secret); err != nil {
+		return err
+	}
+	metadata, err := v.readMetadata(secret.Name)
+	if err != nil {
+		log.Errorf("Unable to update secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		return err
+	}
+	if metadata.CustomMetadata[vaultScopeMetadataKey] != secret.Scope {
+		return ErrSecretNotFound
+	}
+
+	if _, _, err := v.do(http.MethodPost, v.dataPath(secret.Name), vaultKVData{Data: secret.Data}); err != nil {
+		log.Errorf("Unable to update secret %s: %s", secret.Name, err)
+		return err
+	}
+	return v.writeMetadata(secret)
+}
+
+func (v VaultSecretBackend) DeleteSecret(secret model.Secret) error {
+	log.Infof("Deleting secret: %s with scope %s", secret.Name, secret.Scope)
+	if _, err := checkScopeDefined(v.ScopesRepository, secret); err != nil {
+		return err
+	}
+	metadata, err := v.readMetadata(secret.Name)
+	if err != nil {
+		log.Errorf("Unable to delete secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		return err
+	}
+	if metadata.CustomMetadata[vaultScopeMetadataKey] != secret.Scope {
+		return ErrSecretNotFound
+	}
+
+	// deleting the metadata removes all versions of the secret
+	if _, _, err := v.do(http.MethodDelete, v.metadataPath(secret.Name), nil); err != nil {
+		log.Errorf("Unable to delete secret %s with scope %s: %s", secret.Name, secret.Scope, err)
+		return err
+	}
+	return nil
+}
+
+func (v VaultSecretBackend) GetSecrets() ([]model.GetSecretResponseItem, error) {
+	result := []model.GetSecretResponseItem{}
+
+	statusCode, body, err := v.do("LIST", v.metadataPath(""), nil)
+	if statusCode == http.StatusNotFound {
+		// nothing has been stored yet
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+	}
+	keyList := vaultKeyList{}
+	if err := json.Unmarshal(body, &keyList); err != nil {
+		return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+	}
+	sort.Strings(keyList.Keys)
+
+	for _, name := range keyList.Keys {
+		if strings.HasSuffix(name, "/") {
+			// nested paths are not created by the secret-service
+			continue
+		}
+		metadata, err := v.readMetadata(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+		}
+		if metadata.CustomMetadata[vaultManagedByMetadataKey] != SecretServiceName {
+			continue
+		}
+		_, body, err := v.do(http.MethodGet, v.dataPath(name), nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+		}
+		data := vaultKVData{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, fmt.Errorf("could not retrieve secrets: %s", err.Error())
+		}
+		keys := []string{}
+		for key := range data.Data {
+			if key != "" {
+				keys = insert(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		result = append(result, model.GetSecretResponseItem{
+			SecretMetadata: model.SecretMetadata{
+				Name:  name,
+				Scope: metadata.CustomMetadata[vaultScopeMetadataKey],
+			},
+			Keys: keys,
+		})
+	}
+	return result, nil
+}
+
+func (v VaultSecretBackend) GetScopes() ([]string, error) {
+	return getScopes(v.ScopesRepository)
+}
+
+func (v VaultSecretBackend) writeMetadata(secret model.Secret) error {
+	_, _, err := v.do(http.MethodPost, v.metadataPath(secret.Name), vaultKVMetadata{
+		CustomMetadata: map[string]string{
+			vaultScopeMetadataKey:     secret.Scope,
+			vaultManagedByMetadataKey: SecretServiceName,
+		},
+	})
+	if err != nil {
+		log.Errorf("Unable to store metadata of secret %s: %s", secret.Name, err)
+	}
+	return err
+}
+
+func (v VaultSecretBackend) readMetadata(name string) (*vaultKVMetadata, error) {
+	statusCode, body, err := v.do(http.MethodGet, v.metadataPath(name), nil)
+	if statusCode == http.StatusNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	metadata := &vaultKVMetadata{}
+	if err := json.Unmarshal(body, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (v VaultSecretBackend) dataPath(name string) string {
+	return v.path("data", name)
+}
+
+func (v VaultSecretBackend) metadataPath(name string) string {
+	return v.path("metadata", name)
+}
+
+func (v VaultSecretBackend) path(kind, name string) string {
+	segments := []string{strings.Trim(v.MountPath, "/"), kind}
+	if basePath := strings.Trim(v.BasePath, "/"); basePath != "" {
+		segments = append(segments, basePath)
+	}
+	if name != "" {
+		segments = append(segments, name)
+	}
+	return strings.Join(segments, "/")
+}
+
+// do sends a request to the vault API and returns the status code as well as the content of the "data" property of the response
+func (v VaultSecretBackend) do(method, path string, payload interface{}) (int, []byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		reqBody = bytes.NewBuffer(payloadBytes)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(v.AddressProvider(), "/")+"/v1/"+path, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set(vaultTokenHeader, v.TokenProvider())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	vaultResp := vaultResponse{}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &vaultResp); err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("could not decode response of vault: %w", err)
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, nil, fmt.Errorf("vault responded with status %d: %s", resp.StatusCode, strings.Join(vaultResp.Errors, ", "))
+	}
+	return resp.StatusCode, vaultResp.Data, nil
+}
+
+// VaultSecretHistoryStore stores the history of secrets next to the secrets in the KV version 2 secrets engine
+// at <MountPath>/data/<BasePath>-history/<secret name>. The audit log is stored at <MountPath>/data/<BasePath>-audit/log
+type VaultSecretHistoryStore struct {
+	histories VaultSecretBackend
+	auditLog  VaultSecretBackend
+}
+
+func NewVaultSecretHistoryStore(backend VaultSecretBackend) *VaultSecretHistoryStore {
+	histories := backend
+	histories.BasePath = strings.Trim(backend.BasePath, "/") + "-history"
+	auditLog := backend
+	auditLog.BasePath = strings.Trim(backend.BasePath, "/") + "-audit"
+	return &VaultSecretHistoryStore{
+		histories: histories,
+		auditLog:  auditLog,
+	}
+}
+
+func (v VaultSecretHistoryStore) GetHistory(name string) (*model.SecretHistory, error) {
+	history := &model.SecretHistory{}
+	if err := v.read(v.histories, name, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (v VaultSecretHistoryStore) UpdateHistory(name string, update func(history *model.SecretHistory) (*model.SecretHistory, error)) error {
+	return v.modify(v.histories, name, func(current []byte) (interface{}, error) {
+		if current == nil {
+			return update(nil)
+		}
+		history := &model.SecretHistory{}
+		if err := json.Unmarshal(current, history); err != nil {
+			return nil, err
+		}
+		return update(history)
+	})
+}
+
+func (v VaultSecretHistoryStore) DeleteHistory(name string) error {
+	_, _, err := v.histories.do(http.MethodDelete, v.histories.metadataPath(name), nil)
+	return err
+}
+
+func (v VaultSecretHistoryStore) GetAuditLog() ([]model.AuditLogEntry, error) {
+	entries := []model.AuditLogEntry{}
+	if err := v.read(v.auditLog, "log", &entries); err != nil {
+		if errors.Is(err, ErrSecretHistoryNotFound) {
+			return []model.AuditLogEntry{}, nil
+		}
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (v VaultSecretHistoryStore) AddAuditLogEntry(entry model.AuditLogEntry, maxEntries int) error {
+	return v.modify(v.auditLog, "log", func(current []byte) (interface{}, error) {
+		entries := []model.AuditLogEntry{}
+		if current != nil {
+			if err := json.Unmarshal(current, &entries); err != nil {
+				return nil, err
+			}
+		}
+		return appendAuditLogEntry(entries, entry, maxEntries), nil
+	})
+}
+
+func (v VaultSecretHistoryStore) read(backend VaultSecretBackend, name string, into interface{}) error {
+	content, _, err := v.readVersion(backend, name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, into)
+}
+
+// readVersion returns the stored content as well as the version of the secret holding it, or ErrSecretHistoryNotFound if there is none
+func (v VaultSecretHistoryStore) readVersion(backend VaultSecretBackend, name string) ([]byte, int, error) {
+	statusCode, body, err := backend.do(http.MethodGet, backend.dataPath(name), nil)
+	if statusCode == http.StatusNotFound {
+		return nil, 0, ErrSecretHistoryNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	data := vaultKVVersion{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, 0, err
+	}
+	return []byte(data.Data[secretHistoryDataKey]), data.Metadata.Version, nil
+}
+
+// modify passes the current content, or nil if nothing has been stored yet, to update and stores the returned content.
+// The content is written using the check-and-set parameter of the version that has been read, so that changes made
+// concurrently by other instances of the secret-service are not overwritten. On a mismatch, update is called again with the latest content
+func (v VaultSecretHistoryStore) modify(backend VaultSecretBackend, name string, update func(current []byte) (interface{}, error)) error {
+	isCASMismatch := func(err error) bool {
+		return errors.Is(err, errVaultCASMismatch)
+	}
+	return retry.OnError(retry.DefaultRetry, isCASMismatch, func() error {
+		current, version, err := v.readVersion(backend, name)
+		if err != nil && !errors.Is(err, ErrSecretHistoryNotFound) {
+			return err
+		}
+
+		content, err := update(current)
+		if err != nil {
+			return err
+		}
+		contentBytes, err := json.Marshal(content)
+		if err != nil {
+			return err
+		}
+		// vault rejects the write if the version has changed since it has been read
+		statusCode, _, err := backend.do(http.MethodPost, backend.dataPath(name), vaultKVData{
+			Data:    map[string]string{secretHistoryDataKey: string(contentBytes)},
+			Options: map[string]interface{}{"cas": version},
+		})
+		if err != nil && statusCode == http.StatusBadRequest && strings.Contains(err.Error(), "check-and-set") {
+			return fmt.Errorf("%w: %s", errVaultCASMismatch, err.Error())
+		}
+		return err
+	})
+}
+
+func checkKeySize(secret model.Secret) error {
+	if len(secret.Name) > 253 {
+		return ErrTooBigKeySize
+	}
+	for key := range secret.Data {
+		if len(key) > 253 {
+			return ErrTooBigKeySize
+		}
+	}
+	return nil
+}
+
+func init() {
+	log.Info("Registering Secret Backend type: vault")
+	Register(SecretBackendTypeVault, func() SecretBackend {
+		return mirrorToK8sIfEnabled(NewVaultSecretBackend(&http.Client{Timeout: 10 * time.Second}, repository.NewFileBasedScopesRepository()))
+	})
+	RegisterHistoryStore(SecretBackendTypeVault, func() SecretHistoryStore {
+		return NewVaultSecretHistoryStore(*NewVaultSecretBackend(&http.Client{Timeout: 10 * time.Second}, repository.NewFileBasedScopesRepository()))
+	})
+}
@@ -0,0 +1,234 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/keptn/keptn/secret-service/pkg/model"
+	"github.com/keptn/keptn/secret-service/pkg/repository/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVaultToken = "my-token"
+
+// fakeVault is a minimal in-memory stand-in for the KV version 2 secrets engine of vault
+type fakeVault struct {
+	mutex    sync.Mutex
+	data     map[string]map[string]string
+	versions map[string]int
+	metadata map[string]map[string]string
+}
+
+func newFakeVault() *fakeVault {
+	return &fakeVault{
+		data:     map[string]map[string]string{},
+		versions: map[string]int{},
+		metadata: map[string]map[string]string{},
+	}
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if r.Header.Get(vaultTokenHeader) != testVaultToken {
+		writeVaultResponse(w, http.StatusForbidden, nil, "permission denied")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+	switch {
+	case strings.HasPrefix(path, "data/"):
+		name := strings.TrimPrefix(path, "data/")
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := f.data[name]
+			if !ok {
+				writeVaultResponse(w, http.StatusNotFound, nil)
+				return
+			}
+			writeVaultResponse(w, http.StatusOK, map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": f.versions[name]}})
+		case http.MethodPost:
+			payload := vaultKVData{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			if cas, ok := payload.Options["cas"]; ok && int(cas.(float64)) != f.versions[name] {
+				writeVaultResponse(w, http.StatusBadRequest, nil, "check-and-set parameter did not match the current version")
+				return
+			}
+			f.data[name] = payload.Data
+			f.versions[name]++
+			writeVaultResponse(w, http.StatusOK, map[string]interface{}{"version": f.versions[name]})
+		}
+	case strings.HasPrefix(path, "metadata/"):
+		name := strings.TrimPrefix(path, "metadata/")
+		switch r.Method {
+		case "LIST":
+			keys := []string{}
+			for key := range f.data {
+				if strings.HasPrefix(key, name+"/") {
+					keys = append(keys, strings.TrimPrefix(key, name+"/"))
+				}
+			}
+			if len(keys) == 0 {
+				writeVaultResponse(w, http.StatusNotFound, nil)
+				return
+			}
+			sort.Strings(keys)
+			writeVaultResponse(w, http.StatusOK, vaultKeyList{Keys: keys})
+		case http.MethodGet:
+			if _, ok := f.data[name]; !ok {
+				writeVaultResponse(w, http.StatusNotFound, nil)
+				return
+			}
+			writeVaultResponse(w, http.StatusOK, vaultKVMetadata{CustomMetadata: f.metadata[name]})
+		case http.MethodPost:
+			payload := vaultKVMetadata{}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			f.metadata[name] = payload.CustomMetadata
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			delete(f.data, name)
+			delete(f.versions, name)
+			delete(f.metadata, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		writeVaultResponse(w, http.StatusNotFound, nil)
+	}
+}
+
+func writeVaultResponse(w http.ResponseWriter, status int, data interface{}, errs ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+}
+
+func newTestVaultBackend(t *testing.T) (*VaultSecretBackend, *fakeVault) {
+	vault := newFakeVault()
+	server := httptest.NewServer(vault)
+	t.Cleanup(server.Close)
+
+	scopesRepository := &fake.ScopesRepositoryMock{}
+	scopesRepository.ReadFunc = func() (model.Scopes, error) { return createTestScopes(), nil }
+
+	return &VaultSecretBackend{
+		HTTPClient:       server.Client(),
+		AddressProvider:  func() string { return server.URL },
+		TokenProvider:    func() string { return testVaultToken },
+		MountPath:        "secret",
+		BasePath:         "keptn",
+		ScopesRepository: scopesRepository,
+	}, vault
+}
+
+func TestVaultSecretBackend_CreateSecret(t *testing.T) {
+	backend, vault := newTestVaultBackend(t)
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]string{"password": "keptn"}, vault.data["keptn/my-secret"])
+	assert.Equal(t, "my-scope", vault.metadata["keptn/my-secret"][vaultScopeMetadataKey])
+	assert.Equal(t, SecretServiceName, vault.metadata["keptn/my-secret"][vaultManagedByMetadataKey])
+}
+
+func TestVaultSecretBackend_CreateSecretAlreadyExists(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretAlreadyExists)
+}
+
+func TestVaultSecretBackend_CreateSecretUnknownScope(t *testing.T) {
+	backend, vault := newTestVaultBackend(t)
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "unknown-scope"))
+	assert.ErrorIs(t, err, ErrScopeNotFound)
+	assert.Empty(t, vault.data)
+}
+
+func TestVaultSecretBackend_UpdateSecret(t *testing.T) {
+	backend, vault := newTestVaultBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	updated := createTestSecret("my-secret", "my-scope")
+	updated.Data = map[string]string{"password": "new-keptn"}
+	err := backend.UpdateSecret(updated)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "new-keptn"}, vault.data["keptn/my-secret"])
+}
+
+func TestVaultSecretBackend_UpdateSecretNotFound(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+
+	err := backend.UpdateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultSecretBackend_UpdateSecretScopeMismatch(t *testing.T) {
+	backend, vault := newTestVaultBackend(t)
+	scopes := createTestScopes()
+	scopes.Scopes["my-other-scope"] = model.Scope{}
+	backend.ScopesRepository = &fake.ScopesRepositoryMock{ReadFunc: func() (model.Scopes, error) { return scopes, nil }}
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+
+	updated := createTestSecret("my-secret", "my-other-scope")
+	updated.Data = map[string]string{"password": "new-keptn"}
+	err := backend.UpdateSecret(updated)
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Equal(t, map[string]string{"password": "keptn"}, vault.data["keptn/my-secret"])
+	assert.Equal(t, "my-scope", vault.metadata["keptn/my-secret"][vaultScopeMetadataKey])
+}
+
+func TestVaultSecretBackend_DeleteSecret(t *testing.T) {
+	backend, vault := newTestVaultBackend(t)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	err := backend.DeleteSecret(createTestSecret("my-secret", "my-scope"))
+	require.Nil(t, err)
+	assert.Empty(t, vault.data)
+	assert.Empty(t, vault.metadata)
+}
+
+func TestVaultSecretBackend_DeleteSecretNotFound(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+
+	err := backend.DeleteSecret(createTestSecret("my-secret", "my-scope"))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultSecretBackend_GetSecrets(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+
+	secrets, err := backend.GetSecrets()
+	require.Nil(t, err)
+	assert.Empty(t, secrets)
+
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-secret", "my-scope")))
+	require.Nil(t, backend.CreateSecret(createTestSecret("my-other-secret", "my-scope")))
+
+	secrets, err = backend.GetSecrets()
+	require.Nil(t, err)
+	assert.Equal(t, []model.GetSecretResponseItem{
+		{SecretMetadata: model.SecretMetadata{Name: "my-other-secret", Scope: "my-scope"}, Keys: []string{"password"}},
+		{SecretMetadata: model.SecretMetadata{Name: "my-secret", Scope: "my-scope"}, Keys: []string{"password"}},
+	}, secrets)
+}
+
+func TestVaultSecretBackend_InvalidToken(t *testing.T) {
+	backend, _ := newTestVaultBackend(t)
+	backend.TokenProvider = func() string { return "invalid" }
+
+	err := backend.CreateSecret(createTestSecret("my-secret", "my-scope"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
@@ -20,4 +20,7 @@ func (controller SecretController) Inject(apiGroup *gin.RouterGroup) {
 	apiGroup.DELETE(SecretAPIBasePath, controller.SecretHandler.DeleteSecret)
 	apiGroup.PUT(SecretAPIBasePath, controller.SecretHandler.UpdateSecret)
 	apiGroup.GET(SecretAPIBasePath, controller.SecretHandler.GetSecrets)
+	apiGroup.GET(SecretAPIBasePath+"/versions", controller.SecretHandler.GetSecretVersions)
+	apiGroup.POST(SecretAPIBasePath+"/rollback", controller.SecretHandler.RollbackSecret)
+	apiGroup.GET(SecretAPIBasePath+"/audit", controller.SecretHandler.GetAuditLog)
 }
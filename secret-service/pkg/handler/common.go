@@ -13,6 +13,9 @@ var ErrUpdateSecretMsg = "Unable to update secret: %s"
 var ErrGetSecretMsg = "Unable to get secret: %s"
 var ErrDeleteSecretMsg = "Unable to delete secret: %s"
 var ErrGetScopesMsg = "Unable to get scopes: %s"
+var ErrGetSecretVersionsMsg = "Unable to get secret versions: %s"
+var ErrRollbackSecretMsg = "Unable to roll back secret: %s"
+var ErrGetAuditLogMsg = "Unable to get audit log: %s"
 
 func SetBadRequestErrorResponse(c *gin.Context, msg string) {
 	c.JSON(http.StatusBadRequest, model.Error{
@@ -7,7 +7,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/keptn/keptn/secret-service/pkg/backend"
+	"github.com/keptn/keptn/secret-service/pkg/common"
 	"github.com/keptn/keptn/secret-service/pkg/model"
+	log "github.com/sirupsen/logrus"
 )
 
 type ISecretHandler interface {
@@ -15,16 +17,28 @@ type ISecretHandler interface {
 	UpdateSecret(c *gin.Context)
 	DeleteSecret(c *gin.Context)
 	GetSecrets(c *gin.Context)
+	GetSecretVersions(c *gin.Context)
+	RollbackSecret(c *gin.Context)
+	GetAuditLog(c *gin.Context)
 }
 
-func NewSecretHandler(backend backend.SecretManager) *SecretHandler {
+// envVarUserHeader is the name of the env var containing the header in which the authenticating proxy in front of the
+// secret-service passes the user. The proxy must overwrite this header, otherwise clients can set it themselves
+const envVarUserHeader = "AUTH_USER_HEADER"
+
+func NewSecretHandler(backend backend.SecretManager, history backend.SecretHistoryManager) *SecretHandler {
 	return &SecretHandler{
-		SecretManager: backend,
+		SecretManager:  backend,
+		HistoryManager: history,
+		UserHeader:     common.EnvBasedStringSupplier(envVarUserHeader, "")(),
 	}
 }
 
 type SecretHandler struct {
-	SecretManager backend.SecretManager
+	SecretManager  backend.SecretManager
+	HistoryManager backend.SecretHistoryManager
+	// UserHeader is the header containing the authenticated user, which is recorded as the caller in the audit log
+	UserHeader string
 }
 
 // CreateSecret godoc
@@ -63,6 +77,7 @@ func (s SecretHandler) CreateSecret(c *gin.Context) {
 		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrCreateSecretMsg, err.Error()))
 		return
 	}
+	s.recordChange(c, model.SecretOperationCreate, secret)
 
 	c.JSON(http.StatusCreated, secret)
 }
@@ -99,6 +114,8 @@ func (s SecretHandler) UpdateSecret(c *gin.Context) {
 		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrUpdateSecretMsg, err.Error()))
 		return
 	}
+	s.recordChange(c, model.SecretOperationUpdate, secret)
+
 	c.JSON(http.StatusOK, secret)
 
 }
@@ -141,6 +158,7 @@ func (s SecretHandler) DeleteSecret(c *gin.Context) {
 		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrDeleteSecretMsg, err.Error()))
 		return
 	}
+	s.recordChange(c, model.SecretOperationDelete, secret)
 
 	c.Status(http.StatusOK)
 
@@ -161,11 +179,155 @@ func (s SecretHandler) GetSecrets(c *gin.Context) {
 		return
 	}
 
+	for i := range secrets {
+		latestVersion, err := s.HistoryManager.GetLatestVersion(secrets[i].SecretMetadata)
+		if err != nil {
+			if !errors.Is(err, backend.ErrSecretNotFound) {
+				log.Warnf("Unable to retrieve latest version of secret %s: %s", secrets[i].Name, err.Error())
+			}
+			continue
+		}
+		secrets[i].Version = latestVersion.Version
+		secrets[i].ExpiresAt = latestVersion.ExpiresAt
+	}
+
 	c.Status(http.StatusOK)
 	c.JSON(http.StatusOK, model.GetSecretsResponse{Secrets: secrets})
 }
 
+// GetSecretVersions godoc
+// @Summary Get the versions of a secret
+// @Description Get the metadata of the stored versions of a secret. The values of the secret are not included
+// @Tags Secrets
+// @Security ApiKeyAuth
+// @Param name query string true "The name of the secret"
+// @Param scope query string true "The scope of the secret"
+// @Success 200 {object} model.GetSecretVersionsResponse
+// @Failure 400 {object} model.Error
+// @Failure 404 {object} model.Error
+// @Failure 500 {object} model.Error
+// @Router /secret/versions [get]
+func (s SecretHandler) GetSecretVersions(c *gin.Context) {
+	params := &SecretQueryParams{}
+	if err := c.ShouldBindQuery(params); err != nil {
+		SetBadRequestErrorResponse(c, fmt.Sprintf(ErrInvalidRequestFormatMsg, err.Error()))
+		return
+	}
+
+	secret := model.SecretMetadata{Name: params.Name, Scope: params.Scope}
+	versions, err := s.HistoryManager.GetSecretVersions(secret)
+	if err != nil {
+		if errors.Is(err, backend.ErrSecretNotFound) {
+			SetNotFoundErrorResponse(c, fmt.Sprintf(ErrGetSecretVersionsMsg, err.Error()))
+			return
+		}
+		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrGetSecretVersionsMsg, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.GetSecretVersionsResponse{SecretMetadata: secret, Versions: versions})
+}
+
+// RollbackSecret godoc
+// @Summary Roll back a Secret
+// @Description Roll back an existing Secret to a previous version. The rollback is stored as a new version of the secret
+// @Tags Secrets
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param rollback body model.RollbackSecretRequest true "The secret and the version to roll back to"
+// @Success 200 {object} model.SecretMetadata
+// @Failure 400 {object} model.Error
+// @Failure 404 {object} model.Error
+// @Failure 409 {object} model.Error
+// @Failure 500 {object} model.Error
+// @Router /secret/rollback [post]
+func (s SecretHandler) RollbackSecret(c *gin.Context) {
+	rollback := model.RollbackSecretRequest{}
+	if err := c.ShouldBindJSON(&rollback); err != nil {
+		SetBadRequestErrorResponse(c, fmt.Sprintf(ErrInvalidRequestFormatMsg, err.Error()))
+		return
+	}
+
+	if rollback.Scope == "" {
+		rollback.Scope = model.DefaultSecretScope
+	}
+
+	secret, err := s.HistoryManager.GetSecretVersion(rollback.SecretMetadata, rollback.Version)
+	if err != nil {
+		if errors.Is(err, backend.ErrSecretNotFound) || errors.Is(err, backend.ErrSecretVersionNotFound) {
+			SetNotFoundErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+			return
+		}
+		if errors.Is(err, backend.ErrSecretVersionValuesNotStored) {
+			SetConflictErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+			return
+		}
+		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+		return
+	}
+
+	if err := s.SecretManager.UpdateSecret(*secret); err != nil {
+		if errors.Is(err, backend.ErrSecretNotFound) {
+			SetNotFoundErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+			return
+		}
+		if errors.Is(err, backend.ErrScopeNotFound) {
+			SetBadRequestErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+			return
+		}
+		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrRollbackSecretMsg, err.Error()))
+		return
+	}
+	s.recordChange(c, model.SecretOperationRollback, *secret)
+
+	c.JSON(http.StatusOK, secret.SecretMetadata)
+}
+
+// GetAuditLog godoc
+// @Summary Get the audit log
+// @Description Get the create, update, delete and rollback operations performed on secrets, ordered from the latest to the oldest operation
+// @Tags Secrets
+// @Security ApiKeyAuth
+// @Param name query string false "The name of the secret"
+// @Success 200 {object} model.GetAuditLogResponse
+// @Failure 500 {object} model.Error
+// @Router /secret/audit [get]
+func (s SecretHandler) GetAuditLog(c *gin.Context) {
+	entries, err := s.HistoryManager.GetAuditLog(c.Query("name"))
+	if err != nil {
+		SetInternalServerErrorResponse(c, fmt.Sprintf(ErrGetAuditLogMsg, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.GetAuditLogResponse{Entries: entries})
+}
+
+// recordChange stores the change in the history of the secret. As the change has already been applied to the backend,
+// a failure is only logged
+func (s SecretHandler) recordChange(c *gin.Context, operation model.SecretOperation, secret model.Secret) {
+	if err := s.HistoryManager.RecordChange(operation, secret, s.getCaller(c)); err != nil {
+		log.Errorf("Unable to record %s of secret %s in secret history: %s", operation, secret.Name, err.Error())
+	}
+}
+
+// getCaller returns the user the authenticating proxy has passed in the UserHeader or, if the user is unknown,
+// the address of the client
+func (s SecretHandler) getCaller(c *gin.Context) string {
+	if s.UserHeader != "" {
+		if user := c.GetHeader(s.UserHeader); user != "" {
+			return user
+		}
+	}
+	return c.ClientIP()
+}
+
 type DeleteSecretQueryParams struct {
 	Name  string `form:"name" binding:"required"`
 	Scope string `form:"scope" binding:"required"`
 }
+
+type SecretQueryParams struct {
+	Name  string `form:"name" binding:"required"`
+	Scope string `form:"scope" binding:"required"`
+}
@@ -18,7 +18,7 @@ import (
 
 func Test_CreateNewHandler(t *testing.T) {
 	secretsBackend := fake.SecretBackendMock{}
-	secretsHandler := handler.NewSecretHandler(&secretsBackend)
+	secretsHandler := handler.NewSecretHandler(&secretsBackend, &fake.SecretHistoryManagerMock{})
 	assert.NotNil(t, secretsHandler)
 }
 
@@ -119,7 +119,7 @@ func TestHandler_CreateSecret(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			secretsHandler := handler.NewSecretHandler(tt.fields.Backend)
+			secretsHandler := handler.NewSecretHandler(tt.fields.Backend, newHistoryManagerMock())
 			handler := func(w http.ResponseWriter, r *http.Request) {
 				c, _ := gin.CreateTestContext(w)
 				c.Request = r
@@ -213,7 +213,7 @@ func TestHandler_DeleteSecret(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			secretsHandler := handler.NewSecretHandler(tt.fields.Backend)
+			secretsHandler := handler.NewSecretHandler(tt.fields.Backend, newHistoryManagerMock())
 			handler := func(w http.ResponseWriter, r *http.Request) {
 				c, _ := gin.CreateTestContext(w)
 				c.Request = r
@@ -307,7 +307,7 @@ func TestHandler_Update(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			secretsHandler := handler.NewSecretHandler(tt.fields.Backend)
+			secretsHandler := handler.NewSecretHandler(tt.fields.Backend, newHistoryManagerMock())
 			handler := func(w http.ResponseWriter, r *http.Request) {
 				c, _ := gin.CreateTestContext(w)
 				c.Request = r
@@ -363,7 +363,7 @@ func TestHandler_GetSecrets(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			secretsHandler := handler.NewSecretHandler(tt.fields.Backend)
+			secretsHandler := handler.NewSecretHandler(tt.fields.Backend, newHistoryManagerMock())
 			handler := func(w http.ResponseWriter, r *http.Request) {
 				c, _ := gin.CreateTestContext(w)
 				c.Request = r
@@ -379,3 +379,220 @@ func TestHandler_GetSecrets(t *testing.T) {
 		})
 	}
 }
+
+func newHistoryManagerMock() *fake.SecretHistoryManagerMock {
+	return &fake.SecretHistoryManagerMock{
+		RecordChangeFunc: func(operation model.SecretOperation, secret model.Secret, caller string) error {
+			return nil
+		},
+		GetLatestVersionFunc: func(secret model.SecretMetadata) (*model.SecretVersion, error) {
+			return nil, backend.ErrSecretNotFound
+		},
+	}
+}
+
+func TestHandler_RecordsChanges(t *testing.T) {
+	secretsBackend := &fake.SecretBackendMock{
+		CreateSecretFunc: func(secret model.Secret) error { return nil },
+		UpdateSecretFunc: func(secret model.Secret) error { return nil },
+		DeleteSecretFunc: func(secret model.Secret) error { return nil },
+	}
+	historyManager := newHistoryManagerMock()
+	secretsHandler := handler.NewSecretHandler(secretsBackend, historyManager)
+	secretsHandler.UserHeader = "X-Forwarded-User"
+
+	requests := []struct {
+		request *http.Request
+		handle  func(c *gin.Context)
+	}{
+		{
+			request: httptest.NewRequest("POST", "/secret", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","data":{"username":"keptn"}}`))),
+			handle:  secretsHandler.CreateSecret,
+		},
+		{
+			request: httptest.NewRequest("PUT", "/secret", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","data":{"username":"keptn"},"expiresAt":"2022-05-01T00:00:00Z"}`))),
+			handle:  secretsHandler.UpdateSecret,
+		},
+		{
+			request: httptest.NewRequest("DELETE", "/secret?name=my-secret&scope=my-scope", nil),
+			handle:  secretsHandler.DeleteSecret,
+		},
+	}
+	requests[0].request.Header.Set("X-Forwarded-User", "jane")
+	requests[1].request.Header.Set("X-Forwarded-User", "jane")
+	for _, r := range requests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = r.request
+		r.handle(c)
+	}
+
+	calls := historyManager.RecordChangeCalls()
+	assert.Len(t, calls, 3)
+	assert.Equal(t, model.SecretOperationCreate, calls[0].Operation)
+	assert.Equal(t, model.SecretOperationUpdate, calls[1].Operation)
+	assert.NotNil(t, calls[1].Secret.ExpiresAt)
+	assert.Equal(t, model.SecretOperationDelete, calls[2].Operation)
+	assert.Equal(t, "jane", calls[0].Caller)
+	assert.Equal(t, "jane", calls[1].Caller)
+	// without the header of the authenticating proxy, the address of the client is recorded
+	assert.Equal(t, "192.0.2.1", calls[2].Caller)
+	for _, call := range calls {
+		assert.Equal(t, "my-secret", call.Secret.Name)
+	}
+}
+
+func TestHandler_GetSecretVersions(t *testing.T) {
+	tests := []struct {
+		name               string
+		historyManager     *fake.SecretHistoryManagerMock
+		request            *http.Request
+		expectedHTTPStatus int
+	}{
+		{
+			name: "GET Secret versions - SUCCESS",
+			historyManager: &fake.SecretHistoryManagerMock{
+				GetSecretVersionsFunc: func(secret model.SecretMetadata) ([]model.SecretVersion, error) {
+					return []model.SecretVersion{{Version: 2}, {Version: 1}}, nil
+				},
+			},
+			request:            httptest.NewRequest("GET", "/secret/versions?name=my-secret&scope=my-scope", nil),
+			expectedHTTPStatus: http.StatusOK,
+		},
+		{
+			name:               "GET Secret versions - missing parameters",
+			historyManager:     &fake.SecretHistoryManagerMock{},
+			request:            httptest.NewRequest("GET", "/secret/versions?name=my-secret", nil),
+			expectedHTTPStatus: http.StatusBadRequest,
+		},
+		{
+			name: "GET Secret versions - Secret not found",
+			historyManager: &fake.SecretHistoryManagerMock{
+				GetSecretVersionsFunc: func(secret model.SecretMetadata) ([]model.SecretVersion, error) {
+					return nil, backend.ErrSecretNotFound
+				},
+			},
+			request:            httptest.NewRequest("GET", "/secret/versions?name=my-secret&scope=my-scope", nil),
+			expectedHTTPStatus: http.StatusNotFound,
+		},
+		{
+			name: "GET Secret versions - some error",
+			historyManager: &fake.SecretHistoryManagerMock{
+				GetSecretVersionsFunc: func(secret model.SecretMetadata) ([]model.SecretVersion, error) {
+					return nil, errors.New("oops")
+				},
+			},
+			request:            httptest.NewRequest("GET", "/secret/versions?name=my-secret&scope=my-scope", nil),
+			expectedHTTPStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretsHandler := handler.NewSecretHandler(&fake.SecretBackendMock{}, tt.historyManager)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = tt.request
+			secretsHandler.GetSecretVersions(c)
+
+			assert.Equal(t, tt.expectedHTTPStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestHandler_RollbackSecret(t *testing.T) {
+	previousVersion := func(secret model.SecretMetadata, version int) (*model.Secret, error) {
+		if version == 2 {
+			return nil, backend.ErrSecretVersionValuesNotStored
+		}
+		if version != 1 {
+			return nil, backend.ErrSecretVersionNotFound
+		}
+		return &model.Secret{SecretMetadata: secret, Data: map[string]string{"username": "old"}}, nil
+	}
+
+	tests := []struct {
+		name               string
+		backend            *fake.SecretBackendMock
+		request            *http.Request
+		expectedHTTPStatus int
+		expectedRecords    int
+	}{
+		{
+			name: "POST Rollback Secret - SUCCESS",
+			backend: &fake.SecretBackendMock{
+				UpdateSecretFunc: func(secret model.Secret) error {
+					if secret.Data["username"] != "old" {
+						return errors.New("unexpected data")
+					}
+					return nil
+				},
+			},
+			request:            httptest.NewRequest("POST", "/secret/rollback", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","version":1}`))),
+			expectedHTTPStatus: http.StatusOK,
+			expectedRecords:    1,
+		},
+		{
+			name:               "POST Rollback Secret - Version not found",
+			backend:            &fake.SecretBackendMock{},
+			request:            httptest.NewRequest("POST", "/secret/rollback", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","version":5}`))),
+			expectedHTTPStatus: http.StatusNotFound,
+		},
+		{
+			name:               "POST Rollback Secret - Values of version not stored",
+			backend:            &fake.SecretBackendMock{},
+			request:            httptest.NewRequest("POST", "/secret/rollback", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","version":2}`))),
+			expectedHTTPStatus: http.StatusConflict,
+		},
+		{
+			name:               "POST Rollback Secret - Missing version",
+			backend:            &fake.SecretBackendMock{},
+			request:            httptest.NewRequest("POST", "/secret/rollback", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope"}`))),
+			expectedHTTPStatus: http.StatusBadRequest,
+		},
+		{
+			name: "POST Rollback Secret - Secret has been deleted",
+			backend: &fake.SecretBackendMock{
+				UpdateSecretFunc: func(secret model.Secret) error { return backend.ErrSecretNotFound },
+			},
+			request:            httptest.NewRequest("POST", "/secret/rollback", bytes.NewBuffer([]byte(`{"name":"my-secret","scope":"my-scope","version":1}`))),
+			expectedHTTPStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			historyManager := newHistoryManagerMock()
+			historyManager.GetSecretVersionFunc = previousVersion
+			secretsHandler := handler.NewSecretHandler(tt.backend, historyManager)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = tt.request
+			secretsHandler.RollbackSecret(c)
+
+			assert.Equal(t, tt.expectedHTTPStatus, w.Result().StatusCode)
+			assert.Len(t, historyManager.RecordChangeCalls(), tt.expectedRecords)
+			if tt.expectedRecords > 0 {
+				assert.Equal(t, model.SecretOperationRollback, historyManager.RecordChangeCalls()[0].Operation)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAuditLog(t *testing.T) {
+	historyManager := &fake.SecretHistoryManagerMock{
+		GetAuditLogFunc: func(name string) ([]model.AuditLogEntry, error) {
+			return []model.AuditLogEntry{{Name: name, Operation: model.SecretOperationCreate}}, nil
+		},
+	}
+	secretsHandler := handler.NewSecretHandler(&fake.SecretBackendMock{}, historyManager)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/secret/audit?name=my-secret", nil)
+	secretsHandler.GetAuditLog(c)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "my-secret", historyManager.GetAuditLogCalls()[0].Name)
+	assert.JSONEq(t, `{"entries":[{"time":"0001-01-01T00:00:00Z","operation":"create","name":"my-secret","scope":""}]}`, w.Body.String())
+}
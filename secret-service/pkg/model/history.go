@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+type SecretOperation string
+
+const (
+	SecretOperationCreate   SecretOperation = "create"
+	SecretOperationUpdate   SecretOperation = "update"
+	SecretOperationDelete   SecretOperation = "delete"
+	SecretOperationRollback SecretOperation = "rollback"
+)
+
+// SecretVersion contains the metadata of a single version of a secret
+type SecretVersion struct {
+	Version   int             `json:"version"`
+	Operation SecretOperation `json:"operation"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Keys      []string        `json:"keys"`
+	// ExpiresAt is the point in time at which the secret is due for rotation
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// StoredSecretVersion is a version of a secret as it is kept in the history. It is only used internally and never returned by the API.
+// Data only contains the values of the secret if keeping them for rollbacks has been enabled, otherwise only
+// the SHA-256 hashes of the values are stored in Hashes
+type StoredSecretVersion struct {
+	SecretVersion
+	Data   Data              `json:"data,omitempty"`
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+// SecretHistory contains the bounded list of versions of a secret, ordered from the oldest to the latest version
+type SecretHistory struct {
+	SecretMetadata
+	Versions []StoredSecretVersion `json:"versions"`
+}
+
+type GetSecretVersionsResponse struct {
+	SecretMetadata
+	Versions []SecretVersion `json:"versions"`
+}
+
+type RollbackSecretRequest struct {
+	SecretMetadata
+	// Version is the version of the secret the secret should be rolled back to
+	Version int `json:"version" binding:"required"`
+}
+
+// AuditLogEntry describes an operation that has been performed on a secret
+type AuditLogEntry struct {
+	Time      time.Time       `json:"time"`
+	Operation SecretOperation `json:"operation"`
+	Name      string          `json:"name"`
+	Scope     string          `json:"scope"`
+	Version   int             `json:"version,omitempty"`
+	// Caller is the authenticated user who performed the operation or, if the user is unknown, the address of the client
+	Caller string `json:"caller,omitempty"`
+}
+
+type GetAuditLogResponse struct {
+	Entries []AuditLogEntry `json:"entries"`
+}
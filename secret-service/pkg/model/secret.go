@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 const DefaultSecretScope = "keptn-default"
 
 // Secret secret
@@ -7,6 +9,8 @@ const DefaultSecretScope = "keptn-default"
 type Secret struct {
 	SecretMetadata
 	Data Data `json:"data"`
+	// ExpiresAt optionally defines the point in time at which the secret is due for rotation
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type Data map[string]string
@@ -20,6 +24,10 @@ type SecretMetadata struct {
 type GetSecretResponseItem struct {
 	SecretMetadata
 	Keys []string `json:"keys"`
+	// Version is the current version of the secret
+	Version int `json:"version,omitempty"`
+	// ExpiresAt is the point in time at which the secret is due for rotation
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 type GetSecretsResponse struct {
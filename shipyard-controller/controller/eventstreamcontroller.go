@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keptn/keptn/shipyard-controller/handler"
+)
+
+type EventStreamController struct {
+	EventStreamHandler handler.IEventStreamHandler
+}
+
+func NewEventStreamController(eventStreamHandler handler.IEventStreamHandler) Controller {
+	return &EventStreamController{EventStreamHandler: eventStreamHandler}
+}
+
+func (controller EventStreamController) Inject(apiGroup *gin.RouterGroup) {
+	apiGroup.GET("/uniform/registration/:integrationID/stream", controller.EventStreamHandler.Stream)
+}
@@ -11,6 +11,7 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/jeremywohl/flatten v1.0.1
 	github.com/keptn/go-utils v0.14.1-0.20220414081235-2e23eb712e3d
+	github.com/keptn/keptn/cp-connector v0.0.0-00010101000000-000000000000
 	github.com/mitchellh/copystructure v1.2.0
 	github.com/nats-io/nats-server/v2 v2.7.4
 	github.com/nats-io/nats.go v1.14.0
@@ -27,7 +28,10 @@ require (
 	k8s.io/client-go v0.22.8
 )
 
-require github.com/kelseyhightower/envconfig v1.4.0
+require (
+	github.com/gorilla/websocket v1.4.2
+	github.com/kelseyhightower/envconfig v1.4.0
+)
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
@@ -106,3 +110,5 @@ require (
 	sigs.k8s.io/structured-merge-diff/v4 v4.2.1 // indirect
 	sigs.k8s.io/yaml v1.2.0 // indirect
 )
+
+replace github.com/keptn/keptn/cp-connector => ../cp-connector
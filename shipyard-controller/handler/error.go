@@ -31,6 +31,8 @@ var ErrNoMatchingEvent = errors.New("no matching event found")
 
 var ErrSequenceNotFound = errors.New("sequence not found")
 
+var ErrApprovalRequiresQuorum = errors.New("approval is decided by the votes of a quorum")
+
 var ErrInternalError = errors.New("internal server error")
 
 var InvalidRequestFormatMsg = "Invalid request format: %s"
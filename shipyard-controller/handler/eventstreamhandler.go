@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
+	"github.com/keptn/keptn/shipyard-controller/db"
+	"github.com/keptn/keptn/shipyard-controller/models"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	eventStreamBufferSize           = 100
+	eventStreamPingInterval         = 30 * time.Second
+	eventStreamPongWait             = 2 * eventStreamPingInterval
+	eventStreamWriteWait            = 10 * time.Second
+	eventStreamSubscriptionInterval = 10 * time.Second
+)
+
+type IEventStreamHandler interface {
+	Stream(context *gin.Context)
+}
+
+// EventStreamHandler pushes .triggered events to Keptn integrations which are connected via WebSocket.
+// Every integration only receives the events matching its subscriptions. All event streams are closed when the
+// context is done
+type EventStreamHandler struct {
+	ctx         context.Context
+	uniformRepo db.UniformRepo
+	upgrader    websocket.Upgrader
+	mutex       sync.RWMutex
+	clients     map[*eventStreamClient]bool
+}
+
+type eventStreamClient struct {
+	integrationID string
+	events        chan apimodels.KeptnContextExtendedCE
+}
+
+func NewEventStreamHandler(ctx context.Context, uniformRepo db.UniformRepo) *EventStreamHandler {
+	return &EventStreamHandler{
+		ctx:         ctx,
+		uniformRepo: uniformRepo,
+		upgrader:    websocket.Upgrader{},
+		clients:     map[*eventStreamClient]bool{},
+	}
+}
+
+// Stream opens a WebSocket connection pushing the .triggered events matching the subscriptions of the integration
+// @Summary BETA: Stream the .triggered events of a uniform integration
+// @Description Opens a WebSocket connection pushing the .triggered events matching the subscriptions of the integration
+// @Tags Uniform
+// @Security ApiKeyAuth
+// @Param integrationID path string true "integrationID"
+// @Success 101 {object} models.StreamedEvent "ok: the connection has been upgraded to WebSocket"
+// @Failure 400 {object} models.Error "Invalid request"
+// @Failure 404 {object} models.Error "Not found"
+// @Failure 500 {object} models.Error "Internal error"
+// @Router /uniform/registration/{integrationID}/stream [get]
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	integrationID := c.Param("integrationID")
+
+	subscriptions, err := h.uniformRepo.GetSubscriptions(integrationID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			SetNotFoundErrorResponse(c, err.Error())
+			return
+		}
+		SetInternalServerErrorResponse(c, err.Error())
+		return
+	}
+
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		SetBadRequestErrorResponse(c, "the event stream requires a WebSocket connection")
+		return
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already replied with an error
+		log.WithError(err).Errorf("could not open event stream for integration %s", integrationID)
+		return
+	}
+
+	client := &eventStreamClient{
+		integrationID: integrationID,
+		events:        make(chan apimodels.KeptnContextExtendedCE, eventStreamBufferSize),
+	}
+	h.addClient(client)
+	defer h.removeClient(client)
+
+	log.Infof("Opened event stream for integration %s", integrationID)
+	closed := make(chan struct{})
+	go readUntilClosed(conn, closed)
+	h.writeEvents(conn, client, subscriptions, closed)
+	log.Infof("Closed event stream for integration %s", integrationID)
+}
+
+// Publish pushes the event to all connected integrations with a matching subscription. Events which are not
+// .triggered events are ignored
+func (h *EventStreamHandler) Publish(event apimodels.KeptnContextExtendedCE) {
+	if event.Type == nil || !keptnv2.IsTriggeredEventType(*event.Type) {
+		return
+	}
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for client := range h.clients {
+		select {
+		case client.events <- event:
+		default:
+			// the integration picks up the event the next time it fetches the open .triggered events
+			log.Warnf("Event stream of integration %s is full, dropping event with ID %s", client.integrationID, event.ID)
+		}
+	}
+}
+
+func (h *EventStreamHandler) addClient(client *eventStreamClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.clients[client] = true
+}
+
+func (h *EventStreamHandler) removeClient(client *eventStreamClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.clients, client)
+}
+
+func (h *EventStreamHandler) writeEvents(conn *websocket.Conn, client *eventStreamClient, subscriptions []apimodels.EventSubscription, closed chan struct{}) {
+	defer conn.Close()
+	pingTicker := time.NewTicker(eventStreamPingInterval)
+	defer pingTicker.Stop()
+	subscriptionTicker := time.NewTicker(eventStreamSubscriptionInterval)
+	defer subscriptionTicker.Stop()
+
+	for {
+		select {
+		case event := <-client.events:
+			for _, subscription := range subscriptions {
+				if !subscriptionMatches(subscription, event) {
+					continue
+				}
+				_ = conn.SetWriteDeadline(time.Now().Add(eventStreamWriteWait))
+				if err := conn.WriteJSON(models.StreamedEvent{SubscriptionID: subscription.ID, Event: event}); err != nil {
+					log.WithError(err).Errorf("could not push event with ID %s to integration %s", event.ID, client.integrationID)
+					return
+				}
+			}
+		case <-subscriptionTicker.C:
+			updatedSubscriptions, err := h.uniformRepo.GetSubscriptions(client.integrationID)
+			if err != nil {
+				log.WithError(err).Errorf("could not update subscriptions of integration %s", client.integrationID)
+				continue
+			}
+			subscriptions = updatedSubscriptions
+		case <-pingTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventStreamWriteWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-h.ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down"), time.Now().Add(eventStreamWriteWait))
+			return
+		}
+	}
+}
+
+// readUntilClosed processes the control messages of the connection and closes the channel once the connection is closed
+func readUntilClosed(conn *websocket.Conn, closed chan struct{}) {
+	defer close(closed)
+	_ = conn.SetReadDeadline(time.Now().Add(eventStreamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventStreamPongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// subscriptionMatches checks whether the event matches the event type and the filter of the subscription
+func subscriptionMatches(subscription apimodels.EventSubscription, event apimodels.KeptnContextExtendedCE) bool {
+	if event.Type == nil || !subjectMatches(subscription.Event, *event.Type) {
+		return false
+	}
+	eventData := &keptnv2.EventData{}
+	if err := event.DataAs(eventData); err != nil {
+		return false
+	}
+	return filter.MatchesValues(subscription.Filter.Projects, eventData.Project) &&
+		filter.MatchesValues(subscription.Filter.Stages, eventData.Stage) &&
+		filter.MatchesValues(subscription.Filter.Services, eventData.Service)
+}
+
+// subjectMatches checks whether the event type matches the subject of a subscription, which may contain the
+// NATS wildcards '*' and '>'
+func subjectMatches(subject string, eventType string) bool {
+	subjectTokens := strings.Split(subject, ".")
+	typeTokens := strings.Split(eventType, ".")
+	for i, token := range subjectTokens {
+		if token == ">" {
+			return len(typeTokens) > i
+		}
+		if i >= len(typeTokens) || token != "*" && token != typeTokens[i] {
+			return false
+		}
+	}
+	return len(subjectTokens) == len(typeTokens)
+}
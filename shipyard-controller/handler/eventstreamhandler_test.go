@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/go-utils/pkg/common/strutils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	db_mock "github.com/keptn/keptn/shipyard-controller/db/mock"
+	"github.com/keptn/keptn/shipyard-controller/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newStreamedTestEvent(id string, eventType string, project string) apimodels.KeptnContextExtendedCE {
+	return apimodels.KeptnContextExtendedCE{
+		ID:   id,
+		Type: strutils.Stringp(eventType),
+		Data: keptnv2.EventData{Project: project, Stage: "dev", Service: "carts"},
+	}
+}
+
+func TestEventStreamHandler_Stream(t *testing.T) {
+	uniformRepo := &db_mock.UniformRepoMock{
+		GetSubscriptionsFunc: func(integrationID string) ([]apimodels.EventSubscription, error) {
+			if integrationID != "my-integration" {
+				return nil, mongo.ErrNoDocuments
+			}
+			return []apimodels.EventSubscription{
+				{ID: "sub-1", Event: "sh.keptn.event.deployment.triggered", Filter: apimodels.EventSubscriptionFilter{Projects: []string{"!podtato-head"}}},
+				{ID: "sub-2", Event: "sh.keptn.event.*.triggered", Filter: apimodels.EventSubscriptionFilter{Projects: []string{"sockshop"}}},
+			}, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewEventStreamHandler(ctx, uniformRepo)
+
+	router := gin.New()
+	router.GET("/uniform/registration/:integrationID/stream", h.Stream)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/uniform/registration/"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"unknown/stream", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/uniform/registration/my-integration/stream")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"my-integration/stream", nil)
+	require.Nil(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool {
+		h.mutex.RLock()
+		defer h.mutex.RUnlock()
+		return len(h.clients) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	h.Publish(newStreamedTestEvent("id-1", "sh.keptn.event.deployment.started", "sockshop"))
+	h.Publish(newStreamedTestEvent("id-2", "sh.keptn.event.deployment.triggered", "podtato-head"))
+	h.Publish(newStreamedTestEvent("id-3", "sh.keptn.event.test.triggered", "sockshop"))
+
+	streamedEvent := models.StreamedEvent{}
+	require.Nil(t, conn.ReadJSON(&streamedEvent))
+	assert.Equal(t, "sub-2", streamedEvent.SubscriptionID)
+	assert.Equal(t, "id-3", streamedEvent.Event.ID)
+
+	cancel()
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	require.Eventually(t, func() bool {
+		h.mutex.RLock()
+		defer h.mutex.RUnlock()
+		return len(h.clients) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_subjectMatches(t *testing.T) {
+	assert.True(t, subjectMatches("sh.keptn.event.deployment.triggered", "sh.keptn.event.deployment.triggered"))
+	assert.True(t, subjectMatches("sh.keptn.event.*.triggered", "sh.keptn.event.deployment.triggered"))
+	assert.True(t, subjectMatches("sh.keptn.>", "sh.keptn.event.dev.delivery.triggered"))
+	assert.False(t, subjectMatches("sh.keptn.event.*.triggered", "sh.keptn.event.dev.delivery.triggered"))
+	assert.False(t, subjectMatches("sh.keptn.event.deployment.triggered", "sh.keptn.event.test.triggered"))
+	assert.False(t, subjectMatches("sh.keptn.event.deployment", "sh.keptn.event.deployment.triggered"))
+	assert.False(t, subjectMatches("sh.keptn.event.>", "sh.keptn.event"))
+}
@@ -19,6 +19,9 @@ import (
 
 const maxRepoReadRetries = 5
 
+// userLabel is the label in which the Keptn API passes the user who sent an event
+const userLabel = "triggeredBy"
+
 const couldNotGetActiveSequencesErrMsg = "unable to get active task executions for project %s in stage %s for Keptn context %s: %w"
 const noActiveSequencesErrMsg = "no active task executions for project %s in stage %s for Keptn context %s found"
 
@@ -167,7 +170,7 @@ func (sc *shipyardController) HandleIncomingEvent(event apimodels.KeptnContextEx
 		go func() {
 			err := sc.handleTaskEvent(event)
 			if err != nil {
-				if errors.Is(err, ErrSequenceNotFound) || errors.Is(err, models.ErrInvalidEventScope) {
+				if errors.Is(err, ErrSequenceNotFound) || errors.Is(err, models.ErrInvalidEventScope) || errors.Is(err, ErrApprovalRequiresQuorum) {
 					log.Infof("Unable to handle task event: %v", err)
 				} else {
 					log.Errorf("Unable to handle task event: %v", err)
@@ -309,6 +312,12 @@ func (sc *shipyardController) handleTaskEvent(event apimodels.KeptnContextExtend
 		return ErrSequenceNotFound
 	}
 
+	if eventScope.EventType == keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName) {
+		if err := validateApprovalFinished(*eventScope, sequenceExecution.Status.CurrentTask); err != nil {
+			return err
+		}
+	}
+
 	if keptnv2.IsStartedEventType(*event.Type) {
 		sc.onSequenceTaskStarted(eventScope.WrappedEvent)
 	}
@@ -316,6 +325,31 @@ func (sc *shipyardController) handleTaskEvent(event apimodels.KeptnContextExtend
 	return sc.onTaskProgress(event, *sequenceExecution, eventScope)
 }
 
+// approvalStartedEventData contains the properties of an approval.started event marking an approval which requires a quorum
+type approvalStartedEventData struct {
+	Quorum struct {
+		Required bool `json:"required"`
+	} `json:"quorum"`
+}
+
+// validateApprovalFinished rejects approval.finished events for approvals requiring a quorum, unless they have been sent
+// by the service collecting the votes, i.e. the source of the approval.started event. Since the Keptn API sets the
+// label triggeredBy for events sent by users, such events are rejected as well
+func validateApprovalFinished(eventScope models.EventScope, task models.TaskExecutionState) error {
+	for _, taskEvent := range task.Events {
+		if !taskEvent.RequiresQuorum {
+			continue
+		}
+		if taskEvent.Source != eventScope.EventSource {
+			return fmt.Errorf("%w: ignoring approval.finished event sent by %s", ErrApprovalRequiresQuorum, eventScope.EventSource)
+		}
+		if user := eventScope.Labels[userLabel]; user != "" {
+			return fmt.Errorf("%w: ignoring approval.finished event sent by user %s", ErrApprovalRequiresQuorum, user)
+		}
+	}
+	return nil
+}
+
 func (sc *shipyardController) onTaskProgress(event apimodels.KeptnContextExtendedCE, sequenceExecution models.SequenceExecution, eventScope *models.EventScope) error {
 	taskEvent := models.TaskEvent{
 		EventType: *event.Type,
@@ -332,6 +366,12 @@ func (sc *shipyardController) onTaskProgress(event apimodels.KeptnContextExtende
 		}
 		taskEvent.Properties = eventData
 	}
+	if taskEvent.EventType == keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName) {
+		eventData := approvalStartedEventData{}
+		if err := keptnv2.Decode(event.Data, &eventData); err == nil {
+			taskEvent.RequiresQuorum = eventData.Quorum.Required
+		}
+	}
 	updatedSequenceExecution, err := sc.sequenceExecutionRepo.AppendTaskEvent(sequenceExecution, taskEvent)
 	if err != nil {
 		return err
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/keptn/keptn/shipyard-controller/common"
 	"github.com/keptn/keptn/shipyard-controller/db"
 	db_mock "github.com/keptn/keptn/shipyard-controller/db/mock"
@@ -205,3 +206,105 @@ func TestHandleTaskEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleTaskEvent_ApprovalRequiringQuorum(t *testing.T) {
+	approvalFinishedEvent := func(source string, labels map[string]string) apimodels.KeptnContextExtendedCE {
+		return apimodels.KeptnContextExtendedCE{
+			Data:           keptnv2.ApprovalFinishedEventData{EventData: keptnv2.EventData{Project: "test-project", Stage: "dev", Service: "carts", Labels: labels, Result: keptnv2.ResultPass, Status: keptnv2.StatusSucceeded}},
+			ID:             "approval-finished-id",
+			Shkeptncontext: "test-context",
+			Source:         common.Stringp(source),
+			Triggeredid:    "approval-triggered-id",
+			Type:           common.Stringp(keptnv2.GetFinishedEventType(keptnv2.ApprovalTaskName)),
+		}
+	}
+	tests := []struct {
+		name       string
+		event      apimodels.KeptnContextExtendedCE
+		wantErr    error
+		wantAppend bool
+	}{
+		{
+			name:    "approval.finished sent by another service is rejected",
+			event:   approvalFinishedEvent("bridge", nil),
+			wantErr: ErrApprovalRequiresQuorum,
+		},
+		{
+			name:    "approval.finished sent by a user is rejected",
+			event:   approvalFinishedEvent("approval-service", map[string]string{userLabel: "admin"}),
+			wantErr: ErrApprovalRequiresQuorum,
+		},
+		{
+			name:       "approval.finished sent by the service collecting the votes is accepted",
+			event:      approvalFinishedEvent("approval-service", nil),
+			wantAppend: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequenceExecutionRepo := &db_mock.SequenceExecutionRepoMock{
+				GetFunc: func(filter models.SequenceExecutionFilter) ([]models.SequenceExecution, error) {
+					return []models.SequenceExecution{
+						{
+							Status: models.SequenceExecutionStatus{
+								CurrentTask: models.TaskExecutionState{
+									Name:        keptnv2.ApprovalTaskName,
+									TriggeredID: "approval-triggered-id",
+									Events: []models.TaskEvent{
+										{EventType: keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName), Source: "approval-service", RequiresQuorum: true},
+									},
+								},
+							},
+						},
+					}, nil
+				},
+				AppendTaskEventFunc: func(taskSequence models.SequenceExecution, event models.TaskEvent) (*models.SequenceExecution, error) {
+					return &taskSequence, nil
+				},
+			}
+			em := &shipyardController{sequenceExecutionRepo: sequenceExecutionRepo}
+
+			err := em.handleTaskEvent(tt.event)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantAppend {
+				require.Len(t, sequenceExecutionRepo.AppendTaskEventCalls(), 1)
+			} else {
+				require.Empty(t, sequenceExecutionRepo.AppendTaskEventCalls())
+			}
+		})
+	}
+}
+
+func TestHandleTaskEvent_ApprovalStartedRequiringQuorum(t *testing.T) {
+	sequenceExecutionRepo := &db_mock.SequenceExecutionRepoMock{
+		GetFunc: func(filter models.SequenceExecutionFilter) ([]models.SequenceExecution, error) {
+			return []models.SequenceExecution{{Status: models.SequenceExecutionStatus{CurrentTask: models.TaskExecutionState{Name: keptnv2.ApprovalTaskName}}}}, nil
+		},
+		AppendTaskEventFunc: func(taskSequence models.SequenceExecution, event models.TaskEvent) (*models.SequenceExecution, error) {
+			return &taskSequence, nil
+		},
+	}
+	em := &shipyardController{sequenceExecutionRepo: sequenceExecutionRepo}
+	em.AddSequenceTaskStartedHook(&fakehooks.ISequenceTaskStartedHookMock{OnSequenceTaskStartedFunc: func(event apimodels.KeptnContextExtendedCE) {}})
+
+	err := em.handleTaskEvent(apimodels.KeptnContextExtendedCE{
+		Data: map[string]interface{}{
+			"project": "test-project",
+			"stage":   "dev",
+			"service": "carts",
+			"quorum":  map[string]interface{}{"required": true},
+		},
+		ID:             "approval-started-id",
+		Shkeptncontext: "test-context",
+		Source:         common.Stringp("approval-service"),
+		Triggeredid:    "approval-triggered-id",
+		Type:           common.Stringp(keptnv2.GetStartedEventType(keptnv2.ApprovalTaskName)),
+	})
+	require.NoError(t, err)
+	require.Len(t, sequenceExecutionRepo.AppendTaskEventCalls(), 1)
+	require.True(t, sequenceExecutionRepo.AppendTaskEventCalls()[0].Event.RequiresQuorum)
+}
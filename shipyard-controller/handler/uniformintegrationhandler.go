@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	"github.com/keptn/keptn/cp-connector/pkg/filter"
 	"github.com/keptn/keptn/shipyard-controller/db"
 	"github.com/keptn/keptn/shipyard-controller/models"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -85,19 +86,44 @@ func (u UniformParamsValidator) validateFilterParams(params apimodels.EventSubsc
 
 	// Since empty project stands for all projects, we cannot impose a project in the validation
 
+	// Projects, stages and services prefixed with '!' are excluded
+	for filterName, values := range map[string][]string{"project": params.Projects, "stage": params.Stages, "service": params.Services} {
+		if err := validateFilterList(filterName, values); err != nil {
+			return err
+		}
+	}
+
 	// If the service is specified then also the stage should be
-	if params.Services != nil && params.Stages == nil {
+	if len(filter.IncludedValues(params.Services)) > 0 && params.Stages == nil {
 		return fmt.Errorf("at least one stage must be specified when setting up a subscription filter for a service")
 	}
 
 	//if the service is the webhook it should not be able to apply for all projects
-	if checkProject && (params.Projects == nil || len(params.Projects) > 1) {
+	if checkProject && len(filter.IncludedValues(params.Projects)) != 1 {
 		return fmt.Errorf("webhook should refer to exactly one project")
 	}
 
 	return nil
 }
 
+func validateFilterList(filterName string, values []string) error {
+	excluded := map[string]bool{}
+	for _, value := range values {
+		if strings.HasPrefix(value, filter.ExclusionPrefix) {
+			excluded[strings.TrimPrefix(value, filter.ExclusionPrefix)] = true
+		}
+	}
+	for _, value := range values {
+		if strings.TrimPrefix(value, filter.ExclusionPrefix) == "" {
+			return fmt.Errorf("the %s filter of a subscription must not contain empty values", filterName)
+		}
+		if excluded[value] {
+			return fmt.Errorf("the %s %s must not be included and excluded in the filter of a subscription", filterName, value)
+		}
+	}
+	return nil
+}
+
 // Register creates or updates a uniform integration
 // @Summary BETA: Register a uniform integration
 // @Description Register a uniform integration
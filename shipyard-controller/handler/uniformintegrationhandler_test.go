@@ -520,6 +520,63 @@ func TestUniformParamsValidator_Validate(t *testing.T) {
 			wantErr:      errors.New("at least one stage must be specified when setting up a subscription filter for a service"),
 			checkProject: false,
 		},
+		{
+			name: "test service - excluded service without stage",
+			params: apimodels.EventSubscription{
+				Event: "started.test.whatever",
+				Filter: apimodels.EventSubscriptionFilter{
+					Projects: []string{"!demo"},
+					Services: []string{"!my-service"},
+				},
+			},
+			wantErr:      nil,
+			checkProject: false,
+		},
+		{
+			name: "test webhook subscription - one project with exclusions",
+			params: apimodels.EventSubscription{
+				Event: "started.test.whatever",
+				Filter: apimodels.EventSubscriptionFilter{
+					Projects: []string{"demo"},
+					Stages:   []string{"!production"},
+				},
+			},
+			wantErr:      nil,
+			checkProject: true,
+		},
+		{
+			name: "test error webhook subscription - only excluded projects",
+			params: apimodels.EventSubscription{
+				Event: "started.test.whatever",
+				Filter: apimodels.EventSubscriptionFilter{
+					Projects: []string{"!demo"},
+				},
+			},
+			wantErr:      errors.New("webhook should refer to exactly one project"),
+			checkProject: true,
+		},
+		{
+			name: "test error subscription - stage included and excluded",
+			params: apimodels.EventSubscription{
+				Event: "started.test.whatever",
+				Filter: apimodels.EventSubscriptionFilter{
+					Stages: []string{"dev", "!dev"},
+				},
+			},
+			wantErr:      errors.New("the stage dev must not be included and excluded in the filter of a subscription"),
+			checkProject: false,
+		},
+		{
+			name: "test error subscription - empty exclusion",
+			params: apimodels.EventSubscription{
+				Event: "started.test.whatever",
+				Filter: apimodels.EventSubscriptionFilter{
+					Projects: []string{"demo", "!"},
+				},
+			},
+			wantErr:      errors.New("the project filter of a subscription must not contain empty values"),
+			checkProject: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
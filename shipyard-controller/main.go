@@ -247,6 +247,10 @@ func main() {
 	uniformController := controller.NewUniformIntegrationController(uniformHandler)
 	uniformController.Inject(apiV1)
 
+	eventStreamHandler := handler.NewEventStreamHandler(ctx, uniformRepo)
+	eventStreamController := controller.NewEventStreamController(eventStreamHandler)
+	eventStreamController.Inject(apiV1)
+
 	logRepo := createLogRepo()
 	err = logRepo.SetupTTLIndex(getDurationFromEnvVar(envVarLogTTL, envVarLogsTTLDefault))
 	if err != nil {
@@ -278,6 +282,13 @@ func main() {
 		log.Fatalf("Could not subscribe to nats: %v", err)
 	}
 
+	// every instance pushes the .triggered events to the integrations connected to it via the event stream
+	for _, subject := range []string{"sh.keptn.event.*.triggered", "sh.keptn.event.*.*.triggered"} {
+		if err := connectionHandler.SubscribeToBroadcast(subject, eventStreamHandler.Publish); err != nil {
+			log.Fatalf("Could not subscribe to nats: %v", err)
+		}
+	}
+
 	// Initializing the server in a goroutine so that
 	// it won't block the graceful shutdown handling below
 	go func() {
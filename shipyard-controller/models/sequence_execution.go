@@ -251,6 +251,9 @@ type TaskEvent struct {
 	Status     keptnv2.StatusType     `json:"status" bson:"status"`
 	Time       string                 `json:"time" bson:"time"`
 	Properties map[string]interface{} `json:"properties" bson:"properties"`
+	// RequiresQuorum is set for the approval.started event of an approval which is decided by the votes of a quorum.
+	// Such an approval can only be finished by the source of this event
+	RequiresQuorum bool `json:"requiresQuorum,omitempty" bson:"requiresQuorum,omitempty"`
 }
 
 type SequenceExecutionFilter struct {
@@ -1,5 +1,7 @@
 package models
 
+import apimodels "github.com/keptn/go-utils/pkg/api/models"
+
 type GetUniformIntegrationsParams struct {
 	Name    string `form:"name" json:"name"`
 	ID      string `form:"id" json:"id"`
@@ -18,3 +20,11 @@ type CreateSubscriptionResponse struct {
 type UnregisterResponse struct{}
 
 type DeleteSubscriptionResponse struct{}
+
+// StreamedEvent is a .triggered event pushed to a Keptn integration via its event stream
+type StreamedEvent struct {
+	// SubscriptionID is the ID of the subscription of the integration matching the event
+	SubscriptionID string `json:"subscriptionID"`
+	// Event is the .triggered event
+	Event apimodels.KeptnContextExtendedCE `json:"event"`
+}
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
@@ -90,6 +91,33 @@ func (nch *NatsConnectionHandler) SubscribeToTopics(topics []string, messageHand
 	return nil
 }
 
+// SubscribeToBroadcast expresses interest in the given subject on the NATS message broker. In contrast to
+// SubscribeToTopics, every instance of the shipyard-controller receives all messages of the subject.
+func (nch *NatsConnectionHandler) SubscribeToBroadcast(subject string, handler func(event apimodels.KeptnContextExtendedCE)) error {
+	if nch.natsURL == "" {
+		return errors.New("no PubSub URL defined")
+	}
+
+	if nch.natsConnection == nil || !nch.natsConnection.IsConnected() {
+		if err := nch.renewNatsConnection(); err != nil {
+			return err
+		}
+	}
+
+	_, err := nch.natsConnection.Subscribe(subject, func(msg *nats.Msg) {
+		event := &apimodels.KeptnContextExtendedCE{}
+		if err := json.Unmarshal(msg.Data, event); err != nil {
+			logger.WithError(err).Error("could not unmarshal message")
+			return
+		}
+		handler(*event)
+	})
+	if err != nil {
+		return fmt.Errorf("could not subscribe to %s: %s", subject, err.Error())
+	}
+	return nil
+}
+
 func (nch *NatsConnectionHandler) GetPublisher() (*Publisher, error) {
 	if nch.natsConnection == nil || !nch.natsConnection.IsConnected() {
 		if err := nch.renewNatsConnection(); err != nil {
@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	logger "github.com/sirupsen/logrus"
+)
+
+// CallbackPath is the path under which the callback URLs of asynchronous webhooks are served
+const CallbackPath = "/v1/callback/"
+
+// callbackDataKey is the key of the template data containing the callback URL and token of a webhook
+const callbackDataKey = "callback"
+
+const maxCallbackPayloadSize = 1024 * 1024
+
+var errCallbackNotFound = errors.New("callback not found or already used")
+
+// CallbackPayload is the payload an external system can send to the callback URL of a webhook.
+// All properties are optional; without a result, the task is considered to be successful
+type CallbackPayload struct {
+	Result  keptnv2.ResultType     `json:"result,omitempty"`
+	Status  keptnv2.StatusType     `json:"status,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Callback identifies the callback of a single task execution
+type Callback struct {
+	URL   string
+	Token string
+}
+
+type pendingCallback struct {
+	keptnHandler sdk.IKeptn
+	event        sdk.KeptnEvent
+	taskName     string
+	// result contains the finished event data collected while executing the requests, and is only set once the requests have been executed
+	result   map[string]interface{}
+	timeout  time.Duration
+	deadline time.Time
+	timer    *time.Timer
+	received *CallbackPayload
+}
+
+// CallbackRegistry keeps track of the tasks waiting for a callback, and sends the .finished event of a task once its callback URL
+// has been called, or its timeout has been reached. Pending callbacks are persisted in a CallbackStore, so that they can be resumed
+// after a restart. Callbacks are identified by the hash of their token, i.e. the token itself is never persisted
+type CallbackRegistry struct {
+	baseURL  string
+	mtx      sync.Mutex
+	pending  map[string]*pendingCallback
+	store    CallbackStore
+	newToken func() (string, error)
+	now      func() time.Time
+}
+
+type CallbackRegistryOption func(r *CallbackRegistry)
+
+// WithCallbackStore sets the store the pending callbacks are persisted in. Without a store, pending callbacks are only kept in memory
+func WithCallbackStore(store CallbackStore) CallbackRegistryOption {
+	return func(r *CallbackRegistry) {
+		r.store = store
+	}
+}
+
+func NewCallbackRegistry(baseURL string, opts ...CallbackRegistryOption) *CallbackRegistry {
+	r := &CallbackRegistry{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		pending:  map[string]*pendingCallback{},
+		store:    noopCallbackStore{},
+		newToken: generateCallbackToken,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Create registers a new callback for the given task and returns its URL, which contains a one-time token.
+// The callback only sends a .finished event after Await has been called
+func (r *CallbackRegistry) Create(keptnHandler sdk.IKeptn, event sdk.KeptnEvent, taskName string) (*Callback, error) {
+	token, err := r.newToken()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate callback token: %w", err)
+	}
+	pending := &pendingCallback{
+		keptnHandler: keptnHandler,
+		event:        event,
+		taskName:     taskName,
+	}
+	id := callbackID(token)
+	if err := r.store.Save(pending.persisted(id)); err != nil {
+		return nil, fmt.Errorf("could not persist callback: %w", err)
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.pending[id] = pending
+	return &Callback{URL: r.baseURL + CallbackPath + token, Token: token}, nil
+}
+
+// Await starts waiting for the callback. The given result is used as data of the .finished event, extended by the payload of the callback.
+// If no callback is received within the timeout, a failed .finished event is sent
+func (r *CallbackRegistry) Await(callback *Callback, result map[string]interface{}, timeout time.Duration) {
+	id := callbackID(callback.Token)
+	r.mtx.Lock()
+	pending, ok := r.pending[id]
+	if !ok {
+		r.mtx.Unlock()
+		return
+	}
+	pending.result = result
+	if pending.received != nil {
+		// the external system called back before all requests have been executed
+		delete(r.pending, id)
+		r.mtx.Unlock()
+		r.finish(id, pending, pending.received)
+		return
+	}
+	pending.timeout = timeout
+	pending.deadline = r.now().Add(timeout)
+	r.startTimer(id, pending, timeout)
+	persisted := pending.persisted(id)
+	r.mtx.Unlock()
+
+	if err := r.store.Save(persisted); err != nil {
+		logger.WithError(err).Errorf("could not persist callback of task %s, it will be lost if the webhook-service is restarted", pending.taskName)
+	}
+}
+
+// Cancel removes the callback, e.g. because one of the requests of the webhook failed
+func (r *CallbackRegistry) Cancel(callback *Callback) {
+	id := callbackID(callback.Token)
+	r.mtx.Lock()
+	if pending, ok := r.pending[id]; ok && pending.timer != nil {
+		pending.timer.Stop()
+	}
+	delete(r.pending, id)
+	r.mtx.Unlock()
+	r.deletePersisted(id)
+}
+
+// Complete finishes the task waiting for the callback with the given token
+func (r *CallbackRegistry) Complete(token string, payload *CallbackPayload) error {
+	id := callbackID(token)
+	r.mtx.Lock()
+	pending, ok := r.pending[id]
+	if !ok || pending.received != nil {
+		r.mtx.Unlock()
+		return errCallbackNotFound
+	}
+	if pending.result == nil {
+		// keep the payload until all requests have been executed
+		pending.received = payload
+		persisted := pending.persisted(id)
+		r.mtx.Unlock()
+		if err := r.store.Save(persisted); err != nil {
+			logger.WithError(err).Errorf("could not persist callback of task %s", pending.taskName)
+		}
+		return nil
+	}
+	pending.timer.Stop()
+	delete(r.pending, id)
+	r.mtx.Unlock()
+
+	r.finish(id, pending, payload)
+	return nil
+}
+
+// Resume loads the callbacks persisted before a restart of the webhook-service and resumes waiting for them with their remaining timeout.
+// The .finished events of resumed callbacks are sent using the given keptnHandler. Callbacks whose requests were still being executed
+// during the restart cannot be resumed, since the outcome of the requests is unknown, and their tasks are finished with an error
+func (r *CallbackRegistry) Resume(keptnHandler sdk.IKeptn) error {
+	persisted, err := r.store.List()
+	if err != nil {
+		return fmt.Errorf("could not load persisted callbacks: %w", err)
+	}
+	for _, p := range persisted {
+		pending := &pendingCallback{
+			keptnHandler: keptnHandler,
+			event:        p.Event,
+			taskName:     p.TaskName,
+			result:       p.Result,
+			timeout:      p.Timeout,
+			deadline:     p.Deadline,
+			received:     p.Received,
+		}
+		if pending.deadline.IsZero() {
+			// Await has not been called before the restart
+			logger.Infof("cannot resume callback of task %s, since its requests were interrupted", pending.taskName)
+			r.finish(p.ID, pending, &CallbackPayload{
+				Result:  keptnv2.ResultFailed,
+				Status:  keptnv2.StatusErrored,
+				Message: "the webhook-service was restarted while executing the requests of the webhook",
+			})
+			continue
+		}
+		if pending.result == nil {
+			pending.result = map[string]interface{}{}
+		}
+		remaining := pending.deadline.Sub(r.now())
+		if remaining <= 0 {
+			r.finish(p.ID, pending, timeoutPayload(pending.timeout))
+			continue
+		}
+		logger.Infof("resuming callback of task %s, waiting another %s", pending.taskName, remaining.String())
+		r.mtx.Lock()
+		r.pending[p.ID] = pending
+		r.startTimer(p.ID, pending, remaining)
+		r.mtx.Unlock()
+	}
+	return nil
+}
+
+// startTimer expires the callback after the given duration. The caller must hold the lock of the registry
+func (r *CallbackRegistry) startTimer(id string, pending *pendingCallback, after time.Duration) {
+	pending.timer = time.AfterFunc(after, func() {
+		r.expire(id)
+	})
+}
+
+func (r *CallbackRegistry) expire(id string) {
+	r.mtx.Lock()
+	pending, ok := r.pending[id]
+	if !ok {
+		r.mtx.Unlock()
+		return
+	}
+	delete(r.pending, id)
+	r.mtx.Unlock()
+
+	logger.Infof("no callback received for task %s within %s", pending.taskName, pending.timeout.String())
+	r.finish(id, pending, timeoutPayload(pending.timeout))
+}
+
+// finish sends the .finished event of the task and removes the persisted callback
+func (r *CallbackRegistry) finish(id string, pending *pendingCallback, payload *CallbackPayload) {
+	r.sendFinishedEvent(pending, payload)
+	r.deletePersisted(id)
+}
+
+func (r *CallbackRegistry) deletePersisted(id string) {
+	if err := r.store.Delete(id); err != nil {
+		logger.WithError(err).Errorf("could not delete persisted callback %s", id)
+	}
+}
+
+func timeoutPayload(timeout time.Duration) *CallbackPayload {
+	return &CallbackPayload{
+		Result:  keptnv2.ResultFailed,
+		Status:  keptnv2.StatusErrored,
+		Message: fmt.Sprintf("no callback received within %s", timeout.String()),
+	}
+}
+
+func (p *pendingCallback) persisted(id string) PersistedCallback {
+	return PersistedCallback{
+		ID:       id,
+		Event:    p.event,
+		TaskName: p.taskName,
+		Result:   p.result,
+		Timeout:  p.timeout,
+		Deadline: p.deadline,
+		Received: p.received,
+	}
+}
+
+func (r *CallbackRegistry) sendFinishedEvent(pending *pendingCallback, payload *CallbackPayload) {
+	result := map[string]interface{}{}
+	for key, value := range pending.result {
+		result[key] = value
+	}
+	result["result"] = keptnv2.ResultPass
+	result["status"] = keptnv2.StatusSucceeded
+	if payload.Result != "" {
+		result["result"] = payload.Result
+	}
+	if payload.Status != "" {
+		result["status"] = payload.Status
+	}
+	if payload.Message != "" {
+		result["message"] = payload.Message
+	}
+	if len(payload.Data) > 0 {
+		taskData, ok := result[pending.taskName].(map[string]interface{})
+		if !ok {
+			taskData = map[string]interface{}{}
+		}
+		for key, value := range payload.Data {
+			taskData[key] = value
+		}
+		result[pending.taskName] = taskData
+	}
+	if err := pending.keptnHandler.SendFinishedEvent(pending.event, result); err != nil {
+		logger.WithError(err).Error("could not send .finished event")
+	}
+}
+
+// ServeHTTP receives the callbacks of external systems. The token is the last segment of the path
+func (r *CallbackRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(req.URL.Path, CallbackPath)
+	if token == "" || strings.Contains(token, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	payload := &CallbackPayload{}
+	body := http.MaxBytesReader(w, req.Body, maxCallbackPayloadSize)
+	if err := json.NewDecoder(body).Decode(payload); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "could not parse callback payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateCallbackPayload(payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := r.Complete(token, payload); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func validateCallbackPayload(payload *CallbackPayload) error {
+	switch payload.Result {
+	case "", keptnv2.ResultPass, keptnv2.ResultWarning, keptnv2.ResultFailed:
+	default:
+		return fmt.Errorf("invalid result %s", payload.Result)
+	}
+	switch payload.Status {
+	case "", keptnv2.StatusSucceeded, keptnv2.StatusErrored, keptnv2.StatusUnknown:
+	default:
+		return fmt.Errorf("invalid status %s", payload.Status)
+	}
+	return nil
+}
+
+// callbackID derives the ID a callback is stored with from its token
+func callbackID(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateCallbackToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// CallbackSecretName is the name of the K8S secret holding the pending callbacks. The webhook-service is only allowed to read and update this secret
+const CallbackSecretName = "keptn-webhook-callbacks"
+
+// PersistedCallback contains everything needed to finish the task of a pending callback after a restart of the webhook-service
+type PersistedCallback struct {
+	// ID is the hash of the token of the callback
+	ID       string                 `json:"id"`
+	Event    sdk.KeptnEvent         `json:"event"`
+	TaskName string                 `json:"taskName"`
+	Result   map[string]interface{} `json:"result,omitempty"`
+	Timeout  time.Duration          `json:"timeout,omitempty"`
+	Deadline time.Time              `json:"deadline,omitempty"`
+	Received *CallbackPayload       `json:"received,omitempty"`
+}
+
+// CallbackStore persists the pending callbacks of a CallbackRegistry
+type CallbackStore interface {
+	// Save creates or updates the given callback
+	Save(callback PersistedCallback) error
+	// Delete removes the callback with the given ID. Deleting an unknown callback is not an error
+	Delete(id string) error
+	// List returns all persisted callbacks
+	List() ([]PersistedCallback, error)
+}
+
+type noopCallbackStore struct{}
+
+func (noopCallbackStore) Save(PersistedCallback) error       { return nil }
+func (noopCallbackStore) Delete(string) error                { return nil }
+func (noopCallbackStore) List() ([]PersistedCallback, error) { return nil, nil }
+
+// K8sCallbackStore persists all pending callbacks in a single K8S secret, as they contain the data of the event and the responses
+// of the webhook requests. Each callback is stored in a key named after its ID
+type K8sCallbackStore struct {
+	kubeAPI   kubernetes.Interface
+	namespace string
+}
+
+func NewK8sCallbackStore(kubeAPI kubernetes.Interface, namespace string) *K8sCallbackStore {
+	return &K8sCallbackStore{kubeAPI: kubeAPI, namespace: namespace}
+}
+
+func (s *K8sCallbackStore) Save(callback PersistedCallback) error {
+	content, err := json.Marshal(callback)
+	if err != nil {
+		return err
+	}
+	return s.modify(func(data map[string][]byte) {
+		data[callback.ID] = content
+	})
+}
+
+func (s *K8sCallbackStore) Delete(id string) error {
+	return s.modify(func(data map[string][]byte) {
+		delete(data, id)
+	})
+}
+
+func (s *K8sCallbackStore) List() ([]PersistedCallback, error) {
+	secret, err := s.kubeAPI.CoreV1().Secrets(s.namespace).Get(context.TODO(), CallbackSecretName, metav1.GetOptions{})
+	if err != nil {
+		if k8serr.IsNotFound(err) {
+			return []PersistedCallback{}, nil
+		}
+		return nil, err
+	}
+	ids := make([]string, 0, len(secret.Data))
+	for id := range secret.Data {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	callbacks := make([]PersistedCallback, 0, len(ids))
+	for _, id := range ids {
+		callback := PersistedCallback{}
+		if err := json.Unmarshal(secret.Data[id], &callback); err != nil {
+			return nil, fmt.Errorf("could not decode callback %s stored in secret %s: %w", id, CallbackSecretName, err)
+		}
+		callbacks = append(callbacks, callback)
+	}
+	return callbacks, nil
+}
+
+// modify applies the given function to the stored callbacks and updates the secret using its resourceVersion,
+// so that callbacks stored concurrently are not overwritten. On a conflict, the function is applied to the latest version of the secret.
+// The secret is usually created by the Helm chart, as the webhook-service is not allowed to create secrets
+func (s *K8sCallbackStore) modify(fn func(data map[string][]byte)) error {
+	isConflict := func(err error) bool {
+		return k8serr.IsConflict(err) || k8serr.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isConflict, func() error {
+		secret, err := s.kubeAPI.CoreV1().Secrets(s.namespace).Get(context.TODO(), CallbackSecretName, metav1.GetOptions{})
+		exists := err == nil
+		if err != nil {
+			if !k8serr.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      CallbackSecretName,
+					Namespace: s.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "webhook-service",
+						"app.kubernetes.io/component":  "callback",
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		fn(secret.Data)
+
+		if !exists {
+			// fails with AlreadyExists if another instance created the secret in the meantime
+			_, err = s.kubeAPI.CoreV1().Secrets(s.namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+			return err
+		}
+		_, err = s.kubeAPI.CoreV1().Secrets(s.namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,181 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	"github.com/keptn/keptn/webhook-service/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// listStoredCallbacks returns the keys of the secret holding the pending callbacks
+func listStoredCallbacks(t *testing.T, kubeAPI *fake.Clientset) []string {
+	secret, err := kubeAPI.CoreV1().Secrets("keptn").Get(context.TODO(), handler.CallbackSecretName, metav1.GetOptions{})
+	require.Nil(t, err)
+	var ids []string
+	for id := range secret.Data {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestCallbackRegistry_ResumeAfterRestart(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	kubeAPI := fake.NewSimpleClientset()
+	store := handler.NewK8sCallbackStore(kubeAPI, "keptn")
+
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+	registry.Await(callback, map[string]interface{}{"webhook": map[string]interface{}{"responses": []string{"accepted"}}}, time.Hour)
+
+	persisted, err := store.List()
+	require.Nil(t, err)
+	require.Len(t, persisted, 1)
+	assert.NotContains(t, listStoredCallbacks(t, kubeAPI)[0], callback.Token)
+
+	// simulate a restart of the webhook-service
+	restartedKeptn, _ := newCallbackTestSetup(t)
+	restarted := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	require.Nil(t, restarted.Resume(restartedKeptn.Keptn))
+	require.Empty(t, restartedKeptn.GetEventSender().SentEvents)
+
+	require.Nil(t, restarted.Complete(callback.Token, &handler.CallbackPayload{Data: map[string]interface{}{"jobId": "42"}}))
+
+	eventData := finishedEventData(t, restartedKeptn)
+	assert.Equal(t, string(keptnv2.ResultPass), eventData["result"])
+	assert.Equal(t, map[string]interface{}{"jobId": "42", "responses": []interface{}{"accepted"}}, eventData["webhook"])
+	assert.Empty(t, listStoredCallbacks(t, kubeAPI))
+}
+
+func TestCallbackRegistry_ResumeRemainingTimeout(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	store := handler.NewK8sCallbackStore(fake.NewSimpleClientset(), "keptn")
+	require.Nil(t, store.Save(handler.PersistedCallback{
+		ID:       "my-callback",
+		Event:    event,
+		TaskName: "webhook",
+		Result:   map[string]interface{}{},
+		Timeout:  time.Hour,
+		Deadline: time.Now().Add(10 * time.Millisecond),
+	}))
+
+	keptnHandler := &notifyingKeptn{IKeptn: fakeKeptn.Keptn, finished: make(chan struct{})}
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	require.Nil(t, registry.Resume(keptnHandler))
+
+	select {
+	case <-keptnHandler.finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no .finished event sent after the remaining timeout of the resumed callback")
+	}
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultFailed), eventData["result"])
+	assert.Equal(t, "no callback received within 1h0m0s", eventData["message"])
+}
+
+func TestCallbackRegistry_ResumeExpired(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	kubeAPI := fake.NewSimpleClientset()
+	store := handler.NewK8sCallbackStore(kubeAPI, "keptn")
+	require.Nil(t, store.Save(handler.PersistedCallback{
+		ID:       "my-callback",
+		Event:    event,
+		TaskName: "webhook",
+		Result:   map[string]interface{}{},
+		Timeout:  time.Hour,
+		Deadline: time.Now().Add(-time.Minute),
+	}))
+
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	require.Nil(t, registry.Resume(fakeKeptn.Keptn))
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultFailed), eventData["result"])
+	assert.Equal(t, string(keptnv2.StatusErrored), eventData["status"])
+	assert.Empty(t, listStoredCallbacks(t, kubeAPI))
+}
+
+func TestCallbackRegistry_ResumeInterruptedRequests(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	kubeAPI := fake.NewSimpleClientset()
+	store := handler.NewK8sCallbackStore(kubeAPI, "keptn")
+
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	_, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+
+	// the webhook-service is restarted before Await has been called
+	restartedKeptn, _ := newCallbackTestSetup(t)
+	restarted := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(store))
+	require.Nil(t, restarted.Resume(restartedKeptn.Keptn))
+
+	eventData := finishedEventData(t, restartedKeptn)
+	assert.Equal(t, string(keptnv2.ResultFailed), eventData["result"])
+	assert.Equal(t, string(keptnv2.StatusErrored), eventData["status"])
+	assert.Empty(t, listStoredCallbacks(t, kubeAPI))
+}
+
+func TestCallbackRegistry_CancelDeletesPersistedCallback(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	kubeAPI := fake.NewSimpleClientset()
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082", handler.WithCallbackStore(handler.NewK8sCallbackStore(kubeAPI, "keptn")))
+
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+	require.Len(t, listStoredCallbacks(t, kubeAPI), 1)
+
+	registry.Cancel(callback)
+	assert.Empty(t, listStoredCallbacks(t, kubeAPI))
+}
+
+func TestK8sCallbackStore(t *testing.T) {
+	kubeAPI := fake.NewSimpleClientset()
+	store := handler.NewK8sCallbackStore(kubeAPI, "keptn")
+
+	callback := handler.PersistedCallback{ID: "my-callback", TaskName: "webhook", Event: sdk.KeptnEvent{ID: "my-event"}}
+	require.Nil(t, store.Save(callback))
+	callback.Timeout = time.Hour
+	require.Nil(t, store.Save(callback))
+
+	callbacks, err := store.List()
+	require.Nil(t, err)
+	require.Len(t, callbacks, 1)
+	assert.Equal(t, "my-callback", callbacks[0].ID)
+	assert.Equal(t, "my-event", callbacks[0].Event.ID)
+	assert.Equal(t, time.Hour, callbacks[0].Timeout)
+	assert.Equal(t, []string{"my-callback"}, listStoredCallbacks(t, kubeAPI))
+
+	require.Nil(t, store.Delete("my-callback"))
+	require.Nil(t, store.Delete("my-callback"))
+	callbacks, err = store.List()
+	require.Nil(t, err)
+	assert.Empty(t, callbacks)
+}
+
+func TestK8sCallbackStore_UsesExistingSecret(t *testing.T) {
+	// the secret is created by the Helm chart, as the webhook-service is only allowed to read and update it
+	kubeAPI := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: handler.CallbackSecretName, Namespace: "keptn"}})
+	store := handler.NewK8sCallbackStore(kubeAPI, "keptn")
+
+	callbacks, err := store.List()
+	require.Nil(t, err)
+	assert.Empty(t, callbacks)
+
+	require.Nil(t, store.Save(handler.PersistedCallback{ID: "my-callback", TaskName: "webhook"}))
+	require.Nil(t, store.Save(handler.PersistedCallback{ID: "other-callback", TaskName: "webhook"}))
+
+	callbacks, err = store.List()
+	require.Nil(t, err)
+	require.Len(t, callbacks, 2)
+	assert.Equal(t, "my-callback", callbacks[0].ID)
+	assert.Equal(t, "other-callback", callbacks[1].ID)
+}
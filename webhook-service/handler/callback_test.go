@@ -0,0 +1,188 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/keptn/keptn/go-sdk/pkg/sdk"
+	"github.com/keptn/keptn/webhook-service/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCallbackTestSetup(t *testing.T) (*sdk.FakeKeptn, sdk.KeptnEvent) {
+	fakeKeptn := sdk.NewFakeKeptn("test-webhook-svc")
+	ce := newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json")
+	event := sdk.KeptnEvent{}
+	require.Nil(t, keptnv2.Decode(&ce, &event))
+	return fakeKeptn, event
+}
+
+func finishedEventData(t *testing.T, fakeKeptn *sdk.FakeKeptn) map[string]interface{} {
+	sentEvents := fakeKeptn.GetEventSender().SentEvents
+	require.Len(t, sentEvents, 1)
+	assert.Equal(t, "sh.keptn.event.webhook.finished", sentEvents[0].Type())
+	finishedEvent, err := keptnv2.ToKeptnEvent(sentEvents[0])
+	require.Nil(t, err)
+	eventData := map[string]interface{}{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, &eventData))
+	return eventData
+}
+
+func TestCallbackRegistry_Complete(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082/")
+
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+	require.Equal(t, "http://webhook-service:8082"+handler.CallbackPath+callback.Token, callback.URL)
+
+	registry.Await(callback, map[string]interface{}{"webhook": map[string]interface{}{"responses": []string{"accepted"}}}, time.Hour)
+	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
+
+	err = registry.Complete(callback.Token, &handler.CallbackPayload{Data: map[string]interface{}{"jobId": "42"}})
+	require.Nil(t, err)
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultPass), eventData["result"])
+	assert.Equal(t, string(keptnv2.StatusSucceeded), eventData["status"])
+	assert.Equal(t, map[string]interface{}{"jobId": "42", "responses": []interface{}{"accepted"}}, eventData["webhook"])
+
+	require.NotNil(t, registry.Complete(callback.Token, &handler.CallbackPayload{}))
+}
+
+func TestCallbackRegistry_CompleteBeforeAwait(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082")
+
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+
+	// the external system may call back before the requests of the webhook have been completed
+	err = registry.Complete(callback.Token, &handler.CallbackPayload{Result: keptnv2.ResultFailed, Message: "job failed"})
+	require.Nil(t, err)
+	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
+
+	registry.Await(callback, map[string]interface{}{}, time.Hour)
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultFailed), eventData["result"])
+	assert.Equal(t, "job failed", eventData["message"])
+}
+
+// notifyingKeptn signals when a .finished event has been sent from another goroutine
+type notifyingKeptn struct {
+	sdk.IKeptn
+	finished chan struct{}
+}
+
+func (k *notifyingKeptn) SendFinishedEvent(event sdk.KeptnEvent, result interface{}) error {
+	defer close(k.finished)
+	return k.IKeptn.SendFinishedEvent(event, result)
+}
+
+func TestCallbackRegistry_Timeout(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082")
+	keptnHandler := &notifyingKeptn{IKeptn: fakeKeptn.Keptn, finished: make(chan struct{})}
+
+	callback, err := registry.Create(keptnHandler, event, "webhook")
+	require.Nil(t, err)
+	registry.Await(callback, map[string]interface{}{}, 10*time.Millisecond)
+
+	select {
+	case <-keptnHandler.finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no .finished event sent after the callback timed out")
+	}
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultFailed), eventData["result"])
+	assert.Equal(t, string(keptnv2.StatusErrored), eventData["status"])
+	assert.Contains(t, eventData["message"], "no callback received")
+
+	require.NotNil(t, registry.Complete(callback.Token, &handler.CallbackPayload{}))
+}
+
+func TestCallbackRegistry_Cancel(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082")
+
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+	registry.Cancel(callback)
+
+	require.NotNil(t, registry.Complete(callback.Token, &handler.CallbackPayload{}))
+	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
+}
+
+func TestCallbackRegistry_ServeHTTP(t *testing.T) {
+	fakeKeptn, event := newCallbackTestSetup(t)
+	registry := handler.NewCallbackRegistry("http://webhook-service:8082")
+	callback, err := registry.Create(fakeKeptn.Keptn, event, "webhook")
+	require.Nil(t, err)
+	registry.Await(callback, map[string]interface{}{}, time.Hour)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       handler.CallbackPath + callback.Token,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "unknown token",
+			method:     http.MethodPost,
+			path:       handler.CallbackPath + "unknown",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPost,
+			path:       handler.CallbackPath + callback.Token,
+			body:       `{"result": "great"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed payload",
+			method:     http.MethodPost,
+			path:       handler.CallbackPath + callback.Token,
+			body:       `{"result":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "valid payload",
+			method:     http.MethodPost,
+			path:       handler.CallbackPath + callback.Token,
+			body:       `{"result": "warning", "data": {"jobId": "42"}}`,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "token already used",
+			method:     http.MethodPost,
+			path:       handler.CallbackPath + callback.Token,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			registry.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	eventData := finishedEventData(t, fakeKeptn)
+	assert.Equal(t, string(keptnv2.ResultWarning), eventData["result"])
+	assert.Equal(t, map[string]interface{}{"jobId": "42"}, eventData["webhook"])
+}
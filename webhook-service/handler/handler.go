@@ -22,17 +22,31 @@ type SecretEnv struct {
 }
 
 type TaskHandler struct {
-	templateEngine lib.ITemplateEngine
-	curlExecutor   lib.ICurlExecutor
-	secretReader   lib.ISecretReader
+	templateEngine  lib.ITemplateEngine
+	requestExecutor lib.IRequestExecutor
+	secretReader    lib.ISecretReader
+	callbacks       *CallbackRegistry
 }
 
-func NewTaskHandler(templateEngine lib.ITemplateEngine, curlExecutor lib.ICurlExecutor, secretReader lib.ISecretReader) *TaskHandler {
-	return &TaskHandler{
-		templateEngine: templateEngine,
-		curlExecutor:   curlExecutor,
-		secretReader:   secretReader,
+type TaskHandlerOption func(th *TaskHandler)
+
+// WithCallbacks enables webhooks that wait for a call of their callback URL before sending the .finished event
+func WithCallbacks(callbacks *CallbackRegistry) TaskHandlerOption {
+	return func(th *TaskHandler) {
+		th.callbacks = callbacks
+	}
+}
+
+func NewTaskHandler(templateEngine lib.ITemplateEngine, requestExecutor lib.IRequestExecutor, secretReader lib.ISecretReader, opts ...TaskHandlerOption) *TaskHandler {
+	th := &TaskHandler{
+		templateEngine:  templateEngine,
+		requestExecutor: requestExecutor,
+		secretReader:    secretReader,
+	}
+	for _, opt := range opts {
+		opt(th)
 	}
+	return th
 }
 
 func (th *TaskHandler) Execute(keptnHandler sdk.IKeptn, event sdk.KeptnEvent) (interface{}, *sdk.Error) {
@@ -64,8 +78,21 @@ func (th *TaskHandler) Execute(keptnHandler sdk.IKeptn, event sdk.KeptnEvent) (i
 		return nil, sdkError(removeSecretsFromMessage(err.Error(), secretEnvVars), err)
 	}
 	eventAdapter.Add("env", secretEnvVars)
-	responses, err = th.performWebhookRequests(*webhook, eventAdapter, responses)
+
+	callback, err := th.createCallback(keptnHandler, event, webhook)
+	if err != nil {
+		onError(err, secretEnvVars)
+		return nil, sdkError(err.Error(), err)
+	}
+	if callback != nil {
+		eventAdapter.Add(callbackDataKey, map[string]string{"url": callback.URL, "token": callback.Token})
+	}
+
+	responses, extractedValues, err := th.performWebhookRequests(keptnHandler, *webhook, eventAdapter, event.GitCommitID, responses)
 	if err != nil {
+		if callback != nil {
+			th.callbacks.Cancel(callback)
+		}
 		onError(err, secretEnvVars)
 		return nil, sdkError(removeSecretsFromMessage(err.Error(), secretEnvVars), err)
 	}
@@ -77,14 +104,26 @@ func (th *TaskHandler) Execute(keptnHandler sdk.IKeptn, event sdk.KeptnEvent) (i
 		if err != nil {
 			return nil, sdkError(fmt.Sprintf("could not derive task name from event type %s", *event.Type), err)
 		}
+		taskData := map[string]interface{}{}
+		for key, value := range extractedValues {
+			taskData[key] = value
+		}
+		taskData[lib.ResponsesKey] = responses
 		result := map[string]interface{}{
 			"project": eventAdapter.Project(),
 			"stage":   eventAdapter.Stage(),
 			"service": eventAdapter.Service(),
 			"labels":  eventAdapter.Labels(),
-			taskName: map[string]interface{}{
-				"responses": responses,
-			},
+			taskName:  taskData,
+		}
+		if callback != nil {
+			// the .finished event is sent once the callback URL has been called, or the timeout has been reached
+			timeout, err := webhook.Callback.GetTimeout()
+			if err != nil {
+				return nil, sdkError(err.Error(), err)
+			}
+			th.callbacks.Await(callback, result, timeout)
+			return nil, nil
 		}
 		err = keptnHandler.SendFinishedEvent(event, result)
 		if err != nil {
@@ -96,6 +135,25 @@ func (th *TaskHandler) Execute(keptnHandler sdk.IKeptn, event sdk.KeptnEvent) (i
 	return nil, nil
 }
 
+// createCallback creates the callback for webhooks waiting for a callback. Callbacks are only created for <task>.triggered events
+func (th *TaskHandler) createCallback(keptnHandler sdk.IKeptn, event sdk.KeptnEvent, webhook *lib.Webhook) (*Callback, error) {
+	if !webhook.ShouldWaitForCallback() || !keptnv2.IsTaskEventType(*event.Type) || !keptnv2.IsTriggeredEventType(*event.Type) {
+		return nil, nil
+	}
+	if th.callbacks == nil {
+		return nil, lib.NewWebhookExecutionError(true, errors.New("webhook waits for a callback, but callbacks are not enabled"))
+	}
+	taskName, _, err := keptnv2.ParseTaskEventType(*event.Type)
+	if err != nil {
+		return nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not derive task name from event type %s", *event.Type))
+	}
+	callback, err := th.callbacks.Create(keptnHandler, event, taskName)
+	if err != nil {
+		return nil, lib.NewWebhookExecutionError(true, err)
+	}
+	return callback, nil
+}
+
 func (th *TaskHandler) onPreExecutionError(keptnHandler sdk.IKeptn, event sdk.KeptnEvent, eventAdapter *lib.EventDataAdapter, err error) (interface{}, *sdk.Error) {
 	// in this case, send .started and .finished event immediately
 	if err := keptnHandler.SendStartedEvent(event); err != nil {
@@ -129,6 +187,10 @@ func (th *TaskHandler) getErrorCallbackForWebhookConfig(keptnHandler sdk.IKeptn,
 			"status":  keptnv2.StatusErrored,
 			"message": removeSecretsFromMessage(err.Error(), secrets),
 		}
+		if ok && whe.SuccessConditionFailed {
+			// the request itself has been executed, but its response did not fulfill the success condition
+			result["status"] = keptnv2.StatusSucceeded
+		}
 
 		if ok && whe.PreExecutionError {
 			if webhook.ShouldSendFinishedEvent() {
@@ -192,29 +254,84 @@ func (th *TaskHandler) onStartedWebhookExecution(keptnHandler sdk.IKeptn, event
 	return nil
 }
 
-func (th *TaskHandler) performWebhookRequests(webhook lib.Webhook, eventAdapter *lib.EventDataAdapter, responses []string) ([]string, error) {
+// performWebhookRequests executes the requests of the webhook and returns their responses, as well as the values extracted from the responses
+func (th *TaskHandler) performWebhookRequests(keptnHandler sdk.IKeptn, webhook lib.Webhook, eventAdapter *lib.EventDataAdapter, commitID string, responses []string) ([]string, map[string]interface{}, error) {
 	executedRequests := 0
+	extractedValues := map[string]interface{}{}
 	logger.Infof("executing webhooks for subscriptionID %s", webhook.SubscriptionID)
 	for _, req := range webhook.Requests {
-		request, err := CreateRequest(req)
-		if err != nil {
-			logger.Infof("creating CURL request failed: %s", err.Error())
-			return nil, err
-		}
 		// parse the data from the event, together with the secret env vars
-		parsedCurlCommand, err := th.templateEngine.ParseTemplate(eventAdapter.Get(), request)
+		request, err := CreateRequest(th.templateEngine, eventAdapter.Get(), req)
 		if err != nil {
-			return nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not parse request '%s' : %s", request, err.Error()), lib.WithNrOfExecutedRequests(executedRequests))
+			logger.Infof("creating request failed: %s", err.Error())
+			return nil, nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not create request '%s': %s", describeRequest(req), err.Error()), lib.WithNrOfExecutedRequests(executedRequests))
+		}
+		if err := th.readMultipartResources(keptnHandler, eventAdapter, commitID, request); err != nil {
+			return nil, nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not create request '%s': %s", describeRequest(req), err.Error()), lib.WithNrOfExecutedRequests(executedRequests))
 		}
 		// perform the request
-		response, err := th.curlExecutor.Curl(parsedCurlCommand)
+		response, err := th.requestExecutor.Execute(*request)
+		if err == nil {
+			err = lib.CheckResponse(*request, response)
+		}
+		if err != nil {
+			opts := []lib.WebhookExecutionErrorOpt{lib.WithNrOfExecutedRequests(executedRequests)}
+			if lib.IsSuccessConditionError(err) {
+				opts = append(opts, lib.WithFailedSuccessCondition())
+			}
+			return nil, nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not execute request '%s': %s", describeRequest(req), err.Error()), opts...)
+		}
+		values, err := lib.ExtractResponseValues(*request, response)
 		if err != nil {
-			return nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not execute request '%s': %s", request, err.Error()), lib.WithNrOfExecutedRequests(executedRequests))
+			return nil, nil, lib.NewWebhookExecutionError(true, fmt.Errorf("could not process response of request '%s': %s", describeRequest(req), err.Error()), lib.WithNrOfExecutedRequests(executedRequests))
+		}
+		for key, value := range values {
+			extractedValues[key] = value
 		}
 		executedRequests = executedRequests + 1
-		responses = append(responses, response)
+		responses = append(responses, response.Body)
+	}
+	return responses, extractedValues, nil
+}
+
+// readMultipartResources retrieves the content of the Keptn resources attached to the multipart body of the request
+func (th *TaskHandler) readMultipartResources(keptnHandler sdk.IKeptn, eventAdapter *lib.EventDataAdapter, commitID string, request *lib.Request) error {
+	for i, field := range request.Multipart {
+		if field.Resource == "" {
+			continue
+		}
+		resource, err := th.getResource(keptnHandler, eventAdapter, field.Resource, commitID)
+		if err != nil {
+			return fmt.Errorf("could not read resource %s: %s", field.Resource, err.Error())
+		}
+		request.Multipart[i].Content = resource.ResourceContent
+	}
+	return nil
+}
+
+// getResource retrieves the resource with the given URI from the service, the stage or the project of the event, in this order
+func (th *TaskHandler) getResource(keptnHandler sdk.IKeptn, eventAdapter *lib.EventDataAdapter, resourceURI string, commitID string) (*models.Resource, error) {
+	commitOption := url.Values{}
+	if commitID != "" {
+		commitOption.Add("commitID", commitID)
 	}
-	return responses, nil
+	scopes := []*keptn.ResourceScope{
+		keptn.NewResourceScope().Project(eventAdapter.Project()).Stage(eventAdapter.Stage()).Service(eventAdapter.Service()).Resource(resourceURI),
+		keptn.NewResourceScope().Project(eventAdapter.Project()).Stage(eventAdapter.Stage()).Resource(resourceURI),
+		keptn.NewResourceScope().Project(eventAdapter.Project()).Resource(resourceURI),
+	}
+	var err error
+	for _, scope := range scopes {
+		var resource *models.Resource
+		resource, err = keptnHandler.GetResourceHandler().GetResource(*scope, keptn.AppendQuery(commitOption))
+		if err == nil && resource != nil {
+			return resource, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.New("resource not found")
 }
 
 func (th *TaskHandler) gatherSecretEnvVars(webhook lib.Webhook) (map[string]string, error) {
@@ -229,44 +346,110 @@ func (th *TaskHandler) gatherSecretEnvVars(webhook lib.Webhook) (map[string]stri
 	return secretEnvVars, nil
 }
 
-func CreateRequest(request interface{}) (string, error) {
+// CreateRequest fills in the templates of the given webhook request using the provided data and converts it into a lib.Request.
+// Requests of v1alpha1 are curl commands, which are parsed after the templates have been executed
+func CreateRequest(templateEngine lib.ITemplateEngine, data interface{}, request interface{}) (*lib.Request, error) {
 	switch req := request.(type) {
 	// v1alpha1 version
 	case string:
-		logger.Debug("creating CURL request from type string")
-		return req, nil
+		logger.Debug("creating request from curl command")
+		curlCmd, err := templateEngine.ParseTemplate(data, req)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse request template: %s", err.Error())
+		}
+		return lib.ParseCurlCommand(curlCmd)
 	// v1beta1 version
 	default:
-		logger.Debug("creating CURL request from type Request")
-		betaRequest := buildBetaCurlRequest(lib.ConvertToRequest(request))
-		if betaRequest != "" {
-			return betaRequest, nil
-		}
+		logger.Debug("creating request from type Request")
+		return createBetaRequest(templateEngine, data, lib.ConvertToRequest(request))
 	}
-
-	return "", fmt.Errorf("could not create request: invalid request type")
 }
 
-func buildBetaCurlRequest(req lib.Request) string {
-	tmpReq := ""
-	if req.Method != "" {
-		tmpReq = fmt.Sprintf("curl --request %s", req.Method)
+func createBetaRequest(templateEngine lib.ITemplateEngine, data interface{}, req lib.Request) (*lib.Request, error) {
+	if req.URL == "" {
+		return nil, errors.New("could not create request: invalid request type")
+	}
+	parse := func(templateStr string) (string, error) {
+		if templateStr == "" {
+			return "", nil
+		}
+		parsed, err := templateEngine.ParseTemplate(data, templateStr)
+		if err != nil {
+			return "", fmt.Errorf("could not parse request template: %s", err.Error())
+		}
+		return parsed, nil
+	}
+
+	var err error
+	result := req
+	result.Headers = []lib.Header{}
+	if result.URL, err = parse(req.URL); err != nil {
+		return nil, err
+	}
+	if result.Method, err = parse(req.Method); err != nil {
+		return nil, err
+	}
+	if result.Payload, err = parse(req.Payload); err != nil {
+		return nil, err
+	}
+	for _, header := range req.Headers {
+		key, err := parse(header.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parse(header.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.Headers = append(result.Headers, lib.Header{Key: key, Value: value})
+	}
+	result.Form = nil
+	for _, field := range req.Form {
+		key, err := parse(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parse(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.Form = append(result.Form, lib.FormField{Key: key, Value: value})
 	}
-	if len(req.Headers) > 0 {
-		for _, header := range req.Headers {
-			tmpReq = fmt.Sprintf(tmpReq+" --header '%s: %s'", header.Key, header.Value)
+	result.Multipart = nil
+	for _, field := range req.Multipart {
+		part := lib.MultipartField{ContentType: field.ContentType}
+		if part.Name, err = parse(field.Name); err != nil {
+			return nil, err
+		}
+		if part.Value, err = parse(field.Value); err != nil {
+			return nil, err
 		}
+		if part.Resource, err = parse(field.Resource); err != nil {
+			return nil, err
+		}
+		if part.Filename, err = parse(field.Filename); err != nil {
+			return nil, err
+		}
+		result.Multipart = append(result.Multipart, part)
 	}
-	if req.Payload != "" {
-		tmpReq = fmt.Sprintf(tmpReq+" --data '%s'", req.Payload)
+	if result.Options, err = parse(req.Options); err != nil {
+		return nil, err
 	}
-	if req.Options != "" {
-		tmpReq = fmt.Sprintf(tmpReq+" %s", req.Options)
+	if result.Options != "" {
+		if err := lib.ApplyCurlOptions(&result, result.Options); err != nil {
+			return nil, err
+		}
 	}
-	if req.URL != "" {
-		tmpReq = fmt.Sprintf(tmpReq+" %s", req.URL)
+	return &result, nil
+}
+
+// describeRequest returns the representation of a request used within error messages, before any templates are executed
+func describeRequest(request interface{}) string {
+	if req, ok := request.(string); ok {
+		return req
 	}
-	return tmpReq
+	req := lib.ConvertToRequest(request)
+	return fmt.Sprintf("%s %s", req.GetMethod(), req.URL)
 }
 
 func sdkError(msg string, err error) *sdk.Error {
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -34,7 +36,7 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithStartedEvent = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -49,7 +51,7 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithFinishedEvent = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -64,7 +66,7 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithMultipleRequests = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -79,8 +81,8 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithMultipleRequestsAndDisabledFinished = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -95,10 +97,10 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithMultipleRequestsAndDisabledStarted = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -114,10 +116,10 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 const webHookContentWithMissingTemplateData = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -132,7 +134,7 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.unavailable}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.unavailable}}"`
 
 const webHookContentWithNoMatchingSubscriptionID = `apiVersion: webhookconfig.keptn.sh/v1alpha1
 kind: WebhookConfig
@@ -146,7 +148,7 @@ spec:
         - secretRef:
           name: mysecret
       requests:
-        - "curl http://localhost:8080 {{.data.project}} {{.env.mysecret}}"`
+        - "curl -H 'token: {{.env.mysecret}}' http://localhost:8080/{{.data.project}}"`
 
 func newWebhookTriggeredEvent(filename string) cloudevents.Event {
 	content, err := ioutil.ReadFile(filename)
@@ -159,6 +161,11 @@ func newWebhookTriggeredEvent(filename string) cloudevents.Event {
 	return keptnv2.ToCloudEvent(event)
 }
 
+var expectedWebhookRequest = lib.Request{
+	URL:     "http://localhost:8080/myproject",
+	Headers: []lib.Header{{Key: "token", Value: "my-secret-value"}},
+}
+
 func Test_HandleIncomingTriggeredEvent(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
 		tplE := &lib.TemplateEngine{}
@@ -170,12 +177,12 @@ func Test_HandleIncomingTriggeredEvent(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -185,8 +192,8 @@ func Test_HandleIncomingTriggeredEvent(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 1)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -212,12 +219,12 @@ func Test_HandleIncomingStartedEvent(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -227,8 +234,8 @@ func Test_HandleIncomingStartedEvent(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.started.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 1)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
@@ -245,12 +252,12 @@ func Test_HandleIncomingStartedEventWithResultingError(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "", errors.New("oops")
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return nil, errors.New("oops")
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -260,8 +267,8 @@ func Test_HandleIncomingStartedEventWithResultingError(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.started.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 1)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
@@ -278,12 +285,12 @@ func Test_HandleIncomingFinishedEvent(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -293,8 +300,8 @@ func Test_HandleIncomingFinishedEvent(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.finished.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 1)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
@@ -311,12 +318,12 @@ func Test_HandleIncomingFinishedEventWithResultingError(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "", errors.New("oops")
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return nil, errors.New("oops")
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -326,8 +333,8 @@ func Test_HandleIncomingFinishedEventWithResultingError(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.finished.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 1)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
@@ -344,12 +351,12 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequests(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -360,9 +367,9 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequests(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 2)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[1].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 2)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[1].Request)
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -388,12 +395,12 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableFinished(t *te
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -404,11 +411,11 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableFinished(t *te
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 4)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[1].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[2].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[3].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 4)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[1].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[2].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[3].Request)
 
 	//verify sent events
 	require.Equal(t, 4, len(fakeKeptn.GetEventSender().SentEvents))
@@ -429,12 +436,12 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableStarted(t *tes
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -445,11 +452,11 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableStarted(t *tes
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 4)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[1].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[2].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[3].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 4)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[1].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[2].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[3].Request)
 
 	//verify sent events
 	require.Empty(t, len(fakeKeptn.GetEventSender().SentEvents))
@@ -466,16 +473,16 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableFinishedOneReq
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
 		// make the second request fail
-		if len(curlExecutorMock.CurlCalls()) == 2 {
-			return "", errors.New("oops")
+		if len(requestExecutorMock.ExecuteCalls()) == 2 {
+			return nil, errors.New("oops")
 		}
-		return "success", nil
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -486,9 +493,9 @@ func Test_HandleIncomingTriggeredEvent_SendMultipleRequestsDisableFinishedOneReq
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Len(t, curlExecutorMock.CurlCalls(), 2)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
-	assert.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[1].CurlCmd)
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 2)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
+	assert.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[1].Request)
 
 	//verify sent events
 	require.Equal(t, 7, len(fakeKeptn.GetEventSender().SentEvents))
@@ -514,12 +521,12 @@ func Test_HandleIncomingTriggeredEvent_NoMatchingWebhookFound(t *testing.T) {
 		return "my-secret-value", nil
 	}
 
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -530,7 +537,7 @@ func Test_HandleIncomingTriggeredEvent_NoMatchingWebhookFound(t *testing.T) {
 	fakeKeptn.Start()
 	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
 
-	require.Empty(t, curlExecutorMock.CurlCalls())
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -548,9 +555,9 @@ func Test_HandleIncomingTriggeredEvent_NoMatchingWebhookFound(t *testing.T) {
 func TestTaskHandler_Execute_WebhookCannotBeRetrieved(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{}
 	secretReaderMock := &fake.ISecretReaderMock{}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -577,9 +584,9 @@ func TestTaskHandler_Execute_WebhookCannotBeRetrieved(t *testing.T) {
 func TestTaskHandler_Execute_NoSubscriptionIDInEvent(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{}
 	secretReaderMock := &fake.ISecretReaderMock{}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -593,15 +600,15 @@ func TestTaskHandler_Execute_NoSubscriptionIDInEvent(t *testing.T) {
 	//verify sent events
 	require.Equal(t, 0, len(fakeKeptn.GetEventSender().SentEvents))
 
-	require.Empty(t, curlExecutorMock.CurlCalls())
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
 }
 
 func TestTaskHandler_Execute_InvalidEvent(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{}
 	secretReaderMock := &fake.ISecretReaderMock{}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -615,7 +622,7 @@ func TestTaskHandler_Execute_InvalidEvent(t *testing.T) {
 	//verify sent events
 	require.Empty(t, fakeKeptn.GetEventSender().SentEvents)
 
-	require.Empty(t, curlExecutorMock.CurlCalls())
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
 }
 
 func TestTaskHandler_CannotReadSecret(t *testing.T) {
@@ -624,9 +631,9 @@ func TestTaskHandler_CannotReadSecret(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "", errors.New("unable to read secret :(")
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -659,9 +666,9 @@ func TestTaskHandler_IncompleteDataForTemplate(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -674,7 +681,7 @@ func TestTaskHandler_IncompleteDataForTemplate(t *testing.T) {
 
 	require.NotEmpty(t, secretReaderMock.ReadSecretCalls())
 	require.NotEmpty(t, templateEngineMock.ParseTemplateCalls())
-	require.Empty(t, curlExecutorMock.CurlCalls())
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -689,7 +696,7 @@ func TestTaskHandler_IncompleteDataForTemplate(t *testing.T) {
 	assert.Equal(t, keptnv2.ResultFailed, eventData.Result)
 }
 
-func TestTaskHandler_CurlExecutorFails(t *testing.T) {
+func TestTaskHandler_RequestExecutorFails(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
 		tplE := &lib.TemplateEngine{}
 		return tplE.ParseTemplate(data, templateStr)
@@ -698,11 +705,11 @@ func TestTaskHandler_CurlExecutorFails(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "", errors.New("unable to execute curl call")
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return nil, errors.New("unable to execute request")
 	}
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -715,7 +722,7 @@ func TestTaskHandler_CurlExecutorFails(t *testing.T) {
 
 	require.NotEmpty(t, secretReaderMock.ReadSecretCalls())
 	require.NotEmpty(t, templateEngineMock.ParseTemplateCalls())
-	require.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -730,7 +737,7 @@ func TestTaskHandler_CurlExecutorFails(t *testing.T) {
 	assert.Equal(t, keptnv2.ResultFailed, eventData.Result)
 }
 
-func TestTaskHandler_CurlExecutorFailsHideSecret(t *testing.T) {
+func TestTaskHandler_RequestExecutorFailsHideSecret(t *testing.T) {
 	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
 		tplE := &lib.TemplateEngine{}
 		return tplE.ParseTemplate(data, templateStr)
@@ -739,11 +746,11 @@ func TestTaskHandler_CurlExecutorFailsHideSecret(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "", errors.New("unable to execute curl call containing secret my-secret-value")
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return nil, errors.New("unable to execute request containing secret my-secret-value")
 	}
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -756,7 +763,7 @@ func TestTaskHandler_CurlExecutorFailsHideSecret(t *testing.T) {
 
 	require.NotEmpty(t, secretReaderMock.ReadSecretCalls())
 	require.NotEmpty(t, templateEngineMock.ParseTemplateCalls())
-	require.Equal(t, "curl http://localhost:8080 myproject my-secret-value", curlExecutorMock.CurlCalls()[0].CurlCmd)
+	require.Equal(t, expectedWebhookRequest, requestExecutorMock.ExecuteCalls()[0].Request)
 
 	//verify sent events
 	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
@@ -781,9 +788,9 @@ func TestTaskHandler_Execute_WebhookConfigInService(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
 	resourceHandlerMock := &fake2.IResourceHandlerMock{}
@@ -795,7 +802,7 @@ func TestTaskHandler_Execute_WebhookConfigInService(t *testing.T) {
 		}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -837,9 +844,9 @@ func TestTaskHandler_Execute_WebhookConfigInStage(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
 	resourceHandlerMock := &fake2.IResourceHandlerMock{}
@@ -858,7 +865,7 @@ func TestTaskHandler_Execute_WebhookConfigInStage(t *testing.T) {
 		}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -906,9 +913,9 @@ func TestTaskHandler_Execute_WebhookConfigInProject(t *testing.T) {
 	secretReaderMock.ReadSecretFunc = func(name string, key string) (string, error) {
 		return "my-secret-value", nil
 	}
-	curlExecutorMock := &fake.ICurlExecutorMock{}
-	curlExecutorMock.CurlFunc = func(curlCmd string) (string, error) {
-		return "success", nil
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
 	}
 
 	resourceHandlerMock := &fake2.IResourceHandlerMock{}
@@ -927,7 +934,7 @@ func TestTaskHandler_Execute_WebhookConfigInProject(t *testing.T) {
 		}, nil
 	}
 
-	taskHandler := handler.NewTaskHandler(templateEngineMock, curlExecutorMock, secretReaderMock)
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
 
 	fakeKeptn := sdk.NewFakeKeptn(
 		"test-webhook-svc")
@@ -973,70 +980,106 @@ func TestTaskHandler_Execute_WebhookConfigInProject(t *testing.T) {
 }
 
 func Test_createRequest(t *testing.T) {
+	retries := 2
+	data := map[string]interface{}{
+		"data": map[string]interface{}{"project": "myproject"},
+		"env":  map[string]string{"mysecret": "my-secret-value"},
+	}
 	tests := []struct {
 		name    string
 		data    interface{}
-		want    string
+		want    *lib.Request
 		wantErr bool
 	}{
 		{
-			name:    "valid alpha input",
-			data:    "curl command",
-			want:    "curl command",
+			name: "valid alpha input",
+			data: "curl -X POST -H 'token: {{.env.mysecret}}' --data 'some payload' http://localhost:8080/{{.data.project}}",
+			want: &lib.Request{
+				Method:  "POST",
+				URL:     "http://localhost:8080/myproject",
+				Headers: []lib.Header{{Key: "token", Value: "my-secret-value"}},
+				Payload: "some payload",
+			},
 			wantErr: false,
 		},
+		{
+			name:    "invalid alpha input",
+			data:    "wget http://localhost:8080",
+			wantErr: true,
+		},
 		{
 			name: "valid beta input #1",
 			data: lib.Request{
 				Headers: []lib.Header{
 					{
 						Key:   "key",
-						Value: "value",
+						Value: "{{.env.mysecret}}",
 					},
 				},
 				Method:  "POST",
-				Options: "--some-options",
-				Payload: "some payload",
-				URL:     "http://localhost:8080",
+				Options: "--insecure --retry 2",
+				Payload: "some payload for {{.data.project}}",
+				URL:     "http://localhost:8080/{{.data.project}}",
 			},
-			want:    "curl --request POST --header 'key: value' --data 'some payload' --some-options http://localhost:8080",
-			wantErr: false,
-		},
-		{
-			name: "valid beta input #2",
-			data: lib.Request{
+			want: &lib.Request{
 				Headers: []lib.Header{
 					{
 						Key:   "key",
-						Value: "value",
+						Value: "my-secret-value",
 					},
 				},
-				Method: "POST",
-				URL:    "http://localhost:8080",
+				Method:                "POST",
+				Options:               "--insecure --retry 2",
+				Payload:               "some payload for myproject",
+				URL:                   "http://localhost:8080/myproject",
+				InsecureSkipTLSVerify: true,
+				Retries:               &retries,
 			},
-			want:    "curl --request POST --header 'key: value' http://localhost:8080",
 			wantErr: false,
 		},
 		{
-			name: "valid beta input #3",
+			name: "valid beta input #2",
 			data: lib.Request{
-				Method: "POST",
-				URL:    "http://localhost:8080",
+				Method:  "POST",
+				URL:     "http://localhost:8080",
+				Timeout: "5s",
+			},
+			want: &lib.Request{
+				Headers: []lib.Header{},
+				Method:  "POST",
+				URL:     "http://localhost:8080",
+				Timeout: "5s",
 			},
-			want:    "curl --request POST http://localhost:8080",
 			wantErr: false,
 		},
+		{
+			name: "beta input with unsupported options",
+			data: lib.Request{
+				Method:  "POST",
+				Options: "--output somefile",
+				URL:     "http://localhost:8080",
+			},
+			wantErr: true,
+		},
+		{
+			name: "beta input with incomplete data for template",
+			data: lib.Request{
+				Method: "POST",
+				URL:    "http://localhost:8080/{{.unavailable}}",
+			},
+			wantErr: true,
+		},
 		{
 			name:    "invalid input",
 			data:    1,
-			want:    "",
+			want:    nil,
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := handler.CreateRequest(tt.data)
+			got, err := handler.CreateRequest(&lib.TemplateEngine{}, data, tt.data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1046,3 +1089,325 @@ func Test_createRequest(t *testing.T) {
 		})
 	}
 }
+
+const webHookContentWithResponseMapping = `apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      sendFinished: true
+      requests:
+        - url: http://localhost:8080/{{.data.project}}/tickets
+          method: POST
+          response:
+            extract:
+              ticketId: $.id
+              ticketUrl: "{{.body.links.self}}"
+            successCondition:
+              statusCodes:
+                - 201
+              expression: '{{eq .body.status "open"}}'`
+
+func Test_HandleIncomingTriggeredEvent_ExtractResponseValues(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{
+			StatusCode: http.StatusCreated,
+			Body:       `{"id": "TICKET-42", "status": "open", "links": {"self": "https://tickets.example.com/TICKET-42"}}`,
+		}, nil
+	}
+
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: webHookContentWithResponseMapping})
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	assert.Equal(t, "http://localhost:8080/myproject/tickets", requestExecutorMock.ExecuteCalls()[0].Request.URL)
+
+	//verify sent events
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	assert.Equal(t, "sh.keptn.event.webhook.finished", fakeKeptn.GetEventSender().SentEvents[1].Type())
+
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	require.Nil(t, err)
+	eventData := map[string]interface{}{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, &eventData))
+	assert.Equal(t, string(keptnv2.StatusSucceeded), eventData["status"])
+	assert.Equal(t, string(keptnv2.ResultPass), eventData["result"])
+	assert.Equal(t, map[string]interface{}{
+		"ticketId":  "TICKET-42",
+		"ticketUrl": "https://tickets.example.com/TICKET-42",
+		"responses": []interface{}{`{"id": "TICKET-42", "status": "open", "links": {"self": "https://tickets.example.com/TICKET-42"}}`},
+	}, eventData["webhook"])
+}
+
+func Test_HandleIncomingTriggeredEvent_SuccessConditionNotFulfilled(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{
+			StatusCode: http.StatusCreated,
+			Body:       `{"id": "TICKET-42", "status": "rejected", "links": {"self": "https://tickets.example.com/TICKET-42"}}`,
+		}, nil
+	}
+
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: webHookContentWithResponseMapping})
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+
+	//verify sent events
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	assert.Equal(t, "sh.keptn.event.webhook.finished", fakeKeptn.GetEventSender().SentEvents[1].Type())
+
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	eventData := &keptnv2.EventData{}
+	keptnv2.EventDataAs(finishedEvent, eventData)
+	require.Nil(t, err)
+	assert.Equal(t, keptnv2.StatusSucceeded, eventData.Status)
+	assert.Equal(t, keptnv2.ResultFailed, eventData.Result)
+	assert.Contains(t, eventData.Message, "success condition")
+}
+
+const webHookContentWithCallback = `apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      callback:
+        timeout: 1h
+      requests:
+        - url: http://localhost:8080/{{.data.project}}/jobs
+          method: POST
+          payload: '{"callbackUrl": "{{.callback.url}}"}'`
+
+func Test_HandleIncomingTriggeredEvent_Callback(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusAccepted, Body: "accepted"}, nil
+	}
+
+	callbacks := handler.NewCallbackRegistry("http://webhook-service:8082")
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock, handler.WithCallbacks(callbacks))
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: webHookContentWithCallback})
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	payload := map[string]string{}
+	require.Nil(t, json.Unmarshal([]byte(requestExecutorMock.ExecuteCalls()[0].Request.Payload), &payload))
+	require.Contains(t, payload["callbackUrl"], "http://webhook-service:8082"+handler.CallbackPath)
+
+	// the .finished event is only sent after the callback has been received
+	require.Equal(t, 1, len(fakeKeptn.GetEventSender().SentEvents))
+	assert.Equal(t, "sh.keptn.event.webhook.started", fakeKeptn.GetEventSender().SentEvents[0].Type())
+
+	token := strings.TrimPrefix(payload["callbackUrl"], "http://webhook-service:8082"+handler.CallbackPath)
+	err := callbacks.Complete(token, &handler.CallbackPayload{
+		Result:  keptnv2.ResultWarning,
+		Message: "job finished with warnings",
+		Data:    map[string]interface{}{"jobId": "42"},
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	assert.Equal(t, "sh.keptn.event.webhook.finished", fakeKeptn.GetEventSender().SentEvents[1].Type())
+
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	require.Nil(t, err)
+	eventData := map[string]interface{}{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, &eventData))
+	assert.Equal(t, string(keptnv2.StatusSucceeded), eventData["status"])
+	assert.Equal(t, string(keptnv2.ResultWarning), eventData["result"])
+	assert.Equal(t, "job finished with warnings", eventData["message"])
+	assert.Equal(t, map[string]interface{}{
+		"jobId":     "42",
+		"responses": []interface{}{"accepted"},
+	}, eventData["webhook"])
+
+	// callback URLs can only be used once
+	require.NotNil(t, callbacks.Complete(token, &handler.CallbackPayload{}))
+}
+
+func Test_HandleIncomingTriggeredEvent_CallbacksNotEnabled(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(sdk.StringResourceHandler{ResourceContent: webHookContentWithCallback})
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
+
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	require.Nil(t, err)
+	eventData := &keptnv2.EventData{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, eventData))
+	assert.Equal(t, keptnv2.StatusErrored, eventData.Status)
+	assert.Equal(t, keptnv2.ResultFailed, eventData.Result)
+	assert.Contains(t, eventData.Message, "callbacks are not enabled")
+}
+
+const webHookContentWithMultipart = `apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      sendFinished: true
+      requests:
+        - url: http://localhost:8080/{{.data.project}}/attachments
+          method: POST
+          multipart:
+            - name: summary
+              value: "Deployment of {{.data.service}}"
+            - name: values
+              resource: helm/values.yaml
+              contentType: text/yaml`
+
+func Test_HandleIncomingTriggeredEvent_MultipartWithResource(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+	requestExecutorMock.ExecuteFunc = func(request lib.Request) (*lib.Response, error) {
+		return &lib.Response{StatusCode: http.StatusOK, Body: "success"}, nil
+	}
+
+	resourceHandlerMock := &fake2.IResourceHandlerMock{}
+	resourceHandlerMock.GetResourceFunc = func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+		scopeVals := reflect.ValueOf(scope)
+		if scopeVals.FieldByName("resource").String() == "webhook/webhook.yaml" {
+			return &models.Resource{ResourceContent: webHookContentWithMultipart}, nil
+		}
+		// the attached resource is only available on stage level
+		if scopeVals.FieldByName("service").String() != "" {
+			return nil, errors.New("resource not found")
+		}
+		return &models.Resource{ResourceContent: "replicas: 2"}, nil
+	}
+
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(resourceHandlerMock)
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Len(t, requestExecutorMock.ExecuteCalls(), 1)
+	request := requestExecutorMock.ExecuteCalls()[0].Request
+	assert.Equal(t, "http://localhost:8080/myproject/attachments", request.URL)
+	assert.Equal(t, []lib.MultipartField{
+		{Name: "summary", Value: "Deployment of myservice"},
+		{Name: "values", Resource: "helm/values.yaml", ContentType: "text/yaml", Content: "replicas: 2"},
+	}, request.Multipart)
+
+	require.Len(t, resourceHandlerMock.GetResourceCalls(), 3)
+	scopeVals := reflect.ValueOf(resourceHandlerMock.GetResourceCalls()[2].Scope)
+	require.Equal(t, "helm/values.yaml", scopeVals.FieldByName("resource").String())
+	require.Equal(t, "mystage", scopeVals.FieldByName("stage").String())
+
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	require.Nil(t, err)
+	eventData := &keptnv2.EventData{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, eventData))
+	assert.Equal(t, keptnv2.ResultPass, eventData.Result)
+}
+
+func Test_HandleIncomingTriggeredEvent_MultipartResourceNotFound(t *testing.T) {
+	templateEngineMock := &fake.ITemplateEngineMock{ParseTemplateFunc: func(data interface{}, templateStr string) (string, error) {
+		tplE := &lib.TemplateEngine{}
+		return tplE.ParseTemplate(data, templateStr)
+	}}
+	secretReaderMock := &fake.ISecretReaderMock{}
+	requestExecutorMock := &fake.IRequestExecutorMock{}
+
+	resourceHandlerMock := &fake2.IResourceHandlerMock{}
+	resourceHandlerMock.GetResourceFunc = func(scope api.ResourceScope, options ...api.URIOption) (*models.Resource, error) {
+		if reflect.ValueOf(scope).FieldByName("resource").String() == "webhook/webhook.yaml" {
+			return &models.Resource{ResourceContent: webHookContentWithMultipart}, nil
+		}
+		return nil, errors.New("resource not found")
+	}
+
+	taskHandler := handler.NewTaskHandler(templateEngineMock, requestExecutorMock, secretReaderMock)
+
+	fakeKeptn := sdk.NewFakeKeptn(
+		"test-webhook-svc")
+	fakeKeptn.SetResourceHandler(resourceHandlerMock)
+	fakeKeptn.AddTaskHandler("*", taskHandler)
+	fakeKeptn.SetAutomaticResponse(false)
+	fakeKeptn.Start()
+	fakeKeptn.NewEvent(newWebhookTriggeredEvent("test/events/test-webhook.triggered-0.json"))
+
+	require.Empty(t, requestExecutorMock.ExecuteCalls())
+
+	require.Equal(t, 2, len(fakeKeptn.GetEventSender().SentEvents))
+	finishedEvent, err := keptnv2.ToKeptnEvent(fakeKeptn.GetEventSender().SentEvents[1])
+	require.Nil(t, err)
+	eventData := &keptnv2.EventData{}
+	require.Nil(t, keptnv2.EventDataAs(finishedEvent, eventData))
+	assert.Equal(t, keptnv2.StatusErrored, eventData.Status)
+	assert.Equal(t, keptnv2.ResultFailed, eventData.Result)
+	assert.Contains(t, eventData.Message, "could not read resource helm/values.yaml")
+}
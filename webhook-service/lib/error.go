@@ -4,6 +4,8 @@ type WebhookExecutionError struct {
 	PreExecutionError bool
 	ErrorObj          error
 	ExecutedRequests  int
+	// SuccessConditionFailed indicates that the request has been executed, but the response did not fulfill the success condition
+	SuccessConditionFailed bool
 }
 
 type WebhookExecutionErrorOpt func(executionError *WebhookExecutionError)
@@ -14,6 +16,12 @@ func WithNrOfExecutedRequests(nrRequests int) WebhookExecutionErrorOpt {
 	}
 }
 
+func WithFailedSuccessCondition() WebhookExecutionErrorOpt {
+	return func(executionError *WebhookExecutionError) {
+		executionError.SuccessConditionFailed = true
+	}
+}
+
 func NewWebhookExecutionError(preExec bool, err error, opts ...WebhookExecutionErrorOpt) *WebhookExecutionError {
 	whe := &WebhookExecutionError{
 		PreExecutionError: preExec,
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const (
+	formContentType      = "application/x-www-form-urlencoded"
+	multipartContentType = "multipart/form-data"
+)
+
+// FormField is a field of an application/x-www-form-urlencoded request body
+type FormField struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// MultipartField is a part of a multipart/form-data request body. A part either contains a value, or the content of a Keptn resource
+type MultipartField struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value,omitempty"`
+	// Resource is the URI of a Keptn resource which is attached as a file, e.g. helm/values.yaml.
+	// The resource is looked up in the service, the stage and the project of the event, in this order
+	Resource string `yaml:"resource,omitempty"`
+	// Filename of the attached file. Defaults to the file name of the resource
+	Filename string `yaml:"filename,omitempty"`
+	// ContentType of the part. Defaults to application/octet-stream for resources
+	ContentType string `yaml:"contentType,omitempty"`
+	// Content is the content of the resource, which is retrieved before the request is executed
+	Content string `yaml:"-" mapstructure:"-"`
+}
+
+// GetFilename returns the file name used for the part, if it contains a resource
+func (f MultipartField) GetFilename() string {
+	if f.Filename != "" {
+		return f.Filename
+	}
+	if f.Resource == "" {
+		return ""
+	}
+	segments := strings.Split(f.Resource, "/")
+	return segments[len(segments)-1]
+}
+
+// HasBody returns whether the request has a payload, form fields or multipart fields
+func (r Request) HasBody() bool {
+	return r.Payload != "" || len(r.Form) > 0 || len(r.Multipart) > 0
+}
+
+// GetBody returns the encoded body of the request, together with its content type.
+// The content type is empty if the request has a plain payload, or no body at all
+func (r Request) GetBody() ([]byte, string, error) {
+	if len(r.Form) > 0 {
+		return []byte(encodeForm(r.Form)), formContentType, nil
+	}
+	if len(r.Multipart) > 0 {
+		return encodeMultipart(r.Multipart)
+	}
+	return []byte(r.Payload), "", nil
+}
+
+func encodeForm(fields []FormField) string {
+	// url.Values would sort the fields by their keys, so the fields are encoded manually to keep their order
+	encoded := make([]string, 0, len(fields))
+	for _, field := range fields {
+		encoded = append(encoded, url.QueryEscape(field.Key)+"="+url.QueryEscape(field.Value))
+	}
+	return strings.Join(encoded, "&")
+}
+
+func encodeMultipart(fields []MultipartField) ([]byte, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, field := range fields {
+		header := textproto.MIMEHeader{}
+		disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(field.Name))
+		content := field.Value
+		if field.Resource != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(field.GetFilename()))
+			content = field.Content
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		header.Set("Content-Disposition", disposition)
+		if field.ContentType != "" {
+			header.Set("Content-Type", field.ContentType)
+		}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+func verifyRequestBody(request Request) error {
+	bodies := 0
+	for _, hasBody := range []bool{request.Payload != "", len(request.Form) > 0, len(request.Multipart) > 0} {
+		if hasBody {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return errors.New("only one of payload, form and multipart can be set")
+	}
+	for _, field := range request.Form {
+		if field.Key == "" {
+			return errors.New("form field key empty")
+		}
+	}
+	for _, field := range request.Multipart {
+		if field.Name == "" {
+			return errors.New("multipart field name empty")
+		}
+		if field.Value != "" && field.Resource != "" {
+			return fmt.Errorf("multipart field %s can either contain a value or a resource", field.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	RequestTimeoutEnvVar                  = "REQUEST_TIMEOUT"
+	RequestMaxRetriesEnvVar               = "REQUEST_MAX_RETRIES"
+	RequestRetryBackoffEnvVar             = "REQUEST_RETRY_BACKOFF"
+	RequestInsecureSkipVerifyEnvVar       = "REQUEST_INSECURE_SKIP_TLS_VERIFY"
+	RequestCACertificateFileEnvVar        = "REQUEST_CA_CERT_FILE"
+	defaultRequestTimeout                 = 60 * time.Second
+	defaultRequestRetryBackoff            = time.Second
+	maxResponseBodySize             int64 = 10 * 1024 * 1024
+)
+
+// RequestErrorReason describes why a request could not be executed successfully
+type RequestErrorReason int
+
+const (
+	// MissingURLReason indicates that the request does not contain a URL
+	MissingURLReason RequestErrorReason = iota
+	// InvalidRequestReason indicates that the request cannot be sent, e.g. due to an unsupported URL scheme or an invalid timeout
+	InvalidRequestReason
+	// DeniedURLReason indicates that the URL of the request, or an address it resolves to, is on the deny list
+	DeniedURLReason
+	// FailedRequestReason indicates that the request has been sent, but failed or returned an error status code
+	FailedRequestReason
+)
+
+// RequestExecutionError is returned if a webhook request could not be executed successfully
+type RequestExecutionError struct {
+	err    error
+	reason RequestErrorReason
+}
+
+func (e *RequestExecutionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RequestExecutionError) Unwrap() error {
+	return e.err
+}
+
+// IsRequestExecutionError checks whether the request could not be executed successfully for the given reason
+func IsRequestExecutionError(err error, reason RequestErrorReason) bool {
+	var requestErr *RequestExecutionError
+	if errors.As(err, &requestErr) {
+		return requestErr.reason == reason
+	}
+	return false
+}
+
+//go:generate moq  -pkg fake -out ./fake/request_executor_mock.go . IRequestExecutor
+type IRequestExecutor interface {
+	Execute(request Request) (*Response, error)
+}
+
+// HTTPRequestExecutor executes webhook requests using the net/http client of Go
+type HTTPRequestExecutor struct {
+	deniedURLs         []string
+	timeout            time.Duration
+	maxRetries         int
+	retryBackoff       time.Duration
+	tlsConfig          *tls.Config
+	proxy              func(*http.Request) (*url.URL, error)
+	transport          http.RoundTripper
+	insecureTransport  http.RoundTripper
+	customTransportSet bool
+}
+
+type HTTPRequestExecutorOption func(executor *HTTPRequestExecutor)
+
+// WithDeniedURLs sets the hosts (optionally including a port) which must not be called by any request
+func WithDeniedURLs(urls []string) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.deniedURLs = urls
+	}
+}
+
+// WithTimeout sets the default timeout of a request, including all redirects and reading the response body
+func WithTimeout(timeout time.Duration) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.timeout = timeout
+	}
+}
+
+// WithRetries sets the default number of retries of failed requests. The backoff is doubled with every retry
+func WithRetries(maxRetries int, backoff time.Duration) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.maxRetries = maxRetries
+		executor.retryBackoff = backoff
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for HTTPS requests
+func WithTLSConfig(tlsConfig *tls.Config) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.tlsConfig = tlsConfig
+	}
+}
+
+// WithProxy sets the function returning the proxy used for a request. By default, the proxy is taken from the environment
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.proxy = proxy
+	}
+}
+
+// WithTransport replaces the transport used to send requests, which is mainly useful for testing
+func WithTransport(transport http.RoundTripper) HTTPRequestExecutorOption {
+	return func(executor *HTTPRequestExecutor) {
+		executor.transport = transport
+		executor.insecureTransport = transport
+		executor.customTransportSet = true
+	}
+}
+
+func NewHTTPRequestExecutor(opts ...HTTPRequestExecutorOption) *HTTPRequestExecutor {
+	executor := &HTTPRequestExecutor{
+		timeout:      defaultRequestTimeout,
+		retryBackoff: defaultRequestRetryBackoff,
+		proxy:        http.ProxyFromEnvironment,
+	}
+	for _, o := range opts {
+		o(executor)
+	}
+	if !executor.customTransportSet {
+		tlsConfig := &tls.Config{}
+		if executor.tlsConfig != nil {
+			tlsConfig = executor.tlsConfig.Clone()
+		}
+		insecureTLSConfig := tlsConfig.Clone()
+		insecureTLSConfig.InsecureSkipVerify = true
+
+		executor.transport = executor.newTransport(tlsConfig)
+		executor.insecureTransport = executor.newTransport(insecureTLSConfig)
+	}
+	return executor
+}
+
+// HTTPRequestExecutorOptionsFromEnv derives the timeout, retry and TLS settings of the HTTPRequestExecutor from the given environment variables
+func HTTPRequestExecutorOptionsFromEnv(env map[string]string) ([]HTTPRequestExecutorOption, error) {
+	opts := []HTTPRequestExecutorOption{}
+	if timeout := env[RequestTimeoutEnvVar]; timeout != "" {
+		parsedTimeout, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", RequestTimeoutEnvVar, err)
+		}
+		opts = append(opts, WithTimeout(parsedTimeout))
+	}
+	if maxRetries := env[RequestMaxRetriesEnvVar]; maxRetries != "" {
+		parsedMaxRetries, err := strconv.Atoi(maxRetries)
+		if err != nil || parsedMaxRetries < 0 {
+			return nil, fmt.Errorf("invalid value for %s: must be a positive number", RequestMaxRetriesEnvVar)
+		}
+		backoff := defaultRequestRetryBackoff
+		if retryBackoff := env[RequestRetryBackoffEnvVar]; retryBackoff != "" {
+			backoff, err = time.ParseDuration(retryBackoff)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", RequestRetryBackoffEnvVar, err)
+			}
+		}
+		opts = append(opts, WithRetries(parsedMaxRetries, backoff))
+	}
+
+	tlsConfig := &tls.Config{}
+	if caCertFile := env[RequestCACertificateFileEnvVar]; caCertFile != "" {
+		caCert, err := ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate file %s: %w", caCertFile, err)
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("could not parse CA certificate file %s", caCertFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if insecure := env[RequestInsecureSkipVerifyEnvVar]; insecure != "" {
+		insecureSkipVerify, err := strconv.ParseBool(insecure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", RequestInsecureSkipVerifyEnvVar, err)
+		}
+		tlsConfig.InsecureSkipVerify = insecureSkipVerify
+	}
+	opts = append(opts, WithTLSConfig(tlsConfig))
+	return opts, nil
+}
+
+// Execute sends the request and returns the response. The request is retried if it failed due to a network error or a server-side error.
+// Whether the response indicates a successful request is decided by CheckResponse
+func (e *HTTPRequestExecutor) Execute(request Request) (*Response, error) {
+	if request.URL == "" {
+		return nil, &RequestExecutionError{err: errors.New("no URL provided"), reason: MissingURLReason}
+	}
+	requestURL, err := parseRequestURL(request.URL)
+	if err != nil {
+		return nil, &RequestExecutionError{err: err, reason: InvalidRequestReason}
+	}
+	if err := e.validateURL(requestURL); err != nil {
+		return nil, &RequestExecutionError{err: err, reason: DeniedURLReason}
+	}
+
+	timeout, err := request.GetTimeout(e.timeout)
+	if err != nil {
+		return nil, &RequestExecutionError{err: err, reason: InvalidRequestReason}
+	}
+	retries := e.maxRetries
+	if request.Retries != nil {
+		retries = *request.Retries
+	}
+
+	body, contentType, err := request.GetBody()
+	if err != nil {
+		return nil, &RequestExecutionError{err: fmt.Errorf("could not encode request body: %w", err), reason: InvalidRequestReason}
+	}
+
+	client := e.newClient(request)
+	var response *Response
+	for attempt := 0; ; attempt++ {
+		response, err = e.send(client, request, requestURL, body, contentType, timeout)
+		if IsRequestExecutionError(err, DeniedURLReason) {
+			return nil, err
+		}
+		if !shouldRetry(response, err) || attempt >= retries {
+			break
+		}
+		time.Sleep(e.retryBackoff * time.Duration(1<<uint(attempt)))
+	}
+
+	if err != nil {
+		return nil, &RequestExecutionError{err: fmt.Errorf("error during request execution: %s", err.Error()), reason: FailedRequestReason}
+	}
+	return response, nil
+}
+
+func (e *HTTPRequestExecutor) send(client *http.Client, request Request, requestURL *url.URL, body []byte, contentType string, timeout time.Duration) (*Response, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.GetMethod(), requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range request.Headers {
+		req.Header.Add(header.Key, header.Value)
+	}
+	if contentType != "" {
+		// the content type of form and multipart bodies is determined by their encoding, e.g. the multipart boundary
+		req.Header.Set("Content-Type", contentType)
+	} else if len(body) > 0 && req.Header.Get("Content-Type") == "" {
+		// same default as curl when using --data
+		req.Header.Set("Content-Type", formContentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var deniedErr *deniedAddressError
+		if errors.As(err, &deniedErr) {
+			return nil, &RequestExecutionError{err: deniedErr, reason: DeniedURLReason}
+		}
+		var requestErr *RequestExecutionError
+		if errors.As(err, &requestErr) {
+			return nil, requestErr
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: resp.StatusCode, Headers: resp.Header, Body: string(responseBody)}, nil
+}
+
+func (e *HTTPRequestExecutor) newClient(request Request) *http.Client {
+	transport := e.transport
+	if request.InsecureSkipTLSVerify {
+		transport = e.insecureTransport
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !request.FollowRedirects {
+				// same behavior as curl without --location
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if err := e.validateURL(req.URL); err != nil {
+				return &RequestExecutionError{err: err, reason: DeniedURLReason}
+			}
+			return nil
+		},
+	}
+}
+
+func (e *HTTPRequestExecutor) newTransport(tlsConfig *tls.Config) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// check the resolved address as well, so that host names resolving to a denied IP address cannot be used to bypass the deny list
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if e.isDenied(host, port) {
+				return &deniedAddressError{address: address}
+			}
+			return nil
+		},
+	}
+	return &http.Transport{
+		Proxy:                 e.proxyFor,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// proxyFor returns the proxy used for the request. As the dialer only sees the address of the proxy in this case,
+// the addresses the host of the request resolves to are checked against the deny list before the request is passed to the proxy
+func (e *HTTPRequestExecutor) proxyFor(req *http.Request) (*url.URL, error) {
+	proxyURL, err := e.proxy(req)
+	if err != nil || proxyURL == nil {
+		return proxyURL, err
+	}
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	addresses, err := net.DefaultResolver.LookupHost(req.Context(), req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if e.isDenied(address, port) {
+			return nil, &deniedAddressError{address: net.JoinHostPort(address, port)}
+		}
+	}
+	return proxyURL, nil
+}
+
+func (e *HTTPRequestExecutor) validateURL(requestURL *url.URL) error {
+	if e.isDenied(requestURL.Hostname(), requestURL.Port()) {
+		return fmt.Errorf("request contains invalid URL %s", requestURL.Host)
+	}
+	return nil
+}
+
+func (e *HTTPRequestExecutor) isDenied(host, port string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, deniedURL := range e.deniedURLs {
+		deniedURL = strings.ToLower(deniedURL)
+		if deniedURL == host || (port != "" && (deniedURL == host+":"+port || deniedURL == net.JoinHostPort(host, port))) {
+			return true
+		}
+	}
+	return false
+}
+
+type deniedAddressError struct {
+	address string
+}
+
+func (d *deniedAddressError) Error() string {
+	return fmt.Sprintf("request to denied address %s is not allowed", d.address)
+}
+
+// parseRequestURL parses the URL of a request. Like curl, http is assumed if no scheme is given
+func parseRequestURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	requestURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse URL: %s", err.Error())
+	}
+	if requestURL.Scheme != "http" && requestURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %s", requestURL.Scheme)
+	}
+	if requestURL.Host == "" {
+		return nil, errors.New("URL does not contain a host")
+	}
+	return requestURL, nil
+}
+
+func shouldRetry(response *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+}
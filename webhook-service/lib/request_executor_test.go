@@ -0,0 +1,277 @@
+package lib_test
+
+import (
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn/keptn/webhook-service/lib"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingRoundTripper struct {
+	mtx      sync.Mutex
+	requests []*http.Request
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.requests = append(r.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("success")), Request: req}, nil
+}
+
+func TestHTTPRequestExecutor_Execute(t *testing.T) {
+	var received *http.Request
+	var receivedBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := ioutil.ReadAll(r.Body)
+		receivedBody = string(body)
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor()
+	response, err := executor.Execute(lib.Request{
+		URL:     ts.URL + "/foo",
+		Method:  "PUT",
+		Headers: []lib.Header{{Key: "Content-Type", Value: "application/json"}, {Key: "token", Value: "my-token"}},
+		Payload: `{"text":"Hello, World!"}`,
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "success", response.Body)
+	require.Equal(t, http.MethodPut, received.Method)
+	require.Equal(t, "/foo", received.URL.Path)
+	require.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	require.Equal(t, "my-token", received.Header.Get("token"))
+	require.Equal(t, `{"text":"Hello, World!"}`, receivedBody)
+}
+
+func TestHTTPRequestExecutor_ExecuteForm(t *testing.T) {
+	var received url.Values
+	var receivedMethod string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		require.Nil(t, r.ParseForm())
+		received = r.PostForm
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor()
+	_, err := executor.Execute(lib.Request{
+		URL:    ts.URL,
+		Method: "PATCH",
+		Form:   []lib.FormField{{Key: "status", Value: "deployed & verified"}, {Key: "stage", Value: "dev"}},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, http.MethodPatch, receivedMethod)
+	require.Equal(t, url.Values{"status": []string{"deployed & verified"}, "stage": []string{"dev"}}, received)
+}
+
+func TestHTTPRequestExecutor_ExecuteMultipart(t *testing.T) {
+	var received *multipart.Form
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseMultipartForm(1024*1024))
+		received = r.MultipartForm
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor()
+	_, err := executor.Execute(lib.Request{
+		URL: ts.URL,
+		// an explicitly set content type must not override the boundary of the multipart body
+		Headers: []lib.Header{{Key: "Content-Type", Value: "multipart/form-data"}},
+		Multipart: []lib.MultipartField{
+			{Name: "summary", Value: "Deployment failed"},
+			{Name: "values", Resource: "helm/values.yaml", ContentType: "text/yaml", Content: "replicas: 2"},
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, []string{"Deployment failed"}, received.Value["summary"])
+	require.Len(t, received.File["values"], 1)
+	file := received.File["values"][0]
+	require.Equal(t, "values.yaml", file.Filename)
+	require.Equal(t, "text/yaml", file.Header.Get("Content-Type"))
+	content, err := file.Open()
+	require.Nil(t, err)
+	defer content.Close()
+	data, err := ioutil.ReadAll(content)
+	require.Nil(t, err)
+	require.Equal(t, "replicas: 2", string(data))
+}
+
+func TestHTTPRequestExecutor_ExecuteDefaultMethod(t *testing.T) {
+	transport := &recordingRoundTripper{}
+	executor := lib.NewHTTPRequestExecutor(lib.WithTransport(transport))
+
+	_, err := executor.Execute(lib.Request{URL: "my.hook.com"})
+	require.Nil(t, err)
+	_, err = executor.Execute(lib.Request{URL: "my.hook.com", Payload: "a=b"})
+	require.Nil(t, err)
+
+	require.Len(t, transport.requests, 2)
+	require.Equal(t, http.MethodGet, transport.requests[0].Method)
+	require.Equal(t, "http://my.hook.com", transport.requests[0].URL.String())
+	require.Equal(t, http.MethodPost, transport.requests[1].Method)
+	require.Equal(t, "application/x-www-form-urlencoded", transport.requests[1].Header.Get("Content-Type"))
+}
+
+func TestHTTPRequestExecutor_ExecuteErrorStatusCode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor()
+	response, err := executor.Execute(lib.Request{URL: ts.URL})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	// without success condition, the status code fails the request
+	err = lib.CheckResponse(lib.Request{URL: ts.URL}, response)
+	require.NotNil(t, err)
+	require.True(t, lib.IsRequestExecutionError(err, lib.FailedRequestReason))
+	require.Contains(t, err.Error(), "404")
+	require.Contains(t, err.Error(), "not found")
+}
+
+func TestHTTPRequestExecutor_ExecuteRetries(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor(lib.WithRetries(2, time.Millisecond))
+	response, err := executor.Execute(lib.Request{URL: ts.URL})
+	require.Nil(t, err)
+	require.Equal(t, "success", response.Body)
+	require.Equal(t, 3, attempts)
+
+	// retries of the request override the default
+	attempts = 0
+	noRetries := 0
+	response, err = executor.Execute(lib.Request{URL: ts.URL, Retries: &noRetries})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	require.Equal(t, 1, attempts)
+}
+
+func TestHTTPRequestExecutor_ExecuteTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	executor := lib.NewHTTPRequestExecutor()
+	_, err := executor.Execute(lib.Request{URL: ts.URL, Timeout: "50ms"})
+
+	require.NotNil(t, err)
+	require.True(t, lib.IsRequestExecutionError(err, lib.FailedRequestReason))
+}
+
+func TestHTTPRequestExecutor_ExecuteRedirects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("target"))
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor()
+
+	// like curl, redirects are not followed by default
+	response, err := executor.Execute(lib.Request{URL: ts.URL + "/redirect"})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusFound, response.StatusCode)
+
+	response, err = executor.Execute(lib.Request{URL: ts.URL + "/redirect", FollowRedirects: true})
+	require.Nil(t, err)
+	require.Equal(t, "target", response.Body)
+}
+
+func TestHTTPRequestExecutor_ExecuteDeniedURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to denied URL must not be sent")
+	}))
+	defer ts.Close()
+
+	executor := lib.NewHTTPRequestExecutor(lib.WithDeniedURLs([]string{"127.0.0.1"}))
+	_, err := executor.Execute(lib.Request{URL: ts.URL})
+	require.NotNil(t, err)
+	require.True(t, lib.IsRequestExecutionError(err, lib.DeniedURLReason))
+
+	// host names resolving to a denied IP address are rejected as well
+	_, err = executor.Execute(lib.Request{URL: strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)})
+	require.NotNil(t, err)
+	require.True(t, lib.IsRequestExecutionError(err, lib.DeniedURLReason))
+}
+
+func TestHTTPRequestExecutor_ExecuteDeniedURLThroughProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to denied URL must not be passed to the proxy")
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.Nil(t, err)
+
+	// the dialer only sees the address of the proxy, hence the host of the request has to be resolved before passing it to the proxy
+	executor := lib.NewHTTPRequestExecutor(lib.WithDeniedURLs([]string{"127.0.0.1:8081"}), lib.WithProxy(http.ProxyURL(proxyURL)))
+	_, err = executor.Execute(lib.Request{URL: "http://localhost:8081/api"})
+	require.NotNil(t, err)
+	require.True(t, lib.IsRequestExecutionError(err, lib.DeniedURLReason))
+}
+
+func TestHTTPRequestExecutor_ExecuteInvalidURL(t *testing.T) {
+	executor := lib.NewHTTPRequestExecutor(lib.WithTransport(&recordingRoundTripper{}))
+
+	_, err := executor.Execute(lib.Request{})
+	require.True(t, lib.IsRequestExecutionError(err, lib.MissingURLReason))
+
+	_, err = executor.Execute(lib.Request{URL: "file:///etc/hosts"})
+	require.True(t, lib.IsRequestExecutionError(err, lib.InvalidRequestReason))
+}
+
+func TestHTTPRequestExecutorOptionsFromEnv(t *testing.T) {
+	opts, err := lib.HTTPRequestExecutorOptionsFromEnv(map[string]string{
+		lib.RequestTimeoutEnvVar:            "10s",
+		lib.RequestMaxRetriesEnvVar:         "3",
+		lib.RequestRetryBackoffEnvVar:       "2s",
+		lib.RequestInsecureSkipVerifyEnvVar: "true",
+	})
+	require.Nil(t, err)
+	require.Len(t, opts, 3)
+
+	_, err = lib.HTTPRequestExecutorOptionsFromEnv(map[string]string{lib.RequestTimeoutEnvVar: "abc"})
+	require.NotNil(t, err)
+
+	_, err = lib.HTTPRequestExecutorOptionsFromEnv(map[string]string{lib.RequestMaxRetriesEnvVar: "-1"})
+	require.NotNil(t, err)
+
+	_, err = lib.HTTPRequestExecutorOptionsFromEnv(map[string]string{lib.RequestCACertificateFileEnvVar: "/does/not/exist"})
+	require.NotNil(t, err)
+}
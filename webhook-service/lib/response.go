@@ -0,0 +1,251 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ResponsesKey is the key of the finished event data containing the raw responses of the requests.
+// It can therefore not be used as a key for extracted values
+const ResponsesKey = "responses"
+
+// Response contains the result of an executed request
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       string
+}
+
+// ResponseConfig defines how the response of a request is evaluated
+type ResponseConfig struct {
+	// Extract maps keys of the finished event data to expressions that are evaluated on the response.
+	// An expression is either a Go template, e.g. {{.body.id}}, or a JSONPath expression applied on the body, e.g. $.id or {.id}
+	Extract map[string]string `yaml:"extract,omitempty"`
+	// SuccessCondition decides whether the request has been successful. By default, a status code >= 400 fails the request
+	SuccessCondition *SuccessCondition `yaml:"successCondition,omitempty"`
+}
+
+// SuccessCondition defines the conditions a response needs to fulfill for a request to be successful.
+// If both status codes and an expression are set, both need to be fulfilled
+type SuccessCondition struct {
+	// StatusCodes contains the accepted status codes, either as single code (200), range (200-299) or class (2xx)
+	StatusCodes []string `yaml:"statusCodes,omitempty"`
+	// Expression is a Go template evaluated on the response that needs to result in "true", e.g. {{eq .body.status "done"}}
+	Expression string `yaml:"expression,omitempty"`
+}
+
+// SuccessConditionError indicates that a request has been executed, but its response did not fulfill the success condition
+type SuccessConditionError struct {
+	reason string
+}
+
+func (e *SuccessConditionError) Error() string {
+	return fmt.Sprintf("response did not fulfill success condition: %s", e.reason)
+}
+
+func IsSuccessConditionError(err error) bool {
+	var conditionErr *SuccessConditionError
+	return errors.As(err, &conditionErr)
+}
+
+type statusCodeRange struct {
+	from int
+	to   int
+}
+
+// Validate checks whether all expressions and status codes of the response config can be parsed
+func (c *ResponseConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for key, expression := range c.Extract {
+		if key == "" {
+			return errors.New("key of extracted value must not be empty")
+		}
+		if key == ResponsesKey {
+			return fmt.Errorf("key '%s' is reserved for the responses of the requests", ResponsesKey)
+		}
+		if err := validateExpression(expression); err != nil {
+			return fmt.Errorf("invalid expression for key '%s': %s", key, err.Error())
+		}
+	}
+	if c.SuccessCondition != nil {
+		if _, err := parseStatusCodeRanges(c.SuccessCondition.StatusCodes); err != nil {
+			return err
+		}
+		if c.SuccessCondition.Expression != "" {
+			if _, err := template.New("").Parse(c.SuccessCondition.Expression); err != nil {
+				return fmt.Errorf("invalid success condition expression: %s", err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// CheckResponse decides whether the request was successful based on the success condition of the request.
+// Without success condition, a status code >= 400 results in a RequestExecutionError
+func CheckResponse(request Request, response *Response) error {
+	if request.Response == nil || request.Response.SuccessCondition == nil {
+		if response.StatusCode >= http.StatusBadRequest {
+			return &RequestExecutionError{err: fmt.Errorf("error during request execution: request returned status code %d.\nResponse: \n%s", response.StatusCode, response.Body), reason: FailedRequestReason}
+		}
+		return nil
+	}
+
+	condition := request.Response.SuccessCondition
+	if len(condition.StatusCodes) > 0 {
+		ranges, err := parseStatusCodeRanges(condition.StatusCodes)
+		if err != nil {
+			return err
+		}
+		if !matchesStatusCode(ranges, response.StatusCode) {
+			return &SuccessConditionError{reason: fmt.Sprintf("status code %d is not one of %s", response.StatusCode, strings.Join(condition.StatusCodes, ", "))}
+		}
+	}
+	if condition.Expression != "" {
+		result, err := (&TemplateEngine{}).ParseTemplate(newResponseTemplateData(response), condition.Expression)
+		if err != nil {
+			return &SuccessConditionError{reason: fmt.Sprintf("could not evaluate expression: %s", err.Error())}
+		}
+		if strings.TrimSpace(result) != "true" {
+			return &SuccessConditionError{reason: fmt.Sprintf("expression '%s' evaluated to '%s'", condition.Expression, strings.TrimSpace(result))}
+		}
+	}
+	return nil
+}
+
+// ExtractResponseValues evaluates the extract expressions of the request on the response
+func ExtractResponseValues(request Request, response *Response) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if request.Response == nil || len(request.Response.Extract) == 0 {
+		return values, nil
+	}
+	data := newResponseTemplateData(response)
+	for key, expression := range request.Response.Extract {
+		var value interface{}
+		var err error
+		if isTemplateExpression(expression) {
+			value, err = (&TemplateEngine{}).ParseTemplate(data, expression)
+		} else {
+			value, err = evaluateJSONPath(expression, data["body"])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not extract value for key '%s': %s", key, err.Error())
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// newResponseTemplateData returns the data that can be accessed by the expressions evaluated on a response.
+// If the body contains JSON, it is accessible as an object, otherwise as a string
+func newResponseTemplateData(response *Response) map[string]interface{} {
+	headers := map[string]string{}
+	for key := range response.Headers {
+		headers[key] = response.Headers.Get(key)
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		body = response.Body
+	}
+	return map[string]interface{}{
+		"statusCode": response.StatusCode,
+		"headers":    headers,
+		"body":       body,
+		"rawBody":    response.Body,
+	}
+}
+
+func isTemplateExpression(expression string) bool {
+	return strings.Contains(expression, "{{")
+}
+
+func validateExpression(expression string) error {
+	if strings.TrimSpace(expression) == "" {
+		return errors.New("expression must not be empty")
+	}
+	if isTemplateExpression(expression) {
+		_, err := template.New("").Parse(expression)
+		return err
+	}
+	_, err := parseJSONPath(expression)
+	return err
+}
+
+func parseJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	expression = strings.TrimSpace(expression)
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + strings.TrimPrefix(expression, "$") + "}"
+	}
+	parser := jsonpath.New("")
+	if err := parser.Parse(expression); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+func evaluateJSONPath(expression string, body interface{}) (interface{}, error) {
+	parser, err := parseJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parser.FindResults(body)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, value.Interface())
+		}
+	}
+	if len(values) == 1 {
+		return values[0], nil
+	}
+	return values, nil
+}
+
+func parseStatusCodeRanges(statusCodes []string) ([]statusCodeRange, error) {
+	ranges := []statusCodeRange{}
+	for _, statusCode := range statusCodes {
+		statusCode = strings.ToLower(strings.TrimSpace(statusCode))
+		var r statusCodeRange
+		var err error
+		switch {
+		case len(statusCode) == 3 && strings.HasSuffix(statusCode, "xx"):
+			var class int
+			class, err = strconv.Atoi(statusCode[:1])
+			r = statusCodeRange{from: class * 100, to: class*100 + 99}
+		case strings.Contains(statusCode, "-"):
+			bounds := strings.SplitN(statusCode, "-", 2)
+			r.from, err = strconv.Atoi(strings.TrimSpace(bounds[0]))
+			if err == nil {
+				r.to, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			}
+		default:
+			r.from, err = strconv.Atoi(statusCode)
+			r.to = r.from
+		}
+		if err != nil || r.from < 100 || r.to > 599 || r.from > r.to {
+			return nil, fmt.Errorf("invalid status code '%s'", statusCode)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func matchesStatusCode(ranges []statusCodeRange, statusCode int) bool {
+	for _, r := range ranges {
+		if statusCode >= r.from && statusCode <= r.to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package lib_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keptn/keptn/webhook-service/lib"
+	"github.com/stretchr/testify/require"
+)
+
+const ticketResponse = `{"id": "TICKET-42", "status": "open", "links": {"self": "https://tickets.example.com/TICKET-42"}, "assignees": [{"name": "alice"}, {"name": "bob"}], "priority": 2}`
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name             string
+		condition        *lib.SuccessCondition
+		response         *lib.Response
+		wantErr          bool
+		wantConditionErr bool
+	}{
+		{
+			name:     "no condition - success",
+			response: &lib.Response{StatusCode: http.StatusCreated},
+		},
+		{
+			name:     "no condition - error status code",
+			response: &lib.Response{StatusCode: http.StatusNotFound},
+			wantErr:  true,
+		},
+		{
+			name:      "status code range",
+			condition: &lib.SuccessCondition{StatusCodes: []string{"200-299"}},
+			response:  &lib.Response{StatusCode: http.StatusAccepted},
+		},
+		{
+			name:      "status code class",
+			condition: &lib.SuccessCondition{StatusCodes: []string{"2xx", "404"}},
+			response:  &lib.Response{StatusCode: http.StatusNotFound},
+		},
+		{
+			name:             "status code not accepted",
+			condition:        &lib.SuccessCondition{StatusCodes: []string{"201"}},
+			response:         &lib.Response{StatusCode: http.StatusOK},
+			wantErr:          true,
+			wantConditionErr: true,
+		},
+		{
+			name:      "expression fulfilled",
+			condition: &lib.SuccessCondition{StatusCodes: []string{"2xx"}, Expression: `{{eq .body.status "open"}}`},
+			response:  &lib.Response{StatusCode: http.StatusOK, Body: ticketResponse},
+		},
+		{
+			name:             "expression not fulfilled",
+			condition:        &lib.SuccessCondition{Expression: `{{eq .body.status "closed"}}`},
+			response:         &lib.Response{StatusCode: http.StatusOK, Body: ticketResponse},
+			wantErr:          true,
+			wantConditionErr: true,
+		},
+		{
+			name:             "expression referencing missing field",
+			condition:        &lib.SuccessCondition{Expression: `{{eq .body.unknown "closed"}}`},
+			response:         &lib.Response{StatusCode: http.StatusOK, Body: ticketResponse},
+			wantErr:          true,
+			wantConditionErr: true,
+		},
+		{
+			name:      "expression on status code and raw body",
+			condition: &lib.SuccessCondition{Expression: `{{and (eq .statusCode 500) (eq .rawBody "expected")}}`},
+			response:  &lib.Response{StatusCode: http.StatusInternalServerError, Body: "expected"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := lib.Request{}
+			if tt.condition != nil {
+				request.Response = &lib.ResponseConfig{SuccessCondition: tt.condition}
+			}
+			err := lib.CheckResponse(request, tt.response)
+			if !tt.wantErr {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Equal(t, tt.wantConditionErr, lib.IsSuccessConditionError(err))
+		})
+	}
+}
+
+func TestExtractResponseValues(t *testing.T) {
+	request := lib.Request{
+		Response: &lib.ResponseConfig{
+			Extract: map[string]string{
+				"ticketId":   "$.id",
+				"ticketUrl":  "{.links.self}",
+				"assignees":  "$.assignees[*].name",
+				"priority":   ".priority",
+				"summary":    "{{.body.id}} ({{.body.status}})",
+				"statusCode": "{{.statusCode}}",
+				"location":   "{{.headers.Location}}",
+			},
+		},
+	}
+	response := &lib.Response{
+		StatusCode: http.StatusCreated,
+		Headers:    http.Header{"Location": []string{"/tickets/42"}},
+		Body:       ticketResponse,
+	}
+
+	values, err := lib.ExtractResponseValues(request, response)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{
+		"ticketId":   "TICKET-42",
+		"ticketUrl":  "https://tickets.example.com/TICKET-42",
+		"assignees":  []interface{}{"alice", "bob"},
+		"priority":   float64(2),
+		"summary":    "TICKET-42 (open)",
+		"statusCode": "201",
+		"location":   "/tickets/42",
+	}, values)
+}
+
+func TestExtractResponseValues_Errors(t *testing.T) {
+	response := &lib.Response{StatusCode: http.StatusOK, Body: ticketResponse}
+
+	_, err := lib.ExtractResponseValues(lib.Request{Response: &lib.ResponseConfig{Extract: map[string]string{"id": "$.unknown"}}}, response)
+	require.NotNil(t, err)
+
+	_, err = lib.ExtractResponseValues(lib.Request{Response: &lib.ResponseConfig{Extract: map[string]string{"id": "{{.body.unknown}}"}}}, response)
+	require.NotNil(t, err)
+
+	// JSONPath expressions cannot be applied on a body that is not JSON
+	_, err = lib.ExtractResponseValues(lib.Request{Response: &lib.ResponseConfig{Extract: map[string]string{"id": "$.id"}}}, &lib.Response{Body: "plain text"})
+	require.NotNil(t, err)
+
+	values, err := lib.ExtractResponseValues(lib.Request{}, response)
+	require.Nil(t, err)
+	require.Empty(t, values)
+}
+
+func TestResponseConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *lib.ResponseConfig
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			config: &lib.ResponseConfig{
+				Extract:          map[string]string{"id": "$.id", "url": "{{.body.url}}"},
+				SuccessCondition: &lib.SuccessCondition{StatusCodes: []string{"200", "201-204", "3xx"}, Expression: "{{eq .body.status \"ok\"}}"},
+			},
+		},
+		{
+			name: "no config",
+		},
+		{
+			name:    "reserved key",
+			config:  &lib.ResponseConfig{Extract: map[string]string{"responses": "$.id"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty expression",
+			config:  &lib.ResponseConfig{Extract: map[string]string{"id": ""}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSONPath",
+			config:  &lib.ResponseConfig{Extract: map[string]string{"id": "$.items[?(@.id=="}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			config:  &lib.ResponseConfig{Extract: map[string]string{"id": "{{.body.id"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status code",
+			config:  &lib.ResponseConfig{SuccessCondition: &lib.SuccessCondition{StatusCodes: []string{"abc"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid status code range",
+			config:  &lib.ResponseConfig{SuccessCondition: &lib.SuccessCondition{StatusCodes: []string{"299-200"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid expression",
+			config:  &lib.ResponseConfig{SuccessCondition: &lib.SuccessCondition{Expression: "{{eq .body.status"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
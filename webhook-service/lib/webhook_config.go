@@ -3,6 +3,8 @@ package lib
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"gopkg.in/yaml.v3"
@@ -31,6 +33,28 @@ type Webhook struct {
 	SendStarted    *bool         `yaml:"sendStarted,omitempty"`
 	EnvFrom        []EnvFrom     `yaml:"envFrom"`
 	Requests       []interface{} `yaml:"requests"`
+	// Callback makes the webhook service wait for a call of a callback URL, which is available as {{.callback.url}}, before sending the .finished event
+	Callback *CallbackConfig `yaml:"callback,omitempty"`
+}
+
+type CallbackConfig struct {
+	// Timeout after which a failed .finished event is sent if the callback URL has not been called, e.g. 2h. Default: 1h
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// GetTimeout returns the timeout of the callback, or the default timeout of one hour
+func (c CallbackConfig) GetTimeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return defaultCallbackTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid callback timeout %s: %w", c.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid callback timeout %s: must be positive", c.Timeout)
+	}
+	return timeout, nil
 }
 
 type EnvFrom struct {
@@ -43,7 +67,45 @@ type Request struct {
 	Method  string   `yaml:"method"`
 	Headers []Header `yaml:"headers,omitempty"`
 	Payload string   `yaml:"payload,omitempty"`
-	Options string   `yaml:"options,omitempty"`
+	// Form contains the fields of an application/x-www-form-urlencoded body
+	Form []FormField `yaml:"form,omitempty"`
+	// Multipart contains the parts of a multipart/form-data body
+	Multipart []MultipartField `yaml:"multipart,omitempty"`
+	// Options contains additional curl options, e.g. --insecure. Only the options supported by ParseCurlCommand can be used
+	Options string `yaml:"options,omitempty"`
+	// Timeout overrides the default timeout of the request, e.g. 30s
+	Timeout string `yaml:"timeout,omitempty"`
+	// Retries overrides the default number of retries if the request fails due to a network error or a server-side error
+	Retries *int `yaml:"retries,omitempty"`
+	// InsecureSkipTLSVerify disables the verification of the server certificate
+	InsecureSkipTLSVerify bool `yaml:"insecureSkipTLSVerify,omitempty"`
+	// FollowRedirects makes the request follow redirects, which are not followed by default
+	FollowRedirects bool `yaml:"followRedirects,omitempty"`
+	// Response defines which values are extracted from the response and when the request is considered to be successful
+	Response *ResponseConfig `yaml:"response,omitempty"`
+}
+
+// GetMethod returns the HTTP method of the request. Like curl, GET is used if no method is set, or POST if the request has a body
+func (r Request) GetMethod() string {
+	if r.Method != "" {
+		return r.Method
+	}
+	if r.HasBody() {
+		return http.MethodPost
+	}
+	return http.MethodGet
+}
+
+// GetTimeout returns the timeout of the request, or the given default timeout if none is set
+func (r Request) GetTimeout(defaultTimeout time.Duration) (time.Duration, error) {
+	if r.Timeout == "" {
+		return defaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(r.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %s: %w", r.Timeout, err)
+	}
+	return timeout, nil
 }
 
 type Header struct {
@@ -57,9 +119,10 @@ type WebHookSecretRef struct {
 }
 
 const webhookConfInvalid = "Webhook configuration invalid: "
+const defaultCallbackTimeout = time.Hour
 const betaApiVersion = "webhookconfig.keptn.sh/v1beta1"
 
-var supportedCurlMethods = [4]string{"POST", "PUT", "GET", "HEAD"}
+var supportedMethods = [6]string{"POST", "PUT", "PATCH", "DELETE", "GET", "HEAD"}
 
 // DecodeWebHookConfigYAML takes a webhook config string formatted as YAML and decodes it to
 // Shipyard value
@@ -86,6 +149,12 @@ func DecodeWebHookConfigYAML(webhookConfigYaml []byte) (*WebHookConfig, error) {
 		if len(webhook.Requests) == 0 {
 			return nil, errors.New(webhookConfInvalid + "missing 'webhooks[].Requests[]' part")
 		}
+
+		if webhook.Callback != nil {
+			if _, err := webhook.Callback.GetTimeout(); err != nil {
+				return nil, errors.New(webhookConfInvalid + err.Error())
+			}
+		}
 	}
 
 	if webHookConfig.ApiVersion == betaApiVersion {
@@ -127,11 +196,23 @@ func verifyBeta1Request(request Request) error {
 			}
 		}
 	}
+	if err := verifyRequestBody(request); err != nil {
+		return fmt.Errorf(webhookConfInvalid+"webhook request body invalid: %s", err.Error())
+	}
+	if _, err := request.GetTimeout(0); err != nil {
+		return fmt.Errorf(webhookConfInvalid+"webhook request timeout invalid: %s", err.Error())
+	}
+	if request.Retries != nil && *request.Retries < 0 {
+		return fmt.Errorf(webhookConfInvalid + "webhook request retries must not be negative")
+	}
+	if err := request.Response.Validate(); err != nil {
+		return fmt.Errorf(webhookConfInvalid+"webhook request response invalid: %s", err.Error())
+	}
 	return nil
 }
 
 func isMethodSupported(method string) bool {
-	for _, m := range supportedCurlMethods {
+	for _, m := range supportedMethods {
 		if m == method {
 			return true
 		}
@@ -146,12 +227,23 @@ func (wh Webhook) ShouldSendStartedEvent() bool {
 	return *wh.SendStarted
 }
 
+// ShouldSendFinishedEvent returns whether a single .finished event is sent for all requests of the webhook.
+// This is the case if sendFinished is set, or if the webhook waits for a callback
 func (wh Webhook) ShouldSendFinishedEvent() bool {
-	return wh.SendFinished
+	return wh.SendFinished || wh.ShouldWaitForCallback()
+}
+
+// ShouldWaitForCallback returns whether the .finished event is only sent once the callback URL of the webhook has been called
+func (wh Webhook) ShouldWaitForCallback() bool {
+	return wh.Callback != nil
 }
 
 func ConvertToRequest(data interface{}) Request {
+	if request, ok := data.(Request); ok {
+		return request
+	}
 	requestStruct := Request{}
-	mapstructure.Decode(data, &requestStruct)
+	// weakly typed, since status codes of a success condition might be given as numbers
+	mapstructure.WeakDecode(data, &requestStruct)
 	return requestStruct
 }
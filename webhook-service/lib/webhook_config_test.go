@@ -183,6 +183,257 @@ spec:
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "valid Beta1 version input - response config",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      requests:
+        - url: http://localhost:8080
+          method: POST
+          response:
+            extract:
+              ticketId: $.id
+              ticketUrl: "{{.body.links.self}}"
+            successCondition:
+              statusCodes:
+                - 201
+                - 2xx
+              expression: '{{eq .body.status "open"}}'`),
+			},
+			want: &WebHookConfig{
+				ApiVersion: "webhookconfig.keptn.sh/v1beta1",
+				Kind:       "WebhookConfig",
+				Metadata: Metadata{
+					Name: "webhook-configuration",
+				},
+				Spec: WebHookConfigSpec{
+					Webhooks: []Webhook{
+						{
+							Type:           "sh.keptn.event.webhook.triggered",
+							SubscriptionID: "my-subscription-id",
+							Requests: []interface{}{
+								Request{
+									Method: "POST",
+									URL:    "http://localhost:8080",
+									Response: &ResponseConfig{
+										Extract: map[string]string{
+											"ticketId":  "$.id",
+											"ticketUrl": "{{.body.links.self}}",
+										},
+										SuccessCondition: &SuccessCondition{
+											StatusCodes: []string{"201", "2xx"},
+											Expression:  `{{eq .body.status "open"}}`,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Beta1 version input - invalid response config",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      requests:
+        - url: http://localhost:8080
+          method: POST
+          response:
+            successCondition:
+              statusCodes:
+                - 2xxx`),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Beta1 version input - callback",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      callback:
+        timeout: 2h
+      requests:
+        - url: http://localhost:8080
+          method: POST
+          payload: '{"callbackUrl": "{{.callback.url}}"}'`),
+			},
+			want: &WebHookConfig{
+				ApiVersion: "webhookconfig.keptn.sh/v1beta1",
+				Kind:       "WebhookConfig",
+				Metadata: Metadata{
+					Name: "webhook-configuration",
+				},
+				Spec: WebHookConfigSpec{
+					Webhooks: []Webhook{
+						{
+							Type:           "sh.keptn.event.webhook.triggered",
+							SubscriptionID: "my-subscription-id",
+							Callback:       &CallbackConfig{Timeout: "2h"},
+							Requests: []interface{}{
+								Request{
+									URL:     "http://localhost:8080",
+									Method:  "POST",
+									Payload: `{"callbackUrl": "{{.callback.url}}"}`,
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Beta1 version input - invalid callback timeout",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      callback:
+        timeout: -5m
+      requests:
+        - url: http://localhost:8080
+          method: POST`),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Beta1 version input - form and multipart bodies",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      requests:
+        - url: http://localhost:8080
+          method: PATCH
+          form:
+            - key: status
+              value: deployed
+        - url: http://localhost:8080
+          method: DELETE
+        - url: http://localhost:8080
+          method: POST
+          multipart:
+            - name: summary
+              value: "{{.data.project}}"
+            - name: values
+              resource: helm/values.yaml
+              filename: values.yaml
+              contentType: text/yaml`),
+			},
+			want: &WebHookConfig{
+				ApiVersion: "webhookconfig.keptn.sh/v1beta1",
+				Kind:       "WebhookConfig",
+				Metadata: Metadata{
+					Name: "webhook-configuration",
+				},
+				Spec: WebHookConfigSpec{
+					Webhooks: []Webhook{
+						{
+							Type:           "sh.keptn.event.webhook.triggered",
+							SubscriptionID: "my-subscription-id",
+							Requests: []interface{}{
+								Request{
+									URL:    "http://localhost:8080",
+									Method: "PATCH",
+									Form:   []FormField{{Key: "status", Value: "deployed"}},
+								},
+								Request{
+									URL:    "http://localhost:8080",
+									Method: "DELETE",
+								},
+								Request{
+									URL:    "http://localhost:8080",
+									Method: "POST",
+									Multipart: []MultipartField{
+										{Name: "summary", Value: "{{.data.project}}"},
+										{Name: "values", Resource: "helm/values.yaml", Filename: "values.yaml", ContentType: "text/yaml"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Beta1 version input - payload and form",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      requests:
+        - url: http://localhost:8080
+          method: POST
+          payload: "a=b"
+          form:
+            - key: c
+              value: d`),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Beta1 version input - multipart field with value and resource",
+			args: args{
+				webhookConfigYaml: []byte(`apiVersion: webhookconfig.keptn.sh/v1beta1
+kind: WebhookConfig
+metadata:
+  name: webhook-configuration
+spec:
+  webhooks:
+    - type: "sh.keptn.event.webhook.triggered"
+      subscriptionID: "my-subscription-id"
+      requests:
+        - url: http://localhost:8080
+          method: POST
+          multipart:
+            - name: values
+              value: abc
+              resource: helm/values.yaml`),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "Beta1 version input - empty requests",
 			args: args{
@@ -218,7 +469,7 @@ spec:
           name: mysecret
       requests:
         - url: http://localhost:8080
-          method: DELETE`),
+          method: CONNECT`),
 			},
 			want:    nil,
 			wantErr: true,
@@ -453,6 +704,7 @@ func TestWebhook_ShouldSendFinishedEvent(t *testing.T) {
 		SendStarted    *bool
 		EnvFrom        []EnvFrom
 		Requests       []interface{}
+		Callback       *CallbackConfig
 	}
 	tests := []struct {
 		name   string
@@ -471,6 +723,13 @@ func TestWebhook_ShouldSendFinishedEvent(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "waiting for callback",
+			fields: fields{
+				Callback: &CallbackConfig{},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -481,6 +740,7 @@ func TestWebhook_ShouldSendFinishedEvent(t *testing.T) {
 				SendStarted:    tt.fields.SendStarted,
 				EnvFrom:        tt.fields.EnvFrom,
 				Requests:       tt.fields.Requests,
+				Callback:       tt.fields.Callback,
 			}
 			if got := wh.ShouldSendFinishedEvent(); got != tt.want {
 				t.Errorf("ShouldSendFinishedEvent() = %v, want %v", got, tt.want)
@@ -7,6 +7,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"net/http"
 	"os"
 	"strings"
 )
@@ -14,6 +15,9 @@ import (
 const eventTypeWildcard = "*"
 const serviceName = "webhook-service"
 const envVarLogLevel = "LOG_LEVEL"
+const envVarCallbackBaseURL = "CALLBACK_BASE_URL"
+const envVarCallbackPort = "CALLBACK_PORT"
+const defaultCallbackPort = "8082"
 
 func main() {
 	if os.Getenv(envVarLogLevel) != "" {
@@ -30,13 +34,26 @@ func main() {
 	}
 	secretReader := lib.NewK8sSecretReader(kubeAPI)
 
-	curlExecutor := lib.NewCmdCurlExecutor(
-		&lib.OSCmdExecutor{},
-		lib.WithDeniedURLs(lib.DeniedURLs(getEnv())),
+	env := getEnv()
+	requestExecutorOptions, err := lib.HTTPRequestExecutorOptionsFromEnv(env)
+	if err != nil {
+		log.Fatalf("could not configure request executor: %s", err.Error())
+	}
+	requestExecutor := lib.NewHTTPRequestExecutor(
+		append(requestExecutorOptions, lib.WithDeniedURLs(lib.DeniedURLs(env)))...,
 	)
-	taskHandler := handler.NewTaskHandler(&lib.TemplateEngine{}, curlExecutor, secretReader)
+	var taskHandlerOptions []handler.TaskHandlerOption
+	var callbacks *handler.CallbackRegistry
+	if callbackBaseURL := env[envVarCallbackBaseURL]; callbackBaseURL != "" {
+		callbacks = handler.NewCallbackRegistry(
+			callbackBaseURL,
+			handler.WithCallbackStore(handler.NewK8sCallbackStore(kubeAPI, lib.GetNamespaceFromEnvVar())),
+		)
+		taskHandlerOptions = append(taskHandlerOptions, handler.WithCallbacks(callbacks))
+	}
+	taskHandler := handler.NewTaskHandler(&lib.TemplateEngine{}, requestExecutor, secretReader, taskHandlerOptions...)
 
-	log.Fatal(sdk.NewKeptn(
+	keptn := sdk.NewKeptn(
 		serviceName,
 		sdk.WithTaskHandler(
 			eventTypeWildcard,
@@ -44,7 +61,25 @@ func main() {
 		),
 		sdk.WithAutomaticResponse(false),
 		sdk.WithLogger(log.New()),
-	).Start())
+	)
+	if callbacks != nil {
+		if err := callbacks.Resume(keptn); err != nil {
+			log.WithError(err).Error("could not resume pending webhook callbacks")
+		}
+		go startCallbackServer(env, callbacks)
+	}
+	log.Fatal(keptn.Start())
+}
+
+func startCallbackServer(env map[string]string, callbacks *handler.CallbackRegistry) {
+	port := env[envVarCallbackPort]
+	if port == "" {
+		port = defaultCallbackPort
+	}
+	mux := http.NewServeMux()
+	mux.Handle(handler.CallbackPath, callbacks)
+	log.Infof("receiving webhook callbacks on port %s", port)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
 }
 
 func createKubeAPI() (*kubernetes.Clientset, error) {